@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var dartReserved = map[string]bool{
+	"class": true, "default": true, "enum": true, "in": true, "is": true, "new": true,
+	"required": true, "switch": true, "var": true, "final": true, "const": true, "null": true,
+}
+
+func dartName(name string) string {
+	field := camelCase(name)
+	if dartReserved[field] {
+		return field + "Value"
+	}
+	return field
+}
+
+// dartType returns the Dart type of s, without the nullable marker.
+func dartType(s *schema) string {
+	if s == nil {
+		return "dynamic"
+	}
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case s.Type == "string":
+		return "String"
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "double"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "array":
+		return "List<" + dartNullable(s.Items) + ">"
+	case s.Type == "object" || len(s.Properties) > 0:
+		return "Map<String, dynamic>"
+	default:
+		return "dynamic"
+	}
+}
+
+func dartNullable(s *schema) string {
+	t := dartType(s)
+	if t != "dynamic" && s.Nullable {
+		t += "?"
+	}
+	return t
+}
+
+// isDartClass reports whether s refers to a component generated as a class.
+func isDartClass(spec *spec, s *schema) bool {
+	if s == nil || s.Ref == "" {
+		return false
+	}
+	target := spec.Components.Schemas[s.Ref[strings.LastIndex(s.Ref, "/")+1:]]
+	return target != nil && len(target.Properties) > 0
+}
+
+// dartDecode returns an expression converting the JSON value expr into s.
+func dartDecode(spec *spec, s *schema, expr string, nullable bool) string {
+	if s == nil {
+		return expr
+	}
+
+	var decoded string
+	switch t := dartType(s); {
+	case isDartClass(spec, s):
+		decoded = fmt.Sprintf("%s.fromJson(%s as Map<String, dynamic>)", t, expr)
+	case t == "int":
+		decoded = fmt.Sprintf("(%s as num).toInt()", expr)
+	case t == "double":
+		decoded = fmt.Sprintf("(%s as num).toDouble()", expr)
+	case s.Type == "array":
+		decoded = fmt.Sprintf("(%s as List<dynamic>).map((e) => %s).toList()", expr, dartDecode(spec, s.Items, "e", s.Items != nil && s.Items.Nullable))
+	case t == "dynamic":
+		return expr
+	default:
+		decoded = fmt.Sprintf("%s as %s", expr, t)
+	}
+
+	if nullable {
+		return fmt.Sprintf("%s == null ? null : %s", expr, decoded)
+	}
+	return decoded
+}
+
+// dartEncode returns an expression converting expr of type s back into JSON.
+func dartEncode(spec *spec, s *schema, expr string, nullable bool) string {
+	switch {
+	case isDartClass(spec, s):
+		if nullable {
+			return expr + "?.toJson()"
+		}
+		return expr + ".toJson()"
+	case s != nil && s.Type == "array" && isDartClass(spec, s.Items):
+		op := "."
+		if nullable {
+			op = "?."
+		}
+		return fmt.Sprintf("%s%smap((e) => %s).toList()", expr, op, dartEncode(spec, s.Items, "e", s.Items.Nullable))
+	default:
+		return expr
+	}
+}
+
+func generateDart(s *spec, ops []operation, source string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by cmd/genclient from %s. DO NOT EDIT.\n\n", source)
+	b.WriteString("import 'dart:convert';\n\nimport 'package:http/http.dart' as http;\n\n")
+
+	for _, name := range s.schemaNames() {
+		sc := s.Components.Schemas[name]
+		class := typeName(name)
+		if sc.Description != "" {
+			fmt.Fprintf(&b, "/// %s\n", sc.Description)
+		}
+		if len(sc.Properties) == 0 {
+			fmt.Fprintf(&b, "typedef %s = %s;\n\n", class, dartType(sc))
+			continue
+		}
+
+		fields := sortedProperties(sc)
+		fmt.Fprintf(&b, "class %s {\n", class)
+		var ctor []string
+		for _, field := range fields {
+			prop := sc.Properties[field]
+			nullable := prop.Nullable || !isRequired(sc, field)
+			t := dartType(prop)
+			if nullable && t != "dynamic" {
+				t += "?"
+			}
+			fmt.Fprintf(&b, "  final %s %s;\n", t, dartName(field))
+			if nullable {
+				ctor = append(ctor, "this."+dartName(field))
+			} else {
+				ctor = append(ctor, "required this."+dartName(field))
+			}
+		}
+		fmt.Fprintf(&b, "\n  %s({%s});\n\n", class, strings.Join(ctor, ", "))
+
+		fmt.Fprintf(&b, "  factory %s.fromJson(Map<String, dynamic> json) => %s(\n", class, class)
+		for _, field := range fields {
+			prop := sc.Properties[field]
+			nullable := prop.Nullable || !isRequired(sc, field)
+			fmt.Fprintf(&b, "        %s: %s,\n", dartName(field), dartDecode(s, prop, fmt.Sprintf("json['%s']", field), nullable))
+		}
+		b.WriteString("      );\n\n")
+
+		b.WriteString("  Map<String, dynamic> toJson() => {\n")
+		for _, field := range fields {
+			prop := sc.Properties[field]
+			nullable := prop.Nullable || !isRequired(sc, field)
+			fmt.Fprintf(&b, "        '%s': %s,\n", field, dartEncode(s, prop, dartName(field), nullable))
+		}
+		b.WriteString("      };\n}\n\n")
+	}
+
+	b.WriteString(dartRuntime)
+
+	for _, op := range ops {
+		var args, named []string
+		for _, p := range op.PathArgs {
+			args = append(args, fmt.Sprintf("%s %s", dartType(p.Schema), dartName(p.Name)))
+		}
+		body := ""
+		switch {
+		case op.Body != nil:
+			args = append(args, fmt.Sprintf("%s body", dartNullable(op.Body)))
+			body = ", body: " + dartEncode(s, op.Body, "body", op.Body.Nullable)
+		case op.Multipart:
+			named = append(named, "Map<String, String> fields = const {}", "List<http.MultipartFile> files = const []")
+			body = ", fields: fields, files: files"
+		}
+		query := ""
+		if len(op.Query) > 0 {
+			var entries []string
+			for _, p := range op.Query {
+				if p.Required {
+					named = append(named, fmt.Sprintf("required %s %s", dartType(p.Schema), dartName(p.Name)))
+				} else {
+					named = append(named, fmt.Sprintf("%s? %s", dartType(p.Schema), dartName(p.Name)))
+				}
+				entries = append(entries, fmt.Sprintf("'%s': %s", p.Name, dartName(p.Name)))
+			}
+			query = ", query: {" + strings.Join(entries, ", ") + "}"
+		}
+		if len(named) > 0 {
+			args = append(args, "{"+strings.Join(named, ", ")+"}")
+		}
+
+		path := op.Path
+		for _, p := range op.PathArgs {
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", "${Uri.encodeComponent("+dartName(p.Name)+".toString())}")
+		}
+
+		result := "void"
+		if op.Response != nil {
+			result = dartNullable(op.Response)
+		}
+
+		b.WriteString("\n")
+		if op.Summary != "" {
+			fmt.Fprintf(&b, "  /// %s\n", op.Summary)
+		}
+		fmt.Fprintf(&b, "  Future<%s> %s(%s) async {\n", result, op.Name, strings.Join(args, ", "))
+		call := fmt.Sprintf("_request('%s', '%s'%s%s)", op.Method, path, query, body)
+		if op.Response == nil {
+			fmt.Fprintf(&b, "    await %s;\n", call)
+		} else {
+			fmt.Fprintf(&b, "    final data = await %s;\n", call)
+			fmt.Fprintf(&b, "    return %s;\n", dartDecode(s, op.Response, "data", op.Response.Nullable))
+		}
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dartRuntime unwraps the {success, data, error} envelope of pkg/response.
+const dartRuntime = `class ApiException implements Exception {
+  final int status;
+  final String code;
+  final String message;
+  final Map<String, dynamic>? details;
+
+  ApiException(this.status, this.code, this.message, [this.details]);
+
+  @override
+  String toString() => 'ApiException($status, $code): $message';
+}
+
+class ApiClient {
+  ApiClient({required this.baseUrl, this.token, http.Client? httpClient}) : _http = httpClient ?? http.Client();
+
+  final String baseUrl;
+  final Future<String?> Function()? token;
+  final http.Client _http;
+
+  Future<dynamic> _request(
+    String method,
+    String path, {
+    Map<String, Object?>? query,
+    Object? body,
+    Map<String, String>? fields,
+    List<http.MultipartFile>? files,
+  }) async {
+    final params = <String, String>{
+      for (final entry in (query ?? const <String, Object?>{}).entries)
+        if (entry.value != null) entry.key: entry.value.toString(),
+    };
+    final base = baseUrl.endsWith('/') ? baseUrl.substring(0, baseUrl.length - 1) : baseUrl;
+    final uri = Uri.parse(base + path).replace(queryParameters: params.isEmpty ? null : params);
+
+    final http.BaseRequest request;
+    if (fields != null || files != null) {
+      request = http.MultipartRequest(method, uri)
+        ..fields.addAll(fields ?? const {})
+        ..files.addAll(files ?? const []);
+    } else {
+      final jsonRequest = http.Request(method, uri);
+      if (body != null) {
+        jsonRequest.headers['Content-Type'] = 'application/json';
+        jsonRequest.body = jsonEncode(body);
+      }
+      request = jsonRequest;
+    }
+    request.headers['Accept'] = 'application/json';
+    final bearer = await token?.call();
+    if (bearer != null) {
+      request.headers['Authorization'] = 'Bearer $bearer';
+    }
+
+    final response = await http.Response.fromStream(await _http.send(request));
+    final envelope = response.body.isEmpty ? null : jsonDecode(response.body);
+    if (response.statusCode >= 400 || (envelope is Map && envelope['success'] == false)) {
+      final error = envelope is Map ? envelope['error'] as Map<String, dynamic>? : null;
+      throw ApiException(
+        response.statusCode,
+        error?['code'] as String? ?? 'UNKNOWN',
+        error?['message'] as String? ?? response.reasonPhrase ?? '',
+        error?['details'] as Map<String, dynamic>?,
+      );
+    }
+    return envelope is Map ? envelope['data'] : envelope;
+  }
+`
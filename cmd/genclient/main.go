@@ -0,0 +1,96 @@
+// Command genclient generates typed TypeScript and Dart API clients from the
+// OpenAPI spec, so the web and Flutter apps stay in sync with the handler DTOs.
+//
+// Response schemas describe the data field of the pkg/response envelope; the
+// generated clients unwrap it and raise ApiError/ApiException on failures.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const packageName = "uwu_api_client"
+
+func main() {
+	var (
+		specPath string
+		outDir   string
+		langs    string
+	)
+
+	flag.StringVar(&specPath, "spec", "api/openapi.json", "Path to the OpenAPI 3 spec (JSON)")
+	flag.StringVar(&outDir, "out", "bin/clients", "Directory the client packages are written to")
+	flag.StringVar(&langs, "lang", "ts,dart", "Comma separated client languages: ts, dart")
+	flag.Parse()
+
+	s, err := loadSpec(specPath)
+	if err != nil {
+		log.Fatalf("Failed to load spec: %v", err)
+	}
+	ops, err := s.operations()
+	if err != nil {
+		log.Fatalf("Failed to read operations: %v", err)
+	}
+
+	version := s.Info.Version
+	if version == "" {
+		version = "0.0.0"
+	}
+	source := filepath.ToSlash(specPath)
+
+	for _, lang := range strings.Split(langs, ",") {
+		var files map[string]string
+		switch strings.TrimSpace(lang) {
+		case "ts":
+			files = map[string]string{
+				"typescript/src/index.ts": generateTypeScript(s, ops, source),
+				"typescript/package.json": fmt.Sprintf(tsPackageJSON, strings.ReplaceAll(packageName, "_", "-"), version),
+			}
+		case "dart":
+			files = map[string]string{
+				"dart/lib/" + packageName + ".dart": generateDart(s, ops, source),
+				"dart/pubspec.yaml":                 fmt.Sprintf(dartPubspec, packageName, version),
+			}
+		default:
+			log.Fatalf("Unknown language %q", lang)
+		}
+
+		for name, content := range files {
+			path := filepath.Join(outDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				log.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				log.Fatalf("Failed to write %s: %v", path, err)
+			}
+			fmt.Printf("WROTE %s\n", path)
+		}
+	}
+
+	fmt.Printf("Done. operations=%d schemas=%d version=%s\n", len(ops), len(s.Components.Schemas), version)
+}
+
+const tsPackageJSON = `{
+  "name": "%s",
+  "version": "%s",
+  "private": true,
+  "main": "src/index.ts",
+  "types": "src/index.ts"
+}
+`
+
+const dartPubspec = `name: %s
+version: %s
+publish_to: none
+
+environment:
+  sdk: ">=3.0.0 <4.0.0"
+
+dependencies:
+  http: ^1.2.0
+`
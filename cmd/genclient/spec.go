@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// spec is the subset of an OpenAPI 3 document the generators understand.
+type spec struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Nullable    bool               `json:"nullable"`
+	Enum        []string           `json:"enum"`
+	Items       *schema            `json:"items"`
+	Properties  map[string]*schema `json:"properties"`
+	Required    []string           `json:"required"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type operationDoc struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"responses"`
+}
+
+// operation is one endpoint, flattened for the generators.
+type operation struct {
+	Name      string
+	Method    string
+	Path      string
+	Summary   string
+	PathArgs  []parameter
+	Query     []parameter
+	Body      *schema // JSON request body
+	Multipart bool    // multipart/form-data request body
+	Response  *schema // the envelope's data field, nil when the endpoint returns none
+}
+
+var httpMethods = []string{"get", "post", "put", "patch", "delete"}
+
+func loadSpec(path string) (*spec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s spec
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// operations returns every endpoint sorted by path then method, so output is stable.
+func (s *spec) operations() ([]operation, error) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []operation
+	for _, path := range paths {
+		var shared []parameter
+		if raw, ok := s.Paths[path]["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("%s parameters: %w", path, err)
+			}
+		}
+
+		for _, method := range httpMethods {
+			raw, ok := s.Paths[path][method]
+			if !ok {
+				continue
+			}
+
+			var doc operationDoc
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			op := operation{
+				Name:    doc.OperationID,
+				Method:  strings.ToUpper(method),
+				Path:    path,
+				Summary: doc.Summary,
+			}
+			if op.Name == "" {
+				op.Name = camelCase(method + "_" + path)
+			}
+
+			for _, p := range append(shared, doc.Parameters...) {
+				switch p.In {
+				case "path":
+					op.PathArgs = append(op.PathArgs, p)
+				case "query":
+					op.Query = append(op.Query, p)
+				}
+			}
+
+			// Path placeholders the spec forgot to declare are still arguments
+			for _, name := range pathPlaceholders(path) {
+				if !hasParameter(op.PathArgs, name) {
+					op.PathArgs = append(op.PathArgs, parameter{Name: name, In: "path", Required: true, Schema: &schema{Type: "string"}})
+				}
+			}
+
+			if doc.RequestBody != nil {
+				if mt, ok := doc.RequestBody.Content["application/json"]; ok {
+					op.Body = mt.Schema
+				} else if _, ok := doc.RequestBody.Content["multipart/form-data"]; ok {
+					op.Multipart = true
+				}
+			}
+
+			for _, code := range []string{"200", "201", "202"} {
+				if res, ok := doc.Responses[code]; ok {
+					if mt, ok := res.Content["application/json"]; ok {
+						op.Response = mt.Schema
+					}
+					break
+				}
+			}
+
+			ops = append(ops, op)
+		}
+	}
+	return ops, nil
+}
+
+func pathPlaceholders(path string) []string {
+	var names []string
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			names = append(names, part[1:len(part)-1])
+		}
+	}
+	return names
+}
+
+func hasParameter(params []parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaNames returns the component schema names in sorted order.
+func (s *spec) schemaNames() []string {
+	names := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// refName turns "#/components/schemas/Video" into "Video".
+func refName(ref string) string {
+	return typeName(ref[strings.LastIndex(ref, "/")+1:])
+}
+
+// typeName turns a schema name such as "video.VideoResponse" into "VideoResponse".
+func typeName(name string) string {
+	return pascalCase(name[strings.LastIndex(name, ".")+1:])
+}
+
+func isRequired(s *schema, field string) bool {
+	for _, name := range s.Required {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedProperties(s *schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// camelCase joins words split on anything that is not a letter or digit: "get_/api/v1/videos/{videoID}" -> "getApiV1VideosVideoID".
+func camelCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word[:1]) + word[1:]
+		} else {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+func pascalCase(s string) string {
+	name := camelCase(s)
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tsType returns the TypeScript type of s.
+func tsType(s *schema) string {
+	if s == nil {
+		return "unknown"
+	}
+
+	var t string
+	switch {
+	case s.Ref != "":
+		t = refName(s.Ref)
+	case len(s.Enum) > 0:
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprintf("%q", v)
+		}
+		t = strings.Join(values, " | ")
+	case s.Type == "string":
+		t = "string"
+	case s.Type == "integer" || s.Type == "number":
+		t = "number"
+	case s.Type == "boolean":
+		t = "boolean"
+	case s.Type == "array":
+		t = "Array<" + tsType(s.Items) + ">"
+	case len(s.Properties) > 0:
+		t = "{ " + strings.Join(tsFields(s), " ") + " }"
+	case s.Type == "object":
+		t = "Record<string, unknown>"
+	default:
+		t = "unknown"
+	}
+
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func tsFields(s *schema) []string {
+	var fields []string
+	for _, name := range sortedProperties(s) {
+		optional := "?"
+		if isRequired(s, name) {
+			optional = ""
+		}
+		fields = append(fields, fmt.Sprintf("%q%s: %s;", name, optional, tsType(s.Properties[name])))
+	}
+	return fields
+}
+
+func generateTypeScript(s *spec, ops []operation, source string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by cmd/genclient from %s. DO NOT EDIT.\n\n", source)
+
+	for _, name := range s.schemaNames() {
+		sc := s.Components.Schemas[name]
+		if sc.Description != "" {
+			fmt.Fprintf(&b, "/** %s */\n", sc.Description)
+		}
+		if len(sc.Properties) > 0 {
+			fmt.Fprintf(&b, "export interface %s {\n", typeName(name))
+			for _, field := range tsFields(sc) {
+				fmt.Fprintf(&b, "  %s\n", field)
+			}
+			b.WriteString("}\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "export type %s = %s;\n\n", typeName(name), tsType(sc))
+	}
+
+	b.WriteString(tsRuntime)
+
+	for _, op := range ops {
+		var args []string
+		for _, p := range op.PathArgs {
+			args = append(args, fmt.Sprintf("%s: %s", camelCase(p.Name), tsType(p.Schema)))
+		}
+		body := "undefined"
+		switch {
+		case op.Body != nil:
+			args = append(args, "body: "+tsType(op.Body))
+			body = "body"
+		case op.Multipart:
+			args = append(args, "form: FormData")
+			body = "form"
+		}
+		query := "undefined"
+		if len(op.Query) > 0 {
+			optional := "?"
+			var fields []string
+			for _, p := range op.Query {
+				marker := "?"
+				if p.Required {
+					marker, optional = "", ""
+				}
+				fields = append(fields, fmt.Sprintf("%q%s: %s;", p.Name, marker, tsType(p.Schema)))
+			}
+			args = append(args, fmt.Sprintf("query%s: { %s }", optional, strings.Join(fields, " ")))
+			query = "query"
+		}
+
+		path := op.Path
+		for _, p := range op.PathArgs {
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", "${encodeURIComponent(String("+camelCase(p.Name)+"))}")
+		}
+
+		result := "void"
+		if op.Response != nil {
+			result = tsType(op.Response)
+		}
+
+		if op.Summary != "" {
+			fmt.Fprintf(&b, "\n  /** %s */\n", op.Summary)
+		} else {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %s(%s): Promise<%s> {\n", op.Name, strings.Join(args, ", "), result)
+		fmt.Fprintf(&b, "    return this.request<%s>(%q, `%s`, %s, %s);\n", result, op.Method, path, query, body)
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// tsRuntime unwraps the {success, data, error} envelope of pkg/response.
+const tsRuntime = `export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly code: string,
+    message: string,
+    readonly details?: Record<string, unknown>,
+  ) {
+    super(message);
+  }
+}
+
+export interface ClientOptions {
+  baseUrl: string;
+  token?: () => string | undefined | Promise<string | undefined>;
+  fetch?: typeof fetch;
+}
+
+export class Client {
+  constructor(private readonly options: ClientOptions) {}
+
+  private async request<T>(method: string, path: string, query?: Record<string, unknown>, body?: unknown): Promise<T> {
+    const url = new URL(this.options.baseUrl.replace(/\/$/, "") + path);
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined && value !== null) url.searchParams.set(key, String(value));
+    }
+
+    const headers: Record<string, string> = { Accept: "application/json" };
+    const token = await this.options.token?.();
+    if (token) headers.Authorization = "Bearer " + token;
+
+    let payload: BodyInit | undefined;
+    if (body instanceof FormData) {
+      payload = body;
+    } else if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      payload = JSON.stringify(body);
+    }
+
+    const res = await (this.options.fetch ?? fetch)(url, { method, headers, body: payload });
+    const text = await res.text();
+    const envelope = text ? JSON.parse(text) : undefined;
+    if (!res.ok || envelope?.success === false) {
+      const error = envelope?.error ?? {};
+      throw new ApiError(res.status, error.code ?? "UNKNOWN", error.message ?? res.statusText, error.details);
+    }
+    return envelope?.data as T;
+  }
+`
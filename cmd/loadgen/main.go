@@ -0,0 +1,181 @@
+// Command loadgen simulates client traffic against a running environment for
+// capacity planning of the media pipeline: video uploads, dialog generation,
+// batch polling and content browsing, with latency and error reporting.
+//
+// Every upload and generation runs the real pipeline of the target, so the target
+// must run with FAKE_PROVIDERS=true: loadgen checks GET /api/v1/capabilities and
+// refuses the generate and upload scenarios when any AI provider they use is real.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func main() {
+	var (
+		opts     options
+		mix      string
+		users    int
+		duration time.Duration
+	)
+
+	flag.StringVar(&opts.target, "target", "http://localhost:8080", "Base URL of the environment under test")
+	flag.IntVar(&users, "users", 5, "Concurrent virtual users")
+	flag.DurationVar(&duration, "duration", time.Minute, "How long virtual users keep starting scenarios")
+	flag.StringVar(&mix, "mix", "browse:8,generate:1,upload:1", "Weighted scenarios: browse, generate, upload")
+	flag.StringVar(&opts.videoPath, "video", "", "Sample video for uploads (mp4 or mov, required by the upload scenario)")
+	flag.StringVar(&opts.thumbnailPath, "thumbnail", "", "Sample thumbnail for uploads (jpeg, png or webp)")
+	flag.StringVar(&opts.language, "language", "english", "Language of uploads and generated dialogs")
+	flag.StringVar(&opts.level, "level", "beginner", "Level of generated dialogs")
+	flag.DurationVar(&opts.pollInterval, "poll-interval", 2*time.Second, "Delay between batch status polls")
+	flag.DurationVar(&opts.pollTimeout, "poll-timeout", 5*time.Minute, "Longest a batch is polled before it counts as timed out")
+	flag.StringVar(&opts.password, "password", "loadgen-password", "Password of the virtual users")
+	flag.Parse()
+
+	scenarios, err := parseMix(mix)
+	if err != nil {
+		log.Fatalf("Invalid -mix: %v", err)
+	}
+	if users <= 0 {
+		log.Fatalf("-users must be positive")
+	}
+	for _, s := range scenarios {
+		if s.name == scenarioUpload && (opts.videoPath == "" || opts.thumbnailPath == "") {
+			log.Fatalf("The upload scenario needs -video and -thumbnail")
+		}
+	}
+	if err := opts.loadMedia(); err != nil {
+		log.Fatalf("Failed to read sample media: %v", err)
+	}
+	opts.target = strings.TrimRight(opts.target, "/")
+	if err := requireFakeProviders(opts.target, scenarios); err != nil {
+		log.Fatalf("Refusing to run: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stats := newStats()
+	run := fmt.Sprintf("%d", time.Now().Unix())
+
+	fmt.Printf("Load test: target=%s users=%d duration=%s mix=%s\n", opts.target, users, duration, mix)
+	started := time.Now()
+	deadline := started.Add(duration)
+
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			u := newVirtualUser(opts, stats)
+			email := fmt.Sprintf("loadgen-%s-%d@example.com", run, i)
+			if err := u.signUp(ctx, email); err != nil {
+				log.Printf("User %d could not sign up: %v", i, err)
+				return
+			}
+
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+			for ctx.Err() == nil && time.Now().Before(deadline) {
+				u.run(ctx, pickScenario(scenarios, rng))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats.print(os.Stdout, time.Since(started))
+}
+
+// options are shared by every virtual user.
+type options struct {
+	target        string
+	videoPath     string
+	thumbnailPath string
+	language      string
+	level         string
+	pollInterval  time.Duration
+	pollTimeout   time.Duration
+	password      string
+
+	video     []byte
+	thumbnail []byte
+}
+
+// loadMedia reads the sample upload files once so each upload only sends them.
+func (o *options) loadMedia() error {
+	var err error
+	if o.videoPath != "" {
+		if o.video, err = os.ReadFile(o.videoPath); err != nil {
+			return err
+		}
+	}
+	if o.thumbnailPath != "" {
+		if o.thumbnail, err = os.ReadFile(o.thumbnailPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Capabilities each scenario spends AI calls on
+var scenarioCapabilities = map[string][]string{
+	scenarioGenerate: {"chat", "tts", "image_generation"},
+	scenarioUpload:   {"stt", "chat"},
+}
+
+// requireFakeProviders checks that every capability the scenarios use is served by the target's
+// fake providers only (FAKE_PROVIDERS=true), so a run never spends on real AI calls.
+func requireFakeProviders(target string, scenarios []scenario) error {
+	var needed []string
+	for _, s := range scenarios {
+		needed = append(needed, scenarioCapabilities[s.name]...)
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Get(target + "/api/v1/capabilities")
+	if err != nil {
+		return fmt.Errorf("could not read the capabilities of the target: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not read the capabilities of the target: status %d", resp.StatusCode)
+	}
+
+	var env envelope
+	var capabilities []struct {
+		Name      string   `json:"name"`
+		Providers []string `json:"providers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("could not read the capabilities of the target: %v", err)
+	}
+	if err := json.Unmarshal(env.Data, &capabilities); err != nil {
+		return fmt.Errorf("could not read the capabilities of the target: %v", err)
+	}
+
+	providers := make(map[string][]string, len(capabilities))
+	for _, c := range capabilities {
+		providers[c.Name] = c.Providers
+	}
+	for _, name := range needed {
+		if p := providers[name]; len(p) != 1 || p[0] != "fake" {
+			return fmt.Errorf("%s on the target uses %v; run the target with FAKE_PROVIDERS=true for the generate and upload scenarios", name, p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scenarios a virtual user can run
+const (
+	scenarioBrowse   = "browse"   // list videos and dialogs
+	scenarioGenerate = "generate" // generate a dialog and poll its batch
+	scenarioUpload   = "upload"   // upload a video and poll its processing
+)
+
+// Batch statuses that end polling
+const (
+	batchCompleted = "completed"
+	batchFailed    = "failed"
+)
+
+type scenario struct {
+	name   string
+	weight int
+}
+
+// parseMix reads "name:weight,name:weight".
+func parseMix(mix string) ([]scenario, error) {
+	var scenarios []scenario
+	for _, entry := range strings.Split(mix, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("%q must be name:weight", entry)
+		}
+		switch name {
+		case scenarioBrowse, scenarioGenerate, scenarioUpload:
+		default:
+			return nil, fmt.Errorf("unknown scenario %q", name)
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight of %q must be a non-negative integer", name)
+		}
+		if w > 0 {
+			scenarios = append(scenarios, scenario{name: name, weight: w})
+		}
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenario has a weight")
+	}
+	return scenarios, nil
+}
+
+func pickScenario(scenarios []scenario, rng *rand.Rand) string {
+	total := 0
+	for _, s := range scenarios {
+		total += s.weight
+	}
+	n := rng.Intn(total)
+	for _, s := range scenarios {
+		if n < s.weight {
+			return s.name
+		}
+		n -= s.weight
+	}
+	return scenarios[len(scenarios)-1].name
+}
+
+// envelope is the pkg/response body.
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Meta    json.RawMessage `json:"meta"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// processing is the part of the batch meta loadgen needs.
+type processing struct {
+	BatchID string `json:"batch_id"`
+	Status  string `json:"status"`
+}
+
+// virtualUser is one signed-up client running scenarios back to back.
+type virtualUser struct {
+	opts  options
+	stats *stats
+	http  *http.Client
+	token string
+}
+
+func newVirtualUser(opts options, stats *stats) *virtualUser {
+	return &virtualUser{
+		opts:  opts,
+		stats: stats,
+		http:  &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// signUp registers the user (emails are unique per run).
+func (u *virtualUser) signUp(ctx context.Context, email string) error {
+	body, _ := json.Marshal(map[string]string{
+		"email":        email,
+		"password":     u.opts.password,
+		"display_name": "loadgen",
+	})
+
+	env, _, err := u.call(ctx, "register", http.MethodPost, "/api/v1/auth/register", "application/json", body, nil)
+	if err != nil {
+		return err
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(env.Data, &auth); err != nil || auth.Token == "" {
+		return fmt.Errorf("no token in auth response")
+	}
+	u.token = auth.Token
+	return nil
+}
+
+func (u *virtualUser) run(ctx context.Context, name string) {
+	switch name {
+	case scenarioBrowse:
+		u.browse(ctx)
+	case scenarioGenerate:
+		u.generate(ctx)
+	case scenarioUpload:
+		u.upload(ctx)
+	}
+}
+
+func (u *virtualUser) browse(ctx context.Context) {
+	u.call(ctx, "list_videos", http.MethodGet, "/api/v1/videos/contents", "", nil, nil)
+	u.call(ctx, "list_dialogs", http.MethodGet, "/api/v1/dialogs/contents", "", nil, nil)
+}
+
+func (u *virtualUser) generate(ctx context.Context) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"topic":       "Ordering coffee",
+		"description": "A short conversation at a cafe (load test)",
+		"language":    u.opts.language,
+		"level":       u.opts.level,
+		"tags":        []string{"loadgen"},
+	})
+
+	started := time.Now()
+	env, _, err := u.call(ctx, "generate_dialog", http.MethodPost, "/api/v1/dialogs/generate", "application/json", body, nil)
+	if err != nil {
+		return
+	}
+	id, meta, ok := accepted(env)
+	if !ok {
+		u.stats.fail("generate_dialog", "bad_response")
+		return
+	}
+
+	u.poll(ctx, "dialog_pipeline", started, func() (string, bool) {
+		env, _, err := u.call(ctx, "poll_dialog", http.MethodGet, "/api/v1/dialogs/"+id+"/batches/"+meta.BatchID, "", nil, nil)
+		if err != nil {
+			return "", false
+		}
+		var p processing
+		if json.Unmarshal(env.Data, &p) != nil {
+			return "", false
+		}
+		return p.Status, true
+	})
+}
+
+func (u *virtualUser) upload(ctx context.Context) {
+	body, contentType, err := u.uploadForm()
+	if err != nil {
+		u.stats.fail("upload_video", "client_error")
+		return
+	}
+
+	started := time.Now()
+	env, _, err := u.call(ctx, "upload_video", http.MethodPost, "/api/v1/videos/upload", contentType, body, map[string]string{
+		"Language": u.opts.language,
+	})
+	if err != nil {
+		return
+	}
+	id, _, ok := accepted(env)
+	if !ok {
+		u.stats.fail("upload_video", "bad_response")
+		return
+	}
+
+	u.poll(ctx, "video_pipeline", started, func() (string, bool) {
+		env, _, err := u.call(ctx, "poll_video", http.MethodGet, "/api/v1/videos/"+id+"/details", "", nil, nil)
+		if err != nil {
+			return "", false
+		}
+		var p processing
+		if json.Unmarshal(env.Meta, &p) != nil {
+			return "", false
+		}
+		return p.Status, true
+	})
+}
+
+// poll checks a batch until it completes, fails or times out, and records how long the
+// whole pipeline took from the request that started it.
+func (u *virtualUser) poll(ctx context.Context, op string, started time.Time, check func() (string, bool)) {
+	deadline := time.Now().Add(u.opts.pollTimeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(u.opts.pollInterval):
+		}
+
+		if status, ok := check(); ok {
+			switch status {
+			case batchCompleted:
+				u.stats.record(op, time.Since(started), "")
+				return
+			case batchFailed:
+				u.stats.record(op, time.Since(started), batchFailed)
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			u.stats.record(op, time.Since(started), "timeout")
+			return
+		}
+	}
+}
+
+func (u *virtualUser) uploadForm() ([]byte, string, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	files := []struct {
+		field, path string
+		data        []byte
+	}{
+		{"video", u.opts.videoPath, u.opts.video},
+		{"thumbnail", u.opts.thumbnailPath, u.opts.thumbnail},
+	}
+	for _, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, f.field, filepath.Base(f.path)))
+		if contentType := mediaType(f.path); contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, err := form.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(f.data); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), form.FormDataContentType(), nil
+}
+
+func mediaType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4":
+		return "video/mp4"
+	case ".mov":
+		return "video/quicktime"
+	case ".webm":
+		return "video/webm"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".webp":
+		return "image/webp"
+	}
+	return ""
+}
+
+// accepted reads the item id and batch meta of a 202 response.
+func accepted(env *envelope) (string, processing, bool) {
+	var item struct {
+		ID string `json:"id"`
+	}
+	var meta processing
+	if json.Unmarshal(env.Data, &item) != nil || item.ID == "" {
+		return "", meta, false
+	}
+	_ = json.Unmarshal(env.Meta, &meta)
+	return item.ID, meta, true
+}
+
+// call sends one request and records its latency under op. Non-2xx responses are errors
+// labelled with their status code.
+func (u *virtualUser) call(ctx context.Context, op, method, path, contentType string, body []byte, headers map[string]string) (*envelope, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.opts.target+path, bytes.NewReader(body))
+	if err != nil {
+		u.stats.fail(op, "client_error")
+		return nil, 0, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if u.token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.token)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	started := time.Now()
+	resp, err := u.http.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			u.stats.record(op, time.Since(started), "network")
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	elapsed := time.Since(started)
+	if err != nil {
+		u.stats.record(op, elapsed, "network")
+		return nil, resp.StatusCode, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		u.stats.record(op, elapsed, strconv.Itoa(resp.StatusCode))
+		return nil, resp.StatusCode, fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+	}
+	u.stats.record(op, elapsed, "")
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("%s %s: %v", method, path, err)
+	}
+	return &env, resp.StatusCode, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// stats collects latencies and errors per operation.
+type stats struct {
+	mu  sync.Mutex
+	ops map[string]*opStats
+}
+
+type opStats struct {
+	latencies []time.Duration
+	errors    map[string]int // by label: status code, network, timeout, failed, ...
+}
+
+func newStats() *stats {
+	return &stats{ops: make(map[string]*opStats)}
+}
+
+func (s *stats) get(op string) *opStats {
+	o, ok := s.ops[op]
+	if !ok {
+		o = &opStats{errors: make(map[string]int)}
+		s.ops[op] = o
+	}
+	return o
+}
+
+// record adds one finished operation; errLabel is empty when it succeeded.
+func (s *stats) record(op string, latency time.Duration, errLabel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.get(op)
+	o.latencies = append(o.latencies, latency)
+	if errLabel != "" {
+		o.errors[errLabel]++
+	}
+}
+
+// fail counts an operation that failed before it had a meaningful latency.
+func (s *stats) fail(op, errLabel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(op).errors[errLabel]++
+}
+
+// print writes one row per operation: throughput, error rate and latency percentiles.
+func (s *stats) print(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.ops))
+	for name := range s.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tCOUNT\tRPS\tERRORS\tP50\tP95\tP99\tMAX\tERROR BREAKDOWN")
+	for _, name := range names {
+		o := s.ops[name]
+		sorted := append([]time.Duration(nil), o.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		failed := 0
+		labels := make([]string, 0, len(o.errors))
+		for label, n := range o.errors {
+			failed += n
+			labels = append(labels, fmt.Sprintf("%s=%d", label, n))
+		}
+		sort.Strings(labels)
+
+		count := len(sorted)
+		if count < failed {
+			count = failed
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			name, count, float64(len(sorted))/elapsed.Seconds(), failed,
+			percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 99), percentile(sorted, 100),
+			strings.Join(labels, " "))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "Done. elapsed=%s\n", elapsed.Round(time.Millisecond))
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p int) string {
+	if len(sorted) == 0 {
+		return "-"
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1].Round(time.Millisecond).String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/windfall/uwu_service/internal/config"
+	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/domain/video"
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// normalizers re-encode details into the typed schema of each feature
+var normalizers = map[int]func(json.RawMessage) (json.RawMessage, error){
+	video.FeatureID:  video.NormalizeDetails,
+	dialog.FeatureID: dialog.NormalizeDetails,
+}
+
+func main() {
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dry-run", false, "Report rows that would change without updating them")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := client.NewPostgresClient(ctx, cfg.DatabaseURL(), nil)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Pool.Query(ctx, `SELECT id::text, feature_id, details FROM learning_items ORDER BY created_at`)
+	if err != nil {
+		log.Fatalf("Failed to list learning items: %v", err)
+	}
+
+	type item struct {
+		id        string
+		featureID int
+		details   json.RawMessage
+	}
+	var items []item
+	for rows.Next() {
+		var it item
+		if err := rows.Scan(&it.id, &it.featureID, &it.details); err != nil {
+			log.Fatalf("Failed to scan learning item: %v", err)
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+
+	var changed, skipped, failed int
+	for _, it := range items {
+		normalize, ok := normalizers[it.featureID]
+		if !ok {
+			skipped++
+			continue
+		}
+
+		normalized, err := normalize(it.details)
+		if err != nil {
+			// Not decodable into the schema: needs a manual fix
+			fmt.Printf("FAILED  %s (feature %d): %v\n", it.id, it.featureID, err)
+			failed++
+			continue
+		}
+		if sameJSON(it.details, normalized) {
+			continue
+		}
+
+		changed++
+		if dryRun {
+			fmt.Printf("CHANGE  %s (feature %d)\n", it.id, it.featureID)
+			continue
+		}
+		if _, err := db.Pool.Exec(ctx,
+			`UPDATE learning_items SET details = $1, version = version + 1, updated_at = NOW() WHERE id = $2`,
+			normalized, it.id,
+		); err != nil {
+			log.Fatalf("Failed to update %s: %v", it.id, err)
+		}
+		fmt.Printf("UPDATED %s (feature %d)\n", it.id, it.featureID)
+	}
+
+	fmt.Printf("Done. total=%d changed=%d failed=%d skipped=%d dry_run=%v\n", len(items), changed, failed, skipped, dryRun)
+}
+
+// sameJSON compares two JSON documents ignoring key order and formatting.
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,13 @@
+// Package apitest runs the HTTP API end to end: the router of internal/app with its real handlers,
+// services, repositories and queue workers, FakeProviders in place of every AI provider and an
+// in-memory S3 server in place of R2. Postgres and Redis stay real; the tests take them from
+// APITEST_DATABASE_URL and APITEST_REDIS_URL (see `make apitest`) and skip when either is unset.
+//
+// The tests cover video upload → batch → gist quiz, dialog generation → batch → details and
+// speaking practice on a generated dialog: a spoken turn analyzed against its script and a chat
+// message replied to by the worker. The upload and spoken turn are skipped without ffmpeg. Workout
+// generation is out of scope: the service has no such endpoint.
+//
+// The deterministic part of each response is compared with testdata/*.golden; run
+// go test -tags fake ./internal/apitest -update to rewrite them after an intended change.
+package apitest
@@ -0,0 +1,387 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestVideoUploadQuiz uploads a video, waits for its pipeline and takes the gist quiz of the fake
+// video details.
+func TestVideoUploadQuiz(t *testing.T) {
+	h := start(t)
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg is not installed (the upload pipeline extracts the audio with it)")
+	}
+	u := h.signUp(t)
+
+	// 1. upload
+	env := u.call(t, http.MethodPost, "/api/v1/videos/upload", http.StatusAccepted, uploadForm(t))
+	var video struct {
+		ID string `json:"id"`
+	}
+	decode(t, env.Data, &video)
+
+	// 2. wait for the batch
+	waitForBatch(t, func() processing {
+		env := u.call(t, http.MethodGet, "/api/v1/videos/"+video.ID+"/details", http.StatusOK, nil)
+		var batch processing
+		decode(t, env.Meta, &batch)
+		return batch
+	})
+
+	env = u.call(t, http.MethodGet, "/api/v1/videos/"+video.ID+"/details", http.StatusOK, nil)
+	var item struct {
+		Details struct {
+			Topic    string `json:"topic"`
+			VideoURL string `json:"video_url"`
+			Segments []struct {
+				Text string `json:"text"`
+			} `json:"segments"`
+			GistQuiz []struct {
+				ID int `json:"id"`
+			} `json:"gist_quiz"`
+		} `json:"details"`
+	}
+	decode(t, env.Data, &item)
+	if !h.r2.has(item.Details.VideoURL) {
+		t.Errorf("video %q was not uploaded to R2", item.Details.VideoURL)
+	}
+
+	// 3. take the quiz
+	u.call(t, http.MethodPost, "/api/v1/videos/"+video.ID+"/start-quiz", http.StatusOK, nil)
+	env = u.call(t, http.MethodPost, "/api/v1/videos/"+video.ID+"/submit-quiz", http.StatusOK, jsonBody(t, map[string]any{
+		"answers": []map[string]any{
+			{"quiz_id": 1, "type": "multiple_response", "option_ids": []string{"A"}},
+			{"quiz_id": 2, "type": "single_choice", "option_ids": []string{"B"}},
+			{"quiz_id": 3, "type": "ordering", "order": []string{"B", "D", "C", "A"}},
+		},
+	}))
+	var attempt struct {
+		AttemptID string  `json:"attempt_id"`
+		QuizScore float64 `json:"quiz_score"`
+	}
+	decode(t, env.Data, &attempt)
+
+	env = u.call(t, http.MethodPost, "/api/v1/videos/"+video.ID+"/start-quiz", http.StatusOK, nil)
+	var quiz struct {
+		Attempts []struct {
+			AttemptID string `json:"attempt_id"`
+		} `json:"attempts"`
+	}
+	decode(t, env.Data, &quiz)
+	if len(quiz.Attempts) == 0 || quiz.Attempts[0].AttemptID != attempt.AttemptID {
+		t.Errorf("quiz attempts = %+v, want the submitted attempt %s", quiz.Attempts, attempt.AttemptID)
+	}
+
+	summary := struct {
+		Topic     string   `json:"topic"`
+		Segments  []string `json:"segments"`
+		GistQuiz  []int    `json:"gist_quiz"`
+		QuizScore float64  `json:"quiz_score"`
+		Attempts  int      `json:"attempts"`
+	}{
+		Topic:     item.Details.Topic,
+		QuizScore: attempt.QuizScore,
+		Attempts:  len(quiz.Attempts),
+	}
+	for _, segment := range item.Details.Segments {
+		summary.Segments = append(summary.Segments, segment.Text)
+	}
+	for _, question := range item.Details.GistQuiz {
+		summary.GistQuiz = append(summary.GistQuiz, question.ID)
+	}
+	golden(t, "video_upload_quiz", summary)
+}
+
+// TestDialogGenerate generates a dialog, waits for its batch and reads the saved dialog.
+func TestDialogGenerate(t *testing.T) {
+	h := start(t)
+	u := h.signUp(t)
+	dialogID := generateDialog(t, u)
+
+	env := u.call(t, http.MethodGet, "/api/v1/dialogs/"+dialogID+"/details", http.StatusOK, nil)
+	var item struct {
+		IsActive bool `json:"is_active"`
+		Details  struct {
+			Description string `json:"description"`
+			ImageURL    string `json:"image_url"`
+			SpeechMode  struct {
+				Script []struct {
+					Speaker  string  `json:"speaker"`
+					Text     string  `json:"text"`
+					AudioURL *string `json:"audio_url"`
+				} `json:"script"`
+			} `json:"speech_mode"`
+			UserTurnCount int `json:"user_turn_count"`
+			Quality       *struct {
+				Naturalness int `json:"naturalness"`
+				Correctness int `json:"correctness"`
+			} `json:"quality"`
+		} `json:"details"`
+	}
+	decode(t, env.Data, &item)
+	for i, turn := range item.Details.SpeechMode.Script {
+		if turn.AudioURL == nil || !h.r2.has(*turn.AudioURL) {
+			t.Errorf("turn %d has no uploaded audio", i)
+		}
+	}
+	if !h.r2.has(item.Details.ImageURL) {
+		t.Errorf("image %q was not uploaded to R2", item.Details.ImageURL)
+	}
+
+	type turn struct {
+		Speaker string `json:"speaker"`
+		Text    string `json:"text"`
+	}
+	summary := struct {
+		IsActive      bool   `json:"is_active"`
+		Description   string `json:"description"`
+		Script        []turn `json:"script"`
+		UserTurnCount int    `json:"user_turn_count"`
+		Quality       any    `json:"quality"`
+	}{
+		IsActive:      item.IsActive,
+		Description:   item.Details.Description,
+		UserTurnCount: item.Details.UserTurnCount,
+		Quality:       item.Details.Quality,
+	}
+	for _, script := range item.Details.SpeechMode.Script {
+		summary.Script = append(summary.Script, turn{Speaker: script.Speaker, Text: script.Text})
+	}
+	golden(t, "dialog_generate", summary)
+}
+
+// TestDialogSpeaking practices a generated dialog: one spoken turn analyzed against its script
+// (skipped without ffmpeg, which checks the recording) and one chat message replied to by the
+// worker.
+func TestDialogSpeaking(t *testing.T) {
+	h := start(t)
+	u := h.signUp(t)
+	dialogID := generateDialog(t, u)
+
+	t.Run("speech", func(t *testing.T) {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			t.Skip("ffmpeg is not installed (speech analysis checks the recording with it)")
+		}
+		acceptConsent(t, u, "recording")
+
+		// 1. start, then speak the first user turn
+		u.call(t, http.MethodPost, "/api/v1/dialogs/"+dialogID+"/start-speech", http.StatusCreated, nil)
+		env := u.call(t, http.MethodPost, "/api/v1/dialogs/"+dialogID+"/submit-speech", http.StatusCreated,
+			speechForm(t, 1, "I would like a coffee, please."))
+
+		// 2. read the evaluation
+		var speech struct {
+			Turn    int `json:"turn"`
+			Scripts []struct {
+				Evaluation *struct {
+					AccuracyScore     float64 `json:"accuracy_score"`
+					FluencyScore      float64 `json:"fluency_score"`
+					PronScore         float64 `json:"pron_score"`
+					CompletenessScore float64 `json:"completeness_score"`
+					DisplayText       string  `json:"display_text"`
+					Duration          int     `json:"duration"`
+					Words             []struct {
+						Word          string  `json:"Word"`
+						AccuracyScore float64 `json:"AccuracyScore"`
+						ErrorType     string  `json:"ErrorType"`
+					} `json:"words"`
+				} `json:"evaluation"`
+			} `json:"scripts"`
+		}
+		decode(t, env.Data, &speech)
+		if len(speech.Scripts) < 2 || speech.Scripts[1].Evaluation == nil {
+			t.Fatalf("got %d scripts, want the evaluation of script 1", len(speech.Scripts))
+		}
+		golden(t, "dialog_speech", struct {
+			Turn       int `json:"turn"`
+			Evaluation any `json:"evaluation"`
+		}{speech.Turn, speech.Scripts[1].Evaluation})
+	})
+
+	t.Run("chat", func(t *testing.T) {
+		// 1. start, then send a message
+		u.call(t, http.MethodPost, "/api/v1/dialogs/"+dialogID+"/start-chat", http.StatusCreated, nil)
+		u.call(t, http.MethodPost, "/api/v1/dialogs/"+dialogID+"/submit-chat", http.StatusAccepted, jsonBody(t, map[string]string{
+			"message": "What food do you have?",
+		}))
+
+		// 2. wait for the reply
+		var chat struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+			CompletedObjectives []string `json:"completed_objectives"`
+			Status              string   `json:"status"`
+		}
+		waitForBatch(t, func() processing {
+			env := u.call(t, http.MethodGet, "/api/v1/dialogs/"+dialogID+"/submit-chat", http.StatusOK, nil)
+			decode(t, env.Data, &chat)
+			return processing{BatchID: "chat " + dialogID, Status: chat.Status}
+		})
+		golden(t, "dialog_chat", chat)
+	})
+}
+
+// generateDialog generates a dialog as the user and waits for its batch.
+func generateDialog(t *testing.T, u *user) string {
+	t.Helper()
+
+	env := u.call(t, http.MethodPost, "/api/v1/dialogs/generate", http.StatusAccepted, jsonBody(t, map[string]any{
+		"topic":       "Ordering coffee",
+		"description": "A short conversation at a cafe",
+		"language":    "english",
+		"level":       "beginner",
+		"tags":        []string{"apitest"},
+	}))
+	var dialog struct {
+		ID string `json:"id"`
+	}
+	var accepted processing
+	decode(t, env.Data, &dialog)
+	decode(t, env.Meta, &accepted)
+
+	waitForBatch(t, func() processing {
+		env := u.call(t, http.MethodGet, "/api/v1/dialogs/"+dialog.ID+"/batches/"+accepted.BatchID, http.StatusOK, nil)
+		var batch processing
+		decode(t, env.Data, &batch)
+		return batch
+	})
+	return dialog.ID
+}
+
+// acceptConsent accepts the current version of the consentType terms as the user.
+func acceptConsent(t *testing.T, u *user, consentType string) {
+	t.Helper()
+
+	env := u.call(t, http.MethodGet, "/api/v1/consents", http.StatusOK, nil)
+	var terms []struct {
+		Type    string `json:"type"`
+		Version string `json:"version"`
+	}
+	decode(t, env.Data, &terms)
+	for _, term := range terms {
+		if term.Type == consentType {
+			u.call(t, http.MethodPost, "/api/v1/consents/"+consentType+"/accept", http.StatusOK, jsonBody(t, map[string]string{
+				"version": term.Version,
+			}))
+			return
+		}
+	}
+	t.Fatalf("no %q terms in %s", consentType, env.Data)
+}
+
+// speechForm builds the submit-speech body: a second of silence as 16 kHz mono WAV spoken for the
+// script at index.
+func speechForm(t *testing.T, index int, referenceText string) *request {
+	t.Helper()
+
+	const sampleRate = 16000
+	samples := make([]byte, sampleRate*2)
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	binary.Write(&wav, binary.LittleEndian, uint32(36+len(samples)))
+	wav.WriteString("WAVEfmt ")
+	binary.Write(&wav, binary.LittleEndian, []any{
+		uint32(16), uint16(1), uint16(1), uint32(sampleRate), uint32(sampleRate * 2), uint16(2), uint16(16),
+	})
+	wav.WriteString("data")
+	binary.Write(&wav, binary.LittleEndian, uint32(len(samples)))
+	wav.Write(samples)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="audio"; filename="speech.wav"`)
+	header.Set("Content-Type", "audio/wav")
+	part, err := form.CreatePart(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(wav.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{"reference_text": referenceText, "script_index": strconv.Itoa(index)}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &request{
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+		headers:     map[string]string{"Language": "english"},
+	}
+}
+
+// uploadForm builds the upload body: a two-second video made with ffmpeg and a PNG thumbnail.
+func uploadForm(t *testing.T) *request {
+	t.Helper()
+
+	videoPath := filepath.Join(t.TempDir(), "sample.mp4")
+	output, err := exec.Command("ffmpeg", "-y",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=2",
+		"-f", "lavfi", "-i", "color=c=gray:size=64x64:duration=2",
+		"-shortest", "-c:v", "mpeg4", "-c:a", "aac",
+		videoPath,
+	).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to make the sample video: %v: %s", err, output)
+	}
+	video, err := os.ReadFile(videoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var thumbnail bytes.Buffer
+	if err := png.Encode(&thumbnail, image.NewGray(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	files := []struct {
+		field, filename, contentType string
+		data                         []byte
+	}{
+		{"video", "sample.mp4", "video/mp4", video},
+		{"thumbnail", "sample.png", "image/png", thumbnail.Bytes()},
+	}
+	for _, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, f.field, f.filename))
+		header.Set("Content-Type", f.contentType)
+		part, err := form.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &request{
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+		headers:     map[string]string{"Language": "english"},
+	}
+}
@@ -0,0 +1,45 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites the golden files instead of comparing against them:
+// go test -tags fake ./internal/apitest -update (or make apitest ARGS=-update).
+var update = flag.Bool("update", false, "rewrite the golden files under testdata with the current responses")
+
+// golden compares v, as indented JSON, with testdata/<name>.golden. Only the deterministic part of
+// a response goes in a golden file; IDs, timestamps and URLs are checked inline by the tests.
+func golden(t *testing.T, name string, v any) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode %s: %v", name, err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s (run with -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match %s (run with -update if the change is intended):\ngot:\n%s\nwant:\n%s", name, path, got, want)
+	}
+}
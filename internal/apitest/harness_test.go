@@ -0,0 +1,304 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/app"
+	"github.com/windfall/uwu_service/internal/config"
+	"github.com/windfall/uwu_service/pkg/logger"
+)
+
+// migrationsDir is relative to this package, where go test runs.
+const migrationsDir = "../../migrations"
+
+// Batch polling
+const (
+	pollInterval = 200 * time.Millisecond
+	pollTimeout  = time.Minute
+)
+
+// harness is the service booted once for every test of the package.
+type harness struct {
+	api    *httptest.Server
+	r2     *fakeR2
+	app    *app.App
+	cancel context.CancelFunc
+}
+
+var (
+	bootOnce sync.Once
+	booted   *harness
+	bootErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if booted != nil {
+		booted.close()
+	}
+	os.Exit(code)
+}
+
+// start returns the running service, booting it on first use. Tests skip when the Postgres and
+// Redis of the API tests are not configured.
+func start(t *testing.T) *harness {
+	t.Helper()
+
+	databaseURL, redisURL := os.Getenv("APITEST_DATABASE_URL"), os.Getenv("APITEST_REDIS_URL")
+	if databaseURL == "" || redisURL == "" {
+		t.Skip("APITEST_DATABASE_URL and APITEST_REDIS_URL are not set (make apitest runs them against make deps)")
+	}
+
+	bootOnce.Do(func() { booted, bootErr = boot(databaseURL, redisURL) })
+	if bootErr != nil {
+		t.Fatalf("failed to boot the service: %v", bootErr)
+	}
+	return booted
+}
+
+// boot migrates the database and starts the service with fake providers, a fake R2 and no
+// scheduled jobs.
+func boot(databaseURL, redisURL string) (*harness, error) {
+	m, err := migrate.New("file://"+migrationsDir, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	err = m.Up()
+	m.Close()
+	if err != nil && err != migrate.ErrNoChange {
+		return nil, fmt.Errorf("failed to migrate: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	r2 := newFakeR2()
+	if err := configure(cfg, databaseURL, redisURL, r2.URL); err != nil {
+		r2.Close()
+		return nil, err
+	}
+
+	application, err := app.New(cfg, logger.NewLogger(cfg.LogLevel, cfg.LogFormat))
+	if err != nil {
+		r2.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	application.Start(ctx)
+
+	return &harness{
+		api:    httptest.NewServer(application.HTTP.Handler()),
+		r2:     r2,
+		app:    application,
+		cancel: cancel,
+	}, nil
+}
+
+// configure points the configuration at the test dependencies and turns off everything a test
+// does not exercise: admin routes, provider probes, scheduled jobs and media variants (ffmpeg).
+func configure(cfg *config.Config, databaseURL, redisURL, r2URL string) error {
+	db, err := url.Parse(databaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid APITEST_DATABASE_URL: %w", err)
+	}
+	cfg.PostgresHost = db.Hostname()
+	if cfg.PostgresPort, err = strconv.Atoi(db.Port()); err != nil {
+		cfg.PostgresPort = 5432
+	}
+	cfg.PostgresUser = db.User.Username()
+	cfg.PostgresPassword, _ = db.User.Password()
+	cfg.PostgresDB = strings.TrimPrefix(db.Path, "/")
+	cfg.RedisURL = redisURL
+
+	cfg.CloudflareR2Endpoint = r2URL
+	cfg.CloudflareAccessKeyID = "apitest"
+	cfg.CloudflareSecretKey = "apitest"
+	cfg.CloudflareBucketName = "apitest"
+	cfg.CloudflarePublicURL = r2URL + "/apitest"
+
+	cfg.Environment = "test"
+	cfg.FakeProviders = true
+	cfg.FakeProviderLatency = 0
+	cfg.LogLevel = "error"
+	cfg.DevAdminUser, cfg.DevAdminPass = "", ""
+	cfg.DemoEnabled = false
+	cfg.QueuePollInterval = pollInterval
+	cfg.MediaAudioVariants, cfg.MediaImageVariants = nil, nil
+
+	cfg.ProviderProbeInterval = 0
+	cfg.JobCheckInterval = 0
+	cfg.RecordingPurgeInterval = 0
+	cfg.ContentClusterInterval = 0
+	cfg.GlossaryCheckInterval = 0
+	cfg.MediaCheckInterval = 0
+	cfg.GoalReminderInterval = 0
+	cfg.StorageUsageInterval = 0
+	cfg.ActivityPartitionInterval = 0
+	cfg.AnalyticsSink = ""
+	cfg.FieldReencryptInterval = 0
+	cfg.DemoCleanupInterval = 0
+	cfg.WordFrequencyDir = ""
+	return nil
+}
+
+func (h *harness) close() {
+	h.api.Close()
+	h.cancel()
+	h.app.Stop()
+	h.app.Close()
+	h.r2.Close()
+}
+
+// envelope is the pkg/response body.
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Meta    json.RawMessage `json:"meta"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// processing is the batch meta of pkg/response.MetaProcessing the tests read.
+type processing struct {
+	BatchID string `json:"batch_id"`
+	Status  string `json:"status"`
+	Jobs    []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"jobs"`
+}
+
+// user is a signed-up client of the API.
+type user struct {
+	h     *harness
+	token string
+}
+
+// signUp registers a new user.
+func (h *harness) signUp(t *testing.T) *user {
+	t.Helper()
+
+	u := &user{h: h}
+	env := u.call(t, http.MethodPost, "/api/v1/auth/register", http.StatusCreated, jsonBody(t, map[string]string{
+		"email":        fmt.Sprintf("apitest-%s@example.com", uuid.NewString()),
+		"password":     "apitest-password",
+		"display_name": "apitest",
+	}))
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	decode(t, env.Data, &auth)
+	if auth.Token == "" {
+		t.Fatal("register returned no token")
+	}
+	u.token = auth.Token
+	return u
+}
+
+// request is a request body with its content type.
+type request struct {
+	body        []byte
+	contentType string
+	headers     map[string]string
+}
+
+func jsonBody(t *testing.T, v any) *request {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+	return &request{body: body, contentType: "application/json"}
+}
+
+// call sends a request as the user and fails the test unless it answers wantStatus.
+func (u *user) call(t *testing.T, method, path string, wantStatus int, req *request) *envelope {
+	t.Helper()
+
+	var body io.Reader
+	if req != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequest(method, u.h.api.URL+path, body)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if req != nil {
+		httpReq.Header.Set("Content-Type", req.contentType)
+		for name, value := range req.headers {
+			httpReq.Header.Set(name, value)
+		}
+	}
+	if u.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+u.token)
+	}
+
+	resp, err := u.h.api.Client().Do(httpReq)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, wantStatus, raw)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		t.Fatalf("%s %s: %v: %s", method, path, err, raw)
+	}
+	return &env
+}
+
+// waitForBatch polls until the batch read by check completes, failing the test if it fails or
+// does not finish in time.
+func waitForBatch(t *testing.T, check func() processing) processing {
+	t.Helper()
+
+	deadline := time.Now().Add(pollTimeout)
+	for {
+		batch := check()
+		switch batch.Status {
+		case "completed":
+			return batch
+		case "failed":
+			t.Fatalf("batch %s failed: %+v", batch.BatchID, batch.Jobs)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("batch %s still %q after %s: %+v", batch.BatchID, batch.Status, pollTimeout, batch.Jobs)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func decode(t *testing.T, raw json.RawMessage, v any) {
+	t.Helper()
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("failed to decode %s: %v", raw, err)
+	}
+}
@@ -0,0 +1,142 @@
+package apitest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// r2Object is an object stored by fakeR2.
+type r2Object struct {
+	data        []byte
+	contentType string
+	metadata    http.Header // x-amz-meta-* headers as sent
+}
+
+// fakeR2 is the part of the S3 API CloudflareClient uses for single uploads: PUT, HEAD, GET and
+// DELETE of objects addressed by path (bucket/key). Objects are kept in memory.
+type fakeR2 struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string]r2Object
+}
+
+func newFakeR2() *fakeR2 {
+	r2 := &fakeR2{objects: map[string]r2Object{}}
+	r2.Server = httptest.NewServer(http.HandlerFunc(r2.serve))
+	return r2
+}
+
+func (r2 *fakeR2) serve(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		object := r2Object{data: data, contentType: r.Header.Get("Content-Type"), metadata: http.Header{}}
+		for name, values := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+				object.metadata[name] = values
+			}
+		}
+		r2.mu.Lock()
+		r2.objects[path] = object
+		r2.mu.Unlock()
+		w.Header().Set("ETag", `"apitest"`)
+
+	case http.MethodHead, http.MethodGet:
+		r2.mu.Lock()
+		object, ok := r2.objects[path]
+		r2.mu.Unlock()
+		if !ok {
+			// HEAD of the bucket itself (HeadBucket)
+			if !strings.Contains(path, "/") && r.Method == http.MethodHead {
+				return
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			return
+		}
+		for name, values := range object.metadata {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("ETag", `"apitest"`)
+		if r.Method == http.MethodGet {
+			// Answer checksum mode so the SDK validates the download instead of warning
+			crc := binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(object.data))
+			w.Header().Set("X-Amz-Checksum-Crc32", base64.StdEncoding.EncodeToString(crc))
+			_, _ = w.Write(object.data)
+		}
+
+	case http.MethodDelete:
+		r2.mu.Lock()
+		delete(r2.objects, path)
+		r2.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "not implemented by the fake R2", http.StatusNotImplemented)
+	}
+}
+
+// readS3Body reads an upload body, decoding aws-chunked encoding (used by the SDK for streamed
+// checksums) into its payload.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var payload bytes.Buffer
+	body := bufio.NewReader(r.Body)
+	for {
+		line, err := body.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("read chunk header: %w", err)
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("chunk size %q: %w", sizeHex, err)
+		}
+		if size == 0 {
+			// Trailing checksum headers follow; the payload is complete
+			return payload.Bytes(), nil
+		}
+		if _, err := io.CopyN(&payload, body, size); err != nil {
+			return nil, fmt.Errorf("read chunk: %w", err)
+		}
+		if _, err := body.Discard(2); err != nil { // \r\n after the chunk data
+			return nil, fmt.Errorf("read chunk end: %w", err)
+		}
+	}
+}
+
+// has reports whether the object behind a public URL was uploaded.
+func (r2 *fakeR2) has(publicURL string) bool {
+	path, ok := strings.CutPrefix(publicURL, r2.URL+"/")
+	if !ok {
+		return false
+	}
+	r2.mu.Lock()
+	defer r2.mu.Unlock()
+	_, ok = r2.objects[path]
+	return ok
+}
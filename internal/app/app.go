@@ -0,0 +1,656 @@
+// Package app wires the service from its configuration: clients, domains, the HTTP router and the
+// queue workers. cmd/server runs it; internal/apitest boots it against test dependencies.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/config"
+	"github.com/windfall/uwu_service/internal/domain/analytics"
+	"github.com/windfall/uwu_service/internal/domain/auth"
+	"github.com/windfall/uwu_service/internal/domain/batch"
+	"github.com/windfall/uwu_service/internal/domain/bundle"
+	"github.com/windfall/uwu_service/internal/domain/consent"
+	"github.com/windfall/uwu_service/internal/domain/content"
+	"github.com/windfall/uwu_service/internal/domain/deck"
+	"github.com/windfall/uwu_service/internal/domain/delta"
+	"github.com/windfall/uwu_service/internal/domain/demo"
+	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/domain/encryption"
+	"github.com/windfall/uwu_service/internal/domain/event"
+	"github.com/windfall/uwu_service/internal/domain/favorite"
+	"github.com/windfall/uwu_service/internal/domain/feature"
+	"github.com/windfall/uwu_service/internal/domain/goal"
+	"github.com/windfall/uwu_service/internal/domain/maintenance"
+	"github.com/windfall/uwu_service/internal/domain/moderation"
+	"github.com/windfall/uwu_service/internal/domain/note"
+	"github.com/windfall/uwu_service/internal/domain/organization"
+	"github.com/windfall/uwu_service/internal/domain/profile"
+	"github.com/windfall/uwu_service/internal/domain/public"
+	"github.com/windfall/uwu_service/internal/domain/recommendation"
+	"github.com/windfall/uwu_service/internal/domain/romanize"
+	"github.com/windfall/uwu_service/internal/domain/storage"
+	"github.com/windfall/uwu_service/internal/domain/support"
+	"github.com/windfall/uwu_service/internal/domain/video"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/server"
+)
+
+// App is the wired service.
+type App struct {
+	HTTP        *server.HTTPServer
+	Queue       *client.QueueClient
+	JobRegistry *client.JobRegistry
+	QueryTracer *client.QueryTracer
+
+	cfg            *config.Config
+	db             *client.PostgresClient
+	queueServer    *server.QueueServer
+	providerHealth *client.ProviderHealthClient
+	batchEvents    *client.BatchEvents
+
+	analyticsExportInterval time.Duration
+	fieldReencryptInterval  time.Duration
+	backfillFrequencyRanks  bool
+}
+
+// New connects to the dependencies in cfg and wires every domain. Queue workers and scheduled jobs wait for Start.
+func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
+	// -----------------------------------------
+	// 1. Setup Infrastructure
+	// -----------------------------------------
+
+	// Initialize Queue
+	jobRegistry := client.NewJobRegistry(client.JobRegistryOptions{
+		ExpectedDuration: cfg.JobExpectedDuration,
+		ExpectedByName:   cfg.JobExpectedDurationByName,
+		CheckInterval:    cfg.JobCheckInterval,
+	}, logger)
+	queue := client.NewQueueClient(logger, cfg.QueueBufferSize, jobRegistry)
+
+	// Initialize Database Connection (query timings per repository method on /debug/vars)
+	queryTracer := client.NewQueryTracer(cfg.DBSlowQueryThreshold, logger)
+	db, err := client.NewPostgresClient(context.Background(), cfg.DatabaseURL(), queryTracer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	wired := false
+	defer func() {
+		if !wired {
+			db.Close()
+		}
+	}()
+
+	// Durable queue jobs survive a restart; another instance picks them up once their lease expires
+	var jobStore *client.JobStore
+	if cfg.QueueDurable {
+		jobStore = client.NewJobStore(db, client.JobStoreOptions{
+			Lease:        cfg.QueueLease,
+			MaxAttempts:  cfg.QueueMaxAttempts,
+			RetryBackoff: cfg.QueueRetryBackoff,
+		})
+		queue.SetStore(jobStore, cfg.QueuePollInterval)
+	}
+
+	// Initialize Fault Injector (errors and latency for external dependencies, nil = none)
+	faultInjector, err := client.NewFaultInjector(cfg.FaultErrorRates, cfg.FaultLatency, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fault injection config: %w", err)
+	}
+	if faultInjector != nil {
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("fault injection is not allowed in production")
+		}
+		logger.Warn("Fault injection enabled", "faults", faultInjector.Targets())
+	}
+
+	// Initialize Fake Providers (canned AI output for load tests, nil = real providers)
+	var fakeProviders *client.FakeProviders
+	if cfg.FakeProviders {
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("fake providers are not allowed in production")
+		}
+		if fakeProviders, err = newFakeProviders(cfg.FakeProviderLatency); err != nil {
+			return nil, err
+		}
+		logger.Warn("Fake AI providers enabled", "latency", cfg.FakeProviderLatency)
+	}
+
+	// Admin and debug routes need explicitly configured credentials
+	if !cfg.AdminEnabled() {
+		logger.Warn("Admin credentials not configured, /api/v1/admin and /debug are disabled (set DEV_ADMIN_USER and DEV_ADMIN_PASS)")
+	}
+
+	// Initialize Redis Client
+	redisClient, err := client.NewRedisClient(cfg.RedisURL, faultInjector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Redis client: %w", err)
+	}
+
+	// Initialize Budget Client (AI spend guardrails)
+	budgetClient := client.NewBudgetClient(redisClient, client.BudgetOptions{
+		Prices: map[client.BudgetProvider]float64{
+			client.BudgetProviderChatGPT:    cfg.BudgetPriceChatGPT,
+			client.BudgetProviderWhisper:    cfg.BudgetPriceWhisper,
+			client.BudgetProviderSpeechTTS:  cfg.BudgetPriceSpeechTTS,
+			client.BudgetProviderAssessment: cfg.BudgetPriceAssessment,
+			client.BudgetProviderImage:      cfg.BudgetPriceImage,
+			client.BudgetProviderOCR:        cfg.BudgetPriceOCR,
+			client.BudgetProviderGemini:     cfg.BudgetPriceGemini,
+			client.BudgetProviderGateway:    cfg.BudgetPriceGateway,
+			client.BudgetProviderAnthropic:  cfg.BudgetPriceAnthropic,
+		},
+		DailyLimit: cfg.BudgetDailyLimit,
+		BatchLimit: cfg.BudgetBatchLimit,
+	}, logger)
+
+	// Initialize Maintenance Flags (runtime maintenance mode and route kill switches)
+	maintenanceClient := client.NewMaintenanceClient(redisClient, cfg.MaintenanceRefreshInterval, logger)
+
+	// Initialize Provider Health (background probes, degraded providers fast-fail)
+	providerHealth := client.NewProviderHealthClient(redisClient, client.ProviderHealthOptions{
+		Interval:         cfg.ProviderProbeInterval,
+		Timeout:          cfg.ProviderProbeTimeout,
+		FailureThreshold: cfg.ProviderFailureThreshold,
+		Refresh:          cfg.ProviderHealthRefresh,
+	}, logger)
+
+	// Initialize Rate Limiters (per-provider QPS, 0 disables)
+	chatGPTLimiter := client.NewRateLimiter("chatgpt", cfg.RateLimitChatGPTQPS, cfg.RateLimitChatGPTBurst, logger)
+	whisperLimiter := client.NewRateLimiter("whisper", cfg.RateLimitWhisperQPS, cfg.RateLimitWhisperBurst, logger)
+	speechLimiter := client.NewRateLimiter("azure_speech", cfg.RateLimitSpeechQPS, cfg.RateLimitSpeechBurst, logger)
+	imageLimiter := client.NewRateLimiter("imagen", cfg.RateLimitImageQPS, cfg.RateLimitImageBurst, logger)
+	gatewayLimiter := client.NewRateLimiter("gateway", cfg.RateLimitGatewayQPS, cfg.RateLimitGatewayBurst, logger)
+	anthropicLimiter := client.NewRateLimiter("anthropic", cfg.RateLimitAnthropicQPS, cfg.RateLimitAnthropicBurst, logger)
+	rateLimiters := []*client.RateLimiter{chatGPTLimiter, whisperLimiter, speechLimiter, imageLimiter, gatewayLimiter, anthropicLimiter}
+
+	// Initialize Credential Store (organization keys for Azure and GCP, nil = platform keys only)
+	credentialStore, err := client.NewCredentialStore(db, cfg.CredentialsEncryptionKey, cfg.CredentialsCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize credential store: %w", err)
+	}
+
+	// Initialize Field Cipher (retell and speaking transcripts/feedback at rest, nil = plaintext)
+	fieldCipher, err := client.NewFieldCipher(cfg.FieldEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize field encryption: %w", err)
+	}
+
+	// Initialize Azure AI Client
+	azureChatClient := client.NewAzureChatGPTClient(cfg.AzureGPT5NanoEndpoint, cfg.AzureGPT5NanoKey, budgetClient, chatGPTLimiter, credentialStore, faultInjector)
+	whisperClient := client.NewAzureWhisperClient(cfg.AzureWhisperEndpoint, cfg.AzureWhisperKey, budgetClient, whisperLimiter, providerHealth, credentialStore, faultInjector)
+	speechClient := client.NewAzureSpeechClient(cfg.AzureAISpeechKey, cfg.AzureServiceRegion, budgetClient, speechLimiter, providerHealth, credentialStore, faultInjector)
+
+	// Initialize Chat Provider Chain (fallback in configured order)
+	gatewayChatClient := client.NewOpenAICompatibleClient(cfg.LLMGatewayBaseURL, cfg.LLMGatewayAPIKey, cfg.LLMGatewayModel, budgetClient, gatewayLimiter)
+	anthropicChatClient := client.NewAnthropicClient(cfg.AnthropicBaseURL, cfg.AnthropicAPIKey, cfg.AnthropicModel, cfg.AnthropicMaxTokens, budgetClient, anthropicLimiter)
+	ollamaChatClient := client.NewOllamaClient(cfg.OllamaBaseURL, cfg.OllamaModel, cfg.OllamaJSONMode)
+	chatProviders := map[string]client.ChatClient{
+		"azure":     azureChatClient,
+		"gateway":   gatewayChatClient,
+		"anthropic": anthropicChatClient,
+		"ollama":    ollamaChatClient,
+	}
+	chatChain, featureChains, selectableChat := cfg.LLMProviderChain, cfg.LLMFeatureProviders, cfg.LLMSelectableProviders
+	if fakeProviders != nil {
+		chatProviders = map[string]client.ChatClient{client.ProviderFake: fakeProviders}
+		chatChain, featureChains, selectableChat = []string{client.ProviderFake}, nil, nil
+	}
+	buildChatChain := func(names []string) ([]client.ChatProvider, error) {
+		var chain []client.ChatProvider
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			provider, ok := chatProviders[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown chat provider %q", name)
+			}
+			chain = append(chain, client.ChatProvider{Name: name, Client: provider})
+		}
+		return chain, nil
+	}
+	defaultChain, err := buildChatChain(chatChain)
+	if err != nil {
+		return nil, err
+	}
+	chatGPTClient := client.NewFallbackChatClient(defaultChain, logger)
+	for feature, names := range featureChains {
+		chain, err := buildChatChain(strings.Split(names, "|"))
+		if err != nil {
+			return nil, err
+		}
+		chatGPTClient.SetFeatureChain(feature, chain)
+	}
+	selectable, err := buildChatChain(selectableChat)
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range selectable {
+		chatGPTClient.AddSelectable(provider)
+	}
+
+	// Initialize Conversation Memory (session history for chat and sparring)
+	conversationMemory := client.NewConversationMemory(redisClient, chatGPTClient, client.ConversationMemoryOptions{
+		TTL:         cfg.MemoryTTL,
+		MaxMessages: cfg.MemoryMaxMessages,
+		KeepRecent:  cfg.MemoryKeepRecent,
+	}, logger)
+
+	// Initialize FFmpeg Client (audio processing)
+	ffmpegClient := client.NewFFmpegClient(client.FFmpegOptions{
+		LoudnessTarget:     cfg.AudioLoudnessTarget,
+		TruePeak:           cfg.AudioTruePeak,
+		LoudnessRange:      cfg.AudioLoudnessRange,
+		SilenceTrimEnabled: cfg.AudioSilenceTrimEnabled,
+		SilenceThreshold:   cfg.AudioSilenceThreshold,
+		SilenceMinDuration: cfg.AudioSilenceMinDuration,
+
+		MaxRecordingDuration: cfg.AudioMaxRecordingDuration,
+	})
+
+	// Initialize Gemini Image and Text Clients (not needed, and usually not configured, with fake providers)
+	var imageClient *client.GeminiImageClient
+	var structuredClient client.StructuredClient
+	if fakeProviders == nil {
+		imageClient, err = client.NewGeminiImageClient(cfg.GeminiSABase64, cfg.GCPLocation, budgetClient, imageLimiter, providerHealth, credentialStore, faultInjector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Gemini image client: %w", err)
+		}
+		structuredClient = client.NewGeminiTextClient(imageClient)
+	}
+
+	// Initialize Cloudflare R2 Client (using S3 protocol)
+	cloudflareClient, err := client.NewCloudflareClient(context.Background(),
+		cfg.CloudflareAccessKeyID,
+		cfg.CloudflareSecretKey,
+		cfg.CloudflareR2Endpoint,
+		cfg.CloudflareBucketName,
+		cfg.CloudflarePublicURL,
+		providerHealth,
+		client.R2UploadConfig{
+			MultipartThreshold: cfg.R2MultipartThreshold,
+			PartSize:           cfg.R2PartSize,
+			Retries:            cfg.R2UploadRetries,
+			RetryBackoff:       cfg.R2RetryBackoff,
+		},
+		faultInjector,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Cloudflare client: %w", err)
+	}
+
+	// Speech, transcription and images go to the fakes instead when they are enabled
+	var speech client.SpeechClient = speechClient
+	var transcription client.TranscriptionClient = whisperClient
+	var imageProviders []client.ImageProvider
+	if fakeProviders != nil {
+		speech, transcription = fakeProviders, fakeProviders
+		imageProviders = []client.ImageProvider{{Name: client.ProviderFake, Client: fakeProviders}}
+	} else {
+		imageProviders = []client.ImageProvider{{Name: client.ProviderGemini, Client: imageClient}}
+	}
+	images := client.NewFallbackImageClient(imageProviders, logger)
+
+	// Probe only the providers that are configured
+	providerHealth.Register(client.ProviderR2, cloudflareClient.Probe)
+	if fakeProviders == nil {
+		providerHealth.Register(client.ProviderGemini, imageClient.Probe)
+		if cfg.AzureWhisperEndpoint != "" && cfg.AzureWhisperKey != "" {
+			providerHealth.Register(client.ProviderWhisper, whisperClient.Probe)
+		}
+		if cfg.AzureAISpeechKey != "" && cfg.AzureServiceRegion != "" {
+			providerHealth.Register(client.ProviderAzureSpeech, speechClient.Probe)
+		}
+	}
+
+	// Capabilities of this deployment (GET /api/v1/capabilities); routes reject what is not configured
+	capabilities := client.NewCapabilities()
+	capabilities.Set(client.CapabilityChat, client.ConfiguredProviders(defaultChain)...)
+	switch {
+	case fakeProviders != nil:
+		capabilities.Set(client.CapabilityTTS, client.ProviderFake)
+		capabilities.Set(client.CapabilityPronunciation, client.ProviderFake)
+	case speechClient.Configured():
+		capabilities.Set(client.CapabilityTTS, client.ProviderAzureSpeech)
+		capabilities.Set(client.CapabilityPronunciation, client.ProviderAzureSpeech)
+	default:
+		capabilities.Set(client.CapabilityTTS)
+		capabilities.Set(client.CapabilityPronunciation)
+	}
+	switch {
+	case fakeProviders != nil:
+		capabilities.Set(client.CapabilitySTT, client.ProviderFake)
+	case whisperClient.Configured():
+		capabilities.Set(client.CapabilitySTT, client.ProviderWhisper)
+	default:
+		capabilities.Set(client.CapabilitySTT)
+	}
+	capabilities.Set(client.CapabilityImageGeneration, images.Names()...)
+	if cfg.ContentEmbeddingModel != "" && cfg.LLMGatewayBaseURL != "" {
+		capabilities.Set(client.CapabilityEmbeddings, "gateway")
+	} else {
+		capabilities.Set(client.CapabilityEmbeddings, "word_vectors")
+	}
+
+	// -----------------------------------------
+	// 2. Setup Application
+	// -----------------------------------------
+
+	// Register Auth Domain
+	authRepo := auth.NewAuthRepository(db, []byte(cfg.JWTSecret))
+	authService := auth.NewAuthService(authRepo)
+	authHandler := auth.NewAuthHandler(authService, logger)
+
+	timeouts := client.TimeoutPolicy{
+		Chat:          cfg.TimeoutChat,
+		Image:         cfg.TimeoutImage,
+		Transcription: cfg.TimeoutTranscription,
+		Speech:        cfg.TimeoutSpeech,
+	}
+
+	mediaVariants, err := client.NewMediaVariants(cfg.MediaAudioVariants, cfg.MediaImageVariants)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media variants: %w", err)
+	}
+
+	batchResults := client.NewBatchResultStorage(cloudflareClient, cfg.BatchResultMaxBytes)
+	batchRetention := client.BatchRetention{
+		ProcessingTTL:       cfg.BatchProcessingTTL,
+		CompletedTTL:        cfg.BatchCompletedTTL,
+		ProcessingTTLByType: cfg.BatchProcessingTTLByType,
+		CompletedTTLByType:  cfg.BatchCompletedTTLByType,
+	}
+	batchArchive := client.NewBatchArchive(db)
+	batchStore := client.NewBatchStore(db)
+	cachedBatches := client.NewCachedBatches(redisClient, batchStore, batchArchive, batchResults, batchRetention, logger)
+
+	// Deterministic readings of zh/ja/ko text
+	romanizer := client.NewRomanizer(cfg.RomanizeCacheSize)
+
+	// Word frequency lists (vocabulary ranks)
+	wordFrequency, err := client.LoadWordFrequency(cfg.WordFrequencyDir, cfg.WordFrequencyMaxRank)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load word frequency lists: %w", err)
+	}
+	logger.Info("Word frequency lists loaded", "languages", wordFrequency.Languages())
+
+	// Register Video Domain
+	promptBudget := client.NewPromptBudget(cfg.PromptMaxTokens, logger)
+	videoAIRepo := video.NewAIRepository(transcription, chatGPTClient, video.TranscriptionOptions{
+		MinConfidence:   cfg.WhisperMinConfidence,
+		RetryAutoDetect: cfg.WhisperRetryAutoDetect,
+		ChunkTokens:     cfg.VideoChunkTokens,
+	}, promptBudget, logger)
+	videoBatchRepo := video.NewBatchRepository(cachedBatches)
+	fileRepo := video.NewFileRepository(cloudflareClient, ffmpegClient, logger)
+	videoAudioRepo := video.NewAudioRepository(speech)
+	videoRepo := video.NewVideoRepository(db, fieldCipher)
+	videoService := video.NewVideoService(videoRepo, videoAIRepo, videoBatchRepo, fileRepo, videoAudioRepo, video.VideoOptions{
+		ExtractVocabulary:   cfg.VideoExtractVocabulary,
+		ChaptersMinDuration: cfg.VideoChaptersMinDuration,
+		RetellDefaults: video.RetellSettings{
+			MaxAttempts:    cfg.RetellMaxAttempts,
+			PassPercentage: cfg.RetellPassPercentage,
+			ResetAttempts:  cfg.RetellResetAttempts,
+		},
+		RetellMatchCoverage: cfg.RetellMatchCoverage,
+		Timeouts:            timeouts,
+		Romanizer:           romanizer,
+		WordFrequency:       wordFrequency,
+	})
+	videoHandler := video.NewVideoHandler(videoService, queue, budgetClient)
+
+	// Register Dialog Domain
+	dialogAIRepo := dialog.NewAIRepository(chatGPTClient, structuredClient)
+	organizationRepo := organization.NewOrganizationRepository(db)
+	imageStyles, err := client.NewImageStyles(cfg.ImageStylePrompts, cfg.ImageStyleDefault, organizationRepo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image styles: %w", err)
+	}
+	dialogImageRepo := dialog.NewImageRepository(images, imageStyles)
+	dialogAudioRepo := dialog.NewAudioRepository(speech, transcription)
+	dialogFileRepo := dialog.NewFileRepository(cloudflareClient, ffmpegClient, logger)
+
+	dialogBatchRepo := dialog.NewBatchRepository(cachedBatches)
+	dialogRepo := dialog.NewDialogRepository(db, fieldCipher)
+	dialogMemoryRepo := dialog.NewMemoryRepository(conversationMemory)
+	dialogFixRepo := dialog.NewFixRepository(redisClient)
+	var dialogImageRegistry dialog.ImageRegistry
+	if cfg.ImageReuseEnabled {
+		dialogImageRegistry = dialog.NewImageRegistry(db)
+	}
+	dialogService := dialog.NewDialogService(dialogRepo, dialogAIRepo, dialogImageRepo, dialogAudioRepo, dialogFileRepo, dialogBatchRepo, dialogMemoryRepo, timeouts, mediaVariants, dialogFixRepo, dialogImageRegistry, cfg.QualityReviewThreshold, cfg.ImageTextRetries, cfg.PracticeSessionIdleTTL)
+	dialogHandler := dialog.NewDialogHandler(dialogService, queue, budgetClient)
+
+	// Register Profile Domain
+	profileRepo := profile.NewProfileRepository(db)
+	profileService := profile.NewProfileService(profileRepo)
+	profileHandler := profile.NewProfileHandler(profileService)
+
+	// Register Recommendation Domain ("next best activity" feed)
+	recommendationRepo := recommendation.NewRecommendationRepository(db, cfg.ActivityEventRetention)
+	recommendationService := recommendation.NewRecommendationService(recommendationRepo, recommendation.DefaultStrategies()...)
+	recommendationHandler := recommendation.NewRecommendationHandler(recommendationService)
+
+	// Register Goal Domain (daily goals and reminders)
+	goalRepo := goal.NewGoalRepository(db)
+	goalNotificationRepo := goal.NewLogNotificationRepository(logger)
+	goalService := goal.NewGoalService(goalRepo, goalNotificationRepo)
+	goalHandler := goal.NewGoalHandler(goalService)
+
+	// Register Event Domain (client activity telemetry)
+	eventRepo := event.NewEventRepository(db)
+	eventFileRepo := event.NewFileRepository(cloudflareClient)
+	eventService := event.NewEventService(eventRepo, eventFileRepo, event.EventOptions{
+		Retention: cfg.ActivityEventRetention,
+		Archive:   cfg.ActivityEventArchive,
+	})
+	eventHandler := event.NewEventHandler(eventService)
+
+	// Register Public Domain (unauthenticated content preview)
+	publicRepo := public.NewPublicRepository(db)
+	publicService := public.NewPublicService(publicRepo)
+	publicHandler := public.NewPublicHandler(publicService, cfg.PublicCacheMaxAge)
+
+	// Register Consent Domain (versioned terms, e.g. voice recording consent)
+	consentRepo := consent.NewConsentRepository(db)
+	consentService := consent.NewConsentService(consentRepo)
+	consentHandler := consent.NewConsentHandler(consentService)
+
+	// Register Moderation Domain (content reports and the moderation queue)
+	moderationRepo := moderation.NewModerationRepository(db)
+	moderationService := moderation.NewModerationService(moderationRepo, cfg.ModerationReportThreshold, logger)
+	moderationHandler := moderation.NewModerationHandler(moderationService)
+
+	// Register Romanize Domain
+	romanizeService := romanize.NewRomanizeService(romanizer)
+	romanizeHandler := romanize.NewRomanizeHandler(romanizeService)
+
+	// Register Deck Domain (user-curated collections)
+	deckRepo := deck.NewDeckRepository(db)
+	deckService := deck.NewDeckService(deckRepo)
+	deckHandler := deck.NewDeckHandler(deckService)
+
+	// Register Favorite Domain (favorited videos, dialogs and decks)
+	favoriteRepo := favorite.NewFavoriteRepository(db)
+	favoriteService := favorite.NewFavoriteService(favoriteRepo)
+	favoriteHandler := favorite.NewFavoriteHandler(favoriteService)
+
+	// Register Note Domain (private notes on videos and dialogs)
+	noteRepo := note.NewNoteRepository(db)
+	noteService := note.NewNoteService(noteRepo)
+	noteHandler := note.NewNoteHandler(noteService)
+
+	// Register Batch Domain (live batch progress over SSE)
+	batchEvents := client.NewBatchEvents(db, logger)
+	batchRepo := batch.NewBatchRepository(db, batchStore)
+	batchService := batch.NewBatchService(batchRepo, batchEvents)
+	batchHandler := batch.NewBatchHandler(batchService, logger)
+
+	// Register Storage Domain (R2 usage reporting)
+	storageRepo := storage.NewStorageRepository(db)
+	storageFileRepo := storage.NewFileRepository(cloudflareClient)
+	storageService := storage.NewStorageService(storageRepo, storageFileRepo)
+	storageHandler := storage.NewStorageHandler(storageService)
+
+	// Register Organization Domain (admin: organizations, members and their provider credentials)
+	organizationService := organization.NewOrganizationService(organizationRepo, credentialStore, logger)
+	organizationHandler := organization.NewOrganizationHandler(organizationService)
+
+	// Register Analytics Domain (export to the analytics warehouse)
+	analyticsSink, err := client.NewAnalyticsSink(context.Background(), client.AnalyticsSinkConfig{
+		Sink:               cfg.AnalyticsSink,
+		ClickHouseURL:      cfg.ClickHouseURL,
+		ClickHouseDatabase: cfg.ClickHouseDatabase,
+		ClickHouseUser:     cfg.ClickHouseUser,
+		ClickHousePassword: cfg.ClickHousePassword,
+		BigQueryProjectID:  cfg.BigQueryProjectID,
+		BigQueryDataset:    cfg.BigQueryDataset,
+		SABase64:           cfg.GeminiSABase64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize analytics sink: %w", err)
+	}
+	analyticsRepo := analytics.NewAnalyticsRepository(db)
+	analyticsSinkRepo := analytics.NewSinkRepository(analyticsSink)
+	analyticsService := analytics.NewAnalyticsService(analyticsRepo, analyticsSinkRepo, analytics.AnalyticsOptions{
+		Backfill: cfg.AnalyticsBackfill,
+	})
+	encryptionRepo := encryption.NewEncryptionRepository(db)
+	encryptionService := encryption.NewEncryptionService(encryptionRepo, fieldCipher, logger)
+	fieldReencryptInterval := cfg.FieldReencryptInterval
+	if fieldCipher == nil {
+		fieldReencryptInterval = 0
+	}
+
+	analyticsExportInterval := cfg.AnalyticsExportInterval
+	if analyticsSink == nil {
+		analyticsExportInterval = 0
+		capabilities.Set(client.CapabilityAnalyticsExport)
+	} else {
+		capabilities.Set(client.CapabilityAnalyticsExport, analyticsSink.Name())
+	}
+	for _, capability := range capabilities.List() {
+		if !capability.Available {
+			logger.Warn("Capability unavailable, its endpoints will answer 501", "capability", capability.Name)
+		}
+	}
+
+	// Register Delta Domain (incremental sync for mobile clients)
+	deltaRepo := delta.NewDeltaRepository(db)
+	deltaService := delta.NewDeltaService(deltaRepo)
+	deltaHandler := delta.NewDeltaHandler(deltaService)
+
+	// Register Support Domain (admin impersonation and user inspection)
+	supportRepo := support.NewSupportRepository(db)
+	supportBatchRepo := support.NewBatchRepository(redisClient, batchStore, batchArchive)
+	supportService := support.NewSupportService(supportRepo, supportBatchRepo, authRepo, support.SupportOptions{
+		ImpersonationTTL:    cfg.ImpersonationTTL,
+		ImpersonationMaxTTL: cfg.ImpersonationMaxTTL,
+	})
+	supportHandler := support.NewSupportHandler(supportService)
+
+	// Register Demo Domain (ephemeral accounts for the public try-it widget)
+	demoQuota := client.NewDemoQuota(redisClient, client.DemoQuotaOptions{
+		Limits: map[string]int{
+			client.DemoQuotaWrites:      cfg.DemoMaxWrites,
+			client.DemoQuotaGenerations: cfg.DemoMaxGenerations,
+		},
+		TTL: cfg.DemoSessionTTL,
+	})
+	demoRepo := demo.NewDemoRepository(db)
+	demoFileRepo := demo.NewFileRepository(cloudflareClient)
+	demoService := demo.NewDemoService(demoRepo, demoFileRepo, authRepo, demo.DemoOptions{
+		SessionTTL: cfg.DemoSessionTTL,
+		MaxActive:  cfg.DemoMaxActive,
+		Quotas:     demoQuota.Limits(),
+	})
+	demoHandler := demo.NewDemoHandler(demoService)
+
+	// Register Maintenance Domain
+	maintenanceService := maintenance.NewMaintenanceService(maintenanceClient, logger)
+	maintenanceHandler := maintenance.NewMaintenanceHandler(maintenanceService)
+
+	// Register Bundle Domain (offline zip exports)
+	bundleRepo := bundle.NewBundleRepository(db, redisClient, cfg.BundleTTL)
+	bundleFileRepo := bundle.NewFileRepository(cloudflareClient, cfg.BundleURLTTL, logger)
+	bundleBatchRepo := bundle.NewBatchRepository(cachedBatches)
+	bundleService := bundle.NewBundleService(bundleRepo, bundleFileRepo, bundleBatchRepo, cfg.BundleMaxBytes)
+	bundleHandler := bundle.NewBundleHandler(bundleService, queue)
+
+	featureRepo := feature.NewFeatureRepository(db)
+	featureService := feature.NewFeatureService(featureRepo)
+	featureHandler := feature.NewFeatureHandler(featureService)
+
+	// Register Content Domain (topic clustering, glossary and media checks across the library)
+	contentRepo := content.NewContentRepository(db)
+	contentEmbeddingRepo := content.NewEmbeddingRepository(gatewayChatClient, cfg.ContentEmbeddingModel)
+	contentGlossaryRepo := content.NewGlossaryRepository(db)
+	contentMediaRepo := content.NewMediaRepository(db)
+	mediaChecker := client.NewMediaChecker(cfg.MediaCheckTimeout)
+	contentService := content.NewContentService(contentRepo, contentEmbeddingRepo, contentGlossaryRepo, contentMediaRepo, mediaChecker, queue, content.MediaCheckOptions{
+		Concurrency: cfg.MediaCheckConcurrency,
+		Repair:      cfg.MediaRepairEnabled,
+	})
+	contentHandler := content.NewContentHandler(contentService)
+
+	// -----------------------------------------
+	// 3. Setup Queue Server (Background Jobs) & HTTP Router
+	// -----------------------------------------
+	queueServer := server.NewQueueServer(logger, queue, videoService, dialogService, contentService, goalService, bundleService, storageService, eventService, encryptionService, analyticsService, demoService)
+	queueServer.SetupWorkers()
+
+	httpServer := server.NewHTTPServer(cfg, logger, db, rateLimiters, authRepo, authHandler, videoHandler, dialogHandler, profileHandler, featureHandler, contentHandler, recommendationHandler, goalHandler, eventHandler, deltaHandler, publicHandler, supportHandler, maintenanceClient, providerHealth, maintenanceHandler, bundleHandler, consentService, consentHandler, moderationHandler, romanizeHandler, deckHandler, favoriteHandler, noteHandler, batchHandler, storageHandler, organizationHandler, demoHandler, demoQuota, credentialStore, jobRegistry, jobStore, capabilities, chatGPTClient)
+	wired = true
+
+	return &App{
+		HTTP:        httpServer,
+		Queue:       queue,
+		JobRegistry: jobRegistry,
+		QueryTracer: queryTracer,
+
+		cfg:            cfg,
+		db:             db,
+		queueServer:    queueServer,
+		providerHealth: providerHealth,
+		batchEvents:    batchEvents,
+
+		analyticsExportInterval: analyticsExportInterval,
+		fieldReencryptInterval:  fieldReencryptInterval,
+		backfillFrequencyRanks:  len(wordFrequency.Languages()) > 0,
+	}, nil
+}
+
+// Start runs the queue workers, the scheduled jobs and the background loops until ctx is cancelled.
+// The HTTP server is started by the caller.
+func (a *App) Start(ctx context.Context) {
+	cfg := a.cfg
+	a.queueServer.Start(ctx, cfg.QueueWorkerCount)
+	a.queueServer.ScheduleRecordingPurge(ctx, cfg.RecordingRetention, cfg.RecordingPurgeInterval)
+	a.queueServer.ScheduleContentClustering(ctx, cfg.ContentClusterInterval)
+	a.queueServer.ScheduleGlossaryCheck(ctx, cfg.GlossaryCheckInterval)
+	a.queueServer.ScheduleMediaCheck(ctx, cfg.MediaCheckInterval)
+	a.queueServer.ScheduleGoalReminders(ctx, cfg.GoalReminderInterval)
+	a.queueServer.ScheduleStorageUsage(ctx, cfg.StorageUsageInterval)
+	a.queueServer.ScheduleEventPartitions(ctx, cfg.ActivityPartitionInterval)
+	a.queueServer.ScheduleAnalyticsExport(ctx, a.analyticsExportInterval)
+	a.queueServer.ScheduleFieldReencryption(ctx, a.fieldReencryptInterval)
+	a.queueServer.ScheduleDemoCleanup(ctx, cfg.DemoCleanupInterval)
+	a.queueServer.BackfillFrequencyRanks(a.backfillFrequencyRanks)
+	go a.JobRegistry.Watch(ctx)
+	go a.providerHealth.Run(ctx)
+	go a.batchEvents.Run(ctx)
+}
+
+// Stop waits for the running queue jobs; cancel the context given to Start first.
+func (a *App) Stop() {
+	a.queueServer.Stop()
+}
+
+// Close releases the database pool.
+func (a *App) Close() {
+	a.db.Close()
+}
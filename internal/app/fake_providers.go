@@ -0,0 +1,23 @@
+//go:build fake
+
+package app
+
+import (
+	"time"
+
+	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/domain/video"
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// newFakeProviders creates the fake providers with the canned answers of every domain's prompts.
+func newFakeProviders(latency time.Duration) (*client.FakeProviders, error) {
+	fakeProviders := client.NewFakeProviders(latency)
+	for prompt, response := range video.FakeChatResponses() {
+		fakeProviders.Respond(prompt, response)
+	}
+	for prompt, response := range dialog.FakeChatResponses() {
+		fakeProviders.Respond(prompt, response)
+	}
+	return fakeProviders, nil
+}
@@ -0,0 +1,16 @@
+//go:build !fake
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// newFakeProviders fails: the canned answers are only compiled into builds with -tags fake, so
+// they never ship in the production binary.
+func newFakeProviders(time.Duration) (*client.FakeProviders, error) {
+	return nil, fmt.Errorf("fake providers need a build with -tags fake")
+}
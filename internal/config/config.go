@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,28 +16,248 @@ type Config struct {
 	HTTPPort    int    `envconfig:"SERVER_HTTP_PORT" default:"8080"`
 	Environment string `envconfig:"SERVER_ENV" default:"development"`
 
-	// Admin
-	DevAdminUser string `envconfig:"DEV_ADMIN_USER" default:"wavvy"`
-	DevAdminPass string `envconfig:"DEV_ADMIN_PASS" default:"secretpass"`
+	// Admin basic auth (/api/v1/admin and /debug are not mounted unless both are set, see AdminEnabled)
+	DevAdminUser string `envconfig:"DEV_ADMIN_USER"`
+	DevAdminPass string `envconfig:"DEV_ADMIN_PASS"`
+
+	// /debug/pprof, /debug/vars and /debug/goroutines (admin basic auth)
+	DebugEndpointsEnabled bool `envconfig:"DEBUG_ENDPOINTS_ENABLED" default:"false"`
+
+	// Support impersonation tokens (read-only, audited); issuing one takes its own basic auth credentials,
+	// not the admin ones, and the route is not mounted unless they and the admin credentials are set
+	ImpersonationUser   string        `envconfig:"IMPERSONATION_ADMIN_USER"`
+	ImpersonationPass   string        `envconfig:"IMPERSONATION_ADMIN_PASS"`
+	ImpersonationTTL    time.Duration `envconfig:"IMPERSONATION_TTL" default:"15m"`
+	ImpersonationMaxTTL time.Duration `envconfig:"IMPERSONATION_MAX_TTL" default:"1h"`
+
+	// Maintenance flags are re-read from Redis at most this often
+	MaintenanceRefreshInterval time.Duration `envconfig:"MAINTENANCE_REFRESH_INTERVAL" default:"5s"`
+
+	// Provider health probes (an interval of 0 disables probing and degradation)
+	ProviderProbeInterval    time.Duration `envconfig:"PROVIDER_PROBE_INTERVAL" default:"1m"`
+	ProviderProbeTimeout     time.Duration `envconfig:"PROVIDER_PROBE_TIMEOUT" default:"10s"`
+	ProviderFailureThreshold int           `envconfig:"PROVIDER_FAILURE_THRESHOLD" default:"3"`
+	ProviderHealthRefresh    time.Duration `envconfig:"PROVIDER_HEALTH_REFRESH" default:"5s"`
 
 	// JWT
 	JWTSecret string `envconfig:"JWT_SECRET" default:"jwt-secret"`
 
+	// Proxies whose X-Forwarded-For is trusted for the client IP (rate limits, logs, consent records)
+	TrustedProxies []netip.Prefix `envconfig:"TRUSTED_PROXIES" default:"127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"`
+
 	// CORS
 	CORSAllowedOrigins []string `envconfig:"CORS_ALLOWED_ORIGINS" default:"*"`
 	CORSAllowedMethods []string `envconfig:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE,OPTIONS"`
-	CORSAllowedHeaders []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type,X-Request-ID"`
+	CORSAllowedHeaders []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type,X-Request-ID,X-Chat-Provider"`
+
+	// Security headers (an empty CSP or referrer policy skips the header, an HSTS max age of 0 disables HSTS)
+	SecurityCSP                   string        `envconfig:"SECURITY_CSP" default:"default-src 'none'; frame-ancestors 'none'"`
+	SecurityReferrerPolicy        string        `envconfig:"SECURITY_REFERRER_POLICY" default:"no-referrer"`
+	SecurityHSTSMaxAge            time.Duration `envconfig:"SECURITY_HSTS_MAX_AGE" default:"8760h"`
+	SecurityHSTSIncludeSubdomains bool          `envconfig:"SECURITY_HSTS_INCLUDE_SUBDOMAINS" default:"false"`
+
+	// Request bodies: multipart uploads are capped at MAX_MULTIPART_BODY_BYTES, everything else at
+	// MAX_JSON_BODY_BYTES (0 disables the check)
+	MaxJSONBodyBytes      int64 `envconfig:"MAX_JSON_BODY_BYTES" default:"262144"`
+	MaxMultipartBodyBytes int64 `envconfig:"MAX_MULTIPART_BODY_BYTES" default:"33554432"`
+	MaxJSONDepth          int   `envconfig:"MAX_JSON_DEPTH" default:"32"`
 
 	// Queue
 	QueueWorkerCount int `envconfig:"QUEUE_WORKER_COUNT" default:"4"`
 	QueueBufferSize  int `envconfig:"QUEUE_BUFFER_SIZE" default:"100"`
 
+	// Durable queue (generation jobs kept in Postgres so a restart doesn't orphan their batches)
+	QueueDurable      bool          `envconfig:"QUEUE_DURABLE" default:"true"`
+	QueuePollInterval time.Duration `envconfig:"QUEUE_POLL_INTERVAL" default:"2s"`
+	QueueLease        time.Duration `envconfig:"QUEUE_LEASE" default:"1m"`
+	QueueMaxAttempts  int           `envconfig:"QUEUE_MAX_ATTEMPTS" default:"3"`
+	QueueRetryBackoff time.Duration `envconfig:"QUEUE_RETRY_BACKOFF" default:"30s"`
+
+	// Background job registry (jobs running longer than expected are logged as errors, 0 disables)
+	JobExpectedDuration       time.Duration            `envconfig:"JOB_EXPECTED_DURATION" default:"15m"`
+	JobExpectedDurationByName map[string]time.Duration `envconfig:"JOB_EXPECTED_DURATION_BY_NAME"`
+	JobCheckInterval          time.Duration            `envconfig:"JOB_CHECK_INTERVAL" default:"1m"`
+
 	// Timeouts
 	ReadTimeout     time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"15s"`
 	WriteTimeout    time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"15s"`
 	IdleTimeout     time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" default:"60s"`
 	ShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
 
+	// Audio Processing
+	AudioLoudnessTarget float64 `envconfig:"AUDIO_LOUDNESS_TARGET" default:"-16"`
+	AudioTruePeak       float64 `envconfig:"AUDIO_TRUE_PEAK" default:"-1.5"`
+	AudioLoudnessRange  float64 `envconfig:"AUDIO_LOUDNESS_RANGE" default:"11"`
+
+	AudioSilenceTrimEnabled bool          `envconfig:"AUDIO_SILENCE_TRIM_ENABLED" default:"true"`
+	AudioSilenceThreshold   float64       `envconfig:"AUDIO_SILENCE_THRESHOLD_DB" default:"-50"`
+	AudioSilenceMinDuration time.Duration `envconfig:"AUDIO_SILENCE_MIN_DURATION" default:"300ms"`
+
+	AudioMaxRecordingDuration time.Duration `envconfig:"AUDIO_MAX_RECORDING_DURATION" default:"3m"`
+
+	// Recording Retention
+	RecordingRetention     time.Duration `envconfig:"RECORDING_RETENTION" default:"2160h"` // 90 days
+	RecordingPurgeInterval time.Duration `envconfig:"RECORDING_PURGE_INTERVAL" default:"24h"`
+
+	// Daily goal reminders are checked this often against each user's local reminder time (0 disables)
+	GoalReminderInterval time.Duration `envconfig:"GOAL_REMINDER_INTERVAL" default:"5m"`
+
+	// Activity events are partitioned by month; months older than the retention are archived to R2
+	// (unless ACTIVITY_EVENT_ARCHIVE is false) and dropped, checked every ACTIVITY_PARTITION_INTERVAL (0 disables)
+	ActivityEventRetention    time.Duration `envconfig:"ACTIVITY_EVENT_RETENTION" default:"4320h"`
+	ActivityEventArchive      bool          `envconfig:"ACTIVITY_EVENT_ARCHIVE" default:"true"`
+	ActivityPartitionInterval time.Duration `envconfig:"ACTIVITY_PARTITION_INTERVAL" default:"24h"`
+
+	// Per-feature deadlines for external AI calls (0 disables the deadline)
+	TimeoutChat          time.Duration `envconfig:"TIMEOUT_CHAT" default:"30s"`
+	TimeoutImage         time.Duration `envconfig:"TIMEOUT_IMAGE" default:"60s"`
+	TimeoutTranscription time.Duration `envconfig:"TIMEOUT_TRANSCRIPTION" default:"10m"`
+	TimeoutSpeech        time.Duration `envconfig:"TIMEOUT_SPEECH" default:"60s"`
+
+	// AI Budget (estimated USD per call, 0 limit disables the ceiling)
+	BudgetPriceChatGPT    float64 `envconfig:"BUDGET_PRICE_CHATGPT" default:"0.002"`
+	BudgetPriceWhisper    float64 `envconfig:"BUDGET_PRICE_WHISPER" default:"0.006"`
+	BudgetPriceSpeechTTS  float64 `envconfig:"BUDGET_PRICE_SPEECH_TTS" default:"0.004"`
+	BudgetPriceAssessment float64 `envconfig:"BUDGET_PRICE_SPEECH_ASSESSMENT" default:"0.003"`
+	BudgetPriceImage      float64 `envconfig:"BUDGET_PRICE_IMAGE" default:"0.02"`
+	BudgetPriceOCR        float64 `envconfig:"BUDGET_PRICE_OCR" default:"0.0015"`
+	BudgetPriceGemini     float64 `envconfig:"BUDGET_PRICE_GEMINI" default:"0.001"`
+	BudgetPriceGateway    float64 `envconfig:"BUDGET_PRICE_GATEWAY" default:"0.002"`
+	BudgetPriceAnthropic  float64 `envconfig:"BUDGET_PRICE_ANTHROPIC" default:"0.003"`
+	BudgetDailyLimit      float64 `envconfig:"BUDGET_DAILY_LIMIT" default:"50"`
+	BudgetBatchLimit      float64 `envconfig:"BUDGET_BATCH_LIMIT" default:"1"`
+
+	// Provider Rate Limits (token bucket, QPS of 0 disables the limiter)
+	RateLimitChatGPTQPS     float64 `envconfig:"RATE_LIMIT_CHATGPT_QPS" default:"0"`
+	RateLimitChatGPTBurst   int     `envconfig:"RATE_LIMIT_CHATGPT_BURST" default:"10"`
+	RateLimitWhisperQPS     float64 `envconfig:"RATE_LIMIT_WHISPER_QPS" default:"0"`
+	RateLimitWhisperBurst   int     `envconfig:"RATE_LIMIT_WHISPER_BURST" default:"3"`
+	RateLimitSpeechQPS      float64 `envconfig:"RATE_LIMIT_SPEECH_QPS" default:"5"`
+	RateLimitSpeechBurst    int     `envconfig:"RATE_LIMIT_SPEECH_BURST" default:"5"`
+	RateLimitImageQPS       float64 `envconfig:"RATE_LIMIT_IMAGE_QPS" default:"1"`
+	RateLimitImageBurst     int     `envconfig:"RATE_LIMIT_IMAGE_BURST" default:"2"`
+	RateLimitGatewayQPS     float64 `envconfig:"RATE_LIMIT_GATEWAY_QPS" default:"0"`
+	RateLimitGatewayBurst   int     `envconfig:"RATE_LIMIT_GATEWAY_BURST" default:"10"`
+	RateLimitAnthropicQPS   float64 `envconfig:"RATE_LIMIT_ANTHROPIC_QPS" default:"0"`
+	RateLimitAnthropicBurst int     `envconfig:"RATE_LIMIT_ANTHROPIC_BURST" default:"10"`
+
+	// Public content API (/public/v1): per client IP limit and how long responses may be cached
+	PublicRateLimitQPS   float64       `envconfig:"PUBLIC_RATE_LIMIT_QPS" default:"2"`
+	PublicRateLimitBurst int           `envconfig:"PUBLIC_RATE_LIMIT_BURST" default:"20"`
+	PublicCacheMaxAge    time.Duration `envconfig:"PUBLIC_CACHE_MAX_AGE" default:"5m"`
+
+	// Public demo sessions (POST /demo/session): ephemeral accounts with strict per-session quotas,
+	// deleted with their content once expired. Issuing is limited per client IP and in total.
+	DemoEnabled         bool          `envconfig:"DEMO_ENABLED" default:"false"`
+	DemoSessionTTL      time.Duration `envconfig:"DEMO_SESSION_TTL" default:"30m"`
+	DemoMaxActive       int           `envconfig:"DEMO_MAX_ACTIVE" default:"200"`
+	DemoMaxWrites       int           `envconfig:"DEMO_MAX_WRITES" default:"40"`
+	DemoMaxGenerations  int           `envconfig:"DEMO_MAX_GENERATIONS" default:"1"`
+	DemoRateLimitQPS    float64       `envconfig:"DEMO_RATE_LIMIT_QPS" default:"0.05"`
+	DemoRateLimitBurst  int           `envconfig:"DEMO_RATE_LIMIT_BURST" default:"3"`
+	DemoCleanupInterval time.Duration `envconfig:"DEMO_CLEANUP_INTERVAL" default:"10m"`
+
+	// Video Pipeline (optional stages)
+	VideoExtractVocabulary   bool          `envconfig:"VIDEO_EXTRACT_VOCABULARY" default:"false"`
+	VideoChaptersMinDuration time.Duration `envconfig:"VIDEO_CHAPTERS_MIN_DURATION" default:"5m"`
+
+	// Retell defaults (lessons can override them)
+	RetellMaxAttempts    int     `envconfig:"RETELL_MAX_ATTEMPTS" default:"3"`
+	RetellPassPercentage float64 `envconfig:"RETELL_PASS_PERCENTAGE" default:"100"`
+	RetellResetAttempts  bool    `envconfig:"RETELL_RESET_ATTEMPTS" default:"true"`
+	RetellMatchCoverage  float64 `envconfig:"RETELL_MATCH_COVERAGE" default:"0.8"`
+
+	// Whisper transcript quality checks
+	WhisperMinConfidence   float64 `envconfig:"WHISPER_MIN_CONFIDENCE" default:"0.5"`
+	WhisperRetryAutoDetect bool    `envconfig:"WHISPER_RETRY_AUTO_DETECT" default:"true"`
+
+	// Transcripts longer than this (estimated tokens) are summarized per chunk before analysis (0 disables)
+	VideoChunkTokens int `envconfig:"VIDEO_CHUNK_TOKENS" default:"6000"`
+
+	// LLM Gateway (OpenAI-compatible, e.g. OpenRouter / LiteLLM)
+	LLMGatewayBaseURL string `envconfig:"LLM_GATEWAY_BASE_URL"`
+	LLMGatewayAPIKey  string `envconfig:"LLM_GATEWAY_API_KEY"`
+	LLMGatewayModel   string `envconfig:"LLM_GATEWAY_MODEL"`
+
+	// Anthropic Messages API (chat provider "anthropic")
+	AnthropicBaseURL   string `envconfig:"ANTHROPIC_BASE_URL" default:"https://api.anthropic.com"`
+	AnthropicAPIKey    string `envconfig:"ANTHROPIC_API_KEY"`
+	AnthropicModel     string `envconfig:"ANTHROPIC_MODEL"`
+	AnthropicMaxTokens int    `envconfig:"ANTHROPIC_MAX_TOKENS" default:"8192"`
+
+	// Content clustering (embeddings via the gateway; empty model uses local word vectors, 0 interval disables)
+	ContentEmbeddingModel  string        `envconfig:"CONTENT_EMBEDDING_MODEL"`
+	ContentClusterInterval time.Duration `envconfig:"CONTENT_CLUSTER_INTERVAL" default:"24h"`
+
+	// Glossary consistency check over video vocabulary (0 disables)
+	GlossaryCheckInterval time.Duration `envconfig:"GLOSSARY_CHECK_INTERVAL" default:"24h"`
+
+	// Media completeness check of active items (0 interval disables; repair regenerates dialog media)
+	MediaCheckInterval    time.Duration `envconfig:"MEDIA_CHECK_INTERVAL" default:"24h"`
+	MediaCheckConcurrency int           `envconfig:"MEDIA_CHECK_CONCURRENCY" default:"8"`
+	MediaCheckTimeout     time.Duration `envconfig:"MEDIA_CHECK_TIMEOUT" default:"10s"`
+	MediaRepairEnabled    bool          `envconfig:"MEDIA_REPAIR_ENABLED" default:"false"`
+
+	// R2 storage usage scan by content type and user (0 disables)
+	StorageUsageInterval time.Duration `envconfig:"STORAGE_USAGE_INTERVAL" default:"24h"`
+
+	// Analytics export: domain events and daily aggregates go to ANALYTICS_SINK (bigquery or clickhouse,
+	// empty disables) every ANALYTICS_EXPORT_INTERVAL; a new stream starts ANALYTICS_BACKFILL ago
+	AnalyticsSink           string        `envconfig:"ANALYTICS_SINK"`
+	AnalyticsExportInterval time.Duration `envconfig:"ANALYTICS_EXPORT_INTERVAL" default:"1h"`
+	AnalyticsBackfill       time.Duration `envconfig:"ANALYTICS_BACKFILL" default:"720h"`
+	ClickHouseURL           string        `envconfig:"CLICKHOUSE_URL"`
+	ClickHouseDatabase      string        `envconfig:"CLICKHOUSE_DATABASE" default:"uwu_analytics"`
+	ClickHouseUser          string        `envconfig:"CLICKHOUSE_USER"`
+	ClickHousePassword      string        `envconfig:"CLICKHOUSE_PASSWORD"`
+	BigQueryProjectID       string        `envconfig:"BIGQUERY_PROJECT_ID"` // defaults to the project of GEMINI_SA_BASE64
+	BigQueryDataset         string        `envconfig:"BIGQUERY_DATASET" default:"uwu_analytics"`
+
+	// Organization (bring-your-own-key) provider credentials, encrypted with a Base64 32-byte key
+	// (empty disables them, every call uses the platform keys)
+	CredentialsEncryptionKey string        `envconfig:"CREDENTIALS_ENCRYPTION_KEY"`
+	CredentialsCacheTTL      time.Duration `envconfig:"CREDENTIALS_CACHE_TTL" default:"1m"`
+
+	// Encryption at rest for retell and speaking transcripts/feedback: key ring "id:base64key,..." of
+	// 32-byte keys, the first encrypts and the rest only decrypt until re-encrypted (empty stores plaintext)
+	FieldEncryptionKeys    string        `envconfig:"FIELD_ENCRYPTION_KEYS"`
+	FieldReencryptInterval time.Duration `envconfig:"FIELD_REENCRYPT_INTERVAL" default:"24h"`
+
+	// Fault injection for resilience testing, refused when SERVER_ENV=production: error rate and added
+	// latency per target, e.g. "whisper:0.2,r2:0.05" and "gemini:3s" (azure_chat, whisper, azure_speech, gemini, r2, redis)
+	FaultErrorRates map[string]float64       `envconfig:"FAULT_ERROR_RATES"`
+	FaultLatency    map[string]time.Duration `envconfig:"FAULT_LATENCY"`
+
+	// Fake AI providers for load tests, refused when SERVER_ENV=production: chat, TTS, pronunciation,
+	// transcription and images answer with canned output after FAKE_PROVIDER_LATENCY
+	FakeProviders       bool          `envconfig:"FAKE_PROVIDERS" default:"false"`
+	FakeProviderLatency time.Duration `envconfig:"FAKE_PROVIDER_LATENCY" default:"0s"`
+
+	// Ollama (self-hosted, for low-stakes generation)
+	OllamaBaseURL  string `envconfig:"OLLAMA_BASE_URL" default:"http://localhost:11434"`
+	OllamaModel    string `envconfig:"OLLAMA_MODEL"`
+	OllamaJSONMode bool   `envconfig:"OLLAMA_JSON_MODE" default:"true"`
+
+	// Chat provider fallback chain, tried in order (azure, gateway, anthropic, ollama)
+	LLMProviderChain []string `envconfig:"LLM_PROVIDER_CHAIN" default:"azure"`
+
+	// Per-feature chains, e.g. "video_details:ollama|azure,chat_reply:gateway"
+	LLMFeatureProviders map[string]string `envconfig:"LLM_FEATURE_PROVIDERS"`
+
+	// Providers a request may pin with the X-Chat-Provider header, used alone instead of the chains (empty disables it)
+	LLMSelectableProviders []string `envconfig:"LLM_SELECTABLE_PROVIDERS"`
+
+	// Prompt token budget (long transcripts are truncated to fit, 0 disables)
+	PromptMaxTokens int `envconfig:"PROMPT_MAX_TOKENS" default:"100000"`
+
+	// Conversation memory (chat and sparring prompt history in Redis)
+	MemoryTTL         time.Duration `envconfig:"MEMORY_TTL" default:"24h"`
+	MemoryMaxMessages int           `envconfig:"MEMORY_MAX_MESSAGES" default:"30"`
+	MemoryKeepRecent  int           `envconfig:"MEMORY_KEEP_RECENT" default:"10"`
+
+	// Speech practice and sparring sessions idle longer than this start over on resume (0 never expires)
+	PracticeSessionIdleTTL time.Duration `envconfig:"PRACTICE_SESSION_IDLE_TTL" default:"24h"`
+
 	// Logging
 	LogLevel  string `envconfig:"LOG_LEVEL" default:"info"`
 	LogFormat string `envconfig:"LOG_FORMAT" default:"json"`
@@ -60,6 +281,50 @@ type Config struct {
 	// Redis
 	RedisURL string `envconfig:"REDIS_URL"`
 
+	// Batch retention in Redis; the *_BY_TYPE maps override it per batch type
+	// (upload_video, evaluate_retell, generate_dialog, regenerate_turn, build_bundle). Finished batches are archived to Postgres.
+	BatchProcessingTTL       time.Duration            `envconfig:"BATCH_PROCESSING_TTL" default:"3h"`
+	BatchCompletedTTL        time.Duration            `envconfig:"BATCH_COMPLETED_TTL" default:"10m"`
+	BatchProcessingTTLByType map[string]time.Duration `envconfig:"BATCH_PROCESSING_TTL_BY_TYPE"`
+	BatchCompletedTTLByType  map[string]time.Duration `envconfig:"BATCH_COMPLETED_TTL_BY_TYPE"`
+
+	// Batch results larger than this are stored in R2 and returned as result_url (0 keeps them all in Redis)
+	BatchResultMaxBytes int `envconfig:"BATCH_RESULT_MAX_BYTES" default:"65536"`
+
+	// Alternate encodings uploaded next to generated dialog audio and images (empty disables them)
+	MediaAudioVariants []string `envconfig:"MEDIA_AUDIO_VARIANTS" default:"opus"`
+	MediaImageVariants []string `envconfig:"MEDIA_IMAGE_VARIANTS" default:"webp,avif"`
+
+	// Style appended to dialog image prompts when neither the request nor the organization names one
+	// (empty keeps the model's own), and replacement prompt text per preset, e.g.
+	// "vector:Flat icon style;thick outlines"
+	ImageStyleDefault string            `envconfig:"IMAGE_STYLE_DEFAULT"`
+	ImageStylePrompts map[string]string `envconfig:"IMAGE_STYLE_PROMPTS"`
+
+	// Regenerations of a dialog image OCR finds text in (0 skips the OCR check)
+	ImageTextRetries int `envconfig:"IMAGE_TEXT_RETRIES" default:"2"`
+
+	// Share one image among dialogs whose image prompt and style match instead of generating it again
+	ImageReuseEnabled bool `envconfig:"IMAGE_REUSE_ENABLED" default:"true"`
+
+	// Offline bundles: how long a bundle is kept, how long its download URL is valid, and its size cap (0 disables it)
+	BundleTTL      time.Duration `envconfig:"BUNDLE_TTL" default:"24h"`
+	BundleURLTTL   time.Duration `envconfig:"BUNDLE_URL_TTL" default:"1h"`
+	BundleMaxBytes int64         `envconfig:"BUNDLE_MAX_BYTES" default:"524288000"`
+
+	// Open reports that deactivate a video or dialog until a moderator reviews it (0 disables it)
+	ModerationReportThreshold int `envconfig:"MODERATION_REPORT_THRESHOLD" default:"3"`
+
+	// Critic score (0-100) below which a generated dialog is held for review (0 skips the critic)
+	QualityReviewThreshold int `envconfig:"QUALITY_REVIEW_THRESHOLD" default:"60"`
+
+	// Cached romanizations of zh/ja/ko text (the cache is cleared when full)
+	RomanizeCacheSize int `envconfig:"ROMANIZE_CACHE_SIZE" default:"10000"`
+
+	// Word frequency lists (<language code>.txt, most frequent first) used to rank vocabulary (empty dir disables)
+	WordFrequencyDir     string `envconfig:"WORD_FREQUENCY_DIR"`
+	WordFrequencyMaxRank int    `envconfig:"WORD_FREQUENCY_MAX_RANK" default:"50000"`
+
 	// Database
 	PostgresUser     string `envconfig:"POSTGRES_USER" default:"uwu_user"`
 	PostgresPassword string `envconfig:"POSTGRES_PASSWORD" default:"uwu_password"`
@@ -67,12 +332,22 @@ type Config struct {
 	PostgresPort     int    `envconfig:"POSTGRES_PORT" default:"5432"`
 	PostgresDB       string `envconfig:"POSTGRES_DB" default:"uwu_service"`
 
+	// Queries taking at least this long are logged with their calling repository method (0 disables the log)
+	DBSlowQueryThreshold time.Duration `envconfig:"DB_SLOW_QUERY_THRESHOLD" default:"500ms"`
+
 	// Cloudflare R2
 	CloudflareAccessKeyID string `envconfig:"CLOUDFLARE_ACCESS_KEY_ID"`
 	CloudflareSecretKey   string `envconfig:"CLOUDFLARE_SECRET_ACCESS_KEY"`
 	CloudflareR2Endpoint  string `envconfig:"CLOUDFLARE_R2_ENDPOINT"`
 	CloudflarePublicURL   string `envconfig:"CLOUDFLARE_PUBLIC_URL"`
 	CloudflareBucketName  string `envconfig:"CLOUDFLARE_BUCKET_NAME"`
+
+	// R2 uploads: objects at least R2_MULTIPART_THRESHOLD bytes go up in R2_PART_SIZE parts (min 5 MiB),
+	// and a failed request is retried R2_UPLOAD_RETRIES times, waiting R2_RETRY_BACKOFF doubled each time
+	R2MultipartThreshold int64         `envconfig:"R2_MULTIPART_THRESHOLD" default:"67108864"`
+	R2PartSize           int64         `envconfig:"R2_PART_SIZE" default:"16777216"`
+	R2UploadRetries      int           `envconfig:"R2_UPLOAD_RETRIES" default:"3"`
+	R2RetryBackoff       time.Duration `envconfig:"R2_RETRY_BACKOFF" default:"500ms"`
 }
 
 // Load loads configuration from environment variables.
@@ -87,6 +362,20 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// legacyAdminPass was the committed default of DEV_ADMIN_PASS; it is public, so it never enables admin routes.
+const legacyAdminPass = "secretpass"
+
+// AdminEnabled reports whether admin credentials were explicitly configured.
+func (c *Config) AdminEnabled() bool {
+	return c.DevAdminUser != "" && c.DevAdminPass != "" && c.DevAdminPass != legacyAdminPass
+}
+
+// ImpersonationEnabled reports whether impersonation has credentials of its own, separate from the admin ones.
+func (c *Config) ImpersonationEnabled() bool {
+	return c.AdminEnabled() && c.ImpersonationUser != "" && c.ImpersonationPass != "" &&
+		c.ImpersonationPass != c.DevAdminPass && c.ImpersonationPass != legacyAdminPass
+}
+
 // HTTPAddress returns the HTTP server address.
 func (c *Config) HTTPAddress() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.HTTPPort)
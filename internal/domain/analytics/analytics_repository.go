@@ -0,0 +1,196 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// AnalyticsRepository reads the rows of each export window and tracks how far each stream was exported.
+type AnalyticsRepository interface {
+	GetCursor(ctx context.Context, stream string) (*time.Time, *errors.AppError)
+	SaveCursor(ctx context.Context, stream string, until time.Time) *errors.AppError
+	ListRows(ctx context.Context, stream string, from, to time.Time) ([]map[string]any, *errors.AppError)
+}
+
+type analyticsRepository struct {
+	db *client.PostgresClient
+}
+
+// NewAnalyticsRepository creates a new analytics repository.
+func NewAnalyticsRepository(db *client.PostgresClient) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// streamQueries select the rows of [$1, $2) as JSON objects. insert_id makes a re-exported row
+// recognizable as a duplicate.
+var streamQueries = map[string]string{
+	// Events can be sent up to 7 days after they occurred, so only the recent partitions are read
+	STREAM_ACTIVITY_EVENTS: `
+		SELECT jsonb_build_object(
+			'insert_id', 'event:' || id,
+			'event_id', id,
+			'user_id', user_id,
+			'event_type', event_type,
+			'learning_id', learning_id,
+			'duration_ms', duration_ms,
+			'metadata', COALESCE(metadata, '{}'::jsonb)::text,
+			'occurred_at', occurred_at,
+			'received_at', created_at
+		)
+		FROM activity_events
+		WHERE created_at >= $1 AND created_at < $2
+		AND occurred_at >= $1 - INTERVAL '8 days'
+		ORDER BY created_at, id
+	`,
+	STREAM_QUIZ_ATTEMPTS: `
+		SELECT jsonb_build_object(
+			'insert_id', 'quiz:' || (attempt->>'attempt_id'),
+			'attempt_id', attempt->>'attempt_id',
+			'user_id', ua.user_id,
+			'learning_id', ua.learning_id,
+			'language', l.language,
+			'level', l.level,
+			'quiz_score', (attempt->>'quiz_score')::float8,
+			'answer_count', CASE WHEN jsonb_typeof(attempt->'answers') = 'array' THEN jsonb_array_length(attempt->'answers') ELSE 0 END,
+			'submitted_at', (attempt->>'submitted_at')::timestamptz
+		)
+		FROM user_actions ua
+		JOIN learning_items l ON l.id = ua.learning_id
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(ua.metadata->'attempts', '[]'::jsonb)) AS attempt
+		WHERE ua.action_type = 'submit_quiz' AND ua.updated_at >= $1
+		AND (attempt->>'submitted_at')::timestamptz >= $1 AND (attempt->>'submitted_at')::timestamptz < $2
+	`,
+	STREAM_RETELL_ATTEMPTS: `
+		SELECT jsonb_build_object(
+			'insert_id', 'retell:' || (attempt->>'attempt_id'),
+			'attempt_id', attempt->>'attempt_id',
+			'user_id', ua.user_id,
+			'learning_id', ua.learning_id,
+			'language', l.language,
+			'level', l.level,
+			'status', COALESCE(attempt->>'status', ''),
+			'retell_score', (attempt->>'retell_score')::float8,
+			'passed', COALESCE((attempt->>'passed')::boolean, false),
+			'matched_key_points', CASE WHEN jsonb_typeof(attempt->'matches_key_points') = 'array' THEN jsonb_array_length(attempt->'matches_key_points') ELSE 0 END,
+			'submitted_at', (attempt->>'submitted_at')::timestamptz
+		)
+		FROM user_actions ua
+		JOIN learning_items l ON l.id = ua.learning_id
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(ua.metadata->'attempts', '[]'::jsonb)) AS attempt
+		WHERE ua.action_type = 'submit_retell' AND ua.updated_at >= $1
+		AND (attempt->>'submitted_at')::timestamptz >= $1 AND (attempt->>'submitted_at')::timestamptz < $2
+	`,
+	STREAM_GENERATION_BATCHES: `
+		SELECT jsonb_build_object(
+			'insert_id', 'batch:' || batch_id,
+			'batch_id', batch_id,
+			'batch_type', batch_type,
+			'status', status,
+			'total_jobs', total_jobs,
+			'completed_jobs', completed_jobs,
+			'duration_seconds', EXTRACT(EPOCH FROM (updated_at - created_at))::float8,
+			'created_at', created_at,
+			'finished_at', updated_at,
+			'archived_at', archived_at
+		)
+		FROM batch_history
+		WHERE archived_at >= $1 AND archived_at < $2
+		ORDER BY archived_at
+	`,
+	// [$1, $2) is a whole UTC day
+	STREAM_DAILY_USAGE: `
+		WITH quiz AS (
+			SELECT (attempt->>'quiz_score')::float8 AS score
+			FROM user_actions ua
+			CROSS JOIN LATERAL jsonb_array_elements(COALESCE(ua.metadata->'attempts', '[]'::jsonb)) AS attempt
+			WHERE ua.action_type = 'submit_quiz' AND ua.updated_at >= $1
+			AND (attempt->>'submitted_at')::timestamptz >= $1 AND (attempt->>'submitted_at')::timestamptz < $2
+		), retell AS (
+			SELECT (attempt->>'retell_score')::float8 AS score, COALESCE((attempt->>'passed')::boolean, false) AS passed
+			FROM user_actions ua
+			CROSS JOIN LATERAL jsonb_array_elements(COALESCE(ua.metadata->'attempts', '[]'::jsonb)) AS attempt
+			WHERE ua.action_type = 'submit_retell' AND ua.updated_at >= $1
+			AND (attempt->>'submitted_at')::timestamptz >= $1 AND (attempt->>'submitted_at')::timestamptz < $2
+		)
+		SELECT jsonb_build_object(
+			'insert_id', 'daily:' || to_char($1::timestamptz AT TIME ZONE 'UTC', 'YYYY-MM-DD'),
+			'date', to_char($1::timestamptz AT TIME ZONE 'UTC', 'YYYY-MM-DD'),
+			'active_users', (SELECT COUNT(DISTINCT user_id) FROM activity_events WHERE occurred_at >= $1 AND occurred_at < $2),
+			'events', (SELECT COUNT(*) FROM activity_events WHERE occurred_at >= $1 AND occurred_at < $2),
+			'new_users', (SELECT COUNT(*) FROM users WHERE created_at >= $1 AND created_at < $2),
+			'new_videos', (SELECT COUNT(*) FROM learning_items WHERE feature_id = 1 AND created_at >= $1 AND created_at < $2),
+			'new_dialogs', (SELECT COUNT(*) FROM learning_items WHERE feature_id = 2 AND created_at >= $1 AND created_at < $2),
+			'quiz_attempts', (SELECT COUNT(*) FROM quiz),
+			'avg_quiz_score', (SELECT AVG(score) FROM quiz),
+			'retell_attempts', (SELECT COUNT(*) FROM retell),
+			'avg_retell_score', (SELECT AVG(score) FROM retell),
+			'retell_passed', (SELECT COUNT(*) FILTER (WHERE passed) FROM retell),
+			'batches', (SELECT COUNT(*) FROM batch_history WHERE archived_at >= $1 AND archived_at < $2),
+			'failed_batches', (SELECT COUNT(*) FROM batch_history WHERE archived_at >= $1 AND archived_at < $2 AND status = 'failed'),
+			'generated_jobs', (SELECT COALESCE(SUM(completed_jobs), 0) FROM batch_history WHERE archived_at >= $1 AND archived_at < $2)
+		)
+	`,
+}
+
+// GetCursor returns the end of the last exported window of a stream, or nil before its first export.
+func (r *analyticsRepository) GetCursor(ctx context.Context, stream string) (*time.Time, *errors.AppError) {
+	var until time.Time
+	err := r.db.Pool.QueryRow(ctx, `SELECT exported_until FROM analytics_export_cursors WHERE stream = $1`, stream).Scan(&until)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get analytics export cursor", err)
+	}
+	return &until, nil
+}
+
+// SaveCursor records that a stream was exported up to until.
+func (r *analyticsRepository) SaveCursor(ctx context.Context, stream string, until time.Time) *errors.AppError {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO analytics_export_cursors (stream, exported_until, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (stream) DO UPDATE SET exported_until = EXCLUDED.exported_until, updated_at = NOW()
+	`, stream, until)
+	if err != nil {
+		return errors.InternalWrap("failed to save analytics export cursor", err)
+	}
+	return nil
+}
+
+// ListRows returns the rows of a stream in [from, to).
+func (r *analyticsRepository) ListRows(ctx context.Context, stream string, from, to time.Time) ([]map[string]any, *errors.AppError) {
+	query, ok := streamQueries[stream]
+	if !ok {
+		return nil, errors.Validation("unknown analytics stream " + stream)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list "+stream+" for export", err)
+	}
+	defer rows.Close()
+
+	result := make([]map[string]any, 0)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, errors.InternalWrap("failed to scan "+stream+" row", err)
+		}
+		var row map[string]any
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return nil, errors.InternalWrap("failed to decode "+stream+" row", err)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list "+stream+" for export", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,22 @@
+package analytics
+
+// Streams exported to the analytics sink, each to the table of the same name
+const (
+	STREAM_ACTIVITY_EVENTS    = "activity_events"    // client events, by when they were received
+	STREAM_QUIZ_ATTEMPTS      = "quiz_attempts"      // gist quiz submissions with their score
+	STREAM_RETELL_ATTEMPTS    = "retell_attempts"    // retell submissions with their evaluation
+	STREAM_GENERATION_BATCHES = "generation_batches" // finished generation batches, by when they were archived
+	STREAM_DAILY_USAGE        = "daily_usage"        // one row per completed UTC day
+)
+
+// Streams are exported in this order on every run.
+var Streams = []string{
+	STREAM_ACTIVITY_EVENTS,
+	STREAM_QUIZ_ATTEMPTS,
+	STREAM_RETELL_ATTEMPTS,
+	STREAM_GENERATION_BATCHES,
+	STREAM_DAILY_USAGE,
+}
+
+// ExportPayload is the payload for the analytics export job
+type ExportPayload struct{}
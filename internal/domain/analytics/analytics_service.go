@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+const (
+	// exportWindow is the most one query reads of a stream.
+	exportWindow = 24 * time.Hour
+
+	// exportLag keeps the newest hour out of the export, so retell evaluations and batches still
+	// running have settled and late inserts are not skipped by the cursor.
+	exportLag = time.Hour
+
+	// insertChunk is how many rows go to the sink per request.
+	insertChunk = 500
+)
+
+// AnalyticsOptions controls what the first export of a stream covers.
+type AnalyticsOptions struct {
+	Backfill time.Duration // a stream without a cursor starts this long ago (UTC midnight)
+}
+
+// AnalyticsService exports domain events and daily aggregates to the analytics sink.
+type AnalyticsService struct {
+	analyticsRepo AnalyticsRepository
+	sinkRepo      SinkRepository
+	opts          AnalyticsOptions
+}
+
+// NewAnalyticsService creates a new analytics service.
+func NewAnalyticsService(analyticsRepo AnalyticsRepository, sinkRepo SinkRepository, opts AnalyticsOptions) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		sinkRepo:      sinkRepo,
+		opts:          opts,
+	}
+}
+
+// Worker: Export
+// Exports every stream from its cursor up to an hour ago, one window at a time. The cursor advances after
+// each window is inserted, so a failed run resumes where it stopped and repeats at most one window.
+func (s *AnalyticsService) Export(ctx context.Context, payload ExportPayload) *errors.AppError {
+	until := time.Now().UTC().Add(-exportLag)
+	for _, stream := range Streams {
+		if err := s.exportStream(ctx, stream, until); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AnalyticsService) exportStream(ctx context.Context, stream string, until time.Time) *errors.AppError {
+	// Daily usage only covers days that are over
+	if stream == STREAM_DAILY_USAGE {
+		until = until.Truncate(24 * time.Hour)
+	}
+
+	cursor, err := s.analyticsRepo.GetCursor(ctx, stream)
+	if err != nil {
+		return err
+	}
+	from := time.Now().UTC().Add(-s.opts.Backfill).Truncate(24 * time.Hour)
+	if cursor != nil {
+		from = cursor.UTC()
+	}
+
+	for from.Before(until) {
+		to := from.Add(exportWindow)
+		if to.After(until) {
+			to = until
+		}
+
+		rows, err := s.analyticsRepo.ListRows(ctx, stream, from, to)
+		if err != nil {
+			return err
+		}
+		for start := 0; start < len(rows); start += insertChunk {
+			if err := s.sinkRepo.Insert(ctx, stream, rows[start:min(start+insertChunk, len(rows))]); err != nil {
+				return err
+			}
+		}
+		if err := s.analyticsRepo.SaveCursor(ctx, stream, to); err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
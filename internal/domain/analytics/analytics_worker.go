@@ -0,0 +1,29 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_EXPORT_ANALYTICS = "worker_export_analytics"
+)
+
+// RegisterAnalyticsWorkers register analytics workers to queue
+func RegisterAnalyticsWorkers(queue *client.QueueClient, service *AnalyticsService) {
+
+	// Job Export Analytics
+	queue.RegisterWorker(WORKER_EXPORT_ANALYTICS, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(ExportPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_EXPORT_ANALYTICS)
+		}
+		if err := service.Export(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
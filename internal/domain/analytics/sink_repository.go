@@ -0,0 +1,29 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// SinkRepository appends exported rows to the analytics warehouse.
+type SinkRepository interface {
+	Insert(ctx context.Context, table string, rows []map[string]any) *errors.AppError
+}
+
+type sinkRepository struct {
+	sink client.AnalyticsSink
+}
+
+// NewSinkRepository creates a new analytics sink repository.
+func NewSinkRepository(sink client.AnalyticsSink) SinkRepository {
+	return &sinkRepository{sink: sink}
+}
+
+func (r *sinkRepository) Insert(ctx context.Context, table string, rows []map[string]any) *errors.AppError {
+	if err := r.sink.Insert(ctx, table, rows); err != nil {
+		return errors.InternalWrap("failed to export rows to "+r.sink.Name(), err)
+	}
+	return nil
+}
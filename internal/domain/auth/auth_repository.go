@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
 	"github.com/windfall/uwu_service/pkg/errors"
 )
 
@@ -30,17 +31,16 @@ type User struct {
 type AuthRepository interface {
 	RegisterUser(ctx context.Context, user *User) *errors.AppError
 	GetByEmail(ctx context.Context, email string) (*User, *errors.AppError)
+	GetByID(ctx context.Context, userID string) (*User, *errors.AppError)
 	GenerateToken(user *User) (string, *errors.AppError)
+	GenerateImpersonationToken(user *User, admin string, ttl time.Duration) (string, time.Time, *errors.AppError)
+	GenerateDemoToken(user *User, expiresAt time.Time) (string, *errors.AppError)
 	ValidateToken(tokenString string) (*TokenClaims, *errors.AppError)
 }
 
-// TokenClaims represents the structured claims inside the JWT
-type TokenClaims struct {
-	UserID      string
-	Email       string
-	DisplayName string
-	AvatarURL   string
-}
+// TokenClaims represents the structured claims inside the JWT. The type lives in middleware, which
+// reads them on every request and cannot import this package.
+type TokenClaims = middleware.TokenClaims
 
 // AuthRepository struct
 type authRepository struct {
@@ -113,6 +113,37 @@ func (r *authRepository) GetByEmail(ctx context.Context, email string) (*User, *
 	return &user, nil
 }
 
+// GetByID retrieves a user by ID.
+func (r *authRepository) GetByID(ctx context.Context, userID string) (*User, *errors.AppError) {
+	query := `
+        SELECT id, email, password_hash, display_name, avatar_url, bio, settings, created_at, updated_at
+        FROM users
+        WHERE id = $1
+    `
+
+	var user User
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.DisplayName,
+		&user.AvatarURL,
+		&user.Bio,
+		&user.Settings,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("user not found")
+		}
+		return nil, errors.InternalWrap("failed to get user by id", err)
+	}
+
+	return &user, nil
+}
+
 // ValidateToken parses and validates a JWT token string, returning the structured claims.
 func (s *authRepository) ValidateToken(tokenString string) (*TokenClaims, *errors.AppError) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
@@ -138,12 +169,16 @@ func (s *authRepository) ValidateToken(tokenString string) (*TokenClaims, *error
 	email, _ := claims["email"].(string)
 	displayName, _ := claims["display_name"].(string)
 	avatarURL, _ := claims["avatar_url"].(string)
+	impersonatedBy, _ := claims["imp"].(string)
+	demo, _ := claims["demo"].(bool)
 
 	return &TokenClaims{
-		UserID:      userID,
-		Email:       email,
-		DisplayName: displayName,
-		AvatarURL:   avatarURL,
+		UserID:         userID,
+		Email:          email,
+		DisplayName:    displayName,
+		AvatarURL:      avatarURL,
+		ImpersonatedBy: impersonatedBy,
+		Demo:           demo,
 	}, nil
 }
 
@@ -166,3 +201,42 @@ func (s *authRepository) GenerateToken(user *User) (string, *errors.AppError) {
 	}
 	return jwtString, nil
 }
+
+// GenerateImpersonationToken issues a short-lived token that acts as user on behalf of admin.
+func (s *authRepository) GenerateImpersonationToken(user *User, admin string, ttl time.Duration) (string, time.Time, *errors.AppError) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := jwt.MapClaims{
+		"sub":          user.ID.String(),
+		"email":        user.Email,
+		"display_name": user.DisplayName,
+		"imp":          admin,
+		"iat":          now.Unix(),
+		"exp":          expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwtString, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", time.Time{}, errors.InternalWrap("failed to generate impersonation token", err)
+	}
+	return jwtString, expiresAt, nil
+}
+
+// GenerateDemoToken issues a token for an ephemeral demo account that expires with the account.
+func (s *authRepository) GenerateDemoToken(user *User, expiresAt time.Time) (string, *errors.AppError) {
+	claims := jwt.MapClaims{
+		"sub":          user.ID.String(),
+		"display_name": user.DisplayName,
+		"demo":         true,
+		"iat":          time.Now().Unix(),
+		"exp":          expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwtString, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", errors.InternalWrap("failed to generate demo token", err)
+	}
+	return jwtString, nil
+}
@@ -1,10 +1,10 @@
 package auth
 
 import (
-	"encoding/json"
 	"net/http"
 	"strings"
 
+	"github.com/windfall/uwu_service/internal/infra/middleware"
 	"github.com/windfall/uwu_service/pkg/errors"
 )
 
@@ -28,7 +28,7 @@ type RegisterInput struct {
 
 func (req *RegisterRequest) ParseAndValidate(r *http.Request) error {
 	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+	if err := middleware.DecodeJSON(r, req); err != nil {
 		return errors.Validation("invalid request body")
 	}
 	if req.Email == "" || req.Password == "" {
@@ -77,7 +77,7 @@ type LoginInput struct {
 
 func (req *LoginRequest) ParseAndValidate(r *http.Request) error {
 	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+	if err := middleware.DecodeJSON(r, req); err != nil {
 		return errors.Validation("invalid request body")
 	}
 	if req.Email == "" || req.Password == "" {
@@ -0,0 +1,47 @@
+package batch
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BatchHandler handles batch HTTP endpoints.
+type BatchHandler struct {
+	service *BatchService
+	log     *slog.Logger
+}
+
+// NewBatchHandler creates a new batch handler.
+func NewBatchHandler(service *BatchService, log *slog.Logger) *BatchHandler {
+	return &BatchHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// StreamEvents handles GET /api/v1/batches/{batchID}/events
+func (h *BatchHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	var req StreamBatchRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.CheckOwner(r.Context(), req.BatchID, req.UserID); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	stream, err := response.NewEventStream(w)
+	if err != nil {
+		h.log.Error("Failed to open event stream", "batch_id", req.BatchID, "error", err)
+		return
+	}
+
+	// Headers are sent; errors can only end the stream
+	if err := h.service.StreamBatch(r.Context(), req.BatchID, stream); err != nil && r.Context().Err() == nil {
+		h.log.Warn("Batch event stream ended", "batch_id", req.BatchID, "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package batch
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BatchRepository reads batches of any type from the batch store.
+type BatchRepository interface {
+	IsOwner(ctx context.Context, batchID, userID string) (bool, *errors.AppError)
+	GetBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError)
+}
+
+type batchRepository struct {
+	db    *client.PostgresClient
+	store *client.BatchStore
+}
+
+// NewBatchRepository creates a new batch repository.
+func NewBatchRepository(db *client.PostgresClient, store *client.BatchStore) BatchRepository {
+	return &batchRepository{db: db, store: store}
+}
+
+// IsOwner reports whether the user started the batch: it works on a video or dialog they created,
+// or evaluates one of their retell attempts.
+func (r *batchRepository) IsOwner(ctx context.Context, batchID, userID string) (bool, *errors.AppError) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM batches b
+			WHERE b.batch_id = $1 AND (
+				(b.reference_type IN ('video', 'dialog') AND EXISTS (
+					SELECT 1 FROM learning_items l WHERE l.id::text = b.reference_id AND l.created_by = $2))
+				OR (b.reference_type = 'retell_attempt' AND EXISTS (
+					SELECT 1 FROM user_actions ua
+					WHERE ua.user_id::text = $2 AND ua.action_type = 'submit_retell' AND ua.deleted_at IS NULL
+						AND ua.metadata->'attempts' @> jsonb_build_array(jsonb_build_object('attempt_id', b.batch_id))))
+			)
+		)
+	`
+
+	var ok bool
+	if err := r.db.Pool.QueryRow(ctx, query, batchID, userID).Scan(&ok); err != nil {
+		return false, errors.InternalWrap("failed to check batch owner", err)
+	}
+	return ok, nil
+}
+
+func (r *batchRepository) GetBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError) {
+	batch, _, err := r.store.Get(ctx, batchID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get batch", err)
+	}
+	if batch == nil {
+		return nil, errors.NotFound("batch not found")
+	}
+	return batch, nil
+}
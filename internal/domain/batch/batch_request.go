@@ -0,0 +1,32 @@
+package batch
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// -------------------------------------------------------------------------
+// Stream Batch Request
+// -------------------------------------------------------------------------
+
+// StreamBatchRequest is the HTTP request struct for streaming batch progress
+type StreamBatchRequest struct {
+	UserID  string
+	BatchID string
+}
+
+func (req *StreamBatchRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.BatchID = chi.URLParam(r, "batchID")
+	if req.BatchID == "" {
+		return errors.Validation("batch id is required")
+	}
+	return nil
+}
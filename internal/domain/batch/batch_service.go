@@ -0,0 +1,98 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Stream events
+const (
+	EVENT_BATCH = "batch" // the batch with all its jobs, sent on connect and after every change
+	EVENT_END   = "end"   // the batch completed or failed; clients close the stream
+)
+
+const (
+	// keepaliveInterval pings idle streams and re-reads the batch in case a change was missed
+	keepaliveInterval = 15 * time.Second
+	// maxStreamDuration closes streams of batches that never finish; clients reconnect
+	maxStreamDuration = 30 * time.Minute
+)
+
+// BatchStream is where batch events are written (see response.EventStream).
+type BatchStream interface {
+	Send(event string, data interface{}) error
+	Ping() error
+}
+
+// BatchService serves the progress of background batches.
+type BatchService struct {
+	batchRepo BatchRepository
+	events    *client.BatchEvents
+}
+
+// NewBatchService creates a new BatchService.
+func NewBatchService(batchRepo BatchRepository, events *client.BatchEvents) *BatchService {
+	return &BatchService{
+		batchRepo: batchRepo,
+		events:    events,
+	}
+}
+
+// CheckOwner answers 404 unless the user started the batch.
+func (s *BatchService) CheckOwner(ctx context.Context, batchID, userID string) *errors.AppError {
+	ok, err := s.batchRepo.IsOwner(ctx, batchID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.NotFound("batch not found")
+	}
+	return nil
+}
+
+// StreamBatch sends the batch and then every change to it until it completes or fails, ctx is
+// done or the stream reaches maxStreamDuration. It returns the error that ended the stream early.
+func (s *BatchService) StreamBatch(ctx context.Context, batchID string, stream BatchStream) error {
+	// Subscribe before the first read so no change in between is lost
+	changes, stop := s.events.Subscribe(batchID)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(ctx, maxStreamDuration)
+	defer cancel()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	var last []byte
+	for {
+		batch, err := s.batchRepo.GetBatch(ctx, batchID)
+		if err != nil {
+			return err
+		}
+
+		if body, _ := json.Marshal(batch); !bytes.Equal(body, last) {
+			last = body
+			if err := stream.Send(EVENT_BATCH, batch); err != nil {
+				return err
+			}
+		}
+		if batch.Status == "completed" || batch.Status == "failed" {
+			return stream.Send(EVENT_END, map[string]string{"status": batch.Status})
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changes:
+		case <-keepalive.C:
+			if err := stream.Ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
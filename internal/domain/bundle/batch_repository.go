@@ -0,0 +1,78 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BATCH_TYPE_BUILD_BUNDLE is the batch type (key of the per-type retention config).
+const BATCH_TYPE_BUILD_BUNDLE = "build_bundle"
+
+// BATCH_REFERENCE_BUNDLE marks batches that build a bundle (batch ID = bundle ID).
+const BATCH_REFERENCE_BUNDLE = "bundle"
+
+// Batch processes:
+const (
+	PROCESS_COLLECT_CONTENT = "collect_content"
+	PROCESS_PACKAGE_MEDIA   = "package_media"
+	PROCESS_UPLOAD_BUNDLE   = "upload_bundle"
+)
+
+// Batch status:
+const (
+	BATCH_PENDING    = client.BatchPending
+	BATCH_PROCESSING = client.BatchProcessing
+	BATCH_COMPLETED  = client.BatchCompleted
+	BATCH_FAILED     = client.BatchFailed
+	BATCH_UNKNOWN    = client.BatchUnknown
+)
+
+func GetProcessNames() []string {
+	return []string{
+		PROCESS_COLLECT_CONTENT,
+		PROCESS_PACKAGE_MEDIA,
+		PROCESS_UPLOAD_BUNDLE,
+	}
+}
+
+// BatchRepository interface
+type BatchRepository interface {
+	GetBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError)
+	CreateBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError)
+	UpdateJob(ctx context.Context, batchID, jobName, status, jobErr string) error
+	SetBatchResult(ctx context.Context, batchID string, result json.RawMessage) error
+}
+
+type batchRepository struct {
+	batches *client.CachedBatches
+}
+
+// NewBatchRepository creates a new bundle batch repository.
+func NewBatchRepository(batches *client.CachedBatches) BatchRepository {
+	return &batchRepository{batches: batches}
+}
+
+// GetBatch returns the full batch status including all jobs, from the Redis cache or else from Postgres.
+func (r *batchRepository) GetBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError) {
+	return r.batches.Get(ctx, batchID, GetProcessNames())
+}
+
+// CreateBatch initializes a batch and its jobs in Postgres and caches them in Redis.
+func (r *batchRepository) CreateBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError) {
+	return r.batches.Create(ctx, batchID, BATCH_TYPE_BUILD_BUNDLE, BATCH_REFERENCE_BUNDLE, batchID, GetProcessNames())
+}
+
+// UpdateJob updates a single job within the batch and recalculates batch state.
+func (r *batchRepository) UpdateJob(ctx context.Context, batchID, jobName, status, jobErr string) error {
+	return r.batches.UpdateJob(ctx, batchID, jobName, status, jobErr, GetProcessNames())
+}
+
+// SetBatchResult stores the final serialized result in the batch,
+// or a result_url pointing at R2 when the result is over the size limit.
+func (r *batchRepository) SetBatchResult(ctx context.Context, batchID string, result json.RawMessage) error {
+	return r.batches.SetResult(ctx, batchID, result)
+}
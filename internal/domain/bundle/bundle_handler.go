@@ -0,0 +1,68 @@
+package bundle
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BundleHandler handles offline bundle HTTP endpoints.
+type BundleHandler struct {
+	service *BundleService
+	queue   *client.QueueClient
+}
+
+// NewBundleHandler creates a new BundleHandler.
+func NewBundleHandler(service *BundleService, queue *client.QueueClient) *BundleHandler {
+	return &BundleHandler{
+		service: service,
+		queue:   queue,
+	}
+}
+
+// CreateBundle handles POST /api/v1/bundles
+func (h *BundleHandler) CreateBundle(w http.ResponseWriter, r *http.Request) {
+	var req CreateBundleRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	payload := req.ToPayload()
+	bundle, meta, err := h.service.CreateBundle(r.Context(), payload)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	qErr := h.queue.Enqueue(client.Job{
+		Type:           WORKER_BUILD_BUNDLE,
+		Payload:        payload,
+		BatchID:        payload.BundleID,
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+	})
+	if qErr != nil {
+		response.HandleError(w, qErr)
+		return
+	}
+
+	response.AcceptedWithMeta(w, bundle, meta)
+}
+
+// GetBundle handles GET /api/v1/bundles/{bundleID}
+func (h *BundleHandler) GetBundle(w http.ResponseWriter, r *http.Request) {
+	var req GetBundleRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, meta, err := h.service.GetBundle(r.Context(), req.BundleID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result, meta)
+}
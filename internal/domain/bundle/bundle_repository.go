@@ -0,0 +1,149 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// BundleItem is a learning item as it is written into a bundle.
+type BundleItem struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Content  string          `json:"content"`
+	Language string          `json:"language"`
+	Level    *string         `json:"level,omitempty"`
+	Details  json.RawMessage `json:"details"`
+	Notes    json.RawMessage `json:"notes,omitempty"`
+}
+
+// Bundle is the owner and storage location of an exported bundle.
+type Bundle struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"-"`
+	Items     []BundleItemRef `json:"items"`
+	Key       string          `json:"-"`
+	SizeBytes int64           `json:"size_bytes,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// BundleRepository reads bundled content from Postgres and keeps bundle records in Redis.
+type BundleRepository interface {
+	ListItems(ctx context.Context, userID string, refs []BundleItemRef) ([]*BundleItem, *errors.AppError)
+	CreateBundle(ctx context.Context, bundle *Bundle) *errors.AppError
+	GetBundle(ctx context.Context, bundleID string) (*Bundle, *errors.AppError)
+	SetBundleFile(ctx context.Context, bundleID, key string, size int64) *errors.AppError
+}
+
+type bundleRepository struct {
+	db    *client.PostgresClient
+	redis *client.RedisClient
+	ttl   time.Duration
+}
+
+// NewBundleRepository creates a new bundle repository. Bundle records expire after ttl.
+func NewBundleRepository(db *client.PostgresClient, redis *client.RedisClient, ttl time.Duration) BundleRepository {
+	return &bundleRepository{db: db, redis: redis, ttl: ttl}
+}
+
+func bundleKey(bundleID string) string {
+	return fmt.Sprintf("bundle:%s", bundleID)
+}
+
+// ListItems returns the requested items the user may study, with the user's notes on them: any video,
+// and dialogs that are published (their published snapshot) or owned by the user (the draft), without
+// adult-audience items for minors. Order follows refs.
+func (r *bundleRepository) ListItems(ctx context.Context, userID string, refs []BundleItemRef) ([]*BundleItem, *errors.AppError) {
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+
+	query := `
+		SELECT l.id::text, l.feature_id, l.content, l.language, l.level,
+			CASE WHEN l.feature_id = $3 AND l.created_by <> $4 THEN l.published_details ELSE l.details END,
+			(SELECT jsonb_agg(jsonb_build_object('id', n.id, 'body', n.body, 'created_at', n.created_at, 'updated_at', n.updated_at)
+				ORDER BY n.created_at, n.id)
+			FROM notes n WHERE n.learning_id = l.id AND n.user_id::text = $4 AND n.deleted_at IS NULL)
+		FROM learning_items l
+		WHERE l.id = ANY($1::uuid[]) AND l.is_active = true
+		AND (l.feature_id = $2 OR (l.feature_id = $3 AND (l.published_details IS NOT NULL OR l.created_by = $4)))
+		AND ` + client.AudienceVisibleSQL("l", "$4")
+
+	rows, err := r.db.Pool.Query(ctx, query, ids, client.FeatureVideo, client.FeatureDialog, userID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list bundle items", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]*BundleItem, len(ids))
+	for rows.Next() {
+		var item BundleItem
+		var featureID int
+		if err := rows.Scan(&item.ID, &featureID, &item.Content, &item.Language, &item.Level, &item.Details, &item.Notes); err != nil {
+			return nil, errors.InternalWrap("failed to scan bundle item", err)
+		}
+		item.Type = ITEM_TYPE_VIDEO
+		if featureID == client.FeatureDialog {
+			item.Type = ITEM_TYPE_DIALOG
+		}
+		found[item.ID] = &item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list bundle items", err)
+	}
+
+	items := make([]*BundleItem, 0, len(found))
+	for _, ref := range refs {
+		if item, ok := found[ref.ID]; ok && item.Type == ref.Type {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *bundleRepository) CreateBundle(ctx context.Context, bundle *Bundle) *errors.AppError {
+	itemsJSON, _ := json.Marshal(bundle.Items)
+	key := bundleKey(bundle.ID)
+	if err := r.redis.HSet(ctx, key,
+		"user_id", bundle.UserID,
+		"items", string(itemsJSON),
+		"created_at", bundle.CreatedAt,
+	); err != nil {
+		return errors.InternalWrap("failed to create bundle", err)
+	}
+	_ = r.redis.SetExpiry(ctx, key, r.ttl)
+	return nil
+}
+
+// GetBundle returns nil when the bundle does not exist or has expired.
+func (r *bundleRepository) GetBundle(ctx context.Context, bundleID string) (*Bundle, *errors.AppError) {
+	fields, err := r.redis.HGetAll(ctx, bundleKey(bundleID))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get bundle", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	bundle := &Bundle{
+		ID:        bundleID,
+		UserID:    fields["user_id"],
+		Key:       fields["key"],
+		CreatedAt: fields["created_at"],
+	}
+	_ = json.Unmarshal([]byte(fields["items"]), &bundle.Items)
+	fmt.Sscan(fields["size_bytes"], &bundle.SizeBytes)
+	return bundle, nil
+}
+
+func (r *bundleRepository) SetBundleFile(ctx context.Context, bundleID, key string, size int64) *errors.AppError {
+	if err := r.redis.HSet(ctx, bundleKey(bundleID), "key", key, "size_bytes", fmt.Sprint(size)); err != nil {
+		return errors.InternalWrap("failed to store bundle file", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package bundle
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxBundleItems bounds how many lessons one bundle can hold.
+const maxBundleItems = 20
+
+// Item types that can be bundled
+const (
+	ITEM_TYPE_VIDEO  = "video"
+	ITEM_TYPE_DIALOG = "dialog"
+)
+
+// BundleItemRef points at one learning item to bundle
+type BundleItemRef struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// -------------------------------------------------------------------------
+// Create Bundle Request
+// -------------------------------------------------------------------------
+
+// CreateBundleRequest is the HTTP request struct for exporting an offline bundle
+type CreateBundleRequest struct {
+	UserID string          `json:"-"`
+	Items  []BundleItemRef `json:"items"`
+}
+
+// CreateBundlePayload is the payload struct for the bundle worker
+type CreateBundlePayload struct {
+	BundleID string
+	UserID   string
+	Items    []BundleItemRef
+}
+
+func (req *CreateBundleRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. parse request body
+	defer r.Body.Close()
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid request body")
+	}
+
+	// 3. เช็ก items (de-duplicated, known types, valid ids)
+	if len(req.Items) == 0 {
+		return errors.Validation("items is required")
+	}
+	if len(req.Items) > maxBundleItems {
+		return errors.Validation(fmt.Sprintf("at most %d items are allowed", maxBundleItems))
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	items := make([]BundleItemRef, 0, len(req.Items))
+	for _, item := range req.Items {
+		item.Type = strings.ToLower(strings.TrimSpace(item.Type))
+		if item.Type != ITEM_TYPE_VIDEO && item.Type != ITEM_TYPE_DIALOG {
+			return errors.Validation("item type must be video or dialog")
+		}
+		if _, err := uuid.Parse(item.ID); err != nil {
+			return errors.Validation("invalid item id")
+		}
+		if seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		items = append(items, item)
+	}
+	req.Items = items
+
+	return nil
+}
+
+func (req *CreateBundleRequest) ToPayload() CreateBundlePayload {
+	return CreateBundlePayload{
+		BundleID: uuid.New().String(),
+		UserID:   req.UserID,
+		Items:    req.Items,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Get Bundle Request
+// -------------------------------------------------------------------------
+
+// GetBundleRequest is the HTTP request struct for reading a bundle and its download URL
+type GetBundleRequest struct {
+	UserID   string
+	BundleID string
+}
+
+func (req *GetBundleRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.BundleID = chi.URLParam(r, "bundleID")
+	if _, err := uuid.Parse(req.BundleID); err != nil {
+		return errors.Validation("invalid bundle id")
+	}
+
+	return nil
+}
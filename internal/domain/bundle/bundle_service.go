@@ -0,0 +1,278 @@
+package bundle
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// bundleMediaExts are the media files copied into a bundle; videos stay remote.
+var bundleMediaExts = map[string]bool{
+	".mp3": true, ".m4a": true, ".wav": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".webp": true,
+}
+
+// BundleService exports learning items as zip bundles for offline study.
+type BundleService struct {
+	bundleRepo BundleRepository
+	fileRepo   FileRepository
+	batchRepo  BatchRepository
+	maxBytes   int64
+}
+
+// BundleResponse is returned when reading a bundle.
+type BundleResponse struct {
+	*Bundle
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// BundleManifest is written to manifest.json at the root of every bundle.
+type BundleManifest struct {
+	BundleID  string          `json:"bundle_id"`
+	CreatedAt string          `json:"created_at"`
+	Items     []BundleItemRef `json:"items"`
+	Media     int             `json:"media"`
+}
+
+// NewBundleService creates a new BundleService. Bundles over maxBytes fail (0 disables the limit).
+func NewBundleService(bundleRepo BundleRepository, fileRepo FileRepository, batchRepo BatchRepository, maxBytes int64) *BundleService {
+	return &BundleService{
+		bundleRepo: bundleRepo,
+		fileRepo:   fileRepo,
+		batchRepo:  batchRepo,
+		maxBytes:   maxBytes,
+	}
+}
+
+// CreateBundle checks the items are available to the user and creates the batch that builds the bundle.
+func (s *BundleService) CreateBundle(ctx context.Context, payload CreateBundlePayload) (*Bundle, *response.MetaProcessing, *errors.AppError) {
+	items, err := s.bundleRepo.ListItems(ctx, payload.UserID, payload.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) != len(payload.Items) {
+		return nil, nil, errors.NotFound("one or more items were not found")
+	}
+
+	bundle := &Bundle{
+		ID:        payload.BundleID,
+		UserID:    payload.UserID,
+		Items:     payload.Items,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.bundleRepo.CreateBundle(ctx, bundle); err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := s.batchRepo.CreateBatch(ctx, payload.BundleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bundle, meta, nil
+}
+
+// GetBundle returns one of the user's bundles with its batch, and a signed download URL once built.
+func (s *BundleService) GetBundle(ctx context.Context, bundleID, userID string) (*BundleResponse, *response.MetaProcessing, *errors.AppError) {
+	bundle, err := s.bundleRepo.GetBundle(ctx, bundleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bundle == nil || bundle.UserID != userID {
+		return nil, nil, errors.NotFound("bundle not found")
+	}
+
+	meta, err := s.batchRepo.GetBatch(ctx, bundleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &BundleResponse{Bundle: bundle}
+	if bundle.Key != "" {
+		url, err := s.fileRepo.SignedURL(ctx, bundle.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.DownloadURL = url
+	}
+	return result, meta, nil
+}
+
+// ProcessBuildBundle packages the items and their media into a zip and uploads it.
+func (s *BundleService) ProcessBuildBundle(ctx context.Context, payload CreateBundlePayload) {
+	// 1. collect content
+	_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_COLLECT_CONTENT, BATCH_PROCESSING, "")
+	items, err := s.bundleRepo.ListItems(ctx, payload.UserID, payload.Items)
+	if err != nil {
+		s.failFrom(ctx, payload.BundleID, PROCESS_COLLECT_CONTENT, err.GetMessage())
+		return
+	}
+	_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_COLLECT_CONTENT, BATCH_COMPLETED, "")
+
+	// 2. package items and media into a temp zip
+	_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_PACKAGE_MEDIA, BATCH_PROCESSING, "")
+	tempFile, tErr := os.CreateTemp("", "bundle-*.zip")
+	if tErr != nil {
+		s.failFrom(ctx, payload.BundleID, PROCESS_PACKAGE_MEDIA, "failed to create temp file")
+		return
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if err := s.writeBundle(ctx, tempFile, payload.BundleID, items); err != nil {
+		s.failFrom(ctx, payload.BundleID, PROCESS_PACKAGE_MEDIA, err.GetMessage())
+		return
+	}
+	size, _ := tempFile.Seek(0, io.SeekCurrent)
+	if s.maxBytes > 0 && size > s.maxBytes {
+		s.failFrom(ctx, payload.BundleID, PROCESS_PACKAGE_MEDIA, fmt.Sprintf("bundle exceeds %d bytes", s.maxBytes))
+		return
+	}
+	_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_PACKAGE_MEDIA, BATCH_COMPLETED, "")
+
+	// 3. upload and record where the bundle lives
+	_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_UPLOAD_BUNDLE, BATCH_PROCESSING, "")
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		s.failFrom(ctx, payload.BundleID, PROCESS_UPLOAD_BUNDLE, "failed to read bundle")
+		return
+	}
+	key := fmt.Sprintf("bundles/%s.zip", payload.BundleID)
+	if err := s.fileRepo.Upload(ctx, key, tempFile); err != nil {
+		s.failFrom(ctx, payload.BundleID, PROCESS_UPLOAD_BUNDLE, err.GetMessage())
+		return
+	}
+	if err := s.bundleRepo.SetBundleFile(ctx, payload.BundleID, key, size); err != nil {
+		s.failFrom(ctx, payload.BundleID, PROCESS_UPLOAD_BUNDLE, err.GetMessage())
+		return
+	}
+
+	// Store the result before completing the job so a completed batch always carries it
+	resultJSON, _ := json.Marshal(map[string]interface{}{"bundle_id": payload.BundleID, "size_bytes": size})
+	if err := s.batchRepo.SetBatchResult(ctx, payload.BundleID, resultJSON); err != nil {
+		_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_UPLOAD_BUNDLE, BATCH_FAILED, "failed to store result")
+		return
+	}
+	_ = s.batchRepo.UpdateJob(ctx, payload.BundleID, PROCESS_UPLOAD_BUNDLE, BATCH_COMPLETED, "")
+}
+
+// failFrom fails the given process and skips the ones after it.
+func (s *BundleService) failFrom(ctx context.Context, bundleID, process, message string) {
+	failed := false
+	for _, name := range GetProcessNames() {
+		switch {
+		case name == process:
+			failed = true
+			_ = s.batchRepo.UpdateJob(ctx, bundleID, name, BATCH_FAILED, message)
+		case failed:
+			_ = s.batchRepo.UpdateJob(ctx, bundleID, name, BATCH_FAILED, "skipped: "+process+" failed")
+		}
+	}
+}
+
+// writeBundle writes manifest.json, items/<id>.json and media/<id>/<file>. Media URLs in the
+// item details are rewritten to their path inside the zip.
+func (s *BundleService) writeBundle(ctx context.Context, w io.Writer, bundleID string, items []*BundleItem) *errors.AppError {
+	zw := zip.NewWriter(w)
+	manifest := BundleManifest{BundleID: bundleID, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+
+	for _, item := range items {
+		manifest.Items = append(manifest.Items, BundleItemRef{Type: item.Type, ID: item.ID})
+
+		var details interface{}
+		if len(item.Details) > 0 {
+			if err := json.Unmarshal(item.Details, &details); err != nil {
+				return errors.InternalWrap("failed to read item details", err)
+			}
+		}
+
+		media := make(map[string]string)
+		details = s.collectMedia(details, "", item.ID, media)
+		for name, key := range media {
+			if err := s.copyMedia(ctx, zw, name, key); err != nil {
+				return err
+			}
+		}
+		manifest.Media += len(media)
+
+		detailsJSON, _ := json.Marshal(details)
+		item.Details = detailsJSON
+		if err := writeJSON(zw, fmt.Sprintf("items/%s.json", item.ID), item); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return errors.InternalWrap("failed to write bundle", err)
+	}
+	return nil
+}
+
+// collectMedia walks the details and replaces *_url fields that point at our CDN media with the
+// path inside the zip, recording path -> R2 key in media.
+func (s *BundleService) collectMedia(value interface{}, field, itemID string, media map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = s.collectMedia(child, k, itemID, media)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = s.collectMedia(child, field, itemID, media)
+		}
+		return v
+	case string:
+		if !strings.HasSuffix(field, "_url") || !bundleMediaExts[strings.ToLower(path.Ext(v))] {
+			return v
+		}
+		key, ok := s.fileRepo.MediaKey(v)
+		if !ok {
+			return v
+		}
+		name := fmt.Sprintf("media/%s/%s", itemID, path.Base(key))
+		media[name] = key
+		return name
+	default:
+		return v
+	}
+}
+
+func (s *BundleService) copyMedia(ctx context.Context, zw *zip.Writer, name, key string) *errors.AppError {
+	body, err := s.fileRepo.OpenMedia(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Audio and images are already compressed
+	fw, zErr := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+	if zErr != nil {
+		return errors.InternalWrap("failed to write bundle", zErr)
+	}
+	if _, cErr := io.Copy(fw, body); cErr != nil {
+		return errors.InternalWrap("failed to copy bundle media", cErr)
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) *errors.AppError {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return errors.InternalWrap("failed to write bundle", err)
+	}
+	if err := json.NewEncoder(fw).Encode(v); err != nil {
+		return errors.InternalWrap("failed to write bundle", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_BUILD_BUNDLE = "BUILD_BUNDLE"
+)
+
+// RegisterBundleWorkers register bundle workers to queue
+func RegisterBundleWorkers(queue *client.QueueClient, service *BundleService) {
+
+	// Job Build Bundle
+	queue.RegisterDurableWorker(WORKER_BUILD_BUNDLE, CreateBundlePayload{}, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(CreateBundlePayload)
+		if !ok {
+			return fmt.Errorf("invalid payload type")
+		}
+		service.ProcessBuildBundle(ctx, payload)
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package bundle
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// FileRepository reads bundled media from R2 and stores the packaged bundles.
+type FileRepository interface {
+	MediaKey(url string) (string, bool)
+	OpenMedia(ctx context.Context, key string) (io.ReadCloser, *errors.AppError)
+	Upload(ctx context.Context, key string, body io.Reader) *errors.AppError
+	SignedURL(ctx context.Context, key string) (string, *errors.AppError)
+}
+
+type fileRepository struct {
+	cloudflare *client.CloudflareClient
+	urlTTL     time.Duration
+	log        *slog.Logger
+}
+
+// NewFileRepository creates a new bundle file repository. Download URLs are valid for urlTTL.
+func NewFileRepository(cloudflare *client.CloudflareClient, urlTTL time.Duration, log *slog.Logger) FileRepository {
+	return &fileRepository{cloudflare: cloudflare, urlTTL: urlTTL, log: log}
+}
+
+// MediaKey returns the R2 key of a media URL served from our CDN.
+func (r *fileRepository) MediaKey(url string) (string, bool) {
+	if r.cloudflare == nil {
+		return "", false
+	}
+	return r.cloudflare.R2KeyFromURL(url)
+}
+
+func (r *fileRepository) OpenMedia(ctx context.Context, key string) (io.ReadCloser, *errors.AppError) {
+	if r.cloudflare == nil {
+		return nil, errors.Unsupported("bundle storage client not configured")
+	}
+
+	body, err := r.cloudflare.GetR2Object(ctx, key)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to read bundle media", err)
+	}
+	return body, nil
+}
+
+func (r *fileRepository) Upload(ctx context.Context, key string, body io.Reader) *errors.AppError {
+	if r.cloudflare == nil {
+		return errors.Unsupported("bundle storage client not configured")
+	}
+
+	if _, err := r.cloudflare.UploadR2Object(ctx, key, body, "application/zip"); err != nil {
+		return errors.InternalWrap("failed to upload bundle", err)
+	}
+	return nil
+}
+
+func (r *fileRepository) SignedURL(ctx context.Context, key string) (string, *errors.AppError) {
+	if r.cloudflare == nil {
+		return "", errors.Unsupported("bundle storage client not configured")
+	}
+
+	url, err := r.cloudflare.PresignR2Object(ctx, key, r.urlTTL)
+	if err != nil {
+		r.log.Error("Failed to sign bundle URL", "key", key, "error", err)
+		return "", errors.InternalWrap("failed to sign bundle url", err)
+	}
+	return url, nil
+}
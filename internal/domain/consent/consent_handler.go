@@ -0,0 +1,55 @@
+package consent
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ConsentHandler handles terms and consent HTTP endpoints.
+type ConsentHandler struct {
+	service *ConsentService
+}
+
+// NewConsentHandler creates a new consent handler.
+func NewConsentHandler(service *ConsentService) *ConsentHandler {
+	return &ConsentHandler{
+		service: service,
+	}
+}
+
+// ListConsents handles GET /api/v1/consents
+func (h *ConsentHandler) ListConsents(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		response.HandleError(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	statuses, err := h.service.ListConsents(r.Context(), userID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, statuses)
+}
+
+// AcceptConsent handles POST /api/v1/consents/{consentType}/accept
+func (h *ConsentHandler) AcceptConsent(w http.ResponseWriter, r *http.Request) {
+	var req AcceptConsentRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.AcceptConsent(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -0,0 +1,167 @@
+package consent
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Terms is one published version of a consent text.
+type Terms struct {
+	Type        string    `json:"type"`
+	Version     string    `json:"version"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Acceptance records a user accepting one terms version.
+type Acceptance struct {
+	UserID     string
+	Type       string
+	Version    string
+	AcceptedAt time.Time
+	IPAddress  string
+	UserAgent  string
+}
+
+// ConsentRepository reads terms and stores user acceptances.
+type ConsentRepository interface {
+	ListLatestTerms(ctx context.Context) ([]*Terms, *errors.AppError)
+	GetLatestTerms(ctx context.Context, consentType string) (*Terms, *errors.AppError)
+	ListAcceptances(ctx context.Context, userID string) (map[string]*Acceptance, *errors.AppError)
+	HasAccepted(ctx context.Context, userID, consentType, version string) (bool, *errors.AppError)
+	Accept(ctx context.Context, acceptance *Acceptance) *errors.AppError
+}
+
+type consentRepository struct {
+	db *client.PostgresClient
+}
+
+// NewConsentRepository creates a new consent repository.
+func NewConsentRepository(db *client.PostgresClient) ConsentRepository {
+	return &consentRepository{db: db}
+}
+
+// ListLatestTerms returns the terms in force for every consent type.
+func (r *consentRepository) ListLatestTerms(ctx context.Context) ([]*Terms, *errors.AppError) {
+	query := `
+        SELECT DISTINCT ON (consent_type) consent_type, version, title, body, published_at
+        FROM consent_terms
+        WHERE published_at <= NOW()
+        ORDER BY consent_type, published_at DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list consent terms", err)
+	}
+	defer rows.Close()
+
+	terms := make([]*Terms, 0)
+	for rows.Next() {
+		var t Terms
+		if err := rows.Scan(&t.Type, &t.Version, &t.Title, &t.Body, &t.PublishedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan consent terms", err)
+		}
+		terms = append(terms, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list consent terms", err)
+	}
+
+	return terms, nil
+}
+
+// GetLatestTerms returns the terms in force for one type, or nil when none were published.
+func (r *consentRepository) GetLatestTerms(ctx context.Context, consentType string) (*Terms, *errors.AppError) {
+	query := `
+        SELECT consent_type, version, title, body, published_at
+        FROM consent_terms
+        WHERE consent_type = $1 AND published_at <= NOW()
+        ORDER BY published_at DESC
+        LIMIT 1
+    `
+
+	var t Terms
+	err := r.db.Pool.QueryRow(ctx, query, consentType).Scan(&t.Type, &t.Version, &t.Title, &t.Body, &t.PublishedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.InternalWrap("failed to get consent terms", err)
+	}
+
+	return &t, nil
+}
+
+// ListAcceptances returns the user's most recent acceptance per consent type.
+func (r *consentRepository) ListAcceptances(ctx context.Context, userID string) (map[string]*Acceptance, *errors.AppError) {
+	query := `
+        SELECT DISTINCT ON (consent_type) consent_type, version, accepted_at
+        FROM user_consents
+        WHERE user_id = $1
+        ORDER BY consent_type, accepted_at DESC
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list consents", err)
+	}
+	defer rows.Close()
+
+	acceptances := make(map[string]*Acceptance)
+	for rows.Next() {
+		a := Acceptance{UserID: userID}
+		if err := rows.Scan(&a.Type, &a.Version, &a.AcceptedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan consent", err)
+		}
+		acceptances[a.Type] = &a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list consents", err)
+	}
+
+	return acceptances, nil
+}
+
+// HasAccepted reports whether the user accepted this exact terms version.
+func (r *consentRepository) HasAccepted(ctx context.Context, userID, consentType, version string) (bool, *errors.AppError) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM user_consents
+            WHERE user_id = $1 AND consent_type = $2 AND version = $3
+        )
+    `
+
+	var accepted bool
+	if err := r.db.Pool.QueryRow(ctx, query, userID, consentType, version).Scan(&accepted); err != nil {
+		return false, errors.InternalWrap("failed to check consent", err)
+	}
+	return accepted, nil
+}
+
+// Accept stores an acceptance. Accepting the same version again keeps the first record.
+func (r *consentRepository) Accept(ctx context.Context, acceptance *Acceptance) *errors.AppError {
+	query := `
+        INSERT INTO user_consents (user_id, consent_type, version, ip_address, user_agent)
+        VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+        ON CONFLICT (user_id, consent_type, version) DO UPDATE SET user_id = EXCLUDED.user_id
+        RETURNING accepted_at
+    `
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		acceptance.UserID,
+		acceptance.Type,
+		acceptance.Version,
+		acceptance.IPAddress,
+		acceptance.UserAgent,
+	).Scan(&acceptance.AcceptedAt)
+	if err != nil {
+		return errors.InternalWrap("failed to store consent", err)
+	}
+	return nil
+}
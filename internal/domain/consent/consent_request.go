@@ -0,0 +1,88 @@
+package consent
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Consent types
+const (
+	// CONSENT_RECORDING covers storing the user's voice (speaking, retell, spoken sparring turns)
+	CONSENT_RECORDING = "recording"
+)
+
+// maxUserAgentLength bounds the user agent kept as evidence of an acceptance.
+const maxUserAgentLength = 512
+
+// -------------------------------------------------------------------------
+// Accept Consent Request
+// -------------------------------------------------------------------------
+
+// AcceptConsentRequest is the HTTP request struct for accepting terms
+type AcceptConsentRequest struct {
+	UserID    string `json:"-"`
+	Type      string `json:"-"`
+	Version   string `json:"version"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// AcceptConsentInput is the input struct for service
+type AcceptConsentInput struct {
+	UserID    string
+	Type      string
+	Version   string
+	IPAddress string
+	UserAgent string
+}
+
+func (req *AcceptConsentRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Get consent type from URL
+	req.Type = strings.ToLower(chi.URLParam(r, "consentType"))
+	if req.Type == "" {
+		return errors.Validation("consent type is required")
+	}
+
+	// 3. Parse JSON Body (the version the user was shown, so a newer one is never accepted blindly)
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Version = strings.TrimSpace(req.Version)
+	if req.Version == "" {
+		return errors.Validation("version is required")
+	}
+
+	// ClientIP already put the client address in RemoteAddr
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	req.IPAddress = ip
+	req.UserAgent = r.UserAgent()
+	if len(req.UserAgent) > maxUserAgentLength {
+		req.UserAgent = req.UserAgent[:maxUserAgentLength]
+	}
+
+	return nil
+}
+
+func (req *AcceptConsentRequest) ToInput() AcceptConsentInput {
+	return AcceptConsentInput{
+		UserID:    req.UserID,
+		Type:      req.Type,
+		Version:   req.Version,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	}
+}
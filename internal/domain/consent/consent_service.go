@@ -0,0 +1,117 @@
+package consent
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// ConsentService serves the current terms and tracks which versions each user accepted.
+type ConsentService struct {
+	consentRepo ConsentRepository
+}
+
+// ConsentStatus is the terms in force for one type and whether the user accepted them.
+type ConsentStatus struct {
+	*Terms
+	Accepted        bool       `json:"accepted"`
+	AcceptedVersion *string    `json:"accepted_version"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+}
+
+// AcceptConsentResponse is returned after accepting terms.
+type AcceptConsentResponse struct {
+	Type       string    `json:"type"`
+	Version    string    `json:"version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// NewConsentService creates a new ConsentService.
+func NewConsentService(consentRepo ConsentRepository) *ConsentService {
+	return &ConsentService{consentRepo: consentRepo}
+}
+
+// ListConsents returns every consent type with its latest terms and the user's status.
+func (s *ConsentService) ListConsents(ctx context.Context, userID string) ([]*ConsentStatus, *errors.AppError) {
+	terms, err := s.consentRepo.ListLatestTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	acceptances, err := s.consentRepo.ListAcceptances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*ConsentStatus, len(terms))
+	for i, t := range terms {
+		status := &ConsentStatus{Terms: t}
+		if a, ok := acceptances[t.Type]; ok {
+			status.Accepted = a.Version == t.Version
+			status.AcceptedVersion = &a.Version
+			status.AcceptedAt = &a.AcceptedAt
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// AcceptConsent records the user accepting the terms in force. Accepting an outdated
+// version is a conflict, the client has to show the new text first.
+func (s *ConsentService) AcceptConsent(ctx context.Context, input AcceptConsentInput) (*AcceptConsentResponse, *errors.AppError) {
+	terms, err := s.consentRepo.GetLatestTerms(ctx, input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, errors.NotFound("consent type not found")
+	}
+	if terms.Version != input.Version {
+		return nil, errors.Conflict("terms have a newer version").WithDetails(map[string]interface{}{
+			"type":           terms.Type,
+			"latest_version": terms.Version,
+		})
+	}
+
+	acceptance := &Acceptance{
+		UserID:    input.UserID,
+		Type:      input.Type,
+		Version:   input.Version,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	}
+	if err := s.consentRepo.Accept(ctx, acceptance); err != nil {
+		return nil, err
+	}
+
+	return &AcceptConsentResponse{
+		Type:       acceptance.Type,
+		Version:    acceptance.Version,
+		AcceptedAt: acceptance.AcceptedAt,
+	}, nil
+}
+
+// CheckConsent returns a CONSENT_REQUIRED error unless the user accepted the latest terms of
+// consentType. Types without published terms need no consent.
+func (s *ConsentService) CheckConsent(ctx context.Context, userID, consentType string) *errors.AppError {
+	terms, err := s.consentRepo.GetLatestTerms(ctx, consentType)
+	if err != nil {
+		return err
+	}
+	if terms == nil {
+		return nil
+	}
+
+	accepted, err := s.consentRepo.HasAccepted(ctx, userID, consentType, terms.Version)
+	if err != nil {
+		return err
+	}
+	if accepted {
+		return nil
+	}
+
+	return errors.ConsentRequired("the latest terms must be accepted first").WithDetails(map[string]interface{}{
+		"type":             terms.Type,
+		"required_version": terms.Version,
+	})
+}
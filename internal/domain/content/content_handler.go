@@ -0,0 +1,92 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ContentHandler handles content library HTTP endpoints.
+type ContentHandler struct {
+	service *ContentService
+}
+
+// NewContentHandler creates a new content handler.
+func NewContentHandler(service *ContentService) *ContentHandler {
+	return &ContentHandler{
+		service: service,
+	}
+}
+
+// ListClusters handles GET /api/v1/admin/content/clusters.
+func (h *ContentHandler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	clusters, err := h.service.ListClusters(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, clusters)
+}
+
+// GetStats handles GET /api/v1/admin/content/stats.
+func (h *ContentHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetStats(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, stats)
+}
+
+// ListGlossaryConflicts handles GET /api/v1/admin/content/glossary.
+func (h *ContentHandler) ListGlossaryConflicts(w http.ResponseWriter, r *http.Request) {
+	var req ListGlossaryConflictsRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListGlossaryConflicts(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// ListMediaIssues handles GET /api/v1/admin/content/media.
+func (h *ContentHandler) ListMediaIssues(w http.ResponseWriter, r *http.Request) {
+	var req ListMediaIssuesRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListMediaIssues(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// MergeGlossaryConflict handles POST /api/v1/admin/content/glossary/{conflictID}/merge.
+func (h *ContentHandler) MergeGlossaryConflict(w http.ResponseWriter, r *http.Request) {
+	var req MergeGlossaryConflictRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.MergeGlossaryConflict(r.Context(), req.ConflictID, req.Meaning)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -0,0 +1,203 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// ContentItem is the text of an active learning item used for clustering.
+type ContentItem struct {
+	ID          uuid.UUID
+	FeatureID   int
+	Language    string
+	Level       string
+	Content     string
+	Description string
+	Tags        []string
+}
+
+// ClusterCoverage counts the items of one cluster per language and level.
+type ClusterCoverage struct {
+	Label     string
+	FeatureID int
+	Language  string
+	Level     string
+	Items     int
+}
+
+// ContentStatsRow counts the active items of one feature, language and level, and those
+// missing media.
+type ContentStatsRow struct {
+	FeatureID    int
+	Language     string
+	Level        string
+	Items        int
+	MissingAudio int
+	MissingImage int
+}
+
+// ContentRepository reads the content library and stores cluster assignments.
+type ContentRepository interface {
+	ListContentItems(ctx context.Context) ([]*ContentItem, *errors.AppError)
+	SaveClusterLabels(ctx context.Context, labels map[uuid.UUID]string, clusteredAt time.Time) *errors.AppError
+	ListClusterCoverage(ctx context.Context) ([]*ClusterCoverage, *time.Time, *errors.AppError)
+	CountContent(ctx context.Context) ([]*ContentStatsRow, *errors.AppError)
+}
+
+type contentRepository struct {
+	db *client.PostgresClient
+}
+
+// NewContentRepository creates a new content repository.
+func NewContentRepository(db *client.PostgresClient) ContentRepository {
+	return &contentRepository{db: db}
+}
+
+func (r *contentRepository) ListContentItems(ctx context.Context) ([]*ContentItem, *errors.AppError) {
+	query := `
+		SELECT id, COALESCE(feature_id, 0), COALESCE(language, ''), COALESCE(level, ''), COALESCE(content, ''),
+			COALESCE(details->>'description', ''), COALESCE(tags, '[]'::jsonb), COALESCE(details->'tags', '[]'::jsonb)
+		FROM learning_items
+		WHERE is_active = true
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list content items", err)
+	}
+	defer rows.Close()
+
+	items := make([]*ContentItem, 0)
+	for rows.Next() {
+		var item ContentItem
+		var tags, detailTags json.RawMessage
+		if err := rows.Scan(&item.ID, &item.FeatureID, &item.Language, &item.Level, &item.Content,
+			&item.Description, &tags, &detailTags); err != nil {
+			return nil, errors.InternalWrap("failed to scan content item", err)
+		}
+		item.Tags = append(decodeTags(tags), decodeTags(detailTags)...)
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list content items", err)
+	}
+
+	return items, nil
+}
+
+func (r *contentRepository) SaveClusterLabels(ctx context.Context, labels map[uuid.UUID]string, clusteredAt time.Time) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for id, label := range labels {
+		if _, err := tx.Exec(ctx, `
+			UPDATE learning_items SET cluster_label = $2, clustered_at = $3 WHERE id = $1
+		`, id, label, clusteredAt); err != nil {
+			return errors.InternalWrap("failed to save cluster label", err)
+		}
+	}
+
+	// Items that left the library no longer belong to a cluster
+	if _, err := tx.Exec(ctx, `
+		UPDATE learning_items SET cluster_label = NULL, clustered_at = NULL
+		WHERE cluster_label IS NOT NULL AND clustered_at < $1
+	`, clusteredAt); err != nil {
+		return errors.InternalWrap("failed to clear stale cluster labels", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit cluster labels", err)
+	}
+	return nil
+}
+
+func (r *contentRepository) ListClusterCoverage(ctx context.Context) ([]*ClusterCoverage, *time.Time, *errors.AppError) {
+	query := `
+		SELECT cluster_label, COALESCE(feature_id, 0), COALESCE(language, ''), COALESCE(level, ''), COUNT(*), MAX(clustered_at)
+		FROM learning_items
+		WHERE cluster_label IS NOT NULL AND is_active = true
+		GROUP BY cluster_label, feature_id, language, level
+		ORDER BY cluster_label, feature_id, language, level
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, nil, errors.InternalWrap("failed to list cluster coverage", err)
+	}
+	defer rows.Close()
+
+	var clusteredAt *time.Time
+	coverage := make([]*ClusterCoverage, 0)
+	for rows.Next() {
+		var c ClusterCoverage
+		var at *time.Time
+		if err := rows.Scan(&c.Label, &c.FeatureID, &c.Language, &c.Level, &c.Items, &at); err != nil {
+			return nil, nil, errors.InternalWrap("failed to scan cluster coverage", err)
+		}
+		if at != nil && (clusteredAt == nil || at.After(*clusteredAt)) {
+			clusteredAt = at
+		}
+		coverage = append(coverage, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.InternalWrap("failed to list cluster coverage", err)
+	}
+
+	return coverage, clusteredAt, nil
+}
+
+// CountContent counts active items per feature, language and level. A dialog's audio and image
+// are its situation audio and scene image; a video's are its file and thumbnail.
+func (r *contentRepository) CountContent(ctx context.Context) ([]*ContentStatsRow, *errors.AppError) {
+	query := `
+		SELECT COALESCE(feature_id, 0), COALESCE(language, ''), COALESCE(level, ''), COUNT(*),
+			COUNT(*) FILTER (WHERE CASE feature_id
+				WHEN $1 THEN COALESCE(details->>'video_url', '') = ''
+				ELSE COALESCE(details->>'audio_url', '') = '' END),
+			COUNT(*) FILTER (WHERE CASE feature_id
+				WHEN $1 THEN COALESCE(details->>'thumbnail_url', '') = ''
+				ELSE COALESCE(details->>'image_url', '') = '' END)
+		FROM learning_items
+		WHERE is_active = true
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, client.FeatureVideo)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to count content", err)
+	}
+	defer rows.Close()
+
+	stats := make([]*ContentStatsRow, 0)
+	for rows.Next() {
+		var row ContentStatsRow
+		if err := rows.Scan(&row.FeatureID, &row.Language, &row.Level, &row.Items, &row.MissingAudio, &row.MissingImage); err != nil {
+			return nil, errors.InternalWrap("failed to scan content stats", err)
+		}
+		stats = append(stats, &row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to count content", err)
+	}
+
+	return stats, nil
+}
+
+// decodeTags reads a JSON array of tags, ignoring anything else.
+func decodeTags(raw json.RawMessage) []string {
+	var tags []string
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return nil
+	}
+	return tags
+}
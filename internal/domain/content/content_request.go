@@ -0,0 +1,184 @@
+package content
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Limits
+const (
+	defaultPageSize  = 20
+	maxPageSize      = 100
+	maxMeaningLength = 500
+)
+
+// ClusterContentPayload is the payload for the content clustering job
+type ClusterContentPayload struct{}
+
+// CheckGlossaryPayload is the payload for the glossary consistency job
+type CheckGlossaryPayload struct{}
+
+// CheckMediaPayload is the payload for the media completeness job
+type CheckMediaPayload struct{}
+
+// -------------------------------------------------------------------------
+// List Glossary Conflicts Request (admin)
+// -------------------------------------------------------------------------
+
+// ListGlossaryConflictsRequest is the HTTP request struct for the glossary report
+type ListGlossaryConflictsRequest struct {
+	Language  string
+	Mergeable *bool
+	Page      int
+	PageSize  int
+}
+
+// ListGlossaryConflictsInput is the input struct for service
+type ListGlossaryConflictsInput struct {
+	Language  string
+	Mergeable *bool
+	Page      int
+	PageSize  int
+	Limit     int
+	Offset    int
+}
+
+// Parse reads the optional language and mergeable filters and pagination params
+func (req *ListGlossaryConflictsRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.Language = strings.TrimSpace(q.Get("language"))
+	if mergeable := q.Get("mergeable"); mergeable != "" {
+		v, err := strconv.ParseBool(mergeable)
+		if err != nil {
+			return errors.Validation("mergeable must be true or false")
+		}
+		req.Mergeable = &v
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListGlossaryConflictsRequest to ListGlossaryConflictsInput
+func (req *ListGlossaryConflictsRequest) ToInput() ListGlossaryConflictsInput {
+	return ListGlossaryConflictsInput{
+		Language:  req.Language,
+		Mergeable: req.Mergeable,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		Limit:     req.PageSize,
+		Offset:    (req.Page - 1) * req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// List Media Issues Request (admin)
+// -------------------------------------------------------------------------
+
+// ListMediaIssuesRequest is the HTTP request struct for the media report
+type ListMediaIssuesRequest struct {
+	Problem   string
+	FeatureID int
+	Page      int
+	PageSize  int
+}
+
+// ListMediaIssuesInput is the input struct for service
+type ListMediaIssuesInput struct {
+	Problem   string
+	FeatureID int
+	Page      int
+	PageSize  int
+	Limit     int
+	Offset    int
+}
+
+// Parse reads the optional problem and feature filters and pagination params
+func (req *ListMediaIssuesRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.Problem = strings.TrimSpace(q.Get("problem"))
+	if req.Problem != "" && req.Problem != MediaProblemMissing && req.Problem != MediaProblemUnreachable {
+		return errors.Validation("problem must be missing or unreachable")
+	}
+	switch feature := strings.TrimSpace(q.Get("feature")); feature {
+	case "":
+	case "video":
+		req.FeatureID = client.FeatureVideo
+	case "dialog":
+		req.FeatureID = client.FeatureDialog
+	default:
+		return errors.Validation("feature must be video or dialog")
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListMediaIssuesRequest to ListMediaIssuesInput
+func (req *ListMediaIssuesRequest) ToInput() ListMediaIssuesInput {
+	return ListMediaIssuesInput{
+		Problem:   req.Problem,
+		FeatureID: req.FeatureID,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		Limit:     req.PageSize,
+		Offset:    (req.Page - 1) * req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Merge Glossary Conflict Request (admin)
+// -------------------------------------------------------------------------
+
+// MergeGlossaryConflictRequest is the HTTP request struct for merging a term to one meaning
+type MergeGlossaryConflictRequest struct {
+	ConflictID string `json:"-"`
+	// Empty uses the suggested meaning
+	Meaning string `json:"meaning"`
+}
+
+func (req *MergeGlossaryConflictRequest) ParseAndValidate(r *http.Request) error {
+	req.ConflictID = chi.URLParam(r, "conflictID")
+	if _, err := uuid.Parse(req.ConflictID); err != nil {
+		return errors.Validation("invalid conflict id")
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil && err != io.EOF {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Meaning = strings.TrimSpace(req.Meaning)
+	if utf8.RuneCountInString(req.Meaning) > maxMeaningLength {
+		return errors.Validation("meaning must be at most 500 characters")
+	}
+
+	return nil
+}
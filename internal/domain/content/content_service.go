@@ -0,0 +1,758 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// Clustering limits
+const (
+	maxClusters     = 30
+	kmeansMaxRounds = 25
+	labelTagCount   = 3
+)
+
+// Bigram similarity at which two meanings count as rewordings of each other
+const glossaryMergeSimilarity = 0.5
+
+// ClusterCoverageCell counts a cluster's items for one language and level.
+type ClusterCoverageCell struct {
+	FeatureID int    `json:"feature_id"`
+	Language  string `json:"language"`
+	Level     string `json:"level"`
+	Items     int    `json:"items"`
+}
+
+// ClusterGap is a language and level that the library covers but the cluster does not.
+type ClusterGap struct {
+	Language string `json:"language"`
+	Level    string `json:"level"`
+}
+
+// ClusterResponse describes one topic cluster and where it lacks content.
+type ClusterResponse struct {
+	Label    string                 `json:"label"`
+	Items    int                    `json:"items"`
+	Coverage []*ClusterCoverageCell `json:"coverage"`
+	Gaps     []*ClusterGap          `json:"gaps"`
+}
+
+// ListClustersResponse is the response of the cluster coverage report.
+type ListClustersResponse struct {
+	ClusteredAt *time.Time         `json:"clustered_at"`
+	Languages   []string           `json:"languages"`
+	Levels      []string           `json:"levels"`
+	Clusters    []*ClusterResponse `json:"clusters"`
+}
+
+// ContentCount counts active items and those missing audio or an image.
+type ContentCount struct {
+	Key          string `json:"key"`
+	Items        int    `json:"items"`
+	MissingAudio int    `json:"missing_audio"`
+	MissingImage int    `json:"missing_image"`
+}
+
+// ContentStatsCell counts the active items of one feature, language and level.
+type ContentStatsCell struct {
+	FeatureID    int    `json:"feature_id"`
+	Language     string `json:"language"`
+	Level        string `json:"level"`
+	Items        int    `json:"items"`
+	MissingAudio int    `json:"missing_audio"`
+	MissingImage int    `json:"missing_image"`
+}
+
+// ContentStatsResponse is the response of the content statistics report.
+type ContentStatsResponse struct {
+	Total      ContentCount        `json:"total"`
+	ByFeature  []*ContentCount     `json:"by_feature"`
+	ByLanguage []*ContentCount     `json:"by_language"`
+	ByLevel    []*ContentCount     `json:"by_level"`
+	Cells      []*ContentStatsCell `json:"cells"`
+}
+
+// ListGlossaryConflictsResponse is returned when listing the glossary report.
+type ListGlossaryConflictsResponse struct {
+	Data []*GlossaryConflict      `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// ListMediaIssuesResponse is returned when listing the media report.
+type ListMediaIssuesResponse struct {
+	Data []*MediaIssue            `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// MediaCheckOptions controls the media completeness check.
+type MediaCheckOptions struct {
+	// Concurrency is how many URLs are checked at once
+	Concurrency int
+	// Repair queues regeneration of broken dialog media
+	Repair bool
+}
+
+// MergeGlossaryConflictResponse is returned after merging a term to one meaning.
+type MergeGlossaryConflictResponse struct {
+	ID           string `json:"id"`
+	Language     string `json:"language"`
+	Term         string `json:"term"`
+	Meaning      string `json:"meaning"`
+	UpdatedItems int    `json:"updated_items"`
+}
+
+// ContentService clusters the content library by topic and checks its glossary and media.
+type ContentService struct {
+	contentRepo   ContentRepository
+	embeddingRepo EmbeddingRepository
+	glossaryRepo  GlossaryRepository
+	mediaRepo     MediaRepository
+	mediaChecker  *client.MediaChecker
+	queue         *client.QueueClient
+	mediaOpts     MediaCheckOptions
+}
+
+// NewContentService creates a new content service.
+func NewContentService(
+	contentRepo ContentRepository,
+	embeddingRepo EmbeddingRepository,
+	glossaryRepo GlossaryRepository,
+	mediaRepo MediaRepository,
+	mediaChecker *client.MediaChecker,
+	queue *client.QueueClient,
+	mediaOpts MediaCheckOptions,
+) *ContentService {
+	if mediaOpts.Concurrency <= 0 {
+		mediaOpts.Concurrency = 1
+	}
+	return &ContentService{
+		contentRepo:   contentRepo,
+		embeddingRepo: embeddingRepo,
+		glossaryRepo:  glossaryRepo,
+		mediaRepo:     mediaRepo,
+		mediaChecker:  mediaChecker,
+		queue:         queue,
+		mediaOpts:     mediaOpts,
+	}
+}
+
+// GetStats counts active content per feature, language and level, with the items missing media.
+func (s *ContentService) GetStats(ctx context.Context) (*ContentStatsResponse, *errors.AppError) {
+	rows, err := s.contentRepo.CountContent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ContentStatsResponse{Total: ContentCount{Key: "all"}, Cells: make([]*ContentStatsCell, 0, len(rows))}
+	byFeature := make(map[string]*ContentCount)
+	byLanguage := make(map[string]*ContentCount)
+	byLevel := make(map[string]*ContentCount)
+	for _, row := range rows {
+		result.Cells = append(result.Cells, &ContentStatsCell{
+			FeatureID:    row.FeatureID,
+			Language:     row.Language,
+			Level:        row.Level,
+			Items:        row.Items,
+			MissingAudio: row.MissingAudio,
+			MissingImage: row.MissingImage,
+		})
+		result.Total.add(row)
+		countContent(byFeature, featureName(row.FeatureID), row)
+		countContent(byLanguage, row.Language, row)
+		countContent(byLevel, row.Level, row)
+	}
+
+	result.ByFeature = sortedCounts(byFeature)
+	result.ByLanguage = sortedCounts(byLanguage)
+	result.ByLevel = sortedCounts(byLevel)
+	return result, nil
+}
+
+func (c *ContentCount) add(row *ContentStatsRow) {
+	c.Items += row.Items
+	c.MissingAudio += row.MissingAudio
+	c.MissingImage += row.MissingImage
+}
+
+func countContent(counts map[string]*ContentCount, key string, row *ContentStatsRow) {
+	count, ok := counts[key]
+	if !ok {
+		count = &ContentCount{Key: key}
+		counts[key] = count
+	}
+	count.add(row)
+}
+
+func sortedCounts(counts map[string]*ContentCount) []*ContentCount {
+	sorted := make([]*ContentCount, 0, len(counts))
+	for _, count := range counts {
+		sorted = append(sorted, count)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+// featureName is the feature type shown in the stats ("" for items without a known feature).
+func featureName(featureID int) string {
+	switch featureID {
+	case client.FeatureVideo:
+		return "video"
+	case client.FeatureDialog:
+		return "dialog"
+	}
+	return ""
+}
+
+// ListClusters returns every cluster with its item counts and coverage gaps per language and level.
+func (s *ContentService) ListClusters(ctx context.Context) (*ListClustersResponse, *errors.AppError) {
+	rows, clusteredAt, err := s.contentRepo.ListClusterCoverage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := make(map[string]bool)
+	levels := make(map[string]bool)
+	pairs := make(map[ClusterGap]bool)
+	byLabel := make(map[string]*ClusterResponse)
+	clusters := make([]*ClusterResponse, 0)
+	for _, row := range rows {
+		languages[row.Language] = true
+		levels[row.Level] = true
+		pairs[ClusterGap{Language: row.Language, Level: row.Level}] = true
+
+		cluster, ok := byLabel[row.Label]
+		if !ok {
+			cluster = &ClusterResponse{Label: row.Label, Coverage: make([]*ClusterCoverageCell, 0)}
+			byLabel[row.Label] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.Items += row.Items
+		cluster.Coverage = append(cluster.Coverage, &ClusterCoverageCell{
+			FeatureID: row.FeatureID,
+			Language:  row.Language,
+			Level:     row.Level,
+			Items:     row.Items,
+		})
+	}
+
+	// A gap is a language/level pair present elsewhere in the library but missing from the cluster
+	for _, cluster := range clusters {
+		covered := make(map[ClusterGap]bool)
+		for _, cell := range cluster.Coverage {
+			covered[ClusterGap{Language: cell.Language, Level: cell.Level}] = true
+		}
+		cluster.Gaps = make([]*ClusterGap, 0)
+		for pair := range pairs {
+			if !covered[pair] {
+				gap := pair
+				cluster.Gaps = append(cluster.Gaps, &gap)
+			}
+		}
+		sort.Slice(cluster.Gaps, func(i, j int) bool {
+			if cluster.Gaps[i].Language != cluster.Gaps[j].Language {
+				return cluster.Gaps[i].Language < cluster.Gaps[j].Language
+			}
+			return cluster.Gaps[i].Level < cluster.Gaps[j].Level
+		})
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Items > clusters[j].Items })
+
+	return &ListClustersResponse{
+		ClusteredAt: clusteredAt,
+		Languages:   sortedKeys(languages),
+		Levels:      sortedKeys(levels),
+		Clusters:    clusters,
+	}, nil
+}
+
+// Worker: ClusterContent
+// Embeds every active learning item, groups them with k-means and stores a tag-based label per item.
+func (s *ContentService) ClusterContent(ctx context.Context, payload ClusterContentPayload) *errors.AppError {
+	items, err := s.contentRepo.ListContentItems(ctx)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = strings.TrimSpace(strings.Join([]string{item.Content, item.Description, strings.Join(item.Tags, " ")}, "\n"))
+	}
+
+	vectors, err := s.embeddingRepo.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(items) {
+		return errors.Internal("embedding count does not match content items")
+	}
+	for _, vector := range vectors {
+		normalize(vector)
+	}
+
+	k := int(math.Round(math.Sqrt(float64(len(items)) / 2)))
+	k = max(1, min(k, maxClusters, len(items)))
+	assignments := kmeans(vectors, k)
+
+	members := make([][]*ContentItem, k)
+	for i, cluster := range assignments {
+		members[cluster] = append(members[cluster], items[i])
+	}
+
+	labels := make(map[uuid.UUID]string, len(items))
+	used := make(map[string]int)
+	for _, group := range members {
+		if len(group) == 0 {
+			continue
+		}
+		label := clusterLabel(group)
+		used[label]++
+		if used[label] > 1 {
+			label = fmt.Sprintf("%s #%d", label, used[label])
+		}
+		for _, item := range group {
+			labels[item.ID] = label
+		}
+	}
+
+	return s.contentRepo.SaveClusterLabels(ctx, labels, time.Now().UTC())
+}
+
+// Worker: CheckGlossary
+// Groups the vocabulary of every active video by language and term and stores the terms
+// that have more than one meaning, with the most used meaning as the merge suggestion.
+func (s *ContentService) CheckGlossary(ctx context.Context, payload CheckGlossaryPayload) *errors.AppError {
+	entries, err := s.glossaryRepo.ListGlossaryEntries(ctx)
+	if err != nil {
+		return err
+	}
+
+	type termKey struct{ language, term string }
+	byTerm := make(map[termKey]map[string]*GlossaryMeaning)
+	var keys []termKey
+	for _, entry := range entries {
+		term, meaning := normalizeTerm(entry.Text), normalizeMeaning(entry.Meaning)
+		if term == "" || meaning == "" {
+			continue
+		}
+		key := termKey{language: entry.Language, term: term}
+		meanings, ok := byTerm[key]
+		if !ok {
+			meanings = make(map[string]*GlossaryMeaning)
+			byTerm[key] = meanings
+			keys = append(keys, key)
+		}
+		m, ok := meanings[meaning]
+		if !ok {
+			// The first wording seen is the one reported
+			m = &GlossaryMeaning{Meaning: strings.TrimSpace(entry.Meaning), Items: make([]string, 0)}
+			meanings[meaning] = m
+		}
+		if !slices.Contains(m.Items, entry.LearningID) {
+			m.Items = append(m.Items, entry.LearningID)
+			m.Count++
+		}
+	}
+
+	conflicts := make([]*GlossaryConflict, 0)
+	for _, key := range keys {
+		if len(byTerm[key]) < 2 {
+			continue
+		}
+
+		meanings := make([]GlossaryMeaning, 0, len(byTerm[key]))
+		for _, m := range byTerm[key] {
+			meanings = append(meanings, *m)
+		}
+		sort.Slice(meanings, func(i, j int) bool {
+			if meanings[i].Count != meanings[j].Count {
+				return meanings[i].Count > meanings[j].Count
+			}
+			if len(meanings[i].Meaning) != len(meanings[j].Meaning) {
+				return len(meanings[i].Meaning) < len(meanings[j].Meaning)
+			}
+			return meanings[i].Meaning < meanings[j].Meaning
+		})
+
+		suggested := meanings[0].Meaning
+		mergeable := true
+		for _, m := range meanings[1:] {
+			if bigramSimilarity(normalizeMeaning(suggested), normalizeMeaning(m.Meaning)) < glossaryMergeSimilarity {
+				mergeable = false
+				break
+			}
+		}
+
+		conflicts = append(conflicts, &GlossaryConflict{
+			Language:         key.language,
+			Term:             key.term,
+			Meanings:         meanings,
+			SuggestedMeaning: suggested,
+			Mergeable:        mergeable,
+		})
+	}
+
+	return s.glossaryRepo.SaveGlossaryConflicts(ctx, conflicts, time.Now().UTC())
+}
+
+// Worker: CheckMedia
+// Checks that the media of every active item is set and resolves, and stores the media that
+// is missing or unreachable. With repair on, broken dialog media is queued for regeneration;
+// video media is uploaded by users and only reported.
+func (s *ContentService) CheckMedia(ctx context.Context, payload CheckMediaPayload) *errors.AppError {
+	refs, err := s.mediaRepo.ListMediaRefs(ctx)
+	if err != nil {
+		return err
+	}
+	checkedAt := time.Now().UTC()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		issues = make([]*MediaIssue, 0)
+		sem    = make(chan struct{}, s.mediaOpts.Concurrency)
+	)
+	report := func(ref *MediaRef, problem string, statusCode int) {
+		issue := &MediaIssue{
+			LearningID: ref.LearningID,
+			FeatureID:  ref.FeatureID,
+			Language:   ref.Language,
+			Field:      ref.Field,
+			URL:        ref.URL,
+			Problem:    problem,
+			StatusCode: statusCode,
+			CheckedAt:  checkedAt,
+		}
+		if ref.TurnIndex >= 0 {
+			turnIndex := ref.TurnIndex
+			issue.TurnIndex = &turnIndex
+		}
+		mu.Lock()
+		issues = append(issues, issue)
+		mu.Unlock()
+	}
+
+	for _, ref := range refs {
+		if ref.URL == "" {
+			report(ref, MediaProblemMissing, 0)
+			continue
+		}
+		if s.mediaChecker == nil {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return errors.InternalWrap("media check cancelled", ctx.Err())
+		}
+		wg.Add(1)
+		go func(ref *MediaRef) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			status, err := s.mediaChecker.Check(ctx, ref.URL)
+			if err != nil || status >= 400 {
+				report(ref, MediaProblemUnreachable, status)
+			}
+		}(ref)
+	}
+	wg.Wait()
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].LearningID != issues[j].LearningID {
+			return issues[i].LearningID < issues[j].LearningID
+		}
+		return issues[i].Field < issues[j].Field
+	})
+	if s.mediaOpts.Repair && s.queue != nil {
+		s.queueMediaRepairs(issues)
+	}
+
+	return s.mediaRepo.SaveMediaIssues(ctx, issues, checkedAt)
+}
+
+// queueMediaRepairs queues one regeneration job per dialog with broken media and marks the
+// issues it covers.
+func (s *ContentService) queueMediaRepairs(issues []*MediaIssue) {
+	payloads := make(map[string]*dialog.RepairMediaPayload)
+	var order []string
+	for _, issue := range issues {
+		if issue.FeatureID != client.FeatureDialog {
+			continue
+		}
+		payload, ok := payloads[issue.LearningID]
+		if !ok {
+			payload = &dialog.RepairMediaPayload{DialogID: issue.LearningID}
+			payloads[issue.LearningID] = payload
+			order = append(order, issue.LearningID)
+		}
+		switch issue.Field {
+		case MediaFieldImage:
+			payload.Image = true
+		case MediaFieldAudio:
+			payload.Audio = true
+		case MediaFieldScriptAudio:
+			if issue.TurnIndex != nil {
+				payload.ScriptTurns = append(payload.ScriptTurns, *issue.TurnIndex)
+			}
+		}
+	}
+
+	queued := make(map[string]bool, len(order))
+	for _, dialogID := range order {
+		if err := s.queue.Enqueue(client.Job{
+			Type:    dialog.WORKER_REPAIR_MEDIA,
+			Payload: *payloads[dialogID],
+		}); err == nil {
+			queued[dialogID] = true
+		}
+	}
+	for _, issue := range issues {
+		issue.RepairQueued = queued[issue.LearningID] && issue.FeatureID == client.FeatureDialog
+	}
+}
+
+// ListMediaIssues returns a page of the media report.
+func (s *ContentService) ListMediaIssues(ctx context.Context, input ListMediaIssuesInput) (*ListMediaIssuesResponse, *errors.AppError) {
+	issues, total, err := s.mediaRepo.ListMediaIssues(ctx, input.Problem, input.FeatureID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListMediaIssuesResponse{
+		Data: issues,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// ListGlossaryConflicts returns a page of the glossary report.
+func (s *ContentService) ListGlossaryConflicts(ctx context.Context, input ListGlossaryConflictsInput) (*ListGlossaryConflictsResponse, *errors.AppError) {
+	conflicts, total, err := s.glossaryRepo.ListGlossaryConflicts(ctx, input.Language, input.Mergeable, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListGlossaryConflictsResponse{
+		Data: conflicts,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// MergeGlossaryConflict gives the term one meaning in every item of the conflict,
+// the suggested meaning when meaning is empty.
+func (s *ContentService) MergeGlossaryConflict(ctx context.Context, conflictID, meaning string) (*MergeGlossaryConflictResponse, *errors.AppError) {
+	conflict, err := s.glossaryRepo.GetGlossaryConflict(ctx, conflictID)
+	if err != nil {
+		return nil, err
+	}
+	if meaning == "" {
+		meaning = conflict.SuggestedMeaning
+	}
+
+	updated, err := s.glossaryRepo.MergeGlossaryConflict(ctx, conflict, meaning)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MergeGlossaryConflictResponse{
+		ID:           conflict.ID,
+		Language:     conflict.Language,
+		Term:         conflict.Term,
+		Meaning:      meaning,
+		UpdatedItems: updated,
+	}, nil
+}
+
+// normalizeTerm folds case and spacing so "Take off" and "take  off" are the same term.
+func normalizeTerm(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// normalizeMeaning also drops the punctuation around the meaning.
+func normalizeMeaning(text string) string {
+	return strings.TrimFunc(normalizeTerm(text), func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// bigramSimilarity is the Dice coefficient of the rune bigrams of a and b. It works
+// for scripts written without spaces (Chinese, Thai) as well.
+func bigramSimilarity(a, b string) float64 {
+	bigrams := func(s string) map[string]int {
+		runes := []rune(s)
+		counts := make(map[string]int)
+		for i := 0; i+1 < len(runes); i++ {
+			counts[string(runes[i:i+2])]++
+		}
+		return counts
+	}
+
+	if a == b {
+		return 1
+	}
+	ba, bb := bigrams(a), bigrams(b)
+	total := 0
+	for _, n := range ba {
+		total += n
+	}
+	for _, n := range bb {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	shared := 0
+	for gram, n := range ba {
+		shared += min(n, bb[gram])
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+// kmeans assigns each unit vector to one of k clusters by cosine similarity.
+// Centroids are seeded deterministically (farthest-point) so reruns give stable clusters.
+func kmeans(vectors [][]float64, k int) []int {
+	centroids := [][]float64{append([]float64(nil), vectors[0]...)}
+	for len(centroids) < k {
+		farthest, farthestSim := 0, math.Inf(1)
+		for i, v := range vectors {
+			best := math.Inf(-1)
+			for _, c := range centroids {
+				best = math.Max(best, dot(v, c))
+			}
+			if best < farthestSim {
+				farthest, farthestSim = i, best
+			}
+		}
+		centroids = append(centroids, append([]float64(nil), vectors[farthest]...))
+	}
+
+	assignments := make([]int, len(vectors))
+	for round := 0; round < kmeansMaxRounds; round++ {
+		changed := false
+		for i, v := range vectors {
+			best, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if sim := dot(v, centroid); sim > bestSim {
+					best, bestSim = c, sim
+				}
+			}
+			if round == 0 || assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		for c := range centroids {
+			sum := make([]float64, len(centroids[c]))
+			n := 0
+			for i, v := range vectors {
+				if assignments[i] != c {
+					continue
+				}
+				for d := range sum {
+					sum[d] += v[d]
+				}
+				n++
+			}
+			// An empty cluster keeps its previous centroid
+			if n > 0 {
+				normalize(sum)
+				centroids[c] = sum
+			}
+		}
+	}
+	return assignments
+}
+
+// clusterLabel names a cluster after its most frequent tags, or its most frequent words when untagged.
+func clusterLabel(items []*ContentItem) string {
+	counts := make(map[string]int)
+	for _, item := range items {
+		seen := make(map[string]bool)
+		for _, tag := range item.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				counts[tag]++
+			}
+		}
+	}
+	if len(counts) == 0 {
+		for _, item := range items {
+			for _, word := range strings.Fields(strings.ToLower(item.Content)) {
+				word = strings.Trim(word, ".,!?;:\"'()")
+				if len([]rune(word)) >= 4 {
+					counts[word]++
+				}
+			}
+		}
+	}
+	if len(counts) == 0 {
+		return "untitled"
+	}
+
+	terms := sortedKeys(counts)
+	sort.SliceStable(terms, func(i, j int) bool { return counts[terms[i]] > counts[terms[j]] })
+	if len(terms) > labelTagCount {
+		terms = terms[:labelTagCount]
+	}
+	return strings.Join(terms, ", ")
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// normalize scales v to unit length in place.
+func normalize(v []float64) {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
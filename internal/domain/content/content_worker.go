@@ -0,0 +1,55 @@
+package content
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_CLUSTER_CONTENT = "worker_cluster_content"
+	WORKER_CHECK_GLOSSARY  = "worker_check_glossary"
+	WORKER_CHECK_MEDIA     = "worker_check_media"
+)
+
+// RegisterContentWorkers register content workers to queue
+func RegisterContentWorkers(queue *client.QueueClient, service *ContentService) {
+
+	// Job Cluster Content
+	queue.RegisterWorker(WORKER_CLUSTER_CONTENT, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(ClusterContentPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_CLUSTER_CONTENT)
+		}
+		if err := service.ClusterContent(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	// Job Check Glossary
+	queue.RegisterWorker(WORKER_CHECK_GLOSSARY, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(CheckGlossaryPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_CHECK_GLOSSARY)
+		}
+		if err := service.CheckGlossary(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	// Job Check Media
+	queue.RegisterWorker(WORKER_CHECK_MEDIA, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(CheckMediaPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_CHECK_MEDIA)
+		}
+		if err := service.CheckMedia(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package content
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// hashedDimensions is the vector size of the local bag-of-words fallback.
+const hashedDimensions = 512
+
+// embeddingBatchSize caps the inputs sent per embeddings call.
+const embeddingBatchSize = 64
+
+// EmbeddingRepository turns content texts into vectors.
+type EmbeddingRepository interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, *errors.AppError)
+}
+
+type embeddingRepository struct {
+	gateway *client.OpenAICompatibleClient
+	model   string
+}
+
+// NewEmbeddingRepository creates a new embedding repository. Without a model the
+// texts are embedded locally as hashed bag-of-words vectors.
+func NewEmbeddingRepository(gateway *client.OpenAICompatibleClient, model string) EmbeddingRepository {
+	return &embeddingRepository{gateway: gateway, model: model}
+}
+
+func (r *embeddingRepository) Embed(ctx context.Context, texts []string) ([][]float64, *errors.AppError) {
+	if r.model == "" {
+		vectors := make([][]float64, len(texts))
+		for i, text := range texts {
+			vectors[i] = hashedEmbedding(text)
+		}
+		return vectors, nil
+	}
+
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+		batch, err := r.gateway.Embeddings(ctx, r.model, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// hashedEmbedding maps the words of text into a fixed-size term-frequency vector.
+func hashedEmbedding(text string) []float64 {
+	vector := make([]float64, hashedDimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		if len([]rune(word)) < 3 {
+			continue
+		}
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%hashedDimensions]++
+	}
+	for i, v := range vector {
+		if v > 0 {
+			vector[i] = 1 + math.Log(v)
+		}
+	}
+	return vector
+}
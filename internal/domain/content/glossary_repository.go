@@ -0,0 +1,255 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// glossaryFields are the details arrays of {text, meaning, example} entries
+var glossaryFields = []string{"vocabulary", "key_phrases"}
+
+// GlossaryEntry is one vocabulary or key phrase entry of an active video.
+type GlossaryEntry struct {
+	LearningID string
+	Language   string
+	Text       string
+	Meaning    string
+}
+
+// GlossaryMeaning is one meaning of a conflicting term with the items that use it.
+type GlossaryMeaning struct {
+	Meaning string   `json:"meaning"`
+	Items   []string `json:"items"`
+	Count   int      `json:"count"`
+}
+
+// GlossaryConflict is a term with more than one meaning across the items of a language.
+type GlossaryConflict struct {
+	ID               string            `json:"id"`
+	Language         string            `json:"language"`
+	Term             string            `json:"term"`
+	Meanings         []GlossaryMeaning `json:"meanings"`
+	SuggestedMeaning string            `json:"suggested_meaning"`
+	Mergeable        bool              `json:"mergeable"`
+	CheckedAt        time.Time         `json:"checked_at"`
+}
+
+// GlossaryRepository reads vocabulary across the library and stores the conflict report.
+type GlossaryRepository interface {
+	ListGlossaryEntries(ctx context.Context) ([]*GlossaryEntry, *errors.AppError)
+	SaveGlossaryConflicts(ctx context.Context, conflicts []*GlossaryConflict, checkedAt time.Time) *errors.AppError
+	ListGlossaryConflicts(ctx context.Context, language string, mergeable *bool, limit, offset int) ([]*GlossaryConflict, int, *errors.AppError)
+	GetGlossaryConflict(ctx context.Context, conflictID string) (*GlossaryConflict, *errors.AppError)
+	MergeGlossaryConflict(ctx context.Context, conflict *GlossaryConflict, meaning string) (int, *errors.AppError)
+}
+
+type glossaryRepository struct {
+	db *client.PostgresClient
+}
+
+// NewGlossaryRepository creates a new glossary repository.
+func NewGlossaryRepository(db *client.PostgresClient) GlossaryRepository {
+	return &glossaryRepository{db: db}
+}
+
+// ListGlossaryEntries returns the vocabulary and key phrases of every active video.
+func (r *glossaryRepository) ListGlossaryEntries(ctx context.Context) ([]*GlossaryEntry, *errors.AppError) {
+	query := `
+		SELECT l.id::text, COALESCE(l.language, ''), COALESCE(e->>'text', ''), COALESCE(e->>'meaning', '')
+		FROM learning_items l
+		CROSS JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(l.details->'vocabulary') = 'array' THEN l.details->'vocabulary' ELSE '[]'::jsonb END ||
+			CASE WHEN jsonb_typeof(l.details->'key_phrases') = 'array' THEN l.details->'key_phrases' ELSE '[]'::jsonb END
+		) e
+		WHERE l.feature_id = $1 AND l.is_active = true AND jsonb_typeof(e) = 'object'
+		ORDER BY l.created_at, l.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, client.FeatureVideo)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list glossary entries", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*GlossaryEntry, 0)
+	for rows.Next() {
+		var entry GlossaryEntry
+		if err := rows.Scan(&entry.LearningID, &entry.Language, &entry.Text, &entry.Meaning); err != nil {
+			return nil, errors.InternalWrap("failed to scan glossary entry", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list glossary entries", err)
+	}
+
+	return entries, nil
+}
+
+// SaveGlossaryConflicts replaces the report with conflicts. Existing rows keep their id.
+func (r *glossaryRepository) SaveGlossaryConflicts(ctx context.Context, conflicts []*GlossaryConflict, checkedAt time.Time) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, conflict := range conflicts {
+		meanings, _ := json.Marshal(conflict.Meanings)
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO glossary_conflicts (language, term, meanings, suggested_meaning, mergeable, checked_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (language, term) DO UPDATE
+			SET meanings = EXCLUDED.meanings, suggested_meaning = EXCLUDED.suggested_meaning,
+				mergeable = EXCLUDED.mergeable, checked_at = EXCLUDED.checked_at
+		`, conflict.Language, conflict.Term, meanings, conflict.SuggestedMeaning, conflict.Mergeable, checkedAt); err != nil {
+			return errors.InternalWrap("failed to save glossary conflict", err)
+		}
+	}
+
+	// Terms that no longer conflict leave the report
+	if _, err := tx.Exec(ctx, `DELETE FROM glossary_conflicts WHERE checked_at < $1`, checkedAt); err != nil {
+		return errors.InternalWrap("failed to clear resolved glossary conflicts", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit glossary conflicts", err)
+	}
+	return nil
+}
+
+// ListGlossaryConflicts returns a page of the report, terms used by the most items first.
+func (r *glossaryRepository) ListGlossaryConflicts(ctx context.Context, language string, mergeable *bool, limit, offset int) ([]*GlossaryConflict, int, *errors.AppError) {
+	where := `WHERE ($1 = '' OR language = $1) AND ($2::boolean IS NULL OR mergeable = $2)`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM glossary_conflicts `+where, language, mergeable).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count glossary conflicts", err)
+	}
+
+	query := `
+		SELECT id::text, language, term, meanings, suggested_meaning, mergeable, checked_at
+		FROM glossary_conflicts
+		` + where + `
+		ORDER BY (SELECT COALESCE(SUM((m->>'count')::int), 0) FROM jsonb_array_elements(meanings) m) DESC, language, term
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, language, mergeable, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list glossary conflicts", err)
+	}
+	defer rows.Close()
+
+	conflicts := make([]*GlossaryConflict, 0)
+	for rows.Next() {
+		conflict, err := scanGlossaryConflict(rows)
+		if err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan glossary conflict", err)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list glossary conflicts", err)
+	}
+
+	return conflicts, total, nil
+}
+
+func (r *glossaryRepository) GetGlossaryConflict(ctx context.Context, conflictID string) (*GlossaryConflict, *errors.AppError) {
+	row := r.db.Pool.QueryRow(ctx, `
+		SELECT id::text, language, term, meanings, suggested_meaning, mergeable, checked_at
+		FROM glossary_conflicts
+		WHERE id = $1
+	`, conflictID)
+
+	conflict, err := scanGlossaryConflict(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("glossary conflict not found")
+		}
+		return nil, errors.InternalWrap("failed to get glossary conflict", err)
+	}
+	return conflict, nil
+}
+
+// MergeGlossaryConflict rewrites the term's meaning to meaning in every item of the conflict
+// and removes it from the report. It returns how many items changed.
+func (r *glossaryRepository) MergeGlossaryConflict(ctx context.Context, conflict *GlossaryConflict, meaning string) (int, *errors.AppError) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updated := 0
+	for _, m := range conflict.Meanings {
+		for _, itemID := range m.Items {
+			var details map[string]json.RawMessage
+			err := tx.QueryRow(ctx, `SELECT details FROM learning_items WHERE id = $1 AND feature_id = $2 FOR UPDATE`, itemID, client.FeatureVideo).Scan(&details)
+			if err != nil {
+				if err == pgx.ErrNoRows {
+					continue
+				}
+				return 0, errors.InternalWrap("failed to lock glossary item", err)
+			}
+
+			changed := false
+			for _, field := range glossaryFields {
+				var entries []map[string]any
+				if err := json.Unmarshal(details[field], &entries); err != nil {
+					continue
+				}
+				fieldChanged := false
+				for _, entry := range entries {
+					text, _ := entry["text"].(string)
+					if normalizeTerm(text) == conflict.Term && entry["meaning"] != meaning {
+						entry["meaning"] = meaning
+						fieldChanged = true
+					}
+				}
+				if fieldChanged {
+					details[field], _ = json.Marshal(entries)
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+
+			raw, _ := json.Marshal(details)
+			if _, err := tx.Exec(ctx, `UPDATE learning_items SET details = $1, version = version + 1, updated_at = NOW() WHERE id = $2`, raw, itemID); err != nil {
+				return 0, errors.InternalWrap(fmt.Sprintf("failed to merge glossary item %s", itemID), err)
+			}
+			updated++
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM glossary_conflicts WHERE id = $1`, conflict.ID); err != nil {
+		return 0, errors.InternalWrap("failed to remove merged glossary conflict", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, errors.InternalWrap("failed to commit glossary merge", err)
+	}
+	return updated, nil
+}
+
+func scanGlossaryConflict(row pgx.Row) (*GlossaryConflict, error) {
+	var conflict GlossaryConflict
+	var meanings json.RawMessage
+	if err := row.Scan(&conflict.ID, &conflict.Language, &conflict.Term, &meanings,
+		&conflict.SuggestedMeaning, &conflict.Mergeable, &conflict.CheckedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(meanings, &conflict.Meanings); err != nil {
+		return nil, err
+	}
+	return &conflict, nil
+}
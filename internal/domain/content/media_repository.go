@@ -0,0 +1,187 @@
+package content
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Media fields the media check looks at
+const (
+	MediaFieldImage       = "image"        // dialog scene image
+	MediaFieldAudio       = "audio"        // dialog situation audio
+	MediaFieldScriptAudio = "script_audio" // audio of an AI script turn
+	MediaFieldVideo       = "video"        // video file
+	MediaFieldThumbnail   = "thumbnail"    // video thumbnail
+)
+
+// Media problems
+const (
+	MediaProblemMissing     = "missing"
+	MediaProblemUnreachable = "unreachable"
+)
+
+// MediaRef is one media URL of an active item ("" when the item has none).
+type MediaRef struct {
+	LearningID string
+	FeatureID  int
+	Language   string
+	Field      string
+	TurnIndex  int // script turn of script_audio, -1 otherwise
+	URL        string
+}
+
+// MediaIssue is media of an active item that is missing or does not resolve.
+type MediaIssue struct {
+	LearningID   string    `json:"learning_id"`
+	FeatureID    int       `json:"feature_id"`
+	Language     string    `json:"language"`
+	Field        string    `json:"field"`
+	TurnIndex    *int      `json:"turn_index,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	Problem      string    `json:"problem"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	RepairQueued bool      `json:"repair_queued"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// MediaRepository lists the media of the library and stores the media report.
+type MediaRepository interface {
+	ListMediaRefs(ctx context.Context) ([]*MediaRef, *errors.AppError)
+	SaveMediaIssues(ctx context.Context, issues []*MediaIssue, checkedAt time.Time) *errors.AppError
+	ListMediaIssues(ctx context.Context, problem string, featureID, limit, offset int) ([]*MediaIssue, int, *errors.AppError)
+}
+
+type mediaRepository struct {
+	db *client.PostgresClient
+}
+
+// NewMediaRepository creates a new media repository.
+func NewMediaRepository(db *client.PostgresClient) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+// ListMediaRefs returns the media every active item should have: the image, situation audio and
+// AI turn audio of dialogs, and the file and thumbnail of videos.
+func (r *mediaRepository) ListMediaRefs(ctx context.Context) ([]*MediaRef, *errors.AppError) {
+	query := `
+		SELECT l.id::text, l.feature_id, COALESCE(l.language, ''), m.field, m.turn_index, COALESCE(m.url, '')
+		FROM learning_items l
+		CROSS JOIN LATERAL (
+			SELECT 'video' AS field, -1 AS turn_index, l.details->>'video_url' AS url WHERE l.feature_id = $1
+			UNION ALL
+			SELECT 'thumbnail', -1, l.details->>'thumbnail_url' WHERE l.feature_id = $1
+			UNION ALL
+			SELECT 'image', -1, l.details->>'image_url' WHERE l.feature_id = $2
+			UNION ALL
+			SELECT 'audio', -1, l.details->>'audio_url' WHERE l.feature_id = $2 AND COALESCE(l.details->'speech_mode'->>'situation', '') <> ''
+			UNION ALL
+			SELECT 'script_audio', (t.idx - 1)::int, t.turn->>'audio_url'
+			FROM jsonb_array_elements(
+				CASE WHEN jsonb_typeof(l.details->'speech_mode'->'script') = 'array' THEN l.details->'speech_mode'->'script' ELSE '[]'::jsonb END
+			) WITH ORDINALITY AS t(turn, idx)
+			WHERE l.feature_id = $2 AND UPPER(COALESCE(t.turn->>'speaker', '')) = 'AI' AND COALESCE(t.turn->>'text', '') <> ''
+		) m
+		WHERE l.is_active = true AND l.feature_id IN ($1, $2)
+		ORDER BY l.created_at, l.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, client.FeatureVideo, client.FeatureDialog)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list media", err)
+	}
+	defer rows.Close()
+
+	refs := make([]*MediaRef, 0)
+	for rows.Next() {
+		var ref MediaRef
+		if err := rows.Scan(&ref.LearningID, &ref.FeatureID, &ref.Language, &ref.Field, &ref.TurnIndex, &ref.URL); err != nil {
+			return nil, errors.InternalWrap("failed to scan media", err)
+		}
+		refs = append(refs, &ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list media", err)
+	}
+
+	return refs, nil
+}
+
+// SaveMediaIssues replaces the report with the issues of a check.
+func (r *mediaRepository) SaveMediaIssues(ctx context.Context, issues []*MediaIssue, checkedAt time.Time) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, issue := range issues {
+		turnIndex := -1
+		if issue.TurnIndex != nil {
+			turnIndex = *issue.TurnIndex
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO media_issues (learning_id, feature_id, language, field, turn_index, url, problem, status_code, repair_queued, checked_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (learning_id, field, turn_index) DO UPDATE
+			SET url = EXCLUDED.url, problem = EXCLUDED.problem, status_code = EXCLUDED.status_code,
+				repair_queued = EXCLUDED.repair_queued, checked_at = EXCLUDED.checked_at
+		`, issue.LearningID, issue.FeatureID, issue.Language, issue.Field, turnIndex, issue.URL, issue.Problem,
+			issue.StatusCode, issue.RepairQueued, checkedAt); err != nil {
+			return errors.InternalWrap("failed to save media issue", err)
+		}
+	}
+
+	// Media that resolves again leaves the report
+	if _, err := tx.Exec(ctx, `DELETE FROM media_issues WHERE checked_at < $1`, checkedAt); err != nil {
+		return errors.InternalWrap("failed to clear resolved media issues", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit media issues", err)
+	}
+	return nil
+}
+
+// ListMediaIssues returns a page of the report ("" problem and 0 feature match every issue).
+func (r *mediaRepository) ListMediaIssues(ctx context.Context, problem string, featureID, limit, offset int) ([]*MediaIssue, int, *errors.AppError) {
+	where := `WHERE ($1 = '' OR problem = $1) AND ($2 = 0 OR feature_id = $2)`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM media_issues `+where, problem, featureID).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count media issues", err)
+	}
+
+	query := `
+		SELECT learning_id::text, feature_id, language, field, turn_index, url, problem, status_code, repair_queued, checked_at
+		FROM media_issues ` + where + `
+		ORDER BY feature_id, language, learning_id, field, turn_index
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, problem, featureID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list media issues", err)
+	}
+	defer rows.Close()
+
+	issues := make([]*MediaIssue, 0)
+	for rows.Next() {
+		var issue MediaIssue
+		var turnIndex int
+		if err := rows.Scan(&issue.LearningID, &issue.FeatureID, &issue.Language, &issue.Field, &turnIndex, &issue.URL,
+			&issue.Problem, &issue.StatusCode, &issue.RepairQueued, &issue.CheckedAt); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan media issue", err)
+		}
+		if turnIndex >= 0 {
+			issue.TurnIndex = &turnIndex
+		}
+		issues = append(issues, &issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list media issues", err)
+	}
+
+	return issues, total, nil
+}
@@ -0,0 +1,323 @@
+package deck
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// DeckHandler handles deck HTTP endpoints.
+type DeckHandler struct {
+	service *DeckService
+}
+
+// NewDeckHandler creates a new deck handler.
+func NewDeckHandler(service *DeckService) *DeckHandler {
+	return &DeckHandler{
+		service: service,
+	}
+}
+
+// CreateDeck handles POST /api/v1/decks
+func (h *DeckHandler) CreateDeck(w http.ResponseWriter, r *http.Request) {
+	var req CreateDeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.CreateDeck(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, deck)
+}
+
+// ListDecks handles GET /api/v1/decks
+func (h *DeckHandler) ListDecks(w http.ResponseWriter, r *http.Request) {
+	var req ListDecksRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListDecks(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// GetDeck handles GET /api/v1/decks/{deckID}
+func (h *DeckHandler) GetDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.GetDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// UpdateDeck handles PATCH /api/v1/decks/{deckID}
+func (h *DeckHandler) UpdateDeck(w http.ResponseWriter, r *http.Request) {
+	var req UpdateDeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.UpdateDeck(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// DeleteDeck handles DELETE /api/v1/decks/{deckID}
+func (h *DeckHandler) DeleteDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteDeck(r.Context(), req.DeckID, req.UserID); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// AddItem handles PUT /api/v1/decks/{deckID}/items/{itemID}
+func (h *DeckHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	var req DeckItemRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.AddItem(r.Context(), req.DeckID, req.ItemID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// RemoveItem handles DELETE /api/v1/decks/{deckID}/items/{itemID}
+func (h *DeckHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	var req DeckItemRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.RemoveItem(r.Context(), req.DeckID, req.ItemID, req.UserID); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ShareDeck handles POST /api/v1/decks/{deckID}/share
+func (h *DeckHandler) ShareDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.ShareDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// UnshareDeck handles DELETE /api/v1/decks/{deckID}/share
+func (h *DeckHandler) UnshareDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.UnshareDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// GetSharedDeck handles GET /api/v1/decks/shared/{token}
+func (h *DeckHandler) GetSharedDeck(w http.ResponseWriter, r *http.Request) {
+	var req SharedDeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.GetSharedDeck(r.Context(), req.Token, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// CopySharedDeck handles POST /api/v1/decks/shared/{token}/copy
+func (h *DeckHandler) CopySharedDeck(w http.ResponseWriter, r *http.Request) {
+	var req SharedDeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.CopySharedDeck(r.Context(), req.Token, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, deck)
+}
+
+// GetReviewQueue handles GET /api/v1/decks/{deckID}/review
+func (h *DeckHandler) GetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	var req ReviewQueueRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	queue, err := h.service.GetReviewQueue(r.Context(), req.DeckID, req.UserID, req.Limit)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, queue)
+}
+
+// ReviewItem handles POST /api/v1/decks/{deckID}/items/{itemID}/review
+func (h *DeckHandler) ReviewItem(w http.ResponseWriter, r *http.Request) {
+	var req ReviewItemRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	review, err := h.service.ReviewItem(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, review)
+}
+
+// PublishDeck handles POST /api/v1/decks/{deckID}/publish
+func (h *DeckHandler) PublishDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.PublishDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// UnpublishDeck handles POST /api/v1/decks/{deckID}/unpublish
+func (h *DeckHandler) UnpublishDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.UnpublishDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// ListPublicDecks handles GET /api/v1/decks/public
+func (h *DeckHandler) ListPublicDecks(w http.ResponseWriter, r *http.Request) {
+	var req ListPublicDecksRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListPublicDecks(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// GetPublicDeck handles GET /api/v1/decks/public/{deckID}
+func (h *DeckHandler) GetPublicDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.GetPublicDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, deck)
+}
+
+// CopyPublicDeck handles POST /api/v1/decks/public/{deckID}/copy
+func (h *DeckHandler) CopyPublicDeck(w http.ResponseWriter, r *http.Request) {
+	var req DeckRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	deck, err := h.service.CopyPublicDeck(r.Context(), req.DeckID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, deck)
+}
@@ -0,0 +1,583 @@
+package deck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Deck is a row of the decks table.
+type Deck struct {
+	ID          string  `json:"id"`
+	UserID      string  `json:"-"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ShareToken  *string `json:"share_token,omitempty"`
+	ItemCount   int     `json:"item_count"`
+	OwnerName   string  `json:"owner_name"`
+	// PublishedAt is set while the deck is listed in the public marketplace
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CopiedFrom  *string    `json:"copied_from,omitempty"`
+	CopyCount   int        `json:"copy_count"`
+	ViewCount   int        `json:"view_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// Favorited by the requesting user (lists only)
+	Favorited bool `json:"favorited"`
+}
+
+// PublicDeckFilter narrows the public marketplace listing.
+type PublicDeckFilter struct {
+	Query    string
+	Language string
+	Sort     string
+	Limit    int
+	Offset   int
+	// Viewer marks the listed decks the user favorited; it doesn't filter
+	Viewer string
+}
+
+// DeckItem is a learning item in a deck.
+type DeckItem struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	Language string    `json:"language"`
+	Level    *string   `json:"level,omitempty"`
+	AddedAt  time.Time `json:"added_at"`
+	// Review is the item's review schedule (review queue only)
+	Review *ReviewState `json:"review,omitempty"`
+}
+
+// ReviewState is the spaced-repetition schedule of a deck item.
+type ReviewState struct {
+	IntervalDays int     `json:"interval_days"`
+	Ease         float64 `json:"ease"`
+	// Streak counts the passed reviews since the last failed one
+	Streak     int        `json:"streak"`
+	DueAt      *time.Time `json:"due_at"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+}
+
+// DeckRepository stores decks and the learning items they collect.
+type DeckRepository interface {
+	CountDecks(ctx context.Context, userID string) (int, *errors.AppError)
+	CreateDeck(ctx context.Context, deck *Deck) (*Deck, *errors.AppError)
+	ListDecks(ctx context.Context, userID string, limit, offset int) ([]*Deck, int, *errors.AppError)
+	GetDeck(ctx context.Context, deckID, viewerID string) (*Deck, *errors.AppError)
+	GetDeckByShareToken(ctx context.Context, token, viewerID string) (*Deck, *errors.AppError)
+	UpdateDeck(ctx context.Context, deckID string, name, description *string) (*Deck, *errors.AppError)
+	DeleteDeck(ctx context.Context, deckID string) *errors.AppError
+	SetShareToken(ctx context.Context, deckID string, token *string) (*Deck, *errors.AppError)
+	CopyDeck(ctx context.Context, sourceID string, deck *Deck) (*Deck, *errors.AppError)
+	SetPublished(ctx context.Context, deckID string, published bool) (*Deck, *errors.AppError)
+	ListPublicDecks(ctx context.Context, filter PublicDeckFilter) ([]*Deck, int, *errors.AppError)
+	IncrementViews(ctx context.Context, deckID string) *errors.AppError
+	IsStudyable(ctx context.Context, itemID, userID string) (bool, *errors.AppError)
+	AddItem(ctx context.Context, deckID, itemID string) *errors.AppError
+	RemoveItem(ctx context.Context, deckID, itemID string) (bool, *errors.AppError)
+	ListItems(ctx context.Context, deckID, userID string) ([]*DeckItem, *errors.AppError)
+	ListDueItems(ctx context.Context, deckID, userID string, now time.Time, limit int) ([]*DeckItem, int, *errors.AppError)
+	ReviewItem(ctx context.Context, deckID, itemID string, review func(state *ReviewState)) (*ReviewState, *errors.AppError)
+}
+
+type deckRepository struct {
+	db *client.PostgresClient
+}
+
+// NewDeckRepository creates a new deck repository.
+func NewDeckRepository(db *client.PostgresClient) DeckRepository {
+	return &deckRepository{db: db}
+}
+
+// deckColumns selects a deck with its item count and owner name; d is the decks alias. The owner
+// counts every item, other viewers (ID in viewerParam) only the items they may study.
+func deckColumns(viewerParam string) string {
+	return `d.id::text, d.user_id::text, d.name, d.description, d.share_token::text,
+	(SELECT COUNT(*) FROM deck_items i JOIN learning_items l ON l.id = i.learning_id
+		WHERE i.deck_id = d.id AND (d.user_id::text = ` + viewerParam + ` OR ` + studyableSQL("l", viewerParam) + `)),
+	COALESCE((SELECT u.display_name FROM users u WHERE u.id = d.user_id), ''),
+	d.published_at, d.copied_from::text, d.copy_count, d.view_count, d.created_at, d.updated_at`
+}
+
+// ownerDeckColumns selects a deck as its owner sees it.
+var ownerDeckColumns = deckColumns("d.user_id::text")
+
+func scanDeck(row pgx.Row) (*Deck, error) {
+	var deck Deck
+	if err := row.Scan(deckDest(&deck)...); err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// scanListedDeck reads deckColumns followed by a FavoritedSQL column.
+func scanListedDeck(row pgx.Row) (*Deck, error) {
+	var deck Deck
+	if err := row.Scan(append(deckDest(&deck), &deck.Favorited)...); err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+func deckDest(deck *Deck) []any {
+	return []any{&deck.ID, &deck.UserID, &deck.Name, &deck.Description, &deck.ShareToken,
+		&deck.ItemCount, &deck.OwnerName, &deck.PublishedAt, &deck.CopiedFrom, &deck.CopyCount, &deck.ViewCount,
+		&deck.CreatedAt, &deck.UpdatedAt}
+}
+
+// studyableSQL selects the items the user may study: active videos, and dialogs that are
+// published or owned by the user, without adult-audience items for minors (same as bundles).
+func studyableSQL(alias, userParam string) string {
+	return fmt.Sprintf(`%[1]s.is_active = true
+		AND (%[1]s.feature_id = %[3]d OR (%[1]s.feature_id = %[4]d AND (%[1]s.published_details IS NOT NULL OR %[1]s.created_by = %[2]s)))
+		AND `, alias, userParam, client.FeatureVideo, client.FeatureDialog) + client.AudienceVisibleSQL(alias, userParam)
+}
+
+func itemType(featureID int) string {
+	if featureID == client.FeatureDialog {
+		return ITEM_TYPE_DIALOG
+	}
+	return ITEM_TYPE_VIDEO
+}
+
+func (r *deckRepository) CountDecks(ctx context.Context, userID string) (int, *errors.AppError) {
+	var count int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM decks WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, errors.InternalWrap("failed to count decks", err)
+	}
+	return count, nil
+}
+
+func (r *deckRepository) CreateDeck(ctx context.Context, deck *Deck) (*Deck, *errors.AppError) {
+	query := `
+		INSERT INTO decks (user_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id::text
+	`
+
+	var deckID string
+	if err := r.db.Pool.QueryRow(ctx, query, deck.UserID, deck.Name, deck.Description).Scan(&deckID); err != nil {
+		return nil, errors.InternalWrap("failed to create deck", err)
+	}
+	return r.GetDeck(ctx, deckID, deck.UserID)
+}
+
+// ListDecks returns a page of the user's decks, most recently changed first, and their total.
+func (r *deckRepository) ListDecks(ctx context.Context, userID string, limit, offset int) ([]*Deck, int, *errors.AppError) {
+	query := `
+		SELECT ` + ownerDeckColumns + `, ` + client.FavoritedSQL("d", client.FavoriteDeck, "$1::text") + `
+		FROM decks d
+		WHERE d.user_id = $1
+		ORDER BY d.updated_at DESC, d.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list decks", err)
+	}
+	defer rows.Close()
+
+	decks := make([]*Deck, 0)
+	for rows.Next() {
+		deck, err := scanListedDeck(rows)
+		if err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan deck", err)
+		}
+		decks = append(decks, deck)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list decks", err)
+	}
+
+	total, appErr := r.CountDecks(ctx, userID)
+	if appErr != nil {
+		return nil, 0, appErr
+	}
+	return decks, total, nil
+}
+
+// GetDeck returns the deck as viewerID sees it (see deckColumns).
+func (r *deckRepository) GetDeck(ctx context.Context, deckID, viewerID string) (*Deck, *errors.AppError) {
+	deck, err := scanDeck(r.db.Pool.QueryRow(ctx, `SELECT `+deckColumns("$2::text")+` FROM decks d WHERE d.id = $1`, deckID, viewerID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("deck not found")
+		}
+		return nil, errors.InternalWrap("failed to get deck", err)
+	}
+	return deck, nil
+}
+
+// GetDeckByShareToken returns the shared deck as viewerID sees it (see deckColumns).
+func (r *deckRepository) GetDeckByShareToken(ctx context.Context, token, viewerID string) (*Deck, *errors.AppError) {
+	deck, err := scanDeck(r.db.Pool.QueryRow(ctx, `SELECT `+deckColumns("$2::text")+` FROM decks d WHERE d.share_token = $1`, token, viewerID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("shared deck not found")
+		}
+		return nil, errors.InternalWrap("failed to get shared deck", err)
+	}
+	return deck, nil
+}
+
+// UpdateDeck changes the fields that are not nil.
+func (r *deckRepository) UpdateDeck(ctx context.Context, deckID string, name, description *string) (*Deck, *errors.AppError) {
+	query := `
+		UPDATE decks d
+		SET name = COALESCE($2, d.name), description = COALESCE($3, d.description), updated_at = NOW()
+		WHERE d.id = $1
+		RETURNING ` + ownerDeckColumns
+
+	deck, err := scanDeck(r.db.Pool.QueryRow(ctx, query, deckID, name, description))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("deck not found")
+		}
+		return nil, errors.InternalWrap("failed to update deck", err)
+	}
+	return deck, nil
+}
+
+func (r *deckRepository) DeleteDeck(ctx context.Context, deckID string) *errors.AppError {
+	cmdTag, err := r.db.Pool.Exec(ctx, `
+		WITH unfavorited AS (DELETE FROM favorites WHERE content_type = 'deck' AND content_id = $1)
+		DELETE FROM decks WHERE id = $1
+	`, deckID)
+	if err != nil {
+		return errors.InternalWrap("failed to delete deck", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.NotFound("deck not found")
+	}
+	return nil
+}
+
+// SetShareToken sets or, with nil, clears the share link of the deck.
+func (r *deckRepository) SetShareToken(ctx context.Context, deckID string, token *string) (*Deck, *errors.AppError) {
+	query := `
+		UPDATE decks d
+		SET share_token = $2::uuid, updated_at = NOW()
+		WHERE d.id = $1
+		RETURNING ` + ownerDeckColumns
+
+	deck, err := scanDeck(r.db.Pool.QueryRow(ctx, query, deckID, token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("deck not found")
+		}
+		return nil, errors.InternalWrap("failed to share deck", err)
+	}
+	return deck, nil
+}
+
+// CopyDeck creates deck with clones of the source deck's items and counts the copy on the source.
+// Only the items the copier may study are cloned. The clones belong to the copier and keep the
+// media URLs of the originals, so the media is shared rather than duplicated; dialogs the copier
+// does not own are cloned from their published version.
+func (r *deckRepository) CopyDeck(ctx context.Context, sourceID string, deck *Deck) (*Deck, *errors.AppError) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deckID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO decks (user_id, name, description, copied_from)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id::text
+	`, deck.UserID, deck.Name, deck.Description, sourceID).Scan(&deckID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to copy deck", err)
+	}
+
+	// source is materialized once, so each item keeps the clone ID drawn for it
+	if _, err := tx.Exec(ctx, `
+		WITH source AS (
+			SELECT uuid_generate_v4() AS clone_id, l.id, d.added_at
+			FROM deck_items d
+			JOIN learning_items l ON l.id = d.learning_id
+			WHERE d.deck_id = $2 AND `+studyableSQL("l", "$3::text")+`
+		), cloned AS (
+			INSERT INTO learning_items (id, feature_id, content, language, level, details, metadata, tags,
+				is_active, created_by, audience, quality_score, review_status)
+			SELECT s.clone_id, l.feature_id, l.content, l.language, l.level,
+				CASE WHEN l.created_by = $3::text THEN l.details ELSE COALESCE(l.published_details, l.details) END,
+				l.metadata, l.tags, l.is_active, $3::text, l.audience, l.quality_score, l.review_status
+			FROM source s
+			JOIN learning_items l ON l.id = s.id
+			RETURNING id
+		)
+		INSERT INTO deck_items (deck_id, learning_id, added_at)
+		SELECT $1, s.clone_id, s.added_at
+		FROM source s
+		JOIN cloned c ON c.id = s.clone_id
+	`, deckID, sourceID, deck.UserID); err != nil {
+		return nil, errors.InternalWrap("failed to copy deck items", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE decks SET copy_count = copy_count + 1 WHERE id = $1`, sourceID); err != nil {
+		return nil, errors.InternalWrap("failed to count deck copy", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.InternalWrap("failed to commit deck copy", err)
+	}
+	return r.GetDeck(ctx, deckID, deck.UserID)
+}
+
+// SetPublished lists the deck in the public marketplace or takes it out.
+func (r *deckRepository) SetPublished(ctx context.Context, deckID string, published bool) (*Deck, *errors.AppError) {
+	query := `
+		UPDATE decks d
+		SET published_at = CASE WHEN $2 THEN COALESCE(d.published_at, NOW()) END, updated_at = NOW()
+		WHERE d.id = $1
+		RETURNING ` + ownerDeckColumns
+
+	deck, err := scanDeck(r.db.Pool.QueryRow(ctx, query, deckID, published))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("deck not found")
+		}
+		return nil, errors.InternalWrap("failed to publish deck", err)
+	}
+	return deck, nil
+}
+
+// ListPublicDecks returns a page of published decks matching the filter, and their total.
+// Query matches the name or description; Language keeps decks with an item in that language.
+func (r *deckRepository) ListPublicDecks(ctx context.Context, filter PublicDeckFilter) ([]*Deck, int, *errors.AppError) {
+	where := `d.published_at IS NOT NULL
+		AND ($1 = '' OR d.name ILIKE '%' || $1 || '%' OR d.description ILIKE '%' || $1 || '%')
+		AND ($2 = '' OR EXISTS (
+			SELECT 1 FROM deck_items i JOIN learning_items l ON l.id = i.learning_id
+			WHERE i.deck_id = d.id AND l.language = $2))`
+
+	order := `d.copy_count DESC, d.view_count DESC, d.published_at DESC`
+	if filter.Sort == SORT_RECENT {
+		order = `d.published_at DESC`
+	}
+
+	query := `
+		SELECT ` + deckColumns("$5::text") + `, ` + client.FavoritedSQL("d", client.FavoriteDeck, "$5::text") + `
+		FROM decks d
+		WHERE ` + where + `
+		ORDER BY ` + order + `, d.id
+		LIMIT $3 OFFSET $4
+	`
+
+	search := escapeLike(filter.Query)
+	rows, err := r.db.Pool.Query(ctx, query, search, filter.Language, filter.Limit, filter.Offset, filter.Viewer)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list public decks", err)
+	}
+	defer rows.Close()
+
+	decks := make([]*Deck, 0)
+	for rows.Next() {
+		deck, err := scanListedDeck(rows)
+		if err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan public deck", err)
+		}
+		// Share links stay private to the owner
+		deck.ShareToken = nil
+		decks = append(decks, deck)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list public decks", err)
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM decks d WHERE `+where, search, filter.Language).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count public decks", err)
+	}
+	return decks, total, nil
+}
+
+// escapeLike escapes the LIKE wildcards in a search term.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// IncrementViews counts a view of a public deck.
+func (r *deckRepository) IncrementViews(ctx context.Context, deckID string) *errors.AppError {
+	if _, err := r.db.Pool.Exec(ctx, `UPDATE decks SET view_count = view_count + 1 WHERE id = $1`, deckID); err != nil {
+		return errors.InternalWrap("failed to count deck view", err)
+	}
+	return nil
+}
+
+// IsStudyable reports whether the user may add the item to a deck.
+func (r *deckRepository) IsStudyable(ctx context.Context, itemID, userID string) (bool, *errors.AppError) {
+	query := `SELECT EXISTS (SELECT 1 FROM learning_items l WHERE l.id = $1 AND ` + studyableSQL("l", "$2") + `)`
+
+	var ok bool
+	if err := r.db.Pool.QueryRow(ctx, query, itemID, userID).Scan(&ok); err != nil {
+		return false, errors.InternalWrap("failed to check learning item", err)
+	}
+	return ok, nil
+}
+
+// AddItem adds the item to the deck; adding it again is a no-op.
+func (r *deckRepository) AddItem(ctx context.Context, deckID, itemID string) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `
+		INSERT INTO deck_items (deck_id, learning_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, deckID, itemID)
+	if err != nil {
+		return errors.InternalWrap("failed to add deck item", err)
+	}
+	if cmdTag.RowsAffected() > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE decks SET updated_at = NOW() WHERE id = $1`, deckID); err != nil {
+			return errors.InternalWrap("failed to touch deck", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit deck item", err)
+	}
+	return nil
+}
+
+// RemoveItem removes the item from the deck; false when it was not in the deck.
+func (r *deckRepository) RemoveItem(ctx context.Context, deckID, itemID string) (bool, *errors.AppError) {
+	query := `
+		WITH removed AS (
+			DELETE FROM deck_items
+			WHERE deck_id = $1 AND learning_id = $2
+			RETURNING deck_id
+		)
+		UPDATE decks SET updated_at = NOW()
+		WHERE id IN (SELECT deck_id FROM removed)
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, deckID, itemID)
+	if err != nil {
+		return false, errors.InternalWrap("failed to remove deck item", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// ListItems returns the deck items the user may study, most recently added first.
+func (r *deckRepository) ListItems(ctx context.Context, deckID, userID string) ([]*DeckItem, *errors.AppError) {
+	query := `
+		SELECT l.id::text, l.feature_id, l.content, l.language, l.level, d.added_at
+		FROM deck_items d
+		JOIN learning_items l ON l.id = d.learning_id
+		WHERE d.deck_id = $1 AND ` + studyableSQL("l", "$2") + `
+		ORDER BY d.added_at DESC, l.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, deckID, userID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list deck items", err)
+	}
+	defer rows.Close()
+
+	items := make([]*DeckItem, 0)
+	for rows.Next() {
+		var item DeckItem
+		var featureID int
+		if err := rows.Scan(&item.ID, &featureID, &item.Title, &item.Language, &item.Level, &item.AddedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan deck item", err)
+		}
+		item.Type = itemType(featureID)
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list deck items", err)
+	}
+	return items, nil
+}
+
+// ListDueItems returns up to limit deck items the user may study that are due at now: never
+// reviewed first, then the longest overdue. It also returns how many are due in total.
+func (r *deckRepository) ListDueItems(ctx context.Context, deckID, userID string, now time.Time, limit int) ([]*DeckItem, int, *errors.AppError) {
+	query := `
+		SELECT l.id::text, l.feature_id, l.content, l.language, l.level, d.added_at,
+			d.review_interval, d.review_ease, d.review_streak, d.due_at, d.reviewed_at,
+			COUNT(*) OVER ()
+		FROM deck_items d
+		JOIN learning_items l ON l.id = d.learning_id
+		WHERE d.deck_id = $1 AND ` + studyableSQL("l", "$2") + `
+			AND (d.due_at IS NULL OR d.due_at <= $3)
+		ORDER BY d.due_at ASC NULLS FIRST, d.added_at, l.id
+		LIMIT $4
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, deckID, userID, now, limit)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list due deck items", err)
+	}
+	defer rows.Close()
+
+	items := make([]*DeckItem, 0)
+	total := 0
+	for rows.Next() {
+		item := DeckItem{Review: &ReviewState{}}
+		var featureID int
+		if err := rows.Scan(&item.ID, &featureID, &item.Title, &item.Language, &item.Level, &item.AddedAt,
+			&item.Review.IntervalDays, &item.Review.Ease, &item.Review.Streak, &item.Review.DueAt, &item.Review.ReviewedAt,
+			&total); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan due deck item", err)
+		}
+		item.Type = itemType(featureID)
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list due deck items", err)
+	}
+	return items, total, nil
+}
+
+// ReviewItem applies review to the schedule of a deck item and saves the result. The item is
+// locked meanwhile, so concurrent reviews apply one after the other.
+func (r *deckRepository) ReviewItem(ctx context.Context, deckID, itemID string, review func(state *ReviewState)) (*ReviewState, *errors.AppError) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var state ReviewState
+	err = tx.QueryRow(ctx, `
+		SELECT review_interval, review_ease, review_streak, due_at, reviewed_at
+		FROM deck_items
+		WHERE deck_id = $1 AND learning_id = $2
+		FOR UPDATE
+	`, deckID, itemID).Scan(&state.IntervalDays, &state.Ease, &state.Streak, &state.DueAt, &state.ReviewedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("learning item not in deck")
+		}
+		return nil, errors.InternalWrap("failed to get deck item review", err)
+	}
+
+	review(&state)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE deck_items
+		SET review_interval = $3, review_ease = $4, review_streak = $5, due_at = $6, reviewed_at = $7
+		WHERE deck_id = $1 AND learning_id = $2
+	`, deckID, itemID, state.IntervalDays, state.Ease, state.Streak, state.DueAt, state.ReviewedAt); err != nil {
+		return nil, errors.InternalWrap("failed to save deck item review", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.InternalWrap("failed to commit deck item review", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,452 @@
+package deck
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/domain/video"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Item types a deck can hold
+const (
+	ITEM_TYPE_VIDEO  = "video"
+	ITEM_TYPE_DIALOG = "dialog"
+)
+
+// Sort orders of the public marketplace
+const (
+	SORT_POPULAR = "popular"
+	SORT_RECENT  = "recent"
+)
+
+// Limits
+const (
+	maxNameLength        = 100
+	maxDescriptionLength = 1000
+	defaultPageSize      = 20
+	maxPageSize          = 100
+	defaultReviewLimit   = 20
+	maxReviewLimit       = 100
+	maxSearchLength      = 100
+)
+
+// -------------------------------------------------------------------------
+// Create Deck Request
+// -------------------------------------------------------------------------
+
+// CreateDeckRequest is the HTTP request struct for creating a deck
+type CreateDeckRequest struct {
+	UserID      string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CreateDeckInput is the input struct for service
+type CreateDeckInput struct {
+	UserID      string
+	Name        string
+	Description string
+}
+
+func (req *CreateDeckRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse JSON Body
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if err := validateDeckFields(&req.Name, &req.Description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ToInput convert CreateDeckRequest to CreateDeckInput
+func (req *CreateDeckRequest) ToInput() CreateDeckInput {
+	return CreateDeckInput{
+		UserID:      req.UserID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
+
+// validateDeckFields trims and bounds the name and description that are set.
+func validateDeckFields(name, description *string) error {
+	if name != nil {
+		*name = strings.TrimSpace(*name)
+		if *name == "" {
+			return errors.Validation("name must not be empty")
+		}
+		if utf8.RuneCountInString(*name) > maxNameLength {
+			return errors.Validation("name must be at most 100 characters")
+		}
+	}
+	if description != nil {
+		*description = strings.TrimSpace(*description)
+		if utf8.RuneCountInString(*description) > maxDescriptionLength {
+			return errors.Validation("description must be at most 1000 characters")
+		}
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// List Decks Request
+// -------------------------------------------------------------------------
+
+// ListDecksRequest is the HTTP request struct for listing the user's decks
+type ListDecksRequest struct {
+	UserID   string
+	Page     int
+	PageSize int
+}
+
+// ListDecksInput is the input struct for service
+type ListDecksInput struct {
+	UserID   string
+	Page     int
+	PageSize int
+	Limit    int
+	Offset   int
+}
+
+// Parse reads the optional pagination params
+func (req *ListDecksRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	q := r.URL.Query()
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListDecksRequest to ListDecksInput
+func (req *ListDecksRequest) ToInput() ListDecksInput {
+	return ListDecksInput{
+		UserID:   req.UserID,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Deck Request (get, delete, share, publish and their reverses, public read and copy)
+// -------------------------------------------------------------------------
+
+// DeckRequest is the HTTP request struct for endpoints on one of the user's decks
+type DeckRequest struct {
+	UserID string
+	DeckID string
+}
+
+func (req *DeckRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.DeckID = chi.URLParam(r, "deckID")
+	if _, err := uuid.Parse(req.DeckID); err != nil {
+		return errors.Validation("invalid deck id")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Update Deck Request
+// -------------------------------------------------------------------------
+
+// UpdateDeckRequest is the HTTP request struct for renaming a deck
+type UpdateDeckRequest struct {
+	UserID      string  `json:"-"`
+	DeckID      string  `json:"-"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// UpdateDeckInput is the input struct for service
+type UpdateDeckInput struct {
+	UserID      string
+	DeckID      string
+	Name        *string
+	Description *string
+}
+
+func (req *UpdateDeckRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID and deck ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+	req.DeckID = chi.URLParam(r, "deckID")
+	if _, err := uuid.Parse(req.DeckID); err != nil {
+		return errors.Validation("invalid deck id")
+	}
+
+	// 2. Parse JSON Body
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	if req.Name == nil && req.Description == nil {
+		return errors.Validation("name or description is required")
+	}
+	if err := validateDeckFields(req.Name, req.Description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ToInput convert UpdateDeckRequest to UpdateDeckInput
+func (req *UpdateDeckRequest) ToInput() UpdateDeckInput {
+	return UpdateDeckInput{
+		UserID:      req.UserID,
+		DeckID:      req.DeckID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Deck Item Request (add, remove)
+// -------------------------------------------------------------------------
+
+// DeckItemRequest is the HTTP request struct for adding or removing a deck item
+type DeckItemRequest struct {
+	UserID string
+	DeckID string
+	ItemID string
+}
+
+func (req *DeckItemRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.DeckID = chi.URLParam(r, "deckID")
+	if _, err := uuid.Parse(req.DeckID); err != nil {
+		return errors.Validation("invalid deck id")
+	}
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Shared Deck Request
+// -------------------------------------------------------------------------
+
+// SharedDeckRequest is the HTTP request struct for opening or copying a deck shared by link
+type SharedDeckRequest struct {
+	UserID string
+	Token  string
+}
+
+func (req *SharedDeckRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.Token = chi.URLParam(r, "token")
+	if _, err := uuid.Parse(req.Token); err != nil {
+		return errors.NotFound("shared deck not found")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// List Public Decks Request
+// -------------------------------------------------------------------------
+
+// ListPublicDecksRequest is the HTTP request struct for browsing the public marketplace
+type ListPublicDecksRequest struct {
+	UserID   string
+	Query    string
+	Language string
+	Sort     string
+	Page     int
+	PageSize int
+}
+
+// ListPublicDecksInput is the input struct for service
+type ListPublicDecksInput struct {
+	Filter   PublicDeckFilter
+	Page     int
+	PageSize int
+}
+
+// Parse reads the optional search, language, sort (default popular) and pagination params
+func (req *ListPublicDecksRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	q := r.URL.Query()
+
+	req.Query = strings.TrimSpace(q.Get("q"))
+	if utf8.RuneCountInString(req.Query) > maxSearchLength {
+		return errors.Validation("q must be at most 100 characters")
+	}
+
+	req.Language = strings.ToLower(q.Get("language"))
+	if req.Language != "" && !video.AllowedLanguages[req.Language] {
+		return errors.Validation("unsupported language")
+	}
+
+	req.Sort = strings.ToLower(q.Get("sort"))
+	if req.Sort == "" {
+		req.Sort = SORT_POPULAR
+	}
+	if req.Sort != SORT_POPULAR && req.Sort != SORT_RECENT {
+		return errors.Validation("sort must be popular or recent")
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListPublicDecksRequest to ListPublicDecksInput
+func (req *ListPublicDecksRequest) ToInput() ListPublicDecksInput {
+	return ListPublicDecksInput{
+		Filter: PublicDeckFilter{
+			Query:    req.Query,
+			Language: req.Language,
+			Sort:     req.Sort,
+			Limit:    req.PageSize,
+			Offset:   (req.Page - 1) * req.PageSize,
+			Viewer:   req.UserID,
+		},
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Review Queue Request
+// -------------------------------------------------------------------------
+
+// ReviewQueueRequest is the HTTP request struct for a deck's review queue
+type ReviewQueueRequest struct {
+	UserID string
+	DeckID string
+	Limit  int
+}
+
+func (req *ReviewQueueRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.DeckID = chi.URLParam(r, "deckID")
+	if _, err := uuid.Parse(req.DeckID); err != nil {
+		return errors.Validation("invalid deck id")
+	}
+
+	req.Limit = defaultReviewLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxReviewLimit {
+			return errors.Validation("limit must be between 1 and 100")
+		}
+		req.Limit = limit
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Review Item Request
+// -------------------------------------------------------------------------
+
+// ReviewItemRequest is the HTTP request struct for recording the practice score of a deck item
+type ReviewItemRequest struct {
+	UserID string   `json:"-"`
+	DeckID string   `json:"-"`
+	ItemID string   `json:"-"`
+	Score  *float64 `json:"score"`
+}
+
+// ReviewItemInput is the input struct for service
+type ReviewItemInput struct {
+	UserID string
+	DeckID string
+	ItemID string
+	Score  float64
+}
+
+func (req *ReviewItemRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.DeckID = chi.URLParam(r, "deckID")
+	if _, err := uuid.Parse(req.DeckID); err != nil {
+		return errors.Validation("invalid deck id")
+	}
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	if req.Score == nil || *req.Score < 0 || *req.Score > 100 {
+		return errors.Validation("score must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// ToInput convert ReviewItemRequest to ReviewItemInput
+func (req *ReviewItemRequest) ToInput() ReviewItemInput {
+	return ReviewItemInput{
+		UserID: req.UserID,
+		DeckID: req.DeckID,
+		ItemID: req.ItemID,
+		Score:  *req.Score,
+	}
+}
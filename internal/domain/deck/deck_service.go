@@ -0,0 +1,369 @@
+package deck
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// Deck limits per user
+const (
+	maxDecksPerUser = 100
+	maxDeckItems    = 500
+)
+
+// Review scheduling (SM-2). A practice score of passScore or more (out of 100) passes the review:
+// the interval grows to 1 day, then 6 days, then by the item's ease. A failed review starts over at
+// 1 day. Either way the ease moves with the score, down to minEase.
+const (
+	passScore       = 60
+	minEase         = 1.3
+	maxIntervalDays = 365
+)
+
+// DeckService manages user decks, their share links and review queues.
+type DeckService struct {
+	deckRepo DeckRepository
+}
+
+// ListDecksResponse is returned when listing the user's decks.
+type ListDecksResponse struct {
+	Data []*Deck                  `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// DeckDetailsResponse is a deck with the items the viewer may study.
+type DeckDetailsResponse struct {
+	*Deck
+	Items []*DeckItem `json:"items"`
+}
+
+// ReviewQueueResponse is the next items to review in a deck.
+type ReviewQueueResponse struct {
+	DeckID string      `json:"deck_id"`
+	Due    int         `json:"due"`
+	Items  []*DeckItem `json:"items"`
+}
+
+// NewDeckService creates a new DeckService.
+func NewDeckService(deckRepo DeckRepository) *DeckService {
+	return &DeckService{
+		deckRepo: deckRepo,
+	}
+}
+
+// CreateDeck creates an empty deck for the user.
+func (s *DeckService) CreateDeck(ctx context.Context, input CreateDeckInput) (*Deck, *errors.AppError) {
+	count, err := s.deckRepo.CountDecks(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxDecksPerUser {
+		return nil, errors.Conflict(fmt.Sprintf("at most %d decks are allowed", maxDecksPerUser))
+	}
+
+	return s.deckRepo.CreateDeck(ctx, &Deck{
+		UserID:      input.UserID,
+		Name:        input.Name,
+		Description: input.Description,
+	})
+}
+
+// ListDecks returns a page of the user's decks.
+func (s *DeckService) ListDecks(ctx context.Context, input ListDecksInput) (*ListDecksResponse, *errors.AppError) {
+	decks, total, err := s.deckRepo.ListDecks(ctx, input.UserID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListDecksResponse{
+		Data: decks,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// GetDeck returns one of the user's decks with its items.
+func (s *DeckService) GetDeck(ctx context.Context, deckID, userID string) (*DeckDetailsResponse, *errors.AppError) {
+	deck, err := s.ownDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withItems(ctx, deck, userID)
+}
+
+// UpdateDeck renames the deck or changes its description.
+func (s *DeckService) UpdateDeck(ctx context.Context, input UpdateDeckInput) (*Deck, *errors.AppError) {
+	if _, err := s.ownDeck(ctx, input.DeckID, input.UserID); err != nil {
+		return nil, err
+	}
+	return s.deckRepo.UpdateDeck(ctx, input.DeckID, input.Name, input.Description)
+}
+
+// DeleteDeck deletes the deck; the learning items are kept.
+func (s *DeckService) DeleteDeck(ctx context.Context, deckID, userID string) *errors.AppError {
+	if _, err := s.ownDeck(ctx, deckID, userID); err != nil {
+		return err
+	}
+	return s.deckRepo.DeleteDeck(ctx, deckID)
+}
+
+// AddItem adds a learning item the user may study to the deck.
+func (s *DeckService) AddItem(ctx context.Context, deckID, itemID, userID string) (*Deck, *errors.AppError) {
+	deck, err := s.ownDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.ItemCount >= maxDeckItems {
+		return nil, errors.Conflict(fmt.Sprintf("a deck holds at most %d items", maxDeckItems))
+	}
+
+	ok, err := s.deckRepo.IsStudyable(ctx, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.NotFound("learning item not found")
+	}
+
+	if err := s.deckRepo.AddItem(ctx, deckID, itemID); err != nil {
+		return nil, err
+	}
+	return s.deckRepo.GetDeck(ctx, deckID, userID)
+}
+
+// RemoveItem removes a learning item from the deck.
+func (s *DeckService) RemoveItem(ctx context.Context, deckID, itemID, userID string) *errors.AppError {
+	if _, err := s.ownDeck(ctx, deckID, userID); err != nil {
+		return err
+	}
+
+	removed, err := s.deckRepo.RemoveItem(ctx, deckID, itemID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.NotFound("learning item not in deck")
+	}
+	return nil
+}
+
+// ShareDeck returns the deck with its share token, creating one if the deck is not shared yet.
+func (s *DeckService) ShareDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	deck, err := s.ownDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.ShareToken != nil {
+		return deck, nil
+	}
+
+	token := uuid.New().String()
+	return s.deckRepo.SetShareToken(ctx, deckID, &token)
+}
+
+// UnshareDeck revokes the share link; copies made from it are kept.
+func (s *DeckService) UnshareDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	if _, err := s.ownDeck(ctx, deckID, userID); err != nil {
+		return nil, err
+	}
+	return s.deckRepo.SetShareToken(ctx, deckID, nil)
+}
+
+// GetSharedDeck returns a deck shared by link with the items the viewer may study.
+func (s *DeckService) GetSharedDeck(ctx context.Context, token, userID string) (*DeckDetailsResponse, *errors.AppError) {
+	deck, err := s.deckRepo.GetDeckByShareToken(ctx, token, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withItems(ctx, deck, userID)
+}
+
+// CopySharedDeck copies a deck shared by link into the user's decks.
+func (s *DeckService) CopySharedDeck(ctx context.Context, token, userID string) (*Deck, *errors.AppError) {
+	source, err := s.deckRepo.GetDeckByShareToken(ctx, token, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.copyDeck(ctx, source, userID)
+}
+
+// PublishDeck lists the deck in the public marketplace.
+func (s *DeckService) PublishDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	deck, err := s.ownDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.ItemCount == 0 {
+		return nil, errors.Validation("an empty deck cannot be published")
+	}
+	if deck.PublishedAt != nil {
+		return deck, nil
+	}
+	return s.deckRepo.SetPublished(ctx, deckID, true)
+}
+
+// UnpublishDeck takes the deck out of the public marketplace; copies are kept.
+func (s *DeckService) UnpublishDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	if _, err := s.ownDeck(ctx, deckID, userID); err != nil {
+		return nil, err
+	}
+	return s.deckRepo.SetPublished(ctx, deckID, false)
+}
+
+// ListPublicDecks returns a page of the public marketplace.
+func (s *DeckService) ListPublicDecks(ctx context.Context, input ListPublicDecksInput) (*ListDecksResponse, *errors.AppError) {
+	decks, total, err := s.deckRepo.ListPublicDecks(ctx, input.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListDecksResponse{
+		Data: decks,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// GetPublicDeck returns a published deck with the items the viewer may study and counts the
+// view (owners looking at their own deck are not counted).
+func (s *DeckService) GetPublicDeck(ctx context.Context, deckID, userID string) (*DeckDetailsResponse, *errors.AppError) {
+	deck, err := s.publicDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deck.UserID != userID {
+		if err := s.deckRepo.IncrementViews(ctx, deckID); err != nil {
+			return nil, err
+		}
+		deck.ViewCount++
+	}
+	return s.withItems(ctx, deck, userID)
+}
+
+// CopyPublicDeck copies a published deck into the user's decks.
+func (s *DeckService) CopyPublicDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	source, err := s.publicDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.copyDeck(ctx, source, userID)
+}
+
+// copyDeck copies source into the user's decks; the copy starts private and unshared.
+func (s *DeckService) copyDeck(ctx context.Context, source *Deck, userID string) (*Deck, *errors.AppError) {
+	count, err := s.deckRepo.CountDecks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxDecksPerUser {
+		return nil, errors.Conflict(fmt.Sprintf("at most %d decks are allowed", maxDecksPerUser))
+	}
+
+	return s.deckRepo.CopyDeck(ctx, source.ID, &Deck{
+		UserID:      userID,
+		Name:        source.Name,
+		Description: source.Description,
+	})
+}
+
+// GetReviewQueue returns the deck items due for review: never-reviewed items first, then the
+// longest overdue.
+func (s *DeckService) GetReviewQueue(ctx context.Context, deckID, userID string, limit int) (*ReviewQueueResponse, *errors.AppError) {
+	if _, err := s.ownDeck(ctx, deckID, userID); err != nil {
+		return nil, err
+	}
+
+	items, due, err := s.deckRepo.ListDueItems(ctx, deckID, userID, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewQueueResponse{DeckID: deckID, Due: due, Items: items}, nil
+}
+
+// ReviewItem records the practice score of a deck item and schedules its next review.
+func (s *DeckService) ReviewItem(ctx context.Context, input ReviewItemInput) (*ReviewState, *errors.AppError) {
+	if _, err := s.ownDeck(ctx, input.DeckID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.deckRepo.ReviewItem(ctx, input.DeckID, input.ItemID, func(state *ReviewState) {
+		scheduleReview(state, input.Score, now)
+	})
+}
+
+// scheduleReview moves state to the next review after a practice scoring score (0-100) at now.
+func scheduleReview(state *ReviewState, score float64, now time.Time) {
+	if score >= passScore {
+		state.Streak++
+		switch state.Streak {
+		case 1:
+			state.IntervalDays = 1
+		case 2:
+			state.IntervalDays = 6
+		default:
+			state.IntervalDays = min(int(math.Round(float64(state.IntervalDays)*state.Ease)), maxIntervalDays)
+		}
+	} else {
+		state.Streak = 0
+		state.IntervalDays = 1
+	}
+
+	// SM-2 grades answers 0-5
+	miss := 5 - score/20
+	state.Ease = max(state.Ease+0.1-miss*(0.08+miss*0.02), minEase)
+
+	due := now.AddDate(0, 0, state.IntervalDays)
+	state.DueAt, state.ReviewedAt = &due, &now
+}
+
+// publicDeck loads a published deck as the user sees it and hides unpublished ones as not found.
+func (s *DeckService) publicDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	deck, err := s.deckRepo.GetDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.PublishedAt == nil {
+		return nil, errors.NotFound("deck not found")
+	}
+	return deck, nil
+}
+
+// ownDeck loads the deck and hides decks of other users as not found.
+func (s *DeckService) ownDeck(ctx context.Context, deckID, userID string) (*Deck, *errors.AppError) {
+	deck, err := s.deckRepo.GetDeck(ctx, deckID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.UserID != userID {
+		return nil, errors.NotFound("deck not found")
+	}
+	return deck, nil
+}
+
+func (s *DeckService) withItems(ctx context.Context, deck *Deck, userID string) (*DeckDetailsResponse, *errors.AppError) {
+	items, err := s.deckRepo.ListItems(ctx, deck.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.UserID != userID {
+		// Only the owner manages the share link
+		deck.ShareToken = nil
+	}
+	return &DeckDetailsResponse{Deck: deck, Items: items}, nil
+}
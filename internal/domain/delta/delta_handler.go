@@ -0,0 +1,36 @@
+package delta
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// DeltaHandler handles delta sync HTTP endpoints.
+type DeltaHandler struct {
+	service *DeltaService
+}
+
+// NewDeltaHandler creates a new delta handler.
+func NewDeltaHandler(service *DeltaService) *DeltaHandler {
+	return &DeltaHandler{
+		service: service,
+	}
+}
+
+// Sync handles GET /api/v1/sync.
+func (h *DeltaHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	var req SyncRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, meta, err := h.service.Sync(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result, meta)
+}
@@ -0,0 +1,94 @@
+package delta
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Change is a learning item that changed after a cursor. Visible is false when the user can no
+// longer see the item (deleted, deactivated, or an unpublished dialog of someone else).
+type Change struct {
+	ID        string
+	FeatureID int
+	Content   string
+	Language  string
+	Level     *string
+	Tags      json.RawMessage
+	Details   json.RawMessage
+	Notes     json.RawMessage
+	Version   int
+	CreatedAt time.Time
+	ChangedAt time.Time
+	Visible   bool
+}
+
+// DeltaRepository reads learning item changes and tombstones.
+type DeltaRepository interface {
+	ListChanges(ctx context.Context, userID string, since Cursor, until time.Time, limit int) ([]*Change, *errors.AppError)
+}
+
+type deltaRepository struct {
+	db *client.PostgresClient
+}
+
+// NewDeltaRepository creates a new delta repository.
+func NewDeltaRepository(db *client.PostgresClient) DeltaRepository {
+	return &deltaRepository{db: db}
+}
+
+// ListChanges returns up to limit changes after since and before until, ordered by (changed_at, id).
+// Editing or deleting one of the user's notes on an item changes the item. Hard deletes come from
+// learning_item_tombstones; a zero since skips them and invisible items.
+func (r *deltaRepository) ListChanges(ctx context.Context, userID string, since Cursor, until time.Time, limit int) ([]*Change, *errors.AppError) {
+	query := `
+		SELECT id, feature_id, content, language, level, tags, details, notes, version, created_at, changed_at, visible
+		FROM (
+			SELECT l.id::text AS id, l.feature_id, l.content, l.language, l.level, l.tags,
+				CASE WHEN l.feature_id = $2 AND l.created_by <> $1 THEN l.published_details ELSE l.details END AS details,
+				COALESCE(n.notes, '[]'::jsonb) AS notes,
+				l.version, l.created_at, GREATEST(l.updated_at, n.changed_at) AS changed_at,
+				(COALESCE(l.is_active, false) AND (l.feature_id <> $2 OR l.published_details IS NOT NULL OR l.created_by = $1)
+					AND ` + client.AudienceVisibleSQL("l", "$1") + `) AS visible
+			FROM learning_items l
+			LEFT JOIN LATERAL (
+				SELECT MAX(n.updated_at) AS changed_at,
+					jsonb_agg(jsonb_build_object('id', n.id, 'body', n.body, 'created_at', n.created_at, 'updated_at', n.updated_at)
+						ORDER BY n.created_at, n.id) FILTER (WHERE n.deleted_at IS NULL) AS notes
+				FROM notes n
+				WHERE n.learning_id = l.id AND n.user_id::text = $1
+			) n ON true
+			WHERE l.feature_id IN ($2, $3)
+			UNION ALL
+			SELECT t.id::text, t.feature_id, '', '', NULL, NULL, NULL, NULL, 0, t.deleted_at, t.deleted_at, false
+			FROM learning_item_tombstones t
+			WHERE $7::boolean
+		) changes
+		WHERE (changed_at, id) > ($4::timestamptz, $5::text) AND changed_at < $6 AND (visible OR $7)
+		ORDER BY changed_at, id
+		LIMIT $8
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, client.FeatureDialog, client.FeatureVideo, since.ChangedAt, since.ID, until, !since.IsZero(), limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list changes", err)
+	}
+	defer rows.Close()
+
+	var changes []*Change
+	for rows.Next() {
+		var c Change
+		if err := rows.Scan(&c.ID, &c.FeatureID, &c.Content, &c.Language, &c.Level, &c.Tags, &c.Details, &c.Notes, &c.Version, &c.CreatedAt, &c.ChangedAt, &c.Visible); err != nil {
+			return nil, errors.InternalWrap("failed to scan change", err)
+		}
+		changes = append(changes, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list changes", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,110 @@
+package delta
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Page size limits
+const (
+	defaultSyncLimit = 200
+	maxSyncLimit     = 500
+)
+
+// Cursor is the position of the last change a client has seen: its change time and item ID.
+// The zero Cursor starts a full sync.
+type Cursor struct {
+	ChangedAt time.Time
+	ID        string
+}
+
+// IsZero reports whether the cursor starts a full sync.
+func (c Cursor) IsZero() bool {
+	return c.ChangedAt.IsZero()
+}
+
+// Encode returns the opaque cursor string handed to clients.
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.ChangedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// ParseCursor decodes a cursor returned by a previous sync.
+func ParseCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, errors.Validation("invalid cursor")
+	}
+	changedAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{ChangedAt: changedAt, ID: id}, nil
+}
+
+// -------------------------------------------------------------------------
+// Sync Request
+// -------------------------------------------------------------------------
+
+// SyncRequest is the HTTP request struct for delta sync
+type SyncRequest struct {
+	UserID string
+	Since  Cursor
+	Limit  int
+}
+
+// SyncInput is the input struct for service
+type SyncInput struct {
+	UserID string
+	Since  Cursor
+	Limit  int
+}
+
+// Parse reads the user, the optional since cursor and the optional limit param
+func (req *SyncRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	if since := r.URL.Query().Get("since"); since != "" {
+		cursor, err := ParseCursor(since)
+		if err != nil {
+			return errors.Validation("invalid since cursor")
+		}
+		req.Since = cursor
+	}
+
+	req.Limit = defaultSyncLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return errors.Validation("limit must be a positive integer")
+		}
+		req.Limit = min(limit, maxSyncLimit)
+	}
+
+	return nil
+}
+
+// ToInput convert SyncRequest to SyncInput
+func (req *SyncRequest) ToInput() SyncInput {
+	return SyncInput{
+		UserID: req.UserID,
+		Since:  req.Since,
+		Limit:  req.Limit,
+	}
+}
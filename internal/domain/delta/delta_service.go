@@ -0,0 +1,128 @@
+package delta
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// syncLag holds back the newest changes, so a transaction that commits late with an
+// earlier updated_at is not skipped by a cursor that already moved past it.
+const syncLag = 5 * time.Second
+
+// Item types
+const (
+	ITEM_TYPE_VIDEO  = "video"
+	ITEM_TYPE_DIALOG = "dialog"
+)
+
+// DeltaService serves incremental learning item changes to mobile clients.
+type DeltaService struct {
+	deltaRepo DeltaRepository
+}
+
+// SyncItem is a learning item created or updated since the cursor.
+type SyncItem struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Content   string          `json:"content"`
+	Language  string          `json:"language"`
+	Level     *string         `json:"level"`
+	Tags      json.RawMessage `json:"tags"`
+	Details   json.RawMessage `json:"details"`
+	Notes     json.RawMessage `json:"notes"`
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// DeletedItem is a learning item the client should drop.
+type DeletedItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncResponse is returned by delta sync.
+type SyncResponse struct {
+	Created []*SyncItem    `json:"created"`
+	Updated []*SyncItem    `json:"updated"`
+	Deleted []*DeletedItem `json:"deleted"`
+}
+
+// SyncMeta carries the cursor for the next sync.
+type SyncMeta struct {
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// NewDeltaService creates a new DeltaService.
+func NewDeltaService(deltaRepo DeltaRepository) *DeltaService {
+	return &DeltaService{deltaRepo: deltaRepo}
+}
+
+// Sync returns the changes after input.Since. Without a cursor every visible item is returned as created.
+func (s *DeltaService) Sync(ctx context.Context, input SyncInput) (*SyncResponse, *SyncMeta, *errors.AppError) {
+	until := time.Now().UTC().Add(-syncLag)
+
+	// 1. read one more change than the page holds to know if there are more
+	changes, err := s.deltaRepo.ListChanges(ctx, input.UserID, input.Since, until, input.Limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &SyncMeta{HasMore: len(changes) > input.Limit}
+	if meta.HasMore {
+		changes = changes[:input.Limit]
+	}
+
+	// 2. split changes into created, updated and deleted
+	result := &SyncResponse{
+		Created: []*SyncItem{},
+		Updated: []*SyncItem{},
+		Deleted: []*DeletedItem{},
+	}
+	for _, c := range changes {
+		itemType := ITEM_TYPE_VIDEO
+		if c.FeatureID == client.FeatureDialog {
+			itemType = ITEM_TYPE_DIALOG
+		}
+
+		if !c.Visible {
+			result.Deleted = append(result.Deleted, &DeletedItem{ID: c.ID, Type: itemType, DeletedAt: c.ChangedAt})
+			continue
+		}
+
+		item := &SyncItem{
+			ID:        c.ID,
+			Type:      itemType,
+			Content:   c.Content,
+			Language:  c.Language,
+			Level:     c.Level,
+			Tags:      c.Tags,
+			Details:   c.Details,
+			Notes:     c.Notes,
+			Version:   c.Version,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.ChangedAt,
+		}
+		if input.Since.IsZero() || c.CreatedAt.After(input.Since.ChangedAt) {
+			result.Created = append(result.Created, item)
+		} else {
+			result.Updated = append(result.Updated, item)
+		}
+	}
+
+	// 3. a full page resumes after its last change; otherwise everything before until was sent
+	next := Cursor{ChangedAt: until}
+	if meta.HasMore {
+		last := changes[len(changes)-1]
+		next = Cursor{ChangedAt: last.ChangedAt, ID: last.ID}
+	}
+	meta.NextCursor = next.Encode()
+
+	return result, meta, nil
+}
@@ -0,0 +1,30 @@
+package demo
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// DemoHandler handles public demo session HTTP endpoints.
+type DemoHandler struct {
+	service *DemoService
+}
+
+// NewDemoHandler creates a new demo handler.
+func NewDemoHandler(service *DemoService) *DemoHandler {
+	return &DemoHandler{
+		service: service,
+	}
+}
+
+// StartSession handles POST /api/v1/demo/session.
+func (h *DemoHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.StartSession(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, result)
+}
@@ -0,0 +1,166 @@
+package demo
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/domain/auth"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// DemoItem is a learning item a demo account created.
+type DemoItem struct {
+	ID string
+	// SharedImage is set when other dialogs reuse the item's image, so it must be kept
+	SharedImage bool
+}
+
+// DemoRepository stores ephemeral demo accounts and finds what they leave behind.
+type DemoRepository interface {
+	CountActive(ctx context.Context) (int, *errors.AppError)
+	CreateAccount(ctx context.Context, user *auth.User, expiresAt time.Time) *errors.AppError
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]string, *errors.AppError)
+	ReleaseItems(ctx context.Context, userIDs []string) ([]*DemoItem, *errors.AppError)
+	ListSparringAudio(ctx context.Context, userIDs []string) ([]string, *errors.AppError)
+	DeleteAccounts(ctx context.Context, userIDs []string) *errors.AppError
+}
+
+type demoRepository struct {
+	db *client.PostgresClient
+}
+
+// NewDemoRepository creates a new demo repository.
+func NewDemoRepository(db *client.PostgresClient) DemoRepository {
+	return &demoRepository{db: db}
+}
+
+func (r *demoRepository) CountActive(ctx context.Context) (int, *errors.AppError) {
+	var count int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE demo_expires_at > NOW()`).Scan(&count); err != nil {
+		return 0, errors.InternalWrap("failed to count demo accounts", err)
+	}
+	return count, nil
+}
+
+// CreateAccount inserts a demo user without a password, so it can only be used through its token.
+func (r *demoRepository) CreateAccount(ctx context.Context, user *auth.User, expiresAt time.Time) *errors.AppError {
+	query := `
+		INSERT INTO users (email, password_hash, display_name, settings, demo_expires_at)
+		VALUES ($1, '', $2, '{}', $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := r.db.Pool.QueryRow(ctx, query, user.Email, user.DisplayName, expiresAt).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return errors.InternalWrap("failed to create demo account", err)
+	}
+	return nil
+}
+
+func (r *demoRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]string, *errors.AppError) {
+	query := `
+		SELECT id::text FROM users
+		WHERE demo_expires_at IS NOT NULL AND demo_expires_at < $1
+		ORDER BY demo_expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list expired demo accounts", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.InternalWrap("failed to scan demo account", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ReleaseItems returns the items the users created and drops their images from the image registry
+// so they are not shared again. An image already reused by another dialog stays registered.
+func (r *demoRepository) ReleaseItems(ctx context.Context, userIDs []string) ([]*DemoItem, *errors.AppError) {
+	query := `
+		WITH items AS (
+			SELECT id FROM learning_items WHERE created_by = ANY($1)
+		), released AS (
+			DELETE FROM image_registry ir USING items i
+			WHERE ir.dialog_id = i.id AND ir.reuse_count = 0
+			RETURNING ir.dialog_id
+		)
+		SELECT i.id::text,
+			EXISTS (SELECT 1 FROM image_registry ir WHERE ir.dialog_id = i.id)
+				AND NOT EXISTS (SELECT 1 FROM released x WHERE x.dialog_id = i.id)
+		FROM items i
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to release demo items", err)
+	}
+	defer rows.Close()
+
+	items := make([]*DemoItem, 0)
+	for rows.Next() {
+		var item DemoItem
+		if err := rows.Scan(&item.ID, &item.SharedImage); err != nil {
+			return nil, errors.InternalWrap("failed to scan demo item", err)
+		}
+		items = append(items, &item)
+	}
+	return items, nil
+}
+
+// ListSparringAudio returns the partner audio URLs of the users' sparring sessions on any dialog.
+func (r *demoRepository) ListSparringAudio(ctx context.Context, userIDs []string) ([]string, *errors.AppError) {
+	query := `
+		SELECT m->>'audio_url'
+		FROM user_actions ua
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(ua.metadata->'messages', '[]'::jsonb)) m
+		WHERE ua.user_id = ANY($1::uuid[]) AND ua.action_type = 'submit_sparring'
+			AND COALESCE(m->>'audio_url', '') <> ''
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list demo sparring audio", err)
+	}
+	defer rows.Close()
+
+	urls := make([]string, 0)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, errors.InternalWrap("failed to scan demo sparring audio", err)
+		}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
+
+// DeleteAccounts deletes the demo users with the items they created; everything else they own
+// cascades with the user.
+func (r *demoRepository) DeleteAccounts(ctx context.Context, userIDs []string) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM learning_items WHERE created_by = ANY($1)`, userIDs); err != nil {
+		return errors.InternalWrap("failed to delete demo items", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = ANY($1::uuid[]) AND demo_expires_at IS NOT NULL`, userIDs); err != nil {
+		return errors.InternalWrap("failed to delete demo accounts", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit transaction", err)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package demo
+
+// -------------------------------------------------------------------------
+// Demo Cleanup (worker)
+// -------------------------------------------------------------------------
+
+// CleanupPayload is the payload for the expired demo account cleanup job
+type CleanupPayload struct{}
@@ -0,0 +1,129 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/domain/auth"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+const (
+	// cleanupBatchSize is how many expired accounts are deleted at a time
+	cleanupBatchSize = 100
+	// cleanupGrace lets background jobs started just before expiry finish before their content is deleted
+	cleanupGrace = 15 * time.Minute
+)
+
+// DemoOptions configures demo sessions.
+type DemoOptions struct {
+	SessionTTL time.Duration  // lifetime of the account and its token
+	MaxActive  int            // demo accounts alive at once (0 is unlimited)
+	Quotas     map[string]int // per session, by quota kind (see client.DemoQuota)
+}
+
+// DemoSessionResponse is an issued demo session.
+type DemoSessionResponse struct {
+	Token     string         `json:"token"`
+	User      *auth.User     `json:"user"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Quotas    map[string]int `json:"quotas"`
+}
+
+// DemoService issues ephemeral demo accounts and deletes them with their content once they expire.
+type DemoService struct {
+	demoRepo DemoRepository
+	fileRepo FileRepository
+	authRepo auth.AuthRepository
+	opts     DemoOptions
+}
+
+// NewDemoService creates a new demo service.
+func NewDemoService(demoRepo DemoRepository, fileRepo FileRepository, authRepo auth.AuthRepository, opts DemoOptions) *DemoService {
+	return &DemoService{
+		demoRepo: demoRepo,
+		fileRepo: fileRepo,
+		authRepo: authRepo,
+		opts:     opts,
+	}
+}
+
+// StartSession creates an anonymous demo account and returns a token that expires with it.
+func (s *DemoService) StartSession(ctx context.Context) (*DemoSessionResponse, *errors.AppError) {
+	if s.opts.MaxActive > 0 {
+		active, err := s.demoRepo.CountActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if active >= s.opts.MaxActive {
+			return nil, errors.RateLimit("too many demo sessions, try again later")
+		}
+	}
+
+	expiresAt := time.Now().Add(s.opts.SessionTTL).UTC()
+	user := &auth.User{
+		Email:       fmt.Sprintf("demo-%s@demo.invalid", uuid.NewString()),
+		DisplayName: "Demo learner",
+	}
+	if err := s.demoRepo.CreateAccount(ctx, user, expiresAt); err != nil {
+		return nil, err
+	}
+
+	token, err := s.authRepo.GenerateDemoToken(user, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DemoSessionResponse{
+		Token:     token,
+		User:      user,
+		ExpiresAt: expiresAt,
+		Quotas:    s.opts.Quotas,
+	}, nil
+}
+
+// Worker: CleanupExpired
+// Deletes expired demo accounts with the dialogs they generated, the R2 media of those dialogs and
+// their sparring audio. Accounts whose media cannot be deleted are kept for the next run.
+func (s *DemoService) CleanupExpired(ctx context.Context, payload CleanupPayload) *errors.AppError {
+	cutoff := time.Now().Add(-cleanupGrace)
+
+	for {
+		userIDs, err := s.demoRepo.ListExpired(ctx, cutoff, cleanupBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		items, err := s.demoRepo.ReleaseItems(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := s.fileRepo.DeleteDialogMedia(ctx, item.ID, item.SharedImage); err != nil {
+				return err
+			}
+		}
+
+		urls, err := s.demoRepo.ListSparringAudio(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+		for _, url := range urls {
+			if err := s.fileRepo.DeleteURL(ctx, url); err != nil {
+				return err
+			}
+		}
+
+		if err := s.demoRepo.DeleteAccounts(ctx, userIDs); err != nil {
+			return err
+		}
+		if len(userIDs) < cleanupBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_CLEANUP_DEMO = "worker_cleanup_demo_accounts"
+)
+
+// RegisterDemoWorkers register demo workers to queue
+func RegisterDemoWorkers(queue *client.QueueClient, service *DemoService) {
+
+	// Job Cleanup Expired Demo Accounts
+	queue.RegisterWorker(WORKER_CLEANUP_DEMO, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(CleanupPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_CLEANUP_DEMO)
+		}
+		if err := service.CleanupExpired(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
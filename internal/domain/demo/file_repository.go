@@ -0,0 +1,59 @@
+package demo
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// imageExtensions are the dialog media stored as images.
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".webp": true, ".avif": true}
+
+// FileRepository deletes the R2 objects demo accounts leave behind.
+type FileRepository interface {
+	DeleteDialogMedia(ctx context.Context, dialogID string, keepImages bool) *errors.AppError
+	DeleteURL(ctx context.Context, url string) *errors.AppError
+}
+
+type fileRepository struct {
+	cloudflare *client.CloudflareClient
+}
+
+// NewFileRepository creates a new demo file repository.
+func NewFileRepository(cloudflare *client.CloudflareClient) FileRepository {
+	return &fileRepository{cloudflare: cloudflare}
+}
+
+// DeleteDialogMedia deletes every object stored under the dialog (images, voice and sparring audio).
+func (r *fileRepository) DeleteDialogMedia(ctx context.Context, dialogID string, keepImages bool) *errors.AppError {
+	err := r.cloudflare.ListR2Objects(ctx, "dialogs/"+dialogID+"/", func(objects []client.R2Object) error {
+		for _, obj := range objects {
+			if keepImages && imageExtensions[strings.ToLower(path.Ext(obj.Key))] {
+				continue
+			}
+			if err := r.cloudflare.DeleteR2Object(ctx, obj.Key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.InternalWrap("failed to delete demo dialog media", err)
+	}
+	return nil
+}
+
+// DeleteURL deletes the object behind a public URL; URLs outside the bucket are ignored.
+func (r *fileRepository) DeleteURL(ctx context.Context, url string) *errors.AppError {
+	key, ok := r.cloudflare.R2KeyFromURL(url)
+	if !ok {
+		return nil
+	}
+	if err := r.cloudflare.DeleteR2Object(ctx, key); err != nil {
+		return errors.InternalWrap("failed to delete demo media", err)
+	}
+	return nil
+}
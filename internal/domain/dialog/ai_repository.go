@@ -40,12 +40,16 @@ Ensure the output is properly formatted and fully parseable.
 
 - Keep the speech script concise, coherent, and appropriate for the specified level.  
 
+- For each **User** line, pick 1-3 key words from its text as **missing_words** (in the order they appear, copied exactly) for a fill-in-the-blank exercise, and write 1-3 progressive **hints** that help recall them without giving them away. AI lines have no missing_words or hints.
+
 - Ensure learning objectives are practical, actionable, and easy to follow.  
 
 - Make sure the **chat_mode** context and objectives:
   - Directly and logically continue from the *speech_mode* conversation.
   - Feel like a natural next step (not a separate or unrelated scenario).
 
+- Set **audience** to "adult" when the scenario involves alcohol, gambling, betting, drugs, smoking or dating; otherwise "general".
+
 - Ensure all fields in the output schema are fully populated and consistent with each other:
   - No contradictions between description, tags, and scenarios.
   - Maintain a single coherent context across the entire output.
@@ -55,13 +59,70 @@ Ensure the output is properly formatted and fully parseable.
   "description": "string",
   "level": "string",
   "tags": ["string"],
+  "audience": "general or adult",
   "image_prompt": "string",
   "speech_mode": {
     "situation": "string",
     "script": [
       {
         "speaker": "User or AI",
-        "text": "string"
+        "text": "string",
+        "missing_words": ["string"],
+        "hints": ["string"]
+      }
+    ]
+  },
+  "chat_mode": {
+    "situation": "string",
+    "objectives": {
+      "requirements": ["string"],
+      "persuasion": ["string"],
+      "constraints": ["string"]
+    }
+  }
+}`
+
+// regenerateTurnPrompt rewrites a single line of an existing speech script.
+const regenerateTurnPrompt = `You are an expert language-learning dialogue editor.
+
+Rewrite ONLY the marked line of the dialogue so it reads naturally and fits the surrounding lines.
+
+**Requirements:**
+- Keep the same speaker, language and proficiency level.
+- Keep the meaning needed for the previous and next lines to still make sense.
+- Follow the editor instruction when one is given.
+- Write a single turn; do not add speaker labels or extra lines.
+- If the marked line is a **User** line, pick 1-3 key words from the new text as **missing_words** (in the order they appear, copied exactly) for a fill-in-the-blank exercise, and write 1-3 progressive **hints** that help recall them without giving them away. For an AI line leave both empty.
+
+Return valid JSON only, with no markdown or text around it:
+{
+  "text": "string",
+  "missing_words": ["string"],
+  "hints": ["string"]
+}`
+
+// fixDialogPrompt corrects mistakes an editor pointed out in a generated dialog.
+const fixDialogPrompt = `You are an expert language-learning dialogue editor.
+
+An editor found a mistake in a generated dialogue and described it in a note. Correct the dialogue.
+
+**Requirements:**
+- Fix what the note describes, and only that; leave every other field exactly as it is.
+- Keep the same number of script lines, the same speakers and the same order.
+- Keep the language and proficiency level.
+- Keep missing_words copied exactly from the text of their line.
+
+Return valid JSON only, with no markdown or text around it, in the same schema as the input:
+{
+  "description": "string",
+  "speech_mode": {
+    "situation": "string",
+    "script": [
+      {
+        "speaker": "User or AI",
+        "text": "string",
+        "missing_words": ["string"],
+        "hints": ["string"]
       }
     ]
   },
@@ -75,6 +136,44 @@ Ensure the output is properly formatted and fully parseable.
   }
 }`
 
+// fixDialogContent is the part of the details a fix may change.
+type fixDialogContent struct {
+	Description string     `json:"description"`
+	SpeechMode  SpeechMode `json:"speech_mode"`
+	ChatMode    ChatMode   `json:"chat_mode"`
+}
+
+// reviewDialogPrompt scores a freshly generated dialog before it reaches learners.
+const reviewDialogPrompt = `You are a strict reviewer of language-learning dialogues.
+
+Score the dialogue from 0 to 100 on:
+- **naturalness**: sounds like real people talking in this situation, coherent from line to line.
+- **correctness**: grammar, spelling and word choice are correct for the target language, and the vocabulary fits the proficiency level.
+
+List the concrete problems you found in "issues" (at most 5, empty when there are none).
+
+Return valid JSON only, with no markdown or text around it:
+{
+  "naturalness": 0,
+  "correctness": 0,
+  "issues": ["string"]
+}`
+
+// reviewDialogSchema is the Vertex AI responseSchema of the reviewDialogPrompt reply.
+var reviewDialogSchema = map[string]interface{}{
+	"type": "OBJECT",
+	"properties": map[string]interface{}{
+		"naturalness": map[string]interface{}{"type": "INTEGER", "minimum": 0, "maximum": 100},
+		"correctness": map[string]interface{}{"type": "INTEGER", "minimum": 0, "maximum": 100},
+		"issues": map[string]interface{}{
+			"type":     "ARRAY",
+			"items":    map[string]interface{}{"type": "STRING"},
+			"maxItems": 5,
+		},
+	},
+	"required": []string{"naturalness", "correctness", "issues"},
+}
+
 // submitChatPrompt builds the system prompt for the chat reply.
 const submitChatPrompt = `You are an AI language learning conversational partner. Your role is to roleplay with the user in a specific situation to help them practice their language skills.
 
@@ -117,6 +216,7 @@ type dialogueGuideResponse struct {
 	Description string     `json:"description"`
 	Level       string     `json:"level"`
 	Tags        []string   `json:"tags"`
+	Audience    string     `json:"audience"`
 	ImagePrompt string     `json:"image_prompt"`
 	SpeechMode  SpeechMode `json:"speech_mode"`
 	ChatMode    ChatMode   `json:"chat_mode"`
@@ -134,6 +234,9 @@ type SpeechScript struct {
 	Text       string      `json:"text"`
 	AudioURL   *string     `json:"audio_url,omitempty"`
 	Evaluation *Evaluation `json:"evaluation,omitempty"`
+	// Fill-in-the-blank exercise on user turns (see CheckTurnBlanks)
+	MissingWords []string `json:"missing_words,omitempty"`
+	Hints        []string `json:"hints,omitempty"`
 }
 
 // Evaluation & EvaluationWord
@@ -172,40 +275,74 @@ type ChatObjective struct {
 type AIRepository interface {
 	GenerateDialog(ctx context.Context, payload GenerateDialogPayload) (*DialogDetails, *errors.AppError)
 	ReplyUserMessage(ctx context.Context, chatObjective ChatObjective, history []ChatMessage, situation, userMessage string) (*ReplyMessageResult, *errors.AppError)
+	RegenerateScriptTurn(ctx context.Context, details *DialogDetails, index int, instruction string) (*SpeechScript, *errors.AppError)
+	FixDialog(ctx context.Context, details *DialogDetails, note string) (*DialogDetails, *errors.AppError)
+	ReviewDialog(ctx context.Context, details *DialogDetails) (*QualityReview, *errors.AppError)
 }
 
 type aiRepository struct {
-	chatGPT *client.AzureChatGPTClient
+	chatGPT    client.ChatClient
+	structured client.StructuredClient // schema-constrained JSON (nil = the chat chain only)
 }
 
 // NewAIRepository creates a new dialog AI repository.
-func NewAIRepository(chatGPT *client.AzureChatGPTClient) AIRepository {
-	return &aiRepository{chatGPT: chatGPT}
+func NewAIRepository(chatGPT client.ChatClient, structured client.StructuredClient) AIRepository {
+	return &aiRepository{chatGPT: chatGPT, structured: structured}
 }
 
 // GenerateDialog creates structured dialog content from the configured LLM.
 func (r *aiRepository) GenerateDialog(ctx context.Context, payload GenerateDialogPayload) (*DialogDetails, *errors.AppError) {
 	if r.chatGPT == nil {
-		return nil, errors.Internal("dialog AI client not configured")
+		return nil, errors.Unsupported("dialog AI client not configured")
 	}
 
+	// Malformed scripts are repaired when possible, otherwise the next provider is tried
+	systemPrompt := dialogGenerationPrompt
+	if payload.Minor {
+		systemPrompt += client.MinorContentRules
+	}
+	systemPrompt += client.ModerationGuidance(payload.ReportReasons)
+	systemPrompt += client.UntrustedInputNotice
 	userMessage := buildDialogUserPrompt(payload)
-	raw, err := r.chatGPT.ChatCompletion(ctx, dialogGenerationPrompt, userMessage)
+	var parsed dialogueGuideResponse
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureDialogGeneration), r.chatGPT, systemPrompt, userMessage,
+		func(raw string) *errors.AppError {
+			clean := strings.TrimSpace(raw)
+			clean = strings.TrimPrefix(clean, "```json")
+			clean = strings.TrimPrefix(clean, "```")
+			clean = strings.TrimSuffix(clean, "```")
+			clean = strings.TrimSpace(clean)
+
+			var result dialogueGuideResponse
+			if err := json.Unmarshal([]byte(clean), &result); err != nil {
+				return errors.InternalWrap("failed to parse generated dialog", err)
+			}
+
+			script, err := NormalizeSpeechScript(result.SpeechMode.Script)
+			if err != nil {
+				return err
+			}
+			result.SpeechMode.Script = script
+
+			if result.leaksInstructions(systemPrompt) {
+				return errors.Internal("generated dialog leaks prompt instructions")
+			}
+
+			result.Audience = strings.ToLower(strings.TrimSpace(result.Audience))
+			if !client.IsAudience(result.Audience) {
+				result.Audience = client.AudienceGeneral
+			}
+			if payload.Minor && result.Audience != client.AudienceGeneral {
+				return errors.Internal("generated dialog is not suitable for minors")
+			}
+
+			parsed = result
+			return nil
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	clean := strings.TrimSpace(raw)
-	clean = strings.TrimPrefix(clean, "```json")
-	clean = strings.TrimPrefix(clean, "```")
-	clean = strings.TrimSuffix(clean, "```")
-	clean = strings.TrimSpace(clean)
-
-	var parsed dialogueGuideResponse
-	if err := json.Unmarshal([]byte(clean), &parsed); err != nil {
-		return nil, errors.InternalWrap("failed to parse generated dialog", err)
-	}
-
 	if parsed.Description == "" {
 		parsed.Description = payload.Description
 	}
@@ -216,25 +353,135 @@ func (r *aiRepository) GenerateDialog(ctx context.Context, payload GenerateDialo
 		parsed.Tags = payload.Tags
 	}
 
-	return &DialogDetails{
+	details := &DialogDetails{
 		Topic:       payload.Topic,
 		Description: parsed.Description,
 		Language:    payload.Language,
 		Level:       parsed.Level,
 		Tags:        parsed.Tags,
+		Audience:    parsed.Audience,
 		ImagePrompt: parsed.ImagePrompt,
 		SpeechMode:  parsed.SpeechMode,
 		ChatMode:    parsed.ChatMode,
-	}, nil
+	}
+	details.countTurns()
+
+	return details, nil
+}
+
+// Canonical speaker labels of a speech script
+const (
+	SpeakerAI   = "AI"
+	SpeakerUser = "User"
+)
+
+// speakerAliases maps the labels models use to the canonical speakers
+var speakerAliases = map[string]string{
+	"ai":        SpeakerAI,
+	"assistant": SpeakerAI,
+	"bot":       SpeakerAI,
+	"model":     SpeakerAI,
+	"partner":   SpeakerAI,
+	"user":      SpeakerUser,
+	"learner":   SpeakerUser,
+	"student":   SpeakerUser,
+	"you":       SpeakerUser,
+}
+
+// NormalizeSpeechScript enforces the canonical script schema before it is saved:
+// speakers become "AI" / "User", text is trimmed, empty turns are dropped and
+// consecutive turns of the same speaker are merged. Scripts with unknown speakers,
+// fewer than two turns or no user turn are rejected.
+func NormalizeSpeechScript(script []SpeechScript) ([]SpeechScript, *errors.AppError) {
+	normalized := make([]SpeechScript, 0, len(script))
+	for i, turn := range script {
+		label := strings.ToLower(strings.Trim(strings.TrimSpace(turn.Speaker), ":"))
+		speaker, ok := speakerAliases[label]
+		if !ok {
+			return nil, errors.Validation(fmt.Sprintf("speech script turn %d has unknown speaker %q", i, turn.Speaker))
+		}
+
+		text := strings.TrimSpace(turn.Text)
+		if text == "" {
+			continue
+		}
+
+		if n := len(normalized); n > 0 && normalized[n-1].Speaker == speaker {
+			normalized[n-1].Text += " " + text
+			normalized[n-1].MissingWords = append(normalized[n-1].MissingWords, turn.MissingWords...)
+			normalized[n-1].Hints = append(normalized[n-1].Hints, turn.Hints...)
+			normalized[n-1].MissingWords, normalized[n-1].Hints = normalizeBlanks(normalized[n-1])
+			continue
+		}
+
+		turn.Speaker = speaker
+		turn.Text = text
+		turn.MissingWords, turn.Hints = normalizeBlanks(turn)
+		normalized = append(normalized, turn)
+	}
+
+	if len(normalized) < 2 {
+		return nil, errors.Validation("speech script needs at least two turns")
+	}
+	hasUser := false
+	for _, turn := range normalized {
+		if turn.Speaker == SpeakerUser {
+			hasUser = true
+			break
+		}
+	}
+	if !hasUser {
+		return nil, errors.Validation("speech script has no user turn")
+	}
+
+	return normalized, nil
+}
+
+// normalizeBlanks keeps the missing words that actually occur in a user turn's text,
+// and the hints only when there is something to fill in.
+func normalizeBlanks(turn SpeechScript) ([]string, []string) {
+	if turn.Speaker != SpeakerUser {
+		return nil, nil
+	}
+
+	text := strings.ToLower(turn.Text)
+	var words []string
+	for _, word := range turn.MissingWords {
+		word = strings.TrimSpace(word)
+		if word != "" && strings.Contains(text, strings.ToLower(word)) {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	var hints []string
+	for _, hint := range turn.Hints {
+		if hint = strings.TrimSpace(hint); hint != "" {
+			hints = append(hints, hint)
+		}
+	}
+	return words, hints
+}
+
+// leaksInstructions reports whether any learner-facing text of the guide echoes the system prompt.
+func (g *dialogueGuideResponse) leaksInstructions(systemPrompt string) bool {
+	texts := []string{g.Description, g.SpeechMode.Situation, g.ChatMode.Situation}
+	for _, turn := range g.SpeechMode.Script {
+		texts = append(texts, turn.Text)
+	}
+	return client.LeaksInstructions(strings.Join(texts, "\n"), systemPrompt)
 }
 
+// buildDialogUserPrompt quotes learner-supplied fields in <user_input> blocks.
 func buildDialogUserPrompt(payload GenerateDialogPayload) string {
 	var b strings.Builder
 
 	b.WriteString("Topic: ")
-	b.WriteString(payload.Topic)
+	b.WriteString(client.QuoteUserInput("topic", payload.Topic, true))
 	b.WriteString("\nDescription: ")
-	b.WriteString(payload.Description)
+	b.WriteString(client.QuoteUserInput("description", payload.Description, false))
 	b.WriteString("\nLanguage: ")
 	b.WriteString(payload.Language)
 	b.WriteString("\nLevel: ")
@@ -244,7 +491,235 @@ func buildDialogUserPrompt(payload GenerateDialogPayload) string {
 	if len(payload.Tags) == 0 {
 		b.WriteString("generate relevant tags")
 	} else {
-		b.WriteString(strings.Join(payload.Tags, ", "))
+		b.WriteString(client.QuoteUserInput("tags", strings.Join(payload.Tags, ", "), true))
+	}
+
+	if payload.ParentID != "" {
+		b.WriteString("\n\nThis is an adaptation of an existing dialogue for the level above. ")
+		b.WriteString("Keep the same situation and roles, and only adjust vocabulary, grammar and script length to the level.")
+		b.WriteString("\nSituation: ")
+		b.WriteString(client.QuoteUserInput("situation", payload.Situation, false))
+	}
+
+	return b.String()
+}
+
+// RegenerateScriptTurn asks the LLM to rewrite the script turn at index, using the rest of the script as context.
+// A rewritten user turn comes with new missing words and hints, checked against its text like at generation.
+func (r *aiRepository) RegenerateScriptTurn(ctx context.Context, details *DialogDetails, index int, instruction string) (*SpeechScript, *errors.AppError) {
+	if r.chatGPT == nil {
+		return nil, errors.Unsupported("dialog AI client not configured")
+	}
+
+	systemPrompt := regenerateTurnPrompt + client.UntrustedInputNotice
+	userMessage := buildRegenerateTurnUserPrompt(details, index, instruction)
+	original := details.SpeechMode.Script[index]
+	current := strings.TrimSpace(original.Text)
+
+	var turn SpeechScript
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureDialogGeneration), r.chatGPT, systemPrompt, userMessage,
+		func(raw string) *errors.AppError {
+			clean := strings.TrimSpace(raw)
+			clean = strings.TrimPrefix(clean, "```json")
+			clean = strings.TrimPrefix(clean, "```")
+			clean = strings.TrimSuffix(clean, "```")
+			clean = strings.TrimSpace(clean)
+
+			var result struct {
+				Text         string   `json:"text"`
+				MissingWords []string `json:"missing_words"`
+				Hints        []string `json:"hints"`
+			}
+			if err := json.Unmarshal([]byte(clean), &result); err != nil {
+				return errors.InternalWrap("failed to parse regenerated turn", err)
+			}
+
+			result.Text = strings.TrimSpace(result.Text)
+			if result.Text == "" || strings.Contains(result.Text, "\n") {
+				return errors.Internal("regenerated turn must be a single non-empty line")
+			}
+			if result.Text == current {
+				return errors.Internal("regenerated turn is unchanged")
+			}
+			if client.LeaksInstructions(result.Text, systemPrompt) {
+				return errors.Internal("regenerated turn leaks prompt instructions")
+			}
+
+			candidate := SpeechScript{Speaker: original.Speaker, Text: result.Text, MissingWords: result.MissingWords, Hints: result.Hints}
+			candidate.MissingWords, candidate.Hints = normalizeBlanks(candidate)
+			if candidate.Speaker == SpeakerUser && len(candidate.MissingWords) == 0 {
+				return errors.Internal("regenerated user turn has no missing words from its text")
+			}
+
+			turn = candidate
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &turn, nil
+}
+
+// FixDialog asks the LLM to correct the dialog as the editor's note describes. The returned
+// details are a copy of details with the corrected text; the script keeps its shape.
+func (r *aiRepository) FixDialog(ctx context.Context, details *DialogDetails, note string) (*DialogDetails, *errors.AppError) {
+	if r.chatGPT == nil {
+		return nil, errors.Unsupported("dialog AI client not configured")
+	}
+
+	current := fixDialogContent{
+		Description: details.Description,
+		SpeechMode:  SpeechMode{Situation: details.SpeechMode.Situation},
+		ChatMode:    details.ChatMode,
+	}
+	for _, turn := range details.SpeechMode.Script {
+		current.SpeechMode.Script = append(current.SpeechMode.Script, SpeechScript{
+			Speaker:      turn.Speaker,
+			Text:         turn.Text,
+			MissingWords: turn.MissingWords,
+			Hints:        turn.Hints,
+		})
+	}
+	currentJSON, _ := json.MarshalIndent(current, "", "  ")
+
+	systemPrompt := fixDialogPrompt + client.UntrustedInputNotice
+	var b strings.Builder
+	fmt.Fprintf(&b, "Language: %s\n", details.Language)
+	fmt.Fprintf(&b, "Level: %s\n", details.Level)
+	fmt.Fprintf(&b, "Editor note: %s\n\n", client.QuoteUserInput("note", note, false))
+	b.WriteString("Dialogue:\n")
+	b.Write(currentJSON)
+
+	var fixed DialogDetails
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureDialogGeneration), r.chatGPT, systemPrompt, b.String(),
+		func(raw string) *errors.AppError {
+			clean := strings.TrimSpace(raw)
+			clean = strings.TrimPrefix(clean, "```json")
+			clean = strings.TrimPrefix(clean, "```")
+			clean = strings.TrimSuffix(clean, "```")
+			clean = strings.TrimSpace(clean)
+
+			var result fixDialogContent
+			if err := json.Unmarshal([]byte(clean), &result); err != nil {
+				return errors.InternalWrap("failed to parse fixed dialog", err)
+			}
+
+			script := result.SpeechMode.Script
+			if len(script) != len(details.SpeechMode.Script) {
+				return errors.Internal("fixed dialog changed the number of script lines")
+			}
+			for i := range script {
+				speaker, ok := speakerAliases[strings.ToLower(strings.TrimSpace(script[i].Speaker))]
+				if !ok || speaker != details.SpeechMode.Script[i].Speaker {
+					return errors.Internal("fixed dialog changed the script speakers")
+				}
+				script[i].Text = strings.TrimSpace(script[i].Text)
+				if script[i].Text == "" {
+					return errors.Internal("fixed dialog has an empty script line")
+				}
+			}
+			texts := []string{result.Description, result.SpeechMode.Situation, result.ChatMode.Situation}
+			for _, turn := range script {
+				texts = append(texts, turn.Text)
+			}
+			if client.LeaksInstructions(strings.Join(texts, "\n"), systemPrompt) {
+				return errors.Internal("fixed dialog leaks prompt instructions")
+			}
+
+			fixed = *details
+			fixed.Description = strings.TrimSpace(result.Description)
+			fixed.SpeechMode.Situation = strings.TrimSpace(result.SpeechMode.Situation)
+			fixed.SpeechMode.Script = make([]SpeechScript, len(script))
+			for i, turn := range details.SpeechMode.Script {
+				turn.Text = script[i].Text
+				turn.MissingWords = script[i].MissingWords
+				turn.Hints = script[i].Hints
+				fixed.SpeechMode.Script[i] = turn
+			}
+			fixed.ChatMode = result.ChatMode
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &fixed, nil
+}
+
+// ReviewDialog runs the critic pass over generated details. The score is the lower of the
+// naturalness and correctness scores. The review is asked for as schema-constrained JSON when a
+// structured client is configured and the request pinned no chat provider; when that fails, the
+// chat chain reviews instead.
+func (r *aiRepository) ReviewDialog(ctx context.Context, details *DialogDetails) (*QualityReview, *errors.AppError) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Language: %s\n", details.Language)
+	fmt.Fprintf(&b, "Level: %s\n", details.Level)
+	fmt.Fprintf(&b, "Situation: %s\n", details.SpeechMode.Situation)
+	b.WriteString("\nDialogue:\n")
+	for _, turn := range details.SpeechMode.Script {
+		fmt.Fprintf(&b, "%s: %s\n", turn.Speaker, turn.Text)
+	}
+
+	var review QualityReview
+	parse := func(raw string) *errors.AppError {
+		clean := strings.TrimSpace(raw)
+		clean = strings.TrimPrefix(clean, "```json")
+		clean = strings.TrimPrefix(clean, "```")
+		clean = strings.TrimSuffix(clean, "```")
+		clean = strings.TrimSpace(clean)
+
+		var result QualityReview
+		if err := json.Unmarshal([]byte(clean), &result); err != nil {
+			return errors.InternalWrap("failed to parse dialog review", err)
+		}
+		if result.Naturalness < 0 || result.Naturalness > 100 || result.Correctness < 0 || result.Correctness > 100 {
+			return errors.Internal("dialog review scores must be between 0 and 100")
+		}
+
+		result.Score = min(result.Naturalness, result.Correctness)
+		if len(result.Issues) > 5 {
+			result.Issues = result.Issues[:5]
+		}
+		review = result
+		return nil
+	}
+
+	if r.structured != nil && client.ChatProviderFromContext(ctx) == "" {
+		raw, err := r.structured.GenerateStructured(ctx, reviewDialogPrompt+"\n\n"+b.String(), reviewDialogSchema)
+		if err == nil && parse(string(raw)) == nil {
+			return &review, nil
+		}
+	}
+
+	if r.chatGPT == nil {
+		return nil, errors.Unsupported("dialog AI client not configured")
+	}
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureQualityReview), r.chatGPT, reviewDialogPrompt, b.String(), parse)
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func buildRegenerateTurnUserPrompt(details *DialogDetails, index int, instruction string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Topic: %s\n", client.QuoteUserInput("topic", details.Topic, true))
+	fmt.Fprintf(&b, "Language: %s\n", details.Language)
+	fmt.Fprintf(&b, "Level: %s\n", details.Level)
+	fmt.Fprintf(&b, "Situation: %s\n", details.SpeechMode.Situation)
+	if instruction != "" {
+		fmt.Fprintf(&b, "Editor instruction: %s\n", client.QuoteUserInput("instruction", instruction, true))
+	}
+
+	b.WriteString("\nDialogue:\n")
+	for i, turn := range details.SpeechMode.Script {
+		marker := ""
+		if i == index {
+			marker = " <-- rewrite this line"
+		}
+		fmt.Fprintf(&b, "%s: %s%s\n", turn.Speaker, turn.Text, marker)
 	}
 
 	return b.String()
@@ -253,7 +728,7 @@ func buildDialogUserPrompt(payload GenerateDialogPayload) string {
 // ReplyUserMessage sends a multi-turn chat request and parses the structured AI response.
 func (r *aiRepository) ReplyUserMessage(ctx context.Context, chatObjective ChatObjective, history []ChatMessage, situation, userMessage string) (*ReplyMessageResult, *errors.AppError) {
 	if r.chatGPT == nil {
-		return nil, errors.Internal("dialog AI client not configured")
+		return nil, errors.Unsupported("dialog AI client not configured")
 	}
 
 	// Build system prompt
@@ -265,9 +740,9 @@ func (r *aiRepository) ReplyUserMessage(ctx context.Context, chatObjective ChatO
 	for _, msg := range history {
 		messages = append(messages, client.ChatMessage{Role: msg.Role, Content: msg.Content})
 	}
-	messages = append(messages, client.ChatMessage{Role: "user", Content: userMessage})
+	messages = append(messages, client.ChatMessage{Role: "user", Content: client.SanitizePromptInput(userMessage, false)})
 
-	raw, err := r.chatGPT.ChatCompletionMultiTurn(ctx, messages)
+	raw, err := r.chatGPT.ChatCompletionMultiTurn(client.WithChatFeature(ctx, client.ChatFeatureChatReply), messages)
 	if err != nil {
 		return nil, err
 	}
@@ -283,6 +758,10 @@ func (r *aiRepository) ReplyUserMessage(ctx context.Context, chatObjective ChatO
 	if parseErr := json.Unmarshal([]byte(clean), &result); parseErr != nil {
 		return nil, errors.InternalWrap("failed to parse chat reply", parseErr)
 	}
+	// Compare with the template only; the reply may rightly echo the situation
+	if client.LeaksInstructions(result.ReplyMessage+"\n"+result.Suggestion, submitChatPrompt) {
+		return nil, errors.Internal("chat reply leaks prompt instructions")
+	}
 
 	return &result, nil
 }
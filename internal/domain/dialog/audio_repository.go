@@ -3,6 +3,7 @@ package dialog
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
@@ -12,27 +13,29 @@ import (
 type AudioRepository interface {
 	Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError)
 	EvaluateSpeech(ctx context.Context, tempWav *os.File, referenceText string, language string) (*client.AzureEvaluationSpeech, *errors.AppError)
+	Transcribe(ctx context.Context, wavPath, language string) (string, *errors.AppError)
 }
 
 type audioRepository struct {
-	speechClient *client.AzureSpeechClient
+	speechClient  client.SpeechClient
+	whisperClient client.TranscriptionClient
 }
 
 // NewAudioRepository creates a new dialog audio repository.
-func NewAudioRepository(speechClient *client.AzureSpeechClient) AudioRepository {
-	return &audioRepository{speechClient: speechClient}
+func NewAudioRepository(speechClient client.SpeechClient, whisperClient client.TranscriptionClient) AudioRepository {
+	return &audioRepository{speechClient: speechClient, whisperClient: whisperClient}
 }
 
 func (r *audioRepository) Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError) {
 	if r.speechClient == nil {
-		return nil, errors.Internal("dialog speech client not configured")
+		return nil, errors.Unsupported("dialog speech client not configured")
 	}
 	return r.speechClient.Synthesize(ctx, text, voice)
 }
 
 func (r *audioRepository) EvaluateSpeech(ctx context.Context, tempWav *os.File, referenceText string, language string) (*client.AzureEvaluationSpeech, *errors.AppError) {
 	if r.speechClient == nil {
-		return nil, errors.Internal("dialog speech client not configured")
+		return nil, errors.Unsupported("dialog speech client not configured")
 	}
 
 	audioData, err := os.ReadFile(tempWav.Name())
@@ -42,3 +45,17 @@ func (r *audioRepository) EvaluateSpeech(ctx context.Context, tempWav *os.File,
 
 	return r.speechClient.EvaluatePronunciation(ctx, audioData, referenceText, language)
 }
+
+// Transcribe converts a learner recording to text (language is an ISO code such as "en").
+func (r *audioRepository) Transcribe(ctx context.Context, wavPath, language string) (string, *errors.AppError) {
+	if r.whisperClient == nil {
+		return "", errors.Unsupported("dialog transcription client not configured")
+	}
+
+	result, err := r.whisperClient.TranscribeFile(ctx, wavPath, language)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(result.Text), nil
+}
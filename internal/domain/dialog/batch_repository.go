@@ -3,19 +3,20 @@ package dialog
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"log/slog"
-	"strconv"
-	"time"
 
 	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
 	"github.com/windfall/uwu_service/pkg/response"
 )
 
-// Constants
-const processingBatchTTL = 3 * time.Hour
-const completedBatchTTL = 10 * time.Minute
+// Batch types (keys of the per-type retention config):
+const (
+	BATCH_TYPE_GENERATE_DIALOG = "generate_dialog"
+	BATCH_TYPE_REGENERATE_TURN = "regenerate_turn"
+)
+
+// BATCH_REFERENCE_DIALOG marks batches that work on a dialog (batch ID = dialog ID for generation).
+const BATCH_REFERENCE_DIALOG = "dialog"
 
 // Batch processes:
 const (
@@ -27,15 +28,16 @@ const (
 	PROCESS_GENERATE_AUDIO_SCRIPTS = "generate_audio_scripts"
 	PROCESS_UPLOAD_AUDIO_SCRIPTS   = "upload_audio_scripts"
 	PROCESS_SAVE_DIALOG            = "save_dialog"
+	PROCESS_REGENERATE_TURN        = "regenerate_turn"
 )
 
 // Batch status:
 const (
-	BATCH_PENDING    = "pending"
-	BATCH_PROCESSING = "processing"
-	BATCH_COMPLETED  = "completed"
-	BATCH_FAILED     = "failed"
-	BATCH_UNKNOWN    = "unknown"
+	BATCH_PENDING    = client.BatchPending
+	BATCH_PROCESSING = client.BatchProcessing
+	BATCH_COMPLETED  = client.BatchCompleted
+	BATCH_FAILED     = client.BatchFailed
+	BATCH_UNKNOWN    = client.BatchUnknown
 )
 
 func GetProcessNames() []string {
@@ -55,245 +57,62 @@ func GetProcessNames() []string {
 type BatchRepository interface {
 	GetBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError)
 	CreateBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError)
+	CreateRegenerateTurnBatch(ctx context.Context, batchID, dialogID string) (*response.MetaProcessing, *errors.AppError)
 	UpdateJob(ctx context.Context, batchID, jobName, status, jobErr string) error
+	RetryJobs(ctx context.Context, batchID, failedJob string, names []string) (*response.MetaProcessing, bool, error)
 	SetBatchResult(ctx context.Context, batchID string, result json.RawMessage) error
+	SetBatchInput(ctx context.Context, batchID string, input any) error
+	GetBatchInput(ctx context.Context, batchID string, input any) (bool, error)
 }
 
 type batchRepository struct {
-	redis *client.RedisClient
-	log   *slog.Logger
+	batches *client.CachedBatches
 }
 
 // NewBatchRepository creates a new dialog batch repository.
-func NewBatchRepository(redis *client.RedisClient, log *slog.Logger) BatchRepository {
-	return &batchRepository{
-		redis: redis,
-		log:   log,
-	}
+func NewBatchRepository(batches *client.CachedBatches) BatchRepository {
+	return &batchRepository{batches: batches}
 }
 
-// GetBatch returns the full batch status including all jobs.
+// GetBatch returns the full batch status including all jobs, from the Redis cache or else from Postgres.
 func (r *batchRepository) GetBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError) {
-	batchKey := fmt.Sprintf("batch:%s", batchID)
-	batchFields, err := r.redis.HGetAll(ctx, batchKey)
-	if err != nil {
-		return nil, errors.NotFoundWrap("failed to get batch", err)
-	}
-
-	if len(batchFields) == 0 {
-		return nil, nil
-	}
-
-	totalJobs, _ := strconv.Atoi(batchFields["total_jobs"])
-	completedJobs, _ := strconv.Atoi(batchFields["completed_jobs"])
-	createdAt := batchFields["created_at"]
-	updatedAt := batchFields["updated_at"]
-
-	batch := &response.MetaProcessing{
-		BatchID:       batchID,
-		Status:        batchFields["status"],
-		TotalJobs:     totalJobs,
-		CompletedJobs: completedJobs,
-		CreatedAt:     &createdAt,
-		UpdatedAt:     &updatedAt,
-	}
-
-	jobsKey := fmt.Sprintf("batch:%s:jobs", batchID)
-	jobFields, err := r.redis.HGetAll(ctx, jobsKey)
-	if err != nil {
-		return nil, errors.NotFoundWrap("failed to get jobs", err)
-	}
-
-	processNames := GetProcessNames()
-	if namesRaw, ok := batchFields["job_names"]; ok && namesRaw != "" {
-		var customNames []string
-		if err := json.Unmarshal([]byte(namesRaw), &customNames); err == nil && len(customNames) > 0 {
-			processNames = customNames
-		}
-	}
-
-	for _, name := range processNames {
-		raw, ok := jobFields[name]
-		if !ok {
-			batch.BatchJobs = append(batch.BatchJobs, response.BatchJob{Name: name, Status: BATCH_UNKNOWN})
-			continue
-		}
-
-		var job response.BatchJob
-		if err := json.Unmarshal([]byte(raw), &job); err != nil {
-			batch.BatchJobs = append(batch.BatchJobs, response.BatchJob{Name: name, Status: BATCH_UNKNOWN})
-			continue
-		}
-
-		batch.BatchJobs = append(batch.BatchJobs, job)
-	}
-
-	return batch, nil
+	return r.batches.Get(ctx, batchID, GetProcessNames())
 }
 
-// CreateBatch initializes a batch and its jobs in Redis.
+// CreateBatch initializes a batch and its jobs in Postgres and caches them in Redis.
 func (r *batchRepository) CreateBatch(ctx context.Context, batchID string) (*response.MetaProcessing, *errors.AppError) {
-	now := time.Now().UTC().Format(time.RFC3339)
-	processNames := GetProcessNames()
-	totalJobs := len(processNames)
-	batchKey := fmt.Sprintf("batch:%s", batchID)
-
-	if err := r.redis.HSet(ctx, batchKey,
-		"status", BATCH_PENDING,
-		"total_jobs", strconv.Itoa(totalJobs),
-		"completed_jobs", "0",
-		"created_at", now,
-		"updated_at", now,
-	); err != nil {
-		r.log.Error("Failed to create dialog batch", "batch_id", batchID, "error", err)
-		return nil, errors.Internal("failed to create dialog batch")
-	}
-
-	namesJSON, _ := json.Marshal(processNames)
-	_ = r.redis.HSet(ctx, batchKey, "job_names", string(namesJSON))
-
-	jobsKey := fmt.Sprintf("batch:%s:jobs", batchID)
-	for _, name := range processNames {
-		jobJSON, _ := json.Marshal(response.BatchJob{Name: name, Status: BATCH_PENDING})
-		if err := r.redis.HSet(ctx, jobsKey, name, string(jobJSON)); err != nil {
-			r.log.Error("Failed to create dialog batch job", "batch_id", batchID, "job_name", name, "error", err)
-			return nil, errors.Internal("failed to create dialog batch job")
-		}
-	}
-
-	_ = r.redis.SetExpiry(ctx, batchKey, processingBatchTTL)
-	_ = r.redis.SetExpiry(ctx, jobsKey, processingBatchTTL)
+	return r.batches.Create(ctx, batchID, BATCH_TYPE_GENERATE_DIALOG, BATCH_REFERENCE_DIALOG, batchID, GetProcessNames())
+}
 
-	return &response.MetaProcessing{
-		BatchID:       batchID,
-		Status:        BATCH_PENDING,
-		TotalJobs:     totalJobs,
-		CompletedJobs: 0,
-		BatchJobs: []response.BatchJob{
-			{
-				Name:   PROCESS_GENERATE_DIALOG,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_GENERATE_IMAGE,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_UPLOAD_IMAGE,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_GENERATE_AUDIO,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_UPLOAD_AUDIO,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_GENERATE_AUDIO_SCRIPTS,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_UPLOAD_AUDIO_SCRIPTS,
-				Status: BATCH_PENDING,
-			},
-			{
-				Name:   PROCESS_SAVE_DIALOG,
-				Status: BATCH_PENDING,
-			},
-		},
-		CreatedAt: &now,
-		UpdatedAt: &now,
-	}, nil
+// CreateRegenerateTurnBatch initializes a single-job batch for a background turn regeneration.
+func (r *batchRepository) CreateRegenerateTurnBatch(ctx context.Context, batchID, dialogID string) (*response.MetaProcessing, *errors.AppError) {
+	return r.batches.Create(ctx, batchID, BATCH_TYPE_REGENERATE_TURN, BATCH_REFERENCE_DIALOG, dialogID, []string{PROCESS_REGENERATE_TURN})
 }
 
 // UpdateJob updates a single job within the batch and recalculates batch state.
 func (r *batchRepository) UpdateJob(ctx context.Context, batchID, jobName, status, jobErr string) error {
-	now := time.Now().UTC().Format(time.RFC3339)
-	job := response.BatchJob{
-		Name:   jobName,
-		Status: status,
-	}
-
-	switch status {
-	case BATCH_PROCESSING:
-		job.StartedAt = now
-	case BATCH_COMPLETED:
-		job.CompletedAt = now
-	case BATCH_FAILED:
-		job.CompletedAt = now
-		job.Error = jobErr
-	}
-
-	jobJSON, _ := json.Marshal(job)
-	jobsKey := fmt.Sprintf("batch:%s:jobs", batchID)
-	if err := r.redis.HSet(ctx, jobsKey, jobName, string(jobJSON)); err != nil {
-		r.log.Error("Failed to update dialog job", "batch_id", batchID, "job_name", jobName, "error", err)
-		return err
-	}
-
-	fields, err := r.redis.HGetAll(ctx, jobsKey)
-	if err != nil {
-		return err
-	}
-
-	processNames := GetProcessNames()
-	batchKey := fmt.Sprintf("batch:%s", batchID)
-	if batchMeta, err := r.redis.HGetAll(ctx, batchKey); err == nil {
-		if namesRaw, ok := batchMeta["job_names"]; ok && namesRaw != "" {
-			var customNames []string
-			if err := json.Unmarshal([]byte(namesRaw), &customNames); err == nil && len(customNames) > 0 {
-				processNames = customNames
-			}
-		}
-	}
-
-	completed := 0
-	hasFailed := false
-	for _, raw := range fields {
-		var current response.BatchJob
-		if err := json.Unmarshal([]byte(raw), &current); err != nil {
-			continue
-		}
-		if current.Status == BATCH_COMPLETED {
-			completed++
-		}
-		if current.Status == BATCH_FAILED {
-			hasFailed = true
-		}
-	}
-
-	batchStatus := BATCH_PROCESSING
-	switch {
-	case hasFailed:
-		batchStatus = BATCH_FAILED
-	case completed == len(processNames):
-		batchStatus = BATCH_COMPLETED
-	}
+	return r.batches.UpdateJob(ctx, batchID, jobName, status, jobErr, GetProcessNames())
+}
 
-	if err := r.redis.HSet(ctx, batchKey,
-		"status", batchStatus,
-		"completed_jobs", strconv.Itoa(completed),
-		"updated_at", now,
-	); err != nil {
-		return err
-	}
+// RetryJobs moves the named jobs back to pending when failedJob is still failed and nothing of
+// the batch is running; false when another retry claimed them first.
+func (r *batchRepository) RetryJobs(ctx context.Context, batchID, failedJob string, names []string) (*response.MetaProcessing, bool, error) {
+	return r.batches.RetryJobs(ctx, batchID, failedJob, names)
+}
 
-	if batchStatus == BATCH_COMPLETED || batchStatus == BATCH_FAILED {
-		_ = r.redis.SetExpiry(ctx, batchKey, completedBatchTTL)
-		_ = r.redis.SetExpiry(ctx, jobsKey, completedBatchTTL)
-	}
+// SetBatchResult stores the final serialized result in the batch,
+// or a result_url pointing at R2 when the result is over the size limit.
+func (r *batchRepository) SetBatchResult(ctx context.Context, batchID string, result json.RawMessage) error {
+	return r.batches.SetResult(ctx, batchID, result)
+}
 
-	return nil
+// SetBatchInput stores the job payload of a batch so its failed jobs can be retried.
+func (r *batchRepository) SetBatchInput(ctx context.Context, batchID string, input any) error {
+	return r.batches.SetInput(ctx, batchID, input)
 }
 
-// SetBatchResult stores the final serialized result in the batch hash.
-func (r *batchRepository) SetBatchResult(ctx context.Context, batchID string, result json.RawMessage) error {
-	batchKey := fmt.Sprintf("batch:%s", batchID)
-	if err := r.redis.HSet(ctx, batchKey, "result", string(result)); err != nil {
-		r.log.Error("Failed to set dialog batch result", "batch_id", batchID, "error", err)
-		return err
-	}
-	return nil
+// GetBatchInput decodes the stored job payload of a batch into input. It reports false when
+// the batch has none (it was created before inputs were stored).
+func (r *batchRepository) GetBatchInput(ctx context.Context, batchID string, input any) (bool, error) {
+	return r.batches.GetInput(ctx, batchID, input)
 }
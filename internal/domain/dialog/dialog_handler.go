@@ -2,6 +2,7 @@ package dialog
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/windfall/uwu_service/internal/infra/client"
@@ -14,13 +15,15 @@ import (
 type DialogHandler struct {
 	service *DialogService
 	queue   *client.QueueClient
+	budget  *client.BudgetClient
 }
 
 // NewDialogHandler creates a new DialogHandler.
-func NewDialogHandler(service *DialogService, queue *client.QueueClient) *DialogHandler {
+func NewDialogHandler(service *DialogService, queue *client.QueueClient, budget *client.BudgetClient) *DialogHandler {
 	return &DialogHandler{
 		service: service,
 		queue:   queue,
+		budget:  budget,
 	}
 }
 
@@ -56,27 +59,85 @@ func (h *DialogHandler) GenerateDialog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 2. generate payload once
+	// 2. refuse new generation jobs once the daily AI budget is spent
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 3. generate payload once
 	payload := req.ToPayload()
 
-	// 3. send job to queue
+	// 4. send job to queue
 	qErr := h.queue.Enqueue(client.Job{
-		Type:    WORKER_GENERATE_DIALOG,
-		Payload: payload,
+		Type:           WORKER_GENERATE_DIALOG,
+		Payload:        payload,
+		BatchID:        payload.DialogID,
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+		ChatProvider:   client.ChatProviderFromContext(r.Context()),
 	})
 	if qErr != nil {
 		response.HandleError(w, qErr)
 		return
 	}
 
-	// 4. create dialog record
+	// 5. create dialog record
 	result, err := h.service.CreateDialogContent(r.Context(), payload)
 	if err != nil {
 		response.HandleError(w, err)
 		return
 	}
 
-	// 5. response accepted
+	// 6. response accepted
+	response.AcceptedWithMeta(w, result.Data, result.Meta)
+}
+
+// -------------------------------------------------------------------------
+// AdaptDialog handles POST /api/v1/dialogs/{dialogID}/adapt?level=B2
+// -------------------------------------------------------------------------
+
+func (h *DialogHandler) AdaptDialog(w http.ResponseWriter, r *http.Request) {
+	// 1. parse and validate request
+	var req AdaptDialogRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 2. refuse new generation jobs once the daily AI budget is spent
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 3. build the generation payload from the original dialog
+	payload, err := h.service.PrepareAdaptDialog(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 4. send job to queue
+	qErr := h.queue.Enqueue(client.Job{
+		Type:           WORKER_GENERATE_DIALOG,
+		Payload:        payload,
+		BatchID:        payload.DialogID,
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+		ChatProvider:   client.ChatProviderFromContext(r.Context()),
+	})
+	if qErr != nil {
+		response.HandleError(w, qErr)
+		return
+	}
+
+	// 5. create the variant record
+	result, err := h.service.CreateDialogContent(r.Context(), payload)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 6. response accepted
 	response.AcceptedWithMeta(w, result.Data, result.Meta)
 }
 
@@ -99,8 +160,13 @@ func (h *DialogHandler) GetDialogDetails(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// 3. response success
-	response.OKWithMeta(w, dialog.Data, dialog.Meta)
+	// 3. response success with the media encodings the client accepts (304 when it already has this version)
+	dialog.Data.Details = response.NegotiateMediaJSON(r, dialog.Data.Details)
+	var updatedAt time.Time
+	if dialog.Data.UpdatedAt != nil {
+		updatedAt = *dialog.Data.UpdatedAt
+	}
+	response.OKConditional(w, r, updatedAt, dialog.Data, dialog.Meta)
 }
 
 // ToggleSaved handles POST /api/v1/dialogs/{dialogID}/toggle-saved
@@ -126,6 +192,52 @@ func (h *DialogHandler) ToggleSaved(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, result)
 }
 
+// PublishDialog handles POST /api/v1/dialogs/{dialogID}/publish
+func (h *DialogHandler) PublishDialog(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		response.HandleError(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	dialogID := chi.URLParam(r, "dialogID")
+	if dialogID == "" {
+		response.HandleError(w, errors.Validation("Dialog ID is required"))
+		return
+	}
+
+	result, err := h.service.PublishDialog(r.Context(), dialogID, userID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// UnpublishDialog handles POST /api/v1/dialogs/{dialogID}/unpublish
+func (h *DialogHandler) UnpublishDialog(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		response.HandleError(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	dialogID := chi.URLParam(r, "dialogID")
+	if dialogID == "" {
+		response.HandleError(w, errors.Validation("Dialog ID is required"))
+		return
+	}
+
+	result, err := h.service.UnpublishDialog(r.Context(), dialogID, userID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
 // StartSpeech handles POST /api/v1/dialogs/{dialogID}/start-speech
 func (h *DialogHandler) StartSpeech(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
@@ -199,18 +311,24 @@ func (h *DialogHandler) SubmitChat(w http.ResponseWriter, r *http.Request) {
 
 	payload := req.ToPayload()
 
-	// 1. Enqueue job (can be called before validation)
-	_ = h.queue.Enqueue(client.Job{
-		Type:    WORKER_REPLY_CHAT_MESSAGE,
-		Payload: payload,
-	})
-
+	// 1. Save the message first: the worker replaces it with the reply
 	result, err := h.service.SubmitChat(r.Context(), payload)
 	if err != nil {
 		response.HandleError(w, err)
 		return
 	}
 
+	// 2. Enqueue the reply
+	if qErr := h.queue.Enqueue(client.Job{
+		Type:           WORKER_REPLY_CHAT_MESSAGE,
+		Payload:        payload,
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+		ChatProvider:   client.ChatProviderFromContext(r.Context()),
+	}); qErr != nil {
+		response.HandleError(w, qErr)
+		return
+	}
+
 	response.Accepted(w, result)
 }
 
@@ -236,3 +354,234 @@ func (h *DialogHandler) GetSubmitChat(w http.ResponseWriter, r *http.Request) {
 
 	response.OK(w, result)
 }
+
+// GetPracticeSession handles GET /api/v1/dialogs/{dialogID}/session
+func (h *DialogHandler) GetPracticeSession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		response.HandleError(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	dialogID := chi.URLParam(r, "dialogID")
+	if dialogID == "" {
+		response.HandleError(w, errors.Validation("Dialog ID is required"))
+		return
+	}
+
+	result, err := h.service.GetPracticeSession(r.Context(), dialogID, userID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// RegenerateScriptTurn handles POST /api/v1/dialogs/{dialogID}/turns/{turnIndex}/regenerate
+func (h *DialogHandler) RegenerateScriptTurn(w http.ResponseWriter, r *http.Request) {
+	var req RegenerateTurnRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// Regeneration calls the AI and TTS, so it counts against the daily budget
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// async=true returns the batch right away and regenerates on the job queue
+	if req.Async {
+		payload, meta, err := h.service.PrepareRegenerateScriptTurn(r.Context(), req.ToInput())
+		if err != nil {
+			response.HandleError(w, err)
+			return
+		}
+
+		qErr := h.queue.Enqueue(client.Job{
+			Type:           WORKER_REGENERATE_TURN,
+			Payload:        *payload,
+			BatchID:        payload.BatchID,
+			OrganizationID: client.OrganizationFromContext(r.Context()),
+			ChatProvider:   client.ChatProviderFromContext(r.Context()),
+		})
+		if qErr != nil {
+			response.HandleError(w, qErr)
+			return
+		}
+
+		response.Accepted(w, meta)
+		return
+	}
+
+	result, err := h.service.RegenerateScriptTurn(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetDialogBatch handles GET /api/v1/dialogs/{dialogID}/batches/{batchID}
+func (h *DialogHandler) GetDialogBatch(w http.ResponseWriter, r *http.Request) {
+	var req GetDialogBatchRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.GetDialogBatch(r.Context(), req.DialogID, req.BatchID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// RetryBatchJob handles POST /api/v1/batches/{batchID}/jobs/{jobName}/retry
+func (h *DialogHandler) RetryBatchJob(w http.ResponseWriter, r *http.Request) {
+	var req RetryBatchJobRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// Retries are new generation work and count against the daily AI budget
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	retry, err := h.service.PrepareRetryBatchJob(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	retry.Job.OrganizationID = client.OrganizationFromContext(r.Context())
+	retry.Job.ChatProvider = client.ChatProviderFromContext(r.Context())
+	if qErr := h.queue.Enqueue(retry.Job); qErr != nil {
+		h.service.CancelRetryBatchJob(r.Context(), retry)
+		response.HandleError(w, qErr)
+		return
+	}
+
+	response.Accepted(w, retry.Batch)
+}
+
+// SparringTurn handles POST /api/v1/dialogs/{dialogID}/sparring/turn
+func (h *DialogHandler) SparringTurn(w http.ResponseWriter, r *http.Request) {
+	var req SparringTurnRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// Each turn calls the AI, TTS and possibly Whisper
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.SparringTurn(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// CheckTurnBlanks handles POST /api/v1/dialogs/{dialogID}/turns/{turnIndex}/check
+func (h *DialogHandler) CheckTurnBlanks(w http.ResponseWriter, r *http.Request) {
+	var req CheckBlanksRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.CheckTurnBlanks(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetTurnHint handles GET /api/v1/dialogs/{dialogID}/turns/{turnIndex}/hint?step=0
+func (h *DialogHandler) GetTurnHint(w http.ResponseWriter, r *http.Request) {
+	var req TurnHintRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.GetTurnHint(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// SetAudience handles PUT /api/v1/admin/dialogs/{dialogID}/audience
+func (h *DialogHandler) SetAudience(w http.ResponseWriter, r *http.Request) {
+	var req SetAudienceRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.SetAudience(r.Context(), req.DialogID, req.Audience); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{"id": req.DialogID, "audience": req.Audience})
+}
+
+// ProposeFix handles POST /api/v1/dialogs/{dialogID}/fix
+func (h *DialogHandler) ProposeFix(w http.ResponseWriter, r *http.Request) {
+	var req FixDialogRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// The fix calls the AI, so it counts against the daily budget
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ProposeFix(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ApplyFix handles POST /api/v1/dialogs/{dialogID}/fix/{fixID}/apply
+func (h *DialogHandler) ApplyFix(w http.ResponseWriter, r *http.Request) {
+	var req ApplyFixRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ApplyFix(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -1,18 +1,37 @@
 package dialog
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
 )
 
 // Constants
-const FeatureID = 2
+const FeatureID = client.FeatureDialog
+
+// Publish states. Learners only see the published snapshot; owners edit the draft.
+const (
+	DIALOG_DRAFT     = "draft"
+	DIALOG_PUBLISHED = "published"
+)
+
+// Review states of a dialog the quality critic flagged (empty when it was not flagged)
+const (
+	REVIEW_PENDING  = "pending"
+	REVIEW_APPROVED = "approved"
+	REVIEW_REJECTED = "rejected"
+)
+
+// dialogStatusColumn derives the publish state of a learning_items row aliased as l.
+const dialogStatusColumn = `CASE WHEN l.published_details IS NULL THEN 'draft' ELSE 'published' END`
 
 // User Action model
 type UserAction struct {
@@ -45,6 +64,7 @@ type DialogActions struct {
 // LearningItem model
 type LearningItem struct {
 	ID        uuid.UUID       `json:"id"`
+	ParentID  *uuid.UUID      `json:"parent_id"`
 	FeatureID int             `json:"feature_id"`
 	Content   string          `json:"content"`
 	Language  string          `json:"language"`
@@ -56,8 +76,36 @@ type LearningItem struct {
 	CreatedBy string          `json:"created_by"`
 	CreatedAt *time.Time      `json:"created_at"`
 	UpdatedAt *time.Time      `json:"updated_at"`
+	// general or adult (hidden from minors, see client.AudienceVisibleSQL)
+	Audience string `json:"audience"`
+	// Draft / published versioning
+	Version          int        `json:"version"`
+	Status           string     `json:"status"`
+	PublishedVersion *int       `json:"published_version"`
+	PublishedAt      *time.Time `json:"published_at"`
+	// Quality critic score and review state (see QualityReview)
+	QualityScore *int   `json:"quality_score,omitempty"`
+	ReviewStatus string `json:"review_status,omitempty"`
+	// Video vocabulary used in the published script (details response only)
+	KeyVocab []KeyVocab `json:"key_vocab,omitempty"`
 	// Learning Item Actions
 	Actions DialogActions `json:"actions"`
+	// Favorited by the requesting user
+	Favorited bool `json:"favorited"`
+}
+
+// VocabularyTerm is a vocabulary item or key phrase of an active video
+type VocabularyTerm struct {
+	ItemID string
+	Text   string
+}
+
+// KeyVocab is a video vocabulary term used in a script turn
+type KeyVocab struct {
+	ItemID    string `json:"item_id"`
+	ItemTitle string `json:"item_title,omitempty"`
+	Term      string `json:"term"`
+	TurnIndex int    `json:"turn_index"`
 }
 
 // DialogDetails is the structure of the details field in LearningItem model
@@ -68,18 +116,134 @@ type DialogDetails struct {
 	Level       string     `json:"level"`
 	Tags        []string   `json:"tags"`
 	ImagePrompt string     `json:"image_prompt,omitempty"`
+	ImageStyle  string     `json:"image_style,omitempty"`
 	ImageURL    string     `json:"image_url,omitempty"`
 	AudioURL    string     `json:"audio_url,omitempty"`
 	SpeechMode  SpeechMode `json:"speech_mode"`
 	ChatMode    ChatMode   `json:"chat_mode"`
+	// Computed from the saved speech script (see countTurns)
+	TurnCount     int `json:"turn_count"`
+	UserTurnCount int `json:"user_turn_count"`
+	// Alternate encodings of the image and audio URLs: canonical URL -> content type -> URL
+	MediaVariants response.MediaVariants `json:"media_variants,omitempty"`
+	// Audience the generator classified the scenario as (see LearningItem.Audience)
+	Audience string `json:"audience,omitempty"`
+	// Applied AI fixes, oldest first
+	Revisions []DialogRevision `json:"revisions,omitempty"`
+	// Critic pass run after generation (see DialogService.ProcessGenerateDialog)
+	Quality *QualityReview `json:"quality,omitempty"`
+}
+
+// QualityReview is the critic's verdict on a generated dialog
+type QualityReview struct {
+	Naturalness int      `json:"naturalness"`
+	Correctness int      `json:"correctness"`
+	Score       int      `json:"score"`
+	Issues      []string `json:"issues"`
+}
+
+// DialogRevision records an applied fix with the text it replaced
+type DialogRevision struct {
+	Revision int         `json:"revision"`
+	Note     string      `json:"note"`
+	Changes  []FixChange `json:"changes"`
+	EditedBy string      `json:"edited_by"`
+	EditedAt time.Time   `json:"edited_at"`
+}
+
+// DialogMedia is regenerated media for a dialog; empty URLs and turns not listed are left alone.
+type DialogMedia struct {
+	ImageURL    string
+	AudioURL    string
+	ScriptAudio map[int]string // script turn -> audio URL
+	Variants    response.MediaVariants
+}
+
+// Empty reports whether nothing was regenerated.
+func (m *DialogMedia) Empty() bool {
+	return m.ImageURL == "" && m.AudioURL == "" && len(m.ScriptAudio) == 0
+}
+
+// applyTo writes the media into raw details.
+func (m *DialogMedia) applyTo(raw json.RawMessage) (json.RawMessage, *errors.AppError) {
+	var details DialogDetails
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+
+	if m.ImageURL != "" {
+		details.ImageURL = m.ImageURL
+	}
+	if m.AudioURL != "" {
+		details.AudioURL = m.AudioURL
+	}
+	for idx, url := range m.ScriptAudio {
+		if idx < len(details.SpeechMode.Script) {
+			details.SpeechMode.Script[idx].AudioURL = &url
+		}
+	}
+	if len(m.Variants) > 0 {
+		if details.MediaVariants == nil {
+			details.MediaVariants = response.MediaVariants{}
+		}
+		for url, variants := range m.Variants {
+			details.MediaVariants[url] = variants
+		}
+	}
+
+	updated, _ := json.Marshal(details)
+	return updated, nil
+}
+
+// countTurns recomputes the turn counts from the speech script.
+func (d *DialogDetails) countTurns() {
+	d.TurnCount = len(d.SpeechMode.Script)
+	d.UserTurnCount = 0
+	for _, turn := range d.SpeechMode.Script {
+		if strings.EqualFold(turn.Speaker, SpeakerUser) {
+			d.UserTurnCount++
+		}
+	}
+}
+
+// validateDetails rejects details that don't match the DialogDetails schema (unknown fields included).
+func validateDetails(raw json.RawMessage) *errors.AppError {
+	if len(raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var details DialogDetails
+	if err := decoder.Decode(&details); err != nil {
+		return errors.ValidationWrap("dialog details do not match the schema", err)
+	}
+	return nil
+}
+
+// NormalizeDetails re-encodes stored dialog details as DialogDetails (see cmd/normalize-details).
+func NormalizeDetails(raw json.RawMessage) (json.RawMessage, error) {
+	var details DialogDetails
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, err
+	}
+	if len(details.SpeechMode.Script) > 0 {
+		details.countTurns()
+	}
+	return json.Marshal(details)
 }
 
 // DialogRepository interface
 type DialogRepository interface {
 	GetDialog(ctx context.Context, dialogID, userID string) (*LearningItem, *errors.AppError)
-	ListDialogs(ctx context.Context, limit, offset int) ([]*LearningItem, int, *errors.AppError)
+	GetDialogDetails(ctx context.Context, dialogID, userID string) (*LearningItem, *errors.AppError)
+	ListDialogs(ctx context.Context, userID string, limit, offset int) ([]*LearningItem, int, *errors.AppError)
 	CreateDialog(ctx context.Context, item *LearningItem) *errors.AppError
 	UpdateDialog(ctx context.Context, item *LearningItem) *errors.AppError
+	UpdateDialogDetails(ctx context.Context, dialogID string, update func(details *DialogDetails) *errors.AppError) *errors.AppError
+	GetDetailsByID(ctx context.Context, dialogID string) (*DialogDetails, *errors.AppError)
+	UpdateDialogMedia(ctx context.Context, dialogID string, media *DialogMedia) *errors.AppError
+	PublishDialog(ctx context.Context, dialogID string, keyVocab []KeyVocab) *errors.AppError
+	UnpublishDialog(ctx context.Context, dialogID string) *errors.AppError
 	GetActionByUserID(ctx context.Context, learningID, userID, actionType string) (*UserAction, bool, *errors.AppError)
 	ToggleSaved(ctx context.Context, dialogID, userID string) (string, bool, *errors.AppError)
 	StartSpeech(ctx context.Context, dialogID, userID string, metadata json.RawMessage) (string, *errors.AppError)
@@ -87,43 +251,103 @@ type DialogRepository interface {
 	SubmitSpeechAction(ctx context.Context, actionID, userID string, metadataJSON []byte) *errors.AppError
 	GetChatAction(ctx context.Context, actionID, userID string) (*UserAction, *errors.AppError)
 	UpdateChatAction(ctx context.Context, actionID, userID string, metadataJSON []byte) *errors.AppError
+	StartSparring(ctx context.Context, dialogID, userID string, metadata json.RawMessage) (string, *errors.AppError)
+	UpdateSparringAction(ctx context.Context, actionID, userID string, metadataJSON []byte) *errors.AppError
+	SetAudience(ctx context.Context, dialogID, audience string) *errors.AppError
+	IsMinor(ctx context.Context, userID string) (bool, *errors.AppError)
+	ListUpheldReportReasons(ctx context.Context, since time.Time) ([]string, *errors.AppError)
+	ListVocabularyTerms(ctx context.Context, language string) ([]VocabularyTerm, *errors.AppError)
 }
 
 type dialogRepository struct {
-	db *client.PostgresClient
+	db     *client.PostgresClient
+	cipher *client.FieldCipher // speech and sparring transcripts are stored encrypted (nil = plaintext)
+}
+
+func NewDialogRepository(db *client.PostgresClient, cipher *client.FieldCipher) DialogRepository {
+	return &dialogRepository{db: db, cipher: cipher}
 }
 
-func NewDialogRepository(db *client.PostgresClient) DialogRepository {
-	return &dialogRepository{db: db}
+// sealMetadata encrypts the sensitive metadata fields of an action before it is written.
+func (r *dialogRepository) sealMetadata(actionType string, metadata json.RawMessage) (json.RawMessage, *errors.AppError) {
+	sealed, err := r.cipher.SealAction(actionType, metadata)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to encrypt action metadata", err)
+	}
+	return sealed, nil
 }
 
+// getDialogQuery selects a dialog with the actions of all users on it ($1 dialog id, $2 feature id, $3 user id).
+var getDialogQuery = `
+	SELECT 
+		l.id, l.parent_id, l.feature_id, l.content, l.language, l.level,
+		CASE WHEN l.created_by = $3 THEN l.details ELSE l.published_details END,
+		l.metadata, l.tags, l.is_active, l.created_by,
+		l.created_at, l.updated_at,
+		l.version, ` + dialogStatusColumn + `, l.published_version, l.published_at, l.audience,
+		l.quality_score, COALESCE(l.review_status, ''),
+		COALESCE(
+			jsonb_agg(jsonb_build_object(
+				'user_id', ua.user_id,
+				'action_type', ua.action_type
+			)) FILTER (WHERE ua.id IS NOT NULL),
+			'[]'::jsonb
+		) as actions
+	FROM learning_items l
+	LEFT JOIN (
+		SELECT id, user_id, learning_id, action_type::text AS action_type FROM user_actions
+		WHERE action_type IN ('submit_chat', 'submit_speech') AND deleted_at IS NULL
+		UNION ALL
+		-- saving a dialog is a favorite
+		SELECT id, user_id, content_id, 'dialogue_saved' FROM favorites WHERE content_type = 'dialog'
+	) ua ON l.id = ua.learning_id
+	WHERE l.id = $1 AND l.feature_id = $2
+	AND (l.created_by = $3 OR (l.published_details IS NOT NULL AND l.is_active = true))
+	AND ` + client.AudienceVisibleSQL("l", "$3") + `
+	GROUP BY l.id
+`
+
+// GetDialog returns the draft to the owner and the published snapshot to everyone else.
+// Unpublished or inactive (e.g. hidden by moderation) dialogs are not found for other users.
 func (r *dialogRepository) GetDialog(ctx context.Context, dialogID, userID string) (*LearningItem, *errors.AppError) {
-	query := `
-		SELECT 
-			l.id, l.feature_id, l.content, l.language, l.level,
-			l.details, l.metadata, l.tags, l.is_active, l.created_by,
-			l.created_at, l.updated_at,
-			COALESCE(
-				jsonb_agg(jsonb_build_object(
-					'user_id', ua.user_id,
-					'action_type', ua.action_type
-				)) FILTER (WHERE ua.id IS NOT NULL),
-				'[]'::jsonb
-			) as actions
-		FROM learning_items l
-		LEFT JOIN user_actions ua
-			ON l.id = ua.learning_id
-			AND ua.action_type IN ('dialogue_saved', 'submit_chat', 'submit_speech')
-			AND ua.deleted_at IS NULL
-		WHERE l.id = $1 AND l.feature_id = $2
-		GROUP BY l.id
-	`
+	return scanDialog(r.db.Pool.QueryRow(ctx, getDialogQuery, dialogID, FeatureID, userID), userID)
+}
+
+// GetDialogDetails is GetDialog with the key vocabulary of published dialogs, loaded in one round trip.
+func (r *dialogRepository) GetDialogDetails(ctx context.Context, dialogID, userID string) (*LearningItem, *errors.AppError) {
+	batch := &pgx.Batch{}
+	batch.Queue(getDialogQuery, dialogID, FeatureID, userID)
+	batch.Queue(listKeyVocabQuery, dialogID)
+
+	results := r.db.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	item, err := scanDialog(results.QueryRow(), userID)
+	if err != nil {
+		return nil, err
+	}
+	rows, queryErr := results.Query()
+	if queryErr != nil {
+		return nil, errors.InternalWrap("failed to list key vocabulary", queryErr)
+	}
+	keyVocab, err := scanKeyVocab(rows)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == DIALOG_PUBLISHED {
+		item.KeyVocab = keyVocab
+	}
+	return item, nil
+}
 
+// scanDialog reads a getDialogQuery row and counts the actions, marking those of userID.
+func scanDialog(row pgx.Row, userID string) (*LearningItem, *errors.AppError) {
 	var item LearningItem
 	var actionsJSON []byte
 
-	err := r.db.Pool.QueryRow(ctx, query, dialogID, FeatureID).Scan(
+	err := row.Scan(
 		&item.ID,
+		&item.ParentID,
 		&item.FeatureID,
 		&item.Content,
 		&item.Language,
@@ -135,6 +359,13 @@ func (r *dialogRepository) GetDialog(ctx context.Context, dialogID, userID strin
 		&item.CreatedBy,
 		&item.CreatedAt,
 		&item.UpdatedAt,
+		&item.Version,
+		&item.Status,
+		&item.PublishedVersion,
+		&item.PublishedAt,
+		&item.Audience,
+		&item.QualityScore,
+		&item.ReviewStatus,
 		&actionsJSON,
 	)
 	if err != nil {
@@ -163,6 +394,7 @@ func (r *dialogRepository) GetDialog(ctx context.Context, dialogID, userID strin
 					item.Actions.Type.Saved++
 					if action.UserID == userID {
 						item.Actions.User.Saved = true
+						item.Favorited = true
 					}
 				case "submit_chat":
 					item.Actions.Type.Chat++
@@ -192,11 +424,12 @@ func (r *dialogRepository) GetDialog(ctx context.Context, dialogID, userID strin
 	return &item, nil
 }
 
-func (r *dialogRepository) ListDialogs(ctx context.Context, limit, offset int) ([]*LearningItem, int, *errors.AppError) {
+// ListDialogs lists published dialogs plus the caller's own drafts, without adult ones for minors.
+func (r *dialogRepository) ListDialogs(ctx context.Context, userID string, limit, offset int) ([]*LearningItem, int, *errors.AppError) {
 	// 1. Get total count
-	countQuery := `SELECT COUNT(*) FROM learning_items WHERE feature_id = $1`
+	countQuery := `SELECT COUNT(*) FROM learning_items l WHERE l.feature_id = $1 AND (l.created_by = $2 OR (l.published_details IS NOT NULL AND l.is_active = true)) AND ` + client.AudienceVisibleSQL("l", "$2")
 	var total int
-	err := r.db.Pool.QueryRow(ctx, countQuery, FeatureID).Scan(&total)
+	err := r.db.Pool.QueryRow(ctx, countQuery, FeatureID, userID).Scan(&total)
 	if err != nil {
 		return nil, 0, errors.InternalWrap("failed to count dialog contents", err)
 	}
@@ -204,16 +437,22 @@ func (r *dialogRepository) ListDialogs(ctx context.Context, limit, offset int) (
 	// 2. Get paginated results with LEFT JOIN & jsonb_agg
 	query := `
 		SELECT 
-			l.id, l.feature_id, l.content, l.language, l.level, 
-			l.details, l.metadata, l.tags, l.is_active, l.created_by, 
-			l.created_at, l.updated_at
+			l.id, l.parent_id, l.feature_id, l.content, l.language, l.level, 
+			CASE WHEN l.created_by = $2 THEN l.details ELSE l.published_details END,
+			l.metadata, l.tags, l.is_active, l.created_by, 
+			l.created_at, l.updated_at,
+			l.version, ` + dialogStatusColumn + `, l.published_version, l.published_at, l.audience,
+			l.quality_score, COALESCE(l.review_status, ''),
+			` + client.FavoritedSQL("l", client.FavoriteDialog, "$2") + `
 		FROM learning_items l
 		WHERE l.feature_id = $1
+		AND (l.created_by = $2 OR (l.published_details IS NOT NULL AND l.is_active = true))
+		AND ` + client.AudienceVisibleSQL("l", "$2") + `
 		ORDER BY l.created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, FeatureID, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, FeatureID, userID, limit, offset)
 	if err != nil {
 		return nil, 0, errors.InternalWrap("failed to list dialog contents", err)
 	}
@@ -225,6 +464,7 @@ func (r *dialogRepository) ListDialogs(ctx context.Context, limit, offset int) (
 
 		err := rows.Scan(
 			&dialog.ID,
+			&dialog.ParentID,
 			&dialog.FeatureID,
 			&dialog.Content,
 			&dialog.Language,
@@ -236,6 +476,14 @@ func (r *dialogRepository) ListDialogs(ctx context.Context, limit, offset int) (
 			&dialog.CreatedBy,
 			&dialog.CreatedAt,
 			&dialog.UpdatedAt,
+			&dialog.Version,
+			&dialog.Status,
+			&dialog.PublishedVersion,
+			&dialog.PublishedAt,
+			&dialog.Audience,
+			&dialog.QualityScore,
+			&dialog.ReviewStatus,
+			&dialog.Favorited,
 		)
 		if err != nil {
 			return nil, 0, errors.InternalWrap("failed to scan dialog content", err)
@@ -249,12 +497,16 @@ func (r *dialogRepository) ListDialogs(ctx context.Context, limit, offset int) (
 }
 
 func (r *dialogRepository) CreateDialog(ctx context.Context, item *LearningItem) *errors.AppError {
+	if err := validateDetails(item.Details); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO learning_items (
-			id, feature_id, content, language, level, details, tags, metadata, is_active, created_by
+			id, feature_id, content, language, level, details, tags, metadata, is_active, created_by, parent_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
-		) RETURNING id, created_at, updated_at
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		) RETURNING id, created_at, updated_at, version
 	`
 
 	err := r.db.Pool.QueryRow(ctx, query,
@@ -268,19 +520,27 @@ func (r *dialogRepository) CreateDialog(ctx context.Context, item *LearningItem)
 		item.Metadata,
 		item.IsActive,
 		item.CreatedBy,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+		item.ParentID,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Version)
 
 	if err != nil {
 		return errors.InternalWrap("failed to create dialog content", err)
 	}
+	item.Status = DIALOG_DRAFT
 
 	return nil
 }
 
 func (r *dialogRepository) UpdateDialog(ctx context.Context, item *LearningItem) *errors.AppError {
+	if err := validateDetails(item.Details); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE learning_items
-		SET feature_id = $1, content = $2, language = $3, level = $4, tags = $5, details = $6, metadata = $7, is_active = $8, created_by = $9
+		SET feature_id = $1, content = $2, language = $3, level = $4, tags = $5, details = $6, metadata = $7, is_active = $8, created_by = $9, version = version + 1, updated_at = NOW(),
+			audience = COALESCE(NULLIF($11::text, ''), audience),
+			quality_score = $12, review_status = NULLIF($13, '')
 		WHERE id = $10
 	`
 
@@ -295,6 +555,9 @@ func (r *dialogRepository) UpdateDialog(ctx context.Context, item *LearningItem)
 		item.IsActive,
 		item.CreatedBy,
 		item.ID,
+		item.Audience,
+		item.QualityScore,
+		item.ReviewStatus,
 	)
 
 	if err != nil {
@@ -307,6 +570,248 @@ func (r *dialogRepository) UpdateDialog(ctx context.Context, item *LearningItem)
 	return nil
 }
 
+// UpdateDialogDetails locks the dialog row, applies update to its details and saves them in one transaction.
+func (r *dialogRepository) UpdateDialogDetails(ctx context.Context, dialogID string, update func(details *DialogDetails) *errors.AppError) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var raw json.RawMessage
+	err = tx.QueryRow(ctx, `SELECT details FROM learning_items WHERE id = $1 AND feature_id = $2 FOR UPDATE`, dialogID, FeatureID).Scan(&raw)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("dialog content not found")
+		}
+		return errors.InternalWrap("failed to lock dialog content", err)
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return errors.InternalWrap("failed to parse dialog details", err)
+	}
+	if appErr := update(&details); appErr != nil {
+		return appErr
+	}
+	details.countTurns()
+
+	updated, _ := json.Marshal(details)
+	if _, err := tx.Exec(ctx, `UPDATE learning_items SET details = $1, version = version + 1, updated_at = NOW() WHERE id = $2`, updated, dialogID); err != nil {
+		return errors.InternalWrap("failed to update dialog details", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit dialog details", err)
+	}
+
+	return nil
+}
+
+// GetDetailsByID returns the draft details of a dialog, whoever owns it (background jobs only).
+func (r *dialogRepository) GetDetailsByID(ctx context.Context, dialogID string) (*DialogDetails, *errors.AppError) {
+	var raw json.RawMessage
+	err := r.db.Pool.QueryRow(ctx, `SELECT details FROM learning_items WHERE id = $1 AND feature_id = $2`, dialogID, FeatureID).Scan(&raw)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("dialog content not found")
+		}
+		return nil, errors.InternalWrap("failed to get dialog details", err)
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+	return &details, nil
+}
+
+// UpdateDialogMedia writes regenerated media into the draft and, when the dialog is published,
+// into the published snapshot, so learners stop getting the broken URLs. The version is kept
+// since the content did not change.
+func (r *dialogRepository) UpdateDialogMedia(ctx context.Context, dialogID string, media *DialogMedia) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var draft, published json.RawMessage
+	err = tx.QueryRow(ctx, `SELECT details, published_details FROM learning_items WHERE id = $1 AND feature_id = $2 FOR UPDATE`, dialogID, FeatureID).Scan(&draft, &published)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("dialog content not found")
+		}
+		return errors.InternalWrap("failed to lock dialog content", err)
+	}
+
+	draft, appErr := media.applyTo(draft)
+	if appErr != nil {
+		return appErr
+	}
+	if len(published) > 0 && string(published) != "null" {
+		if published, appErr = media.applyTo(published); appErr != nil {
+			return appErr
+		}
+	} else {
+		published = nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE learning_items SET details = $1, published_details = COALESCE($2, published_details), updated_at = NOW()
+		WHERE id = $3
+	`, draft, published, dialogID); err != nil {
+		return errors.InternalWrap("failed to update dialog media", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit dialog media", err)
+	}
+	return nil
+}
+
+// PublishDialog snapshots the current draft as the version learners see and replaces
+// the dialog's links to the video vocabulary it uses.
+func (r *dialogRepository) PublishDialog(ctx context.Context, dialogID string, keyVocab []KeyVocab) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE learning_items
+		SET published_details = details, published_version = version, published_at = NOW(), is_active = true, updated_at = NOW()
+		WHERE id = $1 AND feature_id = $2 AND moderation_hidden_at IS NULL
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, dialogID, FeatureID)
+	if err != nil {
+		return errors.InternalWrap("failed to publish dialog", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		// Hidden by moderation stays hidden until a moderator resolves its reports
+		var hidden bool
+		err := tx.QueryRow(ctx, `SELECT moderation_hidden_at IS NOT NULL FROM learning_items WHERE id = $1 AND feature_id = $2`, dialogID, FeatureID).Scan(&hidden)
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("dialog content not found")
+		}
+		if err != nil {
+			return errors.InternalWrap("failed to publish dialog", err)
+		}
+		return errors.Forbidden("dialog is hidden by moderation")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM item_scenario_links WHERE scenario_id = $1`, dialogID); err != nil {
+		return errors.InternalWrap("failed to clear dialog vocabulary links", err)
+	}
+	for _, link := range keyVocab {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO item_scenario_links (item_id, scenario_id, turn_index, term)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, link.ItemID, dialogID, link.TurnIndex, link.Term); err != nil {
+			return errors.InternalWrap("failed to link dialog vocabulary", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit dialog publish", err)
+	}
+	return nil
+}
+
+// UnpublishDialog hides the dialog from learners; the draft is kept.
+func (r *dialogRepository) UnpublishDialog(ctx context.Context, dialogID string) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE learning_items
+		SET published_details = NULL, published_version = NULL, published_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND feature_id = $2
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, dialogID, FeatureID)
+	if err != nil {
+		return errors.InternalWrap("failed to unpublish dialog", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.NotFound("dialog content not found")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM item_scenario_links WHERE scenario_id = $1`, dialogID); err != nil {
+		return errors.InternalWrap("failed to clear dialog vocabulary links", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit dialog unpublish", err)
+	}
+	return nil
+}
+
+// ListVocabularyTerms returns the vocabulary and key phrases of every active video in language.
+func (r *dialogRepository) ListVocabularyTerms(ctx context.Context, language string) ([]VocabularyTerm, *errors.AppError) {
+	query := `
+		SELECT l.id::text, e->>'text'
+		FROM learning_items l
+		CROSS JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(l.details->'vocabulary') = 'array' THEN l.details->'vocabulary' ELSE '[]'::jsonb END ||
+			CASE WHEN jsonb_typeof(l.details->'key_phrases') = 'array' THEN l.details->'key_phrases' ELSE '[]'::jsonb END
+		) e
+		WHERE l.feature_id = $1 AND l.is_active = true AND l.language = $2
+			AND jsonb_typeof(e) = 'object' AND COALESCE(e->>'text', '') <> ''
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, client.FeatureVideo, language)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list vocabulary terms", err)
+	}
+	defer rows.Close()
+
+	terms := make([]VocabularyTerm, 0)
+	for rows.Next() {
+		var term VocabularyTerm
+		if err := rows.Scan(&term.ItemID, &term.Text); err != nil {
+			return nil, errors.InternalWrap("failed to scan vocabulary term", err)
+		}
+		terms = append(terms, term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list vocabulary terms", err)
+	}
+
+	return terms, nil
+}
+
+// listKeyVocabQuery selects the video vocabulary linked to the published script, in turn order ($1 dialog id).
+const listKeyVocabQuery = `
+	SELECT k.item_id::text, COALESCE(l.content, ''), k.term, k.turn_index
+	FROM item_scenario_links k
+	JOIN learning_items l ON l.id = k.item_id AND l.is_active = true
+	WHERE k.scenario_id = $1
+	ORDER BY k.turn_index, k.term
+`
+
+func scanKeyVocab(rows pgx.Rows) ([]KeyVocab, *errors.AppError) {
+	defer rows.Close()
+
+	keyVocab := make([]KeyVocab, 0)
+	for rows.Next() {
+		var link KeyVocab
+		if err := rows.Scan(&link.ItemID, &link.ItemTitle, &link.Term, &link.TurnIndex); err != nil {
+			return nil, errors.InternalWrap("failed to scan key vocabulary", err)
+		}
+		keyVocab = append(keyVocab, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list key vocabulary", err)
+	}
+
+	return keyVocab, nil
+}
+
 func (r *dialogRepository) GetActionByUserID(ctx context.Context, learningID, userID, actionType string) (*UserAction, bool, *errors.AppError) {
 	query := `
 		SELECT id, user_id, learning_id, action_type, metadata, created_at, updated_at
@@ -334,31 +839,24 @@ func (r *dialogRepository) GetActionByUserID(ctx context.Context, learningID, us
 		return nil, false, errors.InternalWrap("failed to get action by user ID", err)
 	}
 
+	metadata, err := r.cipher.OpenAction(action.ActionType, action.Metadata)
+	if err != nil {
+		return nil, false, errors.InternalWrap("failed to decrypt action metadata", err)
+	}
+	action.Metadata = metadata
+
 	return &action, true, nil
 }
 
+// ToggleSaved toggles the dialog in the user's favorites.
 func (r *dialogRepository) ToggleSaved(ctx context.Context, dialogID, userID string) (string, bool, *errors.AppError) {
-	query := `
-		INSERT INTO user_actions (user_id, learning_id, action_type, metadata, deleted_at)
-		VALUES ($1, $2, 'dialogue_saved', '{}'::jsonb, NULL)
-		ON CONFLICT (learning_id, user_id, action_type)
-		DO UPDATE SET
-			action_type = 'dialogue_saved',
-			deleted_at = CASE
-				WHEN user_actions.action_type = 'dialogue_saved' AND user_actions.deleted_at IS NULL THEN NOW()
-				ELSE NULL
-			END,
-			updated_at = NOW()
-		RETURNING id, deleted_at IS NULL
-	`
-
-	var actionID string
+	var favoriteID string
 	var isSaved bool
-	if err := r.db.Pool.QueryRow(ctx, query, userID, dialogID).Scan(&actionID, &isSaved); err != nil {
-		return "", false, errors.InternalWrap("failed to toggle dialog saved action", err)
+	if err := r.db.Pool.QueryRow(ctx, client.ToggleFavoriteSQL, userID, dialogID, client.FavoriteDialog).Scan(&favoriteID, &isSaved); err != nil {
+		return "", false, errors.InternalWrap("failed to toggle dialog favorite", err)
 	}
 
-	return actionID, isSaved, nil
+	return favoriteID, isSaved, nil
 }
 
 func (r *dialogRepository) StartSpeech(ctx context.Context, dialogID, userID string, metadata json.RawMessage) (string, *errors.AppError) {
@@ -373,8 +871,13 @@ func (r *dialogRepository) StartSpeech(ctx context.Context, dialogID, userID str
 		RETURNING id
 	`
 
+	sealed, appErr := r.sealMetadata("submit_speech", metadata)
+	if appErr != nil {
+		return "", appErr
+	}
+
 	var actionID string
-	if err := r.db.Pool.QueryRow(ctx, query, userID, dialogID, metadata).Scan(&actionID); err != nil {
+	if err := r.db.Pool.QueryRow(ctx, query, userID, dialogID, sealed).Scan(&actionID); err != nil {
 		return "", errors.InternalWrap("failed to start speech action", err)
 	}
 
@@ -408,7 +911,12 @@ func (r *dialogRepository) SubmitSpeechAction(ctx context.Context, actionID, use
 		WHERE id = $2 AND user_id = $3 AND action_type = 'submit_speech'
 	`
 
-	cmdTag, err := r.db.Pool.Exec(ctx, query, metadataJSON, actionID, userID)
+	sealed, appErr := r.sealMetadata("submit_speech", metadataJSON)
+	if appErr != nil {
+		return appErr
+	}
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, sealed, actionID, userID)
 	if err != nil {
 		return errors.InternalWrap("failed to submit speech action", err)
 	}
@@ -462,3 +970,109 @@ func (r *dialogRepository) UpdateChatAction(ctx context.Context, actionID, userI
 
 	return nil
 }
+
+func (r *dialogRepository) StartSparring(ctx context.Context, dialogID, userID string, metadata json.RawMessage) (string, *errors.AppError) {
+	query := `
+		INSERT INTO user_actions (user_id, learning_id, action_type, metadata, deleted_at)
+		VALUES ($1, $2, 'submit_sparring', $3, NULL)
+		ON CONFLICT (learning_id, user_id, action_type)
+		DO UPDATE SET
+			metadata = EXCLUDED.metadata,
+			deleted_at = NULL,
+			updated_at = NOW()
+		RETURNING id
+	`
+
+	sealed, appErr := r.sealMetadata("submit_sparring", metadata)
+	if appErr != nil {
+		return "", appErr
+	}
+
+	var actionID string
+	if err := r.db.Pool.QueryRow(ctx, query, userID, dialogID, sealed).Scan(&actionID); err != nil {
+		return "", errors.InternalWrap("failed to start sparring action", err)
+	}
+
+	return actionID, nil
+}
+
+func (r *dialogRepository) UpdateSparringAction(ctx context.Context, actionID, userID string, metadataJSON []byte) *errors.AppError {
+	query := `
+		UPDATE user_actions
+		SET metadata = $1, updated_at = NOW()
+		WHERE id = $2 AND user_id = $3 AND action_type = 'submit_sparring'
+	`
+
+	sealed, appErr := r.sealMetadata("submit_sparring", metadataJSON)
+	if appErr != nil {
+		return appErr
+	}
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, sealed, actionID, userID)
+	if err != nil {
+		return errors.InternalWrap("failed to update sparring action", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.NotFound("sparring action not found or unauthorized")
+	}
+
+	return nil
+}
+
+// SetAudience changes who may see a dialog (client.AudienceGeneral or client.AudienceAdult).
+func (r *dialogRepository) SetAudience(ctx context.Context, dialogID, audience string) *errors.AppError {
+	query := `
+		UPDATE learning_items
+		SET audience = $1, updated_at = NOW()
+		WHERE id = $2 AND feature_id = $3
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, audience, dialogID, FeatureID)
+	if err != nil {
+		return errors.InternalWrap("failed to set dialog audience", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.NotFound("dialog content not found")
+	}
+
+	return nil
+}
+
+// IsMinor reports whether the user is under client.AdultAge. Users without a birth date are not.
+func (r *dialogRepository) IsMinor(ctx context.Context, userID string) (bool, *errors.AppError) {
+	var minor bool
+	if err := r.db.Pool.QueryRow(ctx, client.IsMinorSQL("$1"), userID).Scan(&minor); err != nil {
+		return false, errors.InternalWrap("failed to check learner age", err)
+	}
+	return minor, nil
+}
+
+// ListUpheldReportReasons returns the reasons of dialog reports upheld by moderators since the given time.
+func (r *dialogRepository) ListUpheldReportReasons(ctx context.Context, since time.Time) ([]string, *errors.AppError) {
+	query := `
+		SELECT DISTINCT reason
+		FROM content_reports
+		WHERE feature_id = $1 AND status = 'upheld' AND resolved_at >= $2
+		ORDER BY reason
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, FeatureID, since)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list upheld report reasons", err)
+	}
+	defer rows.Close()
+
+	reasons := make([]string, 0)
+	for rows.Next() {
+		var reason string
+		if err := rows.Scan(&reason); err != nil {
+			return nil, errors.InternalWrap("failed to scan report reason", err)
+		}
+		reasons = append(reasons, reason)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list upheld report reasons", err)
+	}
+
+	return reasons, nil
+}
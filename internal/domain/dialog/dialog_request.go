@@ -1,8 +1,8 @@
 package dialog
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/internal/infra/middleware"
 	"github.com/windfall/uwu_service/pkg/errors"
 )
@@ -22,12 +23,13 @@ import (
 
 // GenerateDialogRequest is the HTTP request struct for generating a dialog
 type GenerateDialogRequest struct {
-	UserID      string   `json:"user_id"`
+	UserID      string   `json:"-"`
 	Topic       string   `json:"topic"`
 	Description string   `json:"description"`
 	Language    string   `json:"language"`
 	Level       string   `json:"level"`
 	Tags        []string `json:"tags"`
+	ImageStyle  string   `json:"image_style"`
 }
 
 // GenerateDialogPayload is the payload struct for service
@@ -39,8 +41,28 @@ type GenerateDialogPayload struct {
 	Language    string
 	Level       string
 	Tags        []string
+	// Style preset of the image ("" uses IMAGE_STYLE_DEFAULT)
+	ImageStyle string
+
+	// Set when adapting an existing dialog to another level
+	ParentID  string
+	Situation string
+	ImageURL  string
+
+	// Set by the worker from the learner's birth date; minors only get general-audience scenarios
+	Minor bool
+	// Set by the worker from recently upheld content reports
+	ReportReasons []string
 }
 
+// Limits on the learner-supplied fields of a generation request
+const (
+	maxTopicLength       = 200
+	maxDescriptionLength = 1000
+	maxTags              = 10
+	maxTagLength         = 50
+)
+
 // AllowedLanguages
 var AllowedLanguages = map[string]bool{
 	"english":    true,
@@ -63,14 +85,29 @@ func (req *GenerateDialogRequest) ParseAndValidate(r *http.Request) error {
 
 	// 2. parse request body
 	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+	if err := middleware.DecodeJSON(r, req); err != nil {
 		return errors.Validation("invalid request body")
 	}
 
-	// 3. เช็ก topic
+	// 3. เช็ก topic / description / tags (they end up in the prompt)
+	req.Topic = strings.TrimSpace(req.Topic)
 	if req.Topic == "" {
 		return errors.Validation("topic is required")
 	}
+	if len([]rune(req.Topic)) > maxTopicLength {
+		return errors.Validation(fmt.Sprintf("topic must be at most %d characters", maxTopicLength))
+	}
+	if len([]rune(req.Description)) > maxDescriptionLength {
+		return errors.Validation(fmt.Sprintf("description must be at most %d characters", maxDescriptionLength))
+	}
+	if len(req.Tags) > maxTags {
+		return errors.Validation(fmt.Sprintf("at most %d tags are allowed", maxTags))
+	}
+	for _, tag := range req.Tags {
+		if len([]rune(tag)) > maxTagLength {
+			return errors.Validation(fmt.Sprintf("tags must be at most %d characters", maxTagLength))
+		}
+	}
 
 	// 4. เช็กภาษา
 	req.Language = strings.ToLower(req.Language)
@@ -83,6 +120,12 @@ func (req *GenerateDialogRequest) ParseAndValidate(r *http.Request) error {
 		return errors.Validation("level is required")
 	}
 
+	// 6. เช็ก image style
+	req.ImageStyle = client.ImageStyleName(req.ImageStyle)
+	if req.ImageStyle != "" && !client.IsImageStyle(req.ImageStyle) {
+		return errors.Validation("image_style must be photorealistic, vector, watercolor or kid_friendly")
+	}
+
 	return nil
 }
 
@@ -98,6 +141,7 @@ func (req *GenerateDialogRequest) ToPayload() GenerateDialogPayload {
 		Language:    req.Language,
 		Level:       req.Level,
 		Tags:        req.Tags,
+		ImageStyle:  req.ImageStyle,
 	}
 }
 
@@ -107,12 +151,14 @@ func (req *GenerateDialogRequest) ToPayload() GenerateDialogPayload {
 
 // ListDialogContentsRequest is the HTTP request struct for listing dialog contents
 type ListDialogContentsRequest struct {
+	UserID   string
 	Page     int
 	PageSize int
 }
 
 // ListDialogContentsInput is the input struct for service
 type ListDialogContentsInput struct {
+	UserID   string
 	Page     int
 	PageSize int
 	Limit    int
@@ -121,6 +167,8 @@ type ListDialogContentsInput struct {
 
 // Parse parse pagination params
 func (req *ListDialogContentsRequest) Parse(r *http.Request) {
+	req.UserID = middleware.GetUserID(r.Context())
+
 	pageStr := r.URL.Query().Get("page")
 	pageSizeStr := r.URL.Query().Get("page_size")
 
@@ -144,6 +192,7 @@ func (req *ListDialogContentsRequest) ToInput() ListDialogContentsInput {
 	offset := (req.Page - 1) * req.PageSize
 
 	return ListDialogContentsInput{
+		UserID:   req.UserID,
 		Page:     req.Page,
 		PageSize: req.PageSize,
 		Limit:    limit,
@@ -290,7 +339,7 @@ func (req *SubmitChatRequest) ParseAndValidate(r *http.Request) error {
 
 	// 3. Parse JSON Body
 	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+	if err := middleware.DecodeJSON(r, req); err != nil {
 		return errors.Validation("invalid request body")
 	}
 
@@ -319,3 +368,557 @@ func (req *SubmitChatRequest) ToPayload() ReplyChatMessagePayload {
 		Message:  req.Message,
 	}
 }
+
+// -------------------------------------------------------------------------
+// Regenerate Script Turn Request
+// -------------------------------------------------------------------------
+
+// RegenerateTurnRequest is the HTTP request struct for regenerating one speech script turn
+type RegenerateTurnRequest struct {
+	UserID      string `json:"-"`
+	DialogID    string `json:"-"`
+	Index       int    `json:"-"`
+	Async       bool   `json:"-"`
+	Instruction string `json:"instruction"`
+}
+
+// RegenerateTurnInput is the input struct for service
+type RegenerateTurnInput struct {
+	UserID      string
+	DialogID    string
+	Index       int
+	Instruction string
+}
+
+func (req *RegenerateTurnRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+
+	idx, err := parseTurnIndex(r)
+	if err != nil {
+		return err
+	}
+	req.Index = idx
+
+	// 3. Parse query (async=true runs the regeneration on the job queue)
+	if asyncStr := r.URL.Query().Get("async"); asyncStr != "" {
+		async, err := strconv.ParseBool(asyncStr)
+		if err != nil {
+			return errors.Validation("async must be true or false")
+		}
+		req.Async = async
+	}
+
+	// 4. Parse JSON Body (optional)
+	defer r.Body.Close()
+	if err := middleware.DecodeJSON(r, req); err != nil && err != io.EOF {
+		return errors.Validation("invalid request body")
+	}
+
+	req.Instruction = strings.TrimSpace(req.Instruction)
+	if len([]rune(req.Instruction)) > 500 {
+		return errors.Validation("instruction must be at most 500 characters")
+	}
+
+	return nil
+}
+
+// ToInput convert RegenerateTurnRequest to RegenerateTurnInput
+func (req *RegenerateTurnRequest) ToInput() RegenerateTurnInput {
+	return RegenerateTurnInput{
+		UserID:      req.UserID,
+		DialogID:    req.DialogID,
+		Index:       req.Index,
+		Instruction: req.Instruction,
+	}
+}
+
+// RegenerateTurnPayload is the job payload for regenerating a turn in the background
+type RegenerateTurnPayload struct {
+	BatchID string
+	Input   RegenerateTurnInput
+}
+
+// RepairMediaPayload is the job payload for regenerating the missing or broken media of a dialog
+type RepairMediaPayload struct {
+	DialogID    string
+	Image       bool
+	Audio       bool
+	ScriptTurns []int // AI turns whose audio is regenerated
+
+	// Set when retrying a failed job of a generation batch; the repair reports to its jobs
+	BatchID string
+	Scripts bool // the script audio jobs are retried (even with no turn left to regenerate)
+}
+
+// GetDialogBatchRequest is the HTTP request struct for polling a dialog batch
+type GetDialogBatchRequest struct {
+	UserID   string
+	DialogID string
+	BatchID  string
+}
+
+func (req *GetDialogBatchRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+
+	req.BatchID = chi.URLParam(r, "batchID")
+	if req.BatchID == "" {
+		return errors.Validation("Batch ID is required")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Retry Batch Job Request
+// -------------------------------------------------------------------------
+
+// RetryBatchJobRequest is the HTTP request struct for retrying a failed job of a batch
+type RetryBatchJobRequest struct {
+	UserID  string
+	BatchID string
+	JobName string
+}
+
+// RetryBatchJobInput is the input struct for service
+type RetryBatchJobInput struct {
+	UserID  string
+	BatchID string
+	JobName string
+}
+
+func (req *RetryBatchJobRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.BatchID = chi.URLParam(r, "batchID")
+	if req.BatchID == "" {
+		return errors.Validation("Batch ID is required")
+	}
+
+	req.JobName = chi.URLParam(r, "jobName")
+	if req.JobName == "" {
+		return errors.Validation("Job name is required")
+	}
+
+	return nil
+}
+
+// ToInput convert RetryBatchJobRequest to RetryBatchJobInput
+func (req *RetryBatchJobRequest) ToInput() RetryBatchJobInput {
+	return RetryBatchJobInput{
+		UserID:  req.UserID,
+		BatchID: req.BatchID,
+		JobName: req.JobName,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Adapt Dialog Request
+// -------------------------------------------------------------------------
+
+// AdaptDialogRequest is the HTTP request struct for adapting a dialog to another level
+type AdaptDialogRequest struct {
+	UserID   string
+	DialogID string
+	Level    string
+}
+
+// AdaptDialogInput is the input struct for service
+type AdaptDialogInput struct {
+	UserID   string
+	DialogID string
+	Level    string
+}
+
+func (req *AdaptDialogRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+
+	// 3. Parse target level
+	req.Level = strings.TrimSpace(r.URL.Query().Get("level"))
+	if req.Level == "" {
+		return errors.Validation("level is required")
+	}
+
+	return nil
+}
+
+// ToInput convert AdaptDialogRequest to AdaptDialogInput
+func (req *AdaptDialogRequest) ToInput() AdaptDialogInput {
+	return AdaptDialogInput{
+		UserID:   req.UserID,
+		DialogID: req.DialogID,
+		Level:    req.Level,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Sparring Turn Request
+// -------------------------------------------------------------------------
+
+// SparringTurnRequest is the HTTP request struct for a live sparring turn.
+// The utterance is either JSON {"message", "end"} or multipart with an "audio" file (and optional "end").
+type SparringTurnRequest struct {
+	UserID    string         `json:"-"`
+	DialogID  string         `json:"-"`
+	Message   string         `json:"message"`
+	End       bool           `json:"end"`
+	AudioFile multipart.File `json:"-"`
+}
+
+// SparringTurnInput is the input struct for service
+type SparringTurnInput struct {
+	UserID       string
+	DialogID     string
+	Message      string
+	End          bool
+	AudioFile    multipart.File
+	AudioWavPath string
+}
+
+func (req *SparringTurnRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+
+	// 3. Parse utterance (audio upload or JSON text)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		const maxUploadSize = 10 << 20
+		if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+			return errors.Validation("file too large or invalid multipart data")
+		}
+		req.End, _ = strconv.ParseBool(r.FormValue("end"))
+
+		aFile, _, err := r.FormFile("audio")
+		if err != nil {
+			if req.End {
+				return nil
+			}
+			return errors.Validation("audio file is required (form field: 'audio')")
+		}
+		req.AudioFile = aFile
+		return nil
+	}
+
+	defer r.Body.Close()
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid request body")
+	}
+
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" && !req.End {
+		return errors.Validation("message is required")
+	}
+
+	return nil
+}
+
+// ToInput convert SparringTurnRequest to SparringTurnInput
+func (req *SparringTurnRequest) ToInput() SparringTurnInput {
+	return SparringTurnInput{
+		UserID:       req.UserID,
+		DialogID:     req.DialogID,
+		Message:      req.Message,
+		End:          req.End,
+		AudioFile:    req.AudioFile,
+		AudioWavPath: filepath.Join(os.TempDir(), fmt.Sprintf("%s.wav", uuid.New().String())),
+	}
+}
+
+// parseTurnIndex reads the {turnIndex} URL param.
+func parseTurnIndex(r *http.Request) (int, *errors.AppError) {
+	idx, err := strconv.Atoi(chi.URLParam(r, "turnIndex"))
+	if err != nil || idx < 0 {
+		return 0, errors.Validation("invalid turn index")
+	}
+	return idx, nil
+}
+
+// -------------------------------------------------------------------------
+// Check Turn Blanks Request
+// -------------------------------------------------------------------------
+
+// CheckBlanksRequest is the HTTP request struct for checking a learner's fill-ins
+type CheckBlanksRequest struct {
+	UserID   string   `json:"-"`
+	DialogID string   `json:"-"`
+	Index    int      `json:"-"`
+	Words    []string `json:"words"`
+}
+
+// CheckBlanksInput is the input struct for service
+type CheckBlanksInput struct {
+	UserID   string
+	DialogID string
+	Index    int
+	Words    []string
+}
+
+func (req *CheckBlanksRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+	idx, err := parseTurnIndex(r)
+	if err != nil {
+		return err
+	}
+	req.Index = idx
+
+	// 3. Parse JSON Body
+	defer r.Body.Close()
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid request body")
+	}
+	if len(req.Words) == 0 {
+		return errors.Validation("words are required")
+	}
+
+	return nil
+}
+
+// ToInput convert CheckBlanksRequest to CheckBlanksInput
+func (req *CheckBlanksRequest) ToInput() CheckBlanksInput {
+	return CheckBlanksInput{
+		UserID:   req.UserID,
+		DialogID: req.DialogID,
+		Index:    req.Index,
+		Words:    req.Words,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Turn Hint Request
+// -------------------------------------------------------------------------
+
+// TurnHintRequest is the HTTP request struct for getting a progressive hint
+type TurnHintRequest struct {
+	UserID   string
+	DialogID string
+	Index    int
+	Step     int
+}
+
+// TurnHintInput is the input struct for service
+type TurnHintInput struct {
+	UserID   string
+	DialogID string
+	Index    int
+	Step     int
+}
+
+func (req *TurnHintRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+	idx, err := parseTurnIndex(r)
+	if err != nil {
+		return err
+	}
+	req.Index = idx
+
+	// 3. Parse hint step (0 = first hint)
+	if stepStr := r.URL.Query().Get("step"); stepStr != "" {
+		step, err := strconv.Atoi(stepStr)
+		if err != nil || step < 0 {
+			return errors.Validation("invalid step")
+		}
+		req.Step = step
+	}
+
+	return nil
+}
+
+// ToInput convert TurnHintRequest to TurnHintInput
+func (req *TurnHintRequest) ToInput() TurnHintInput {
+	return TurnHintInput{
+		UserID:   req.UserID,
+		DialogID: req.DialogID,
+		Index:    req.Index,
+		Step:     req.Step,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Set Audience Request (admin)
+// -------------------------------------------------------------------------
+
+// SetAudienceRequest is the HTTP request struct for reclassifying a dialog's audience
+type SetAudienceRequest struct {
+	DialogID string `json:"-"`
+	Audience string `json:"audience"`
+}
+
+func (req *SetAudienceRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if _, err := uuid.Parse(req.DialogID); err != nil {
+		return errors.Validation("invalid dialog id")
+	}
+
+	// 2. Parse JSON Body
+	defer r.Body.Close()
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid request body")
+	}
+	req.Audience = strings.ToLower(strings.TrimSpace(req.Audience))
+	if !client.IsAudience(req.Audience) {
+		return errors.Validation("audience must be general or adult")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Fix Dialog Requests
+// -------------------------------------------------------------------------
+
+// maxFixNoteLength bounds the editor's description of the mistake.
+const maxFixNoteLength = 500
+
+// FixDialogRequest is the HTTP request struct for proposing an AI fix of a dialog
+type FixDialogRequest struct {
+	UserID   string `json:"-"`
+	DialogID string `json:"-"`
+	Note     string `json:"note"`
+}
+
+// FixDialogInput is the input struct for service
+type FixDialogInput struct {
+	UserID   string
+	DialogID string
+	Note     string
+}
+
+func (req *FixDialogRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+
+	// 3. Parse JSON Body
+	defer r.Body.Close()
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid request body")
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" {
+		return errors.Validation("note is required")
+	}
+	if len([]rune(req.Note)) > maxFixNoteLength {
+		return errors.Validation("note must be at most 500 characters")
+	}
+
+	return nil
+}
+
+// ToInput convert FixDialogRequest to FixDialogInput
+func (req *FixDialogRequest) ToInput() FixDialogInput {
+	return FixDialogInput{
+		UserID:   req.UserID,
+		DialogID: req.DialogID,
+		Note:     req.Note,
+	}
+}
+
+// ApplyFixRequest is the HTTP request struct for applying a proposed fix
+type ApplyFixRequest struct {
+	UserID   string
+	DialogID string
+	FixID    string
+}
+
+// ApplyFixInput is the input struct for service
+type ApplyFixInput struct {
+	UserID   string
+	DialogID string
+	FixID    string
+}
+
+func (req *ApplyFixRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.DialogID = chi.URLParam(r, "dialogID")
+	if req.DialogID == "" {
+		return errors.Validation("Dialog ID is required")
+	}
+	req.FixID = chi.URLParam(r, "fixID")
+	if _, err := uuid.Parse(req.FixID); err != nil {
+		return errors.Validation("invalid fix id")
+	}
+
+	return nil
+}
+
+// ToInput convert ApplyFixRequest to ApplyFixInput
+func (req *ApplyFixRequest) ToInput() ApplyFixInput {
+	return ApplyFixInput{
+		UserID:   req.UserID,
+		DialogID: req.DialogID,
+		FixID:    req.FixID,
+	}
+}
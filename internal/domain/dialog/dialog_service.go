@@ -2,14 +2,19 @@ package dialog
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
 	"github.com/windfall/uwu_service/pkg/response"
 )
@@ -22,6 +27,18 @@ type DialogService struct {
 	audioRepo  AudioRepository
 	fileRepo   FileRepository
 	batchRepo  BatchRepository
+	memoryRepo MemoryRepository
+	timeouts   client.TimeoutPolicy
+	media      client.MediaVariants
+	fixRepo    FixRepository
+	// Images shared by dialogs with the same image prompt (nil generates every image)
+	imageRegistry ImageRegistry
+	// Critic score below which a generated dialog waits for review (0 skips the critic)
+	qualityThreshold int
+	// Regenerations of an image OCR finds text in (0 skips the check)
+	imageTextRetries int
+	// Practice sessions idle longer than this start over on resume (0 never expires)
+	sessionIdleTTL time.Duration
 }
 
 // DialogDetailsResponse is returned for dialog details
@@ -59,8 +76,97 @@ type SpeechMetadata struct {
 	SituationText     string         `json:"situation_text"`
 	SituationAudioURL string         `json:"situation_audio_url"`
 	Scripts           []SpeechScript `json:"scripts"`
-	// To implement later
-	Attempts [][]SpeechScript `json:"attempts"`
+	// Turn is the script index the learner continues from
+	Turn int `json:"turn"`
+	// Earlier runs, archived when an idle session started over
+	Attempts []SpeechAttempt `json:"attempts"`
+}
+
+// SpeechAttempt is an archived run of a speech practice session.
+type SpeechAttempt struct {
+	Scripts    []SpeechScript `json:"scripts"`
+	ArchivedAt time.Time      `json:"archived_at"`
+}
+
+// PracticeScores are the averages of the evaluated speech turns.
+type PracticeScores struct {
+	Accuracy      float64 `json:"accuracy"`
+	Fluency       float64 `json:"fluency"`
+	Pronunciation float64 `json:"pronunciation"`
+	Completeness  float64 `json:"completeness"`
+}
+
+// SpeechSession is where the learner left off in speech practice.
+type SpeechSession struct {
+	ActionID       string          `json:"action_id"`
+	Turn           int             `json:"turn"`
+	TotalTurns     int             `json:"total_turns"`
+	EvaluatedTurns int             `json:"evaluated_turns"`
+	Scores         *PracticeScores `json:"scores"`
+	Expired        bool            `json:"expired"` // idle too long; the run was archived and starts over
+	LastActiveAt   time.Time       `json:"last_active_at"`
+	Metadata       *SpeechMetadata `json:"metadata"`
+}
+
+// SparringSession is where the learner left off in a sparring session.
+type SparringSession struct {
+	ActionID     string            `json:"action_id"`
+	Turns        int               `json:"turns"`
+	Status       string            `json:"status"`
+	Progress     *SparringReport   `json:"progress"`
+	Expired      bool              `json:"expired"` // idle too long; the session was ended with its report
+	LastActiveAt time.Time         `json:"last_active_at"`
+	Metadata     *SparringMetadata `json:"metadata"`
+}
+
+// PracticeSessionResponse is returned when resuming practice on a dialog.
+type PracticeSessionResponse struct {
+	DialogID string           `json:"dialog_id"`
+	Speech   *SpeechSession   `json:"speech"`
+	Sparring *SparringSession `json:"sparring"`
+}
+
+// RegenerateTurnResponse is returned after regenerating a speech script turn.
+type RegenerateTurnResponse struct {
+	DialogID string       `json:"dialog_id"`
+	Index    int          `json:"index"`
+	Turn     SpeechScript `json:"turn"`
+}
+
+// ApplyFixResponse is returned after applying a proposed fix.
+type ApplyFixResponse struct {
+	DialogID string      `json:"dialog_id"`
+	Revision int         `json:"revision"`
+	Changes  []FixChange `json:"changes"`
+}
+
+// Fill-in results
+const (
+	BLANK_CORRECT   = "correct"
+	BLANK_CLOSE     = "close"     // accepted, with a small spelling slip
+	BLANK_MISPLACED = "misplaced" // right word, wrong blank
+	BLANK_INCORRECT = "incorrect"
+)
+
+// BlankResult is the grade of one blank; it never includes the expected word.
+type BlankResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+}
+
+// CheckBlanksResponse is returned after checking a learner's fill-ins.
+type CheckBlanksResponse struct {
+	Results    []BlankResult `json:"results"`
+	Correct    int           `json:"correct"`
+	Total      int           `json:"total"`
+	AllCorrect bool          `json:"all_correct"`
+}
+
+// TurnHintResponse is one progressive hint for a turn's blanks.
+type TurnHintResponse struct {
+	Step      int    `json:"step"`
+	Hint      string `json:"hint"`
+	Remaining int    `json:"remaining"`
 }
 
 // ChatMetadata is the structure stored in user_actions.metadata for chat actions.
@@ -78,6 +184,54 @@ type ChatMessage struct {
 	Suggestion string `json:"suggestion"`
 }
 
+// Sparring session states
+const (
+	SPARRING_ACTIVE = "active"
+	SPARRING_ENDED  = "ended"
+)
+
+// maxSparringTurns ends a sparring session after this many learner turns.
+const maxSparringTurns = 20
+
+// SparringMetadata is the structure stored in user_actions.metadata for sparring sessions.
+type SparringMetadata struct {
+	SituationText       string            `json:"situation_text"`
+	ChatObjective       ChatObjective     `json:"chat_objective"`
+	Messages            []SparringMessage `json:"messages"`
+	CompletedObjectives []string          `json:"completed_objectives"`
+	Status              string            `json:"status"`
+	Report              *SparringReport   `json:"report,omitempty"`
+}
+
+// SparringMessage is one turn of a sparring session.
+type SparringMessage struct {
+	Role       string  `json:"role"`
+	Content    string  `json:"content"`
+	AudioURL   *string `json:"audio_url,omitempty"`
+	Suggestion string  `json:"suggestion,omitempty"`
+}
+
+// SparringReport summarises a finished sparring session.
+type SparringReport struct {
+	Turns               int      `json:"turns"`
+	CompletedObjectives []string `json:"completed_objectives"`
+	MissedObjectives    []string `json:"missed_objectives"`
+	CompletionRate      float64  `json:"completion_rate"` // percent of requirements completed
+	Suggestions         []string `json:"suggestions"`
+}
+
+// SparringTurnResponse is returned after each sparring turn.
+type SparringTurnResponse struct {
+	ActionID            string          `json:"action_id"`
+	Transcript          string          `json:"transcript,omitempty"`
+	Reply               string          `json:"reply,omitempty"`
+	ReplyAudioURL       *string         `json:"reply_audio_url,omitempty"`
+	Suggestion          string          `json:"suggestion,omitempty"`
+	CompletedObjectives []string        `json:"completed_objectives"`
+	Status              string          `json:"status"`
+	Report              *SparringReport `json:"report,omitempty"`
+}
+
 // NewDialogService creates a new DialogService.
 func NewDialogService(
 	dialogRepo DialogRepository,
@@ -86,6 +240,14 @@ func NewDialogService(
 	audioRepo AudioRepository,
 	fileRepo FileRepository,
 	batchRepo BatchRepository,
+	memoryRepo MemoryRepository,
+	timeouts client.TimeoutPolicy,
+	media client.MediaVariants,
+	fixRepo FixRepository,
+	imageRegistry ImageRegistry,
+	qualityThreshold int,
+	imageTextRetries int,
+	sessionIdleTTL time.Duration,
 ) *DialogService {
 	return &DialogService{
 		dialogRepo: dialogRepo,
@@ -94,13 +256,22 @@ func NewDialogService(
 		audioRepo:  audioRepo,
 		fileRepo:   fileRepo,
 		batchRepo:  batchRepo,
+		memoryRepo: memoryRepo,
+		timeouts:   timeouts,
+		media:      media,
+		fixRepo:    fixRepo,
+
+		imageRegistry:    imageRegistry,
+		qualityThreshold: qualityThreshold,
+		imageTextRetries: imageTextRetries,
+		sessionIdleTTL:   sessionIdleTTL,
 	}
 }
 
 // List Dialog Contents
 func (s *DialogService) ListDialogContents(ctx context.Context, input ListDialogContentsInput) (*ListDialogContentsResponse, *errors.AppError) {
 	// 1. Get dialog contents from database
-	dialogs, total, err := s.dialogRepo.ListDialogs(ctx, input.Limit, input.Offset)
+	dialogs, total, err := s.dialogRepo.ListDialogs(ctx, input.UserID, input.Limit, input.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -126,8 +297,8 @@ func (s *DialogService) ListDialogContents(ctx context.Context, input ListDialog
 
 // Get Dialog Details
 func (s *DialogService) GetDialogDetails(ctx context.Context, dialogID, userID string) (*DialogDetailsResponse, *errors.AppError) {
-	// Get dialog from database
-	learningItem, err := s.dialogRepo.GetDialog(ctx, dialogID, userID)
+	// Get dialog and its key vocabulary from database
+	learningItem, err := s.dialogRepo.GetDialogDetails(ctx, dialogID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -167,9 +338,13 @@ func (s *DialogService) CreateDialogContent(ctx context.Context, input GenerateD
 		return nil, err
 	}
 
+	// Kept so a failed generation can be retried (see PrepareRetryBatchJob)
+	_ = s.batchRepo.SetBatchInput(ctx, input.DialogID, input)
+
 	metadataJSON, _ := json.Marshal(batchProcessing)
 	learningItem := &LearningItem{
 		ID:        uuid.Must(uuid.Parse(input.DialogID)),
+		ParentID:  parseParentID(input.ParentID),
 		Content:   input.Topic,
 		Language:  input.Language,
 		Level:     input.Level,
@@ -190,15 +365,170 @@ func (s *DialogService) CreateDialogContent(ctx context.Context, input GenerateD
 	}, nil
 }
 
-// Worker: ProcessGenerateDialog handles the background generation flow for dialogs.
-func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload GenerateDialogPayload) {
+// PublishDialog makes the current draft the version learners see.
+func (s *DialogService) PublishDialog(ctx context.Context, dialogID, userID string) (*LearningItem, *errors.AppError) {
+	learningItem, err := s.dialogRepo.GetDialog(ctx, dialogID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != userID {
+		return nil, errors.Forbidden("only the dialog owner can publish it")
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+	if len(details.SpeechMode.Script) == 0 {
+		return nil, errors.Conflict("dialog is still being generated")
+	}
+	switch learningItem.ReviewStatus {
+	case REVIEW_PENDING:
+		return nil, errors.Conflict("dialog is waiting for quality review")
+	case REVIEW_REJECTED:
+		return nil, errors.Conflict("dialog was rejected in quality review")
+	}
+
+	keyVocab, err := s.findKeyVocab(ctx, learningItem.Language, details.SpeechMode.Script)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.dialogRepo.PublishDialog(ctx, dialogID, keyVocab); err != nil {
+		return nil, err
+	}
+
+	return s.dialogRepo.GetDialog(ctx, dialogID, userID)
+}
+
+// maxKeyVocab caps the vocabulary links recorded for one dialog
+const maxKeyVocab = 200
+
+// findKeyVocab returns the video vocabulary terms of the language used in each script turn.
+func (s *DialogService) findKeyVocab(ctx context.Context, language string, script []SpeechScript) ([]KeyVocab, *errors.AppError) {
+	terms, err := s.dialogRepo.ListVocabularyTerms(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chinese and Japanese have no spaces and Korean attaches particles, so whole-word matching does not apply
+	wholeWords := client.RomanizationLanguage(language) == ""
+	keyVocab := make([]KeyVocab, 0)
+	for index, turn := range script {
+		text := strings.ToLower(turn.Text)
+		for _, term := range terms {
+			if !containsTerm(text, strings.ToLower(strings.TrimSpace(term.Text)), wholeWords) {
+				continue
+			}
+			keyVocab = append(keyVocab, KeyVocab{ItemID: term.ItemID, Term: strings.TrimSpace(term.Text), TurnIndex: index})
+			if len(keyVocab) == maxKeyVocab {
+				return keyVocab, nil
+			}
+		}
+	}
+	return keyVocab, nil
+}
+
+// containsTerm reports whether text contains term, only as whole words when wholeWords is set.
+func containsTerm(text, term string, wholeWords bool) bool {
+	if term == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		if !wholeWords {
+			return true
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !unicode.IsLetter(before)) && (end == len(text) || !unicode.IsLetter(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// UnpublishDialog hides the dialog from learners, keeping the draft.
+func (s *DialogService) UnpublishDialog(ctx context.Context, dialogID, userID string) (*LearningItem, *errors.AppError) {
+	learningItem, err := s.dialogRepo.GetDialog(ctx, dialogID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != userID {
+		return nil, errors.Forbidden("only the dialog owner can unpublish it")
+	}
+
+	if err := s.dialogRepo.UnpublishDialog(ctx, dialogID); err != nil {
+		return nil, err
+	}
+
+	return s.dialogRepo.GetDialog(ctx, dialogID, userID)
+}
+
+// PrepareAdaptDialog builds the generation payload for a variant of an existing dialog at another level.
+// The variant keeps the original situation and image and links back to it through parent_id.
+func (s *DialogService) PrepareAdaptDialog(ctx context.Context, input AdaptDialogInput) (GenerateDialogPayload, *errors.AppError) {
+	parent, err := s.dialogRepo.GetDialog(ctx, input.DialogID, input.UserID)
+	if err != nil {
+		return GenerateDialogPayload{}, err
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(parent.Details, &details); err != nil {
+		return GenerateDialogPayload{}, errors.InternalWrap("failed to parse dialog details", err)
+	}
+	if len(details.SpeechMode.Script) == 0 {
+		return GenerateDialogPayload{}, errors.Conflict("dialog is still being generated")
+	}
+	if strings.EqualFold(input.Level, parent.Level) {
+		return GenerateDialogPayload{}, errors.Validation("level must differ from the original dialog level")
+	}
+
+	return GenerateDialogPayload{
+		DialogID:    uuid.New().String(),
+		UserID:      input.UserID,
+		Topic:       details.Topic,
+		Description: details.Description,
+		Language:    details.Language,
+		Level:       input.Level,
+		Tags:        details.Tags,
+		ParentID:    parent.ID.String(),
+		Situation:   details.SpeechMode.Situation,
+		ImageURL:    details.ImageURL,
+		ImageStyle:  details.ImageStyle,
+	}, nil
+}
+
+// Worker: ProcessGenerateDialog handles the background generation flow for dialogs. It returns an
+// error when the dialog could not be generated or saved, so the queue runs it again; failed media
+// jobs are left to the retry endpoint.
+func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload GenerateDialogPayload) *errors.AppError {
 	_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_DIALOG, BATCH_PROCESSING, "")
 
-	details, err := s.aiRepo.GenerateDialog(ctx, payload)
+	minor, err := s.dialogRepo.IsMinor(ctx, payload.UserID)
 	if err != nil {
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_DIALOG, BATCH_FAILED, err.GetMessage())
 		s.failRemainingMediaJobs(ctx, payload.DialogID, "skipped: dialogue generation failed")
-		return
+		return err
+	}
+	payload.Minor = minor
+
+	// Upheld reports adjust the prompt; generation goes on without them if they cannot be read
+	reasons, err := s.dialogRepo.ListUpheldReportReasons(ctx, time.Now().Add(-client.ModerationGuidanceWindow))
+	if err == nil {
+		payload.ReportReasons = reasons
+	}
+
+	callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Chat)
+	details, err := s.aiRepo.GenerateDialog(callCtx, payload)
+	cancel()
+	if err != nil {
+		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_DIALOG, BATCH_FAILED, err.GetMessage())
+		s.failRemainingMediaJobs(ctx, payload.DialogID, "skipped: dialogue generation failed")
+		return err
 	}
 
 	_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_DIALOG, BATCH_COMPLETED, "")
@@ -208,7 +538,7 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 	situationText := speechModeMap.Situation
 	speechScripts := speechModeMap.Script
 
-	voice := voiceForDialogLanguage(details.Language)
+	voice := client.VoiceForLanguage(details.Language)
 
 	var imageURL string
 	var audioURL string
@@ -217,32 +547,90 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 	var scriptsHasError bool
 	var scriptsLastErr error
 	scriptsStarted := false
+	mediaVariants := response.MediaVariants{}
+	addVariants := func(url string, variants map[string]string) {
+		if len(variants) == 0 {
+			return
+		}
+		mediaMu.Lock()
+		mediaVariants[url] = variants
+		mediaMu.Unlock()
+	}
+
+	// Critic pass runs next to the media jobs; without a verdict the dialog is saved as usual
+	var review *QualityReview
+	if s.qualityThreshold > 0 {
+		// The script audio jobs write to the script, so the critic reads a copy
+		reviewed := *details
+		reviewed.SpeechMode.Script = slices.Clone(details.SpeechMode.Script)
+		mediaWg.Add(1)
+		client.TrackGo(ctx, "generate_dialog.review", func() {
+			defer mediaWg.Done()
+
+			callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Chat)
+			result, err := s.aiRepo.ReviewDialog(callCtx, &reviewed)
+			cancel()
+			if err == nil {
+				review = result
+			}
+		})
+	}
 
-	if details.ImagePrompt != "" && s.imageRepo != nil && s.fileRepo != nil {
+	if payload.ImageURL != "" {
+		// Adapted variants reuse the original image
+		imageURL = payload.ImageURL
+		details.ImageStyle = payload.ImageStyle
+		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_COMPLETED, "")
+		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_COMPLETED, "")
+	} else if details.ImagePrompt != "" && s.imageRepo != nil && s.fileRepo != nil {
+		details.ImageStyle = s.imageRepo.ResolveStyle(ctx, payload.ImageStyle)
 		mediaWg.Add(1)
-		go func() {
+		client.TrackGo(ctx, "generate_dialog.image", func() {
 			defer mediaWg.Done()
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_PROCESSING, "")
 
-			imageBytes, err := s.imageRepo.GenerateImage(ctx, details.ImagePrompt)
+			// Same prompt and style as an earlier dialog: share its image instead of drawing it again
+			promptHash := imagePromptHash(details.ImagePrompt, details.ImageStyle)
+			if s.imageRegistry != nil {
+				if reused, err := s.imageRegistry.FindImage(ctx, promptHash); err == nil && reused != nil {
+					imageURL = reused.ImageURL
+					addVariants(reused.ImageURL, reused.Variants)
+					_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_COMPLETED, "reused image of dialog "+reused.DialogID)
+					_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_COMPLETED, "")
+					return
+				}
+			}
+
+			imageBytes, note, err := s.generateTextFreeImage(ctx, details.ImagePrompt, details.ImageStyle)
 			if err != nil {
 				_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_FAILED, err.GetMessage())
 				_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_FAILED, "skipped: image generation failed")
 				return
 			}
 
-			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_COMPLETED, "")
+			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_COMPLETED, note)
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_PROCESSING, "")
 
-			url, err := s.fileRepo.UploadBytes(ctx, imageBytes, fmt.Sprintf("dialogs/%s/bg_image.png", payload.DialogID), "image/png")
+			imageKey := fmt.Sprintf("dialogs/%s/bg_image.png", payload.DialogID)
+			url, err := s.fileRepo.UploadBytes(ctx, imageBytes, imageKey, "image/png")
 			if err != nil {
 				_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_FAILED, err.GetMessage())
 				return
 			}
+			variants := s.fileRepo.UploadVariants(ctx, imageBytes, imageKey, s.media.Image)
+			addVariants(url, variants)
+			if s.imageRegistry != nil {
+				_ = s.imageRegistry.RegisterImage(ctx, &RegisteredImage{
+					PromptHash: promptHash,
+					ImageURL:   url,
+					Variants:   variants,
+					DialogID:   payload.DialogID,
+				})
+			}
 
 			imageURL = url
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_COMPLETED, "")
-		}()
+		})
 	} else {
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_IMAGE, BATCH_FAILED, "")
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_IMAGE, BATCH_FAILED, "")
@@ -250,11 +638,13 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 
 	if situationText != "" && s.audioRepo != nil && s.fileRepo != nil {
 		mediaWg.Add(1)
-		go func() {
+		client.TrackGo(ctx, "generate_dialog.audio", func() {
 			defer mediaWg.Done()
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_AUDIO, BATCH_PROCESSING, "")
 
-			audioBytes, err := s.audioRepo.Synthesize(ctx, situationText, voice)
+			callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Speech)
+			audioBytes, err := s.audioRepo.Synthesize(callCtx, situationText, voice)
+			cancel()
 			if err != nil {
 				_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_AUDIO, BATCH_FAILED, err.GetMessage())
 				_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_AUDIO, BATCH_FAILED, "skipped: audio generation failed")
@@ -264,15 +654,22 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_AUDIO, BATCH_COMPLETED, "")
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_AUDIO, BATCH_PROCESSING, "")
 
-			url, err := s.fileRepo.UploadBytes(ctx, audioBytes, fmt.Sprintf("dialogs/%s/situation_audio.mp3", payload.DialogID), "audio/mpeg")
+			// Normalize loudness before upload (keep the raw audio if ffmpeg fails)
+			if normalized, err := s.fileRepo.NormalizeAudioBytes(ctx, audioBytes, ".mp3"); err == nil {
+				audioBytes = normalized
+			}
+
+			audioKey := fmt.Sprintf("dialogs/%s/situation_audio.mp3", payload.DialogID)
+			url, err := s.fileRepo.UploadBytes(ctx, audioBytes, audioKey, "audio/mpeg")
 			if err != nil {
 				_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_AUDIO, BATCH_FAILED, err.GetMessage())
 				return
 			}
+			addVariants(url, s.fileRepo.UploadVariants(ctx, audioBytes, audioKey, s.media.Audio))
 
 			audioURL = url
 			_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_AUDIO, BATCH_COMPLETED, "")
-		}()
+		})
 	} else {
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_AUDIO, BATCH_FAILED, "")
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_UPLOAD_AUDIO, BATCH_FAILED, "")
@@ -286,15 +683,18 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 		for i := range speechScripts {
 			speaker := speechScripts[i].Speaker
 			text := speechScripts[i].Text
-			if !strings.EqualFold(speaker, "AI") || text == "" {
+			if !strings.EqualFold(speaker, SpeakerAI) || text == "" {
 				continue
 			}
 
+			idx, scriptText := i, text
 			mediaWg.Add(1)
-			go func(idx int, scriptText string) {
+			client.TrackGo(ctx, "generate_dialog.script_audio", func() {
 				defer mediaWg.Done()
 
-				audioBytes, err := s.audioRepo.Synthesize(ctx, scriptText, voice)
+				callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Speech)
+				audioBytes, err := s.audioRepo.Synthesize(callCtx, scriptText, voice)
+				cancel()
 				if err != nil {
 					mediaMu.Lock()
 					scriptsHasError = true
@@ -303,7 +703,12 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 					return
 				}
 
-				url, err := s.fileRepo.UploadBytes(ctx, audioBytes, fmt.Sprintf("dialogs/%s/script_%d.mp3", payload.DialogID, idx), "audio/mpeg")
+				if normalized, err := s.fileRepo.NormalizeAudioBytes(ctx, audioBytes, ".mp3"); err == nil {
+					audioBytes = normalized
+				}
+
+				scriptKey := fmt.Sprintf("dialogs/%s/script_%d.mp3", payload.DialogID, idx)
+				url, err := s.fileRepo.UploadBytes(ctx, audioBytes, scriptKey, "audio/mpeg")
 				if err != nil {
 					mediaMu.Lock()
 					scriptsHasError = true
@@ -311,9 +716,10 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 					mediaMu.Unlock()
 					return
 				}
+				addVariants(url, s.fileRepo.UploadVariants(ctx, audioBytes, scriptKey, s.media.Audio))
 
 				speechScripts[idx].AudioURL = &url
-			}(i, text)
+			})
 		}
 	} else {
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_GENERATE_AUDIO_SCRIPTS, BATCH_FAILED, "")
@@ -338,6 +744,10 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 
 	details.ImageURL = imageURL
 	details.AudioURL = audioURL
+	if len(mediaVariants) > 0 {
+		details.MediaVariants = mediaVariants
+	}
+	details.Quality = review
 
 	_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_SAVE_DIALOG, BATCH_PROCESSING, "")
 
@@ -360,6 +770,7 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 	metadataJSON, _ := json.Marshal(batch)
 	learningItem := &LearningItem{
 		ID:        uuid.Must(uuid.Parse(payload.DialogID)),
+		ParentID:  parseParentID(payload.ParentID),
 		Content:   details.Topic,
 		Language:  details.Language,
 		Level:     details.Level,
@@ -368,16 +779,97 @@ func (s *DialogService) ProcessGenerateDialog(ctx context.Context, payload Gener
 		Metadata:  metadataJSON,
 		CreatedBy: payload.UserID,
 		IsActive:  true,
+		Audience:  details.Audience,
+	}
+	if review != nil {
+		learningItem.QualityScore = &review.Score
+		if review.Score < s.qualityThreshold {
+			// Low-scoring dialogs stay inactive until a moderator approves them
+			learningItem.IsActive = false
+			learningItem.ReviewStatus = REVIEW_PENDING
+		}
 	}
 
 	if err := s.dialogRepo.UpdateDialog(ctx, learningItem); err != nil {
 		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_SAVE_DIALOG, BATCH_FAILED, err.GetMessage())
-		return
-	} else {
-		_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_SAVE_DIALOG, BATCH_COMPLETED, "")
+		return err
+	}
+	_ = s.batchRepo.UpdateJob(ctx, payload.DialogID, PROCESS_SAVE_DIALOG, BATCH_COMPLETED, "")
+	return nil
+}
+
+// dialogImageOptions shapes the scenario header image (full-screen portrait background).
+var dialogImageOptions = client.ImageOptions{
+	AspectRatio: client.ImageAspectPortrait,
+	SampleCount: 1,
+}
+
+// Text-free image enforcement
+const (
+	imageNegativePrompt       = "text, letters, words, captions, subtitles, labels, signage, watermark, logo"
+	imageStrictNegativePrompt = imageNegativePrompt + ", numbers, typography, handwriting, writing in any script, speech bubbles, posters, book pages, screens showing text"
+	imageNoTextInstruction    = "The image must contain no text of any kind: no letters, numbers, signs, labels or writing in any language. Replace any sign or screen with blank or pictorial surfaces."
+	// OCR readings shorter than this (letters and digits) are treated as noise
+	minImageTextRunes = 3
+)
+
+// generateTextFreeImage generates the dialog image and keeps the first sample OCR reads no text in.
+// When every sample has text it regenerates with a stricter prompt, up to imageTextRetries times.
+// The last image is kept if text remains or OCR fails; note explains why for the batch job.
+func (s *DialogService) generateTextFreeImage(ctx context.Context, prompt, style string) ([]byte, string, *errors.AppError) {
+	opts := dialogImageOptions
+	opts.NegativePrompt = imageNegativePrompt
+	note := ""
+	for attempt := 0; ; attempt++ {
+		callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Image)
+		images, err := s.imageRepo.GenerateImage(callCtx, prompt, style, opts)
+		cancel()
+		if err != nil {
+			return nil, "", err
+		}
+		if s.imageTextRetries <= 0 {
+			return images[0], "", nil
+		}
+
+		for _, image := range images {
+			callCtx, cancel = client.WithTimeout(ctx, s.timeouts.Image)
+			text, err := s.imageRepo.DetectText(callCtx, image)
+			cancel()
+			if err != nil {
+				return image, "text check skipped: " + err.GetMessage(), nil
+			}
+			if countTextRunes(text) < minImageTextRunes {
+				return image, note, nil
+			}
+		}
+		if attempt >= s.imageTextRetries {
+			return images[len(images)-1], fmt.Sprintf("text still detected after %d regenerations", attempt), nil
+		}
+
+		if attempt == 0 {
+			prompt = strings.TrimSpace(prompt) + "\n\n" + imageNoTextInstruction
+			opts.NegativePrompt = imageStrictNegativePrompt
+		}
+		note = fmt.Sprintf("regenerated %d times to remove text", attempt+1)
 	}
 }
 
+// countTextRunes counts the letters and digits in OCR output.
+func countTextRunes(text string) int {
+	n := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// SetAudience reclassifies a dialog, e.g. to hide an existing scenario from minors.
+func (s *DialogService) SetAudience(ctx context.Context, dialogID, audience string) *errors.AppError {
+	return s.dialogRepo.SetAudience(ctx, dialogID, audience)
+}
+
 // ToggleSaved toggles the saved action for a dialog.
 func (s *DialogService) ToggleSaved(ctx context.Context, dialogID, userID string) (*ToggleSavedResponse, *errors.AppError) {
 	actionID, saved, err := s.dialogRepo.ToggleSaved(ctx, dialogID, userID)
@@ -431,7 +923,7 @@ func (s *DialogService) StartSpeech(ctx context.Context, dialogID, userID string
 		SituationText:     details.SpeechMode.Situation,
 		SituationAudioURL: details.AudioURL,
 		Scripts:           details.SpeechMode.Script,
-		Attempts:          [][]SpeechScript{},
+		Attempts:          []SpeechAttempt{},
 	}
 	metadataJSON, _ := json.Marshal(metadata)
 
@@ -476,7 +968,18 @@ func (s *DialogService) SubmitSpeech(ctx context.Context, input SubmitSpeechInpu
 	}
 	defer os.Remove(tempWav.Name())
 
-	evaluation, err := s.audioRepo.EvaluateSpeech(ctx, tempWav, input.ReferenceText, input.Language)
+	// Reject overly long recordings before calling Azure Speech
+	if err := s.fileRepo.CheckRecordingDuration(ctx, tempWav.Name()); err != nil {
+		return nil, err
+	}
+
+	// Trim silence and normalize loudness before assessment (keep the raw recording if ffmpeg fails)
+	_ = s.fileRepo.TrimSilence(ctx, tempWav.Name())
+	_ = s.fileRepo.NormalizeAudio(ctx, tempWav.Name())
+
+	callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Speech)
+	evaluation, err := s.audioRepo.EvaluateSpeech(callCtx, tempWav, input.ReferenceText, input.Language)
+	cancel()
 	if err != nil {
 		return nil, errors.InternalWrap("failed to analyze shadowing audio", err)
 	}
@@ -504,6 +1007,7 @@ func (s *DialogService) SubmitSpeech(ctx context.Context, input SubmitSpeechInpu
 		Duration:          evaluation.Duration,
 		Words:             newWords,
 	}
+	metadata.Turn = input.ScriptIndex + 1
 	metadataJSON, _ := json.Marshal(metadata)
 	if err := s.dialogRepo.SubmitSpeechAction(ctx, action.ID, input.UserID, metadataJSON); err != nil {
 		return nil, err
@@ -536,9 +1040,11 @@ func (s *DialogService) StartChat(ctx context.Context, dialogID, userID string)
 
 	// 4. Create action record
 	metadataJSON, _ := json.Marshal(metadata)
-	if _, err := s.dialogRepo.StartChat(ctx, dialogID, userID, metadataJSON); err != nil {
+	actionID, err := s.dialogRepo.StartChat(ctx, dialogID, userID, metadataJSON)
+	if err != nil {
 		return nil, err
 	}
+	_ = s.memoryRepo.Clear(ctx, actionID)
 
 	return &metadata, nil
 }
@@ -572,12 +1078,16 @@ func (s *DialogService) SubmitChat(ctx context.Context, payload ReplyChatMessage
 }
 
 // ProcessReplyChatMessage handles the background logic of replying to a chat message.
-// worker method
-func (s *DialogService) ProcessReplyChatMessage(ctx context.Context, payload ReplyChatMessagePayload) {
+// worker method; it returns an error when the reply could not be made or saved, so the queue
+// runs it again.
+func (s *DialogService) ProcessReplyChatMessage(ctx context.Context, payload ReplyChatMessagePayload) *errors.AppError {
 	// 1. Get existing chat action metadata (conversation history + progress)
 	action, exists, err := s.dialogRepo.GetActionByUserID(ctx, payload.DialogID, payload.UserID, "submit_chat")
-	if err != nil || !exists {
-		return
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
 	}
 
 	var chatMeta ChatMetadata
@@ -594,40 +1104,33 @@ func (s *DialogService) ProcessReplyChatMessage(ctx context.Context, payload Rep
 	}
 
 	// 3. Call AI with conversation history
-	result, appErr := s.aiRepo.ReplyUserMessage(ctx, chatMeta.ChatObjective, chatMeta.Messages, chatMeta.SituationText, payload.Message)
+	history := s.sessionHistory(ctx, action.ID, chatMeta.Messages)
+	callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Chat)
+	result, appErr := s.aiRepo.ReplyUserMessage(callCtx, chatMeta.ChatObjective, history, chatMeta.SituationText, payload.Message)
+	cancel()
 	if appErr != nil {
 		chatMeta.Status = BATCH_FAILED
 		metadataJSON, _ := json.Marshal(chatMeta)
 		_ = s.dialogRepo.UpdateChatAction(ctx, action.ID, payload.UserID, metadataJSON)
-		return
+		return appErr
 	}
 
 	// 3. Append messages to history
-	chatMeta.Messages = append(chatMeta.Messages,
-		ChatMessage{Role: "user", Content: payload.Message},
-		ChatMessage{Role: "assistant", Content: result.ReplyMessage},
-	)
+	turn := []ChatMessage{
+		{Role: "user", Content: payload.Message},
+		{Role: "assistant", Content: result.ReplyMessage},
+	}
+	chatMeta.Messages = append(chatMeta.Messages, turn...)
+	_ = s.memoryRepo.Append(ctx, action.ID, turn...)
 
 	// 4. Merge completed objectives (deduplicate)
-	existing := make(map[string]bool)
-	for _, text := range chatMeta.CompletedObjectives {
-		existing[text] = true
-	}
-	totalRequirements := len(chatMeta.ChatObjective.Requirements)
-	for _, idx := range result.CompletedObjectivesIndexes {
-		if idx >= 0 && idx < totalRequirements {
-			newCompleted := chatMeta.ChatObjective.Requirements[idx]
-			if !existing[newCompleted] {
-				chatMeta.CompletedObjectives = append(chatMeta.CompletedObjectives, newCompleted)
-			}
-		}
-	}
+	chatMeta.CompletedObjectives = mergeCompletedObjectives(chatMeta.CompletedObjectives, chatMeta.ChatObjective.Requirements, result.CompletedObjectivesIndexes)
 
 	// 5. Update status and save metadata
 	chatMeta.Status = BATCH_COMPLETED
 	metadataJSON, _ := json.Marshal(chatMeta)
 
-	_ = s.dialogRepo.UpdateChatAction(ctx, action.ID, payload.UserID, metadataJSON)
+	return s.dialogRepo.UpdateChatAction(ctx, action.ID, payload.UserID, metadataJSON)
 }
 
 // GetSubmitChat returns the current status and metadata of a chat submission.
@@ -648,29 +1151,1113 @@ func (s *DialogService) GetSubmitChat(ctx context.Context, dialogID, userID stri
 	return &chatMeta, nil
 }
 
-func (s *DialogService) failRemainingMediaJobs(ctx context.Context, dialogID, message string) {
-	for _, processName := range GetProcessNames()[1:] {
-		_ = s.batchRepo.UpdateJob(ctx, dialogID, processName, BATCH_FAILED, message)
+// GetPracticeSession returns where the learner left off in the dialog's speech practice and
+// sparring session. A session idle longer than sessionIdleTTL starts over: an unfinished speech
+// run is archived into its attempts and an active sparring session is ended with its report.
+func (s *DialogService) GetPracticeSession(ctx context.Context, dialogID, userID string) (*PracticeSessionResponse, *errors.AppError) {
+	result := &PracticeSessionResponse{DialogID: dialogID}
+
+	// 1. Speech practice
+	action, exists, err := s.dialogRepo.GetActionByUserID(ctx, dialogID, userID, "submit_speech")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		var metadata SpeechMetadata
+		if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
+			return nil, errors.InternalWrap("failed to parse speech metadata", err)
+		}
+
+		session := &SpeechSession{ActionID: action.ID, LastActiveAt: action.UpdatedAt}
+		if _, evaluated := averageScores(metadata.Scripts); evaluated > 0 && metadata.Turn < len(metadata.Scripts) && s.idle(action.UpdatedAt) {
+			scripts := make([]SpeechScript, len(metadata.Scripts))
+			for i, script := range metadata.Scripts {
+				script.Evaluation = nil
+				scripts[i] = script
+			}
+			metadata.Attempts = append(metadata.Attempts, SpeechAttempt{Scripts: metadata.Scripts, ArchivedAt: time.Now()})
+			metadata.Scripts = scripts
+			metadata.Turn = 0
+
+			metadataJSON, _ := json.Marshal(metadata)
+			if err := s.dialogRepo.SubmitSpeechAction(ctx, action.ID, userID, metadataJSON); err != nil {
+				return nil, err
+			}
+			session.Expired = true
+			session.LastActiveAt = time.Now()
+		}
+
+		session.Turn = metadata.Turn
+		session.TotalTurns = len(metadata.Scripts)
+		session.Scores, session.EvaluatedTurns = averageScores(metadata.Scripts)
+		session.Metadata = &metadata
+		result.Speech = session
+	}
+
+	// 2. Sparring
+	action, exists, err = s.dialogRepo.GetActionByUserID(ctx, dialogID, userID, "submit_sparring")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		var metadata SparringMetadata
+		if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
+			return nil, errors.InternalWrap("failed to parse sparring metadata", err)
+		}
+
+		session := &SparringSession{ActionID: action.ID, LastActiveAt: action.UpdatedAt}
+		if metadata.Status == SPARRING_ACTIVE && len(metadata.Messages) > 0 && s.idle(action.UpdatedAt) {
+			metadata.Status = SPARRING_ENDED
+			metadata.Report = buildSparringReport(metadata)
+
+			metadataJSON, _ := json.Marshal(metadata)
+			if err := s.dialogRepo.UpdateSparringAction(ctx, action.ID, userID, metadataJSON); err != nil {
+				return nil, err
+			}
+			_ = s.memoryRepo.Clear(ctx, action.ID)
+			session.Expired = true
+			session.LastActiveAt = time.Now()
+		}
+
+		session.Progress = buildSparringReport(metadata)
+		session.Turns = session.Progress.Turns
+		session.Status = metadata.Status
+		session.Metadata = &metadata
+		result.Sparring = session
+	}
+
+	if result.Speech == nil && result.Sparring == nil {
+		return nil, errors.NotFound("no practice session for this dialog")
 	}
+	return result, nil
 }
 
-func voiceForDialogLanguage(language string) string {
-	switch strings.ToLower(language) {
-	case "chinese":
-		return "zh-CN-XiaoxiaoNeural"
-	case "japanese":
-		return "ja-JP-NanamiNeural"
-	case "french":
-		return "fr-FR-DeniseNeural"
-	case "spanish":
-		return "es-ES-ElviraNeural"
-	case "portuguese":
-		return "pt-BR-FranciscaNeural"
-	case "arabic":
-		return "ar-SA-ZariyahNeural"
-	case "russian":
-		return "ru-RU-SvetlanaNeural"
-	default:
-		return "en-US-AvaMultilingualNeural"
+// idle reports whether a session last active at lastActive has passed sessionIdleTTL.
+func (s *DialogService) idle(lastActive time.Time) bool {
+	return s.sessionIdleTTL > 0 && time.Since(lastActive) > s.sessionIdleTTL
+}
+
+// averageScores averages the evaluated scripts; scores are nil until one is evaluated.
+func averageScores(scripts []SpeechScript) (*PracticeScores, int) {
+	var scores PracticeScores
+	evaluated := 0
+	for _, script := range scripts {
+		if script.Evaluation == nil {
+			continue
+		}
+		evaluated++
+		scores.Accuracy += script.Evaluation.AccuracyScore
+		scores.Fluency += script.Evaluation.FluencyScore
+		scores.Pronunciation += script.Evaluation.PronScore
+		scores.Completeness += script.Evaluation.CompletenessScore
+	}
+	if evaluated == 0 {
+		return nil, 0
+	}
+
+	n := float64(evaluated)
+	scores.Accuracy /= n
+	scores.Fluency /= n
+	scores.Pronunciation /= n
+	scores.Completeness /= n
+	return &scores, evaluated
+}
+
+// RegenerateScriptTurn rewrites one speech script turn with the AI, regenerates its audio and saves it.
+func (s *DialogService) RegenerateScriptTurn(ctx context.Context, input RegenerateTurnInput) (*RegenerateTurnResponse, *errors.AppError) {
+	// 1. Get dialog and check ownership
+	details, err := s.getRegenerableDetails(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	original := details.SpeechMode.Script[input.Index]
+
+	// 2. Rewrite the turn with the surrounding script as context
+	callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Chat)
+	regenerated, err := s.aiRepo.RegenerateScriptTurn(callCtx, details, input.Index, input.Instruction)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	turn := *regenerated
+
+	// 3. Regenerate audio for AI turns (new key so cached audio is not served)
+	if strings.EqualFold(turn.Speaker, SpeakerAI) {
+		url, err := s.synthesizeTurnAudio(ctx, input.DialogID, input.Index, turn.Text, details.Language)
+		if err != nil {
+			return nil, err
+		}
+		turn.AudioURL = &url
+	}
+
+	// 4. Patch the turn, refusing if it changed while we were regenerating
+	err = s.dialogRepo.UpdateDialogDetails(ctx, input.DialogID, func(current *DialogDetails) *errors.AppError {
+		script := current.SpeechMode.Script
+		if input.Index >= len(script) || script[input.Index].Speaker != original.Speaker || script[input.Index].Text != original.Text {
+			return errors.Conflict("script turn changed while it was being regenerated")
+		}
+		script[input.Index] = turn
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegenerateTurnResponse{
+		DialogID: input.DialogID,
+		Index:    input.Index,
+		Turn:     turn,
+	}, nil
+}
+
+// synthesizeTurnAudio voices an AI script turn and uploads it under a new key, so cached audio is not served.
+func (s *DialogService) synthesizeTurnAudio(ctx context.Context, dialogID string, index int, text, language string) (string, *errors.AppError) {
+	if s.audioRepo == nil || s.fileRepo == nil {
+		return "", errors.Unsupported("dialog audio is not configured")
+	}
+
+	callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Speech)
+	audioBytes, err := s.audioRepo.Synthesize(callCtx, text, client.VoiceForLanguage(language))
+	cancel()
+	if err != nil {
+		return "", err
+	}
+	if normalized, err := s.fileRepo.NormalizeAudioBytes(ctx, audioBytes, ".mp3"); err == nil {
+		audioBytes = normalized
+	}
+
+	key := fmt.Sprintf("dialogs/%s/script_%d_%d.mp3", dialogID, index, time.Now().Unix())
+	return s.fileRepo.UploadBytes(ctx, audioBytes, key, "audio/mpeg")
+}
+
+// PrepareRegenerateScriptTurn validates a turn regeneration and creates the batch tracking it,
+// so the handler can run it on the job queue and return the batch right away.
+func (s *DialogService) PrepareRegenerateScriptTurn(ctx context.Context, input RegenerateTurnInput) (*RegenerateTurnPayload, *response.MetaProcessing, *errors.AppError) {
+	if _, err := s.getRegenerableDetails(ctx, input); err != nil {
+		return nil, nil, err
+	}
+
+	batchID := fmt.Sprintf("%s:turn:%s", input.DialogID, uuid.New().String())
+	meta, err := s.batchRepo.CreateRegenerateTurnBatch(ctx, batchID, input.DialogID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload := &RegenerateTurnPayload{BatchID: batchID, Input: input}
+	_ = s.batchRepo.SetBatchInput(ctx, batchID, payload)
+	return payload, meta, nil
+}
+
+// ProcessRegenerateScriptTurn runs a queued turn regeneration and stores its result in the batch.
+// It returns an error when the turn could not be regenerated or stored, so the queue runs it again.
+func (s *DialogService) ProcessRegenerateScriptTurn(ctx context.Context, payload RegenerateTurnPayload) *errors.AppError {
+	_ = s.batchRepo.UpdateJob(ctx, payload.BatchID, PROCESS_REGENERATE_TURN, BATCH_PROCESSING, "")
+
+	result, err := s.RegenerateScriptTurn(ctx, payload.Input)
+	if err != nil {
+		_ = s.batchRepo.UpdateJob(ctx, payload.BatchID, PROCESS_REGENERATE_TURN, BATCH_FAILED, err.Error())
+		return err
+	}
+
+	// Store the result before completing the job so a completed batch always carries it
+	resultJSON, _ := json.Marshal(result)
+	if err := s.batchRepo.SetBatchResult(ctx, payload.BatchID, resultJSON); err != nil {
+		_ = s.batchRepo.UpdateJob(ctx, payload.BatchID, PROCESS_REGENERATE_TURN, BATCH_FAILED, "failed to store result")
+		return errors.InternalWrap("failed to store regenerated turn", err)
+	}
+	_ = s.batchRepo.UpdateJob(ctx, payload.BatchID, PROCESS_REGENERATE_TURN, BATCH_COMPLETED, "")
+	return nil
+}
+
+// ProcessRepairMedia regenerates missing or broken media from the image prompt, situation and
+// script already in the dialog, and writes the new URLs back. It runs for the media check and
+// for retried batch jobs (payload.BatchID set), whose job status it keeps up to date.
+// It returns an error when any of the media could not be regenerated, so the job is retried.
+func (s *DialogService) ProcessRepairMedia(ctx context.Context, payload RepairMediaPayload) *errors.AppError {
+	track := func(status, message string, jobs ...string) {
+		if payload.BatchID == "" {
+			return
+		}
+		for _, job := range jobs {
+			_ = s.batchRepo.UpdateJob(ctx, payload.BatchID, job, status, message)
+		}
+	}
+	imageJobs := []string{PROCESS_GENERATE_IMAGE, PROCESS_UPLOAD_IMAGE}
+	audioJobs := []string{PROCESS_GENERATE_AUDIO, PROCESS_UPLOAD_AUDIO}
+	scriptJobs := []string{PROCESS_GENERATE_AUDIO_SCRIPTS, PROCESS_UPLOAD_AUDIO_SCRIPTS}
+
+	fail := func(err *errors.AppError) *errors.AppError {
+		if payload.Image {
+			track(BATCH_FAILED, err.GetMessage(), imageJobs...)
+		}
+		if payload.Audio {
+			track(BATCH_FAILED, err.GetMessage(), audioJobs...)
+		}
+		if payload.Scripts {
+			track(BATCH_FAILED, err.GetMessage(), scriptJobs...)
+		}
+		return err
 	}
+	if s.fileRepo == nil {
+		return fail(errors.Unsupported("media storage not configured"))
+	}
+	details, err := s.dialogRepo.GetDetailsByID(ctx, payload.DialogID)
+	if err != nil {
+		return fail(err)
+	}
+
+	media := &DialogMedia{ScriptAudio: make(map[int]string), Variants: response.MediaVariants{}}
+	var failed []string
+
+	if payload.Image {
+		track(BATCH_PROCESSING, "", imageJobs...)
+		err := s.repairImage(ctx, payload.DialogID, details, media)
+		if err != nil {
+			failed = append(failed, "image: "+err.GetMessage())
+			track(BATCH_FAILED, err.GetMessage(), imageJobs...)
+		} else {
+			track(BATCH_COMPLETED, "", imageJobs...)
+		}
+	}
+
+	voice := client.VoiceForLanguage(details.Language)
+	synthesize := func(text, key string) (string, *errors.AppError) {
+		if s.audioRepo == nil {
+			return "", errors.Unsupported("speech synthesis not configured")
+		}
+		callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Speech)
+		audioBytes, err := s.audioRepo.Synthesize(callCtx, text, voice)
+		cancel()
+		if err != nil {
+			return "", err
+		}
+		if normalized, err := s.fileRepo.NormalizeAudioBytes(ctx, audioBytes, ".mp3"); err == nil {
+			audioBytes = normalized
+		}
+		url, err := s.fileRepo.UploadBytes(ctx, audioBytes, key, "audio/mpeg")
+		if err != nil {
+			return "", err
+		}
+		if variants := s.fileRepo.UploadVariants(ctx, audioBytes, key, s.media.Audio); len(variants) > 0 {
+			media.Variants[url] = variants
+		}
+		return url, nil
+	}
+
+	if payload.Audio {
+		track(BATCH_PROCESSING, "", audioJobs...)
+		var url string
+		err := errors.Validation("dialog has no situation")
+		if details.SpeechMode.Situation != "" {
+			url, err = synthesize(details.SpeechMode.Situation, fmt.Sprintf("dialogs/%s/situation_audio.mp3", payload.DialogID))
+		}
+		if err != nil {
+			failed = append(failed, "audio: "+err.GetMessage())
+			track(BATCH_FAILED, err.GetMessage(), audioJobs...)
+		} else {
+			media.AudioURL = url
+			track(BATCH_COMPLETED, "", audioJobs...)
+		}
+	}
+
+	if payload.Scripts {
+		track(BATCH_PROCESSING, "", scriptJobs...)
+	}
+	var scriptErr *errors.AppError
+	for _, idx := range payload.ScriptTurns {
+		if idx < 0 || idx >= len(details.SpeechMode.Script) {
+			continue
+		}
+		turn := details.SpeechMode.Script[idx]
+		if !strings.EqualFold(turn.Speaker, SpeakerAI) || turn.Text == "" {
+			continue
+		}
+		url, err := synthesize(turn.Text, fmt.Sprintf("dialogs/%s/script_%d.mp3", payload.DialogID, idx))
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("script turn %d: %s", idx, err.GetMessage()))
+			scriptErr = err
+			continue
+		}
+		media.ScriptAudio[idx] = url
+	}
+
+	// Keep what did regenerate even when the rest failed
+	if !media.Empty() {
+		if err := s.dialogRepo.UpdateDialogMedia(ctx, payload.DialogID, media); err != nil {
+			return fail(err)
+		}
+	}
+	if payload.Scripts {
+		if scriptErr != nil {
+			track(BATCH_FAILED, scriptErr.GetMessage(), scriptJobs...)
+		} else {
+			track(BATCH_COMPLETED, "", scriptJobs...)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Internal("failed to repair dialog media: " + strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// repairImage regenerates the scene image from the stored prompt and uploads it into media.
+func (s *DialogService) repairImage(ctx context.Context, dialogID string, details *DialogDetails, media *DialogMedia) *errors.AppError {
+	if details.ImagePrompt == "" {
+		return errors.Validation("dialog has no image prompt")
+	}
+	if s.imageRepo == nil {
+		return errors.Unsupported("image generation not configured")
+	}
+
+	style := s.imageRepo.ResolveStyle(ctx, details.ImageStyle)
+	imageBytes, _, err := s.generateTextFreeImage(ctx, details.ImagePrompt, style)
+	if err != nil {
+		return err
+	}
+	imageKey := fmt.Sprintf("dialogs/%s/bg_image.png", dialogID)
+	url, err := s.fileRepo.UploadBytes(ctx, imageBytes, imageKey, "image/png")
+	if err != nil {
+		return err
+	}
+	variants := s.fileRepo.UploadVariants(ctx, imageBytes, imageKey, s.media.Image)
+	if len(variants) > 0 {
+		media.Variants[url] = variants
+	}
+	media.ImageURL = url
+
+	// The old image is broken or missing; later dialogs share the new one instead
+	if s.imageRegistry != nil {
+		if details.ImageURL != "" {
+			_ = s.imageRegistry.ForgetImage(ctx, details.ImageURL)
+		}
+		_ = s.imageRegistry.RegisterImage(ctx, &RegisteredImage{
+			PromptHash: imagePromptHash(details.ImagePrompt, style),
+			ImageURL:   url,
+			Variants:   variants,
+			DialogID:   dialogID,
+		})
+	}
+	return nil
+}
+
+// BatchRetry is a failed batch job claimed for a retry: the queue job that re-runs it, the batch
+// with the claimed jobs back to pending, and those jobs as they were before the claim.
+type BatchRetry struct {
+	Job      client.Job
+	Batch    *response.MetaProcessing
+	previous []response.BatchJob
+}
+
+// PrepareRetryBatchJob checks that a failed job of a dialog batch the caller owns can run again,
+// claims it by moving its jobs back to pending and returns the queue job that re-runs it from the
+// stored input. Generation and save failures re-run the whole generation; media jobs regenerate
+// only their media from the saved dialog. When the queue refuses the job, CancelRetryBatchJob
+// gives the jobs back their failed state.
+func (s *DialogService) PrepareRetryBatchJob(ctx context.Context, input RetryBatchJobInput) (*BatchRetry, *errors.AppError) {
+	batch, err := s.batchRepo.GetBatch(ctx, input.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, errors.NotFound("batch not found")
+	}
+	if batch.ReferenceType != BATCH_REFERENCE_DIALOG {
+		return nil, errors.Validation("jobs of this batch cannot be retried")
+	}
+	learningItem, err := s.dialogRepo.GetDialog(ctx, batch.ReferenceID, input.UserID)
+	if err != nil || learningItem.CreatedBy != input.UserID {
+		return nil, errors.NotFound("batch not found")
+	}
+
+	statuses := make(map[string]string, len(batch.BatchJobs))
+	for _, job := range batch.BatchJobs {
+		statuses[job.Name] = job.Status
+		if job.Status == BATCH_PENDING || job.Status == BATCH_PROCESSING {
+			return nil, errors.Conflict("batch is still running")
+		}
+	}
+	switch statuses[input.JobName] {
+	case "":
+		return nil, errors.NotFound("job not found")
+	case BATCH_FAILED:
+	default:
+		return nil, errors.Conflict("only failed jobs can be retried")
+	}
+
+	var (
+		job   client.Job
+		reset []string
+	)
+	switch input.JobName {
+	case PROCESS_REGENERATE_TURN:
+		var payload RegenerateTurnPayload
+		if ok, err := s.batchRepo.GetBatchInput(ctx, input.BatchID, &payload); err != nil || !ok {
+			return nil, errors.Conflict("batch has no stored input to retry")
+		}
+		job = client.Job{Type: WORKER_REGENERATE_TURN, Payload: payload}
+		reset = []string{PROCESS_REGENERATE_TURN}
+
+	case PROCESS_GENERATE_DIALOG, PROCESS_SAVE_DIALOG:
+		var payload GenerateDialogPayload
+		if ok, err := s.batchRepo.GetBatchInput(ctx, input.BatchID, &payload); err != nil || !ok {
+			return nil, errors.Conflict("batch has no stored input to retry")
+		}
+		job = client.Job{Type: WORKER_GENERATE_DIALOG, Payload: payload}
+		for name, status := range statuses {
+			if status == BATCH_FAILED {
+				reset = append(reset, name)
+			}
+		}
+
+	default:
+		// Media jobs come in generate/upload pairs that run again together
+		if statuses[PROCESS_SAVE_DIALOG] != BATCH_COMPLETED {
+			return nil, errors.Conflict(fmt.Sprintf("dialog was not saved; retry %s instead", PROCESS_GENERATE_DIALOG))
+		}
+		payload := RepairMediaPayload{DialogID: batch.ReferenceID, BatchID: input.BatchID}
+		switch input.JobName {
+		case PROCESS_GENERATE_IMAGE, PROCESS_UPLOAD_IMAGE:
+			payload.Image = true
+			reset = []string{PROCESS_GENERATE_IMAGE, PROCESS_UPLOAD_IMAGE}
+		case PROCESS_GENERATE_AUDIO, PROCESS_UPLOAD_AUDIO:
+			payload.Audio = true
+			reset = []string{PROCESS_GENERATE_AUDIO, PROCESS_UPLOAD_AUDIO}
+		case PROCESS_GENERATE_AUDIO_SCRIPTS, PROCESS_UPLOAD_AUDIO_SCRIPTS:
+			details, err := s.dialogRepo.GetDetailsByID(ctx, batch.ReferenceID)
+			if err != nil {
+				return nil, err
+			}
+			payload.Scripts = true
+			for i, turn := range details.SpeechMode.Script {
+				if strings.EqualFold(turn.Speaker, SpeakerAI) && turn.Text != "" && (turn.AudioURL == nil || *turn.AudioURL == "") {
+					payload.ScriptTurns = append(payload.ScriptTurns, i)
+				}
+			}
+			reset = []string{PROCESS_GENERATE_AUDIO_SCRIPTS, PROCESS_UPLOAD_AUDIO_SCRIPTS}
+		default:
+			return nil, errors.Validation("this job cannot be retried")
+		}
+		job = client.Job{Type: WORKER_REPAIR_MEDIA, Payload: payload}
+	}
+	job.BatchID = input.BatchID
+
+	previous := make([]response.BatchJob, 0, len(reset))
+	for _, old := range batch.BatchJobs {
+		if slices.Contains(reset, old.Name) {
+			previous = append(previous, old)
+		}
+	}
+
+	// Back to pending so the batch reads as running until the job reports again. The claim only
+	// succeeds while the job is still failed, so a concurrent retry of the batch gets a conflict.
+	claimed, ok, claimErr := s.batchRepo.RetryJobs(ctx, input.BatchID, input.JobName, reset)
+	if claimErr != nil {
+		return nil, errors.InternalWrap("failed to reset batch jobs", claimErr)
+	}
+	if claimed == nil {
+		return nil, errors.Conflict("jobs of this batch cannot be retried")
+	}
+	if !ok {
+		return nil, errors.Conflict("job is already being retried")
+	}
+	return &BatchRetry{Job: job, Batch: claimed, previous: previous}, nil
+}
+
+// CancelRetryBatchJob gives the jobs claimed by PrepareRetryBatchJob back the state they had, for
+// a retry whose queue job could not be enqueued. Failures are logged by the batch repository.
+func (s *DialogService) CancelRetryBatchJob(ctx context.Context, retry *BatchRetry) {
+	for _, job := range retry.previous {
+		_ = s.batchRepo.UpdateJob(ctx, retry.Job.BatchID, job.Name, job.Status, job.Error)
+	}
+}
+
+// GetDialogBatch returns a background batch of one of the caller's dialogs.
+func (s *DialogService) GetDialogBatch(ctx context.Context, dialogID, batchID, userID string) (*response.MetaProcessing, *errors.AppError) {
+	learningItem, err := s.dialogRepo.GetDialog(ctx, dialogID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != userID {
+		return nil, errors.NotFound("batch not found")
+	}
+
+	batch, err := s.batchRepo.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil || batch.ReferenceType != BATCH_REFERENCE_DIALOG || batch.ReferenceID != dialogID {
+		return nil, errors.NotFound("batch not found")
+	}
+	return batch, nil
+}
+
+// getRegenerableDetails loads the details of a dialog the caller owns and checks the turn index.
+func (s *DialogService) getRegenerableDetails(ctx context.Context, input RegenerateTurnInput) (*DialogDetails, *errors.AppError) {
+	learningItem, err := s.dialogRepo.GetDialog(ctx, input.DialogID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the dialog owner can regenerate script turns")
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+	if input.Index >= len(details.SpeechMode.Script) {
+		return nil, errors.Validation(fmt.Sprintf("turn index %d out of range", input.Index))
+	}
+	return &details, nil
+}
+
+// SparringTurn plays one turn of a live sparring session against the AI partner.
+// A session starts on the first turn and ends with a report once every requirement is met,
+// the turn limit is reached or the learner ends it.
+func (s *DialogService) SparringTurn(ctx context.Context, input SparringTurnInput) (*SparringTurnResponse, *errors.AppError) {
+	if input.AudioFile != nil {
+		defer input.AudioFile.Close()
+	}
+
+	// 1. Get dialog (learners get the published version)
+	learningItem, err := s.dialogRepo.GetDialog(ctx, input.DialogID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+
+	// 2. Get the running session or start a new one from the chat mode objectives
+	var metadata SparringMetadata
+	action, exists, err := s.dialogRepo.GetActionByUserID(ctx, input.DialogID, input.UserID, "submit_sparring")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		_ = json.Unmarshal(action.Metadata, &metadata)
+	}
+
+	var actionID string
+	if !exists || metadata.Status != SPARRING_ACTIVE {
+		if input.End {
+			return nil, errors.NotFound("no active sparring session for this dialog")
+		}
+
+		metadata = SparringMetadata{
+			SituationText:       details.ChatMode.Situation,
+			ChatObjective:       details.ChatMode.Objectives,
+			Messages:            []SparringMessage{},
+			CompletedObjectives: []string{},
+			Status:              SPARRING_ACTIVE,
+		}
+		metadataJSON, _ := json.Marshal(metadata)
+		actionID, err = s.dialogRepo.StartSparring(ctx, input.DialogID, input.UserID, metadataJSON)
+		if err != nil {
+			return nil, err
+		}
+		_ = s.memoryRepo.Clear(ctx, actionID)
+	} else {
+		actionID = action.ID
+	}
+
+	result := &SparringTurnResponse{ActionID: actionID}
+
+	// 3. Get the learner utterance (transcribe audio when given)
+	message := input.Message
+	if input.AudioFile != nil {
+		tempWav, err := s.fileRepo.CreateTempFile(input.AudioFile, input.AudioWavPath)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(tempWav.Name())
+		_ = tempWav.Close()
+
+		if err := s.fileRepo.CheckRecordingDuration(ctx, tempWav.Name()); err != nil {
+			return nil, err
+		}
+		_ = s.fileRepo.TrimSilence(ctx, tempWav.Name())
+
+		callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Transcription)
+		message, err = s.audioRepo.Transcribe(callCtx, tempWav.Name(), languageCodeForDialog(details.Language))
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if message == "" && !input.End {
+			return nil, errors.Validation("no speech detected in the recording")
+		}
+		result.Transcript = message
+	}
+
+	// 4. Let the AI partner reply in character and track objectives
+	if message != "" {
+		stored := make([]ChatMessage, 0, len(metadata.Messages))
+		for _, msg := range metadata.Messages {
+			stored = append(stored, ChatMessage{Role: msg.Role, Content: msg.Content})
+		}
+		history := s.sessionHistory(ctx, actionID, stored)
+
+		callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Chat)
+		reply, err := s.aiRepo.ReplyUserMessage(callCtx, metadata.ChatObjective, history, metadata.SituationText, message)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		assistant := SparringMessage{Role: "assistant", Content: reply.ReplyMessage}
+		if s.audioRepo != nil && s.fileRepo != nil {
+			callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Speech)
+			audioBytes, err := s.audioRepo.Synthesize(callCtx, reply.ReplyMessage, client.VoiceForLanguage(details.Language))
+			cancel()
+			if err == nil {
+				if normalized, err := s.fileRepo.NormalizeAudioBytes(ctx, audioBytes, ".mp3"); err == nil {
+					audioBytes = normalized
+				}
+				key := fmt.Sprintf("dialogs/%s/sparring/%s_%d.mp3", input.DialogID, actionID, len(metadata.Messages))
+				if url, err := s.fileRepo.UploadBytes(ctx, audioBytes, key, "audio/mpeg"); err == nil {
+					assistant.AudioURL = &url
+				}
+			}
+		}
+
+		metadata.Messages = append(metadata.Messages,
+			SparringMessage{Role: "user", Content: message, Suggestion: reply.Suggestion},
+			assistant,
+		)
+		_ = s.memoryRepo.Append(ctx, actionID,
+			ChatMessage{Role: "user", Content: message},
+			ChatMessage{Role: "assistant", Content: assistant.Content},
+		)
+		metadata.CompletedObjectives = mergeCompletedObjectives(metadata.CompletedObjectives, metadata.ChatObjective.Requirements, reply.CompletedObjectivesIndexes)
+
+		result.Reply = assistant.Content
+		result.ReplyAudioURL = assistant.AudioURL
+		result.Suggestion = reply.Suggestion
+	}
+
+	// 5. End the session with a report when done
+	userTurns := len(metadata.Messages) / 2
+	allCompleted := len(metadata.ChatObjective.Requirements) > 0 && len(metadata.CompletedObjectives) >= len(metadata.ChatObjective.Requirements)
+	if input.End || allCompleted || userTurns >= maxSparringTurns {
+		metadata.Status = SPARRING_ENDED
+		metadata.Report = buildSparringReport(metadata)
+	}
+
+	metadataJSON, _ := json.Marshal(metadata)
+	if err := s.dialogRepo.UpdateSparringAction(ctx, actionID, input.UserID, metadataJSON); err != nil {
+		return nil, err
+	}
+
+	result.CompletedObjectives = metadata.CompletedObjectives
+	result.Status = metadata.Status
+	result.Report = metadata.Report
+	return result, nil
+}
+
+// CheckTurnBlanks grades the learner's fill-ins for a turn's missing words, in order.
+func (s *DialogService) CheckTurnBlanks(ctx context.Context, input CheckBlanksInput) (*CheckBlanksResponse, *errors.AppError) {
+	turn, err := s.blankTurn(ctx, input.DialogID, input.UserID, input.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make([]string, len(turn.MissingWords))
+	for i, word := range turn.MissingWords {
+		expected[i] = normalizeBlankWord(word)
+	}
+
+	result := &CheckBlanksResponse{Results: make([]BlankResult, len(expected)), Total: len(expected)}
+	for i := range expected {
+		status := BLANK_INCORRECT
+		if i < len(input.Words) {
+			status = gradeBlank(normalizeBlankWord(input.Words[i]), i, expected)
+		}
+		if status == BLANK_CORRECT || status == BLANK_CLOSE {
+			result.Correct++
+		}
+		result.Results[i] = BlankResult{Index: i, Status: status}
+	}
+	result.AllCorrect = result.Correct == result.Total
+
+	return result, nil
+}
+
+// GetTurnHint returns hint number step for a turn's blanks: the authored hints first,
+// then the first letter and length of each missing word.
+func (s *DialogService) GetTurnHint(ctx context.Context, input TurnHintInput) (*TurnHintResponse, *errors.AppError) {
+	turn, err := s.blankTurn(ctx, input.DialogID, input.UserID, input.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := append([]string(nil), turn.Hints...)
+	shapes := make([]string, len(turn.MissingWords))
+	for i, word := range turn.MissingWords {
+		runes := []rune(word)
+		shapes[i] = string(runes[0]) + strings.Repeat("_", len(runes)-1)
+	}
+	hints = append(hints, strings.Join(shapes, ", "))
+
+	if input.Step >= len(hints) {
+		return nil, errors.Validation("no more hints for this turn")
+	}
+
+	return &TurnHintResponse{
+		Step:      input.Step,
+		Hint:      hints[input.Step],
+		Remaining: len(hints) - input.Step - 1,
+	}, nil
+}
+
+// blankTurn returns the script turn at index when it has blanks to fill in.
+func (s *DialogService) blankTurn(ctx context.Context, dialogID, userID string, index int) (*SpeechScript, *errors.AppError) {
+	learningItem, err := s.dialogRepo.GetDialog(ctx, dialogID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+	if index >= len(details.SpeechMode.Script) {
+		return nil, errors.Validation(fmt.Sprintf("turn index %d out of range", index))
+	}
+
+	turn := details.SpeechMode.Script[index]
+	if len(turn.MissingWords) == 0 {
+		return nil, errors.Validation("turn has no blanks to fill in")
+	}
+	return &turn, nil
+}
+
+// gradeBlank compares answer with the word expected at blank i (all normalized).
+// Near misses are accepted as close; a word belonging to another blank is misplaced.
+func gradeBlank(answer string, i int, expected []string) string {
+	if answer == "" {
+		return BLANK_INCORRECT
+	}
+	if answer == expected[i] {
+		return BLANK_CORRECT
+	}
+	if editDistance(answer, expected[i]) <= blankTolerance(expected[i]) {
+		return BLANK_CLOSE
+	}
+	for j, word := range expected {
+		if j != i && answer == word {
+			return BLANK_MISPLACED
+		}
+	}
+	return BLANK_INCORRECT
+}
+
+// blankTolerance allows one typo in short words and two in longer ones.
+func blankTolerance(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func normalizeBlankWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}))
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// sessionHistory returns the prompt history of a chat or sparring session from conversation memory.
+// Sessions without memory (expired, or started before it existed) are seeded from the stored messages.
+func (s *DialogService) sessionHistory(ctx context.Context, sessionID string, stored []ChatMessage) []ChatMessage {
+	history, err := s.memoryRepo.History(ctx, sessionID)
+	if err == nil && len(history) > 0 {
+		return history
+	}
+	if err == nil && len(stored) > 0 {
+		_ = s.memoryRepo.Append(ctx, sessionID, stored...)
+	}
+	return stored
+}
+
+// mergeCompletedObjectives adds the requirements at indexes to completed, skipping duplicates.
+func mergeCompletedObjectives(completed, requirements []string, indexes []int) []string {
+	existing := make(map[string]bool, len(completed))
+	for _, text := range completed {
+		existing[text] = true
+	}
+	for _, idx := range indexes {
+		if idx >= 0 && idx < len(requirements) && !existing[requirements[idx]] {
+			existing[requirements[idx]] = true
+			completed = append(completed, requirements[idx])
+		}
+	}
+	return completed
+}
+
+func buildSparringReport(metadata SparringMetadata) *SparringReport {
+	done := make(map[string]bool, len(metadata.CompletedObjectives))
+	for _, text := range metadata.CompletedObjectives {
+		done[text] = true
+	}
+
+	report := &SparringReport{
+		CompletedObjectives: metadata.CompletedObjectives,
+		MissedObjectives:    []string{},
+		Suggestions:         []string{},
+	}
+	for _, requirement := range metadata.ChatObjective.Requirements {
+		if !done[requirement] {
+			report.MissedObjectives = append(report.MissedObjectives, requirement)
+		}
+	}
+	for _, msg := range metadata.Messages {
+		if msg.Role != "user" {
+			continue
+		}
+		report.Turns++
+		if msg.Suggestion != "" {
+			report.Suggestions = append(report.Suggestions, msg.Suggestion)
+		}
+	}
+	if total := len(metadata.ChatObjective.Requirements); total > 0 {
+		report.CompletionRate = float64(total-len(report.MissedObjectives)) / float64(total) * 100
+	}
+
+	return report
+}
+
+func (s *DialogService) failRemainingMediaJobs(ctx context.Context, dialogID, message string) {
+	for _, processName := range GetProcessNames()[1:] {
+		_ = s.batchRepo.UpdateJob(ctx, dialogID, processName, BATCH_FAILED, message)
+	}
+}
+
+func parseParentID(parentID string) *uuid.UUID {
+	if parentID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(parentID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// languageCodeForDialog returns the ISO 639-1 code used for transcription (e.g. "en").
+func languageCodeForDialog(language string) string {
+	return strings.SplitN(client.VoiceForLanguage(language), "-", 2)[0]
+}
+
+// ProposeFix asks the AI to correct the dialog as the editor's note describes and keeps the
+// result as a proposal. Nothing changes until the editor applies it (see ApplyFix).
+func (s *DialogService) ProposeFix(ctx context.Context, input FixDialogInput) (*FixProposal, *errors.AppError) {
+	// 1. Get dialog and check ownership
+	learningItem, err := s.dialogRepo.GetDialog(ctx, input.DialogID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the dialog owner can fix it")
+	}
+
+	var details DialogDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+	if len(details.SpeechMode.Script) == 0 {
+		return nil, errors.Conflict("dialog is still being generated")
+	}
+
+	// 2. Correct the dialog with the note
+	callCtx, cancel := client.WithTimeout(ctx, s.timeouts.Chat)
+	fixed, err := s.aiRepo.FixDialog(callCtx, &details, input.Note)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffDialogText(&details, fixed)
+	if len(changes) == 0 {
+		return nil, errors.Validation("nothing to change for this note, try describing the mistake in more detail")
+	}
+
+	// 3. Keep the proposal until it is applied or expires
+	fixedJSON, _ := json.Marshal(fixed)
+	proposal := &FixProposal{
+		ID:          uuid.New().String(),
+		DialogID:    input.DialogID,
+		UserID:      input.UserID,
+		Note:        input.Note,
+		BaseVersion: learningItem.Version,
+		Changes:     changes,
+		Details:     fixedJSON,
+		Signature:   dialogTextSignature(&details),
+	}
+	if err := s.fixRepo.SaveProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// ApplyFix applies a proposed fix to the draft and records it as a revision. AI lines whose
+// text changed get new audio. The fix is refused if the dialog changed since it was proposed.
+func (s *DialogService) ApplyFix(ctx context.Context, input ApplyFixInput) (*ApplyFixResponse, *errors.AppError) {
+	// 1. Get the proposal and the dialog it was made for
+	proposal, err := s.fixRepo.GetProposal(ctx, input.FixID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil || proposal.DialogID != input.DialogID || proposal.UserID != input.UserID {
+		return nil, errors.NotFound("fix not found or expired")
+	}
+
+	learningItem, err := s.dialogRepo.GetDialog(ctx, input.DialogID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the dialog owner can fix it")
+	}
+	if learningItem.Version != proposal.BaseVersion {
+		return nil, errors.Conflict("dialog changed since the fix was proposed, request a new fix")
+	}
+
+	var fixed DialogDetails
+	if err := json.Unmarshal(proposal.Details, &fixed); err != nil {
+		return nil, errors.InternalWrap("failed to parse fix proposal", err)
+	}
+	var details DialogDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse dialog details", err)
+	}
+
+	// 2. Voice the AI lines whose text changed
+	audioURLs := make(map[int]string)
+	for i, turn := range fixed.SpeechMode.Script {
+		if turn.Speaker != SpeakerAI || i >= len(details.SpeechMode.Script) || details.SpeechMode.Script[i].Text == turn.Text {
+			continue
+		}
+		url, err := s.synthesizeTurnAudio(ctx, input.DialogID, i, turn.Text, details.Language)
+		if err != nil {
+			return nil, err
+		}
+		audioURLs[i] = url
+	}
+
+	// 3. Apply the corrected text and record the revision, refusing if the text changed meanwhile
+	var revision DialogRevision
+	err = s.dialogRepo.UpdateDialogDetails(ctx, input.DialogID, func(current *DialogDetails) *errors.AppError {
+		if dialogTextSignature(current) != proposal.Signature {
+			return errors.Conflict("dialog changed since the fix was proposed, request a new fix")
+		}
+
+		current.Description = fixed.Description
+		current.SpeechMode.Situation = fixed.SpeechMode.Situation
+		for i := range current.SpeechMode.Script {
+			turn := &current.SpeechMode.Script[i]
+			turn.Text = fixed.SpeechMode.Script[i].Text
+			turn.MissingWords = fixed.SpeechMode.Script[i].MissingWords
+			turn.Hints = fixed.SpeechMode.Script[i].Hints
+			if url, ok := audioURLs[i]; ok {
+				turn.AudioURL = &url
+			}
+		}
+		current.ChatMode = fixed.ChatMode
+
+		revision = DialogRevision{
+			Revision: len(current.Revisions) + 1,
+			Note:     proposal.Note,
+			Changes:  proposal.Changes,
+			EditedBy: input.UserID,
+			EditedAt: time.Now().UTC(),
+		}
+		current.Revisions = append(current.Revisions, revision)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.fixRepo.DeleteProposal(ctx, input.FixID)
+
+	return &ApplyFixResponse{
+		DialogID: input.DialogID,
+		Revision: revision.Revision,
+		Changes:  revision.Changes,
+	}, nil
+}
+
+// dialogTextField is one text field of the details a fix can change, lists joined one entry per line.
+type dialogTextField struct {
+	Path  string
+	Value string
+}
+
+func dialogTextFields(d *DialogDetails) []dialogTextField {
+	fields := []dialogTextField{
+		{"description", d.Description},
+		{"speech_mode.situation", d.SpeechMode.Situation},
+	}
+	for i, turn := range d.SpeechMode.Script {
+		path := fmt.Sprintf("speech_mode.script[%d]", i)
+		fields = append(fields,
+			dialogTextField{path + ".text", turn.Text},
+			dialogTextField{path + ".missing_words", strings.Join(turn.MissingWords, "\n")},
+			dialogTextField{path + ".hints", strings.Join(turn.Hints, "\n")},
+		)
+	}
+	objectives := d.ChatMode.Objectives
+	return append(fields,
+		dialogTextField{"chat_mode.situation", d.ChatMode.Situation},
+		dialogTextField{"chat_mode.objectives.requirements", strings.Join(objectives.Requirements, "\n")},
+		dialogTextField{"chat_mode.objectives.persuasion", strings.Join(objectives.Persuasion, "\n")},
+		dialogTextField{"chat_mode.objectives.constraints", strings.Join(objectives.Constraints, "\n")},
+	)
+}
+
+// diffDialogText lists the text fields that differ between two versions with the same script shape.
+func diffDialogText(before, after *DialogDetails) []FixChange {
+	afterFields := dialogTextFields(after)
+	changes := make([]FixChange, 0)
+	for i, field := range dialogTextFields(before) {
+		if i < len(afterFields) && afterFields[i].Value != field.Value {
+			changes = append(changes, FixChange{Path: field.Path, Before: field.Value, After: afterFields[i].Value})
+		}
+	}
+	return changes
+}
+
+// dialogTextSignature identifies the text of the details, to detect edits made after a fix was proposed.
+func dialogTextSignature(d *DialogDetails) string {
+	data, _ := json.Marshal(dialogTextFields(d))
+	return fmt.Sprintf("%x", sha256.Sum256(data))
 }
@@ -11,28 +11,58 @@ import (
 const (
 	WORKER_GENERATE_DIALOG    = "GENERATE_DIALOG"
 	WORKER_REPLY_CHAT_MESSAGE = "REPLY_CHAT_MESSAGE"
+	WORKER_REGENERATE_TURN    = "REGENERATE_TURN"
+	WORKER_REPAIR_MEDIA       = "REPAIR_DIALOG_MEDIA"
 )
 
 // RegisterDialogWorkers register dialog workers to queue
 func RegisterDialogWorkers(queue *client.QueueClient, service *DialogService) {
 
 	// Job Generate Dialog
-	queue.RegisterWorker(WORKER_GENERATE_DIALOG, func(ctx context.Context, job client.Job) error {
+	queue.RegisterDurableWorker(WORKER_GENERATE_DIALOG, GenerateDialogPayload{}, func(ctx context.Context, job client.Job) error {
 		payload, ok := job.Payload.(GenerateDialogPayload)
 		if !ok {
 			return fmt.Errorf("invalid payload type")
 		}
-		service.ProcessGenerateDialog(ctx, payload)
+		if err := service.ProcessGenerateDialog(client.WithBudgetBatch(ctx, payload.DialogID), payload); err != nil {
+			return err
+		}
 		return nil
 	})
 
 	// Job Reply Chat Message
-	queue.RegisterWorker(WORKER_REPLY_CHAT_MESSAGE, func(ctx context.Context, job client.Job) error {
+	queue.RegisterDurableWorker(WORKER_REPLY_CHAT_MESSAGE, ReplyChatMessagePayload{}, func(ctx context.Context, job client.Job) error {
 		payload, ok := job.Payload.(ReplyChatMessagePayload)
 		if !ok {
 			return fmt.Errorf("invalid payload type")
 		}
-		service.ProcessReplyChatMessage(ctx, payload)
+		if err := service.ProcessReplyChatMessage(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	// Job Regenerate Script Turn (async=true)
+	queue.RegisterDurableWorker(WORKER_REGENERATE_TURN, RegenerateTurnPayload{}, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(RegenerateTurnPayload)
+		if !ok {
+			return fmt.Errorf("invalid payload type")
+		}
+		if err := service.ProcessRegenerateScriptTurn(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	// Job Repair Dialog Media (queued by the content media check)
+	queue.RegisterDurableWorker(WORKER_REPAIR_MEDIA, RepairMediaPayload{}, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(RepairMediaPayload)
+		if !ok {
+			return fmt.Errorf("invalid payload type")
+		}
+		if err := service.ProcessRepairMedia(client.WithBudgetBatch(ctx, payload.DialogID), payload); err != nil {
+			return err
+		}
 		return nil
 	})
 }
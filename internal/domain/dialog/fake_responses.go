@@ -0,0 +1,52 @@
+//go:build fake
+
+package dialog
+
+import "strings"
+
+// FakeChatResponses are canned answers to this package's prompts for client.FakeProviders, keyed by
+// system prompt (the chat reply prompt up to its first placeholder). They pass the same checks as
+// real answers, so a generated dialog runs through every stage.
+func FakeChatResponses() map[string]string {
+	return map[string]string{
+		dialogGenerationPrompt: `{
+  "description": "A friendly barista helps you order your morning coffee just the way you like it.",
+  "level": "beginner",
+  "tags": ["cafe", "ordering", "coffee"],
+  "audience": "general",
+  "image_prompt": "A cozy cafe counter with a barista and a cup of coffee",
+  "speech_mode": {
+    "situation": "You are at a cafe counter in the morning.",
+    "script": [
+      { "speaker": "AI", "text": "Good morning! What can I get you?" },
+      { "speaker": "User", "text": "I would like a coffee, please.", "missing_words": ["coffee"], "hints": ["A hot drink", "It starts with c"] },
+      { "speaker": "AI", "text": "Would you like milk or sugar?" },
+      { "speaker": "User", "text": "Just a little milk, thank you.", "missing_words": ["milk"], "hints": ["A white drink"] }
+    ]
+  },
+  "chat_mode": {
+    "situation": "Your coffee is ready and the barista asks if you want something to eat.",
+    "objectives": {
+      "requirements": ["Ask what food is available", "Order one item"],
+      "persuasion": ["Recommend the fresh croissant"],
+      "constraints": ["Only pastries are left"]
+    }
+  }
+}`,
+		regenerateTurnPrompt: `{
+  "text": "Could I have a coffee with milk, please?",
+  "missing_words": ["coffee"],
+  "hints": ["A hot drink"]
+}`,
+		reviewDialogPrompt: `{
+  "naturalness": 85,
+  "correctness": 90,
+  "issues": []
+}`,
+		submitChatPrompt[:strings.Index(submitChatPrompt, "%s")]: `{
+  "reply_message": "We have croissants and muffins today. Which one would you like?",
+  "completed_objectives_indexes": [0],
+  "suggestion": "Well done. You could also say: What do you have to eat?"
+}`,
+	}
+}
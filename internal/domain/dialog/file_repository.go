@@ -8,6 +8,8 @@ import (
 	"mime/multipart"
 	"os"
 	"os/exec"
+	"path"
+	"strings"
 
 	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
@@ -17,22 +19,28 @@ import (
 type FileRepository interface {
 	UploadBytes(ctx context.Context, data []byte, key, contentType string) (string, *errors.AppError)
 	ConvertAudioToM4A(ctx context.Context, srcPath, dstPath string) *errors.AppError
+	NormalizeAudio(ctx context.Context, path string) *errors.AppError
+	TrimSilence(ctx context.Context, path string) *errors.AppError
+	CheckRecordingDuration(ctx context.Context, path string) *errors.AppError
+	NormalizeAudioBytes(ctx context.Context, data []byte, ext string) ([]byte, *errors.AppError)
 	CreateTempFile(file multipart.File, tempPath string) (*os.File, *errors.AppError)
+	UploadVariants(ctx context.Context, data []byte, key string, formats []client.MediaFormat) map[string]string
 }
 
 type fileRepository struct {
 	cloudflare *client.CloudflareClient
+	ffmpeg     *client.FFmpegClient
 	log        *slog.Logger
 }
 
 // NewFileRepository creates a new dialog file repository.
-func NewFileRepository(cloudflare *client.CloudflareClient, log *slog.Logger) FileRepository {
-	return &fileRepository{cloudflare: cloudflare, log: log}
+func NewFileRepository(cloudflare *client.CloudflareClient, ffmpeg *client.FFmpegClient, log *slog.Logger) FileRepository {
+	return &fileRepository{cloudflare: cloudflare, ffmpeg: ffmpeg, log: log}
 }
 
 func (r *fileRepository) UploadBytes(ctx context.Context, data []byte, key, contentType string) (string, *errors.AppError) {
 	if r.cloudflare == nil {
-		return "", errors.Internal("dialog storage client not configured")
+		return "", errors.Unsupported("dialog storage client not configured")
 	}
 
 	url, err := r.cloudflare.UploadR2Object(ctx, key, bytes.NewReader(data), contentType)
@@ -43,10 +51,32 @@ func (r *fileRepository) UploadBytes(ctx context.Context, data []byte, key, cont
 	return url, nil
 }
 
+// UploadVariants transcodes media and uploads each format next to key (same name, new extension).
+// It is best effort: formats that fail are logged and left out of the returned content type -> URL map.
+func (r *fileRepository) UploadVariants(ctx context.Context, data []byte, key string, formats []client.MediaFormat) map[string]string {
+	variants := make(map[string]string, len(formats))
+	srcExt := path.Ext(key)
+	for _, format := range formats {
+		encoded, err := r.ffmpeg.TranscodeBytes(ctx, data, srcExt, format.Ext)
+		if err != nil {
+			r.log.Warn("Failed to transcode dialog media variant", "key", key, "format", format.Name, "error", err.Error())
+			continue
+		}
+
+		url, err := r.UploadBytes(ctx, encoded, strings.TrimSuffix(key, srcExt)+format.Ext, format.ContentType)
+		if err != nil {
+			r.log.Warn("Failed to upload dialog media variant", "key", key, "format", format.Name, "error", err.Error())
+			continue
+		}
+		variants[format.ContentType] = url
+	}
+	return variants
+}
+
 // ConvertAudioToM4A converts a WAV audio file to M4A using ffmpeg.
 func (r *fileRepository) ConvertAudioToM4A(ctx context.Context, srcPath, dstPath string) *errors.AppError {
 	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-i", srcPath,
-		"-af", "afftdn,loudnorm=I=-16:TP=-1.5:LRA=11",
+		"-af", "afftdn,"+r.ffmpeg.LoudnormFilter(),
 		"-c:a", "aac", "-b:a", "64k", "-ac", "1",
 		"-ar", "16000", "-movflags", "faststart",
 		dstPath,
@@ -61,6 +91,43 @@ func (r *fileRepository) ConvertAudioToM4A(ctx context.Context, srcPath, dstPath
 	return nil
 }
 
+// NormalizeAudio applies loudness normalization to the audio file at path (in place).
+func (r *fileRepository) NormalizeAudio(ctx context.Context, path string) *errors.AppError {
+	if err := r.ffmpeg.NormalizeLoudnessInPlace(ctx, path); err != nil {
+		r.log.Error("FFmpeg loudness normalization failed", "path", path, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// NormalizeAudioBytes applies loudness normalization to in-memory audio such as TTS output.
+func (r *fileRepository) NormalizeAudioBytes(ctx context.Context, data []byte, ext string) ([]byte, *errors.AppError) {
+	normalized, err := r.ffmpeg.NormalizeLoudnessBytes(ctx, data, ext)
+	if err != nil {
+		r.log.Error("FFmpeg loudness normalization failed", "ext", ext, "error", err.Error())
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// TrimSilence removes leading and trailing silence from the audio file at path (in place).
+func (r *fileRepository) TrimSilence(ctx context.Context, path string) *errors.AppError {
+	if err := r.ffmpeg.TrimSilenceInPlace(ctx, path); err != nil {
+		r.log.Error("FFmpeg silence trim failed", "path", path, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// CheckRecordingDuration rejects user recordings longer than the configured cap.
+func (r *fileRepository) CheckRecordingDuration(ctx context.Context, path string) *errors.AppError {
+	if err := r.ffmpeg.CheckMaxDuration(ctx, path); err != nil {
+		r.log.Warn("Recording duration check failed", "path", path, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
 // CreateTempFile saves a multipart file to a temporary file.
 func (r *fileRepository) CreateTempFile(file multipart.File, tempPath string) (*os.File, *errors.AppError) {
 	// 1. ตรวจสอบว่าไฟล์ต้นทางไม่ได้ว่างเปล่า หรือหัวอ่านค้างอยู่ที่ท้ายไฟล์
@@ -0,0 +1,101 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// fixProposalTTL is how long an editor has to confirm a proposed fix.
+const fixProposalTTL = 30 * time.Minute
+
+// FixChange is one text field a fix changes. List fields are shown one entry per line.
+type FixChange struct {
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// FixProposal is an AI correction of a dialog waiting for the editor to apply it.
+type FixProposal struct {
+	ID          string      `json:"id"`
+	DialogID    string      `json:"dialog_id"`
+	UserID      string      `json:"-"`
+	Note        string      `json:"note"`
+	BaseVersion int         `json:"base_version"`
+	Changes     []FixChange `json:"changes"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	// Corrected details and the text signature of the details they were made from
+	Details   json.RawMessage `json:"-"`
+	Signature string          `json:"-"`
+}
+
+// FixRepository keeps proposed fixes in Redis until they are applied or expire.
+type FixRepository interface {
+	SaveProposal(ctx context.Context, proposal *FixProposal) *errors.AppError
+	GetProposal(ctx context.Context, fixID string) (*FixProposal, *errors.AppError)
+	DeleteProposal(ctx context.Context, fixID string) *errors.AppError
+}
+
+type fixRepository struct {
+	redis *client.RedisClient
+}
+
+// NewFixRepository creates a new dialog fix repository.
+func NewFixRepository(redis *client.RedisClient) FixRepository {
+	return &fixRepository{redis: redis}
+}
+
+func fixKey(fixID string) string {
+	return fmt.Sprintf("dialog_fix:%s", fixID)
+}
+
+func (r *fixRepository) SaveProposal(ctx context.Context, proposal *FixProposal) *errors.AppError {
+	proposal.ExpiresAt = time.Now().UTC().Add(fixProposalTTL)
+	data, _ := json.Marshal(proposal)
+
+	key := fixKey(proposal.ID)
+	if err := r.redis.HSet(ctx, key,
+		"proposal", string(data),
+		"user_id", proposal.UserID,
+		"details", string(proposal.Details),
+		"signature", proposal.Signature,
+	); err != nil {
+		return errors.InternalWrap("failed to save fix proposal", err)
+	}
+	if err := r.redis.SetExpiry(ctx, key, fixProposalTTL); err != nil {
+		return errors.InternalWrap("failed to save fix proposal", err)
+	}
+	return nil
+}
+
+// GetProposal returns the proposal, or nil once it was applied or expired.
+func (r *fixRepository) GetProposal(ctx context.Context, fixID string) (*FixProposal, *errors.AppError) {
+	fields, err := r.redis.HGetAll(ctx, fixKey(fixID))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get fix proposal", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var proposal FixProposal
+	if err := json.Unmarshal([]byte(fields["proposal"]), &proposal); err != nil {
+		return nil, errors.InternalWrap("failed to parse fix proposal", err)
+	}
+	proposal.UserID = fields["user_id"]
+	proposal.Details = json.RawMessage(fields["details"])
+	proposal.Signature = fields["signature"]
+	return &proposal, nil
+}
+
+func (r *fixRepository) DeleteProposal(ctx context.Context, fixID string) *errors.AppError {
+	if err := r.redis.Del(ctx, fixKey(fixID)); err != nil {
+		return errors.InternalWrap("failed to delete fix proposal", err)
+	}
+	return nil
+}
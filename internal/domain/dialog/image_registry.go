@@ -0,0 +1,90 @@
+package dialog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// RegisteredImage is a generated dialog image other dialogs with the same prompt can share.
+type RegisteredImage struct {
+	PromptHash string
+	ImageURL   string
+	Variants   map[string]string
+	DialogID   string // dialog the image was generated for
+}
+
+// ImageRegistry maps image prompts to the images already generated for them.
+type ImageRegistry interface {
+	// FindImage returns the image of a prompt hash and counts the reuse, or nil when there is none.
+	FindImage(ctx context.Context, promptHash string) (*RegisteredImage, *errors.AppError)
+	// RegisterImage records a new image; an image already registered for the hash is kept.
+	RegisterImage(ctx context.Context, image *RegisteredImage) *errors.AppError
+	// ForgetImage drops the entries of an image URL (it is being replaced) so it is not shared again.
+	ForgetImage(ctx context.Context, imageURL string) *errors.AppError
+}
+
+type imageRegistry struct {
+	db *client.PostgresClient
+}
+
+// NewImageRegistry creates a new dialog image registry.
+func NewImageRegistry(db *client.PostgresClient) ImageRegistry {
+	return &imageRegistry{db: db}
+}
+
+// imagePromptHash identifies an image by its prompt and style, ignoring case and spacing.
+func imagePromptHash(prompt, style string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(prompt)), " ")
+	sum := sha256.Sum256([]byte(normalized + "\n" + strings.ToLower(style)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *imageRegistry) FindImage(ctx context.Context, promptHash string) (*RegisteredImage, *errors.AppError) {
+	image := &RegisteredImage{PromptHash: promptHash}
+	var variants []byte
+	err := r.db.Pool.QueryRow(ctx, `
+		UPDATE image_registry SET reuse_count = reuse_count + 1, last_reused_at = NOW()
+		WHERE prompt_hash = $1
+		RETURNING image_url, variants, COALESCE(dialog_id::text, '')
+	`, promptHash).Scan(&image.ImageURL, &variants, &image.DialogID)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.InternalWrap("failed to find registered image", err)
+	}
+	if len(variants) > 0 {
+		_ = json.Unmarshal(variants, &image.Variants)
+	}
+	return image, nil
+}
+
+func (r *imageRegistry) RegisterImage(ctx context.Context, image *RegisteredImage) *errors.AppError {
+	var variants []byte
+	if len(image.Variants) > 0 {
+		variants, _ = json.Marshal(image.Variants)
+	}
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO image_registry (prompt_hash, image_url, variants, dialog_id)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+		ON CONFLICT (prompt_hash) DO NOTHING
+	`, image.PromptHash, image.ImageURL, variants, image.DialogID)
+	if err != nil {
+		return errors.InternalWrap("failed to register image", err)
+	}
+	return nil
+}
+
+func (r *imageRegistry) ForgetImage(ctx context.Context, imageURL string) *errors.AppError {
+	if _, err := r.db.Pool.Exec(ctx, `DELETE FROM image_registry WHERE image_url = $1`, imageURL); err != nil {
+		return errors.InternalWrap("failed to forget registered image", err)
+	}
+	return nil
+}
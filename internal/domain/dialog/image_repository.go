@@ -9,21 +9,40 @@ import (
 
 // ImageRepository generates dialog images.
 type ImageRepository interface {
-	GenerateImage(ctx context.Context, prompt string) ([]byte, *errors.AppError)
+	// GenerateImage draws prompt in the style preset ("" uses the default, see ResolveStyle),
+	// shaped by opts, and returns one image per sample.
+	GenerateImage(ctx context.Context, prompt, style string, opts client.ImageOptions) ([][]byte, *errors.AppError)
+	// DetectText returns the text OCR reads in a generated image.
+	DetectText(ctx context.Context, image []byte) (string, *errors.AppError)
+	// ResolveStyle returns the preset a request for style is drawn in ("" uses the default of the
+	// context's organization, then the configured one).
+	ResolveStyle(ctx context.Context, style string) string
 }
 
 type imageRepository struct {
-	imageClient *client.GeminiImageClient
+	imageClient client.ImageClient
+	styles      *client.ImageStyles
 }
 
 // NewImageRepository creates a new dialog image repository.
-func NewImageRepository(imageClient *client.GeminiImageClient) ImageRepository {
-	return &imageRepository{imageClient: imageClient}
+func NewImageRepository(imageClient client.ImageClient, styles *client.ImageStyles) ImageRepository {
+	return &imageRepository{imageClient: imageClient, styles: styles}
 }
 
-func (r *imageRepository) GenerateImage(ctx context.Context, prompt string) ([]byte, *errors.AppError) {
+func (r *imageRepository) GenerateImage(ctx context.Context, prompt, style string, opts client.ImageOptions) ([][]byte, *errors.AppError) {
 	if r.imageClient == nil {
-		return nil, errors.Internal("dialog image client not configured")
+		return nil, errors.Unsupported("dialog image client not configured")
 	}
-	return r.imageClient.GenerateImage(ctx, prompt)
+	return r.imageClient.GenerateImage(ctx, r.styles.Apply(ctx, prompt, style), opts)
+}
+
+func (r *imageRepository) DetectText(ctx context.Context, image []byte) (string, *errors.AppError) {
+	if r.imageClient == nil {
+		return "", errors.Unsupported("dialog image client not configured")
+	}
+	return r.imageClient.DetectText(ctx, image)
+}
+
+func (r *imageRepository) ResolveStyle(ctx context.Context, style string) string {
+	return r.styles.Resolve(ctx, style)
 }
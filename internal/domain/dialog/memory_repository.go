@@ -0,0 +1,49 @@
+package dialog
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// MemoryRepository keeps the prompt history of chat and sparring sessions.
+type MemoryRepository interface {
+	History(ctx context.Context, sessionID string) ([]ChatMessage, *errors.AppError)
+	Append(ctx context.Context, sessionID string, messages ...ChatMessage) *errors.AppError
+	Clear(ctx context.Context, sessionID string) *errors.AppError
+}
+
+type memoryRepository struct {
+	memory *client.ConversationMemory
+}
+
+// NewMemoryRepository creates a new dialog memory repository.
+func NewMemoryRepository(memory *client.ConversationMemory) MemoryRepository {
+	return &memoryRepository{memory: memory}
+}
+
+func (r *memoryRepository) History(ctx context.Context, sessionID string) ([]ChatMessage, *errors.AppError) {
+	messages, err := r.memory.History(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		history = append(history, ChatMessage{Role: msg.Role, Content: msg.Content})
+	}
+	return history, nil
+}
+
+func (r *memoryRepository) Append(ctx context.Context, sessionID string, messages ...ChatMessage) *errors.AppError {
+	turns := make([]client.ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		turns = append(turns, client.ChatMessage{Role: msg.Role, Content: msg.Content})
+	}
+	return r.memory.Append(ctx, sessionID, turns...)
+}
+
+func (r *memoryRepository) Clear(ctx context.Context, sessionID string) *errors.AppError {
+	return r.memory.Clear(ctx, sessionID)
+}
@@ -0,0 +1,75 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// firstID sorts before every action id, so a scan starts from it.
+const firstID = "00000000-0000-0000-0000-000000000000"
+
+// ActionMetadata is the stored (possibly encrypted) metadata of a user action.
+type ActionMetadata struct {
+	ID         string
+	ActionType string
+	Metadata   json.RawMessage
+}
+
+// EncryptionRepository reads and rewrites action metadata as it is stored, without decrypting it.
+type EncryptionRepository interface {
+	ListActions(ctx context.Context, actionTypes []string, afterID string, limit int) ([]*ActionMetadata, *errors.AppError)
+	ReplaceMetadata(ctx context.Context, id string, previous, metadata json.RawMessage) (bool, *errors.AppError)
+}
+
+type encryptionRepository struct {
+	db *client.PostgresClient
+}
+
+// NewEncryptionRepository creates a new encryption repository.
+func NewEncryptionRepository(db *client.PostgresClient) EncryptionRepository {
+	return &encryptionRepository{db: db}
+}
+
+// ListActions returns a page of actions of the given types, ordered by id after afterID.
+func (r *encryptionRepository) ListActions(ctx context.Context, actionTypes []string, afterID string, limit int) ([]*ActionMetadata, *errors.AppError) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id::text, action_type::text, metadata
+		FROM user_actions
+		WHERE action_type::text = ANY($1) AND id > $2::uuid AND metadata IS NOT NULL
+		ORDER BY id
+		LIMIT $3
+	`, actionTypes, afterID, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list actions", err)
+	}
+	defer rows.Close()
+
+	result := make([]*ActionMetadata, 0)
+	for rows.Next() {
+		var a ActionMetadata
+		if err := rows.Scan(&a.ID, &a.ActionType, &a.Metadata); err != nil {
+			return nil, errors.InternalWrap("failed to scan action", err)
+		}
+		result = append(result, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list actions", err)
+	}
+	return result, nil
+}
+
+// ReplaceMetadata rewrites an action's metadata unless it changed since it was read; it reports
+// whether the row was written.
+func (r *encryptionRepository) ReplaceMetadata(ctx context.Context, id string, previous, metadata json.RawMessage) (bool, *errors.AppError) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE user_actions SET metadata = $3
+		WHERE id = $1 AND metadata = $2::jsonb
+	`, id, previous, metadata)
+	if err != nil {
+		return false, errors.InternalWrap("failed to replace action metadata", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
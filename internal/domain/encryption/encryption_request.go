@@ -0,0 +1,4 @@
+package encryption
+
+// ReencryptPayload is the payload for the field re-encryption job
+type ReencryptPayload struct{}
@@ -0,0 +1,83 @@
+package encryption
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// reencryptPageSize is how many actions are read per query.
+const reencryptPageSize = 200
+
+// EncryptionService keeps sensitive fields at rest encrypted with the active key.
+type EncryptionService struct {
+	encryptionRepo EncryptionRepository
+	cipher         *client.FieldCipher
+	log            *slog.Logger
+}
+
+// NewEncryptionService creates a new encryption service.
+func NewEncryptionService(encryptionRepo EncryptionRepository, cipher *client.FieldCipher, log *slog.Logger) *EncryptionService {
+	return &EncryptionService{
+		encryptionRepo: encryptionRepo,
+		cipher:         cipher,
+		log:            log,
+	}
+}
+
+// Worker: Reencrypt
+// Encrypts sensitive fields still stored as plaintext or under an older key with the active key.
+// A row changed by a request while it was being rewritten is left for the next run; that request
+// already sealed it with the active key.
+func (s *EncryptionService) Reencrypt(ctx context.Context, payload ReencryptPayload) *errors.AppError {
+	if s.cipher == nil {
+		return nil
+	}
+
+	actionTypes := make([]string, 0, len(client.SensitiveActionFields))
+	for actionType := range client.SensitiveActionFields {
+		actionTypes = append(actionTypes, actionType)
+	}
+	sort.Strings(actionTypes)
+
+	scanned, rewritten, skipped := 0, 0, 0
+	afterID := firstID
+	for {
+		actions, err := s.encryptionRepo.ListActions(ctx, actionTypes, afterID, reencryptPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range actions {
+			metadata, changed, rotateErr := s.cipher.RotateAction(a.ActionType, a.Metadata)
+			if rotateErr != nil {
+				// A key missing from the ring must not stop the rest of the scan
+				s.log.Error("Failed to re-encrypt action", "action_id", a.ID, "error", rotateErr)
+				skipped++
+				continue
+			}
+			if !changed {
+				continue
+			}
+			written, err := s.encryptionRepo.ReplaceMetadata(ctx, a.ID, a.Metadata, metadata)
+			if err != nil {
+				return err
+			}
+			if written {
+				rewritten++
+			}
+		}
+
+		scanned += len(actions)
+		if len(actions) < reencryptPageSize {
+			break
+		}
+		afterID = actions[len(actions)-1].ID
+	}
+
+	s.log.Info("Field re-encryption finished", "key", s.cipher.ActiveKey(), "scanned", scanned, "rewritten", rewritten, "skipped", skipped)
+	return nil
+}
@@ -0,0 +1,29 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_REENCRYPT_FIELDS = "worker_reencrypt_fields"
+)
+
+// RegisterEncryptionWorkers register encryption workers to queue
+func RegisterEncryptionWorkers(queue *client.QueueClient, service *EncryptionService) {
+
+	// Job Re-encrypt Fields
+	queue.RegisterWorker(WORKER_REENCRYPT_FIELDS, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(ReencryptPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_REENCRYPT_FIELDS)
+		}
+		if err := service.Reencrypt(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
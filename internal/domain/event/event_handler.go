@@ -0,0 +1,36 @@
+package event
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// EventHandler handles activity event HTTP endpoints.
+type EventHandler struct {
+	service *EventService
+}
+
+// NewEventHandler creates a new event handler.
+func NewEventHandler(service *EventService) *EventHandler {
+	return &EventHandler{
+		service: service,
+	}
+}
+
+// IngestEvents handles POST /api/v1/events.
+func (h *EventHandler) IngestEvents(w http.ResponseWriter, r *http.Request) {
+	var req IngestEventsRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.IngestEvents(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Accepted(w, result)
+}
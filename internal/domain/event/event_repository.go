@@ -0,0 +1,199 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// partitionPrefix names the monthly partitions of activity_events (activity_events_2026_01).
+const partitionPrefix = "activity_events_"
+
+// partitionMonthLayout is the month part of a partition name.
+const partitionMonthLayout = "2006_01"
+
+// ActivityEvent is a row of the activity_events table.
+type ActivityEvent struct {
+	UserID     string
+	EventType  string
+	LearningID *string
+	DurationMs *int
+	Metadata   json.RawMessage
+	OccurredAt time.Time
+}
+
+// EventPartition is one monthly partition of activity_events.
+type EventPartition struct {
+	Name  string
+	Month time.Time // first instant of the month (UTC)
+}
+
+// EventRepository appends client activity events and maintains their monthly partitions.
+type EventRepository interface {
+	InsertEvents(ctx context.Context, events []*ActivityEvent) (int, *errors.AppError)
+	CreatePartition(ctx context.Context, month time.Time) *errors.AppError
+	ListPartitions(ctx context.Context) ([]*EventPartition, *errors.AppError)
+	ExportPartition(ctx context.Context, partition *EventPartition, w io.Writer) (int, *errors.AppError)
+	DropPartition(ctx context.Context, partition *EventPartition) *errors.AppError
+}
+
+type eventRepository struct {
+	db *client.PostgresClient
+}
+
+// NewEventRepository creates a new event repository.
+func NewEventRepository(db *client.PostgresClient) EventRepository {
+	return &eventRepository{db: db}
+}
+
+// InsertEvents stores the batch in one transaction and returns how many rows were written.
+// Events for learning items that no longer exist are skipped.
+func (r *eventRepository) InsertEvents(ctx context.Context, events []*ActivityEvent) (int, *errors.AppError) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	stored := 0
+	for _, e := range events {
+		metadata := e.Metadata
+		if len(metadata) == 0 {
+			metadata = json.RawMessage("{}")
+		}
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO activity_events (user_id, event_type, learning_id, duration_ms, metadata, occurred_at)
+			SELECT $1, $2, $3::uuid, $4, $5, $6
+			WHERE $3::uuid IS NULL OR EXISTS (SELECT 1 FROM learning_items WHERE id = $3::uuid)
+		`, e.UserID, e.EventType, e.LearningID, e.DurationMs, metadata, e.OccurredAt)
+		if err != nil {
+			return 0, errors.InternalWrap("failed to insert activity event", err)
+		}
+		stored += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, errors.InternalWrap("failed to commit activity events", err)
+	}
+	return stored, nil
+}
+
+// CreatePartition creates the partition of month (UTC) unless it exists.
+func (r *eventRepository) CreatePartition(ctx context.Context, month time.Time) *errors.AppError {
+	from := month.UTC()
+	to := from.AddDate(0, 1, 0)
+	name := pgx.Identifier{partitionPrefix + from.Format(partitionMonthLayout)}.Sanitize()
+
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF activity_events FOR VALUES FROM ('%s') TO ('%s')`,
+		name, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	if _, err := r.db.Pool.Exec(ctx, query); err != nil {
+		return errors.InternalWrap("failed to create activity event partition", err)
+	}
+	return nil
+}
+
+// ListPartitions returns the monthly partitions, oldest first. The default partition is not listed.
+func (r *eventRepository) ListPartitions(ctx context.Context) ([]*EventPartition, *errors.AppError) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'activity_events'
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list activity event partitions", err)
+	}
+	defer rows.Close()
+
+	partitions := make([]*EventPartition, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.InternalWrap("failed to scan activity event partition", err)
+		}
+		month, err := time.Parse(partitionMonthLayout, strings.TrimPrefix(name, partitionPrefix))
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, &EventPartition{Name: name, Month: month})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list activity event partitions", err)
+	}
+
+	return partitions, nil
+}
+
+// ExportPartition writes the partition's events to w as JSON lines and returns how many were written.
+func (r *eventRepository) ExportPartition(ctx context.Context, partition *EventPartition, w io.Writer) (int, *errors.AppError) {
+	query := `
+		SELECT id, user_id::text, event_type, learning_id::text, duration_ms, COALESCE(metadata, '{}'::jsonb), occurred_at, created_at
+		FROM ` + pgx.Identifier{partition.Name}.Sanitize() + `
+		ORDER BY occurred_at, id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return 0, errors.InternalWrap("failed to read activity event partition", err)
+	}
+	defer rows.Close()
+
+	var row struct {
+		ID         int64           `json:"id"`
+		UserID     string          `json:"user_id"`
+		EventType  string          `json:"event_type"`
+		LearningID *string         `json:"learning_id"`
+		DurationMs *int            `json:"duration_ms"`
+		Metadata   json.RawMessage `json:"metadata"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		CreatedAt  *time.Time      `json:"created_at"`
+	}
+	enc := json.NewEncoder(w)
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(&row.ID, &row.UserID, &row.EventType, &row.LearningID, &row.DurationMs, &row.Metadata, &row.OccurredAt, &row.CreatedAt); err != nil {
+			return 0, errors.InternalWrap("failed to scan activity event", err)
+		}
+		if err := enc.Encode(row); err != nil {
+			return 0, errors.InternalWrap("failed to write activity event", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, errors.InternalWrap("failed to read activity event partition", err)
+	}
+
+	return count, nil
+}
+
+// DropPartition detaches and drops a partition with its events.
+func (r *eventRepository) DropPartition(ctx context.Context, partition *EventPartition) *errors.AppError {
+	name := pgx.Identifier{partition.Name}.Sanitize()
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `ALTER TABLE activity_events DETACH PARTITION `+name); err != nil {
+		return errors.InternalWrap("failed to detach activity event partition", err)
+	}
+	if _, err := tx.Exec(ctx, `DROP TABLE `+name); err != nil {
+		return errors.InternalWrap("failed to drop activity event partition", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit activity event partition drop", err)
+	}
+	return nil
+}
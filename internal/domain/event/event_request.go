@@ -0,0 +1,129 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Client event types
+const (
+	EVENT_ITEM_VIEWED    = "item_viewed"
+	EVENT_AUDIO_PLAYED   = "audio_played"
+	EVENT_TURN_COMPLETED = "turn_completed"
+)
+
+// AllowedEventTypes lists the event types clients may send
+var AllowedEventTypes = map[string]bool{
+	EVENT_ITEM_VIEWED:    true,
+	EVENT_AUDIO_PLAYED:   true,
+	EVENT_TURN_COMPLETED: true,
+}
+
+// Batch limits
+const (
+	maxEventsPerBatch = 100
+	maxEventMetadata  = 4 << 10 // bytes per event
+	maxEventAge       = 7 * 24 * time.Hour
+	maxEventClockSkew = 5 * time.Minute
+	maxEventDuration  = 6 * 60 * 60 * 1000 // 6 hours in ms
+)
+
+// MaintainPartitionsPayload is the payload for the partition maintenance job
+type MaintainPartitionsPayload struct{}
+
+// IngestEventsRequest is the HTTP request struct for a batch of client events
+type IngestEventsRequest struct {
+	UserID string
+	Events []struct {
+		Type       string          `json:"type"`
+		LearningID *string         `json:"learning_id"`
+		DurationMs *int            `json:"duration_ms"`
+		Metadata   json.RawMessage `json:"metadata"`
+		OccurredAt *time.Time      `json:"occurred_at"`
+	} `json:"events"`
+}
+
+// IngestEventsInput is the input struct for service
+type IngestEventsInput struct {
+	UserID string
+	Events []*ActivityEvent
+}
+
+func (req *IngestEventsRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse JSON Body
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	if len(req.Events) == 0 {
+		return errors.Validation("events must not be empty")
+	}
+	if len(req.Events) > maxEventsPerBatch {
+		return errors.Validation(fmt.Sprintf("at most %d events per batch", maxEventsPerBatch))
+	}
+
+	now := time.Now().UTC()
+	for i, e := range req.Events {
+		if !AllowedEventTypes[e.Type] {
+			return errors.Validation(fmt.Sprintf("events[%d].type is not supported", i))
+		}
+		if e.LearningID != nil {
+			if _, err := uuid.Parse(*e.LearningID); err != nil {
+				return errors.Validation(fmt.Sprintf("events[%d].learning_id must be a UUID", i))
+			}
+		}
+		if e.DurationMs != nil && (*e.DurationMs < 0 || *e.DurationMs > maxEventDuration) {
+			return errors.Validation(fmt.Sprintf("events[%d].duration_ms is out of range", i))
+		}
+		if len(e.Metadata) > maxEventMetadata {
+			return errors.Validation(fmt.Sprintf("events[%d].metadata is too large", i))
+		}
+		if len(e.Metadata) > 0 && string(e.Metadata) != "null" {
+			var object map[string]interface{}
+			if err := json.Unmarshal(e.Metadata, &object); err != nil {
+				return errors.Validation(fmt.Sprintf("events[%d].metadata must be an object", i))
+			}
+		}
+		if e.OccurredAt != nil && (e.OccurredAt.After(now.Add(maxEventClockSkew)) || e.OccurredAt.Before(now.Add(-maxEventAge))) {
+			return errors.Validation(fmt.Sprintf("events[%d].occurred_at must be within the last 7 days", i))
+		}
+	}
+
+	return nil
+}
+
+func (req *IngestEventsRequest) ToInput() IngestEventsInput {
+	now := time.Now().UTC()
+	events := make([]*ActivityEvent, 0, len(req.Events))
+	for _, e := range req.Events {
+		occurredAt := now
+		if e.OccurredAt != nil {
+			occurredAt = e.OccurredAt.UTC()
+		}
+		metadata := e.Metadata
+		if string(metadata) == "null" {
+			metadata = nil
+		}
+		events = append(events, &ActivityEvent{
+			UserID:     req.UserID,
+			EventType:  e.Type,
+			LearningID: e.LearningID,
+			DurationMs: e.DurationMs,
+			Metadata:   metadata,
+			OccurredAt: occurredAt,
+		})
+	}
+	return IngestEventsInput{UserID: req.UserID, Events: events}
+}
@@ -0,0 +1,112 @@
+package event
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// partitionMonthsAhead is how many months of partitions are created ahead of the current one.
+const partitionMonthsAhead = 2
+
+// IngestEventsResponse reports how many events of a batch were stored.
+type IngestEventsResponse struct {
+	Received int `json:"received"`
+	Stored   int `json:"stored"`
+}
+
+// EventOptions controls how long activity events are kept.
+type EventOptions struct {
+	Retention time.Duration // monthly partitions ending before now - Retention are dropped (0 keeps them)
+	Archive   bool          // upload a partition to R2 as gzipped JSON lines before dropping it
+}
+
+// EventService ingests client activity events.
+type EventService struct {
+	eventRepo EventRepository
+	fileRepo  FileRepository
+	opts      EventOptions
+}
+
+// NewEventService creates a new EventService.
+func NewEventService(eventRepo EventRepository, fileRepo FileRepository, opts EventOptions) *EventService {
+	return &EventService{
+		eventRepo: eventRepo,
+		fileRepo:  fileRepo,
+		opts:      opts,
+	}
+}
+
+// IngestEvents appends a batch of validated client events.
+func (s *EventService) IngestEvents(ctx context.Context, input IngestEventsInput) (*IngestEventsResponse, *errors.AppError) {
+	stored, err := s.eventRepo.InsertEvents(ctx, input.Events)
+	if err != nil {
+		return nil, err
+	}
+	return &IngestEventsResponse{Received: len(input.Events), Stored: stored}, nil
+}
+
+// Worker: MaintainPartitions
+// Creates the partitions of the coming months, then archives and drops the months past retention.
+func (s *EventService) MaintainPartitions(ctx context.Context, payload MaintainPartitionsPayload) *errors.AppError {
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= partitionMonthsAhead; i++ {
+		if err := s.eventRepo.CreatePartition(ctx, current.AddDate(0, i, 0)); err != nil {
+			return err
+		}
+	}
+
+	if s.opts.Retention <= 0 {
+		return nil
+	}
+
+	partitions, err := s.eventRepo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-s.opts.Retention)
+	for _, partition := range partitions {
+		// Only whole months past retention are dropped
+		if partition.Month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if s.opts.Archive {
+			if err := s.archivePartition(ctx, partition); err != nil {
+				return err
+			}
+		}
+		if err := s.eventRepo.DropPartition(ctx, partition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivePartition uploads the partition to archive/activity_events/YYYY_MM.jsonl.gz.
+func (s *EventService) archivePartition(ctx context.Context, partition *EventPartition) *errors.AppError {
+	tmp, err := os.CreateTemp("", "activity-events-*.jsonl.gz")
+	if err != nil {
+		return errors.InternalWrap("failed to create archive file", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := gzip.NewWriter(tmp)
+	if _, err := s.eventRepo.ExportPartition(ctx, partition, zw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return errors.InternalWrap("failed to write archive file", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return errors.InternalWrap("failed to read archive file", err)
+	}
+
+	key := "archive/activity_events/" + partition.Month.Format(partitionMonthLayout) + ".jsonl.gz"
+	return s.fileRepo.Upload(ctx, key, tmp)
+}
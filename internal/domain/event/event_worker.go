@@ -0,0 +1,29 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_MAINTAIN_PARTITIONS = "worker_maintain_event_partitions"
+)
+
+// RegisterEventWorkers register activity event workers to queue
+func RegisterEventWorkers(queue *client.QueueClient, service *EventService) {
+
+	// Job Maintain Partitions
+	queue.RegisterWorker(WORKER_MAINTAIN_PARTITIONS, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(MaintainPartitionsPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_MAINTAIN_PARTITIONS)
+		}
+		if err := service.MaintainPartitions(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
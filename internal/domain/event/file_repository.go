@@ -0,0 +1,30 @@
+package event
+
+import (
+	"context"
+	"io"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// FileRepository stores archived activity events in R2.
+type FileRepository interface {
+	Upload(ctx context.Context, key string, body io.Reader) *errors.AppError
+}
+
+type fileRepository struct {
+	cloudflare *client.CloudflareClient
+}
+
+// NewFileRepository creates a new event file repository.
+func NewFileRepository(cloudflare *client.CloudflareClient) FileRepository {
+	return &fileRepository{cloudflare: cloudflare}
+}
+
+func (r *fileRepository) Upload(ctx context.Context, key string, body io.Reader) *errors.AppError {
+	if _, err := r.cloudflare.UploadR2Object(ctx, key, body, "application/gzip"); err != nil {
+		return errors.InternalWrap("failed to upload activity event archive", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package favorite
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// FavoriteHandler handles favorite HTTP endpoints.
+type FavoriteHandler struct {
+	service *FavoriteService
+}
+
+// NewFavoriteHandler creates a new favorite handler.
+func NewFavoriteHandler(service *FavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{
+		service: service,
+	}
+}
+
+// ListFavorites handles GET /api/v1/favorites
+func (h *FavoriteHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	var req ListFavoritesRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListFavorites(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// AddFavorite handles PUT /api/v1/favorites/{contentType}/{contentID}
+func (h *FavoriteHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	var req FavoriteRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	favorite, err := h.service.AddFavorite(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, favorite)
+}
+
+// RemoveFavorite handles DELETE /api/v1/favorites/{contentType}/{contentID}
+func (h *FavoriteHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	var req FavoriteRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.RemoveFavorite(r.Context(), req.ToInput()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,133 @@
+package favorite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Favorite is content a user favorited, with the title it is listed under.
+type Favorite struct {
+	ContentType string    `json:"content_type"`
+	ContentID   string    `json:"content_id"`
+	Title       string    `json:"title"`
+	Language    string    `json:"language,omitempty"`
+	Level       *string   `json:"level,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FavoriteRepository stores the favorites of users.
+type FavoriteRepository interface {
+	IsFavoritable(ctx context.Context, userID, contentType, contentID string) (bool, *errors.AppError)
+	AddFavorite(ctx context.Context, userID, contentType, contentID string) (*Favorite, *errors.AppError)
+	RemoveFavorite(ctx context.Context, userID, contentType, contentID string) (bool, *errors.AppError)
+	ListFavorites(ctx context.Context, userID, contentType string, limit, offset int) ([]*Favorite, int, *errors.AppError)
+}
+
+type favoriteRepository struct {
+	db *client.PostgresClient
+}
+
+// NewFavoriteRepository creates a new favorite repository.
+func NewFavoriteRepository(db *client.PostgresClient) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+// IsFavoritable reports whether the user can see the content: active videos, dialogs that are
+// published or their own, and their own decks or decks that are public or shared by link.
+func (r *favoriteRepository) IsFavoritable(ctx context.Context, userID, contentType, contentID string) (bool, *errors.AppError) {
+	var query string
+	switch contentType {
+	case client.FavoriteVideo:
+		query = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM learning_items l
+			WHERE l.id = $1 AND l.feature_id = %d AND (l.is_active = true OR l.created_by = $2) AND `,
+			client.FeatureVideo) + client.AudienceVisibleSQL("l", "$2") + `)`
+	case client.FavoriteDialog:
+		query = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM learning_items l
+			WHERE l.id = $1 AND l.feature_id = %d AND (l.published_details IS NOT NULL OR l.created_by = $2) AND `,
+			client.FeatureDialog) + client.AudienceVisibleSQL("l", "$2") + `)`
+	case client.FavoriteDeck:
+		query = `SELECT EXISTS (SELECT 1 FROM decks d
+			WHERE d.id = $1 AND (d.user_id::text = $2 OR d.published_at IS NOT NULL OR d.share_token IS NOT NULL))`
+	default:
+		return false, nil
+	}
+
+	var ok bool
+	if err := r.db.Pool.QueryRow(ctx, query, contentID, userID).Scan(&ok); err != nil {
+		return false, errors.InternalWrap("failed to check favorite content", err)
+	}
+	return ok, nil
+}
+
+// AddFavorite favorites the content; favoriting it again keeps the first time.
+func (r *favoriteRepository) AddFavorite(ctx context.Context, userID, contentType, contentID string) (*Favorite, *errors.AppError) {
+	query := `
+		INSERT INTO favorites (user_id, content_type, content_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, content_type, content_id) DO UPDATE SET content_type = EXCLUDED.content_type
+		RETURNING created_at
+	`
+
+	favorite := &Favorite{ContentType: contentType, ContentID: contentID}
+	if err := r.db.Pool.QueryRow(ctx, query, userID, contentType, contentID).Scan(&favorite.CreatedAt); err != nil {
+		return nil, errors.InternalWrap("failed to add favorite", err)
+	}
+	return favorite, nil
+}
+
+func (r *favoriteRepository) RemoveFavorite(ctx context.Context, userID, contentType, contentID string) (bool, *errors.AppError) {
+	cmdTag, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM favorites WHERE user_id = $1 AND content_type = $2 AND content_id = $3
+	`, userID, contentType, contentID)
+	if err != nil {
+		return false, errors.InternalWrap("failed to remove favorite", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// ListFavorites returns the user's favorites, newest first ("" content type lists every type).
+// Favorites of deleted content are left out.
+func (r *favoriteRepository) ListFavorites(ctx context.Context, userID, contentType string, limit, offset int) ([]*Favorite, int, *errors.AppError) {
+	from := `
+		FROM favorites f
+		LEFT JOIN learning_items l ON f.content_type IN ('video', 'dialog') AND l.id = f.content_id
+		LEFT JOIN decks d ON f.content_type = 'deck' AND d.id = f.content_id
+		WHERE f.user_id = $1 AND ($2 = '' OR f.content_type = $2) AND (l.id IS NOT NULL OR d.id IS NOT NULL)
+	`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) `+from, userID, contentType).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count favorites", err)
+	}
+
+	query := `
+		SELECT f.content_type, f.content_id::text, COALESCE(l.content, d.name, ''), COALESCE(l.language, ''), l.level, f.created_at
+	` + from + `
+		ORDER BY f.created_at DESC, f.id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID, contentType, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list favorites", err)
+	}
+	defer rows.Close()
+
+	favorites := make([]*Favorite, 0)
+	for rows.Next() {
+		var favorite Favorite
+		if err := rows.Scan(&favorite.ContentType, &favorite.ContentID, &favorite.Title, &favorite.Language,
+			&favorite.Level, &favorite.CreatedAt); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan favorite", err)
+		}
+		favorites = append(favorites, &favorite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list favorites", err)
+	}
+
+	return favorites, total, nil
+}
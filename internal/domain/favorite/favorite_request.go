@@ -0,0 +1,125 @@
+package favorite
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Pagination limits
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// -------------------------------------------------------------------------
+// Favorite Request
+// -------------------------------------------------------------------------
+
+// FavoriteRequest is the HTTP request struct for adding or removing a favorite
+type FavoriteRequest struct {
+	UserID      string
+	ContentType string
+	ContentID   string
+}
+
+// FavoriteInput is the input struct for service
+type FavoriteInput struct {
+	UserID      string
+	ContentType string
+	ContentID   string
+}
+
+func (req *FavoriteRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.ContentType = strings.ToLower(chi.URLParam(r, "contentType"))
+	if !client.IsFavoriteType(req.ContentType) {
+		return errors.Validation("content type must be video, dialog or deck")
+	}
+
+	req.ContentID = chi.URLParam(r, "contentID")
+	if _, err := uuid.Parse(req.ContentID); err != nil {
+		return errors.Validation("invalid content id")
+	}
+
+	return nil
+}
+
+// ToInput convert FavoriteRequest to FavoriteInput
+func (req *FavoriteRequest) ToInput() FavoriteInput {
+	return FavoriteInput{
+		UserID:      req.UserID,
+		ContentType: req.ContentType,
+		ContentID:   req.ContentID,
+	}
+}
+
+// -------------------------------------------------------------------------
+// List Favorites Request
+// -------------------------------------------------------------------------
+
+// ListFavoritesRequest is the HTTP request struct for listing favorites
+type ListFavoritesRequest struct {
+	UserID      string
+	ContentType string
+	Page        int
+	PageSize    int
+}
+
+// ListFavoritesInput is the input struct for service
+type ListFavoritesInput struct {
+	UserID      string
+	ContentType string
+	Page        int
+	PageSize    int
+	Limit       int
+	Offset      int
+}
+
+// Parse reads the optional type filter and pagination params
+func (req *ListFavoritesRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	q := r.URL.Query()
+	req.ContentType = strings.ToLower(q.Get("type"))
+	if req.ContentType != "" && !client.IsFavoriteType(req.ContentType) {
+		return errors.Validation("type must be video, dialog or deck")
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListFavoritesRequest to ListFavoritesInput
+func (req *ListFavoritesRequest) ToInput() ListFavoritesInput {
+	return ListFavoritesInput{
+		UserID:      req.UserID,
+		ContentType: req.ContentType,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		Limit:       req.PageSize,
+		Offset:      (req.Page - 1) * req.PageSize,
+	}
+}
@@ -0,0 +1,67 @@
+package favorite
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// FavoriteService lets users favorite videos, dialogs and decks.
+type FavoriteService struct {
+	favoriteRepo FavoriteRepository
+}
+
+// ListFavoritesResponse is returned when listing favorites.
+type ListFavoritesResponse struct {
+	Data []*Favorite              `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// NewFavoriteService creates a new FavoriteService.
+func NewFavoriteService(favoriteRepo FavoriteRepository) *FavoriteService {
+	return &FavoriteService{favoriteRepo: favoriteRepo}
+}
+
+// AddFavorite favorites content the user can see.
+func (s *FavoriteService) AddFavorite(ctx context.Context, input FavoriteInput) (*Favorite, *errors.AppError) {
+	ok, err := s.favoriteRepo.IsFavoritable(ctx, input.UserID, input.ContentType, input.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.NotFound(input.ContentType + " not found")
+	}
+
+	return s.favoriteRepo.AddFavorite(ctx, input.UserID, input.ContentType, input.ContentID)
+}
+
+// RemoveFavorite removes content from the user's favorites.
+func (s *FavoriteService) RemoveFavorite(ctx context.Context, input FavoriteInput) *errors.AppError {
+	removed, err := s.favoriteRepo.RemoveFavorite(ctx, input.UserID, input.ContentType, input.ContentID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.NotFound("favorite not found")
+	}
+	return nil
+}
+
+// ListFavorites returns a page of the user's favorites.
+func (s *FavoriteService) ListFavorites(ctx context.Context, input ListFavoritesInput) (*ListFavoritesResponse, *errors.AppError) {
+	favorites, total, err := s.favoriteRepo.ListFavorites(ctx, input.UserID, input.ContentType, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListFavoritesResponse{
+		Data: favorites,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
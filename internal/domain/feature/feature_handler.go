@@ -0,0 +1,30 @@
+package feature
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// FeatureHandler handles feature HTTP endpoints.
+type FeatureHandler struct {
+	service *FeatureService
+}
+
+// NewFeatureHandler creates a new feature handler.
+func NewFeatureHandler(service *FeatureService) *FeatureHandler {
+	return &FeatureHandler{
+		service: service,
+	}
+}
+
+// ListFeatures handles GET /api/v1/features.
+func (h *FeatureHandler) ListFeatures(w http.ResponseWriter, r *http.Request) {
+	features, err := h.service.ListFeatures(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, features)
+}
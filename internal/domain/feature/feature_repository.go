@@ -0,0 +1,54 @@
+package feature
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Feature is a row of the features table.
+type Feature struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// FeatureRepository loads features from storage.
+type FeatureRepository interface {
+	ListFeatures(ctx context.Context) ([]*Feature, *errors.AppError)
+}
+
+type featureRepository struct {
+	db *client.PostgresClient
+}
+
+// NewFeatureRepository creates a new feature repository.
+func NewFeatureRepository(db *client.PostgresClient) FeatureRepository {
+	return &featureRepository{db: db}
+}
+
+func (r *featureRepository) ListFeatures(ctx context.Context) ([]*Feature, *errors.AppError) {
+	query := `
+		SELECT id, name, description
+		FROM features
+		ORDER BY id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list features", err)
+	}
+	defer rows.Close()
+
+	features := make([]*Feature, 0)
+	for rows.Next() {
+		var f Feature
+		if err := rows.Scan(&f.ID, &f.Name, &f.Description); err != nil {
+			return nil, errors.InternalWrap("failed to scan feature", err)
+		}
+		features = append(features, &f)
+	}
+
+	return features, nil
+}
@@ -0,0 +1,121 @@
+package feature
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/domain/video"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// registeredFeature maps a feature ID to its slug and details type.
+type registeredFeature struct {
+	ID      int
+	Slug    string
+	Details reflect.Type
+}
+
+// registry lists the features implemented by this service
+var registry = []registeredFeature{
+	{ID: video.FeatureID, Slug: "video", Details: reflect.TypeOf(video.VideoDetails{})},
+	{ID: dialog.FeatureID, Slug: "dialog", Details: reflect.TypeOf(dialog.DialogDetails{})},
+}
+
+// FeatureResponse describes a feature type for clients.
+type FeatureResponse struct {
+	ID            int                    `json:"id"`
+	Slug          string                 `json:"slug"`
+	Name          string                 `json:"name"`
+	Description   *string                `json:"description,omitempty"`
+	DetailsSchema map[string]interface{} `json:"details_schema"`
+}
+
+// FeatureService handles feature operations.
+type FeatureService struct {
+	featureRepo FeatureRepository
+}
+
+// NewFeatureService creates a new feature service.
+func NewFeatureService(featureRepo FeatureRepository) *FeatureService {
+	return &FeatureService{
+		featureRepo: featureRepo,
+	}
+}
+
+// ListFeatures returns the features implemented by this service with their details schema.
+func (s *FeatureService) ListFeatures(ctx context.Context) ([]*FeatureResponse, *errors.AppError) {
+	rows, err := s.featureRepo.ListFeatures(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*Feature, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+
+	features := make([]*FeatureResponse, 0, len(registry))
+	for _, reg := range registry {
+		f := &FeatureResponse{
+			ID:            reg.ID,
+			Slug:          reg.Slug,
+			Name:          reg.Slug,
+			DetailsSchema: schemaOf(reg.Details),
+		}
+		if row, ok := byID[reg.ID]; ok {
+			f.Name = row.Name
+			f.Description = row.Description
+		}
+		features = append(features, f)
+	}
+
+	return features, nil
+}
+
+// schemaOf describes a Go type as a JSON Schema, following its json tags.
+func schemaOf(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.PkgPath() == "time" && t.Name() == "Time" {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaOf(field.Type)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} / any: any JSON value
+		return map[string]interface{}{}
+	}
+}
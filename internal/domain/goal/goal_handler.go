@@ -0,0 +1,55 @@
+package goal
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// GoalHandler handles daily goal HTTP endpoints.
+type GoalHandler struct {
+	service *GoalService
+}
+
+// NewGoalHandler creates a new goal handler.
+func NewGoalHandler(service *GoalService) *GoalHandler {
+	return &GoalHandler{
+		service: service,
+	}
+}
+
+// GetDailyGoal handles GET /api/v1/users/me/goal.
+func (h *GoalHandler) GetDailyGoal(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		response.HandleError(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	goal, err := h.service.GetDailyGoal(r.Context(), userID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, goal)
+}
+
+// UpdateDailyGoal handles PUT /api/v1/users/me/goal.
+func (h *GoalHandler) UpdateDailyGoal(w http.ResponseWriter, r *http.Request) {
+	var req UpdateGoalRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	goal, err := h.service.UpdateDailyGoal(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, goal)
+}
@@ -0,0 +1,214 @@
+package goal
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Goal types
+const (
+	GOAL_ITEMS   = "items"
+	GOAL_MINUTES = "minutes"
+)
+
+// Goal is a row of the user_goals table.
+type Goal struct {
+	UserID       string     `json:"user_id"`
+	GoalType     string     `json:"goal_type"`
+	Target       int        `json:"target"`
+	Timezone     string     `json:"timezone"`
+	ReminderTime *string    `json:"reminder_time"` // HH:MM local time, nil disables reminders
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// ActivityCount is how many learning items the user worked on per action type.
+type ActivityCount struct {
+	ActionType string
+	Count      int
+}
+
+// EventCount sums the client activity events of one type.
+type EventCount struct {
+	EventType  string
+	Count      int
+	DurationMs int64
+}
+
+// DueReminder is a goal whose reminder time has passed today in the user's timezone.
+type DueReminder struct {
+	UserID string
+	Day    time.Time // local date the reminder is for
+}
+
+// GoalRepository stores daily goals and reads the activity they are measured on.
+type GoalRepository interface {
+	GetGoal(ctx context.Context, userID string) (*Goal, *errors.AppError)
+	UpsertGoal(ctx context.Context, goal *Goal) (*Goal, *errors.AppError)
+	ListActivity(ctx context.Context, userID string, from, to time.Time) ([]ActivityCount, *errors.AppError)
+	ListEvents(ctx context.Context, userID string, from, to time.Time) ([]EventCount, *errors.AppError)
+	ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]DueReminder, *errors.AppError)
+}
+
+type goalRepository struct {
+	db *client.PostgresClient
+}
+
+// NewGoalRepository creates a new goal repository.
+func NewGoalRepository(db *client.PostgresClient) GoalRepository {
+	return &goalRepository{db: db}
+}
+
+func (r *goalRepository) GetGoal(ctx context.Context, userID string) (*Goal, *errors.AppError) {
+	query := `
+		SELECT user_id::text, goal_type, target, timezone, to_char(reminder_time, 'HH24:MI'), updated_at
+		FROM user_goals
+		WHERE user_id = $1
+	`
+
+	var goal Goal
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&goal.UserID, &goal.GoalType, &goal.Target, &goal.Timezone, &goal.ReminderTime, &goal.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("goal not found")
+		}
+		return nil, errors.InternalWrap("failed to get goal", err)
+	}
+	return &goal, nil
+}
+
+// UpsertGoal saves the goal. Changing the reminder time re-arms today's reminder.
+func (r *goalRepository) UpsertGoal(ctx context.Context, goal *Goal) (*Goal, *errors.AppError) {
+	query := `
+		INSERT INTO user_goals (user_id, goal_type, target, timezone, reminder_time)
+		VALUES ($1, $2, $3, $4, $5::time)
+		ON CONFLICT (user_id) DO UPDATE SET
+			goal_type = EXCLUDED.goal_type,
+			target = EXCLUDED.target,
+			timezone = EXCLUDED.timezone,
+			reminder_time = EXCLUDED.reminder_time,
+			last_reminded_on = CASE
+				WHEN user_goals.reminder_time IS DISTINCT FROM EXCLUDED.reminder_time
+					OR user_goals.timezone <> EXCLUDED.timezone THEN NULL
+				ELSE user_goals.last_reminded_on
+			END,
+			updated_at = NOW()
+		RETURNING user_id::text, goal_type, target, timezone, to_char(reminder_time, 'HH24:MI'), updated_at
+	`
+
+	var saved Goal
+	err := r.db.Pool.QueryRow(ctx, query, goal.UserID, goal.GoalType, goal.Target, goal.Timezone, goal.ReminderTime).Scan(
+		&saved.UserID, &saved.GoalType, &saved.Target, &saved.Timezone, &saved.ReminderTime, &saved.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to save goal", err)
+	}
+	return &saved, nil
+}
+
+// ListActivity counts the learning items the user practiced in [from, to), per action type.
+// Bookmarks and transcript toggles are not practice and are left out.
+func (r *goalRepository) ListActivity(ctx context.Context, userID string, from, to time.Time) ([]ActivityCount, *errors.AppError) {
+	query := `
+		SELECT action_type::text, COUNT(*)
+		FROM user_actions
+		WHERE user_id = $1 AND deleted_at IS NULL
+			AND updated_at >= $2 AND updated_at < $3
+			AND action_type NOT IN ('quiz_saved', 'quiz_transcript', 'dialogue_saved')
+		GROUP BY action_type
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list activity", err)
+	}
+	defer rows.Close()
+
+	counts := make([]ActivityCount, 0)
+	for rows.Next() {
+		var c ActivityCount
+		if err := rows.Scan(&c.ActionType, &c.Count); err != nil {
+			return nil, errors.InternalWrap("failed to scan activity", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list activity", err)
+	}
+	return counts, nil
+}
+
+// ListEvents sums the client events the user sent in [from, to), per event type.
+func (r *goalRepository) ListEvents(ctx context.Context, userID string, from, to time.Time) ([]EventCount, *errors.AppError) {
+	query := `
+		SELECT event_type, COUNT(*), COALESCE(SUM(duration_ms), 0)
+		FROM activity_events
+		WHERE user_id = $1 AND occurred_at >= $2 AND occurred_at < $3
+		GROUP BY event_type
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list activity events", err)
+	}
+	defer rows.Close()
+
+	counts := make([]EventCount, 0)
+	for rows.Next() {
+		var c EventCount
+		if err := rows.Scan(&c.EventType, &c.Count, &c.DurationMs); err != nil {
+			return nil, errors.InternalWrap("failed to scan activity events", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list activity events", err)
+	}
+	return counts, nil
+}
+
+// ClaimDueReminders marks up to limit reminders whose local time has passed as sent for
+// today and returns them, so overlapping scans never remind a user twice.
+func (r *goalRepository) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]DueReminder, *errors.AppError) {
+	query := `
+		WITH due AS (
+			SELECT user_id, ($1::timestamptz AT TIME ZONE timezone)::date AS local_day
+			FROM user_goals
+			WHERE reminder_time IS NOT NULL
+				AND ($1::timestamptz AT TIME ZONE timezone)::time >= reminder_time
+				AND (last_reminded_on IS NULL OR last_reminded_on < ($1::timestamptz AT TIME ZONE timezone)::date)
+			ORDER BY user_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE user_goals g
+		SET last_reminded_on = due.local_day
+		FROM due
+		WHERE g.user_id = due.user_id
+		RETURNING g.user_id::text, due.local_day
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to claim due reminders", err)
+	}
+	defer rows.Close()
+
+	reminders := make([]DueReminder, 0)
+	for rows.Next() {
+		var reminder DueReminder
+		if err := rows.Scan(&reminder.UserID, &reminder.Day); err != nil {
+			return nil, errors.InternalWrap("failed to scan due reminder", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to claim due reminders", err)
+	}
+	return reminders, nil
+}
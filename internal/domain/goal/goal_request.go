@@ -0,0 +1,90 @@
+package goal
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxGoalTarget bounds targets for both goal types (items per day, minutes per day).
+const maxGoalTarget = 600
+
+// UpdateGoalRequest is the HTTP request struct for setting the daily goal
+type UpdateGoalRequest struct {
+	UserID       string
+	GoalType     string  `json:"goal_type"`
+	Target       int     `json:"target"`
+	Timezone     string  `json:"timezone"`
+	ReminderTime *string `json:"reminder_time"`
+}
+
+// UpdateGoalInput is the input struct for service
+type UpdateGoalInput struct {
+	UserID       string
+	GoalType     string
+	Target       int
+	Timezone     string
+	ReminderTime *string
+}
+
+// SendReminderPayload is the payload for the goal reminder job
+type SendReminderPayload struct {
+	UserID string
+}
+
+// ScanRemindersPayload is the payload for the scheduled reminder scan
+type ScanRemindersPayload struct{}
+
+func (req *UpdateGoalRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse JSON Body
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	if req.GoalType != GOAL_ITEMS && req.GoalType != GOAL_MINUTES {
+		return errors.Validation("goal_type must be items or minutes")
+	}
+	if req.Target < 1 || req.Target > maxGoalTarget {
+		return errors.Validation("target must be between 1 and 600")
+	}
+
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if req.Timezone == "" {
+		req.Timezone = defaultTimezone
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return errors.Validation("timezone must be an IANA name, e.g. Asia/Bangkok")
+	}
+
+	if req.ReminderTime != nil {
+		value := strings.TrimSpace(*req.ReminderTime)
+		if value == "" {
+			req.ReminderTime = nil
+		} else if _, err := time.Parse("15:04", value); err != nil {
+			return errors.Validation("reminder_time must be HH:MM")
+		} else {
+			req.ReminderTime = &value
+		}
+	}
+
+	return nil
+}
+
+func (req *UpdateGoalRequest) ToInput() UpdateGoalInput {
+	return UpdateGoalInput{
+		UserID:       req.UserID,
+		GoalType:     req.GoalType,
+		Target:       req.Target,
+		Timezone:     req.Timezone,
+		ReminderTime: req.ReminderTime,
+	}
+}
@@ -0,0 +1,181 @@
+package goal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Default goal for users who have not set one
+const (
+	defaultGoalType = GOAL_ITEMS
+	defaultTarget   = 3
+	defaultTimezone = "UTC"
+)
+
+// reminderBatchSize caps the reminders claimed per scan.
+const reminderBatchSize = 500
+
+// estimatedMinutes is the typical practice time of one activity, used for minute goals.
+var estimatedMinutes = map[string]int{
+	"submit_quiz":     3,
+	"submit_retell":   5,
+	"submit_chat":     8,
+	"submit_speech":   5,
+	"submit_sparring": 10,
+}
+
+// DailyGoalResponse is the goal with today's progress in the user's timezone.
+type DailyGoalResponse struct {
+	Goal       *Goal          `json:"goal"`
+	Date       string         `json:"date"`
+	Items      int            `json:"items"`
+	Minutes    int            `json:"minutes"`
+	Progress   int            `json:"progress"` // items or minutes, following goal_type
+	Completed  bool           `json:"completed"`
+	ByActivity map[string]int `json:"by_activity"`
+}
+
+// GoalService handles daily goals and reminders.
+type GoalService struct {
+	goalRepo         GoalRepository
+	notificationRepo NotificationRepository
+}
+
+// NewGoalService creates a new goal service.
+func NewGoalService(goalRepo GoalRepository, notificationRepo NotificationRepository) *GoalService {
+	return &GoalService{
+		goalRepo:         goalRepo,
+		notificationRepo: notificationRepo,
+	}
+}
+
+// GetDailyGoal returns the user's goal (or the default) and today's progress.
+func (s *GoalService) GetDailyGoal(ctx context.Context, userID string) (*DailyGoalResponse, *errors.AppError) {
+	goal, err := s.getGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.dailyProgress(ctx, goal, time.Now())
+}
+
+// UpdateDailyGoal saves the user's goal and reminder preferences.
+func (s *GoalService) UpdateDailyGoal(ctx context.Context, input UpdateGoalInput) (*DailyGoalResponse, *errors.AppError) {
+	goal, err := s.goalRepo.UpsertGoal(ctx, &Goal{
+		UserID:       input.UserID,
+		GoalType:     input.GoalType,
+		Target:       input.Target,
+		Timezone:     input.Timezone,
+		ReminderTime: input.ReminderTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.dailyProgress(ctx, goal, time.Now())
+}
+
+// Worker: ScanReminders
+// Claims the reminders that are due now; each is then sent as its own job.
+func (s *GoalService) ClaimDueReminders(ctx context.Context) ([]SendReminderPayload, *errors.AppError) {
+	due, err := s.goalRepo.ClaimDueReminders(ctx, time.Now().UTC(), reminderBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := make([]SendReminderPayload, 0, len(due))
+	for _, reminder := range due {
+		payloads = append(payloads, SendReminderPayload{UserID: reminder.UserID})
+	}
+	return payloads, nil
+}
+
+// Worker: SendReminder
+// Notifies the user unless today's goal is already met.
+func (s *GoalService) SendReminder(ctx context.Context, payload SendReminderPayload) *errors.AppError {
+	goal, err := s.getGoal(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+	daily, err := s.dailyProgress(ctx, goal, time.Now())
+	if err != nil {
+		return err
+	}
+	if daily.Completed {
+		return nil
+	}
+
+	remaining := goal.Target - daily.Progress
+	return s.notificationRepo.Notify(ctx, Notification{
+		UserID: goal.UserID,
+		Title:  "Keep your streak going",
+		Body:   fmt.Sprintf("%d more %s to reach today's goal.", remaining, goal.GoalType),
+	})
+}
+
+// getGoal returns the stored goal or the default one.
+func (s *GoalService) getGoal(ctx context.Context, userID string) (*Goal, *errors.AppError) {
+	goal, err := s.goalRepo.GetGoal(ctx, userID)
+	if err != nil {
+		if err.GetCode() != string(errors.ErrNotFound) {
+			return nil, err
+		}
+		goal = &Goal{
+			UserID:   userID,
+			GoalType: defaultGoalType,
+			Target:   defaultTarget,
+			Timezone: defaultTimezone,
+		}
+	}
+	return goal, nil
+}
+
+// dailyProgress measures activity during the current local day of the goal's timezone.
+func (s *GoalService) dailyProgress(ctx context.Context, goal *Goal, now time.Time) (*DailyGoalResponse, *errors.AppError) {
+	loc, locErr := time.LoadLocation(goal.Timezone)
+	if locErr != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	counts, err := s.goalRepo.ListActivity(ctx, goal.UserID, dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.goalRepo.ListEvents(ctx, goal.UserID, dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DailyGoalResponse{
+		Goal:       goal,
+		Date:       dayStart.Format("2006-01-02"),
+		ByActivity: make(map[string]int, len(counts)),
+	}
+	for _, c := range counts {
+		result.ByActivity[c.ActionType] = c.Count
+		result.Items += c.Count
+		result.Minutes += c.Count * estimatedMinutes[c.ActionType]
+	}
+
+	// Client events add tracked listening time; they do not count as items
+	var listenedMs int64
+	for _, e := range events {
+		result.ByActivity[e.EventType] = e.Count
+		if e.EventType == "audio_played" {
+			listenedMs += e.DurationMs
+		}
+	}
+	result.Minutes += int(listenedMs / int64(time.Minute/time.Millisecond))
+
+	result.Progress = result.Items
+	if goal.GoalType == GOAL_MINUTES {
+		result.Progress = result.Minutes
+	}
+	result.Completed = result.Progress >= goal.Target
+
+	return result, nil
+}
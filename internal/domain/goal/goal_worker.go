@@ -0,0 +1,48 @@
+package goal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_SCAN_REMINDERS = "worker_scan_goal_reminders"
+	WORKER_SEND_REMINDER  = "worker_send_goal_reminder"
+)
+
+// RegisterGoalWorkers register goal reminder workers to queue
+func RegisterGoalWorkers(queue *client.QueueClient, service *GoalService) {
+
+	// Job Scan Reminders (fans out one send job per due user)
+	queue.RegisterWorker(WORKER_SCAN_REMINDERS, func(ctx context.Context, job client.Job) error {
+		if _, ok := job.Payload.(ScanRemindersPayload); !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_SCAN_REMINDERS)
+		}
+		payloads, err := service.ClaimDueReminders(ctx)
+		if err != nil {
+			return err
+		}
+		for _, payload := range payloads {
+			// Reminders are already claimed, so a full queue sends them here instead of dropping them
+			if err := queue.Enqueue(client.Job{Type: WORKER_SEND_REMINDER, Payload: payload}); err != nil {
+				_ = service.SendReminder(ctx, payload)
+			}
+		}
+		return nil
+	})
+
+	// Job Send Reminder
+	queue.RegisterWorker(WORKER_SEND_REMINDER, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(SendReminderPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_SEND_REMINDER)
+		}
+		if err := service.SendReminder(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,35 @@
+package goal
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Notification is a message for one user.
+type Notification struct {
+	UserID string
+	Title  string
+	Body   string
+}
+
+// NotificationRepository delivers notifications to users.
+type NotificationRepository interface {
+	Notify(ctx context.Context, notification Notification) *errors.AppError
+}
+
+type logNotificationRepository struct {
+	log *slog.Logger
+}
+
+// NewLogNotificationRepository creates a notification repository that only logs,
+// until a push or email provider is wired in.
+func NewLogNotificationRepository(log *slog.Logger) NotificationRepository {
+	return &logNotificationRepository{log: log}
+}
+
+func (r *logNotificationRepository) Notify(ctx context.Context, notification Notification) *errors.AppError {
+	r.log.InfoContext(ctx, "Notification", "user_id", notification.UserID, "title", notification.Title, "body", notification.Body)
+	return nil
+}
@@ -0,0 +1,64 @@
+package maintenance
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// MaintenanceHandler handles maintenance HTTP endpoints.
+type MaintenanceHandler struct {
+	service *MaintenanceService
+}
+
+// NewMaintenanceHandler creates a new maintenance handler.
+func NewMaintenanceHandler(service *MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		service: service,
+	}
+}
+
+// ListFlags handles GET /api/v1/admin/maintenance.
+func (h *MaintenanceHandler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.ListFlags(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// EnableScope handles PUT /api/v1/admin/maintenance/{scope}.
+func (h *MaintenanceHandler) EnableScope(w http.ResponseWriter, r *http.Request) {
+	var req ScopeRequest
+	if err := req.ParseAndValidate(r, true); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.EnableScope(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// DisableScope handles DELETE /api/v1/admin/maintenance/{scope}.
+func (h *MaintenanceHandler) DisableScope(w http.ResponseWriter, r *http.Request) {
+	var req ScopeRequest
+	if err := req.ParseAndValidate(r, false); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.DisableScope(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
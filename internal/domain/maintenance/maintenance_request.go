@@ -0,0 +1,76 @@
+package maintenance
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxRetryAfter caps the Retry-After hint (seconds).
+const maxRetryAfter = 24 * 60 * 60
+
+// ScopeRequest is the HTTP request struct for switching a maintenance scope
+type ScopeRequest struct {
+	Admin      string
+	Scope      string
+	Message    string
+	RetryAfter int
+}
+
+// ScopeInput is the input struct for service
+type ScopeInput struct {
+	Admin      string
+	Scope      string
+	Message    string
+	RetryAfter int
+}
+
+// ParseAndValidate reads the scope and, when withBody is set, the flag body
+func (req *ScopeRequest) ParseAndValidate(r *http.Request, withBody bool) error {
+	// 1. Admin name from basic auth (checked by the admin middleware)
+	admin, _, ok := r.BasicAuth()
+	if !ok || admin == "" {
+		return errors.Unauthorized("admin not authenticated")
+	}
+	req.Admin = admin
+
+	// 2. Parse URL Params
+	req.Scope = chi.URLParam(r, "scope")
+	if !client.MaintenanceScopes[req.Scope] {
+		return errors.Validation("unknown maintenance scope")
+	}
+
+	if !withBody {
+		return nil
+	}
+
+	// 3. Parse JSON Body (optional)
+	var body struct {
+		Message    string `json:"message"`
+		RetryAfter int    `json:"retry_after"`
+	}
+	if err := middleware.DecodeJSON(r, &body); err != nil && err != io.EOF {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Message = strings.TrimSpace(body.Message)
+	req.RetryAfter = body.RetryAfter
+	if req.RetryAfter < 0 || req.RetryAfter > maxRetryAfter {
+		return errors.Validation("retry_after must be between 0 and 86400 seconds")
+	}
+
+	return nil
+}
+
+func (req *ScopeRequest) ToInput() ScopeInput {
+	return ScopeInput{
+		Admin:      req.Admin,
+		Scope:      req.Scope,
+		Message:    req.Message,
+		RetryAfter: req.RetryAfter,
+	}
+}
@@ -0,0 +1,80 @@
+package maintenance
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// defaultRetryAfter is the Retry-After hint when the admin gives none (seconds).
+const defaultRetryAfter = 300
+
+// MaintenanceResponse lists the active flags and the scopes that can be switched.
+type MaintenanceResponse struct {
+	Active []client.MaintenanceFlag `json:"active"`
+	Scopes []string                 `json:"scopes"`
+}
+
+// MaintenanceService switches maintenance mode and route kill switches.
+type MaintenanceService struct {
+	maintenance *client.MaintenanceClient
+	log         *slog.Logger
+}
+
+// NewMaintenanceService creates a new maintenance service.
+func NewMaintenanceService(maintenance *client.MaintenanceClient, log *slog.Logger) *MaintenanceService {
+	return &MaintenanceService{
+		maintenance: maintenance,
+		log:         log,
+	}
+}
+
+// ListFlags returns the active maintenance flags.
+func (s *MaintenanceService) ListFlags(ctx context.Context) (*MaintenanceResponse, *errors.AppError) {
+	active, err := s.maintenance.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{
+		client.MaintenanceScopeAll,
+		client.MaintenanceScopeVideoUpload,
+		client.MaintenanceScopeVideoBulk,
+		client.MaintenanceScopeDialogGenerate,
+	}
+
+	return &MaintenanceResponse{Active: active, Scopes: scopes}, nil
+}
+
+// EnableScope switches a scope off until it is disabled again.
+func (s *MaintenanceService) EnableScope(ctx context.Context, input ScopeInput) (*MaintenanceResponse, *errors.AppError) {
+	retryAfter := input.RetryAfter
+	if retryAfter == 0 {
+		retryAfter = defaultRetryAfter
+	}
+
+	if err := s.maintenance.Enable(ctx, client.MaintenanceFlag{
+		Scope:      input.Scope,
+		Message:    input.Message,
+		RetryAfter: retryAfter,
+		Since:      time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log.Warn("Maintenance enabled", "scope", input.Scope, "admin", input.Admin, "retry_after", retryAfter)
+	return s.ListFlags(ctx)
+}
+
+// DisableScope switches a scope back on.
+func (s *MaintenanceService) DisableScope(ctx context.Context, input ScopeInput) (*MaintenanceResponse, *errors.AppError) {
+	if err := s.maintenance.Disable(ctx, input.Scope); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Maintenance disabled", "scope", input.Scope, "admin", input.Admin)
+	return s.ListFlags(ctx)
+}
@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ModerationHandler handles content reports, the admin moderation queue and quality reviews.
+type ModerationHandler struct {
+	service *ModerationService
+}
+
+// NewModerationHandler creates a new moderation handler.
+func NewModerationHandler(service *ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		service: service,
+	}
+}
+
+// ReportContent handles POST /api/v1/content/{type}/{id}/report
+func (h *ModerationHandler) ReportContent(w http.ResponseWriter, r *http.Request) {
+	var req ReportContentRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	report, err := h.service.ReportContent(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, report)
+}
+
+// ListQueue handles GET /api/v1/admin/moderation/queue
+func (h *ModerationHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	var req ListQueueRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListQueue(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// ResolveReports handles POST /api/v1/admin/moderation/items/{itemID}/resolve
+func (h *ModerationHandler) ResolveReports(w http.ResponseWriter, r *http.Request) {
+	var req ResolveReportsRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ResolveReports(r.Context(), req.ItemID, req.Action)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ListReviews handles GET /api/v1/admin/moderation/reviews
+func (h *ModerationHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	var req ListReviewsRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListReviews(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// ResolveReview handles POST /api/v1/admin/moderation/reviews/{itemID}/resolve
+func (h *ModerationHandler) ResolveReview(w http.ResponseWriter, r *http.Request) {
+	var req ResolveReviewRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ResolveReview(r.Context(), req.ItemID, req.Action)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
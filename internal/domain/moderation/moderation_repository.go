@@ -0,0 +1,292 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Report is one learner report on a learning item.
+type Report struct {
+	ID         string    `json:"id"`
+	LearningID string    `json:"learning_id"`
+	FeatureID  int       `json:"-"`
+	UserID     string    `json:"-"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment,omitempty"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// QueueItem is a reported learning item with its reports summarized.
+type QueueItem struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Content         string          `json:"content"`
+	IsActive        bool            `json:"is_active"`
+	HiddenAt        *time.Time      `json:"hidden_at"`
+	Reports         int             `json:"reports"`
+	Reasons         json.RawMessage `json:"reasons"`
+	RecentComments  json.RawMessage `json:"recent_comments"`
+	FirstReportedAt time.Time       `json:"first_reported_at"`
+	LastReportedAt  time.Time       `json:"last_reported_at"`
+}
+
+// ReviewItem is a generated item the quality critic flagged.
+type ReviewItem struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	Content      string          `json:"content"`
+	Language     string          `json:"language"`
+	Level        string          `json:"level"`
+	IsActive     bool            `json:"is_active"`
+	ReviewStatus string          `json:"review_status"`
+	QualityScore *int            `json:"quality_score"`
+	Quality      json.RawMessage `json:"quality"`
+	CreatedBy    string          `json:"created_by"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// ModerationRepository stores reports and applies moderation to learning items.
+type ModerationRepository interface {
+	IsReportable(ctx context.Context, itemID string, featureID int, userID string) (bool, *errors.AppError)
+	CreateReport(ctx context.Context, report *Report) (bool, *errors.AppError)
+	HideIfReported(ctx context.Context, itemID string, threshold int) (bool, *errors.AppError)
+	ListQueue(ctx context.Context, status string, limit, offset int) ([]*QueueItem, int, *errors.AppError)
+	ResolveReports(ctx context.Context, itemID, status string) (int, *errors.AppError)
+	ListReviews(ctx context.Context, status string, limit, offset int) ([]*ReviewItem, int, *errors.AppError)
+	ResolveReview(ctx context.Context, itemID, status string) *errors.AppError
+}
+
+type moderationRepository struct {
+	db *client.PostgresClient
+}
+
+// NewModerationRepository creates a new moderation repository.
+func NewModerationRepository(db *client.PostgresClient) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+// IsReportable reports whether the user can see the item, and so report it.
+func (r *moderationRepository) IsReportable(ctx context.Context, itemID string, featureID int, userID string) (bool, *errors.AppError) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM learning_items l
+			WHERE l.id = $1 AND l.feature_id = $2 AND l.is_active = true
+			AND (l.feature_id <> $4 OR l.published_details IS NOT NULL OR l.created_by = $3)
+			AND ` + client.AudienceVisibleSQL("l", "$3") + `
+		)
+	`
+
+	var reportable bool
+	if err := r.db.Pool.QueryRow(ctx, query, itemID, featureID, userID, client.FeatureDialog).Scan(&reportable); err != nil {
+		return false, errors.InternalWrap("failed to check reported content", err)
+	}
+	return reportable, nil
+}
+
+// CreateReport stores a report. It returns false when the user already reported the item.
+func (r *moderationRepository) CreateReport(ctx context.Context, report *Report) (bool, *errors.AppError) {
+	query := `
+		INSERT INTO content_reports (learning_id, feature_id, user_id, reason, comment)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (learning_id, user_id) DO NOTHING
+		RETURNING id::text, status, created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		report.LearningID,
+		report.FeatureID,
+		report.UserID,
+		report.Reason,
+		report.Comment,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.InternalWrap("failed to create report", err)
+	}
+	return true, nil
+}
+
+// HideIfReported deactivates the item once it has threshold open reports. It returns true
+// when this call hid the item.
+func (r *moderationRepository) HideIfReported(ctx context.Context, itemID string, threshold int) (bool, *errors.AppError) {
+	query := `
+		UPDATE learning_items
+		SET is_active = false, moderation_hidden_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND is_active = true AND moderation_hidden_at IS NULL
+		AND (SELECT COUNT(*) FROM content_reports WHERE learning_id = $1 AND status = 'open') >= $2
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, itemID, threshold)
+	if err != nil {
+		return false, errors.InternalWrap("failed to hide reported content", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// ListQueue returns reported items with reports in status, most reported first.
+func (r *moderationRepository) ListQueue(ctx context.Context, status string, limit, offset int) ([]*QueueItem, int, *errors.AppError) {
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(DISTINCT learning_id) FROM content_reports WHERE status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count moderation queue", err)
+	}
+
+	query := `
+		SELECT q.learning_id::text, q.feature_id, l.content, COALESCE(l.is_active, false), l.moderation_hidden_at,
+			q.reports, q.reasons, cm.comments, q.first_reported_at, q.last_reported_at
+		FROM (
+			SELECT learning_id, MIN(feature_id) AS feature_id, SUM(n)::int AS reports,
+				jsonb_object_agg(reason, n) AS reasons,
+				MIN(first_reported_at) AS first_reported_at, MAX(last_reported_at) AS last_reported_at
+			FROM (
+				SELECT learning_id, feature_id, reason, COUNT(*) AS n,
+					MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at
+				FROM content_reports
+				WHERE status = $1
+				GROUP BY learning_id, feature_id, reason
+			) by_reason
+			GROUP BY learning_id
+		) q
+		JOIN learning_items l ON l.id = q.learning_id
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(jsonb_agg(c.comment ORDER BY c.created_at DESC), '[]'::jsonb) AS comments
+			FROM (
+				SELECT comment, created_at FROM content_reports
+				WHERE learning_id = q.learning_id AND status = $1 AND comment IS NOT NULL
+				ORDER BY created_at DESC
+				LIMIT 5
+			) c
+		) cm
+		ORDER BY q.reports DESC, q.last_reported_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list moderation queue", err)
+	}
+	defer rows.Close()
+
+	items := make([]*QueueItem, 0)
+	for rows.Next() {
+		var item QueueItem
+		var featureID int
+		if err := rows.Scan(&item.ID, &featureID, &item.Content, &item.IsActive, &item.HiddenAt,
+			&item.Reports, &item.Reasons, &item.RecentComments, &item.FirstReportedAt, &item.LastReportedAt); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan moderation queue", err)
+		}
+		item.Type = CONTENT_TYPE_VIDEO
+		if featureID == client.FeatureDialog {
+			item.Type = CONTENT_TYPE_DIALOG
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list moderation queue", err)
+	}
+
+	return items, total, nil
+}
+
+// ResolveReports closes the open reports of an item as dismissed or upheld and returns how many.
+// Dismissing restores an item the reports deactivated; upholding keeps it deactivated for good.
+func (r *moderationRepository) ResolveReports(ctx context.Context, itemID, status string) (int, *errors.AppError) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `UPDATE content_reports SET status = $1, resolved_at = NOW() WHERE learning_id = $2 AND status = 'open'`, status, itemID)
+	if err != nil {
+		return 0, errors.InternalWrap("failed to resolve reports", err)
+	}
+	resolved := int(cmdTag.RowsAffected())
+	if resolved == 0 {
+		return 0, errors.NotFound("no open reports for this item")
+	}
+
+	switch status {
+	case REPORT_DISMISSED:
+		_, err = tx.Exec(ctx, `UPDATE learning_items SET is_active = true, moderation_hidden_at = NULL, version = version + 1, updated_at = NOW() WHERE id = $1 AND moderation_hidden_at IS NOT NULL`, itemID)
+	case REPORT_UPHELD:
+		_, err = tx.Exec(ctx, `UPDATE learning_items SET is_active = false, moderation_hidden_at = NULL, version = version + 1, updated_at = NOW() WHERE id = $1`, itemID)
+	}
+	if err != nil {
+		return 0, errors.InternalWrap(fmt.Sprintf("failed to apply %s decision", status), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, errors.InternalWrap("failed to commit moderation decision", err)
+	}
+	return resolved, nil
+}
+
+// ListReviews returns items the quality critic flagged with the given review status, oldest first.
+func (r *moderationRepository) ListReviews(ctx context.Context, status string, limit, offset int) ([]*ReviewItem, int, *errors.AppError) {
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM learning_items WHERE review_status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count quality reviews", err)
+	}
+
+	query := `
+		SELECT l.id::text, l.feature_id, l.content, l.language, l.level, COALESCE(l.is_active, false),
+			l.review_status, l.quality_score, COALESCE(l.details->'quality', 'null'::jsonb), l.created_by::text, l.created_at
+		FROM learning_items l
+		WHERE l.review_status = $1
+		ORDER BY l.created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list quality reviews", err)
+	}
+	defer rows.Close()
+
+	items := make([]*ReviewItem, 0)
+	for rows.Next() {
+		var item ReviewItem
+		var featureID int
+		if err := rows.Scan(&item.ID, &featureID, &item.Content, &item.Language, &item.Level, &item.IsActive,
+			&item.ReviewStatus, &item.QualityScore, &item.Quality, &item.CreatedBy, &item.CreatedAt); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan quality review", err)
+		}
+		item.Type = CONTENT_TYPE_VIDEO
+		if featureID == client.FeatureDialog {
+			item.Type = CONTENT_TYPE_DIALOG
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list quality reviews", err)
+	}
+
+	return items, total, nil
+}
+
+// ResolveReview approves a pending item, which activates it, or rejects it, which keeps it inactive.
+func (r *moderationRepository) ResolveReview(ctx context.Context, itemID, status string) *errors.AppError {
+	query := `
+		UPDATE learning_items
+		SET review_status = $2, is_active = ($2 = 'approved'), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND review_status = 'pending'
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, itemID, status)
+	if err != nil {
+		return errors.InternalWrap("failed to resolve quality review", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.NotFound("no pending review for this item")
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package moderation
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Content types that can be reported
+const (
+	CONTENT_TYPE_VIDEO  = "video"
+	CONTENT_TYPE_DIALOG = "dialog"
+)
+
+// Report statuses
+const (
+	REPORT_OPEN      = "open"
+	REPORT_DISMISSED = "dismissed"
+	REPORT_UPHELD    = "upheld"
+)
+
+// Moderator actions on a reported item
+const (
+	ACTION_DISMISS = "dismiss"
+	ACTION_UPHOLD  = "uphold"
+)
+
+// Review states of items the quality critic flagged (see dialog.REVIEW_PENDING)
+const (
+	REVIEW_PENDING  = "pending"
+	REVIEW_APPROVED = "approved"
+	REVIEW_REJECTED = "rejected"
+)
+
+// Moderator actions on a flagged item
+const (
+	ACTION_APPROVE = "approve"
+	ACTION_REJECT  = "reject"
+)
+
+// Limits
+const (
+	maxCommentLength = 1000
+	defaultPageSize  = 20
+	maxPageSize      = 100
+)
+
+// -------------------------------------------------------------------------
+// Report Content Request
+// -------------------------------------------------------------------------
+
+// ReportContentRequest is the HTTP request struct for reporting a video or dialog
+type ReportContentRequest struct {
+	UserID  string `json:"-"`
+	Type    string `json:"-"`
+	ItemID  string `json:"-"`
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+// ReportContentInput is the input struct for service
+type ReportContentInput struct {
+	UserID    string
+	FeatureID int
+	ItemID    string
+	Reason    string
+	Comment   string
+}
+
+func (req *ReportContentRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.Type = strings.ToLower(chi.URLParam(r, "type"))
+	if req.Type != CONTENT_TYPE_VIDEO && req.Type != CONTENT_TYPE_DIALOG {
+		return errors.Validation("type must be video or dialog")
+	}
+	req.ItemID = chi.URLParam(r, "id")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid content id")
+	}
+
+	// 3. Parse JSON Body
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Reason = strings.ToLower(strings.TrimSpace(req.Reason))
+	if !client.IsReportReason(req.Reason) {
+		return errors.Validation("reason must be one of inappropriate, offensive, unsafe_for_minors, inaccurate, low_quality, other")
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if req.Reason == client.ReportOther && req.Comment == "" {
+		return errors.Validation("comment is required for reason other")
+	}
+	if len(req.Comment) > maxCommentLength {
+		return errors.Validation("comment must be at most 1000 characters")
+	}
+
+	return nil
+}
+
+// ToInput convert ReportContentRequest to ReportContentInput
+func (req *ReportContentRequest) ToInput() ReportContentInput {
+	featureID := client.FeatureVideo
+	if req.Type == CONTENT_TYPE_DIALOG {
+		featureID = client.FeatureDialog
+	}
+	return ReportContentInput{
+		UserID:    req.UserID,
+		FeatureID: featureID,
+		ItemID:    req.ItemID,
+		Reason:    req.Reason,
+		Comment:   req.Comment,
+	}
+}
+
+// -------------------------------------------------------------------------
+// List Queue Request (admin)
+// -------------------------------------------------------------------------
+
+// ListQueueRequest is the HTTP request struct for the moderation queue
+type ListQueueRequest struct {
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// ListQueueInput is the input struct for service
+type ListQueueInput struct {
+	Status   string
+	Page     int
+	PageSize int
+	Limit    int
+	Offset   int
+}
+
+// Parse reads the optional status (default open) and pagination params
+func (req *ListQueueRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.Status = strings.ToLower(q.Get("status"))
+	if req.Status == "" {
+		req.Status = REPORT_OPEN
+	}
+	if req.Status != REPORT_OPEN && req.Status != REPORT_DISMISSED && req.Status != REPORT_UPHELD {
+		return errors.Validation("status must be open, dismissed or upheld")
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListQueueRequest to ListQueueInput
+func (req *ListQueueRequest) ToInput() ListQueueInput {
+	return ListQueueInput{
+		Status:   req.Status,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Resolve Reports Request (admin)
+// -------------------------------------------------------------------------
+
+// ResolveReportsRequest is the HTTP request struct for resolving the open reports of an item
+type ResolveReportsRequest struct {
+	ItemID string `json:"-"`
+	Action string `json:"action"`
+}
+
+func (req *ResolveReportsRequest) ParseAndValidate(r *http.Request) error {
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Action = strings.ToLower(strings.TrimSpace(req.Action))
+	if req.Action != ACTION_DISMISS && req.Action != ACTION_UPHOLD {
+		return errors.Validation("action must be dismiss or uphold")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// List Reviews Request (admin)
+// -------------------------------------------------------------------------
+
+// ListReviewsRequest is the HTTP request struct for items flagged by the quality critic
+type ListReviewsRequest struct {
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// ListReviewsInput is the input struct for service
+type ListReviewsInput struct {
+	Status   string
+	Page     int
+	PageSize int
+	Limit    int
+	Offset   int
+}
+
+// Parse reads the optional status (default pending) and pagination params
+func (req *ListReviewsRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.Status = strings.ToLower(q.Get("status"))
+	if req.Status == "" {
+		req.Status = REVIEW_PENDING
+	}
+	if req.Status != REVIEW_PENDING && req.Status != REVIEW_APPROVED && req.Status != REVIEW_REJECTED {
+		return errors.Validation("status must be pending, approved or rejected")
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListReviewsRequest to ListReviewsInput
+func (req *ListReviewsRequest) ToInput() ListReviewsInput {
+	return ListReviewsInput{
+		Status:   req.Status,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Resolve Review Request (admin)
+// -------------------------------------------------------------------------
+
+// ResolveReviewRequest is the HTTP request struct for approving or rejecting a flagged item
+type ResolveReviewRequest struct {
+	ItemID string `json:"-"`
+	Action string `json:"action"`
+}
+
+func (req *ResolveReviewRequest) ParseAndValidate(r *http.Request) error {
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Action = strings.ToLower(strings.TrimSpace(req.Action))
+	if req.Action != ACTION_APPROVE && req.Action != ACTION_REJECT {
+		return errors.Validation("action must be approve or reject")
+	}
+
+	return nil
+}
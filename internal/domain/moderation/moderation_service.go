@@ -0,0 +1,155 @@
+package moderation
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ModerationService takes learner reports and runs the moderation queue.
+type ModerationService struct {
+	moderationRepo ModerationRepository
+	// Open reports that deactivate an item until a moderator looks at it (0 never deactivates)
+	threshold int
+	log       *slog.Logger
+}
+
+// ListQueueResponse is returned when listing the moderation queue.
+type ListQueueResponse struct {
+	Data []*QueueItem             `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// ResolveReportsResponse is returned after a moderator decision.
+type ResolveReportsResponse struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Resolved int    `json:"resolved"`
+}
+
+// ListReviewsResponse is returned when listing items flagged by the quality critic.
+type ListReviewsResponse struct {
+	Data []*ReviewItem            `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// ResolveReviewResponse is returned after a review decision.
+type ResolveReviewResponse struct {
+	ID           string `json:"id"`
+	ReviewStatus string `json:"review_status"`
+}
+
+// NewModerationService creates a new ModerationService.
+func NewModerationService(moderationRepo ModerationRepository, threshold int, log *slog.Logger) *ModerationService {
+	return &ModerationService{
+		moderationRepo: moderationRepo,
+		threshold:      threshold,
+		log:            log,
+	}
+}
+
+// ReportContent stores a report and deactivates the item once it reaches the threshold.
+func (s *ModerationService) ReportContent(ctx context.Context, input ReportContentInput) (*Report, *errors.AppError) {
+	reportable, err := s.moderationRepo.IsReportable(ctx, input.ItemID, input.FeatureID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !reportable {
+		return nil, errors.NotFound("content not found")
+	}
+
+	report := &Report{
+		LearningID: input.ItemID,
+		FeatureID:  input.FeatureID,
+		UserID:     input.UserID,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+	}
+	created, err := s.moderationRepo.CreateReport(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errors.Conflict("content already reported")
+	}
+
+	if s.threshold > 0 {
+		hidden, err := s.moderationRepo.HideIfReported(ctx, input.ItemID, s.threshold)
+		if err != nil {
+			s.log.Error("failed to apply report threshold", "learning_id", input.ItemID, "error", err)
+		} else if hidden {
+			s.log.Warn("content deactivated by reports", "learning_id", input.ItemID, "threshold", s.threshold)
+		}
+	}
+
+	return report, nil
+}
+
+// ListQueue returns a page of reported items.
+func (s *ModerationService) ListQueue(ctx context.Context, input ListQueueInput) (*ListQueueResponse, *errors.AppError) {
+	items, total, err := s.moderationRepo.ListQueue(ctx, input.Status, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListQueueResponse{
+		Data: items,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// ResolveReports applies a moderator decision to every open report of an item. Upheld
+// reasons also feed the dialog generation prompt (see client.ModerationGuidance).
+func (s *ModerationService) ResolveReports(ctx context.Context, itemID, action string) (*ResolveReportsResponse, *errors.AppError) {
+	status := REPORT_DISMISSED
+	if action == ACTION_UPHOLD {
+		status = REPORT_UPHELD
+	}
+
+	resolved, err := s.moderationRepo.ResolveReports(ctx, itemID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResolveReportsResponse{ID: itemID, Status: status, Resolved: resolved}, nil
+}
+
+// ListReviews returns a page of items the quality critic flagged.
+func (s *ModerationService) ListReviews(ctx context.Context, input ListReviewsInput) (*ListReviewsResponse, *errors.AppError) {
+	items, total, err := s.moderationRepo.ListReviews(ctx, input.Status, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListReviewsResponse{
+		Data: items,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// ResolveReview approves or rejects an item waiting for quality review. Approved dialogs
+// can then be published by their owner.
+func (s *ModerationService) ResolveReview(ctx context.Context, itemID, action string) (*ResolveReviewResponse, *errors.AppError) {
+	status := REVIEW_REJECTED
+	if action == ACTION_APPROVE {
+		status = REVIEW_APPROVED
+	}
+
+	if err := s.moderationRepo.ResolveReview(ctx, itemID, status); err != nil {
+		return nil, err
+	}
+
+	return &ResolveReviewResponse{ID: itemID, ReviewStatus: status}, nil
+}
@@ -0,0 +1,86 @@
+package note
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// NoteHandler handles note HTTP endpoints.
+type NoteHandler struct {
+	service *NoteService
+}
+
+// NewNoteHandler creates a new note handler.
+func NewNoteHandler(service *NoteService) *NoteHandler {
+	return &NoteHandler{
+		service: service,
+	}
+}
+
+// ListNotes handles GET /api/v1/items/{itemID}/notes
+func (h *NoteHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
+	var req ListNotesRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	notes, err := h.service.ListNotes(r.Context(), req.ItemID, req.UserID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, notes)
+}
+
+// CreateNote handles POST /api/v1/items/{itemID}/notes
+func (h *NoteHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
+	var req CreateNoteRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	note, err := h.service.CreateNote(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, note)
+}
+
+// UpdateNote handles PATCH /api/v1/notes/{noteID}
+func (h *NoteHandler) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	var req UpdateNoteRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	note, err := h.service.UpdateNote(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, note)
+}
+
+// DeleteNote handles DELETE /api/v1/notes/{noteID}
+func (h *NoteHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	var req DeleteNoteRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteNote(r.Context(), req.NoteID, req.UserID); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,143 @@
+package note
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Note is a private note of a user on a learning item.
+type Note struct {
+	ID        string    `json:"id"`
+	ItemID    string    `json:"item_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NoteRepository stores the notes users keep on learning items.
+type NoteRepository interface {
+	IsVisible(ctx context.Context, itemID, userID string) (bool, *errors.AppError)
+	CountNotes(ctx context.Context, itemID, userID string) (int, *errors.AppError)
+	CreateNote(ctx context.Context, itemID, userID, body string) (*Note, *errors.AppError)
+	ListNotes(ctx context.Context, itemID, userID string) ([]*Note, *errors.AppError)
+	UpdateNote(ctx context.Context, noteID, userID, body string) (*Note, *errors.AppError)
+	DeleteNote(ctx context.Context, noteID, userID string) *errors.AppError
+}
+
+type noteRepository struct {
+	db *client.PostgresClient
+}
+
+// NewNoteRepository creates a new note repository.
+func NewNoteRepository(db *client.PostgresClient) NoteRepository {
+	return &noteRepository{db: db}
+}
+
+const noteColumns = `id::text, learning_id::text, body, created_at, updated_at`
+
+func scanNote(row pgx.Row) (*Note, error) {
+	var note Note
+	if err := row.Scan(&note.ID, &note.ItemID, &note.Body, &note.CreatedAt, &note.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// IsVisible reports whether the user can open the item: active videos, and dialogs that are
+// published or their own, without adult-audience items for minors.
+func (r *noteRepository) IsVisible(ctx context.Context, itemID, userID string) (bool, *errors.AppError) {
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM learning_items l
+		WHERE l.id = $1 AND l.is_active = true
+		AND (l.feature_id = %d OR (l.feature_id = %d AND (l.published_details IS NOT NULL OR l.created_by = $2)))
+		AND `, client.FeatureVideo, client.FeatureDialog) + client.AudienceVisibleSQL("l", "$2") + `)`
+
+	var ok bool
+	if err := r.db.Pool.QueryRow(ctx, query, itemID, userID).Scan(&ok); err != nil {
+		return false, errors.InternalWrap("failed to check learning item", err)
+	}
+	return ok, nil
+}
+
+func (r *noteRepository) CountNotes(ctx context.Context, itemID, userID string) (int, *errors.AppError) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notes WHERE learning_id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, itemID, userID).Scan(&count)
+	if err != nil {
+		return 0, errors.InternalWrap("failed to count notes", err)
+	}
+	return count, nil
+}
+
+func (r *noteRepository) CreateNote(ctx context.Context, itemID, userID, body string) (*Note, *errors.AppError) {
+	note, err := scanNote(r.db.Pool.QueryRow(ctx, `
+		INSERT INTO notes (learning_id, user_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING `+noteColumns, itemID, userID, body))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create note", err)
+	}
+	return note, nil
+}
+
+// ListNotes returns the user's notes on the item, oldest first.
+func (r *noteRepository) ListNotes(ctx context.Context, itemID, userID string) ([]*Note, *errors.AppError) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+noteColumns+`
+		FROM notes
+		WHERE learning_id = $1 AND user_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, itemID, userID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list notes", err)
+	}
+	defer rows.Close()
+
+	notes := make([]*Note, 0)
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, errors.InternalWrap("failed to scan note", err)
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list notes", err)
+	}
+	return notes, nil
+}
+
+// UpdateNote replaces the body of one of the user's notes.
+func (r *noteRepository) UpdateNote(ctx context.Context, noteID, userID, body string) (*Note, *errors.AppError) {
+	note, err := scanNote(r.db.Pool.QueryRow(ctx, `
+		UPDATE notes SET body = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		RETURNING `+noteColumns, noteID, userID, body))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("note not found")
+		}
+		return nil, errors.InternalWrap("failed to update note", err)
+	}
+	return note, nil
+}
+
+// DeleteNote marks one of the user's notes deleted, so the next delta sync drops it.
+func (r *noteRepository) DeleteNote(ctx context.Context, noteID, userID string) *errors.AppError {
+	cmdTag, err := r.db.Pool.Exec(ctx, `
+		UPDATE notes SET body = '', deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, noteID, userID)
+	if err != nil {
+		return errors.InternalWrap("failed to delete note", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.NotFound("note not found")
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package note
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxNoteLength is the most characters a note body may have.
+const maxNoteLength = 2000
+
+// validateBody trims the note body and checks it is not empty or too long.
+func validateBody(body *string) error {
+	*body = strings.TrimSpace(*body)
+	if *body == "" {
+		return errors.Validation("body is required")
+	}
+	if utf8.RuneCountInString(*body) > maxNoteLength {
+		return errors.Validation("body must be at most 2000 characters")
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Create Note Request
+// -------------------------------------------------------------------------
+
+// CreateNoteRequest is the HTTP request struct for adding a note to a learning item
+type CreateNoteRequest struct {
+	UserID string `json:"-"`
+	ItemID string `json:"-"`
+	Body   string `json:"body"`
+}
+
+// CreateNoteInput is the input struct for service
+type CreateNoteInput struct {
+	UserID string
+	ItemID string
+	Body   string
+}
+
+func (req *CreateNoteRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Get item ID from URL
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+
+	// 3. Parse JSON Body
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	return validateBody(&req.Body)
+}
+
+// ToInput convert CreateNoteRequest to CreateNoteInput
+func (req *CreateNoteRequest) ToInput() CreateNoteInput {
+	return CreateNoteInput{
+		UserID: req.UserID,
+		ItemID: req.ItemID,
+		Body:   req.Body,
+	}
+}
+
+// -------------------------------------------------------------------------
+// List Notes Request
+// -------------------------------------------------------------------------
+
+// ListNotesRequest is the HTTP request struct for listing the notes on a learning item
+type ListNotesRequest struct {
+	UserID string
+	ItemID string
+}
+
+func (req *ListNotesRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Update Note Request
+// -------------------------------------------------------------------------
+
+// UpdateNoteRequest is the HTTP request struct for editing a note
+type UpdateNoteRequest struct {
+	UserID string `json:"-"`
+	NoteID string `json:"-"`
+	Body   string `json:"body"`
+}
+
+// UpdateNoteInput is the input struct for service
+type UpdateNoteInput struct {
+	UserID string
+	NoteID string
+	Body   string
+}
+
+func (req *UpdateNoteRequest) ParseAndValidate(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.NoteID = chi.URLParam(r, "noteID")
+	if _, err := uuid.Parse(req.NoteID); err != nil {
+		return errors.Validation("invalid note id")
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	return validateBody(&req.Body)
+}
+
+// ToInput convert UpdateNoteRequest to UpdateNoteInput
+func (req *UpdateNoteRequest) ToInput() UpdateNoteInput {
+	return UpdateNoteInput{
+		UserID: req.UserID,
+		NoteID: req.NoteID,
+		Body:   req.Body,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Delete Note Request
+// -------------------------------------------------------------------------
+
+// DeleteNoteRequest is the HTTP request struct for deleting a note
+type DeleteNoteRequest struct {
+	UserID string
+	NoteID string
+}
+
+func (req *DeleteNoteRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.NoteID = chi.URLParam(r, "noteID")
+	if _, err := uuid.Parse(req.NoteID); err != nil {
+		return errors.Validation("invalid note id")
+	}
+	return nil
+}
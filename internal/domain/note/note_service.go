@@ -0,0 +1,67 @@
+package note
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxNotesPerItem caps the notes a user keeps on one learning item.
+const maxNotesPerItem = 50
+
+// NoteService manages the private notes users keep on videos and dialogs.
+type NoteService struct {
+	noteRepo NoteRepository
+}
+
+// NewNoteService creates a new NoteService.
+func NewNoteService(noteRepo NoteRepository) *NoteService {
+	return &NoteService{noteRepo: noteRepo}
+}
+
+// CreateNote adds a note to a learning item the user can open.
+func (s *NoteService) CreateNote(ctx context.Context, input CreateNoteInput) (*Note, *errors.AppError) {
+	if err := s.visibleItem(ctx, input.ItemID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.noteRepo.CountNotes(ctx, input.ItemID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxNotesPerItem {
+		return nil, errors.Conflict(fmt.Sprintf("at most %d notes are allowed per item", maxNotesPerItem))
+	}
+
+	return s.noteRepo.CreateNote(ctx, input.ItemID, input.UserID, input.Body)
+}
+
+// ListNotes returns the user's notes on a learning item.
+func (s *NoteService) ListNotes(ctx context.Context, itemID, userID string) ([]*Note, *errors.AppError) {
+	if err := s.visibleItem(ctx, itemID, userID); err != nil {
+		return nil, err
+	}
+	return s.noteRepo.ListNotes(ctx, itemID, userID)
+}
+
+// UpdateNote replaces the body of the user's note.
+func (s *NoteService) UpdateNote(ctx context.Context, input UpdateNoteInput) (*Note, *errors.AppError) {
+	return s.noteRepo.UpdateNote(ctx, input.NoteID, input.UserID, input.Body)
+}
+
+// DeleteNote deletes the user's note.
+func (s *NoteService) DeleteNote(ctx context.Context, noteID, userID string) *errors.AppError {
+	return s.noteRepo.DeleteNote(ctx, noteID, userID)
+}
+
+func (s *NoteService) visibleItem(ctx context.Context, itemID, userID string) *errors.AppError {
+	ok, err := s.noteRepo.IsVisible(ctx, itemID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.NotFound("learning item not found")
+	}
+	return nil
+}
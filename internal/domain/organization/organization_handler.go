@@ -0,0 +1,149 @@
+package organization
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// OrganizationHandler handles organization admin HTTP endpoints.
+type OrganizationHandler struct {
+	service *OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler.
+func NewOrganizationHandler(service *OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+	}
+}
+
+// ListOrganizations handles GET /api/v1/admin/organizations.
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.ListOrganizations(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// CreateOrganization handles POST /api/v1/admin/organizations.
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrganizationRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.CreateOrganization(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, result)
+}
+
+// AddMember handles PUT /api/v1/admin/organizations/{orgID}/members/{userID}.
+func (h *OrganizationHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var req MemberRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.AddMember(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// RemoveMember handles DELETE /api/v1/admin/organizations/{orgID}/members/{userID}.
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	var req MemberRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.RemoveMember(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// SetImageStyle handles PUT /api/v1/admin/organizations/{orgID}/image-style.
+func (h *OrganizationHandler) SetImageStyle(w http.ResponseWriter, r *http.Request) {
+	var req ImageStyleRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.SetImageStyle(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ListCredentials handles GET /api/v1/admin/organizations/{orgID}/credentials.
+func (h *OrganizationHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	var req OrganizationRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListCredentials(r.Context(), req.OrganizationID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// SetCredentials handles PUT /api/v1/admin/organizations/{orgID}/credentials/{provider}.
+func (h *OrganizationHandler) SetCredentials(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+	if err := req.ParseAndValidate(r, true); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.SetCredentials(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// DeleteCredentials handles DELETE /api/v1/admin/organizations/{orgID}/credentials/{provider}.
+func (h *OrganizationHandler) DeleteCredentials(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+	if err := req.ParseAndValidate(r, false); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.DeleteCredentials(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
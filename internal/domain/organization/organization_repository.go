@@ -0,0 +1,165 @@
+package organization
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Organization is an enterprise customer whose members can use its own provider keys.
+type Organization struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	MemberCount int       `json:"member_count"`
+	Providers   []string  `json:"providers"`   // providers with organization credentials
+	ImageStyle  string    `json:"image_style"` // default image style preset ("" uses the platform's)
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OrganizationRepository stores organizations, their members and the audit trail of admin changes.
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, name string) (*Organization, *errors.AppError)
+	ListOrganizations(ctx context.Context) ([]*Organization, *errors.AppError)
+	GetOrganization(ctx context.Context, organizationID string) (*Organization, *errors.AppError)
+	SetImageStyle(ctx context.Context, organizationID, style string) *errors.AppError
+	OrganizationImageStyle(ctx context.Context, organizationID string) (string, *errors.AppError)
+	AddMember(ctx context.Context, organizationID, userID string) *errors.AppError
+	RemoveMember(ctx context.Context, organizationID, userID string) *errors.AppError
+	WriteAudit(ctx context.Context, admin, action string, targetUserID *string, metadata map[string]interface{}) *errors.AppError
+}
+
+type organizationRepository struct {
+	db *client.PostgresClient
+}
+
+// NewOrganizationRepository creates a new organization repository.
+func NewOrganizationRepository(db *client.PostgresClient) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+const organizationColumns = `
+	o.id::text, o.name, COALESCE(o.image_style, ''), o.created_at,
+	(SELECT COUNT(*) FROM users u WHERE u.organization_id = o.id),
+	COALESCE((SELECT array_agg(c.provider ORDER BY c.provider) FROM organization_credentials c WHERE c.organization_id = o.id), '{}')
+`
+
+func scanOrganization(row pgx.Row) (*Organization, error) {
+	var org Organization
+	if err := row.Scan(&org.ID, &org.Name, &org.ImageStyle, &org.CreatedAt, &org.MemberCount, &org.Providers); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// CreateOrganization inserts a new organization.
+func (r *organizationRepository) CreateOrganization(ctx context.Context, name string) (*Organization, *errors.AppError) {
+	org := &Organization{Name: name, Providers: []string{}}
+	err := r.db.Pool.QueryRow(ctx, `
+		INSERT INTO organizations (name) VALUES ($1)
+		RETURNING id::text, created_at
+	`, name).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create organization", err)
+	}
+	return org, nil
+}
+
+// ListOrganizations returns every organization by name.
+func (r *organizationRepository) ListOrganizations(ctx context.Context) ([]*Organization, *errors.AppError) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT `+organizationColumns+` FROM organizations o ORDER BY o.name, o.id`)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list organizations", err)
+	}
+	defer rows.Close()
+
+	result := make([]*Organization, 0)
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, errors.InternalWrap("failed to scan organization", err)
+		}
+		result = append(result, org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list organizations", err)
+	}
+	return result, nil
+}
+
+// GetOrganization returns one organization.
+func (r *organizationRepository) GetOrganization(ctx context.Context, organizationID string) (*Organization, *errors.AppError) {
+	org, err := scanOrganization(r.db.Pool.QueryRow(ctx, `SELECT `+organizationColumns+` FROM organizations o WHERE o.id = $1`, organizationID))
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("organization not found")
+	}
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get organization", err)
+	}
+	return org, nil
+}
+
+// SetImageStyle sets the organization's default image style ("" clears it).
+func (r *organizationRepository) SetImageStyle(ctx context.Context, organizationID, style string) *errors.AppError {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE organizations SET image_style = NULLIF($2, '') WHERE id = $1`, organizationID, style)
+	if err != nil {
+		return errors.InternalWrap("failed to set organization image style", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("organization not found")
+	}
+	return nil
+}
+
+// OrganizationImageStyle returns the organization's default image style ("" for none).
+func (r *organizationRepository) OrganizationImageStyle(ctx context.Context, organizationID string) (string, *errors.AppError) {
+	var style string
+	err := r.db.Pool.QueryRow(ctx, `SELECT COALESCE(image_style, '') FROM organizations WHERE id = $1`, organizationID).Scan(&style)
+	if err != nil && err != pgx.ErrNoRows {
+		return "", errors.InternalWrap("failed to get organization image style", err)
+	}
+	return style, nil
+}
+
+// AddMember moves a user into the organization (a user belongs to at most one).
+func (r *organizationRepository) AddMember(ctx context.Context, organizationID, userID string) *errors.AppError {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE users SET organization_id = $1 WHERE id = $2`, organizationID, userID)
+	if err != nil {
+		return errors.InternalWrap("failed to add organization member", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("user not found")
+	}
+	return nil
+}
+
+// RemoveMember takes a user out of the organization.
+func (r *organizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) *errors.AppError {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE users SET organization_id = NULL WHERE id = $1 AND organization_id = $2`, userID, organizationID)
+	if err != nil {
+		return errors.InternalWrap("failed to remove organization member", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("user is not a member of the organization")
+	}
+	return nil
+}
+
+// WriteAudit records an admin change in the audit log.
+func (r *organizationRepository) WriteAudit(ctx context.Context, admin, action string, targetUserID *string, metadata map[string]interface{}) *errors.AppError {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.InternalWrap("failed to marshal audit metadata", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO admin_audit_log (admin, action, target_user_id, metadata)
+		VALUES ($1, $2, $3, $4)
+	`, admin, action, targetUserID, metadataJSON); err != nil {
+		return errors.InternalWrap("failed to write audit log", err)
+	}
+	return nil
+}
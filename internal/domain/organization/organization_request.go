@@ -0,0 +1,266 @@
+package organization
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxNameLength bounds an organization name.
+const maxNameLength = 255
+
+// Audit log actions
+const (
+	ACTION_SET_CREDENTIALS    = "set_credentials"
+	ACTION_DELETE_CREDENTIALS = "delete_credentials"
+	ACTION_ADD_MEMBER         = "add_org_member"
+	ACTION_REMOVE_MEMBER      = "remove_org_member"
+	ACTION_SET_IMAGE_STYLE    = "set_org_image_style"
+)
+
+// adminFromRequest reads the admin name from basic auth (checked by the admin middleware)
+func adminFromRequest(r *http.Request) (string, error) {
+	admin, _, ok := r.BasicAuth()
+	if !ok || admin == "" {
+		return "", errors.Unauthorized("admin not authenticated")
+	}
+	return admin, nil
+}
+
+// -------------------------------------------------------------------------
+// Create Organization Request
+// -------------------------------------------------------------------------
+
+// CreateOrganizationRequest is the HTTP request struct for creating an organization
+type CreateOrganizationRequest struct {
+	Admin string `json:"-"`
+	Name  string `json:"name"`
+}
+
+// CreateOrganizationInput is the input struct for service
+type CreateOrganizationInput struct {
+	Admin string
+	Name  string
+}
+
+func (req *CreateOrganizationRequest) ParseAndValidate(r *http.Request) error {
+	admin, err := adminFromRequest(r)
+	if err != nil {
+		return err
+	}
+	req.Admin = admin
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if len(req.Name) > maxNameLength {
+		return errors.Validation("name must be at most 255 characters")
+	}
+
+	return nil
+}
+
+func (req *CreateOrganizationRequest) ToInput() CreateOrganizationInput {
+	return CreateOrganizationInput{Admin: req.Admin, Name: req.Name}
+}
+
+// -------------------------------------------------------------------------
+// Organization Request
+// -------------------------------------------------------------------------
+
+// OrganizationRequest is the HTTP request struct for routes under one organization
+type OrganizationRequest struct {
+	Admin          string `json:"-"`
+	OrganizationID string `json:"-"`
+}
+
+func (req *OrganizationRequest) ParseAndValidate(r *http.Request) error {
+	admin, err := adminFromRequest(r)
+	if err != nil {
+		return err
+	}
+	req.Admin = admin
+
+	req.OrganizationID = chi.URLParam(r, "orgID")
+	if _, err := uuid.Parse(req.OrganizationID); err != nil {
+		return errors.Validation("organization ID must be a UUID")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// Member Request
+// -------------------------------------------------------------------------
+
+// MemberRequest is the HTTP request struct for adding or removing a member
+type MemberRequest struct {
+	OrganizationRequest
+	UserID string
+}
+
+// MemberInput is the input struct for service
+type MemberInput struct {
+	Admin          string
+	OrganizationID string
+	UserID         string
+}
+
+func (req *MemberRequest) ParseAndValidate(r *http.Request) error {
+	if err := req.OrganizationRequest.ParseAndValidate(r); err != nil {
+		return err
+	}
+
+	req.UserID = chi.URLParam(r, "userID")
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		return errors.Validation("user ID must be a UUID")
+	}
+
+	return nil
+}
+
+func (req *MemberRequest) ToInput() MemberInput {
+	return MemberInput{Admin: req.Admin, OrganizationID: req.OrganizationID, UserID: req.UserID}
+}
+
+// -------------------------------------------------------------------------
+// Credentials Request
+// -------------------------------------------------------------------------
+
+// CredentialsRequest is the HTTP request struct for setting or removing provider credentials
+type CredentialsRequest struct {
+	OrganizationRequest
+	Provider string `json:"-"`
+
+	Endpoint       string          `json:"endpoint"`
+	Region         string          `json:"region"`
+	APIKey         string          `json:"api_key"`
+	ServiceAccount json.RawMessage `json:"service_account"`
+}
+
+// CredentialsInput is the input struct for service
+type CredentialsInput struct {
+	Admin          string
+	OrganizationID string
+	Provider       string
+	Credentials    client.ProviderCredentials
+}
+
+// ParseAndValidate reads the provider and, when withBody is set, its credentials
+func (req *CredentialsRequest) ParseAndValidate(r *http.Request, withBody bool) error {
+	if err := req.OrganizationRequest.ParseAndValidate(r); err != nil {
+		return err
+	}
+
+	req.Provider = chi.URLParam(r, "provider")
+	if !client.CredentialProviders[req.Provider] {
+		return errors.Validation("unknown credentials provider")
+	}
+
+	if !withBody {
+		return nil
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Endpoint = strings.TrimSpace(req.Endpoint)
+	req.Region = strings.TrimSpace(req.Region)
+	req.APIKey = strings.TrimSpace(req.APIKey)
+
+	switch req.Provider {
+	case client.CredentialAzureOpenAI, client.CredentialAzureWhisper:
+		if !strings.HasPrefix(req.Endpoint, "https://") {
+			return errors.Validation("endpoint must be an https URL")
+		}
+		if req.APIKey == "" {
+			return errors.Validation("api_key is required")
+		}
+	case client.CredentialAzureSpeech:
+		if req.Region == "" {
+			return errors.Validation("region is required")
+		}
+		if req.APIKey == "" {
+			return errors.Validation("api_key is required")
+		}
+	case client.CredentialGCP:
+		var sa struct {
+			Type        string `json:"type"`
+			ProjectID   string `json:"project_id"`
+			ClientEmail string `json:"client_email"`
+			PrivateKey  string `json:"private_key"`
+		}
+		if len(req.ServiceAccount) == 0 || json.Unmarshal(req.ServiceAccount, &sa) != nil {
+			return errors.Validation("service_account must be a service account JSON object")
+		}
+		if sa.Type != "service_account" || sa.ProjectID == "" || sa.ClientEmail == "" || sa.PrivateKey == "" {
+			return errors.Validation("service_account must have type, project_id, client_email and private_key")
+		}
+	}
+
+	return nil
+}
+
+func (req *CredentialsRequest) ToInput() CredentialsInput {
+	secret := req.APIKey
+	if req.Provider == client.CredentialGCP {
+		secret = string(req.ServiceAccount)
+	}
+	return CredentialsInput{
+		Admin:          req.Admin,
+		OrganizationID: req.OrganizationID,
+		Provider:       req.Provider,
+		Credentials: client.ProviderCredentials{
+			Endpoint: req.Endpoint,
+			Region:   req.Region,
+			Secret:   secret,
+		},
+	}
+}
+
+// -------------------------------------------------------------------------
+// Image Style Request
+// -------------------------------------------------------------------------
+
+// ImageStyleRequest is the HTTP request struct for setting the organization's default image style
+type ImageStyleRequest struct {
+	OrganizationRequest
+	ImageStyle string `json:"image_style"`
+}
+
+// ImageStyleInput is the input struct for service
+type ImageStyleInput struct {
+	Admin          string
+	OrganizationID string
+	ImageStyle     string
+}
+
+func (req *ImageStyleRequest) ParseAndValidate(r *http.Request) error {
+	if err := req.OrganizationRequest.ParseAndValidate(r); err != nil {
+		return err
+	}
+
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	req.ImageStyle = client.ImageStyleName(req.ImageStyle)
+	if req.ImageStyle != "" && !client.IsImageStyle(req.ImageStyle) {
+		return errors.Validation("image_style must be photorealistic, vector, watercolor, kid_friendly or empty")
+	}
+
+	return nil
+}
+
+func (req *ImageStyleRequest) ToInput() ImageStyleInput {
+	return ImageStyleInput{Admin: req.Admin, OrganizationID: req.OrganizationID, ImageStyle: req.ImageStyle}
+}
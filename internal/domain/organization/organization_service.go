@@ -0,0 +1,170 @@
+package organization
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// CredentialsResponse lists the providers an organization has its own credentials for.
+type CredentialsResponse struct {
+	OrganizationID string                  `json:"organization_id"`
+	Credentials    []client.CredentialInfo `json:"credentials"`
+}
+
+// OrganizationService manages organizations, their members and their provider credentials.
+type OrganizationService struct {
+	orgRepo     OrganizationRepository
+	credentials *client.CredentialStore
+	log         *slog.Logger
+}
+
+// NewOrganizationService creates a new organization service.
+func NewOrganizationService(orgRepo OrganizationRepository, credentials *client.CredentialStore, log *slog.Logger) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:     orgRepo,
+		credentials: credentials,
+		log:         log,
+	}
+}
+
+// CreateOrganization creates an organization without members or credentials.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, input CreateOrganizationInput) (*Organization, *errors.AppError) {
+	org, err := s.orgRepo.CreateOrganization(ctx, input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Organization created", "organization_id", org.ID, "admin", input.Admin)
+	return org, nil
+}
+
+// ListOrganizations returns every organization.
+func (s *OrganizationService) ListOrganizations(ctx context.Context) ([]*Organization, *errors.AppError) {
+	return s.orgRepo.ListOrganizations(ctx)
+}
+
+// AddMember moves a user into the organization; their next requests use its credentials.
+func (s *OrganizationService) AddMember(ctx context.Context, input MemberInput) (*Organization, *errors.AppError) {
+	return s.setMember(ctx, input, true)
+}
+
+// RemoveMember takes a user out of the organization; their next requests use platform keys.
+func (s *OrganizationService) RemoveMember(ctx context.Context, input MemberInput) (*Organization, *errors.AppError) {
+	return s.setMember(ctx, input, false)
+}
+
+func (s *OrganizationService) setMember(ctx context.Context, input MemberInput, member bool) (*Organization, *errors.AppError) {
+	if _, err := s.orgRepo.GetOrganization(ctx, input.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	action := ACTION_ADD_MEMBER
+	if member {
+		if err := s.orgRepo.AddMember(ctx, input.OrganizationID, input.UserID); err != nil {
+			return nil, err
+		}
+	} else {
+		action = ACTION_REMOVE_MEMBER
+		if err := s.orgRepo.RemoveMember(ctx, input.OrganizationID, input.UserID); err != nil {
+			return nil, err
+		}
+	}
+	s.credentials.ForgetUser(input.UserID)
+
+	if err := s.orgRepo.WriteAudit(ctx, input.Admin, action, &input.UserID, map[string]interface{}{
+		"organization_id": input.OrganizationID,
+	}); err != nil {
+		s.log.Warn("Failed to audit organization membership", "organization_id", input.OrganizationID, "user_id", input.UserID, "error", err.Error())
+	}
+
+	return s.orgRepo.GetOrganization(ctx, input.OrganizationID)
+}
+
+// SetImageStyle sets the style preset the organization's dialog images are drawn in when a
+// request names none ("" falls back to IMAGE_STYLE_DEFAULT)..
+func (s *OrganizationService) SetImageStyle(ctx context.Context, input ImageStyleInput) (*Organization, *errors.AppError) {
+	if err := s.orgRepo.SetImageStyle(ctx, input.OrganizationID, input.ImageStyle); err != nil {
+		return nil, err
+	}
+
+	if err := s.orgRepo.WriteAudit(ctx, input.Admin, ACTION_SET_IMAGE_STYLE, nil, map[string]interface{}{
+		"organization_id": input.OrganizationID,
+		"image_style":     input.ImageStyle,
+	}); err != nil {
+		s.log.Warn("Failed to audit organization image style", "organization_id", input.OrganizationID, "error", err.Error())
+	}
+
+	return s.orgRepo.GetOrganization(ctx, input.OrganizationID)
+}
+
+// ListCredentials returns the organization's credentials without their secrets.
+func (s *OrganizationService) ListCredentials(ctx context.Context, organizationID string) (*CredentialsResponse, *errors.AppError) {
+	if err := s.requireCredentials(); err != nil {
+		return nil, err
+	}
+	if _, err := s.orgRepo.GetOrganization(ctx, organizationID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.credentials.List(ctx, organizationID)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list organization credentials", err)
+	}
+	return &CredentialsResponse{OrganizationID: organizationID, Credentials: list}, nil
+}
+
+// SetCredentials encrypts and stores the organization's credentials for a provider. Calls of its
+// members switch to them once the credentials cache of each instance expires.
+func (s *OrganizationService) SetCredentials(ctx context.Context, input CredentialsInput) (*CredentialsResponse, *errors.AppError) {
+	if err := s.requireCredentials(); err != nil {
+		return nil, err
+	}
+	if _, err := s.orgRepo.GetOrganization(ctx, input.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	if err := s.credentials.Save(ctx, input.OrganizationID, input.Provider, input.Credentials); err != nil {
+		return nil, errors.InternalWrap("failed to save organization credentials", err)
+	}
+	s.audit(ctx, input, ACTION_SET_CREDENTIALS)
+
+	return s.ListCredentials(ctx, input.OrganizationID)
+}
+
+// DeleteCredentials removes the organization's credentials for a provider; calls fall back to platform keys.
+func (s *OrganizationService) DeleteCredentials(ctx context.Context, input CredentialsInput) (*CredentialsResponse, *errors.AppError) {
+	if err := s.requireCredentials(); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.credentials.Delete(ctx, input.OrganizationID, input.Provider)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to delete organization credentials", err)
+	}
+	if !deleted {
+		return nil, errors.NotFound("organization has no credentials for this provider")
+	}
+	s.audit(ctx, input, ACTION_DELETE_CREDENTIALS)
+
+	return s.ListCredentials(ctx, input.OrganizationID)
+}
+
+func (s *OrganizationService) requireCredentials() *errors.AppError {
+	if !s.credentials.Enabled() {
+		return errors.Unsupported("organization credentials are not enabled on this server")
+	}
+	return nil
+}
+
+func (s *OrganizationService) audit(ctx context.Context, input CredentialsInput, action string) {
+	if err := s.orgRepo.WriteAudit(ctx, input.Admin, action, nil, map[string]interface{}{
+		"organization_id": input.OrganizationID,
+		"provider":        input.Provider,
+	}); err != nil {
+		s.log.Warn("Failed to audit organization credentials", "organization_id", input.OrganizationID, "provider", input.Provider, "error", err.Error())
+	}
+	s.log.Info("Organization credentials changed", "organization_id", input.OrganizationID, "provider", input.Provider, "action", action, "admin", input.Admin)
+}
@@ -36,3 +36,37 @@ func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 	response.OK(w, profile)
 }
+
+// SetBirthDate handles PUT /api/v1/profile/birth-date.
+func (h *ProfileHandler) SetBirthDate(w http.ResponseWriter, r *http.Request) {
+	var req SetBirthDateRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	profile, err := h.service.SetBirthDate(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, profile)
+}
+
+// AdminSetBirthDate handles PUT /api/v1/admin/users/{userID}/birth-date.
+func (h *ProfileHandler) AdminSetBirthDate(w http.ResponseWriter, r *http.Request) {
+	var req SetBirthDateRequest
+	if err := req.ParseAdmin(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	profile, err := h.service.SetBirthDate(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, profile)
+}
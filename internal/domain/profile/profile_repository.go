@@ -2,6 +2,7 @@ package profile
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -17,11 +18,14 @@ type Profile struct {
 	AvatarURL   *string   `json:"avatar_url,omitempty"`
 	Bio         *string   `json:"bio,omitempty"`
 	Settings    []byte    `json:"settings,omitempty"`
+	// YYYY-MM-DD; learners under client.AdultAge only get general-audience content
+	BirthDate *string `json:"birth_date"`
 }
 
 // ProfileRepository loads profile data from storage.
 type ProfileRepository interface {
 	GetProfile(ctx context.Context, userID string) (*Profile, *errors.AppError)
+	SetBirthDate(ctx context.Context, userID string, birthDate time.Time, overwrite bool) (bool, *errors.AppError)
 }
 
 type profileRepository struct {
@@ -35,7 +39,7 @@ func NewProfileRepository(db *client.PostgresClient) ProfileRepository {
 
 func (r *profileRepository) GetProfile(ctx context.Context, userID string) (*Profile, *errors.AppError) {
 	query := `
-		SELECT id, email, display_name, avatar_url, bio, settings
+		SELECT id, email, display_name, avatar_url, bio, settings, to_char(birth_date, 'YYYY-MM-DD')
 		FROM users
 		WHERE id = $1
 	`
@@ -48,6 +52,7 @@ func (r *profileRepository) GetProfile(ctx context.Context, userID string) (*Pro
 		&profile.AvatarURL,
 		&profile.Bio,
 		&profile.Settings,
+		&profile.BirthDate,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -58,3 +63,19 @@ func (r *profileRepository) GetProfile(ctx context.Context, userID string) (*Pro
 
 	return &profile, nil
 }
+
+// SetBirthDate stores the user's birth date. Without overwrite an existing birth date is kept
+// and false is returned.
+func (r *profileRepository) SetBirthDate(ctx context.Context, userID string, birthDate time.Time, overwrite bool) (bool, *errors.AppError) {
+	query := `
+		UPDATE users
+		SET birth_date = $2, updated_at = NOW()
+		WHERE id = $1 AND ($3 OR birth_date IS NULL)
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, query, userID, birthDate, overwrite)
+	if err != nil {
+		return false, errors.InternalWrap("failed to set birth date", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,83 @@
+package profile
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxLearnerAge bounds birth dates to a plausible range.
+const maxLearnerAge = 120
+
+// -------------------------------------------------------------------------
+// Set Birth Date Request
+// -------------------------------------------------------------------------
+
+// SetBirthDateRequest is the HTTP request struct for setting a learner's birth date
+type SetBirthDateRequest struct {
+	UserID    string    `json:"-"`
+	BirthDate string    `json:"birth_date"`
+	Date      time.Time `json:"-"`
+	Overwrite bool      `json:"-"`
+}
+
+// SetBirthDateInput is the input struct for service
+type SetBirthDateInput struct {
+	UserID    string
+	BirthDate time.Time
+	Overwrite bool
+}
+
+// ParseAndValidate reads the birth date of the authenticated learner
+func (req *SetBirthDateRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	return req.parseBody(r)
+}
+
+// ParseAdmin reads the birth date support sets for the user in the URL, replacing any existing one
+func (req *SetBirthDateRequest) ParseAdmin(r *http.Request) error {
+	req.UserID = chi.URLParam(r, "userID")
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		return errors.Validation("invalid user id")
+	}
+	req.Overwrite = true
+
+	return req.parseBody(r)
+}
+
+func (req *SetBirthDateRequest) parseBody(r *http.Request) error {
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	date, err := time.Parse(time.DateOnly, strings.TrimSpace(req.BirthDate))
+	if err != nil {
+		return errors.Validation("birth_date must be YYYY-MM-DD")
+	}
+	now := time.Now().UTC()
+	if date.After(now) || date.Before(now.AddDate(-maxLearnerAge, 0, 0)) {
+		return errors.Validation("birth_date is out of range")
+	}
+	req.Date = date
+
+	return nil
+}
+
+// ToInput convert SetBirthDateRequest to SetBirthDateInput
+func (req *SetBirthDateRequest) ToInput() SetBirthDateInput {
+	return SetBirthDateInput{
+		UserID:    req.UserID,
+		BirthDate: req.Date,
+		Overwrite: req.Overwrite,
+	}
+}
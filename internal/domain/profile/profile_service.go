@@ -22,3 +22,20 @@ func NewProfileService(profileRepo ProfileRepository) *ProfileService {
 func (s *ProfileService) GetProfile(ctx context.Context, userID string) (*Profile, *errors.AppError) {
 	return s.profileRepo.GetProfile(ctx, userID)
 }
+
+// SetBirthDate sets the learner's birth date once. Later changes go through support, so a
+// minor cannot lift the content restrictions on their own.
+func (s *ProfileService) SetBirthDate(ctx context.Context, input SetBirthDateInput) (*Profile, *errors.AppError) {
+	updated, err := s.profileRepo.SetBirthDate(ctx, input.UserID, input.BirthDate, input.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		if input.Overwrite {
+			return nil, errors.NotFound("profile not found")
+		}
+		return nil, errors.Conflict("birth date is already set, contact support to change it")
+	}
+
+	return s.profileRepo.GetProfile(ctx, input.UserID)
+}
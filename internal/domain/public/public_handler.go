@@ -0,0 +1,92 @@
+package public
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// PublicHandler handles the unauthenticated content API and its admin approval endpoints.
+type PublicHandler struct {
+	service *PublicService
+	maxAge  time.Duration
+}
+
+// NewPublicHandler creates a new PublicHandler. Public responses may be cached for maxAge.
+func NewPublicHandler(service *PublicService, maxAge time.Duration) *PublicHandler {
+	return &PublicHandler{
+		service: service,
+		maxAge:  maxAge,
+	}
+}
+
+// cache lets browsers and CDNs keep public responses, serving stale copies while they revalidate.
+func (h *PublicHandler) cache(w http.ResponseWriter) {
+	seconds := int(h.maxAge.Seconds())
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", seconds, seconds))
+}
+
+// ListItems handles GET /public/v1/items
+func (h *PublicHandler) ListItems(w http.ResponseWriter, r *http.Request) {
+	var req ListPublicItemsRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListItems(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	h.cache(w)
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
+
+// GetItem handles GET /public/v1/items/{itemID}
+func (h *PublicHandler) GetItem(w http.ResponseWriter, r *http.Request) {
+	var req ItemIDRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	item, err := h.service.GetItem(r.Context(), req.ItemID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	item.Details = response.NegotiateMediaJSON(r, item.Details)
+	w.Header().Add("Vary", "Accept")
+	h.cache(w)
+	response.OK(w, item)
+}
+
+// Approve handles PUT /api/v1/admin/public/{itemID}
+func (h *PublicHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.setPublic(w, r, true)
+}
+
+// Withdraw handles DELETE /api/v1/admin/public/{itemID}
+func (h *PublicHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	h.setPublic(w, r, false)
+}
+
+func (h *PublicHandler) setPublic(w http.ResponseWriter, r *http.Request, public bool) {
+	var req ItemIDRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.service.SetPublic(r.Context(), req.ItemID, public); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{"id": req.ItemID, "public": public})
+}
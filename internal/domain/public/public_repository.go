@@ -0,0 +1,134 @@
+package public
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// PublicItem is an approved learning item as the public API shows it. Dialogs show their
+// published snapshot, never the draft.
+type PublicItem struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Content   string          `json:"content"`
+	Language  string          `json:"language"`
+	Level     *string         `json:"level"`
+	Tags      json.RawMessage `json:"tags"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PublicRepository reads approved public content and sets the approval flag.
+type PublicRepository interface {
+	ListItems(ctx context.Context, featureID int, language string, limit, offset int) ([]*PublicItem, int, *errors.AppError)
+	GetItem(ctx context.Context, itemID string) (*PublicItem, *errors.AppError)
+	SetPublic(ctx context.Context, itemID string, public bool) *errors.AppError
+}
+
+type publicRepository struct {
+	db *client.PostgresClient
+}
+
+// NewPublicRepository creates a new public content repository.
+func NewPublicRepository(db *client.PostgresClient) PublicRepository {
+	return &publicRepository{db: db}
+}
+
+// publicWhere limits queries (on alias l) to approved, active, general-audience and, for dialogs, published items.
+var publicWhere = fmt.Sprintf(`l.is_public AND l.is_active = true AND l.audience = '%s' AND (l.feature_id = %d OR (l.feature_id = %d AND l.published_details IS NOT NULL))`, client.AudienceGeneral, client.FeatureVideo, client.FeatureDialog)
+
+// ListItems returns public items without details, newest first. featureID 0 lists every type.
+func (r *publicRepository) ListItems(ctx context.Context, featureID int, language string, limit, offset int) ([]*PublicItem, int, *errors.AppError) {
+	where := publicWhere + ` AND ($1 = 0 OR l.feature_id = $1) AND ($2 = '' OR l.language = $2)`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM learning_items l WHERE `+where, featureID, language).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count public items", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT l.id::text, l.feature_id, l.content, l.language, l.level, l.tags, l.created_at
+		FROM learning_items l
+		WHERE `+where+`
+		ORDER BY l.created_at DESC, l.id
+		LIMIT $3 OFFSET $4
+	`, featureID, language, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list public items", err)
+	}
+	defer rows.Close()
+
+	items := []*PublicItem{}
+	for rows.Next() {
+		var item PublicItem
+		var itemFeatureID int
+		if err := rows.Scan(&item.ID, &itemFeatureID, &item.Content, &item.Language, &item.Level, &item.Tags, &item.CreatedAt); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan public item", err)
+		}
+		item.Type = itemType(itemFeatureID)
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list public items", err)
+	}
+
+	return items, total, nil
+}
+
+func (r *publicRepository) GetItem(ctx context.Context, itemID string) (*PublicItem, *errors.AppError) {
+	var item PublicItem
+	var featureID int
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT l.id::text, l.feature_id, l.content, l.language, l.level, l.tags,
+			CASE WHEN l.feature_id = $2 THEN l.published_details ELSE l.details END,
+			l.created_at
+		FROM learning_items l
+		WHERE l.id = $1 AND `+publicWhere,
+		itemID, client.FeatureDialog,
+	).Scan(&item.ID, &featureID, &item.Content, &item.Language, &item.Level, &item.Tags, &item.Details, &item.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("item not found")
+	}
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get public item", err)
+	}
+	item.Type = itemType(featureID)
+	return &item, nil
+}
+
+// SetPublic approves or withdraws an item. Dialogs must be published before they can be approved.
+func (r *publicRepository) SetPublic(ctx context.Context, itemID string, public bool) *errors.AppError {
+	var featureID int
+	var published bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT feature_id, published_details IS NOT NULL FROM learning_items WHERE id = $1`, itemID).Scan(&featureID, &published)
+	if err == pgx.ErrNoRows {
+		return errors.NotFound("item not found")
+	}
+	if err != nil {
+		return errors.InternalWrap("failed to get item", err)
+	}
+	if featureID != client.FeatureVideo && featureID != client.FeatureDialog {
+		return errors.Validation("only videos and dialogs can be public")
+	}
+	if public && featureID == client.FeatureDialog && !published {
+		return errors.Conflict("publish the dialog before approving it")
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, `UPDATE learning_items SET is_public = $1 WHERE id = $2`, public, itemID); err != nil {
+		return errors.InternalWrap("failed to update item", err)
+	}
+	return nil
+}
+
+func itemType(featureID int) string {
+	if featureID == client.FeatureDialog {
+		return ITEM_TYPE_DIALOG
+	}
+	return ITEM_TYPE_VIDEO
+}
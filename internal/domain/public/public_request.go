@@ -0,0 +1,102 @@
+package public
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/domain/video"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Page size limits
+const (
+	defaultPageSize = 20
+	maxPageSize     = 50
+)
+
+// Item types
+const (
+	ITEM_TYPE_VIDEO  = "video"
+	ITEM_TYPE_DIALOG = "dialog"
+)
+
+// -------------------------------------------------------------------------
+// List Public Items Request
+// -------------------------------------------------------------------------
+
+// ListPublicItemsRequest is the HTTP request struct for listing public content
+type ListPublicItemsRequest struct {
+	Type     string
+	Language string
+	Page     int
+	PageSize int
+}
+
+// ListPublicItemsInput is the input struct for service
+type ListPublicItemsInput struct {
+	Type     string
+	Language string
+	Page     int
+	PageSize int
+	Limit    int
+	Offset   int
+}
+
+// Parse reads the optional type, language and pagination params
+func (req *ListPublicItemsRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.Type = strings.ToLower(q.Get("type"))
+	if req.Type != "" && req.Type != ITEM_TYPE_VIDEO && req.Type != ITEM_TYPE_DIALOG {
+		return errors.Validation("type must be video or dialog")
+	}
+
+	req.Language = strings.ToLower(q.Get("language"))
+	if req.Language != "" && !video.AllowedLanguages[req.Language] {
+		return errors.Validation("unsupported language")
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert ListPublicItemsRequest to ListPublicItemsInput
+func (req *ListPublicItemsRequest) ToInput() ListPublicItemsInput {
+	return ListPublicItemsInput{
+		Type:     req.Type,
+		Language: req.Language,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Item ID Request (public read and admin approval)
+// -------------------------------------------------------------------------
+
+// ItemIDRequest is the HTTP request struct for endpoints addressing one learning item
+type ItemIDRequest struct {
+	ItemID string
+}
+
+func (req *ItemIDRequest) Parse(r *http.Request) error {
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid item id")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package public
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// PublicService serves the curated, unauthenticated content preview.
+type PublicService struct {
+	publicRepo PublicRepository
+}
+
+// ListPublicItemsResponse is returned when listing public items.
+type ListPublicItemsResponse struct {
+	Data []*PublicItem            `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// NewPublicService creates a new PublicService.
+func NewPublicService(publicRepo PublicRepository) *PublicService {
+	return &PublicService{publicRepo: publicRepo}
+}
+
+// ListItems returns a page of approved items.
+func (s *PublicService) ListItems(ctx context.Context, input ListPublicItemsInput) (*ListPublicItemsResponse, *errors.AppError) {
+	featureID := 0
+	switch input.Type {
+	case ITEM_TYPE_VIDEO:
+		featureID = client.FeatureVideo
+	case ITEM_TYPE_DIALOG:
+		featureID = client.FeatureDialog
+	}
+
+	items, total, err := s.publicRepo.ListItems(ctx, featureID, input.Language, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListPublicItemsResponse{
+		Data: items,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// GetItem returns one approved item with its details.
+func (s *PublicService) GetItem(ctx context.Context, itemID string) (*PublicItem, *errors.AppError) {
+	return s.publicRepo.GetItem(ctx, itemID)
+}
+
+// SetPublic approves an item for the public API, or withdraws it.
+func (s *PublicService) SetPublic(ctx context.Context, itemID string, public bool) *errors.AppError {
+	return s.publicRepo.SetPublic(ctx, itemID, public)
+}
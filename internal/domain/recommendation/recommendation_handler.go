@@ -0,0 +1,36 @@
+package recommendation
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// RecommendationHandler handles recommendation HTTP endpoints.
+type RecommendationHandler struct {
+	service *RecommendationService
+}
+
+// NewRecommendationHandler creates a new recommendation handler.
+func NewRecommendationHandler(service *RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		service: service,
+	}
+}
+
+// GetNextActivities handles GET /api/v1/users/me/next.
+func (h *RecommendationHandler) GetNextActivities(w http.ResponseWriter, r *http.Request) {
+	var req GetNextActivitiesRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	feed, err := h.service.GetNextActivities(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, feed)
+}
@@ -0,0 +1,236 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Candidate is an activity the user could do next, with the signals strategies score on.
+type Candidate struct {
+	Kind     string
+	ID       string
+	Title    string
+	Language string
+	Level    string
+	// LastActivity is when the user last touched the item (reviews, unfinished dialogs, quizzes)
+	LastActivity *time.Time
+	// CreatedAt is when the item was added (new videos)
+	CreatedAt *time.Time
+	// Score is the user's best quiz score (weak quizzes)
+	Score *float64
+	// Status is the review status of a word or sentence (due reviews)
+	Status string
+}
+
+// RecommendationRepository loads recommendation candidates for a user.
+type RecommendationRepository interface {
+	GetUserLevel(ctx context.Context, userID string) (string, *errors.AppError)
+	ListDueReviews(ctx context.Context, userID string, limit int) ([]*Candidate, *errors.AppError)
+	ListUnfinishedDialogs(ctx context.Context, userID string, limit int) ([]*Candidate, *errors.AppError)
+	ListNewVideos(ctx context.Context, userID, level string, limit int) ([]*Candidate, *errors.AppError)
+	ListWeakQuizzes(ctx context.Context, userID string, maxScore float64, limit int) ([]*Candidate, *errors.AppError)
+}
+
+type recommendationRepository struct {
+	db          *client.PostgresClient
+	eventWindow time.Duration
+}
+
+// NewRecommendationRepository creates a new recommendation repository. Activity events are only read
+// from the last eventWindow, the months kept in activity_events (0 reads them all).
+func NewRecommendationRepository(db *client.PostgresClient, eventWindow time.Duration) RecommendationRepository {
+	return &recommendationRepository{db: db, eventWindow: eventWindow}
+}
+
+// eventsSince is the oldest activity event the feed reads, so only recent partitions are scanned.
+func (r *recommendationRepository) eventsSince() time.Time {
+	if r.eventWindow <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-r.eventWindow)
+}
+
+// GetUserLevel returns the level from the user's settings, or else the level they practiced most recently.
+func (r *recommendationRepository) GetUserLevel(ctx context.Context, userID string) (string, *errors.AppError) {
+	query := `
+		SELECT COALESCE(
+			NULLIF(u.settings->>'level', ''),
+			(
+				SELECT l.level
+				FROM user_actions ua
+				JOIN learning_items l ON l.id = ua.learning_id
+				WHERE ua.user_id = u.id AND ua.deleted_at IS NULL AND COALESCE(l.level, '') <> ''
+				ORDER BY ua.updated_at DESC
+				LIMIT 1
+			),
+			''
+		)
+		FROM users u
+		WHERE u.id = $1
+	`
+
+	var level string
+	if err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&level); err != nil {
+		return "", errors.InternalWrap("failed to get user level", err)
+	}
+	return level, nil
+}
+
+// ListDueReviews returns words and sentences the user has not passed yet, least recently practiced first.
+func (r *recommendationRepository) ListDueReviews(ctx context.Context, userID string, limit int) ([]*Candidate, *errors.AppError) {
+	query := `
+		SELECT id::text, content, language, status::text, updated_at
+		FROM user_stats
+		WHERE user_id = $1 AND deleted_at IS NULL AND status <> 'passed'
+		ORDER BY updated_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list due reviews", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]*Candidate, 0)
+	for rows.Next() {
+		c := Candidate{Kind: KIND_REVIEW}
+		if err := rows.Scan(&c.ID, &c.Title, &c.Language, &c.Status, &c.LastActivity); err != nil {
+			return nil, errors.InternalWrap("failed to scan due review", err)
+		}
+		candidates = append(candidates, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list due reviews", err)
+	}
+	return candidates, nil
+}
+
+// ListUnfinishedDialogs returns dialogs the user started (a submission or a completed turn) but has not
+// completed in both chat and speech.
+func (r *recommendationRepository) ListUnfinishedDialogs(ctx context.Context, userID string, limit int) ([]*Candidate, *errors.AppError) {
+	query := `
+		SELECT l.id::text,
+			COALESCE(NULLIF(CASE WHEN l.created_by = $4 THEN l.details ELSE l.published_details END->>'topic', ''), l.content),
+			l.language, COALESCE(l.level, ''), MAX(ua.updated_at)
+		FROM (
+			SELECT learning_id, action_type::text AS activity, updated_at
+			FROM user_actions
+			WHERE user_id = $1 AND deleted_at IS NULL
+				AND action_type IN ('submit_chat', 'submit_speech', 'submit_sparring')
+			UNION ALL
+			-- Turns completed on the client count as started even before a submission
+			SELECT learning_id, event_type, occurred_at
+			FROM activity_events
+			WHERE user_id = $1 AND event_type = 'turn_completed' AND learning_id IS NOT NULL
+				AND occurred_at >= $5
+		) ua
+		JOIN learning_items l ON l.id = ua.learning_id
+		WHERE l.feature_id = $2 AND l.is_active = true
+			AND (l.published_details IS NOT NULL OR l.created_by = $4)
+		GROUP BY l.id
+		HAVING NOT (bool_or(ua.activity = 'submit_chat') AND bool_or(ua.activity = 'submit_speech'))
+		ORDER BY MAX(ua.updated_at) DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, client.FeatureDialog, limit, userID, r.eventsSince())
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list unfinished dialogs", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]*Candidate, 0)
+	for rows.Next() {
+		c := Candidate{Kind: KIND_DIALOG}
+		if err := rows.Scan(&c.ID, &c.Title, &c.Language, &c.Level, &c.LastActivity); err != nil {
+			return nil, errors.InternalWrap("failed to scan unfinished dialog", err)
+		}
+		candidates = append(candidates, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list unfinished dialogs", err)
+	}
+	return candidates, nil
+}
+
+// ListNewVideos returns the newest videos at the given level (any level when empty) the user has not opened or viewed.
+func (r *recommendationRepository) ListNewVideos(ctx context.Context, userID, level string, limit int) ([]*Candidate, *errors.AppError) {
+	query := `
+		SELECT l.id::text, COALESCE(NULLIF(l.details->>'topic', ''), l.content), l.language, COALESCE(l.level, ''), l.created_at
+		FROM learning_items l
+		WHERE l.feature_id = $2 AND l.is_active = true
+			AND ($3 = '' OR l.level = $3)
+			AND NOT EXISTS (
+				SELECT 1 FROM user_actions ua
+				WHERE ua.learning_id = l.id AND ua.user_id = $1 AND ua.deleted_at IS NULL
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM activity_events ae
+				WHERE ae.learning_id = l.id AND ae.user_id = $1 AND ae.event_type = 'item_viewed'
+					AND ae.occurred_at >= $5
+			)
+		ORDER BY l.created_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, client.FeatureVideo, level, limit, r.eventsSince())
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list new videos", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]*Candidate, 0)
+	for rows.Next() {
+		c := Candidate{Kind: KIND_VIDEO}
+		if err := rows.Scan(&c.ID, &c.Title, &c.Language, &c.Level, &c.CreatedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan new video", err)
+		}
+		candidates = append(candidates, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list new videos", err)
+	}
+	return candidates, nil
+}
+
+// ListWeakQuizzes returns videos whose best gist quiz score is below maxScore, weakest first.
+func (r *recommendationRepository) ListWeakQuizzes(ctx context.Context, userID string, maxScore float64, limit int) ([]*Candidate, *errors.AppError) {
+	query := `
+		SELECT l.id::text, COALESCE(NULLIF(l.details->>'topic', ''), l.content), l.language, COALESCE(l.level, ''),
+			ua.updated_at, best.score
+		FROM user_actions ua
+		JOIN learning_items l ON l.id = ua.learning_id
+		CROSS JOIN LATERAL (
+			SELECT MAX((attempt->>'quiz_score')::float8) AS score
+			FROM jsonb_array_elements(COALESCE(ua.metadata->'attempts', '[]'::jsonb)) attempt
+		) best
+		WHERE ua.user_id = $1 AND ua.action_type = 'submit_quiz' AND ua.deleted_at IS NULL
+			AND l.feature_id = $2 AND l.is_active = true
+			AND best.score IS NOT NULL AND best.score < $3
+		ORDER BY best.score ASC
+		LIMIT $4
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, client.FeatureVideo, maxScore, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list weak quizzes", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]*Candidate, 0)
+	for rows.Next() {
+		c := Candidate{Kind: KIND_QUIZ}
+		if err := rows.Scan(&c.ID, &c.Title, &c.Language, &c.Level, &c.LastActivity, &c.Score); err != nil {
+			return nil, errors.InternalWrap("failed to scan weak quiz", err)
+		}
+		candidates = append(candidates, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list weak quizzes", err)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,54 @@
+package recommendation
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Feed size limits
+const (
+	defaultNextLimit = 20
+	maxNextLimit     = 50
+)
+
+// GetNextActivitiesRequest is the HTTP request struct for the next activity feed
+type GetNextActivitiesRequest struct {
+	UserID string
+	Limit  int
+}
+
+// GetNextActivitiesInput is the input struct for service
+type GetNextActivitiesInput struct {
+	UserID string
+	Limit  int
+}
+
+// Parse reads the user and the optional limit param
+func (req *GetNextActivitiesRequest) Parse(r *http.Request) error {
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	req.Limit = defaultNextLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return errors.Validation("limit must be a positive integer")
+		}
+		req.Limit = min(limit, maxNextLimit)
+	}
+
+	return nil
+}
+
+// ToInput convert GetNextActivitiesRequest to GetNextActivitiesInput
+func (req *GetNextActivitiesRequest) ToInput() GetNextActivitiesInput {
+	return GetNextActivitiesInput{
+		UserID: req.UserID,
+		Limit:  req.Limit,
+	}
+}
@@ -0,0 +1,127 @@
+package recommendation
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// weakQuizScore is the best gist quiz score (0-100) below which a quiz is worth retrying.
+const weakQuizScore = 60.0
+
+// kindRepeatPenalty lowers each further item of the same kind so the feed stays mixed.
+const kindRepeatPenalty = 0.9
+
+// NextActivity is one entry of the "next best activity" feed.
+type NextActivity struct {
+	Kind     string  `json:"kind"`
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Language string  `json:"language"`
+	Level    string  `json:"level"`
+	Score    float64 `json:"score"`
+	Reason   string  `json:"reason"`
+}
+
+// NextActivitiesResponse is the ranked feed for the current user.
+type NextActivitiesResponse struct {
+	Level string          `json:"level"`
+	Items []*NextActivity `json:"items"`
+}
+
+// RecommendationService ranks what a user should do next.
+type RecommendationService struct {
+	recommendationRepo RecommendationRepository
+	strategies         map[string]ScoringStrategy
+}
+
+// NewRecommendationService creates a new recommendation service. Candidate kinds without
+// a strategy are left out of the feed.
+func NewRecommendationService(recommendationRepo RecommendationRepository, strategies ...ScoringStrategy) *RecommendationService {
+	byKind := make(map[string]ScoringStrategy, len(strategies))
+	for _, strategy := range strategies {
+		byKind[strategy.Kind()] = strategy
+	}
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		strategies:         byKind,
+	}
+}
+
+// GetNextActivities returns due reviews, unfinished dialogs, new videos and weak quizzes ranked together.
+func (s *RecommendationService) GetNextActivities(ctx context.Context, input GetNextActivitiesInput) (*NextActivitiesResponse, *errors.AppError) {
+	level, err := s.recommendationRepo.GetUserLevel(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each source is capped at the page size; the ranking picks from their union
+	loaders := map[string]func() ([]*Candidate, *errors.AppError){
+		KIND_REVIEW: func() ([]*Candidate, *errors.AppError) {
+			return s.recommendationRepo.ListDueReviews(ctx, input.UserID, input.Limit)
+		},
+		KIND_DIALOG: func() ([]*Candidate, *errors.AppError) {
+			return s.recommendationRepo.ListUnfinishedDialogs(ctx, input.UserID, input.Limit)
+		},
+		KIND_VIDEO: func() ([]*Candidate, *errors.AppError) {
+			return s.recommendationRepo.ListNewVideos(ctx, input.UserID, level, input.Limit)
+		},
+		KIND_QUIZ: func() ([]*Candidate, *errors.AppError) {
+			return s.recommendationRepo.ListWeakQuizzes(ctx, input.UserID, weakQuizScore, input.Limit)
+		},
+	}
+
+	sc := ScoringContext{Now: time.Now().UTC(), Level: level}
+	items := make([]*NextActivity, 0)
+	for kind, load := range loaders {
+		strategy, ok := s.strategies[kind]
+		if !ok {
+			continue
+		}
+		candidates, err := load()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range candidates {
+			score, reason := strategy.Score(c, sc)
+			items = append(items, &NextActivity{
+				Kind:     c.Kind,
+				ID:       c.ID,
+				Title:    c.Title,
+				Language: c.Language,
+				Level:    c.Level,
+				Score:    score,
+				Reason:   reason,
+			})
+		}
+	}
+
+	items = rankActivities(items)
+	if len(items) > input.Limit {
+		items = items[:input.Limit]
+	}
+
+	return &NextActivitiesResponse{Level: level, Items: items}, nil
+}
+
+// rankActivities sorts by score, discounting repeats of a kind so one source cannot fill the feed.
+func rankActivities(items []*NextActivity) []*NextActivity {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	seen := make(map[string]int)
+	for _, item := range items {
+		item.Score = math.Round(item.Score*math.Pow(kindRepeatPenalty, float64(seen[item.Kind]))*1000) / 1000
+		seen[item.Kind]++
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+	return items
+}
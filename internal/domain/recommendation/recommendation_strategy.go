@@ -0,0 +1,92 @@
+package recommendation
+
+import (
+	"math"
+	"time"
+)
+
+// Candidate kinds
+const (
+	KIND_REVIEW = "review" // due word / sentence review
+	KIND_DIALOG = "dialog" // unfinished dialog
+	KIND_VIDEO  = "video"  // new video at the user's level
+	KIND_QUIZ   = "quiz"   // gist quiz with a weak score
+)
+
+// ScoringContext is what strategies know about the user when scoring.
+type ScoringContext struct {
+	Now   time.Time
+	Level string
+}
+
+// ScoringStrategy ranks the candidates of one kind. Scores are roughly 0-1 so kinds mix.
+type ScoringStrategy interface {
+	Kind() string
+	Score(c *Candidate, sc ScoringContext) (score float64, reason string)
+}
+
+// DefaultStrategies returns the built-in strategy for every candidate kind.
+func DefaultStrategies() []ScoringStrategy {
+	return []ScoringStrategy{
+		ReviewStrategy{},
+		DialogStrategy{},
+		VideoStrategy{},
+		QuizStrategy{},
+	}
+}
+
+// ReviewStrategy favors reviews that have waited longest, up to a week.
+type ReviewStrategy struct{}
+
+func (ReviewStrategy) Kind() string { return KIND_REVIEW }
+
+func (ReviewStrategy) Score(c *Candidate, sc ScoringContext) (float64, string) {
+	score := 0.6 + 0.4*math.Min(daysSince(c.LastActivity, sc.Now)/7, 1)
+	if c.Status == "new" {
+		return score, "new word to practice"
+	}
+	return score, "due for review"
+}
+
+// DialogStrategy favors dialogs the user worked on recently.
+type DialogStrategy struct{}
+
+func (DialogStrategy) Kind() string { return KIND_DIALOG }
+
+func (DialogStrategy) Score(c *Candidate, sc ScoringContext) (float64, string) {
+	return 0.3 + 0.6*math.Exp(-daysSince(c.LastActivity, sc.Now)/14), "continue where you left off"
+}
+
+// VideoStrategy favors fresh videos that match the user's level.
+type VideoStrategy struct{}
+
+func (VideoStrategy) Kind() string { return KIND_VIDEO }
+
+func (VideoStrategy) Score(c *Candidate, sc ScoringContext) (float64, string) {
+	score := 0.3 + 0.3*math.Exp(-daysSince(c.CreatedAt, sc.Now)/30)
+	if sc.Level != "" && c.Level == sc.Level {
+		return score + 0.2, "new at your level"
+	}
+	return score, "new video"
+}
+
+// QuizStrategy favors the quizzes with the lowest best score.
+type QuizStrategy struct{}
+
+func (QuizStrategy) Kind() string { return KIND_QUIZ }
+
+func (QuizStrategy) Score(c *Candidate, sc ScoringContext) (float64, string) {
+	best := 0.0
+	if c.Score != nil {
+		best = *c.Score
+	}
+	return 0.4 + 0.5*(1-math.Min(best, weakQuizScore)/weakQuizScore), "improve a weak quiz score"
+}
+
+// daysSince returns the days between t and now (0 when t is unknown or in the future).
+func daysSince(t *time.Time, now time.Time) float64 {
+	if t == nil {
+		return 0
+	}
+	return math.Max(now.Sub(*t).Hours()/24, 0)
+}
@@ -0,0 +1,36 @@
+package romanize
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// RomanizeHandler handles romanization HTTP endpoints.
+type RomanizeHandler struct {
+	service *RomanizeService
+}
+
+// NewRomanizeHandler creates a new romanize handler.
+func NewRomanizeHandler(service *RomanizeService) *RomanizeHandler {
+	return &RomanizeHandler{
+		service: service,
+	}
+}
+
+// Romanize handles GET /api/v1/romanize
+func (h *RomanizeHandler) Romanize(w http.ResponseWriter, r *http.Request) {
+	var req RomanizeRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.Romanize(req.Language, req.Text)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -0,0 +1,39 @@
+package romanize
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxTextLength caps the text romanized per request (characters).
+const maxTextLength = 500
+
+// RomanizeRequest is the HTTP request struct for romanizing a text
+type RomanizeRequest struct {
+	Language string
+	Text     string
+}
+
+// Parse reads the language (zh, ja, ko or their names) and text query params
+func (req *RomanizeRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.Language = strings.TrimSpace(q.Get("language"))
+	if client.RomanizationLanguage(req.Language) == "" {
+		return errors.Validation("language must be zh, ja or ko")
+	}
+
+	req.Text = strings.TrimSpace(q.Get("text"))
+	if req.Text == "" {
+		return errors.Validation("text is required")
+	}
+	if utf8.RuneCountInString(req.Text) > maxTextLength {
+		return errors.Validation("text must be at most 500 characters")
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package romanize
+
+import (
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// RomanizeService returns deterministic readings of Chinese, Japanese and Korean text.
+type RomanizeService struct {
+	romanizer *client.Romanizer
+}
+
+// NewRomanizeService creates a new romanize service.
+func NewRomanizeService(romanizer *client.Romanizer) *RomanizeService {
+	return &RomanizeService{
+		romanizer: romanizer,
+	}
+}
+
+// Romanize returns the reading of text. Incomplete results list the unresolved characters.
+func (s *RomanizeService) Romanize(language, text string) (*client.Romanization, *errors.AppError) {
+	result, ok := s.romanizer.Romanize(language, text)
+	if !ok {
+		return nil, errors.Validation("language must be zh, ja or ko")
+	}
+	return &result, nil
+}
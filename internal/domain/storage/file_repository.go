@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// FileRepository lists the objects stored in R2.
+type FileRepository interface {
+	ListObjects(ctx context.Context, fn func([]client.R2Object) error) *errors.AppError
+}
+
+type fileRepository struct {
+	cloudflare *client.CloudflareClient
+}
+
+// NewFileRepository creates a new storage file repository.
+func NewFileRepository(cloudflare *client.CloudflareClient) FileRepository {
+	return &fileRepository{cloudflare: cloudflare}
+}
+
+func (r *fileRepository) ListObjects(ctx context.Context, fn func([]client.R2Object) error) *errors.AppError {
+	if err := r.cloudflare.ListR2Objects(ctx, "", fn); err != nil {
+		return errors.InternalWrap("failed to list storage objects", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// StorageHandler handles storage usage HTTP endpoints.
+type StorageHandler struct {
+	service *StorageService
+}
+
+// NewStorageHandler creates a new storage handler.
+func NewStorageHandler(service *StorageService) *StorageHandler {
+	return &StorageHandler{
+		service: service,
+	}
+}
+
+// GetUsage handles GET /api/v1/admin/storage/usage.
+func (h *StorageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	var req UsageRequest
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.GetUsage(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OKWithMeta(w, result.Data, result.Meta)
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// UsageRow is the storage one owner uses for one content type ("" owner when unattributed).
+type UsageRow struct {
+	ContentType string
+	UserID      string
+	ObjectCount int64
+	TotalBytes  int64
+}
+
+// ContentTypeUsage is the storage used by one content type.
+type ContentTypeUsage struct {
+	ContentType       string `json:"content_type"`
+	ObjectCount       int64  `json:"object_count"`
+	TotalBytes        int64  `json:"total_bytes"`
+	UnattributedBytes int64  `json:"unattributed_bytes"`
+}
+
+// UserUsage is the storage used by one user, with the bytes per content type.
+type UserUsage struct {
+	UserID      string           `json:"user_id"`
+	Email       string           `json:"email"`
+	DisplayName string           `json:"display_name"`
+	ObjectCount int64            `json:"object_count"`
+	TotalBytes  int64            `json:"total_bytes"`
+	BytesByType map[string]int64 `json:"bytes_by_type"`
+}
+
+// StorageRepository resolves object owners and stores the usage report.
+type StorageRepository interface {
+	ResolveItemOwners(ctx context.Context, itemIDs []string) (map[string]string, *errors.AppError)
+	ResolveActionOwners(ctx context.Context, actionIDs []string) (map[string]string, *errors.AppError)
+	ResolveAttemptOwners(ctx context.Context, attemptIDs []string) (map[string]string, *errors.AppError)
+	SaveUsage(ctx context.Context, rows []*UsageRow, scannedAt time.Time) *errors.AppError
+	ListContentTypeUsage(ctx context.Context) ([]*ContentTypeUsage, *time.Time, *errors.AppError)
+	ListUserUsage(ctx context.Context, contentType string, limit, offset int) ([]*UserUsage, int, *errors.AppError)
+}
+
+type storageRepository struct {
+	db *client.PostgresClient
+}
+
+// NewStorageRepository creates a new storage repository.
+func NewStorageRepository(db *client.PostgresClient) StorageRepository {
+	return &storageRepository{db: db}
+}
+
+// ResolveItemOwners maps learning item ids to the user who created them.
+func (r *storageRepository) ResolveItemOwners(ctx context.Context, itemIDs []string) (map[string]string, *errors.AppError) {
+	return r.resolveOwners(ctx, `
+		SELECT id::text, COALESCE(created_by, '')
+		FROM learning_items
+		WHERE id = ANY($1::text[]::uuid[])
+	`, itemIDs)
+}
+
+// ResolveActionOwners maps user action ids (e.g. sparring sessions) to their user.
+func (r *storageRepository) ResolveActionOwners(ctx context.Context, actionIDs []string) (map[string]string, *errors.AppError) {
+	return r.resolveOwners(ctx, `
+		SELECT id::text, user_id::text
+		FROM user_actions
+		WHERE id = ANY($1::text[]::uuid[])
+	`, actionIDs)
+}
+
+// ResolveAttemptOwners maps retell attempt ids to the user who recorded them.
+func (r *storageRepository) ResolveAttemptOwners(ctx context.Context, attemptIDs []string) (map[string]string, *errors.AppError) {
+	return r.resolveOwners(ctx, `
+		SELECT attempt->>'attempt_id', ua.user_id::text
+		FROM user_actions ua
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(ua.metadata->'attempts', '[]'::jsonb)) AS attempt
+		WHERE ua.action_type = 'submit_retell'
+		AND attempt->>'attempt_id' = ANY($1::text[])
+	`, attemptIDs)
+}
+
+func (r *storageRepository) resolveOwners(ctx context.Context, query string, ids []string) (map[string]string, *errors.AppError) {
+	owners := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return owners, nil
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to resolve storage owners", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, owner string
+		if err := rows.Scan(&id, &owner); err != nil {
+			return nil, errors.InternalWrap("failed to scan storage owner", err)
+		}
+		owners[id] = owner
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to resolve storage owners", err)
+	}
+
+	return owners, nil
+}
+
+// SaveUsage replaces the usage report with rows.
+func (r *storageRepository) SaveUsage(ctx context.Context, rows []*UsageRow, scannedAt time.Time) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM storage_usage`); err != nil {
+		return errors.InternalWrap("failed to clear storage usage", err)
+	}
+	for _, row := range rows {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO storage_usage (content_type, user_id, object_count, total_bytes, scanned_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, row.ContentType, row.UserID, row.ObjectCount, row.TotalBytes, scannedAt); err != nil {
+			return errors.InternalWrap("failed to save storage usage", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit storage usage", err)
+	}
+	return nil
+}
+
+// ListContentTypeUsage returns the usage per content type, largest first, and when it was scanned.
+func (r *storageRepository) ListContentTypeUsage(ctx context.Context) ([]*ContentTypeUsage, *time.Time, *errors.AppError) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT content_type, SUM(object_count)::bigint, SUM(total_bytes)::bigint,
+			COALESCE(SUM(total_bytes) FILTER (WHERE user_id = ''), 0)::bigint, MAX(scanned_at)
+		FROM storage_usage
+		GROUP BY content_type
+		ORDER BY SUM(total_bytes) DESC, content_type
+	`)
+	if err != nil {
+		return nil, nil, errors.InternalWrap("failed to list storage usage", err)
+	}
+	defer rows.Close()
+
+	var scannedAt *time.Time
+	usage := make([]*ContentTypeUsage, 0)
+	for rows.Next() {
+		var u ContentTypeUsage
+		var at time.Time
+		if err := rows.Scan(&u.ContentType, &u.ObjectCount, &u.TotalBytes, &u.UnattributedBytes, &at); err != nil {
+			return nil, nil, errors.InternalWrap("failed to scan storage usage", err)
+		}
+		scannedAt = &at
+		usage = append(usage, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.InternalWrap("failed to list storage usage", err)
+	}
+
+	return usage, scannedAt, nil
+}
+
+// ListUserUsage returns a page of users by the storage they use (of contentType when set), largest first.
+func (r *storageRepository) ListUserUsage(ctx context.Context, contentType string, limit, offset int) ([]*UserUsage, int, *errors.AppError) {
+	where := `WHERE s.user_id <> '' AND ($1 = '' OR s.content_type = $1)`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(DISTINCT s.user_id) FROM storage_usage s `+where, contentType).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count storage users", err)
+	}
+
+	query := `
+		SELECT s.user_id, COALESCE(u.email, ''), COALESCE(u.display_name, ''),
+			SUM(s.object_count)::bigint, SUM(s.total_bytes)::bigint,
+			jsonb_object_agg(s.content_type, s.total_bytes)
+		FROM storage_usage s
+		LEFT JOIN users u ON u.id::text = s.user_id
+		` + where + `
+		GROUP BY s.user_id, u.email, u.display_name
+		ORDER BY SUM(s.total_bytes) DESC, s.user_id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, contentType, limit, offset)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list storage users", err)
+	}
+	defer rows.Close()
+
+	users := make([]*UserUsage, 0)
+	for rows.Next() {
+		var u UserUsage
+		if err := rows.Scan(&u.UserID, &u.Email, &u.DisplayName, &u.ObjectCount, &u.TotalBytes, &u.BytesByType); err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan storage user", err)
+		}
+		users = append(users, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.InternalWrap("failed to list storage users", err)
+	}
+
+	return users, total, nil
+}
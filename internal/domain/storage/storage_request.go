@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Content types of stored objects, by key prefix
+const (
+	CONTENT_TYPE_VIDEO           = "video"           // videos/{videoID}.{ext}
+	CONTENT_TYPE_VIDEO_THUMBNAIL = "video_thumbnail" // thumbnails/{videoID}.{ext}
+	CONTENT_TYPE_VIDEO_TTS       = "video_tts"       // videos/{videoID}/vocabulary/...
+	CONTENT_TYPE_DIALOG_IMAGE    = "dialog_image"    // dialogs/{dialogID}/bg_image.*
+	CONTENT_TYPE_DIALOG_TTS      = "dialog_tts"      // dialogs/{dialogID}/*.mp3 and its variants
+	CONTENT_TYPE_SPARRING_AUDIO  = "sparring_audio"  // dialogs/{dialogID}/sparring/{actionID}_{n}.mp3
+	CONTENT_TYPE_RECORDING       = "recording"       // retell-story/{attemptID}.m4a
+	CONTENT_TYPE_BUNDLE          = "bundle"          // bundles/{bundleID}.zip
+	CONTENT_TYPE_BATCH_RESULT    = "batch_result"    // batches/...
+	CONTENT_TYPE_STAGED_UPLOAD   = "staged_upload"   // uploads/{batchID}/... waiting for their job
+	CONTENT_TYPE_ARCHIVE         = "archive"         // archive/... (e.g. archived activity events)
+	CONTENT_TYPE_OTHER           = "other"
+)
+
+// ContentTypes are the content types usage is reported for.
+var ContentTypes = []string{
+	CONTENT_TYPE_VIDEO,
+	CONTENT_TYPE_VIDEO_THUMBNAIL,
+	CONTENT_TYPE_VIDEO_TTS,
+	CONTENT_TYPE_DIALOG_IMAGE,
+	CONTENT_TYPE_DIALOG_TTS,
+	CONTENT_TYPE_SPARRING_AUDIO,
+	CONTENT_TYPE_RECORDING,
+	CONTENT_TYPE_BUNDLE,
+	CONTENT_TYPE_BATCH_RESULT,
+	CONTENT_TYPE_STAGED_UPLOAD,
+	CONTENT_TYPE_ARCHIVE,
+	CONTENT_TYPE_OTHER,
+}
+
+// Limits
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ScanUsagePayload is the payload for the storage usage job
+type ScanUsagePayload struct{}
+
+// -------------------------------------------------------------------------
+// Storage Usage Request (admin)
+// -------------------------------------------------------------------------
+
+// UsageRequest is the HTTP request struct for the storage usage report
+type UsageRequest struct {
+	ContentType string
+	Page        int
+	PageSize    int
+}
+
+// UsageInput is the input struct for service
+type UsageInput struct {
+	ContentType string
+	Page        int
+	PageSize    int
+	Limit       int
+	Offset      int
+}
+
+// Parse reads the optional content_type filter of the user ranking and pagination params
+func (req *UsageRequest) Parse(r *http.Request) error {
+	q := r.URL.Query()
+
+	req.ContentType = strings.ToLower(strings.TrimSpace(q.Get("content_type")))
+	if req.ContentType != "" && !slices.Contains(ContentTypes, req.ContentType) {
+		return errors.Validation("content_type must be one of " + strings.Join(ContentTypes, ", "))
+	}
+
+	req.Page, _ = strconv.Atoi(q.Get("page"))
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	req.PageSize = min(req.PageSize, maxPageSize)
+
+	return nil
+}
+
+// ToInput convert UsageRequest to UsageInput
+func (req *UsageRequest) ToInput() UsageInput {
+	return UsageInput{
+		ContentType: req.ContentType,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		Limit:       req.PageSize,
+		Offset:      (req.Page - 1) * req.PageSize,
+	}
+}
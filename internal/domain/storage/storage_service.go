@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ownerResolveBatch is how many ids are resolved to owners per query.
+const ownerResolveBatch = 1000
+
+// imageExtensions are the dialog media stored as images (the rest is audio).
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".webp": true, ".avif": true}
+
+// Kinds of id an object key names its owner by
+const (
+	ownerNone    = ""
+	ownerItem    = "item"
+	ownerAction  = "action"
+	ownerAttempt = "attempt"
+)
+
+// objectRef is what a key says about an object: its content type and the id its owner is found by.
+type objectRef struct {
+	ContentType string
+	OwnerKind   string
+	OwnerID     string
+}
+
+type usageKey struct {
+	ContentType string
+	UserID      string
+}
+
+// StorageUsageResponse is the storage usage report.
+type StorageUsageResponse struct {
+	ScannedAt    *time.Time          `json:"scanned_at"`
+	TotalObjects int64               `json:"total_objects"`
+	TotalBytes   int64               `json:"total_bytes"`
+	ContentTypes []*ContentTypeUsage `json:"content_types"`
+	Users        []*UserUsage        `json:"users"`
+}
+
+// UsageResponse is returned for the storage usage report; users are paginated.
+type UsageResponse struct {
+	Data *StorageUsageResponse    `json:"data"`
+	Meta *response.MetaPagination `json:"meta"`
+}
+
+// StorageService reports which content and users R2 storage goes to.
+type StorageService struct {
+	storageRepo StorageRepository
+	fileRepo    FileRepository
+}
+
+// NewStorageService creates a new storage service.
+func NewStorageService(storageRepo StorageRepository, fileRepo FileRepository) *StorageService {
+	return &StorageService{
+		storageRepo: storageRepo,
+		fileRepo:    fileRepo,
+	}
+}
+
+// GetUsage returns the last scanned usage per content type and a page of users by usage.
+func (s *StorageService) GetUsage(ctx context.Context, input UsageInput) (*UsageResponse, *errors.AppError) {
+	contentTypes, scannedAt, err := s.storageRepo.ListContentTypeUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, total, err := s.storageRepo.ListUserUsage(ctx, input.ContentType, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &StorageUsageResponse{
+		ScannedAt:    scannedAt,
+		ContentTypes: contentTypes,
+		Users:        users,
+	}
+	for _, t := range contentTypes {
+		usage.TotalObjects += t.ObjectCount
+		usage.TotalBytes += t.TotalBytes
+	}
+
+	return &UsageResponse{
+		Data: usage,
+		Meta: &response.MetaPagination{
+			Page:       input.Page,
+			PerPage:    input.PageSize,
+			Total:      total,
+			TotalPages: (total + input.PageSize - 1) / input.PageSize,
+		},
+	}, nil
+}
+
+// Worker: ScanUsage
+// Lists every R2 object, sorts it into a content type by its key prefix, attributes it to the user
+// who owns the video, dialog, sparring session or recording it belongs to and replaces the report.
+func (s *StorageService) ScanUsage(ctx context.Context, payload ScanUsagePayload) *errors.AppError {
+	scannedAt := time.Now()
+
+	counts := make(map[objectRef]*UsageRow)
+	err := s.fileRepo.ListObjects(ctx, func(objects []client.R2Object) error {
+		for _, obj := range objects {
+			ref := classifyKey(obj.Key)
+			row, ok := counts[ref]
+			if !ok {
+				row = &UsageRow{ContentType: ref.ContentType}
+				counts[ref] = row
+			}
+			row.ObjectCount++
+			row.TotalBytes += obj.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string][]string)
+	for ref := range counts {
+		if ref.OwnerKind != ownerNone {
+			ids[ref.OwnerKind] = append(ids[ref.OwnerKind], ref.OwnerID)
+		}
+	}
+	owners := make(map[string]map[string]string, len(ids))
+	for kind, kindIDs := range ids {
+		resolved, err := s.resolveOwners(ctx, kind, kindIDs)
+		if err != nil {
+			return err
+		}
+		owners[kind] = resolved
+	}
+
+	usage := make(map[usageKey]*UsageRow)
+	for ref, count := range counts {
+		key := usageKey{ContentType: ref.ContentType, UserID: owners[ref.OwnerKind][ref.OwnerID]}
+		row, ok := usage[key]
+		if !ok {
+			row = &UsageRow{ContentType: key.ContentType, UserID: key.UserID}
+			usage[key] = row
+		}
+		row.ObjectCount += count.ObjectCount
+		row.TotalBytes += count.TotalBytes
+	}
+
+	rows := make([]*UsageRow, 0, len(usage))
+	for _, row := range usage {
+		rows = append(rows, row)
+	}
+	return s.storageRepo.SaveUsage(ctx, rows, scannedAt)
+}
+
+func (s *StorageService) resolveOwners(ctx context.Context, kind string, ids []string) (map[string]string, *errors.AppError) {
+	owners := make(map[string]string, len(ids))
+	for start := 0; start < len(ids); start += ownerResolveBatch {
+		chunk := ids[start:min(start+ownerResolveBatch, len(ids))]
+
+		var resolved map[string]string
+		var err *errors.AppError
+		switch kind {
+		case ownerItem:
+			resolved, err = s.storageRepo.ResolveItemOwners(ctx, chunk)
+		case ownerAction:
+			resolved, err = s.storageRepo.ResolveActionOwners(ctx, chunk)
+		case ownerAttempt:
+			resolved, err = s.storageRepo.ResolveAttemptOwners(ctx, chunk)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for id, owner := range resolved {
+			owners[id] = owner
+		}
+	}
+	return owners, nil
+}
+
+// classifyKey sorts an object key into its content type and the id its owner is found by.
+func classifyKey(key string) objectRef {
+	parts := strings.Split(key, "/")
+	base := strings.TrimSuffix(parts[len(parts)-1], path.Ext(key))
+
+	switch parts[0] {
+	case "videos":
+		if len(parts) == 2 {
+			return withOwner(CONTENT_TYPE_VIDEO, ownerItem, base)
+		}
+		if len(parts) > 2 && parts[2] == "vocabulary" {
+			return withOwner(CONTENT_TYPE_VIDEO_TTS, ownerItem, parts[1])
+		}
+	case "thumbnails":
+		if len(parts) == 2 {
+			return withOwner(CONTENT_TYPE_VIDEO_THUMBNAIL, ownerItem, base)
+		}
+	case "dialogs":
+		if len(parts) == 4 && parts[2] == "sparring" {
+			// The session is named by its action id, before the message count
+			actionID, _, _ := strings.Cut(base, "_")
+			return withOwner(CONTENT_TYPE_SPARRING_AUDIO, ownerAction, actionID)
+		}
+		if len(parts) == 3 {
+			if imageExtensions[strings.ToLower(path.Ext(key))] {
+				return withOwner(CONTENT_TYPE_DIALOG_IMAGE, ownerItem, parts[1])
+			}
+			return withOwner(CONTENT_TYPE_DIALOG_TTS, ownerItem, parts[1])
+		}
+	case "retell-story":
+		if len(parts) == 2 {
+			return withOwner(CONTENT_TYPE_RECORDING, ownerAttempt, base)
+		}
+	case "bundles":
+		return objectRef{ContentType: CONTENT_TYPE_BUNDLE}
+	case "batches":
+		return objectRef{ContentType: CONTENT_TYPE_BATCH_RESULT}
+	case "uploads":
+		return objectRef{ContentType: CONTENT_TYPE_STAGED_UPLOAD}
+	case "archive":
+		return objectRef{ContentType: CONTENT_TYPE_ARCHIVE}
+	}
+	return objectRef{ContentType: CONTENT_TYPE_OTHER}
+}
+
+// withOwner keeps the owner id only when it is a valid id, so the owner lookups never fail on a stray key.
+func withOwner(contentType, kind, id string) objectRef {
+	if _, err := uuid.Parse(id); err != nil {
+		return objectRef{ContentType: contentType}
+	}
+	return objectRef{ContentType: contentType, OwnerKind: kind, OwnerID: id}
+}
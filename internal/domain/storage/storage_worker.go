@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+)
+
+// Worker names
+const (
+	WORKER_SCAN_USAGE = "worker_scan_storage_usage"
+)
+
+// RegisterStorageWorkers register storage workers to queue
+func RegisterStorageWorkers(queue *client.QueueClient, service *StorageService) {
+
+	// Job Scan Usage
+	queue.RegisterWorker(WORKER_SCAN_USAGE, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(ScanUsagePayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_SCAN_USAGE)
+		}
+		if err := service.ScanUsage(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
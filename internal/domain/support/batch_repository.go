@@ -0,0 +1,78 @@
+package support
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BatchRepository reads processing batches written by the video and dialog domains.
+type BatchRepository interface {
+	GetBatches(ctx context.Context, batchIDs []string) (map[string]*response.MetaProcessing, *errors.AppError)
+}
+
+type batchRepository struct {
+	redis   *client.RedisClient
+	store   *client.BatchStore
+	archive *client.BatchArchive
+}
+
+// NewBatchRepository creates a new read-only batch repository.
+func NewBatchRepository(redis *client.RedisClient, store *client.BatchStore, archive *client.BatchArchive) BatchRepository {
+	return &batchRepository{redis: redis, store: store, archive: archive}
+}
+
+// GetBatches returns the batches with their jobs by id: from Redis in one round trip, then the expired
+// ones from Postgres (the batch store, then the archive of batches older than it). Batches found
+// nowhere are left out.
+func (r *batchRepository) GetBatches(ctx context.Context, batchIDs []string) (map[string]*response.MetaProcessing, *errors.AppError) {
+	batches := make(map[string]*response.MetaProcessing, len(batchIDs))
+	if len(batchIDs) == 0 {
+		return batches, nil
+	}
+
+	keys := make([]string, 0, 2*len(batchIDs))
+	for _, batchID := range batchIDs {
+		keys = append(keys, fmt.Sprintf("batch:%s", batchID), fmt.Sprintf("batch:%s:jobs", batchID))
+	}
+	hashes, err := r.redis.HGetAllMany(ctx, keys...)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get batches", err)
+	}
+
+	expired := make([]string, 0)
+	for i, batchID := range batchIDs {
+		batchFields, jobFields := hashes[2*i], hashes[2*i+1]
+		if len(batchFields) == 0 {
+			expired = append(expired, batchID)
+			continue
+		}
+		batches[batchID] = client.BatchFromCache(batchID, batchFields, jobFields, nil)
+	}
+
+	stored, err := r.store.GetMany(ctx, expired)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get stored batches", err)
+	}
+	remaining := make([]string, 0, len(expired))
+	for _, batchID := range expired {
+		if batch, ok := stored[batchID]; ok {
+			batches[batchID] = batch
+			continue
+		}
+		remaining = append(remaining, batchID)
+	}
+
+	archived, err := r.archive.GetMany(ctx, remaining)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to get archived batches", err)
+	}
+	for batchID, batch := range archived {
+		batches[batchID] = batch
+	}
+
+	return batches, nil
+}
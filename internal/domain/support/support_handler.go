@@ -0,0 +1,87 @@
+package support
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// SupportHandler handles admin support HTTP endpoints.
+type SupportHandler struct {
+	service *SupportService
+}
+
+// NewSupportHandler creates a new support handler.
+func NewSupportHandler(service *SupportService) *SupportHandler {
+	return &SupportHandler{
+		service: service,
+	}
+}
+
+// Impersonate handles POST /api/v1/admin/users/{userID}/impersonate.
+func (h *SupportHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	var req ImpersonateRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.Impersonate(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, result)
+}
+
+// ListSessions handles GET /api/v1/admin/users/{userID}/sessions.
+func (h *SupportHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	var req UserLookupRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListSessions(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ListBatches handles GET /api/v1/admin/users/{userID}/batches?reference_type=video.
+func (h *SupportHandler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	var req ListBatchesRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListBatches(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ListErrors handles GET /api/v1/admin/users/{userID}/errors.
+func (h *SupportHandler) ListErrors(w http.ResponseWriter, r *http.Request) {
+	var req UserLookupRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListErrors(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
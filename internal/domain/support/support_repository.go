@@ -0,0 +1,229 @@
+package support
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Audit actions
+const (
+	AUDIT_IMPERSONATE   = "impersonate"
+	AUDIT_VIEW_SESSIONS = "view_sessions"
+	AUDIT_VIEW_BATCHES  = "view_batches"
+	AUDIT_VIEW_ERRORS   = "view_errors"
+)
+
+// Batch kinds
+const (
+	BATCH_KIND_VIDEO  = "video"
+	BATCH_KIND_DIALOG = "dialog"
+	BATCH_KIND_RETELL = "retell"
+)
+
+// AuditEntry is a row of the admin_audit_log table.
+type AuditEntry struct {
+	Admin        string
+	Action       string
+	TargetUserID string
+	Reason       string
+	Metadata     map[string]interface{}
+}
+
+// Session is one of the user's learning sessions (a user action on a learning item).
+type Session struct {
+	ID         string     `json:"id"`
+	LearningID string     `json:"learning_id"`
+	FeatureID  int        `json:"feature_id"`
+	Title      string     `json:"title"`
+	ActionType string     `json:"action_type"`
+	Status     string     `json:"status,omitempty"`
+	Attempts   int        `json:"attempts"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+// BatchRef points at a processing batch that belongs to the user.
+type BatchRef struct {
+	ID         string
+	Kind       string
+	LearningID string
+	CreatedAt  *time.Time
+}
+
+// ActionError is a failure recorded in a user action's metadata.
+type ActionError struct {
+	Source     string     `json:"source"`
+	LearningID string     `json:"learning_id"`
+	Reference  string     `json:"reference,omitempty"`
+	Message    string     `json:"message"`
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+// SupportRepository reads user data for support and records the audit trail.
+type SupportRepository interface {
+	InsertAudit(ctx context.Context, entry AuditEntry) *errors.AppError
+	ListSessions(ctx context.Context, userID string, limit int) ([]*Session, *errors.AppError)
+	ListBatchRefs(ctx context.Context, userID string, limit int) ([]*BatchRef, *errors.AppError)
+	ListActionErrors(ctx context.Context, userID string, limit int) ([]*ActionError, *errors.AppError)
+}
+
+type supportRepository struct {
+	db *client.PostgresClient
+}
+
+// NewSupportRepository creates a new support repository.
+func NewSupportRepository(db *client.PostgresClient) SupportRepository {
+	return &supportRepository{db: db}
+}
+
+func (r *supportRepository) InsertAudit(ctx context.Context, entry AuditEntry) *errors.AppError {
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.InternalWrap("failed to marshal audit metadata", err)
+	}
+
+	var reason *string
+	if entry.Reason != "" {
+		reason = &entry.Reason
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO admin_audit_log (admin, action, target_user_id, reason, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+	`, entry.Admin, entry.Action, entry.TargetUserID, reason, metadataJSON); err != nil {
+		return errors.InternalWrap("failed to write audit log", err)
+	}
+	return nil
+}
+
+// ListSessions returns the user's most recently updated actions.
+func (r *supportRepository) ListSessions(ctx context.Context, userID string, limit int) ([]*Session, *errors.AppError) {
+	query := `
+		SELECT ua.id::text, l.id::text, COALESCE(l.feature_id, 0),
+			COALESCE(NULLIF(l.details->>'topic', ''), l.content),
+			ua.action_type::text, COALESCE(ua.metadata->>'status', ''),
+			CASE WHEN jsonb_typeof(ua.metadata->'attempts') = 'array' THEN jsonb_array_length(ua.metadata->'attempts') ELSE 0 END,
+			ua.created_at, ua.updated_at
+		FROM user_actions ua
+		JOIN learning_items l ON l.id = ua.learning_id
+		WHERE ua.user_id = $1 AND ua.deleted_at IS NULL
+		ORDER BY ua.updated_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list sessions", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*Session, 0)
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.LearningID, &s.FeatureID, &s.Title, &s.ActionType, &s.Status,
+			&s.Attempts, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan session", err)
+		}
+		sessions = append(sessions, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list sessions", err)
+	}
+	return sessions, nil
+}
+
+// ListBatchRefs returns the batch IDs the user started: videos and dialogs they created
+// (batch ID = item ID) and retell evaluations (batch ID = attempt ID), newest first.
+func (r *supportRepository) ListBatchRefs(ctx context.Context, userID string, limit int) ([]*BatchRef, *errors.AppError) {
+	query := `
+		SELECT id, kind, learning_id, created_at FROM (
+			SELECT l.id::text AS id,
+				CASE WHEN l.feature_id = 1 THEN 'video' ELSE 'dialog' END AS kind,
+				l.id::text AS learning_id, l.created_at
+			FROM learning_items l
+			WHERE l.created_by = $1
+			UNION ALL
+			SELECT attempt->>'attempt_id', 'retell', ua.learning_id::text,
+				COALESCE((attempt->>'submitted_at')::timestamptz, ua.updated_at)
+			FROM user_actions ua
+			CROSS JOIN LATERAL jsonb_array_elements(
+				CASE WHEN jsonb_typeof(ua.metadata->'attempts') = 'array' THEN ua.metadata->'attempts' ELSE '[]'::jsonb END
+			) attempt
+			WHERE ua.user_id = $2 AND ua.action_type = 'submit_retell' AND ua.deleted_at IS NULL
+				AND attempt->>'attempt_id' IS NOT NULL
+		) refs
+		ORDER BY created_at DESC NULLS LAST
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, userID, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list batches", err)
+	}
+	defer rows.Close()
+
+	refs := make([]*BatchRef, 0)
+	for rows.Next() {
+		var ref BatchRef
+		if err := rows.Scan(&ref.ID, &ref.Kind, &ref.LearningID, &ref.CreatedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan batch", err)
+		}
+		refs = append(refs, &ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list batches", err)
+	}
+	return refs, nil
+}
+
+// ListActionErrors returns failed chat replies and failed retell evaluations, newest first.
+func (r *supportRepository) ListActionErrors(ctx context.Context, userID string, limit int) ([]*ActionError, *errors.AppError) {
+	query := `
+		SELECT source, learning_id, reference, message, occurred_at FROM (
+			SELECT 'chat' AS source, ua.learning_id::text AS learning_id, ua.id::text AS reference,
+				'chat reply failed' AS message, ua.updated_at AS occurred_at
+			FROM user_actions ua
+			WHERE ua.user_id = $1 AND ua.action_type = 'submit_chat' AND ua.deleted_at IS NULL
+				AND ua.metadata @> '{"status": "failed"}'
+			UNION ALL
+			SELECT 'retell', ua.learning_id::text, attempt->>'attempt_id',
+				'retell evaluation failed', COALESCE((attempt->>'submitted_at')::timestamptz, ua.updated_at)
+			FROM user_actions ua
+			CROSS JOIN LATERAL jsonb_array_elements(
+				CASE WHEN jsonb_typeof(ua.metadata->'attempts') = 'array' THEN ua.metadata->'attempts' ELSE '[]'::jsonb END
+			) attempt
+			WHERE ua.user_id = $1 AND ua.action_type = 'submit_retell' AND ua.deleted_at IS NULL
+				AND ua.metadata @> '{"attempts": [{"status": "evaluation_failed"}]}'
+				AND attempt->>'status' = 'evaluation_failed'
+		) failures
+		ORDER BY occurred_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list errors", err)
+	}
+	defer rows.Close()
+
+	failures := make([]*ActionError, 0)
+	for rows.Next() {
+		var e ActionError
+		if err := rows.Scan(&e.Source, &e.LearningID, &e.Reference, &e.Message, &e.OccurredAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan error", err)
+		}
+		failures = append(failures, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list errors", err)
+	}
+	return failures, nil
+}
@@ -0,0 +1,141 @@
+package support
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxReasonLength bounds the audit reason for an impersonation.
+const maxReasonLength = 500
+
+// UserLookupRequest is the HTTP request struct for the read-only support views
+type UserLookupRequest struct {
+	Admin  string
+	UserID string
+}
+
+// UserLookupInput is the input struct for service
+type UserLookupInput struct {
+	Admin  string
+	UserID string
+}
+
+func (req *UserLookupRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Admin name from basic auth (checked by the admin middleware)
+	admin, _, ok := r.BasicAuth()
+	if !ok || admin == "" {
+		return errors.Unauthorized("admin not authenticated")
+	}
+	req.Admin = admin
+
+	// 2. Parse URL Params
+	req.UserID = chi.URLParam(r, "userID")
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		return errors.Validation("user ID must be a UUID")
+	}
+
+	return nil
+}
+
+func (req *UserLookupRequest) ToInput() UserLookupInput {
+	return UserLookupInput{Admin: req.Admin, UserID: req.UserID}
+}
+
+// Batch reference types accepted by the batch list filter
+var batchReferenceTypes = map[string]bool{
+	"video":          true,
+	"retell_attempt": true,
+	"dialog":         true,
+}
+
+// ListBatchesRequest is the HTTP request struct for listing a user's batches
+type ListBatchesRequest struct {
+	UserLookupRequest
+	ReferenceType string
+}
+
+// ListBatchesInput is the input struct for service
+type ListBatchesInput struct {
+	UserLookupInput
+	ReferenceType string // empty lists every batch
+}
+
+func (req *ListBatchesRequest) ParseAndValidate(r *http.Request) error {
+	if err := req.UserLookupRequest.ParseAndValidate(r); err != nil {
+		return err
+	}
+
+	req.ReferenceType = strings.TrimSpace(r.URL.Query().Get("reference_type"))
+	if req.ReferenceType != "" && !batchReferenceTypes[req.ReferenceType] {
+		return errors.Validation("reference_type must be one of video, retell_attempt, dialog")
+	}
+
+	return nil
+}
+
+func (req *ListBatchesRequest) ToInput() ListBatchesInput {
+	return ListBatchesInput{
+		UserLookupInput: req.UserLookupRequest.ToInput(),
+		ReferenceType:   req.ReferenceType,
+	}
+}
+
+// ImpersonateRequest is the HTTP request struct for issuing an impersonation token
+type ImpersonateRequest struct {
+	UserLookupRequest
+	Reason     string
+	TTLMinutes int
+}
+
+// ImpersonateInput is the input struct for service
+type ImpersonateInput struct {
+	Admin  string
+	UserID string
+	Reason string
+	TTL    time.Duration // 0 uses the default
+}
+
+func (req *ImpersonateRequest) ParseAndValidate(r *http.Request) error {
+	if err := req.UserLookupRequest.ParseAndValidate(r); err != nil {
+		return err
+	}
+
+	// Parse JSON Body (kept apart so the body cannot set the admin or user)
+	var body struct {
+		Reason     string `json:"reason"`
+		TTLMinutes int    `json:"ttl_minutes"`
+	}
+	if err := middleware.DecodeJSON(r, &body); err != nil && err != io.EOF {
+		return errors.Validation("invalid JSON body")
+	}
+	req.Reason = strings.TrimSpace(body.Reason)
+	req.TTLMinutes = body.TTLMinutes
+
+	if req.Reason == "" {
+		return errors.Validation("reason is required")
+	}
+	if len([]rune(req.Reason)) > maxReasonLength {
+		return errors.Validation("reason must be at most 500 characters")
+	}
+	if req.TTLMinutes < 0 {
+		return errors.Validation("ttl_minutes must be positive")
+	}
+
+	return nil
+}
+
+func (req *ImpersonateRequest) ToInput() ImpersonateInput {
+	return ImpersonateInput{
+		Admin:  req.Admin,
+		UserID: req.UserID,
+		Reason: req.Reason,
+		TTL:    time.Duration(req.TTLMinutes) * time.Minute,
+	}
+}
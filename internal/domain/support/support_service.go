@@ -0,0 +1,206 @@
+package support
+
+import (
+	"context"
+	"time"
+
+	"github.com/windfall/uwu_service/internal/domain/auth"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// List sizes of the support views
+const (
+	sessionsLimit = 50
+	batchesLimit  = 20
+	errorsLimit   = 50
+)
+
+// SupportOptions configures support tooling.
+type SupportOptions struct {
+	ImpersonationTTL    time.Duration // default token lifetime
+	ImpersonationMaxTTL time.Duration // longest lifetime an admin may request
+}
+
+// ImpersonationResponse is an issued impersonation token.
+type ImpersonationResponse struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ReadOnly  bool      `json:"read_only"`
+}
+
+// UserBatch is a processing batch with what it was for.
+type UserBatch struct {
+	Kind       string                   `json:"kind"`
+	LearningID string                   `json:"learning_id"`
+	Batch      *response.MetaProcessing `json:"batch"`
+}
+
+// UserErrorsResponse lists the user's recent failures.
+type UserErrorsResponse struct {
+	Actions []*ActionError `json:"actions"`
+	Batches []*UserBatch   `json:"batches"`
+}
+
+// SupportService powers the admin support tooling. Every call is audited.
+type SupportService struct {
+	supportRepo SupportRepository
+	batchRepo   BatchRepository
+	authRepo    auth.AuthRepository
+	opts        SupportOptions
+}
+
+// NewSupportService creates a new support service.
+func NewSupportService(supportRepo SupportRepository, batchRepo BatchRepository, authRepo auth.AuthRepository, opts SupportOptions) *SupportService {
+	return &SupportService{
+		supportRepo: supportRepo,
+		batchRepo:   batchRepo,
+		authRepo:    authRepo,
+		opts:        opts,
+	}
+}
+
+// Impersonate issues a read-only token that acts as the user for a limited time.
+func (s *SupportService) Impersonate(ctx context.Context, input ImpersonateInput) (*ImpersonationResponse, *errors.AppError) {
+	user, err := s.authRepo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.opts.ImpersonationTTL
+	if input.TTL > 0 {
+		ttl = input.TTL
+	}
+	if s.opts.ImpersonationMaxTTL > 0 && ttl > s.opts.ImpersonationMaxTTL {
+		return nil, errors.Validation("ttl_minutes exceeds the maximum impersonation lifetime")
+	}
+
+	token, expiresAt, err := s.authRepo.GenerateImpersonationToken(user, input.Admin, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is only handed out once the audit entry is stored
+	if err := s.supportRepo.InsertAudit(ctx, AuditEntry{
+		Admin:        input.Admin,
+		Action:       AUDIT_IMPERSONATE,
+		TargetUserID: input.UserID,
+		Reason:       input.Reason,
+		Metadata:     map[string]interface{}{"expires_at": expiresAt.UTC()},
+	}); err != nil {
+		return nil, err
+	}
+
+	return &ImpersonationResponse{
+		Token:     token,
+		UserID:    input.UserID,
+		ExpiresAt: expiresAt.UTC(),
+		ReadOnly:  true,
+	}, nil
+}
+
+// ListSessions returns the user's recent learning sessions.
+func (s *SupportService) ListSessions(ctx context.Context, input UserLookupInput) ([]*Session, *errors.AppError) {
+	if err := s.audit(ctx, input, AUDIT_VIEW_SESSIONS); err != nil {
+		return nil, err
+	}
+	return s.supportRepo.ListSessions(ctx, input.UserID, sessionsLimit)
+}
+
+// ListBatches returns the user's recent batches, from Redis or the batch archive,
+// optionally only those of one reference type.
+func (s *SupportService) ListBatches(ctx context.Context, input ListBatchesInput) ([]*UserBatch, *errors.AppError) {
+	if err := s.audit(ctx, input.UserLookupInput, AUDIT_VIEW_BATCHES); err != nil {
+		return nil, err
+	}
+
+	batches, err := s.userBatches(ctx, input.UserID)
+	if err != nil || input.ReferenceType == "" {
+		return batches, err
+	}
+
+	filtered := make([]*UserBatch, 0, len(batches))
+	for _, b := range batches {
+		if b.Batch.ReferenceType == input.ReferenceType {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered, nil
+}
+
+// ListErrors returns failed actions and failed batches of the user.
+func (s *SupportService) ListErrors(ctx context.Context, input UserLookupInput) (*UserErrorsResponse, *errors.AppError) {
+	if err := s.audit(ctx, input, AUDIT_VIEW_ERRORS); err != nil {
+		return nil, err
+	}
+
+	actions, err := s.supportRepo.ListActionErrors(ctx, input.UserID, errorsLimit)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := s.userBatches(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make([]*UserBatch, 0)
+	for _, b := range batches {
+		if b.Batch.Status == "failed" {
+			failed = append(failed, b)
+		}
+	}
+
+	return &UserErrorsResponse{Actions: actions, Batches: failed}, nil
+}
+
+// userBatches loads the batches of the user's recent items; batches that were never archived are skipped.
+func (s *SupportService) userBatches(ctx context.Context, userID string) ([]*UserBatch, *errors.AppError) {
+	refs, err := s.supportRepo.ListBatchRefs(ctx, userID, batchesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+	found, err := s.batchRepo.GetBatches(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]*UserBatch, 0, len(refs))
+	for _, ref := range refs {
+		batch, ok := found[ref.ID]
+		if !ok {
+			continue
+		}
+		// Batches created before references were recorded
+		if batch.ReferenceType == "" {
+			batch.ReferenceType, batch.ReferenceID = referenceForKind(ref.Kind), ref.ID
+		}
+		batches = append(batches, &UserBatch{Kind: ref.Kind, LearningID: ref.LearningID, Batch: batch})
+	}
+	return batches, nil
+}
+
+// audit records the lookup; unknown users are not found.
+func (s *SupportService) audit(ctx context.Context, input UserLookupInput, action string) *errors.AppError {
+	if _, err := s.authRepo.GetByID(ctx, input.UserID); err != nil {
+		return err
+	}
+	return s.supportRepo.InsertAudit(ctx, AuditEntry{
+		Admin:        input.Admin,
+		Action:       action,
+		TargetUserID: input.UserID,
+	})
+}
+
+// referenceForKind maps a batch ref kind to its batch reference type.
+func referenceForKind(kind string) string {
+	if kind == "retell" {
+		return "retell_attempt"
+	}
+	return kind
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
@@ -156,6 +160,117 @@ Respond strictly in the following JSON format, with no markdown formatting or ex
   "analysis": "<string>"
 }`
 
+const extractVocabularySystemPrompt = `Role
+You are an expert language teacher. Your task is to pick the most useful vocabulary and key phrases for a learner from a video transcript.
+
+# Instructions
+1. vocabulary:
+- Extract 5-10 words that are important for understanding the transcript and suitable for the given level.
+- Skip names, numbers and very basic words.
+
+2. key_phrases:
+- Extract 3-5 reusable phrases or sentence structures (collocations, idioms, patterns).
+
+For every item:
+- "meaning": a short, simple explanation in the transcript language.
+- "example": the sentence from the transcript where it appears (verbatim).
+- "reading": {"ipa": "...", "standard": "..."}.
+  - "ipa": the broad IPA transcription of "text" without slashes; mark syllable breaks with "." or stress marks, and tones with tone letters (e.g. ˨˩˦) for Chinese.
+  - "standard": for Chinese, Japanese or Korean only, the pinyin (tone marks), Hepburn romaji or Revised Romanization of "text"; omit it for other languages.
+
+# Output Format (STRICT JSON)
+- Output ONLY valid JSON
+- Do NOT include markdown, comments, or extra text
+
+{
+  "vocabulary": [
+    { "text": "string", "meaning": "string", "example": "string", "reading": { "ipa": "string", "standard": "string" } }
+  ],
+  "key_phrases": [
+    { "text": "string", "meaning": "string", "example": "string", "reading": { "ipa": "string", "standard": "string" } }
+  ]
+}`
+
+const generateReadingsSystemPrompt = `Role
+You are an expert phonetician. Your task is to write the IPA transcription of words and phrases for language learners.
+
+# Instructions
+- Write a broad IPA transcription of every item in the given language, without slashes or brackets.
+- Use IPA letters only: no spelling, romanization, digits or capital letters.
+- Mark syllable breaks with "." or stress marks (ˈ ˌ).
+- For Chinese, mark the tone of every syllable with tone letters (e.g. ˥ ˧˥ ˨˩˦ ˥˩), not accents.
+- Keep the items exactly as given.
+
+# Output Format (STRICT JSON)
+- Output ONLY valid JSON
+- Do NOT include markdown, comments, or extra text
+
+{
+  "readings": [
+    { "text": "string", "ipa": "string" }
+  ]
+}`
+
+const expandVocabularySystemPrompt = `Role
+You are an expert language teacher. Your task is to give a learner more material for one word or phrase from a video lesson.
+
+# Instructions
+1. examples:
+- Write exactly the requested number of new example sentences that use the item in the given meaning.
+- Write them in the item's language and at the given level; keep them short and natural.
+- Each sentence must differ from the existing examples.
+
+2. collocations:
+- List exactly the requested number of common collocations or fixed expressions containing the item.
+- "meaning": a short, simple explanation in the item's language.
+- Skip collocations that are already listed.
+
+# Output Format (STRICT JSON)
+- Output ONLY valid JSON
+- Do NOT include markdown, comments, or extra text
+
+{
+  "examples": ["string"],
+  "collocations": [
+    { "text": "string", "meaning": "string" }
+  ]
+}`
+
+const generateChaptersSystemPrompt = `Role
+You are an expert video editor. Your task is to split a timestamped transcript into chapters for the player's chapter navigation.
+
+# Instructions
+- Group consecutive segments by topic into 3-10 chapters.
+- Chapters must be in order, must not overlap, and must cover the whole transcript.
+- "start" is the start time (seconds) of the first segment in the chapter, taken from the transcript.
+- "title" is a short title (max 6 words) in the transcript language.
+
+# Output Format (STRICT JSON)
+- Output ONLY valid JSON
+- Do NOT include markdown, comments, or extra text
+
+{
+  "chapters": [
+    { "title": "string", "start": 0.0 }
+  ]
+}`
+
+const summarizeChunkSystemPrompt = `Role
+You are an expert transcript analyst. You receive one part of a long video transcript; the parts are summarized separately and merged later.
+
+# Instructions
+- Write the notes in the transcript language.
+- List the key events and ideas of this part in order, as short factual sentences.
+- Copy 2-4 representative sentences verbatim (they are used for quizzes and to judge the language level).
+- Keep names, numbers and specific facts exactly as stated.
+- Do NOT add anything that is not in this part.
+
+# Output Format
+Plain text only, at most 200 words.`
+
+// chunkConcurrency caps the parallel chunk summaries of one transcript.
+const chunkConcurrency = 4
+
 // Whisper language code map
 var transcriptLanguageMap = map[string]string{
 	"english":    "en",
@@ -171,8 +286,13 @@ var transcriptLanguageMap = map[string]string{
 // AIRepository interface
 type AIRepository interface {
 	GenerateVideoTranscript(ctx context.Context, audioPath, language string) (*client.WhisperResponse, *errors.AppError)
+	GenerateCheckedVideoTranscript(ctx context.Context, audioPath, language string) (*client.WhisperResponse, *TranscriptQuality, *errors.AppError)
 	GenerateVideoDetails(ctx context.Context, transcript *client.WhisperResponse) (*VideoDetails, *errors.AppError)
 	EvaluateRetellStory(ctx context.Context, transcript string, keyPoints []string) (*RetellEvaluation, *errors.AppError)
+	ExtractVocabulary(ctx context.Context, transcript, level string) (*VideoVocabulary, *errors.AppError)
+	GenerateReadingIPA(ctx context.Context, language string, texts []string) (map[string]string, *errors.AppError)
+	ExpandVocabulary(ctx context.Context, item VocabularyItem, language, level string, examples, collocations int) (*VocabularyExpansion, *errors.AppError)
+	GenerateChapters(ctx context.Context, segments []TranscriptSegment) ([]VideoChapter, *errors.AppError)
 }
 
 type TranscriptSegment struct {
@@ -181,22 +301,112 @@ type TranscriptSegment struct {
 	Duration float64 `json:"duration"`
 }
 
+// VocabularyItem is a word or phrase worth studying from the video
+type VocabularyItem struct {
+	Text    string `json:"text"`
+	Meaning string `json:"meaning"`
+	Example string `json:"example"`
+	// IPA and, for Chinese, Japanese and Korean, romanized reading (see checkReadings)
+	Reading *VocabularyReading `json:"reading,omitempty"`
+	// Rank in the language's frequency list (1 is the most common), 0 when not listed
+	FrequencyRank int `json:"frequency_rank,omitempty"`
+	// Added by POST /learning-items/{itemID}/expand, oldest first
+	Examples     []VocabularyExample     `json:"examples,omitempty"`
+	Collocations []VocabularyCollocation `json:"collocations,omitempty"`
+}
+
+// VOCABULARY_SOURCE_EXPAND marks material generated by the expand endpoint
+const VOCABULARY_SOURCE_EXPAND = "ai_expand"
+
+// Provenance records where added vocabulary material came from
+type Provenance struct {
+	Source    string    `json:"source"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VocabularyExample is an extra example sentence of a vocabulary item
+type VocabularyExample struct {
+	Text     string `json:"text"`
+	AudioURL string `json:"audio_url,omitempty"`
+	Provenance
+}
+
+// VocabularyCollocation is a common collocation of a vocabulary item
+type VocabularyCollocation struct {
+	Text    string `json:"text"`
+	Meaning string `json:"meaning"`
+	Provenance
+}
+
+// VocabularyExpansion is the AI output of an expand request
+type VocabularyExpansion struct {
+	Examples     []string `json:"examples"`
+	Collocations []struct {
+		Text    string `json:"text"`
+		Meaning string `json:"meaning"`
+	} `json:"collocations"`
+}
+
+// VocabularyReading is the reading of a vocabulary item
+type VocabularyReading struct {
+	IPA      string `json:"ipa,omitempty"`
+	Standard string `json:"standard,omitempty"`
+}
+
+// VideoVocabulary holds the vocabulary and key phrases extracted from a transcript
+type VideoVocabulary struct {
+	Vocabulary []VocabularyItem `json:"vocabulary"`
+	KeyPhrases []VocabularyItem `json:"key_phrases"`
+}
+
+// VideoChapter is a titled section of the video
+type VideoChapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"` // seconds
+	End   float64 `json:"end"`   // seconds
+}
+
 type RetellEvaluation struct {
 	Score            float64  `json:"score"`
 	MatchesKeyPoints []string `json:"matches_key_points"`
 	Analysis         string   `json:"analysis"`
 }
 
+// TranscriptQuality describes how much a Whisper transcript can be trusted
+type TranscriptQuality struct {
+	Confidence        float64 `json:"confidence"`
+	RequestedLanguage string  `json:"requested_language"`
+	DetectedLanguage  string  `json:"detected_language,omitempty"`
+	LanguageMismatch  bool    `json:"language_mismatch"`
+	LowConfidence     bool    `json:"low_confidence"`
+	AutoDetectRetried bool    `json:"auto_detect_retried"`
+}
+
+// Flagged reports whether the transcript needs review before the video goes live
+func (q *TranscriptQuality) Flagged() bool {
+	return q.LanguageMismatch || q.LowConfidence
+}
+
+// TranscriptionOptions configures the transcript quality checks
+type TranscriptionOptions struct {
+	MinConfidence   float64 // below this the transcript is flagged as low confidence
+	RetryAutoDetect bool    // re-run Whisper with language auto-detect on low confidence
+	ChunkTokens     int     // longer transcripts are summarized per chunk before analysis (0 disables)
+}
+
 // aiRepository is the implementation of the AIRepository interface
 type aiRepository struct {
-	chatGPT *client.AzureChatGPTClient
-	whisper *client.AzureWhisperClient
+	chatGPT client.ChatClient
+	whisper client.TranscriptionClient
+	opts    TranscriptionOptions
+	prompts *client.PromptBudget
 	log     *slog.Logger
 }
 
 // NewAIRepository creates a new aiRepository
-func NewAIRepository(whisper *client.AzureWhisperClient, chatGPT *client.AzureChatGPTClient, log *slog.Logger) *aiRepository {
-	return &aiRepository{chatGPT: chatGPT, whisper: whisper, log: log}
+func NewAIRepository(whisper client.TranscriptionClient, chatGPT client.ChatClient, opts TranscriptionOptions, prompts *client.PromptBudget, log *slog.Logger) *aiRepository {
+	return &aiRepository{chatGPT: chatGPT, whisper: whisper, opts: opts, prompts: prompts, log: log}
 }
 
 // GenerateVideoTranscript generates video transcript
@@ -215,6 +425,65 @@ func (r *aiRepository) GenerateVideoTranscript(ctx context.Context, audioPath, l
 	return transcript, nil
 }
 
+// GenerateCheckedVideoTranscript transcribes in the requested language and scores the result.
+// On low confidence it can re-run with auto-detect to find out whether the audio is in another language.
+func (r *aiRepository) GenerateCheckedVideoTranscript(ctx context.Context, audioPath, language string) (*client.WhisperResponse, *TranscriptQuality, *errors.AppError) {
+	transcript, err := r.GenerateVideoTranscript(ctx, audioPath, language)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quality := &TranscriptQuality{
+		Confidence:        transcriptConfidence(transcript),
+		RequestedLanguage: language,
+	}
+	quality.LowConfidence = quality.Confidence < r.opts.MinConfidence
+
+	if quality.LowConfidence && r.opts.RetryAutoDetect {
+		quality.AutoDetectRetried = true
+
+		detected, err := r.whisper.TranscribeFile(ctx, audioPath, "")
+		if err != nil {
+			r.log.Warn("Whisper auto-detect retry failed", "error", err.Error())
+			return transcript, quality, nil
+		}
+
+		quality.DetectedLanguage = strings.ToLower(detected.Language)
+		quality.LanguageMismatch = !sameTranscriptLanguage(quality.DetectedLanguage, language)
+
+		// Keep the auto-detected transcript only when it agrees on language and scores better
+		if confidence := transcriptConfidence(detected); !quality.LanguageMismatch && confidence > quality.Confidence {
+			transcript = detected
+			quality.Confidence = confidence
+			quality.LowConfidence = confidence < r.opts.MinConfidence
+		}
+	}
+
+	return transcript, quality, nil
+}
+
+// transcriptConfidence returns the duration-weighted average token probability of the segments (0-1).
+func transcriptConfidence(transcript *client.WhisperResponse) float64 {
+	var weighted, total float64
+	for _, seg := range transcript.Segments {
+		duration := seg.End - seg.Start
+		if duration <= 0 {
+			continue
+		}
+		weighted += math.Exp(seg.AvgLogprob) * (1 - seg.NoSpeechProb) * duration
+		total += duration
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// sameTranscriptLanguage compares Whisper's detected language (name or code) with a requested language name.
+func sameTranscriptLanguage(detected, requested string) bool {
+	return detected == requested || detected == transcriptLanguageMap[requested]
+}
+
 // GenerateVideoDetails generates video details
 func (r *aiRepository) GenerateVideoDetails(ctx context.Context, transcript *client.WhisperResponse) (*VideoDetails, *errors.AppError) {
 	// Convert transcript segments
@@ -240,9 +509,21 @@ func (r *aiRepository) GenerateVideoDetails(ctx context.Context, transcript *cli
 
 	// Build LLM prompt
 	detectedLanguage := transcript.Language
-	userMessage := fmt.Sprintf("Transcript:\n\"\"\"\n%s\n\"\"\"\n\nLanguage: %s", transcriptText, detectedLanguage)
+	userMessage := ""
+	if r.opts.ChunkTokens > 0 && client.EstimateTokens(transcriptText) > r.opts.ChunkTokens {
+		// Long transcripts: summarize each chunk, then analyze the merged notes
+		notes, err := r.condenseTranscript(ctx, segments)
+		if err != nil {
+			return nil, err
+		}
+		notes = r.prompts.Fit("video_details.notes", notes, videoDetailsSystemPrompt)
+		userMessage = fmt.Sprintf("Transcript (condensed: notes for each part of a long video, in order):\n\"\"\"\n%s\n\"\"\"\n\nLanguage: %s", notes, detectedLanguage)
+	} else {
+		promptText := r.prompts.Fit("video_details.transcript", transcriptText, videoDetailsSystemPrompt)
+		userMessage = fmt.Sprintf("Transcript:\n\"\"\"\n%s\n\"\"\"\n\nLanguage: %s", promptText, detectedLanguage)
+	}
 
-	responseText, err := r.chatGPT.ChatCompletion(ctx, videoDetailsSystemPrompt, userMessage)
+	responseText, err := r.chatGPT.ChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureVideoDetails), videoDetailsSystemPrompt, userMessage)
 	if err != nil {
 		return nil, err
 	}
@@ -264,23 +545,260 @@ func (r *aiRepository) GenerateVideoDetails(ctx context.Context, transcript *cli
 // EvaluateRetellStory compares the transcript against key points and returns a summary.
 func (r *aiRepository) EvaluateRetellStory(ctx context.Context, transcript string, keyPoints []string) (*RetellEvaluation, *errors.AppError) {
 	// Build LLM prompt
-	transcript = strings.TrimSpace(transcript)
 	keyPointsList := "- " + strings.Join(keyPoints, "\n- ")
+	transcript = r.prompts.Fit("retell_evaluation.transcript", strings.TrimSpace(transcript), evaluateRetellSystemPrompt, keyPointsList)
 	userMessage := fmt.Sprintf("Required Key Points:\n\"\"\"\n%s\n\"\"\"\n\nLearner's Transcript: %s", keyPointsList, transcript)
 
-	// Call AI
-	responseText, err := r.chatGPT.ChatCompletion(ctx, evaluateRetellSystemPrompt, userMessage)
+	// Call AI, falling back to the next provider when the output does not match the schema
+	var evaluate *RetellEvaluation
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureRetellEvaluation), r.chatGPT, evaluateRetellSystemPrompt, userMessage,
+		func(responseText string) *errors.AppError {
+			result, err := cleanAndParseJSONResponse[RetellEvaluation](responseText)
+			if err != nil {
+				return err
+			}
+			if err := result.validate(keyPoints); err != nil {
+				return err
+			}
+			evaluate = result
+			return nil
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	// Clean up and Parse responseText
-	evaulate, err := cleanAndParseJSONResponse[RetellEvaluation](responseText)
+	return evaluate, nil
+}
+
+// validate checks the evaluation against the expected schema:
+// score within 0-100, a non-empty analysis, and matched points taken from the key points.
+func (e *RetellEvaluation) validate(keyPoints []string) *errors.AppError {
+	if e.Score < 0 || e.Score > 100 {
+		return errors.Internal(fmt.Sprintf("retell score out of range: %v", e.Score))
+	}
+	if strings.TrimSpace(e.Analysis) == "" {
+		return errors.Internal("retell evaluation is missing analysis")
+	}
+
+	known := make(map[string]bool, len(keyPoints))
+	for _, kp := range keyPoints {
+		known[strings.TrimSpace(kp)] = true
+	}
+	for _, match := range e.MatchesKeyPoints {
+		if !known[strings.TrimSpace(match)] {
+			return errors.Internal(fmt.Sprintf("retell evaluation matched an unknown key point: %q", match))
+		}
+	}
+	if e.MatchesKeyPoints == nil {
+		e.MatchesKeyPoints = []string{}
+	}
+	return nil
+}
+
+// ExtractVocabulary picks study vocabulary and key phrases from the transcript.
+func (r *aiRepository) ExtractVocabulary(ctx context.Context, transcript, level string) (*VideoVocabulary, *errors.AppError) {
+	transcript = r.prompts.Fit("vocabulary.transcript", strings.TrimSpace(transcript), extractVocabularySystemPrompt)
+	userMessage := fmt.Sprintf("Transcript:\n\"\"\"\n%s\n\"\"\"\n\nLevel: %s", transcript, level)
+
+	responseText, err := r.chatGPT.ChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureVocabulary), extractVocabularySystemPrompt, userMessage)
 	if err != nil {
 		return nil, err
 	}
 
-	return evaulate, nil
+	return cleanAndParseJSONResponse[VideoVocabulary](responseText)
+}
+
+// GenerateReadingIPA transcribes texts to IPA again, for readings that failed validation.
+// Every returned transcription passes client.ValidateIPA; texts the model left out are missing from the map.
+func (r *aiRepository) GenerateReadingIPA(ctx context.Context, language string, texts []string) (map[string]string, *errors.AppError) {
+	if len(texts) == 0 {
+		return map[string]string{}, nil
+	}
+	userMessage := fmt.Sprintf("Language: %s\n\nItems:\n- %s", language, strings.Join(texts, "\n- "))
+
+	// Call AI, falling back to the next provider when a transcription is still not valid IPA
+	var readings map[string]string
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureVocabulary), r.chatGPT, generateReadingsSystemPrompt, userMessage,
+		func(responseText string) *errors.AppError {
+			result, err := cleanAndParseJSONResponse[struct {
+				Readings []struct {
+					Text string `json:"text"`
+					IPA  string `json:"ipa"`
+				} `json:"readings"`
+			}](responseText)
+			if err != nil {
+				return err
+			}
+			parsed := make(map[string]string, len(result.Readings))
+			for _, reading := range result.Readings {
+				if err := client.ValidateIPA(language, reading.IPA); err != nil {
+					return err
+				}
+				parsed[strings.TrimSpace(reading.Text)] = strings.TrimSpace(reading.IPA)
+			}
+			readings = parsed
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+// ExpandVocabulary writes new example sentences and collocations for a vocabulary item.
+// Existing ones are passed along so the model does not repeat them.
+func (r *aiRepository) ExpandVocabulary(ctx context.Context, item VocabularyItem, language, level string, examples, collocations int) (*VocabularyExpansion, *errors.AppError) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Language: %s\nLevel: %s\n\nItem: %s\nMeaning: %s\n", language, level, item.Text, item.Meaning)
+	b.WriteString("\nExisting examples:\n")
+	if item.Example != "" {
+		fmt.Fprintf(&b, "- %s\n", item.Example)
+	}
+	for _, example := range item.Examples {
+		fmt.Fprintf(&b, "- %s\n", example.Text)
+	}
+	b.WriteString("\nExisting collocations:\n")
+	for _, collocation := range item.Collocations {
+		fmt.Fprintf(&b, "- %s\n", collocation.Text)
+	}
+	fmt.Fprintf(&b, "\nNew examples: %d\nNew collocations: %d", examples, collocations)
+
+	// Call AI, falling back to the next provider when the counts do not match
+	var expansion *VocabularyExpansion
+	_, err := client.ValidatedChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureVocabulary), r.chatGPT, expandVocabularySystemPrompt, b.String(),
+		func(responseText string) *errors.AppError {
+			result, err := cleanAndParseJSONResponse[VocabularyExpansion](responseText)
+			if err != nil {
+				return err
+			}
+			if len(result.Examples) < examples || len(result.Collocations) < collocations {
+				return errors.Internal(fmt.Sprintf("expansion returned %d examples and %d collocations, want %d and %d",
+					len(result.Examples), len(result.Collocations), examples, collocations))
+			}
+			result.Examples = result.Examples[:examples]
+			result.Collocations = result.Collocations[:collocations]
+			expansion = result
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return expansion, nil
+}
+
+// GenerateChapters splits the timestamped transcript into titled chapters.
+func (r *aiRepository) GenerateChapters(ctx context.Context, segments []TranscriptSegment) ([]VideoChapter, *errors.AppError) {
+	if len(segments) == 0 {
+		return nil, errors.Internal("Empty transcript")
+	}
+
+	var sb strings.Builder
+	for _, seg := range segments {
+		sb.WriteString(fmt.Sprintf("[%.1f] %s\n", seg.Start, seg.Text))
+	}
+	timestamped := r.prompts.Fit("chapters.transcript", sb.String(), generateChaptersSystemPrompt)
+	userMessage := fmt.Sprintf("Transcript:\n\"\"\"\n%s\"\"\"", timestamped)
+
+	responseText, err := r.chatGPT.ChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureChapters), generateChaptersSystemPrompt, userMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := cleanAndParseJSONResponse[struct {
+		Chapters []VideoChapter `json:"chapters"`
+	}](responseText)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Chapters) == 0 {
+		return nil, errors.Internal("no chapters returned")
+	}
+
+	// Each chapter ends where the next one starts; the last ends with the transcript
+	last := segments[len(segments)-1]
+	chapters := result.Chapters
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].Start < chapters[j].Start })
+	for i := range chapters {
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+		} else {
+			chapters[i].End = last.Start + last.Duration
+		}
+	}
+
+	return chapters, nil
+}
+
+// condenseTranscript splits the segments into chunks of about ChunkTokens, summarizes the chunks in parallel
+// and returns the summaries in order, each headed by its time range.
+func (r *aiRepository) condenseTranscript(ctx context.Context, segments []TranscriptSegment) (string, *errors.AppError) {
+	chunks := chunkSegments(segments, r.opts.ChunkTokens)
+	r.log.Info("Summarizing long transcript in chunks", "chunks", len(chunks))
+
+	summaries := make([]string, len(chunks))
+	errs := make([]*errors.AppError, len(chunks))
+	sem := make(chan struct{}, chunkConcurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []TranscriptSegment) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var sb strings.Builder
+			for _, seg := range chunk {
+				sb.WriteString(seg.Text)
+				sb.WriteString(" ")
+			}
+			userMessage := fmt.Sprintf("Part %d of %d:\n\"\"\"\n%s\n\"\"\"", i+1, len(chunks), strings.TrimSpace(sb.String()))
+
+			summary, err := r.chatGPT.ChatCompletion(client.WithChatFeature(ctx, client.ChatFeatureTranscriptSummary), summarizeChunkSystemPrompt, userMessage)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			summaries[i] = strings.TrimSpace(summary)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	var sb strings.Builder
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			return "", errs[i]
+		}
+		last := chunk[len(chunk)-1]
+		fmt.Fprintf(&sb, "Part %d [%s-%s]:\n%s\n\n", i+1, formatChunkTime(chunk[0].Start), formatChunkTime(last.Start+last.Duration), summaries[i])
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// chunkSegments groups consecutive segments into chunks of at most maxTokens (a longer single segment gets its own chunk).
+func chunkSegments(segments []TranscriptSegment, maxTokens int) [][]TranscriptSegment {
+	var chunks [][]TranscriptSegment
+	var current []TranscriptSegment
+	tokens := 0
+	for _, seg := range segments {
+		cost := client.EstimateTokens(seg.Text)
+		if len(current) > 0 && tokens+cost > maxTokens {
+			chunks = append(chunks, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, seg)
+		tokens += cost
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+func formatChunkTime(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
 }
 
 func cleanAndParseJSONResponse[T any](response string) (*T, *errors.AppError) {
@@ -0,0 +1,29 @@
+package video
+
+import (
+	"context"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// AudioRepository voices vocabulary example sentences.
+type AudioRepository interface {
+	Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError)
+}
+
+type audioRepository struct {
+	speechClient client.SpeechClient
+}
+
+// NewAudioRepository creates a new video audio repository.
+func NewAudioRepository(speechClient client.SpeechClient) AudioRepository {
+	return &audioRepository{speechClient: speechClient}
+}
+
+func (r *audioRepository) Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError) {
+	if r.speechClient == nil {
+		return nil, errors.Unsupported("video speech client not configured")
+	}
+	return r.speechClient.Synthesize(ctx, text, voice)
+}
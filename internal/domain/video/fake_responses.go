@@ -0,0 +1,90 @@
+//go:build fake
+
+package video
+
+// FakeChatResponses are canned answers to this package's prompts for client.FakeProviders, keyed by
+// system prompt. They match the fake transcript, so an upload runs through every stage.
+func FakeChatResponses() map[string]string {
+	return map[string]string{
+		videoDetailsSystemPrompt: `{
+  "topic": "Ordering a coffee",
+  "description": "A customer orders a coffee with a little milk at a cafe.",
+  "level": "CEFR A2",
+  "tags": ["cafe", "ordering", "coffee"],
+  "gist_quiz": [
+    {
+      "id": 1,
+      "category": "context",
+      "type": "multiple_response",
+      "question": "Where does the conversation take place?",
+      "options": [
+        { "id": "A", "text": "At a cafe", "is_correct": true },
+        { "id": "B", "text": "At a bank", "is_correct": false },
+        { "id": "C", "text": "At a school", "is_correct": false }
+      ]
+    },
+    {
+      "id": 2,
+      "category": "main_idea",
+      "type": "single_choice",
+      "question": "What does the customer want?",
+      "options": [
+        { "id": "A", "text": "A tea", "is_correct": false },
+        { "id": "B", "text": "A coffee", "is_correct": true },
+        { "id": "C", "text": "A sandwich", "is_correct": false }
+      ]
+    },
+    {
+      "id": 3,
+      "category": "sequence",
+      "type": "ordering",
+      "question": "Put the events in order.",
+      "options": [
+        { "id": "A", "text": "The customer thanks the barista." },
+        { "id": "B", "text": "The customer greets the barista." },
+        { "id": "C", "text": "The barista asks about milk and sugar." },
+        { "id": "D", "text": "The customer asks for a coffee." }
+      ],
+      "correct_order": ["B", "D", "C", "A"]
+    }
+  ],
+  "retell_story": {
+    "retell_example": "A customer asks for a coffee, and when the barista offers milk or sugar, just a little milk is enough.",
+    "key_points": ["The customer orders a coffee.", "The barista offers milk or sugar.", "The customer takes a little milk."]
+  }
+}`,
+		evaluateRetellSystemPrompt: `{
+  "score": 70,
+  "matches_key_points": [],
+  "analysis": "The retell covers the order but leaves out some details."
+}`,
+		extractVocabularySystemPrompt: `{
+  "vocabulary": [
+    { "text": "coffee", "meaning": "a hot drink made from roasted beans", "example": "Good morning, I would like a coffee, please.", "reading": { "ipa": "ˈkɒfi" } },
+    { "text": "milk", "meaning": "a white drink that comes from cows", "example": "Just a little milk, thank you very much.", "reading": { "ipa": "mɪlk" } },
+    { "text": "sugar", "meaning": "a sweet powder added to food and drinks", "example": "Would you like milk or sugar with it?", "reading": { "ipa": "ˈʃʊɡə" } }
+  ],
+  "key_phrases": [
+    { "text": "I would like", "meaning": "a polite way to ask for something", "example": "Good morning, I would like a coffee, please.", "reading": { "ipa": "aɪ wʊd laɪk" } }
+  ]
+}`,
+		generateReadingsSystemPrompt: `{"readings": []}`,
+		expandVocabularySystemPrompt: `{
+  "examples": ["I drink it every morning.", "Can I have another one?", "It is too hot to drink.", "She bought one for her friend.", "We talked over a cup of it."],
+  "collocations": [
+    { "text": "a cup of", "meaning": "the amount one cup holds" },
+    { "text": "strong", "meaning": "with a lot of flavour" },
+    { "text": "to go", "meaning": "to take away and drink elsewhere" },
+    { "text": "black", "meaning": "without milk" },
+    { "text": "make", "meaning": "prepare" }
+  ]
+}`,
+		generateChaptersSystemPrompt: `{
+  "chapters": [
+    { "title": "Ordering", "start": 0 },
+    { "title": "Milk or sugar", "start": 4 }
+  ]
+}`,
+		summarizeChunkSystemPrompt: "A customer orders a coffee. The barista asks about milk or sugar. Good morning, I would like a coffee, please.",
+	}
+}
@@ -1,6 +1,7 @@
 package video
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"log/slog"
@@ -16,21 +17,28 @@ import (
 type FileRepository interface {
 	GetMediaURL(pattern string) (string, *errors.AppError)
 	ExtractAudio(ctx context.Context, videoPath, audioPath string) *errors.AppError
-	UploadToR2(ctx context.Context, src multipart.File, key, path, contentType string) (string, *errors.AppError)
+	StageToR2(ctx context.Context, src multipart.File, key, contentType string) *errors.AppError
+	DownloadFromR2(ctx context.Context, key, path string) *errors.AppError
 	UploadReaderToR2(ctx context.Context, audioM4APath, key, contentType string) (string, *errors.AppError)
+	UploadBytes(ctx context.Context, data []byte, key, contentType string) (string, *errors.AppError)
+	DeleteFromR2(ctx context.Context, key string) *errors.AppError
 	ConvertAudioToM4A(ctx context.Context, srcPath, dstPath string) *errors.AppError
+	NormalizeAudio(ctx context.Context, path string) *errors.AppError
+	TrimSilence(ctx context.Context, path string) *errors.AppError
+	CheckRecordingDuration(ctx context.Context, path string) *errors.AppError
 	CreateTempFile(file multipart.File, pattern string) (*os.File, *errors.AppError)
 }
 
 // fileRepository is the implementation of the FileRepository interface
 type fileRepository struct {
 	cloudflare *client.CloudflareClient
+	ffmpeg     *client.FFmpegClient
 	log        *slog.Logger
 }
 
 // NewFileRepository creates a new fileRepository
-func NewFileRepository(cloudflare *client.CloudflareClient, log *slog.Logger) *fileRepository {
-	return &fileRepository{cloudflare: cloudflare, log: log}
+func NewFileRepository(cloudflare *client.CloudflareClient, ffmpeg *client.FFmpegClient, log *slog.Logger) *fileRepository {
+	return &fileRepository{cloudflare: cloudflare, ffmpeg: ffmpeg, log: log}
 }
 
 // GetMediaURL generates a temporary file path
@@ -63,34 +71,36 @@ func (r *fileRepository) ExtractAudio(ctx context.Context, videoPath, audioPath
 	return nil
 }
 
-// UploadToR2 uploads a file to R2
-func (r *fileRepository) UploadToR2(ctx context.Context, src multipart.File, key, path, contentType string) (string, *errors.AppError) {
-	// Save file to temp location
-	dst, err := os.Create(path)
-	if err != nil {
-		return "", errors.InternalWrap("create temp file", err)
+// StageToR2 uploads a file of the request to R2, where its queued job picks it up
+func (r *fileRepository) StageToR2(ctx context.Context, src multipart.File, key, contentType string) *errors.AppError {
+	// The request may have read the file already (e.g. the duration check)
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return errors.InternalWrap("rewind uploaded file", err)
 	}
-	defer dst.Close()
-
-	// Copy file to temp location
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", errors.InternalWrap("write temp file", err)
+	if _, err := r.cloudflare.UploadR2Object(ctx, key, src, contentType); err != nil {
+		return errors.InternalWrap("stage upload to R2", err)
 	}
+	return nil
+}
 
-	// Open file
-	file, err := os.Open(path)
+// DownloadFromR2 writes an R2 object to a local file
+func (r *fileRepository) DownloadFromR2(ctx context.Context, key, path string) *errors.AppError {
+	body, err := r.cloudflare.GetR2Object(ctx, key)
 	if err != nil {
-		return "", errors.InternalWrap("open output file", err)
+		return errors.InternalWrap("download from R2", err)
 	}
-	defer file.Close()
+	defer body.Close()
 
-	// Upload file to R2
-	url, err := r.cloudflare.UploadR2Object(ctx, key, file, contentType)
+	dst, err := os.Create(path)
 	if err != nil {
-		return "", errors.InternalWrap("upload to R2", err)
+		return errors.InternalWrap("create temp file", err)
 	}
+	defer dst.Close()
 
-	return url, nil
+	if _, err := io.Copy(dst, body); err != nil {
+		return errors.InternalWrap("write temp file", err)
+	}
+	return nil
 }
 
 // UploadReaderToR2 uploads an io.Reader directly to R2 without saving to a temp file.
@@ -108,10 +118,28 @@ func (r *fileRepository) UploadReaderToR2(ctx context.Context, audioM4APath, key
 	return url, nil
 }
 
+// UploadBytes uploads in-memory media (e.g. synthesized audio) to R2.
+func (r *fileRepository) UploadBytes(ctx context.Context, data []byte, key, contentType string) (string, *errors.AppError) {
+	url, err := r.cloudflare.UploadR2Object(ctx, key, bytes.NewReader(data), contentType)
+	if err != nil {
+		return "", errors.InternalWrap("upload to R2", err)
+	}
+	return url, nil
+}
+
+// DeleteFromR2 removes an object from R2.
+func (r *fileRepository) DeleteFromR2(ctx context.Context, key string) *errors.AppError {
+	if err := r.cloudflare.DeleteR2Object(ctx, key); err != nil {
+		r.log.Error("R2 delete failed", "key", key, "error", err.Error())
+		return errors.InternalWrap("delete from R2", err)
+	}
+	return nil
+}
+
 // ConvertAudioToM4A converts a WAV audio file to M4A using ffmpeg.
 func (r *fileRepository) ConvertAudioToM4A(ctx context.Context, srcPath, dstPath string) *errors.AppError {
 	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-i", srcPath,
-		"-af", "afftdn,loudnorm=I=-16:TP=-1.5:LRA=11",
+		"-af", "afftdn,"+r.ffmpeg.LoudnormFilter(),
 		"-c:a", "aac", "-b:a", "64k", "-ac", "1",
 		"-ar", "16000", "-movflags", "faststart",
 		dstPath,
@@ -126,6 +154,33 @@ func (r *fileRepository) ConvertAudioToM4A(ctx context.Context, srcPath, dstPath
 	return nil
 }
 
+// NormalizeAudio applies loudness normalization to the audio file at path (in place).
+func (r *fileRepository) NormalizeAudio(ctx context.Context, path string) *errors.AppError {
+	if err := r.ffmpeg.NormalizeLoudnessInPlace(ctx, path); err != nil {
+		r.log.Error("FFmpeg loudness normalization failed", "path", path, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// TrimSilence removes leading and trailing silence from the audio file at path (in place).
+func (r *fileRepository) TrimSilence(ctx context.Context, path string) *errors.AppError {
+	if err := r.ffmpeg.TrimSilenceInPlace(ctx, path); err != nil {
+		r.log.Error("FFmpeg silence trim failed", "path", path, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// CheckRecordingDuration rejects user recordings longer than the configured cap.
+func (r *fileRepository) CheckRecordingDuration(ctx context.Context, path string) *errors.AppError {
+	if err := r.ffmpeg.CheckMaxDuration(ctx, path); err != nil {
+		r.log.Warn("Recording duration check failed", "path", path, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
 // CreateTempFile saves a multipart file to a temporary file.
 func (r *fileRepository) CreateTempFile(file multipart.File, tempPath string) (*os.File, *errors.AppError) {
 	// 1. ตรวจสอบว่าไฟล์ต้นทางไม่ได้ว่างเปล่า หรือหัวอ่านค้างอยู่ที่ท้ายไฟล์
@@ -2,6 +2,7 @@ package video
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/windfall/uwu_service/internal/infra/client"
@@ -14,13 +15,15 @@ import (
 type VideoHandler struct {
 	service *VideoService
 	queue   *client.QueueClient
+	budget  *client.BudgetClient
 }
 
 // NewVideoHandler creates a new VideoHandler.
-func NewVideoHandler(service *VideoService, queue *client.QueueClient) *VideoHandler {
+func NewVideoHandler(service *VideoService, queue *client.QueueClient, budget *client.BudgetClient) *VideoHandler {
 	return &VideoHandler{
 		service: service,
 		queue:   queue,
+		budget:  budget,
 	}
 }
 
@@ -29,9 +32,12 @@ func NewVideoHandler(service *VideoService, queue *client.QueueClient) *VideoHan
 // -------------------------------------------------------------------------
 
 func (h *VideoHandler) ListVideoContents(w http.ResponseWriter, r *http.Request) {
-	// 1. parse pagination params
+	// 1. parse pagination and filter params
 	var req ListVideoContentsRequest
-	req.Parse(r)
+	if err := req.Parse(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
 
 	// 2. get video contents from database
 	result, err := h.service.ListVideoContents(r.Context(), req.ToInput())
@@ -63,27 +69,40 @@ func (h *VideoHandler) UploadVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 4. generate payload once
+	// 4. refuse new generation jobs once the daily AI budget is spent
+	if err := h.budget.CheckDaily(r.Context()); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 5. generate payload once and stage the files for the job
 	payload := req.ToPayload()
+	if err := h.service.StageUploadVideo(r.Context(), payload, req.VideoFile, req.ThumbnailFile); err != nil {
+		response.HandleError(w, err)
+		return
+	}
 
-	// 5. send job to queue
+	// 6. send job to queue
 	qErr := h.queue.Enqueue(client.Job{
-		Type:    WORKER_UPLOAD_VIDEO,
-		Payload: payload,
+		Type:           WORKER_UPLOAD_VIDEO,
+		Payload:        payload,
+		BatchID:        payload.VideoID,
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+		ChatProvider:   client.ChatProviderFromContext(r.Context()),
 	})
 	if qErr != nil {
 		response.HandleError(w, qErr)
 		return
 	}
 
-	// 6. create video record
+	// 7. create video record
 	result, err := h.service.CreateVideoContent(r.Context(), payload)
 	if err != nil {
 		response.HandleError(w, err)
 		return
 	}
 
-	// 6. response accepted
+	// 8. response accepted
 	response.AcceptedWithMeta(w, result.Data, result.Meta)
 }
 
@@ -106,8 +125,79 @@ func (h *VideoHandler) GetVideoDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 3. response success
-	response.OKWithMeta(w, video.Data, video.Meta)
+	// 3. response success (304 when the client already has this version)
+	var updatedAt time.Time
+	if video.Data.UpdatedAt != nil {
+		updatedAt = *video.Data.UpdatedAt
+	}
+	response.OKConditional(w, r, updatedAt, video.Data, video.Meta)
+}
+
+// -------------------------------------------------------------------------
+// POST /api/v1/videos/bulk
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) BulkVideos(w http.ResponseWriter, r *http.Request) {
+	var req BulkVideosRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.BulkVideos(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// PATCH /api/v1/videos/{videoID}
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) PatchVideo(w http.ResponseWriter, r *http.Request) {
+	var req PatchVideoRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	input, err := req.ToInput()
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, appErr := h.service.PatchVideo(r.Context(), input)
+	if appErr != nil {
+		response.HandleError(w, appErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// GET /api/v1/videos/{videoID}/chapters
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) GetVideoChapters(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "videoID")
+	if videoID == "" {
+		response.HandleError(w, errors.Validation("Video ID is required"))
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	result, err := h.service.GetVideoChapters(r.Context(), videoID, userID)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
 }
 
 // -------------------------------------------------------------------------
@@ -196,6 +286,203 @@ func (h *VideoHandler) ToggleTranscript(w http.ResponseWriter, r *http.Request)
 	response.OK(w, result)
 }
 
+// -------------------------------------------------------------------------
+// PATCH /api/v1/videos/{videoID}/transcript
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) UpdateTranscript(w http.ResponseWriter, r *http.Request) {
+	var req UpdateTranscriptRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	input := req.ToInput()
+	if input.Regenerate {
+		if err := h.budget.CheckDaily(r.Context()); err != nil {
+			response.HandleError(w, err)
+			return
+		}
+	}
+
+	result, err := h.service.UpdateTranscript(r.Context(), input)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	if !input.Regenerate {
+		response.OK(w, result)
+		return
+	}
+
+	// Regenerate details / quiz / retell story from the corrected text
+	qErr := h.queue.Enqueue(client.Job{
+		Type:           WORKER_REGENERATE,
+		Payload:        RegenerateVideoDetailsPayload{UserID: input.UserID, VideoID: input.VideoID},
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+		ChatProvider:   client.ChatProviderFromContext(r.Context()),
+	})
+	if qErr != nil {
+		response.HandleError(w, qErr)
+		return
+	}
+
+	response.Accepted(w, result)
+}
+
+// -------------------------------------------------------------------------
+// PUT /api/v1/videos/{videoID}/retell-settings
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) UpdateRetellSettings(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRetellSettingsRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.UpdateRetellSettings(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// POST /api/v1/learning-items/{itemID}/expand
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) ExpandVocabulary(w http.ResponseWriter, r *http.Request) {
+	var req ExpandVocabularyRequest
+	if err := req.ParseAndValidate(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ExpandVocabulary(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// GET /api/v1/videos/{videoID}/retell-points
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) ListRetellPoints(w http.ResponseWriter, r *http.Request) {
+	var req RetellPointRequest
+	if err := req.ParseAndValidate(r, false); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ListRetellPoints(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// POST /api/v1/videos/{videoID}/retell-points
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) AddRetellPoint(w http.ResponseWriter, r *http.Request) {
+	var req RetellPointRequest
+	if err := req.ParseAndValidate(r, false); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if err := req.ParseBody(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.AddRetellPoint(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, result)
+}
+
+// -------------------------------------------------------------------------
+// PATCH /api/v1/videos/{videoID}/retell-points/{pointIndex}
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) UpdateRetellPoint(w http.ResponseWriter, r *http.Request) {
+	var req RetellPointRequest
+	if err := req.ParseAndValidate(r, true); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if err := req.ParseBody(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.UpdateRetellPoint(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// DELETE /api/v1/videos/{videoID}/retell-points/{pointIndex}
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) DeleteRetellPoint(w http.ResponseWriter, r *http.Request) {
+	var req RetellPointRequest
+	if err := req.ParseAndValidate(r, true); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.DeleteRetellPoint(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// -------------------------------------------------------------------------
+// PUT /api/v1/videos/{videoID}/retell-points/order
+// -------------------------------------------------------------------------
+
+func (h *VideoHandler) ReorderRetellPoints(w http.ResponseWriter, r *http.Request) {
+	var req RetellPointRequest
+	if err := req.ParseAndValidate(r, false); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if err := req.ParseBody(r); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.service.ReorderRetellPoints(r.Context(), req.ToInput())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
 // -------------------------------------------------------------------------
 // POST /api/v1/videos/{videoID}/submit-quiz
 // -------------------------------------------------------------------------
@@ -227,6 +514,7 @@ func (h *VideoHandler) SubmitRetellStory(w http.ResponseWriter, r *http.Request)
 
 	// 2. declare request struct and defer close
 	var req SubmitRetellRequest
+	defer req.Close()
 	if err := req.ParseAndValidate(r); err != nil {
 		response.HandleError(w, err)
 		return
@@ -235,23 +523,40 @@ func (h *VideoHandler) SubmitRetellStory(w http.ResponseWriter, r *http.Request)
 	// 3. generate payload once
 	payload := req.ToPayload()
 
-	// 4. send job to queue
+	// 4. reject submissions past the attempt limit and overly long recordings before queueing
+	if err := h.service.CheckRetellAttempts(r.Context(), payload); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if err := h.service.ValidateRetellAudio(r.Context(), payload, req.AudioFile); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if err := h.service.StageRetellAudio(r.Context(), payload, req.AudioFile); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	// 5. send job to queue
 	qErr := h.queue.Enqueue(client.Job{
-		Type:    WORKER_EVALUATE_RETEL,
-		Payload: payload,
+		Type:           WORKER_EVALUATE_RETEL,
+		Payload:        payload,
+		BatchID:        payload.AttemptID,
+		OrganizationID: client.OrganizationFromContext(r.Context()),
+		ChatProvider:   client.ChatProviderFromContext(r.Context()),
 	})
 	if qErr != nil {
 		response.HandleError(w, qErr)
 		return
 	}
 
-	// 5. create video record
+	// 6. create video record
 	result, err := h.service.SubmitRetellStory(r.Context(), payload)
 	if err != nil {
 		response.HandleError(w, err)
 		return
 	}
 
-	// 6. response accepted
+	// 7. response accepted
 	response.Accepted(w, result)
 }
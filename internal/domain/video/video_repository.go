@@ -1,8 +1,11 @@
 package video
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,7 +15,7 @@ import (
 )
 
 // Constants
-const FeatureID = 1
+const FeatureID = client.FeatureVideo
 
 // User Action model
 type UserAction struct {
@@ -55,12 +58,28 @@ type LearningItem struct {
 	Tags      json.RawMessage `json:"tags"`
 	IsActive  bool            `json:"is_active"`
 	CreatedBy string          `json:"created_by"`
+	Version   int             `json:"version"`
 	CreatedAt *time.Time      `json:"created_at"`
 	UpdatedAt *time.Time      `json:"updated_at"`
+	// Published dialogs whose script uses the vocabulary (details response only)
+	AppearsIn []ScenarioAppearance `json:"appears_in,omitempty"`
 	// Learning Item Actions
 	Actions VideoActions `json:"actions"`
+	// Favorited by the requesting user
+	Favorited bool `json:"favorited"`
 }
 
+// ScenarioAppearance is a dialog turn that uses one of the video's vocabulary terms
+type ScenarioAppearance struct {
+	ScenarioID    string `json:"scenario_id"`
+	ScenarioTitle string `json:"scenario_title"`
+	Term          string `json:"term"`
+	TurnIndex     int    `json:"turn_index"`
+}
+
+// maxAppearances caps the dialog turns listed for a video
+const maxAppearances = 50
+
 // VideoDetails is the structure of the details field in LearningItem model
 type VideoDetails struct {
 	Topic       string              `json:"topic"`
@@ -83,62 +102,203 @@ type VideoDetails struct {
 		CorrectOrder any    `json:"correct_order"`
 	} `json:"gist_quiz"`
 	RetellStory struct {
-		KeyPoints     []string `json:"key_points"`
-		RetellExample string   `json:"retell_example"`
+		KeyPoints     []string  `json:"key_points"`
+		Weights       []float64 `json:"weights,omitempty"`  // per key point, empty means equal weights
+		Authored      bool      `json:"authored,omitempty"` // edited by the owner, kept on regeneration
+		RetellExample string    `json:"retell_example"`
 	} `json:"retell_story"`
-	VideoURL     string `json:"video_url"`
-	ThumbnailURL string `json:"thumbnail_url"`
+	VideoURL          string             `json:"video_url"`
+	ThumbnailURL      string             `json:"thumbnail_url"`
+	TranscriptQuality *TranscriptQuality `json:"transcript_quality,omitempty"`
+	Vocabulary        []VocabularyItem   `json:"vocabulary,omitempty"`
+	KeyPhrases        []VocabularyItem   `json:"key_phrases,omitempty"`
+	Chapters          []VideoChapter     `json:"chapters,omitempty"`
+	RetellSettings    *RetellSettings    `json:"retell_settings,omitempty"`
+	// Previous transcript versions, oldest first
+	TranscriptRevisions []TranscriptRevision `json:"transcript_revisions,omitempty"`
+}
+
+// TranscriptRevision is a snapshot of the transcript before an edit
+type TranscriptRevision struct {
+	Revision   int                 `json:"revision"`
+	Transcript string              `json:"transcript"`
+	Segments   []TranscriptSegment `json:"segments"`
+	EditedBy   string              `json:"edited_by"`
+	EditedAt   time.Time           `json:"edited_at"`
+}
+
+// validateDetails rejects details that don't match the VideoDetails schema (unknown fields included).
+func validateDetails(raw json.RawMessage) *errors.AppError {
+	if len(raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var details VideoDetails
+	if err := decoder.Decode(&details); err != nil {
+		return errors.ValidationWrap("video details do not match the schema", err)
+	}
+	return nil
+}
+
+// NormalizeDetails decodes raw details leniently and re-encodes them in the VideoDetails shape,
+// dropping unknown fields. Used by the details backfill tool.
+func NormalizeDetails(raw json.RawMessage) (json.RawMessage, error) {
+	var details VideoDetails
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, err
+	}
+	return json.Marshal(details)
+}
+
+// VideoFilter narrows the video listing, zero values are ignored
+type VideoFilter struct {
+	Language     string
+	Level        string
+	IsActive     *bool
+	Tag          string
+	CreatedAfter *time.Time
+	CreatedBy    string
+	// Viewer marks the listed videos the user favorited; it doesn't filter
+	Viewer string
+}
+
+// BulkAction is an action of the bulk video endpoint
+type BulkAction string
+
+const (
+	BulkActivate   BulkAction = "activate"
+	BulkDeactivate BulkAction = "deactivate"
+	BulkDelete     BulkAction = "delete"
+	BulkRetag      BulkAction = "retag"
+)
+
+// MaxBulkItems caps how many videos a single bulk request may touch
+const MaxBulkItems = 200
+
+// Bulk item statuses
+const (
+	BULK_OK        = "ok"
+	BULK_NOT_FOUND = "not_found"
+	BULK_FORBIDDEN = "forbidden"
+	BULK_HIDDEN    = "hidden" // hidden by moderation, can't be activated by its owner
+)
+
+// BulkItemResult reports the outcome of a bulk action for one video
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
 }
 
 // VideoRepository interface
 type VideoRepository interface {
 	GetVideo(ctx context.Context, videoID, userID string) (*LearningItem, *errors.AppError)
+	GetVideoDetails(ctx context.Context, videoID, userID string) (*LearningItem, *errors.AppError)
 	ListVideos(ctx context.Context, limit, offset int) ([]*LearningItem, int, *errors.AppError)
+	ListFiltered(ctx context.Context, filter VideoFilter, limit, offset int) ([]*LearningItem, int, *errors.AppError)
 	CreateVideo(ctx context.Context, item *LearningItem) *errors.AppError
 	UpdateVideo(ctx context.Context, item *LearningItem) *errors.AppError
 	ToggleSaved(ctx context.Context, videoID, userID string) (string, bool, *errors.AppError)
-	StartQuiz(ctx context.Context, videoID, userID string, metadata json.RawMessage) (string, *errors.AppError)
-	StartRetell(ctx context.Context, videoID, userID string, metadata json.RawMessage) (string, *errors.AppError)
+	StartQuiz(ctx context.Context, videoID, userID string, metadata json.RawMessage) (*UserAction, *errors.AppError)
+	StartRetell(ctx context.Context, videoID, userID string, metadata json.RawMessage) (*UserAction, *errors.AppError)
 	ToggleTranscript(ctx context.Context, videoID, userID string) (string, bool, *errors.AppError)
 	GetQuizAction(ctx context.Context, actionID string) (*UserAction, *errors.AppError)
 	GetActionByUserID(ctx context.Context, videoID, userID, actionType string) (*UserAction, bool, *errors.AppError)
-	UpdateQuizAction(ctx context.Context, actionID string, metadata json.RawMessage) *errors.AppError
+	UpdateQuizAction(ctx context.Context, actionID, actionType string, metadata json.RawMessage) *errors.AppError
+	UpdateActionMetadata(ctx context.Context, actionID string, update func(metadata json.RawMessage) (json.RawMessage, *errors.AppError)) *errors.AppError
+	UpdateVideoDetails(ctx context.Context, item *LearningItem) *errors.AppError
+	PatchVideo(ctx context.Context, item *LearningItem) *errors.AppError
+	BulkUpdate(ctx context.Context, userID string, action BulkAction, ids []string, filter *VideoFilter, tags json.RawMessage) ([]BulkItemResult, *errors.AppError)
+	ListRetellActionsWithAudioBefore(ctx context.Context, cutoff time.Time, limit int) ([]*UserAction, *errors.AppError)
 }
 
 type videoRepository struct {
-	db *client.PostgresClient
+	db     *client.PostgresClient
+	cipher *client.FieldCipher // retell transcripts and analyses are stored encrypted (nil = plaintext)
+}
+
+func NewVideoRepository(db *client.PostgresClient, cipher *client.FieldCipher) VideoRepository {
+	return &videoRepository{db: db, cipher: cipher}
+}
+
+// openAction decrypts the sensitive metadata fields of an action read from the database.
+func (r *videoRepository) openAction(a *UserAction) *errors.AppError {
+	metadata, err := r.cipher.OpenAction(a.ActionType, a.Metadata)
+	if err != nil {
+		return errors.InternalWrap("failed to decrypt action metadata", err)
+	}
+	a.Metadata = metadata
+	return nil
 }
 
-func NewVideoRepository(db *client.PostgresClient) VideoRepository {
-	return &videoRepository{db: db}
+// sealMetadata encrypts the sensitive metadata fields of an action before it is written.
+func (r *videoRepository) sealMetadata(actionType string, metadata json.RawMessage) (json.RawMessage, *errors.AppError) {
+	sealed, err := r.cipher.SealAction(actionType, metadata)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to encrypt action metadata", err)
+	}
+	return sealed, nil
 }
 
+// getVideoQuery selects a video with the actions of all users on it ($1 video id, $2 feature id).
+const getVideoQuery = `
+	SELECT 
+		l.id, l.feature_id, l.content, l.language, l.level,
+		l.details, l.metadata, l.tags, l.is_active, l.created_by,
+		l.version, l.created_at, l.updated_at,
+		COALESCE(
+			jsonb_agg(jsonb_build_object(
+				'user_id', ua.user_id,
+				'action_type', ua.action_type
+			)) FILTER (WHERE ua.id IS NOT NULL),
+			'[]'::jsonb
+		) as actions
+	FROM learning_items l
+	LEFT JOIN (
+		SELECT id, user_id, learning_id, action_type::text AS action_type FROM user_actions
+		WHERE action_type IN ('quiz_transcript', 'submit_quiz', 'submit_retell') AND deleted_at IS NULL
+		UNION ALL
+		-- saving a video is a favorite
+		SELECT id, user_id, content_id, 'quiz_saved' FROM favorites WHERE content_type = 'video'
+	) ua ON l.id = ua.learning_id
+	WHERE l.id = $1 AND l.feature_id = $2
+	GROUP BY l.id
+`
+
 func (r *videoRepository) GetVideo(ctx context.Context, videoID, userID string) (*LearningItem, *errors.AppError) {
-	query := `
-		SELECT 
-			l.id, l.feature_id, l.content, l.language, l.level,
-			l.details, l.metadata, l.tags, l.is_active, l.created_by,
-			l.created_at, l.updated_at,
-			COALESCE(
-				jsonb_agg(jsonb_build_object(
-					'user_id', ua.user_id,
-					'action_type', ua.action_type
-				)) FILTER (WHERE ua.id IS NOT NULL),
-				'[]'::jsonb
-			) as actions
-		FROM learning_items l
-		LEFT JOIN user_actions ua
-			ON l.id = ua.learning_id
-			AND ua.action_type IN ('quiz_saved', 'quiz_transcript', 'submit_quiz', 'submit_retell')
-			AND ua.deleted_at IS NULL
-		WHERE l.id = $1 AND l.feature_id = $2
-		GROUP BY l.id
-	`
+	return scanVideo(r.db.Pool.QueryRow(ctx, getVideoQuery, videoID, FeatureID), userID)
+}
+
+// GetVideoDetails returns the video with the dialog turns that use its vocabulary, loaded in one round trip.
+func (r *videoRepository) GetVideoDetails(ctx context.Context, videoID, userID string) (*LearningItem, *errors.AppError) {
+	batch := &pgx.Batch{}
+	batch.Queue(getVideoQuery, videoID, FeatureID)
+	batch.Queue(listAppearancesQuery, videoID, userID, maxAppearances)
+
+	results := r.db.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	item, err := scanVideo(results.QueryRow(), userID)
+	if err != nil {
+		return nil, err
+	}
+	rows, queryErr := results.Query()
+	if queryErr != nil {
+		return nil, errors.InternalWrap("failed to list vocabulary appearances", queryErr)
+	}
+	item.AppearsIn, err = scanAppearances(rows)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
 
+// scanVideo reads a getVideoQuery row and counts the actions, marking those of userID.
+func scanVideo(row pgx.Row, userID string) (*LearningItem, *errors.AppError) {
 	var item LearningItem
 	var actionsJSON []byte
 
-	err := r.db.Pool.QueryRow(ctx, query, videoID, FeatureID).Scan(
+	err := row.Scan(
 		&item.ID,
 		&item.FeatureID,
 		&item.Content,
@@ -149,6 +309,7 @@ func (r *videoRepository) GetVideo(ctx context.Context, videoID, userID string)
 		&item.Tags,
 		&item.IsActive,
 		&item.CreatedBy,
+		&item.Version,
 		&item.CreatedAt,
 		&item.UpdatedAt,
 		&actionsJSON,
@@ -179,6 +340,7 @@ func (r *videoRepository) GetVideo(ctx context.Context, videoID, userID string)
 					item.Actions.Type.Saved++
 					if action.UserID == userID {
 						item.Actions.User.Saved = true
+						item.Favorited = true
 					}
 				case "quiz_transcript":
 					if action.UserID == userID {
@@ -226,7 +388,7 @@ func (r *videoRepository) ListVideos(ctx context.Context, limit, offset int) ([]
 		SELECT 
 			l.id, l.feature_id, l.content, l.language, l.level, 
 			l.details, l.metadata, l.tags, l.is_active, l.created_by, 
-			l.created_at, l.updated_at
+			l.version, l.created_at, l.updated_at
 		FROM learning_items l
 		WHERE l.feature_id = $1
 		ORDER BY l.created_at DESC
@@ -254,8 +416,10 @@ func (r *videoRepository) ListVideos(ctx context.Context, limit, offset int) ([]
 			&video.Tags,
 			&video.IsActive,
 			&video.CreatedBy,
+			&video.Version,
 			&video.CreatedAt,
 			&video.UpdatedAt,
+			&video.Favorited,
 		)
 		if err != nil {
 			return nil, 0, errors.InternalWrap("failed to scan video content", err)
@@ -268,13 +432,189 @@ func (r *videoRepository) ListVideos(ctx context.Context, limit, offset int) ([]
 	return videos, total, nil
 }
 
+// ListFiltered lists videos matching filter, newest first.
+// Every condition is backed by an index on learning_items.
+func (r *videoRepository) ListFiltered(ctx context.Context, filter VideoFilter, limit, offset int) ([]*LearningItem, int, *errors.AppError) {
+	// 1. Build WHERE clause
+	where, args := videoFilterWhere(filter)
+
+	// 2. Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM learning_items l WHERE ` + where
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, errors.InternalWrap("failed to count video contents", err)
+	}
+
+	// 3. Get paginated results
+	query := fmt.Sprintf(`
+		SELECT 
+			l.id, l.feature_id, l.content, l.language, l.level, 
+			l.details, l.metadata, l.tags, l.is_active, l.created_by, 
+			l.version, l.created_at, l.updated_at,
+			%s
+		FROM learning_items l
+		WHERE %s
+		ORDER BY l.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, client.FavoritedSQL("l", client.FavoriteVideo, fmt.Sprintf("$%d", len(args)+3)), where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Pool.Query(ctx, query, append(args, limit, offset, filter.Viewer)...)
+	if err != nil {
+		return nil, 0, errors.InternalWrap("failed to list video contents", err)
+	}
+	defer rows.Close()
+
+	videos := make([]*LearningItem, 0)
+	for rows.Next() {
+		var video LearningItem
+		err := rows.Scan(
+			&video.ID,
+			&video.FeatureID,
+			&video.Content,
+			&video.Language,
+			&video.Level,
+			&video.Details,
+			&video.Metadata,
+			&video.Tags,
+			&video.IsActive,
+			&video.CreatedBy,
+			&video.Version,
+			&video.CreatedAt,
+			&video.UpdatedAt,
+			&video.Favorited,
+		)
+		if err != nil {
+			return nil, 0, errors.InternalWrap("failed to scan video content", err)
+		}
+
+		video.Actions = VideoActions{}
+		videos = append(videos, &video)
+	}
+
+	return videos, total, nil
+}
+
+// videoFilterWhere builds the WHERE clause (on alias l) and its args for filter.
+func videoFilterWhere(filter VideoFilter) (string, []interface{}) {
+	conditions := []string{"l.feature_id = $1"}
+	args := []interface{}{FeatureID}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Language != "" {
+		addCondition("l.language = $%d", filter.Language)
+	}
+	if filter.Level != "" {
+		addCondition("l.level = $%d", filter.Level)
+	}
+	if filter.IsActive != nil {
+		addCondition("l.is_active = $%d", *filter.IsActive)
+	}
+	if filter.Tag != "" {
+		addCondition("l.tags @> jsonb_build_array($%d::text)", filter.Tag)
+	}
+	if filter.CreatedAfter != nil {
+		addCondition("l.created_at > $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBy != "" {
+		addCondition("l.created_by = $%d", filter.CreatedBy)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// BulkUpdate applies action to the given videos (or, when ids is empty, to the owner's videos matching filter)
+// in a single transaction. Items the user doesn't own are skipped and reported; a database error rolls back everything.
+func (r *videoRepository) BulkUpdate(ctx context.Context, userID string, action BulkAction, ids []string, filter *VideoFilter, tags json.RawMessage) ([]BulkItemResult, *errors.AppError) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Resolve filter to the owner's video IDs
+	if len(ids) == 0 && filter != nil {
+		scoped := *filter
+		scoped.CreatedBy = userID
+		where, args := videoFilterWhere(scoped)
+		rows, err := tx.Query(ctx, fmt.Sprintf(`SELECT l.id::text FROM learning_items l WHERE %s LIMIT $%d`, where, len(args)+1), append(args, MaxBulkItems)...)
+		if err != nil {
+			return nil, errors.InternalWrap("failed to resolve bulk filter", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, errors.InternalWrap("failed to scan video id", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+	}
+
+	// 2. Apply action item by item, locking each row
+	results := make([]BulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		var createdBy string
+		var hidden bool
+		err := tx.QueryRow(ctx, `SELECT created_by, moderation_hidden_at IS NOT NULL FROM learning_items WHERE id = $1 AND feature_id = $2 FOR UPDATE`, id, FeatureID).Scan(&createdBy, &hidden)
+		if err == pgx.ErrNoRows {
+			results = append(results, BulkItemResult{ID: id, Status: BULK_NOT_FOUND})
+			continue
+		}
+		if err != nil {
+			return nil, errors.InternalWrap("failed to lock video", err)
+		}
+		if createdBy != userID {
+			results = append(results, BulkItemResult{ID: id, Status: BULK_FORBIDDEN})
+			continue
+		}
+		// Hidden by moderation stays hidden until a moderator resolves its reports
+		if hidden && action == BulkActivate {
+			results = append(results, BulkItemResult{ID: id, Status: BULK_HIDDEN})
+			continue
+		}
+
+		switch action {
+		case BulkActivate:
+			_, err = tx.Exec(ctx, `UPDATE learning_items SET is_active = true, version = version + 1, updated_at = NOW() WHERE id = $1 AND moderation_hidden_at IS NULL`, id)
+		case BulkDeactivate:
+			_, err = tx.Exec(ctx, `UPDATE learning_items SET is_active = false, version = version + 1, updated_at = NOW() WHERE id = $1`, id)
+		case BulkRetag:
+			_, err = tx.Exec(ctx, `UPDATE learning_items SET tags = $1, version = version + 1, updated_at = NOW() WHERE id = $2`, tags, id)
+		case BulkDelete:
+			_, err = tx.Exec(ctx, `DELETE FROM learning_items WHERE id = $1`, id)
+			if err == nil {
+				// Leave a tombstone so delta sync can tell clients the video is gone
+				_, err = tx.Exec(ctx, `INSERT INTO learning_item_tombstones (id, feature_id) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, id, FeatureID)
+			}
+		}
+		if err != nil {
+			return nil, errors.InternalWrap(fmt.Sprintf("failed to %s video", action), err)
+		}
+		results = append(results, BulkItemResult{ID: id, Status: BULK_OK})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.InternalWrap("failed to commit bulk update", err)
+	}
+
+	return results, nil
+}
+
 func (r *videoRepository) CreateVideo(ctx context.Context, item *LearningItem) *errors.AppError {
+	if err := validateDetails(item.Details); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO learning_items (
 			id, feature_id, content, language, level, details, tags, metadata, is_active, created_by
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
-		) RETURNING id, created_at, updated_at
+		) RETURNING id, version, created_at, updated_at
 	`
 
 	err := r.db.Pool.QueryRow(ctx, query,
@@ -288,7 +628,7 @@ func (r *videoRepository) CreateVideo(ctx context.Context, item *LearningItem) *
 		item.Metadata,
 		item.IsActive,
 		item.CreatedBy,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	).Scan(&item.ID, &item.Version, &item.CreatedAt, &item.UpdatedAt)
 
 	if err != nil {
 		return errors.InternalWrap("failed to create video content", err)
@@ -298,10 +638,15 @@ func (r *videoRepository) CreateVideo(ctx context.Context, item *LearningItem) *
 }
 
 func (r *videoRepository) UpdateVideo(ctx context.Context, item *LearningItem) *errors.AppError {
+	if err := validateDetails(item.Details); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE learning_items
-		SET feature_id = $1, content = $2, language = $3, level = $4, tags = $5, details = $6, metadata = $7, is_active = $8, created_by = $9
+		SET feature_id = $1, content = $2, language = $3, level = $4, tags = $5, details = $6, metadata = $7, is_active = $8, created_by = $9, version = version + 1, updated_at = NOW()
 		WHERE id = $10
+		RETURNING id, version, created_at, updated_at
 	`
 
 	err := r.db.Pool.QueryRow(ctx, query,
@@ -315,8 +660,11 @@ func (r *videoRepository) UpdateVideo(ctx context.Context, item *LearningItem) *
 		item.IsActive,
 		item.CreatedBy,
 		item.ID,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	).Scan(&item.ID, &item.Version, &item.CreatedAt, &item.UpdatedAt)
 
+	if err == pgx.ErrNoRows {
+		return errors.NotFound("video not found")
+	}
 	if err != nil {
 		return errors.InternalWrap("failed to update video details", err)
 	}
@@ -324,67 +672,143 @@ func (r *videoRepository) UpdateVideo(ctx context.Context, item *LearningItem) *
 	return nil
 }
 
-func (r *videoRepository) StartQuiz(ctx context.Context, videoID, userID string, metadata json.RawMessage) (string, *errors.AppError) {
+// UpdateVideoDetails updates the content fields of a video without touching its processing metadata.
+// The update only applies if item.Version is still the stored version (compare-and-swap);
+// otherwise a CONFLICT error carrying the current version is returned.
+func (r *videoRepository) UpdateVideoDetails(ctx context.Context, item *LearningItem) *errors.AppError {
+	if err := validateDetails(item.Details); err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO user_actions (user_id, learning_id, action_type, metadata, deleted_at)
-		VALUES ($1, $2, 'submit_quiz', $3, NULL)
-		ON CONFLICT (learning_id, user_id, action_type)
-		DO UPDATE SET
-			metadata = EXCLUDED.metadata,
-			deleted_at = NULL,
-			updated_at = NOW()
-		RETURNING id
+		UPDATE learning_items
+		SET content = $1, level = $2, tags = $3, details = $4, version = version + 1, updated_at = NOW()
+		WHERE id = $5 AND feature_id = $6 AND version = $7
+		RETURNING version
 	`
 
-	var actionID string
-	if err := r.db.Pool.QueryRow(ctx, query, userID, videoID, metadata).Scan(&actionID); err != nil {
-		return "", errors.InternalWrap("failed to start quiz action", err)
+	err := r.db.Pool.QueryRow(ctx, query, item.Content, item.Level, item.Tags, item.Details, item.ID, FeatureID, item.Version).Scan(&item.Version)
+	if err == nil {
+		return nil
+	}
+	if err != pgx.ErrNoRows {
+		return errors.InternalWrap("failed to update video details", err)
 	}
 
-	return actionID, nil
+	return r.versionConflict(ctx, item)
 }
 
-func (r *videoRepository) StartRetell(ctx context.Context, videoID, userID string, metadata json.RawMessage) (string, *errors.AppError) {
+// PatchVideo saves content, tags, details and metadata of a video with the same
+// compare-and-swap semantics as UpdateVideoDetails.
+func (r *videoRepository) PatchVideo(ctx context.Context, item *LearningItem) *errors.AppError {
+	if err := validateDetails(item.Details); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE learning_items
+		SET content = $1, tags = $2, details = $3, metadata = $4, version = version + 1, updated_at = NOW()
+		WHERE id = $5 AND feature_id = $6 AND version = $7
+		RETURNING version, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query, item.Content, item.Tags, item.Details, item.Metadata, item.ID, FeatureID, item.Version).Scan(&item.Version, &item.UpdatedAt)
+	if err == nil {
+		return nil
+	}
+	if err != pgx.ErrNoRows {
+		return errors.InternalWrap("failed to patch video", err)
+	}
+
+	return r.versionConflict(ctx, item)
+}
+
+// versionConflict explains a compare-and-swap update that matched no row:
+// either the video is gone or someone else updated it first.
+func (r *videoRepository) versionConflict(ctx context.Context, item *LearningItem) *errors.AppError {
+	var current int
+	err := r.db.Pool.QueryRow(ctx, `SELECT version FROM learning_items WHERE id = $1 AND feature_id = $2`, item.ID, FeatureID).Scan(&current)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("video not found")
+		}
+		return errors.InternalWrap("failed to get video version", err)
+	}
+
+	return errors.Conflict("video was modified by another request").
+		WithDetails(map[string]interface{}{"current_version": current})
+}
+
+// StartQuiz returns the user's active quiz action, creating it with metadata when there is none.
+// The upsert locks the row, so concurrent first calls all get the same action and metadata.
+func (r *videoRepository) StartQuiz(ctx context.Context, videoID, userID string, metadata json.RawMessage) (*UserAction, *errors.AppError) {
 	query := `
 		INSERT INTO user_actions (user_id, learning_id, action_type, metadata, deleted_at)
-		VALUES ($1, $2, 'submit_retell', $3, NULL)
+		VALUES ($1, $2, 'submit_quiz', $3, NULL)
 		ON CONFLICT (learning_id, user_id, action_type)
 		DO UPDATE SET
-			metadata = EXCLUDED.metadata,
+			metadata = CASE
+				WHEN user_actions.deleted_at IS NULL THEN user_actions.metadata
+				ELSE EXCLUDED.metadata
+			END,
 			deleted_at = NULL,
 			updated_at = NOW()
-		RETURNING id
+		RETURNING id, user_id, learning_id, action_type, metadata, created_at, updated_at, deleted_at
 	`
 
-	var actionID string
-	if err := r.db.Pool.QueryRow(ctx, query, userID, videoID, metadata).Scan(&actionID); err != nil {
-		return "", errors.InternalWrap("failed to start retell action", err)
+	var a UserAction
+	err := r.db.Pool.QueryRow(ctx, query, userID, videoID, metadata).Scan(
+		&a.ID, &a.UserID, &a.LearningID, &a.ActionType, &a.Metadata, &a.CreatedAt, &a.UpdatedAt, &a.DeletedAt,
+	)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to start quiz action", err)
 	}
 
-	return actionID, nil
+	return &a, nil
 }
 
-func (r *videoRepository) ToggleSaved(ctx context.Context, videoID, userID string) (string, bool, *errors.AppError) {
+// StartRetell returns the user's active retell action, creating it with metadata when there is none.
+// The upsert locks the row, so concurrent first calls all get the same action and metadata.
+func (r *videoRepository) StartRetell(ctx context.Context, videoID, userID string, metadata json.RawMessage) (*UserAction, *errors.AppError) {
 	query := `
 		INSERT INTO user_actions (user_id, learning_id, action_type, metadata, deleted_at)
-		VALUES ($1, $2, 'quiz_saved', '{}'::jsonb, NULL)
+		VALUES ($1, $2, 'submit_retell', $3, NULL)
 		ON CONFLICT (learning_id, user_id, action_type)
 		DO UPDATE SET
-			deleted_at = CASE
-				WHEN user_actions.action_type = 'quiz_saved' AND user_actions.deleted_at IS NULL THEN NOW()
-				ELSE NULL
+			metadata = CASE
+				WHEN user_actions.deleted_at IS NULL THEN user_actions.metadata
+				ELSE EXCLUDED.metadata
 			END,
+			deleted_at = NULL,
 			updated_at = NOW()
-		RETURNING id, deleted_at IS NULL
+		RETURNING id, user_id, learning_id, action_type, metadata, created_at, updated_at, deleted_at
 	`
 
-	var actionID string
+	sealed, appErr := r.sealMetadata("submit_retell", metadata)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	var a UserAction
+	err := r.db.Pool.QueryRow(ctx, query, userID, videoID, sealed).Scan(
+		&a.ID, &a.UserID, &a.LearningID, &a.ActionType, &a.Metadata, &a.CreatedAt, &a.UpdatedAt, &a.DeletedAt,
+	)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to start retell action", err)
+	}
+
+	return &a, r.openAction(&a)
+}
+
+// ToggleSaved toggles the video in the user's favorites.
+func (r *videoRepository) ToggleSaved(ctx context.Context, videoID, userID string) (string, bool, *errors.AppError) {
+	var favoriteID string
 	var isSaved bool
-	if err := r.db.Pool.QueryRow(ctx, query, userID, videoID).Scan(&actionID, &isSaved); err != nil {
-		return "", false, errors.InternalWrap("failed to toggle video saved action", err)
+	if err := r.db.Pool.QueryRow(ctx, client.ToggleFavoriteSQL, userID, videoID, client.FavoriteVideo).Scan(&favoriteID, &isSaved); err != nil {
+		return "", false, errors.InternalWrap("failed to toggle video favorite", err)
 	}
 
-	return actionID, isSaved, nil
+	return favoriteID, isSaved, nil
 }
 
 func (r *videoRepository) ToggleTranscript(ctx context.Context, videoID, userID string) (string, bool, *errors.AppError) {
@@ -426,7 +850,7 @@ func (r *videoRepository) GetQuizAction(ctx context.Context, actionID string) (*
 		return nil, errors.InternalWrap("failed to get quiz action", err)
 	}
 
-	return &a, nil
+	return &a, r.openAction(&a)
 }
 
 func (r *videoRepository) GetActionByUserID(ctx context.Context, videoID, userID, actionType string) (*UserAction, bool, *errors.AppError) {
@@ -448,21 +872,138 @@ func (r *videoRepository) GetActionByUserID(ctx context.Context, videoID, userID
 		}
 		return nil, false, errors.InternalWrap("failed to get quiz action by user id", err)
 	}
+	if err := r.openAction(&a); err != nil {
+		return nil, false, err
+	}
 
 	return &a, true, nil
 }
 
-func (r *videoRepository) UpdateQuizAction(ctx context.Context, actionID string, metadata json.RawMessage) *errors.AppError {
+// UpdateQuizAction overwrites the action metadata, sealing the sensitive fields of its action type.
+func (r *videoRepository) UpdateQuizAction(ctx context.Context, actionID, actionType string, metadata json.RawMessage) *errors.AppError {
+	sealed, appErr := r.sealMetadata(actionType, metadata)
+	if appErr != nil {
+		return appErr
+	}
+
 	query := `
 		UPDATE user_actions
 		SET metadata = $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND action_type = $3
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query, metadata, actionID)
+	_, err := r.db.Pool.Exec(ctx, query, sealed, actionID, actionType)
 	if err != nil {
 		return errors.InternalWrap("failed to update quiz action metadata", err)
 	}
 
 	return nil
 }
+
+// UpdateActionMetadata applies update to the action metadata while holding a row lock,
+// so concurrent read-modify-write updates (e.g. two retell attempts) don't overwrite each other.
+func (r *videoRepository) UpdateActionMetadata(ctx context.Context, actionID string, update func(metadata json.RawMessage) (json.RawMessage, *errors.AppError)) *errors.AppError {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.InternalWrap("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	a := UserAction{ID: actionID}
+	err = tx.QueryRow(ctx, `SELECT action_type, metadata FROM user_actions WHERE id = $1 FOR UPDATE`, actionID).Scan(&a.ActionType, &a.Metadata)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("action not found")
+		}
+		return errors.InternalWrap("failed to lock action", err)
+	}
+	if appErr := r.openAction(&a); appErr != nil {
+		return appErr
+	}
+
+	updated, appErr := update(a.Metadata)
+	if appErr != nil {
+		return appErr
+	}
+	updated, appErr = r.sealMetadata(a.ActionType, updated)
+	if appErr != nil {
+		return appErr
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE user_actions SET metadata = $1, updated_at = NOW() WHERE id = $2`, updated, actionID); err != nil {
+		return errors.InternalWrap("failed to update action metadata", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.InternalWrap("failed to commit action metadata", err)
+	}
+
+	return nil
+}
+
+// ListRetellActionsWithAudioBefore returns retell actions holding at least one attempt audio submitted before cutoff.
+func (r *videoRepository) ListRetellActionsWithAudioBefore(ctx context.Context, cutoff time.Time, limit int) ([]*UserAction, *errors.AppError) {
+	query := `
+		SELECT id, user_id, learning_id, action_type, metadata, created_at, updated_at, deleted_at
+		FROM user_actions
+		WHERE action_type = 'submit_retell'
+		AND EXISTS (
+			SELECT 1 FROM jsonb_array_elements(COALESCE(metadata->'attempts', '[]'::jsonb)) AS attempt
+			WHERE COALESCE(attempt->>'audio_url', '') <> ''
+			AND (attempt->>'submitted_at')::timestamptz < $1
+		)
+		ORDER BY updated_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, cutoff, limit)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to list retell actions with audio", err)
+	}
+	defer rows.Close()
+
+	actions := make([]*UserAction, 0)
+	for rows.Next() {
+		var a UserAction
+		if err := rows.Scan(&a.ID, &a.UserID, &a.LearningID, &a.ActionType, &a.Metadata, &a.CreatedAt, &a.UpdatedAt, &a.DeletedAt); err != nil {
+			return nil, errors.InternalWrap("failed to scan retell action", err)
+		}
+		if err := r.openAction(&a); err != nil {
+			return nil, err
+		}
+		actions = append(actions, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to iterate retell actions", err)
+	}
+
+	return actions, nil
+}
+
+// listAppearancesQuery selects the turns of published dialogs visible to the user that use the
+// video's vocabulary, most recently published first ($1 video id, $2 user id, $3 limit).
+var listAppearancesQuery = `
+	SELECT k.scenario_id::text, l.content, k.term, k.turn_index
+	FROM item_scenario_links k
+	JOIN learning_items l ON l.id = k.scenario_id AND l.published_details IS NOT NULL
+	WHERE k.item_id = $1 AND ` + client.AudienceVisibleSQL("l", "$2") + `
+	ORDER BY l.published_at DESC, k.scenario_id, k.turn_index, k.term
+	LIMIT $3
+`
+
+func scanAppearances(rows pgx.Rows) ([]ScenarioAppearance, *errors.AppError) {
+	defer rows.Close()
+
+	appearances := make([]ScenarioAppearance, 0)
+	for rows.Next() {
+		var appearance ScenarioAppearance
+		if err := rows.Scan(&appearance.ScenarioID, &appearance.ScenarioTitle, &appearance.Term, &appearance.TurnIndex); err != nil {
+			return nil, errors.InternalWrap("failed to scan vocabulary appearance", err)
+		}
+		appearances = append(appearances, appearance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.InternalWrap("failed to list vocabulary appearances", err)
+	}
+
+	return appearances, nil
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -16,6 +17,19 @@ import (
 	"github.com/windfall/uwu_service/pkg/errors"
 )
 
+// parseExpectedVersion reads the optional If-Match header holding the video version the client edited.
+func parseExpectedVersion(r *http.Request) (*int, error) {
+	value := strings.Trim(r.Header.Get("If-Match"), `" `)
+	if value == "" {
+		return nil, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return nil, errors.Validation("If-Match must be a video version")
+	}
+	return &version, nil
+}
+
 // -------------------------------------------------------------------------
 // Upload Video Request
 // -------------------------------------------------------------------------
@@ -30,19 +44,20 @@ type UploadVideoRequest struct {
 	ThumbnailContentType string
 }
 
-// UploadVideoPayload is the payload struct for queue
+// UploadVideoPayload is the payload struct for queue. The uploaded files are staged in R2 under
+// the staging keys before the job is queued, so any instance can run it after a restart.
 type UploadVideoPayload struct {
 	UserID               string
 	VideoID              string
 	Language             string
 	VideoExt             string
 	VideoPath            string
-	VideoFile            multipart.File
+	VideoStagingKey      string
 	VideoContentType     string
 	VideoR2Path          string
 	ThumbnailExt         string
 	ThumbnailPath        string
-	ThumbnailFile        multipart.File
+	ThumbnailStagingKey  string
 	ThumbnailContentType string
 	ThumbnailR2Path      string
 	AudioPath            string
@@ -185,12 +200,12 @@ func (req *UploadVideoRequest) ToPayload() UploadVideoPayload {
 		Language:             req.Language,
 		VideoExt:             videoExt,
 		VideoPath:            videoPath,
-		VideoFile:            req.VideoFile,
+		VideoStagingKey:      stagedUploadKey(videoID, "video"+videoExt),
 		VideoContentType:     req.VideoContentType,
 		VideoR2Path:          videoR2Path,
 		ThumbnailExt:         thumbExt,
 		ThumbnailPath:        thumbPath,
-		ThumbnailFile:        req.ThumbnailFile,
+		ThumbnailStagingKey:  stagedUploadKey(videoID, "thumbnail"+thumbExt),
 		ThumbnailContentType: req.ThumbnailContentType,
 		ThumbnailR2Path:      thumbR2Path,
 		AudioPath:            audioPath,
@@ -205,6 +220,7 @@ func (req *UploadVideoRequest) ToPayload() UploadVideoPayload {
 type ListVideoContentsRequest struct {
 	Page     int
 	PageSize int
+	Filter   VideoFilter
 }
 
 // ListVideoContentsInput is the input struct for service
@@ -213,10 +229,11 @@ type ListVideoContentsInput struct {
 	PageSize int
 	Limit    int
 	Offset   int
+	Filter   VideoFilter
 }
 
-// Parse parse pagination params
-func (req *ListVideoContentsRequest) Parse(r *http.Request) {
+// Parse parse pagination and filter params
+func (req *ListVideoContentsRequest) Parse(r *http.Request) error {
 	pageStr := r.URL.Query().Get("page")
 	pageSizeStr := r.URL.Query().Get("page_size")
 
@@ -232,6 +249,45 @@ func (req *ListVideoContentsRequest) Parse(r *http.Request) {
 
 	req.Page = page
 	req.PageSize = pageSize
+
+	return req.parseFilter(r)
+}
+
+// parseFilter reads the optional filters: language, level, is_active, tag, created_after
+func (req *ListVideoContentsRequest) parseFilter(r *http.Request) error {
+	q := r.URL.Query()
+	req.Filter.Viewer = middleware.GetUserID(r.Context())
+
+	if language := strings.ToLower(q.Get("language")); language != "" {
+		if !AllowedLanguages[language] {
+			return errors.Validation("unsupported language")
+		}
+		req.Filter.Language = language
+	}
+
+	req.Filter.Level = strings.TrimSpace(q.Get("level")) // e.g. "CEFR B1", "JLPT N3"
+	req.Filter.Tag = strings.TrimSpace(q.Get("tag"))
+
+	if isActive := q.Get("is_active"); isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return errors.Validation("is_active must be true or false")
+		}
+		req.Filter.IsActive = &active
+	}
+
+	if createdAfter := q.Get("created_after"); createdAfter != "" {
+		t, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			// Accept a plain date as well
+			if t, err = time.Parse("2006-01-02", createdAfter); err != nil {
+				return errors.Validation("created_after must be an RFC 3339 timestamp or YYYY-MM-DD date")
+			}
+		}
+		req.Filter.CreatedAfter = &t
+	}
+
+	return nil
 }
 
 // ToInput convert ListVideoContentsRequest to ListVideoContentsInput
@@ -244,6 +300,7 @@ func (req *ListVideoContentsRequest) ToInput() ListVideoContentsInput {
 		PageSize: req.PageSize,
 		Limit:    limit,
 		Offset:   offset,
+		Filter:   req.Filter,
 	}
 }
 
@@ -366,7 +423,7 @@ func (req *SubmitGistQuizRequest) ParseAndValidate(r *http.Request) error {
 	}
 
 	// 3. Parse JSON Body
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := middleware.DecodeJSON(r, &req); err != nil {
 		return errors.Validation("invalid JSON body")
 	}
 
@@ -385,6 +442,436 @@ func (req *SubmitGistQuizRequest) ToInput() SubmitGistQuizInput {
 	}
 }
 
+// -------------------------------------------------------------------------
+// Update Transcript Request
+// -------------------------------------------------------------------------
+
+// TranscriptSegmentEdit replaces the text of a single transcript segment
+type TranscriptSegmentEdit struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+// UpdateTranscriptRequest is the HTTP request struct for editing a video transcript
+type UpdateTranscriptRequest struct {
+	UserID     string
+	VideoID    string
+	Segments   []TranscriptSegmentEdit `json:"segments"`
+	Regenerate bool                    `json:"regenerate"`
+	Version    *int
+}
+
+// UpdateTranscriptInput is the input struct for service
+type UpdateTranscriptInput struct {
+	UserID     string
+	VideoID    string
+	Segments   []TranscriptSegmentEdit
+	Regenerate bool
+	Version    *int // expected video version, nil skips the check
+}
+
+// RegenerateVideoDetailsPayload is the payload for regenerating details from an edited transcript
+type RegenerateVideoDetailsPayload struct {
+	UserID  string
+	VideoID string
+}
+
+func (req *UpdateTranscriptRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.VideoID = chi.URLParam(r, "videoID")
+	if req.VideoID == "" {
+		return errors.Validation("Video ID is required")
+	}
+
+	version, err := parseExpectedVersion(r)
+	if err != nil {
+		return err
+	}
+	req.Version = version
+
+	// 3. Parse JSON Body
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	if len(req.Segments) == 0 {
+		return errors.Validation("segments cannot be empty")
+	}
+	for _, seg := range req.Segments {
+		if seg.Index < 0 {
+			return errors.Validation("segment index must not be negative")
+		}
+		if strings.TrimSpace(seg.Text) == "" {
+			return errors.Validation("segment text cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+func (req *UpdateTranscriptRequest) ToInput() UpdateTranscriptInput {
+	return UpdateTranscriptInput{
+		UserID:     req.UserID,
+		VideoID:    req.VideoID,
+		Segments:   req.Segments,
+		Regenerate: req.Regenerate,
+		Version:    req.Version,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Bulk Videos Request
+// -------------------------------------------------------------------------
+
+// BulkVideosRequest is the HTTP request struct for bulk video actions
+type BulkVideosRequest struct {
+	UserID string
+	Action BulkAction `json:"action"`
+	IDs    []string   `json:"ids"`
+	Filter *struct {
+		Language     string     `json:"language"`
+		Level        string     `json:"level"`
+		IsActive     *bool      `json:"is_active"`
+		Tag          string     `json:"tag"`
+		CreatedAfter *time.Time `json:"created_after"`
+	} `json:"filter"`
+	Tags []string `json:"tags"`
+}
+
+// BulkVideosInput is the input struct for service
+type BulkVideosInput struct {
+	UserID string
+	Action BulkAction
+	IDs    []string
+	Filter *VideoFilter
+	Tags   []string
+}
+
+func (req *BulkVideosRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse JSON Body
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	switch req.Action {
+	case BulkActivate, BulkDeactivate, BulkDelete:
+	case BulkRetag:
+		if req.Tags == nil {
+			return errors.Validation("tags are required for retag")
+		}
+	default:
+		return errors.Validation("action must be one of activate, deactivate, delete, retag")
+	}
+
+	// 3. Exactly one target: ids or filter
+	if (len(req.IDs) == 0) == (req.Filter == nil) {
+		return errors.Validation("provide either ids or filter")
+	}
+	if len(req.IDs) > MaxBulkItems {
+		return errors.Validation(fmt.Sprintf("at most %d ids per request", MaxBulkItems))
+	}
+	for _, id := range req.IDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return errors.Validation(fmt.Sprintf("invalid video id: %s", id))
+		}
+	}
+	if req.Filter != nil && req.Filter.Language != "" && !AllowedLanguages[strings.ToLower(req.Filter.Language)] {
+		return errors.Validation("unsupported language")
+	}
+
+	return nil
+}
+
+func (req *BulkVideosRequest) ToInput() BulkVideosInput {
+	input := BulkVideosInput{
+		UserID: req.UserID,
+		Action: req.Action,
+		IDs:    req.IDs,
+		Tags:   req.Tags,
+	}
+	if req.Filter != nil {
+		input.Filter = &VideoFilter{
+			Language:     strings.ToLower(req.Filter.Language),
+			Level:        strings.TrimSpace(req.Filter.Level),
+			IsActive:     req.Filter.IsActive,
+			Tag:          strings.TrimSpace(req.Filter.Tag),
+			CreatedAfter: req.Filter.CreatedAfter,
+		}
+	}
+	return input
+}
+
+// -------------------------------------------------------------------------
+// Patch Video Request
+// -------------------------------------------------------------------------
+
+// patchableVideoFields are the top-level fields accepted by PATCH /videos/{videoID}
+var patchableVideoFields = map[string]bool{
+	"content":  true,
+	"tags":     true,
+	"details":  true,
+	"metadata": true,
+}
+
+// PatchVideoRequest is the HTTP request struct for a JSON Merge Patch (RFC 7386) of a video
+type PatchVideoRequest struct {
+	UserID  string
+	VideoID string
+	Version *int
+	Patch   map[string]json.RawMessage
+}
+
+// PatchVideoInput is the input struct for service
+type PatchVideoInput struct {
+	UserID   string
+	VideoID  string
+	Version  *int            // expected video version, nil skips the check
+	Content  *string         // nil leaves content unchanged
+	Tags     []string        // replaces tags when TagsSet
+	TagsSet  bool            // tags were present in the patch
+	Details  json.RawMessage // merge patch for details, nil leaves it unchanged
+	Metadata json.RawMessage // merge patch for metadata, nil leaves it unchanged
+}
+
+func (req *PatchVideoRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.VideoID = chi.URLParam(r, "videoID")
+	if req.VideoID == "" {
+		return errors.Validation("Video ID is required")
+	}
+
+	version, err := parseExpectedVersion(r)
+	if err != nil {
+		return err
+	}
+	req.Version = version
+
+	// 3. Parse JSON Merge Patch body
+	if err := middleware.DecodeJSON(r, &req.Patch); err != nil {
+		return errors.Validation("invalid JSON merge patch body")
+	}
+	if len(req.Patch) == 0 {
+		return errors.Validation("patch cannot be empty")
+	}
+	for field := range req.Patch {
+		if !patchableVideoFields[field] {
+			return errors.Validation(fmt.Sprintf("field '%s' cannot be patched", field))
+		}
+	}
+
+	return nil
+}
+
+func (req *PatchVideoRequest) ToInput() (PatchVideoInput, error) {
+	input := PatchVideoInput{
+		UserID:  req.UserID,
+		VideoID: req.VideoID,
+		Version: req.Version,
+	}
+
+	if raw, ok := req.Patch["content"]; ok {
+		var content string
+		if err := json.Unmarshal(raw, &content); err != nil || strings.TrimSpace(content) == "" {
+			return input, errors.Validation("content must be a non-empty string")
+		}
+		input.Content = &content
+	}
+
+	if raw, ok := req.Patch["tags"]; ok {
+		// Arrays are replaced as a whole; null clears the tags
+		var tags []string
+		if err := json.Unmarshal(raw, &tags); err != nil {
+			return input, errors.Validation("tags must be an array of strings")
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+		input.Tags = tags
+		input.TagsSet = true
+	}
+
+	if raw, ok := req.Patch["details"]; ok {
+		if string(raw) == "null" {
+			return input, errors.Validation("details cannot be removed")
+		}
+		input.Details = raw
+	}
+
+	if raw, ok := req.Patch["metadata"]; ok {
+		input.Metadata = raw
+	}
+
+	return input, nil
+}
+
+// -------------------------------------------------------------------------
+// Update Retell Settings Request
+// -------------------------------------------------------------------------
+
+// UpdateRetellSettingsRequest is the HTTP request struct for changing a lesson's retell settings
+type UpdateRetellSettingsRequest struct {
+	UserID         string
+	VideoID        string
+	MaxAttempts    int     `json:"max_attempts"`
+	PassPercentage float64 `json:"pass_percentage"`
+	ResetAttempts  bool    `json:"reset_attempts"`
+	Version        *int
+}
+
+// UpdateRetellSettingsInput is the input struct for service
+type UpdateRetellSettingsInput struct {
+	UserID   string
+	VideoID  string
+	Settings RetellSettings
+	Version  *int // expected video version, nil skips the check
+}
+
+func (req *UpdateRetellSettingsRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.VideoID = chi.URLParam(r, "videoID")
+	if req.VideoID == "" {
+		return errors.Validation("Video ID is required")
+	}
+
+	version, err := parseExpectedVersion(r)
+	if err != nil {
+		return err
+	}
+	req.Version = version
+
+	// 3. Parse JSON Body
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	if req.MaxAttempts < 1 || req.MaxAttempts > 20 {
+		return errors.Validation("max_attempts must be between 1 and 20")
+	}
+	if req.PassPercentage <= 0 || req.PassPercentage > 100 {
+		return errors.Validation("pass_percentage must be greater than 0 and at most 100")
+	}
+
+	return nil
+}
+
+func (req *UpdateRetellSettingsRequest) ToInput() UpdateRetellSettingsInput {
+	return UpdateRetellSettingsInput{
+		UserID:  req.UserID,
+		VideoID: req.VideoID,
+		Settings: RetellSettings{
+			MaxAttempts:    req.MaxAttempts,
+			PassPercentage: req.PassPercentage,
+			ResetAttempts:  req.ResetAttempts,
+		},
+		Version: req.Version,
+	}
+}
+
+// -------------------------------------------------------------------------
+// Retell Key Point Requests
+// -------------------------------------------------------------------------
+
+// RetellPointRequest is the HTTP request struct for retell key point endpoints
+type RetellPointRequest struct {
+	UserID  string
+	VideoID string
+	Index   int
+	Text    string  `json:"text"`
+	Weight  float64 `json:"weight"`
+	Order   []int   `json:"order"`
+	Version *int
+}
+
+// RetellPointInput is the input struct for service
+type RetellPointInput struct {
+	UserID  string
+	VideoID string
+	Index   int
+	Text    string
+	Weight  float64
+	Order   []int
+	Version *int // expected video version, nil skips the check
+}
+
+// ParseAndValidate reads the auth user, video ID and (when withIndex) the key point index.
+func (req *RetellPointRequest) ParseAndValidate(r *http.Request, withIndex bool) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.VideoID = chi.URLParam(r, "videoID")
+	if req.VideoID == "" {
+		return errors.Validation("Video ID is required")
+	}
+
+	version, err := parseExpectedVersion(r)
+	if err != nil {
+		return err
+	}
+	req.Version = version
+
+	if withIndex {
+		index, err := strconv.Atoi(chi.URLParam(r, "pointIndex"))
+		if err != nil || index < 0 {
+			return errors.Validation("invalid key point index")
+		}
+		req.Index = index
+	}
+
+	return nil
+}
+
+// ParseBody reads the JSON body of add / update / reorder requests.
+func (req *RetellPointRequest) ParseBody(r *http.Request) error {
+	if err := middleware.DecodeJSON(r, &req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+	// Weight defaults to 1 when omitted
+	if req.Weight == 0 {
+		req.Weight = 1
+	}
+	return nil
+}
+
+func (req *RetellPointRequest) ToInput() RetellPointInput {
+	return RetellPointInput{
+		UserID:  req.UserID,
+		VideoID: req.VideoID,
+		Index:   req.Index,
+		Text:    req.Text,
+		Weight:  req.Weight,
+		Order:   req.Order,
+		Version: req.Version,
+	}
+}
+
 // -------------------------------------------------------------------------
 // Submit Retell Request
 // -------------------------------------------------------------------------
@@ -398,17 +885,18 @@ type SubmitRetellRequest struct {
 	AudioHeader *multipart.FileHeader
 }
 
-// SubmitRetellPayload is the payload struct for service
+// SubmitRetellPayload is the payload struct for service. The recording is staged in R2 under
+// AudioStagingKey before the job is queued.
 type SubmitRetellPayload struct {
-	UserID       string
-	VideoID      string
-	AttemptID    string
-	Language     string
-	AudioFile    multipart.File
-	AudioR2Path  string
-	AudioM4aPath string
-	AudioWavPath string
-	AudioType    string
+	UserID          string
+	VideoID         string
+	AttemptID       string
+	Language        string
+	AudioStagingKey string
+	AudioR2Path     string
+	AudioM4aPath    string
+	AudioWavPath    string
+	AudioType       string
 }
 
 func (req *SubmitRetellRequest) ParseAndValidate(r *http.Request) error {
@@ -440,30 +928,147 @@ func (req *SubmitRetellRequest) ParseAndValidate(r *http.Request) error {
 	if err != nil {
 		return errors.Validation("audio file is required (form field: 'audio')")
 	}
-	defer audioFile.Close()
-
 	req.AudioFile = audioFile
 	req.AudioHeader = audioHeader
 	return nil
 }
 
+// Close closes the uploaded recording once the handler is done with it.
+func (req *SubmitRetellRequest) Close() {
+	if req.AudioFile != nil {
+		req.AudioFile.Close()
+	}
+}
+
+// PurgeRetellAudioPayload is the payload for the retell audio retention purge job
+type PurgeRetellAudioPayload struct {
+	Retention time.Duration
+}
+
+// -------------------------------------------------------------------------
+// Expand Vocabulary Request
+// -------------------------------------------------------------------------
+
+// Expansion limits
+const (
+	defaultExpandCount  = 3
+	maxExpandCount      = 5
+	maxWordExamples     = 20
+	maxWordCollocations = 20
+)
+
+// ExpandVocabularyRequest is the HTTP request struct for generating more material for a word
+type ExpandVocabularyRequest struct {
+	UserID       string `json:"-"`
+	ItemID       string `json:"-"`
+	Word         string `json:"word"`
+	Examples     *int   `json:"examples"`
+	Collocations *int   `json:"collocations"`
+	// Voice every new example sentence
+	Audio   bool `json:"audio"`
+	Version *int `json:"-"`
+}
+
+// ExpandVocabularyInput is the input struct for service
+type ExpandVocabularyInput struct {
+	UserID       string
+	ItemID       string
+	Word         string
+	Examples     int
+	Collocations int
+	Audio        bool
+	Version      *int
+}
+
+func (req *ExpandVocabularyRequest) ParseAndValidate(r *http.Request) error {
+	// 1. Get user ID from auth context
+	req.UserID = middleware.GetUserID(r.Context())
+	if req.UserID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+
+	// 2. Parse URL Params
+	req.ItemID = chi.URLParam(r, "itemID")
+	if _, err := uuid.Parse(req.ItemID); err != nil {
+		return errors.Validation("invalid learning item id")
+	}
+
+	version, err := parseExpectedVersion(r)
+	if err != nil {
+		return err
+	}
+	req.Version = version
+
+	// 3. Parse JSON Body
+	if err := middleware.DecodeJSON(r, req); err != nil {
+		return errors.Validation("invalid JSON body")
+	}
+
+	req.Word = strings.TrimSpace(req.Word)
+	if req.Word == "" {
+		return errors.Validation("word is required")
+	}
+	for _, count := range []*int{req.Examples, req.Collocations} {
+		if count != nil && (*count < 0 || *count > maxExpandCount) {
+			return errors.Validation("examples and collocations must be between 0 and 5")
+		}
+	}
+	if req.Examples != nil && req.Collocations != nil && *req.Examples == 0 && *req.Collocations == 0 {
+		return errors.Validation("examples or collocations must be greater than 0")
+	}
+
+	return nil
+}
+
+func (req *ExpandVocabularyRequest) ToInput() ExpandVocabularyInput {
+	input := ExpandVocabularyInput{
+		UserID:       req.UserID,
+		ItemID:       req.ItemID,
+		Word:         req.Word,
+		Examples:     defaultExpandCount,
+		Collocations: defaultExpandCount,
+		Audio:        req.Audio,
+		Version:      req.Version,
+	}
+	if req.Examples != nil {
+		input.Examples = *req.Examples
+	}
+	if req.Collocations != nil {
+		input.Collocations = *req.Collocations
+	}
+	return input
+}
+
+// BackfillFrequencyPayload is the payload for the vocabulary frequency rank backfill job
+type BackfillFrequencyPayload struct{}
+
+// retellAudioKey returns the R2 key of a retell attempt recording
+func retellAudioKey(attemptID string) string {
+	return fmt.Sprintf("retell-story/%s.m4a", attemptID)
+}
+
+// stagedUploadKey returns the R2 key an uploaded file waits under until its job has processed it
+func stagedUploadKey(batchID, name string) string {
+	return fmt.Sprintf("uploads/%s/%s", batchID, name)
+}
+
 func (req *SubmitRetellRequest) ToPayload() SubmitRetellPayload {
 	attemptID := uuid.New().String()
 
-	audioR2Path := fmt.Sprintf("retell-story/%s.m4a", attemptID)
+	audioR2Path := retellAudioKey(attemptID)
 	audioWavPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s.wav", attemptID))
 	audioM4aPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s.m4a", attemptID))
 
 	return SubmitRetellPayload{
-		AttemptID:    attemptID,
-		UserID:       req.UserID,
-		VideoID:      req.VideoID,
-		Language:     req.Language,
-		AudioFile:    req.AudioFile,
-		AudioR2Path:  audioR2Path,
-		AudioWavPath: audioWavPath,
-		AudioM4aPath: audioM4aPath,
-		AudioType:    "audio/m4a",
+		AttemptID:       attemptID,
+		UserID:          req.UserID,
+		VideoID:         req.VideoID,
+		Language:        req.Language,
+		AudioStagingKey: stagedUploadKey(attemptID, "recording"),
+		AudioR2Path:     audioR2Path,
+		AudioWavPath:    audioWavPath,
+		AudioM4aPath:    audioM4aPath,
+		AudioType:       "audio/m4a",
 	}
 }
 
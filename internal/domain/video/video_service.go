@@ -3,12 +3,17 @@ package video
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"os"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/pkg/errors"
 	"github.com/windfall/uwu_service/pkg/response"
 )
@@ -19,6 +24,19 @@ type VideoService struct {
 	aiRepo    AIRepository
 	batchRepo BatchRepository
 	fileRepo  FileRepository
+	audioRepo AudioRepository
+	opts      VideoOptions
+}
+
+// VideoOptions toggles optional stages of the video pipeline.
+type VideoOptions struct {
+	ExtractVocabulary   bool                  // extract vocabulary and key phrases after details are generated
+	ChaptersMinDuration time.Duration         // generate chapters for videos at least this long, 0 disables
+	RetellDefaults      RetellSettings        // used when a lesson has no retell settings of its own
+	RetellMatchCoverage float64               // key point coverage (0-1) credited without the AI, 0 disables
+	Timeouts            client.TimeoutPolicy  // per-feature deadlines for AI calls
+	Romanizer           *client.Romanizer     // checks vocabulary readings on save, nil keeps the AI readings
+	WordFrequency       *client.WordFrequency // ranks vocabulary by word frequency, nil leaves it unranked
 }
 
 // VideoDetailsResponse is returned for video details.
@@ -33,6 +51,39 @@ type ListVideoContentsResponse struct {
 	Meta *response.MetaPagination `json:"meta"`
 }
 
+// UpdateTranscriptResponse is returned after editing a transcript.
+type UpdateTranscriptResponse struct {
+	VideoID      string              `json:"video_id"`
+	Revision     int                 `json:"revision"`
+	Transcript   string              `json:"transcript"`
+	Segments     []TranscriptSegment `json:"segments"`
+	Regenerating bool                `json:"regenerating"`
+	Version      int                 `json:"version"`
+}
+
+// BulkVideosResponse is returned after a bulk video action.
+type BulkVideosResponse struct {
+	Action    BulkAction       `json:"action"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// VideoChaptersResponse is returned for video chapters.
+type VideoChaptersResponse struct {
+	VideoID  string         `json:"video_id"`
+	Chapters []VideoChapter `json:"chapters"`
+}
+
+// ExpandVocabularyResponse is returned after expanding a vocabulary item.
+type ExpandVocabularyResponse struct {
+	ItemID       string                  `json:"item_id"`
+	Item         VocabularyItem          `json:"item"`
+	Examples     []VocabularyExample     `json:"examples"`
+	Collocations []VocabularyCollocation `json:"collocations"`
+	Version      int                     `json:"version"`
+}
+
 // ToggleSavedResponse is returned after toggling saved state.
 type ToggleSavedResponse struct {
 	ActionID string `json:"action_id"`
@@ -56,6 +107,7 @@ type StartRetellResponse struct {
 	VideoID     string          `json:"video_id"`
 	UserID      string          `json:"user_id"`
 	RetellStory interface{}     `json:"retell_story"`
+	Settings    RetellSettings  `json:"settings"`
 	Attempts    []RetellAttempt `json:"attempts"`
 }
 
@@ -70,19 +122,41 @@ type ToggleTranscriptResponse struct {
 type VideoGistQuiz []gistQuizQuestion
 
 type VideoRetell struct {
-	KeyPoints     []string `json:"key_points"`
-	RetellExample string   `json:"retell_example"`
+	KeyPoints     []string  `json:"key_points"`
+	Weights       []float64 `json:"weights,omitempty"`
+	RetellExample string    `json:"retell_example"`
+}
+
+// RetellKeyPoint is a single authored retell key point
+type RetellKeyPoint struct {
+	Index  int     `json:"index"`
+	Text   string  `json:"text"`
+	Weight float64 `json:"weight"`
 }
 
+// Retell key point limits
+const (
+	maxRetellKeyPoints      = 20
+	maxRetellKeyPointWeight = 10
+)
+
 // GistQuizMetadata represents the metadata for gist quiz actions
 type GistQuizMetadata struct {
 	GistQuiz *VideoGistQuiz    `json:"gist_quiz,omitempty"`
 	Attempts []GistQuizAttempt `json:"attempts"`
 }
 
+// RetellSettings configures retell attempts for a lesson
+type RetellSettings struct {
+	MaxAttempts    int     `json:"max_attempts"`    // evaluated attempts allowed
+	PassPercentage float64 `json:"pass_percentage"` // score (0-100) needed to pass
+	ResetAttempts  bool    `json:"reset_attempts"`  // keep accepting attempts after the limit, dropping the oldest
+}
+
 // RetellStoryMetadata represents the metadata for retell story actions
 type RetellStoryMetadata struct {
 	RetellStory *VideoRetell    `json:"retell_story,omitempty"`
+	Settings    *RetellSettings `json:"settings,omitempty"` // snapshot taken when the retell started
 	Attempts    []RetellAttempt `json:"attempts"`
 }
 
@@ -94,15 +168,26 @@ type GistQuizAttempt struct {
 	SubmittedAt time.Time    `json:"submitted_at"`
 }
 
+// Retell attempt statuses
+const (
+	RETELL_EVALUATED         = "evaluated"
+	RETELL_EVALUATION_FAILED = "evaluation_failed" // no provider returned a valid evaluation
+)
+
+// maxFailedRetellAttempts is how many failed evaluations are kept per retell action
+const maxFailedRetellAttempts = 3
+
 // RetellAttempt represents a single attempt at the audio retell story
 type RetellAttempt struct {
 	AttemptID        string    `json:"attempt_id"`
+	Status           string    `json:"status,omitempty"`
 	AudioURL         string    `json:"audio_url"`
 	MimeType         string    `json:"mimeType"`
 	Transcript       string    `json:"transcript"`
 	RetellScore      float64   `json:"retell_score"`
 	MatchesKeyPoints []string  `json:"matches_key_points"`
 	RetellAnalysis   string    `json:"retell_analysis"`
+	Passed           bool      `json:"passed"`
 	SubmittedAt      time.Time `json:"submitted_at"`
 }
 
@@ -122,19 +207,21 @@ type gistQuizQuestion struct {
 }
 
 // NewVideoService creates a new VideoService.
-func NewVideoService(videoRepo VideoRepository, aiRepo AIRepository, batchRepo BatchRepository, fileRepo FileRepository) *VideoService {
+func NewVideoService(videoRepo VideoRepository, aiRepo AIRepository, batchRepo BatchRepository, fileRepo FileRepository, audioRepo AudioRepository, opts VideoOptions) *VideoService {
 	return &VideoService{
 		videoRepo: videoRepo,
 		aiRepo:    aiRepo,
 		batchRepo: batchRepo,
 		fileRepo:  fileRepo,
+		audioRepo: audioRepo,
+		opts:      opts,
 	}
 }
 
 // List Video Contents
 func (s *VideoService) ListVideoContents(ctx context.Context, input ListVideoContentsInput) (*ListVideoContentsResponse, *errors.AppError) {
 	// 1. Get video contents from database
-	videos, total, err := s.videoRepo.ListVideos(ctx, input.Limit, input.Offset)
+	videos, total, err := s.videoRepo.ListFiltered(ctx, input.Filter, input.Limit, input.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -188,20 +275,45 @@ func (s *VideoService) CreateVideoContent(ctx context.Context, input UploadVideo
 	}, nil
 }
 
-// Worker: ProcessUploadVideo handles the background upload flow for videos.
-func (s *VideoService) ProcessUploadVideo(ctx context.Context, payload UploadVideoPayload) {
+// StageUploadVideo stages the uploaded video and thumbnail in R2 for the upload job.
+func (s *VideoService) StageUploadVideo(ctx context.Context, payload UploadVideoPayload, video, thumbnail multipart.File) *errors.AppError {
+	if err := s.fileRepo.StageToR2(ctx, video, payload.VideoStagingKey, payload.VideoContentType); err != nil {
+		return err
+	}
+	return s.fileRepo.StageToR2(ctx, thumbnail, payload.ThumbnailStagingKey, payload.ThumbnailContentType)
+}
+
+// Worker: ProcessUploadVideo handles the background upload flow for videos. It returns an error
+// when a job of the batch failed, so the queue runs the upload again from the staged files.
+func (s *VideoService) ProcessUploadVideo(ctx context.Context, payload UploadVideoPayload) *errors.AppError {
 	var videoURL, thumbnailURL string
 	var videoDetails *VideoDetails
+	var transcriptQuality *TranscriptQuality
+
+	defer os.Remove(payload.AudioPath)
+	defer os.Remove(payload.VideoPath)
+	defer os.Remove(payload.ThumbnailPath)
+
+	// Fetch the staged files; the job may run on another instance than the request
+	_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_VIDEO, BATCH_PROCESSING, "")
+	_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_THUMBNAIL, BATCH_PROCESSING, "")
+	if err := s.fileRepo.DownloadFromR2(ctx, payload.VideoStagingKey, payload.VideoPath); err != nil {
+		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_VIDEO, BATCH_FAILED, err.GetMessage())
+		return err
+	}
+	if err := s.fileRepo.DownloadFromR2(ctx, payload.ThumbnailStagingKey, payload.ThumbnailPath); err != nil {
+		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_THUMBNAIL, BATCH_FAILED, err.GetMessage())
+		return err
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(3)
 
 	// Job A1: Upload Video to R2
-	go func() {
+	client.TrackGo(ctx, "upload_video.video", func() {
 		defer wg.Done()
-		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_VIDEO, BATCH_PROCESSING, "")
 
-		url, err := s.fileRepo.UploadToR2(ctx, payload.VideoFile, payload.VideoR2Path, payload.VideoPath, payload.VideoContentType)
+		url, err := s.fileRepo.UploadReaderToR2(ctx, payload.VideoPath, payload.VideoR2Path, payload.VideoContentType)
 		if err != nil {
 			_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_VIDEO, BATCH_FAILED, err.Error())
 			return
@@ -209,14 +321,13 @@ func (s *VideoService) ProcessUploadVideo(ctx context.Context, payload UploadVid
 
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_VIDEO, BATCH_COMPLETED, "")
 		videoURL = url
-	}()
+	})
 
 	// Job A2: Upload Thumbnail to R2
-	go func() {
+	client.TrackGo(ctx, "upload_video.thumbnail", func() {
 		defer wg.Done()
-		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_THUMBNAIL, BATCH_PROCESSING, "")
 
-		url, err := s.fileRepo.UploadToR2(ctx, payload.ThumbnailFile, payload.ThumbnailR2Path, payload.ThumbnailPath, payload.ThumbnailContentType)
+		url, err := s.fileRepo.UploadReaderToR2(ctx, payload.ThumbnailPath, payload.ThumbnailR2Path, payload.ThumbnailContentType)
 		if err != nil {
 			_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_THUMBNAIL, BATCH_FAILED, err.Error())
 			return
@@ -224,10 +335,10 @@ func (s *VideoService) ProcessUploadVideo(ctx context.Context, payload UploadVid
 
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_UPLOAD_THUMBNAIL, BATCH_COMPLETED, "")
 		thumbnailURL = url
-	}()
+	})
 
 	// Job B: Transcribe & Details
-	go func() {
+	client.TrackGo(ctx, "upload_video.transcript", func() {
 		defer wg.Done()
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_TRANSCRIPT, BATCH_PROCESSING, "")
 
@@ -237,35 +348,71 @@ func (s *VideoService) ProcessUploadVideo(ctx context.Context, payload UploadVid
 			return
 		}
 
-		transcript, err := s.aiRepo.GenerateVideoTranscript(ctx, payload.AudioPath, payload.Language)
+		// Normalize loudness before transcription (keep the raw audio if ffmpeg fails)
+		_ = s.fileRepo.NormalizeAudio(ctx, payload.AudioPath)
+
+		callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Transcription)
+		transcript, quality, err := s.aiRepo.GenerateCheckedVideoTranscript(callCtx, payload.AudioPath, payload.Language)
+		cancel()
 		if err != nil {
 			_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_TRANSCRIPT, BATCH_FAILED, err.Error())
 			_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_DETAILS, BATCH_FAILED, "skipped: generate details failed")
 			return
 		}
+		transcriptQuality = quality
+
+		// Surface transcript confidence / language mismatch in the batch result
+		qualityJSON, _ := json.Marshal(quality)
+		_ = s.batchRepo.SetBatchResult(ctx, payload.VideoID, qualityJSON)
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_TRANSCRIPT, BATCH_COMPLETED, "")
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_DETAILS, BATCH_PROCESSING, "")
 
-		details, err := s.aiRepo.GenerateVideoDetails(ctx, transcript)
+		callCtx, cancel = client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+		details, err := s.aiRepo.GenerateVideoDetails(callCtx, transcript)
+		cancel()
 		if err != nil {
 			_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_DETAILS, BATCH_FAILED, err.Error())
 			return
 		}
+		// Optional stages: vocabulary / chapters (the video is still saved if these fail)
+		if s.opts.ExtractVocabulary {
+			callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+			vocab, err := s.aiRepo.ExtractVocabulary(callCtx, details.Transcript, details.Level)
+			cancel()
+			if err == nil {
+				s.checkReadings(ctx, vocab, details.Language)
+				s.rankVocabulary(vocab, details.Language)
+				s.rankVocabulary(vocab, details.Language)
+				details.Vocabulary = vocab.Vocabulary
+				details.KeyPhrases = vocab.KeyPhrases
+			}
+		}
+		if s.shouldGenerateChapters(transcript.Duration) {
+			callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+			chapters, err := s.aiRepo.GenerateChapters(callCtx, details.Segments)
+			cancel()
+			if err == nil {
+				details.Chapters = chapters
+			}
+		}
+
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_GENERATE_DETAILS, BATCH_COMPLETED, "")
 		videoDetails = details
-	}()
+	})
 
 	// Wait for all jobs to complete
 	wg.Wait()
-	defer os.Remove(payload.AudioPath)
-	defer os.Remove(payload.VideoPath)
-	defer os.Remove(payload.ThumbnailPath)
+	if videoURL == "" || thumbnailURL == "" || videoDetails == nil {
+		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_SAVE_VIDEO, BATCH_FAILED, "skipped: a previous job failed")
+		return errors.Internal("upload video: a job of the batch failed")
+	}
 
 	// Update video content
 	_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_SAVE_VIDEO, BATCH_PROCESSING, "")
 
 	videoDetails.VideoURL = videoURL
 	videoDetails.ThumbnailURL = thumbnailURL
+	videoDetails.TranscriptQuality = transcriptQuality
 
 	detailsJSON, _ := json.Marshal(videoDetails)
 	tagsJSON, _ := json.Marshal(videoDetails.Tags)
@@ -294,21 +441,27 @@ func (s *VideoService) ProcessUploadVideo(ctx context.Context, payload UploadVid
 		Tags:      tagsJSON,
 		Metadata:  metadataJSON,
 		CreatedBy: payload.UserID,
-		IsActive:  true,
+		// Flagged transcripts (low confidence / language mismatch) stay inactive until reviewed
+		IsActive: transcriptQuality == nil || !transcriptQuality.Flagged(),
 	}
 
 	if err := s.videoRepo.UpdateVideo(ctx, learningItem); err != nil {
 		_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_SAVE_VIDEO, BATCH_FAILED, err.GetMessage())
-		return
+		return err
 	}
 
 	_ = s.batchRepo.UpdateUploadVideoJob(ctx, payload.VideoID, PROCESS_SAVE_VIDEO, BATCH_COMPLETED, "")
+
+	// The staged files are no longer needed once the video is saved
+	_ = s.fileRepo.DeleteFromR2(ctx, payload.VideoStagingKey)
+	_ = s.fileRepo.DeleteFromR2(ctx, payload.ThumbnailStagingKey)
+	return nil
 }
 
 // Get Video Details
 func (s *VideoService) GetVideoDetails(ctx context.Context, videoID, userID string) (*VideoDetailsResponse, *errors.AppError) {
-	// Get video from database
-	learningItem, err := s.videoRepo.GetVideo(ctx, videoID, userID)
+	// Get video and its dialog appearances from database
+	learningItem, err := s.videoRepo.GetVideoDetails(ctx, videoID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -411,14 +564,17 @@ func (s *VideoService) StartQuiz(ctx context.Context, input StartQuizInput) (*St
 	_ = json.Unmarshal(gistJSON, &metadata.GistQuiz)
 	metadataJSON, _ := json.Marshal(metadata)
 
-	// 4. Create action record
-	actionID, err := s.videoRepo.StartQuiz(ctx, videoID, userID, metadataJSON)
+	// 4. Get or create action record (a concurrent call may have created it first)
+	action, err = s.videoRepo.StartQuiz(ctx, videoID, userID, metadataJSON)
 	if err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
+		return nil, errors.InternalWrap("failed to parse quiz metadata", err)
+	}
 
 	return &StartQuizResponse{
-		ActionID: actionID,
+		ActionID: action.ID,
 		VideoID:  videoID,
 		UserID:   userID,
 		GistQuiz: metadata.GistQuiz,
@@ -458,6 +614,7 @@ func (s *VideoService) StartRetell(ctx context.Context, input StartRetellInput)
 			VideoID:     videoID,
 			UserID:      userID,
 			RetellStory: metadata.RetellStory,
+			Settings:    s.actionRetellSettings(&metadata),
 			Attempts:    metadata.Attempts,
 		}, nil
 	}
@@ -473,29 +630,85 @@ func (s *VideoService) StartRetell(ctx context.Context, input StartRetellInput)
 		return nil, errors.InternalWrap("failed to parse video details", err)
 	}
 
-	// 3. Create initial metadata snapshot
+	// 3. Create initial metadata snapshot (settings are frozen so later edits don't affect this session)
+	settings := s.lessonRetellSettings(&videoDetails)
 	metadata := RetellStoryMetadata{
+		Settings: &settings,
 		Attempts: []RetellAttempt{},
 	}
 	retellJSON, _ := json.Marshal(videoDetails.RetellStory)
 	_ = json.Unmarshal(retellJSON, &metadata.RetellStory)
 	metadataJSON, _ := json.Marshal(metadata)
 
-	// 4. Create action record
-	actionID, err := s.videoRepo.StartRetell(ctx, videoID, userID, metadataJSON)
+	// 4. Get or create action record (a concurrent call may have created it first)
+	action, err = s.videoRepo.StartRetell(ctx, videoID, userID, metadataJSON)
 	if err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
+		return nil, errors.InternalWrap("failed to parse retell metadata", err)
+	}
 
 	return &StartRetellResponse{
-		ActionID:    actionID,
+		ActionID:    action.ID,
 		VideoID:     videoID,
 		UserID:      userID,
 		RetellStory: metadata.RetellStory,
+		Settings:    s.actionRetellSettings(&metadata),
 		Attempts:    metadata.Attempts,
 	}, nil
 }
 
+// lessonRetellSettings returns the lesson's retell settings, falling back to the defaults.
+func (s *VideoService) lessonRetellSettings(details *VideoDetails) RetellSettings {
+	if details.RetellSettings != nil {
+		return *details.RetellSettings
+	}
+	return s.opts.RetellDefaults
+}
+
+// actionRetellSettings returns the settings snapshot of a retell action (legacy actions use the defaults).
+func (s *VideoService) actionRetellSettings(metadata *RetellStoryMetadata) RetellSettings {
+	if metadata.Settings != nil {
+		return *metadata.Settings
+	}
+	return s.opts.RetellDefaults
+}
+
+// CheckRetellAttempts rejects a submission once the attempt limit is used up,
+// unless the lesson allows attempts to be reused.
+func (s *VideoService) CheckRetellAttempts(ctx context.Context, input SubmitRetellPayload) *errors.AppError {
+	action, exists, err := s.videoRepo.GetActionByUserID(ctx, input.VideoID, input.UserID, "submit_retell")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.Validation("retell has not been started")
+	}
+
+	var metadata RetellStoryMetadata
+	if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
+		return errors.InternalWrap("failed to parse retell metadata", err)
+	}
+
+	settings := s.actionRetellSettings(&metadata)
+	if settings.ResetAttempts {
+		return nil
+	}
+
+	used := 0
+	for _, attempt := range metadata.Attempts {
+		if attempt.Status != RETELL_EVALUATION_FAILED {
+			used++
+		}
+	}
+	if used >= settings.MaxAttempts {
+		return errors.Conflict("retell attempt limit reached").
+			WithDetails(map[string]interface{}{"max_attempts": settings.MaxAttempts})
+	}
+	return nil
+}
+
 // SubmitGistQuiz handles the submission and scoring of a gist quiz.
 func (s *VideoService) SubmitGistQuiz(ctx context.Context, input SubmitGistQuizInput) (*GistQuizAttempt, *errors.AppError) {
 	// 1. Get existing action by videoID, userID, and type
@@ -550,13 +763,33 @@ func (s *VideoService) SubmitGistQuiz(ctx context.Context, input SubmitGistQuizI
 
 	metadataJSON, _ := json.Marshal(metadata)
 
-	if err := s.videoRepo.UpdateQuizAction(ctx, action.ID, metadataJSON); err != nil {
+	if err := s.videoRepo.UpdateQuizAction(ctx, action.ID, action.ActionType, metadataJSON); err != nil {
 		return nil, err
 	}
 
 	return &attempt, nil
 }
 
+// ValidateRetellAudio rejects retell recordings longer than the configured cap.
+// It runs before the job is queued so nothing is uploaded or transcribed.
+func (s *VideoService) ValidateRetellAudio(ctx context.Context, input SubmitRetellPayload, audio multipart.File) *errors.AppError {
+	tempWav, err := s.fileRepo.CreateTempFile(audio, input.AudioWavPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tempWav.Close()
+		os.Remove(tempWav.Name())
+	}()
+
+	return s.fileRepo.CheckRecordingDuration(ctx, tempWav.Name())
+}
+
+// StageRetellAudio stages the retell recording in R2 for the evaluation job.
+func (s *VideoService) StageRetellAudio(ctx context.Context, input SubmitRetellPayload, audio multipart.File) *errors.AppError {
+	return s.fileRepo.StageToR2(ctx, audio, input.AudioStagingKey, "application/octet-stream")
+}
+
 // SubmitRetellStory handles the submission and AI evaluation of a retell story.
 func (s *VideoService) SubmitRetellStory(ctx context.Context, input SubmitRetellPayload) (*RetellAttempt, *errors.AppError) {
 	// 1. Create batch processing
@@ -581,95 +814,750 @@ func (s *VideoService) SubmitRetellStory(ctx context.Context, input SubmitRetell
 	}, nil
 }
 
-// Worker: ProcessEvaluateRetel
-func (s *VideoService) ProcessEvaluateRetel(ctx context.Context, payload SubmitRetellPayload) {
+// Worker: ProcessEvaluateRetel evaluates a staged retell recording. It returns an error when the
+// recording could not be processed or the attempt saved, so the queue runs it again.
+func (s *VideoService) ProcessEvaluateRetel(ctx context.Context, payload SubmitRetellPayload) *errors.AppError {
 	// 1. Get existing action by videoID, userID, and type
 	action, exists, err := s.videoRepo.GetActionByUserID(ctx, payload.VideoID, payload.UserID, "submit_retell")
-	if err != nil || !exists {
-		return
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
 	}
 
 	var metadata RetellStoryMetadata
 	if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
-		return
+		return nil
 	}
 
-	// 2. Process audio
+	// 2. Process audio (fetched from staging; the job may run on another instance than the request)
 	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_UPLOAD_RETELL_AUDIO, BATCH_PROCESSING, "")
-	tempWav, err := s.fileRepo.CreateTempFile(payload.AudioFile, payload.AudioWavPath)
-	if err != nil {
+	defer os.Remove(payload.AudioWavPath)
+	if err := s.fileRepo.DownloadFromR2(ctx, payload.AudioStagingKey, payload.AudioWavPath); err != nil {
 		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_UPLOAD_RETELL_AUDIO, BATCH_FAILED, err.GetMessage())
-		return
+		return err
 	}
 
-	// Defer close and remove temp file
-	defer func() {
-		tempWav.Close()
-		os.Remove(tempWav.Name())
-	}()
+	// Trim silence and normalize loudness before transcription (keep the raw recording if ffmpeg fails)
+	_ = s.fileRepo.TrimSilence(ctx, payload.AudioWavPath)
+	_ = s.fileRepo.NormalizeAudio(ctx, payload.AudioWavPath)
 
-	transcript, err := s.aiRepo.GenerateVideoTranscript(ctx, tempWav.Name(), payload.Language)
+	callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Transcription)
+	transcript, err := s.aiRepo.GenerateVideoTranscript(callCtx, payload.AudioWavPath, payload.Language)
+	cancel()
 	if err != nil {
 		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_UPLOAD_RETELL_AUDIO, BATCH_FAILED, err.GetMessage())
-		return
+		return err
 	}
 
-	if err := s.fileRepo.ConvertAudioToM4A(ctx, tempWav.Name(), payload.AudioM4aPath); err != nil {
+	if err := s.fileRepo.ConvertAudioToM4A(ctx, payload.AudioWavPath, payload.AudioM4aPath); err != nil {
 		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_UPLOAD_RETELL_AUDIO, BATCH_FAILED, err.GetMessage())
-		return
+		return err
 	}
 	defer os.Remove(payload.AudioM4aPath)
 
 	audioURL, err := s.fileRepo.UploadReaderToR2(ctx, payload.AudioM4aPath, payload.AudioR2Path, payload.AudioType)
 	if err != nil {
 		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_UPLOAD_RETELL_AUDIO, BATCH_FAILED, err.GetMessage())
-		return
+		return err
 	}
 	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_UPLOAD_RETELL_AUDIO, BATCH_COMPLETED, "")
 
 	// 4. AI Evaluation
 	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_EVALUATE_RETEL, BATCH_PROCESSING, "")
-	eval, err := s.aiRepo.EvaluateRetellStory(ctx, transcript.Text, metadata.RetellStory.KeyPoints)
+	attempt := RetellAttempt{
+		AttemptID:   payload.AttemptID,
+		AudioURL:    audioURL,
+		MimeType:    payload.AudioType,
+		Transcript:  transcript.Text,
+		SubmittedAt: time.Now().UTC(),
+	}
+
+	settings := s.actionRetellSettings(&metadata)
+	eval, err := s.evaluateRetell(ctx, transcript.Text, metadata.RetellStory.KeyPoints)
+	if err == nil && len(metadata.RetellStory.Weights) == len(metadata.RetellStory.KeyPoints) {
+		eval.Score = weightedRetellScore(metadata.RetellStory.KeyPoints, metadata.RetellStory.Weights, eval.MatchesKeyPoints)
+	}
 	if err != nil {
+		// Every provider failed: keep the recording but do not award a score
 		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_EVALUATE_RETEL, BATCH_FAILED, err.GetMessage())
-		return
+		attempt.Status = RETELL_EVALUATION_FAILED
+		attempt.MatchesKeyPoints = []string{}
+	} else {
+		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_EVALUATE_RETEL, BATCH_COMPLETED, "")
+		attempt.Status = RETELL_EVALUATED
+		attempt.RetellScore = eval.Score
+		attempt.MatchesKeyPoints = eval.MatchesKeyPoints
+		attempt.RetellAnalysis = eval.Analysis
+		attempt.Passed = eval.Score >= settings.PassPercentage
 	}
-	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_EVALUATE_RETEL, BATCH_COMPLETED, "")
 
-	// 5. Create attempt
+	// 5. Update metadata (re-read under lock, other attempts may have been saved meanwhile)
 	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_SAVE_RETEL, BATCH_PROCESSING, "")
-	attempt := RetellAttempt{
-		AttemptID:        payload.AttemptID,
-		AudioURL:         audioURL,
-		MimeType:         payload.AudioType,
-		Transcript:       transcript.Text,
-		RetellScore:      eval.Score,
-		MatchesKeyPoints: eval.MatchesKeyPoints,
-		RetellAnalysis:   eval.Analysis,
-		SubmittedAt:      time.Now().UTC(),
+	err = s.videoRepo.UpdateActionMetadata(ctx, action.ID, func(raw json.RawMessage) (json.RawMessage, *errors.AppError) {
+		var latest RetellStoryMetadata
+		if err := json.Unmarshal(raw, &latest); err != nil {
+			return nil, errors.InternalWrap("failed to parse retell metadata", err)
+		}
+		latest.Attempts = trimRetellAttempts(append(latest.Attempts, attempt), settings.MaxAttempts)
+		metadataJSON, _ := json.Marshal(latest)
+		return metadataJSON, nil
+	})
+	if err != nil {
+		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_SAVE_RETEL, BATCH_FAILED, err.GetMessage())
+		return err
 	}
+	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_SAVE_RETEL, BATCH_COMPLETED, "")
 
-	// 6. Update metadata
-	metadata.Attempts = append(metadata.Attempts, attempt)
+	_ = s.fileRepo.DeleteFromR2(ctx, payload.AudioStagingKey)
+	return nil
+}
 
-	// Sort by date (desc) to keep 3 latest
-	sort.Slice(metadata.Attempts, func(i, j int) bool {
-		return metadata.Attempts[i].SubmittedAt.After(metadata.Attempts[j].SubmittedAt)
+// trimRetellAttempts sorts attempts by date (desc) and keeps the latest maxAttempts evaluated attempts.
+// Failed evaluations do not count against the limit; only the latest ones are kept.
+func trimRetellAttempts(attempts []RetellAttempt, maxAttempts int) []RetellAttempt {
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].SubmittedAt.After(attempts[j].SubmittedAt)
 	})
 
-	// Keep only latest 3
-	if len(metadata.Attempts) > 3 {
-		metadata.Attempts = metadata.Attempts[:3]
+	kept := make([]RetellAttempt, 0, len(attempts))
+	var evaluated, failed int
+	for _, attempt := range attempts {
+		if attempt.Status == RETELL_EVALUATION_FAILED {
+			if failed < maxFailedRetellAttempts {
+				kept = append(kept, attempt)
+			}
+			failed++
+			continue
+		}
+		if evaluated < maxAttempts {
+			kept = append(kept, attempt)
+		}
+		evaluated++
 	}
+	return kept
+}
 
-	metadataJSON, _ := json.Marshal(metadata)
+// Worker: PurgeRetellAudio
+// Deletes retell recordings older than the retention period from R2 and clears their URLs,
+// keeping transcripts and scores intact.
+func (s *VideoService) PurgeRetellAudio(ctx context.Context, payload PurgeRetellAudioPayload) *errors.AppError {
+	const batchSize = 100
+	cutoff := time.Now().UTC().Add(-payload.Retention)
 
-	if err := s.videoRepo.UpdateQuizAction(ctx, action.ID, metadataJSON); err != nil {
-		_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_SAVE_RETEL, BATCH_FAILED, err.GetMessage())
+	for {
+		actions, err := s.videoRepo.ListRetellActionsWithAudioBefore(ctx, cutoff, batchSize)
+		if err != nil {
+			return err
+		}
+
+		purged := 0
+		for _, action := range actions {
+			var metadata RetellStoryMetadata
+			if err := json.Unmarshal(action.Metadata, &metadata); err != nil {
+				continue
+			}
+
+			deleted := make(map[string]bool)
+			for _, attempt := range metadata.Attempts {
+				if attempt.AudioURL == "" || !attempt.SubmittedAt.Before(cutoff) {
+					continue
+				}
+				if err := s.fileRepo.DeleteFromR2(ctx, retellAudioKey(attempt.AttemptID)); err != nil {
+					continue
+				}
+				deleted[attempt.AttemptID] = true
+			}
+			if len(deleted) == 0 {
+				continue
+			}
+
+			// Clear the URLs under the row lock, so an evaluation or attempt landing meanwhile is kept
+			err := s.videoRepo.UpdateActionMetadata(ctx, action.ID, func(raw json.RawMessage) (json.RawMessage, *errors.AppError) {
+				var current RetellStoryMetadata
+				if err := json.Unmarshal(raw, &current); err != nil {
+					return nil, errors.InternalWrap("failed to parse retell metadata", err)
+				}
+				for i, attempt := range current.Attempts {
+					if deleted[attempt.AttemptID] {
+						current.Attempts[i].AudioURL = ""
+					}
+				}
+				metadataJSON, _ := json.Marshal(current)
+				return metadataJSON, nil
+			})
+			if err != nil {
+				return err
+			}
+			purged++
+		}
+
+		// Stop when the last page is done or nothing could be purged (avoid looping on failing deletes)
+		if len(actions) < batchSize || purged == 0 {
+			return nil
+		}
+	}
+}
+
+// Worker: BackfillFrequencyRanks
+// Ranks the vocabulary of every saved video with the current frequency lists.
+// Videos edited while the job runs are skipped and keep their ranks until the next run.
+func (s *VideoService) BackfillFrequencyRanks(ctx context.Context, payload BackfillFrequencyPayload) *errors.AppError {
+	const batchSize = 100
+
+	for offset := 0; ; offset += batchSize {
+		items, _, err := s.videoRepo.ListVideos(ctx, batchSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			var details VideoDetails
+			if err := json.Unmarshal(item.Details, &details); err != nil {
+				continue
+			}
+			vocab := &VideoVocabulary{Vocabulary: details.Vocabulary, KeyPhrases: details.KeyPhrases}
+			if !s.rankVocabulary(vocab, item.Language) {
+				continue
+			}
+
+			item.Details, _ = json.Marshal(details)
+			if err := s.videoRepo.UpdateVideoDetails(ctx, item); err != nil && err.GetCode() != string(errors.ErrConflict) {
+				return err
+			}
+		}
+
+		if len(items) < batchSize {
+			return nil
+		}
+	}
+}
+
+// UpdateTranscript applies per-segment fixes to a video transcript, keeping the previous version as a revision.
+func (s *VideoService) UpdateTranscript(ctx context.Context, input UpdateTranscriptInput) (*UpdateTranscriptResponse, *errors.AppError) {
+	// 1. Get video and check ownership
+	learningItem, err := s.videoRepo.GetVideo(ctx, input.VideoID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the video owner can edit the transcript")
+	}
+	if err := checkVideoVersion(learningItem, input.Version); err != nil {
+		return nil, err
+	}
+
+	var details VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse video details", err)
+	}
+
+	// 2. Keep the current version as a revision
+	details.TranscriptRevisions = append(details.TranscriptRevisions, TranscriptRevision{
+		Revision:   len(details.TranscriptRevisions) + 1,
+		Transcript: details.Transcript,
+		Segments:   append([]TranscriptSegment(nil), details.Segments...),
+		EditedBy:   input.UserID,
+		EditedAt:   time.Now().UTC(),
+	})
+
+	// 3. Apply segment edits and rebuild the full transcript
+	for _, edit := range input.Segments {
+		if edit.Index >= len(details.Segments) {
+			return nil, errors.Validation(fmt.Sprintf("segment index %d out of range", edit.Index))
+		}
+		details.Segments[edit.Index].Text = strings.TrimSpace(edit.Text)
+	}
+	details.Transcript = joinTranscriptSegments(details.Segments)
+
+	// 4. Save details
+	learningItem.Details, _ = json.Marshal(details)
+	if err := s.videoRepo.UpdateVideoDetails(ctx, learningItem); err != nil {
+		return nil, err
+	}
+
+	return &UpdateTranscriptResponse{
+		VideoID:      input.VideoID,
+		Revision:     len(details.TranscriptRevisions) + 1,
+		Transcript:   details.Transcript,
+		Segments:     details.Segments,
+		Regenerating: input.Regenerate,
+		Version:      learningItem.Version,
+	}, nil
+}
+
+// Worker: ProcessRegenerateVideoDetails regenerates details, quiz and retell story from the edited transcript.
+func (s *VideoService) ProcessRegenerateVideoDetails(ctx context.Context, payload RegenerateVideoDetailsPayload) *errors.AppError {
+	learningItem, err := s.videoRepo.GetVideo(ctx, payload.VideoID, payload.UserID)
+	if err != nil {
+		return err
+	}
+
+	var current VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &current); err != nil {
+		return errors.InternalWrap("failed to parse video details", err)
+	}
+
+	// Rebuild a transcript from the edited segments so details see the corrected text
+	transcript := &client.WhisperResponse{Language: current.Language, Text: current.Transcript}
+	for i, seg := range current.Segments {
+		transcript.Segments = append(transcript.Segments, client.WhisperSegment{
+			ID:    i,
+			Start: seg.Start,
+			End:   seg.Start + seg.Duration,
+			Text:  seg.Text,
+		})
+	}
+
+	callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+	details, err := s.aiRepo.GenerateVideoDetails(callCtx, transcript)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	// Keep media, quality and revision history from the current version
+	details.VideoURL = current.VideoURL
+	details.ThumbnailURL = current.ThumbnailURL
+	details.TranscriptQuality = current.TranscriptQuality
+	details.TranscriptRevisions = current.TranscriptRevisions
+	details.RetellSettings = current.RetellSettings
+	if current.RetellStory.Authored {
+		details.RetellStory = current.RetellStory
+	}
+
+	if s.opts.ExtractVocabulary {
+		callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+		vocab, err := s.aiRepo.ExtractVocabulary(callCtx, details.Transcript, details.Level)
+		cancel()
+		if err == nil {
+			s.checkReadings(ctx, vocab, details.Language)
+			s.rankVocabulary(vocab, details.Language)
+			details.Vocabulary = vocab.Vocabulary
+			details.KeyPhrases = vocab.KeyPhrases
+		}
+	}
+	details.Chapters = current.Chapters
+	if n := len(details.Segments); n > 0 && s.shouldGenerateChapters(details.Segments[n-1].Start+details.Segments[n-1].Duration) {
+		callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+		chapters, err := s.aiRepo.GenerateChapters(callCtx, details.Segments)
+		cancel()
+		if err == nil {
+			details.Chapters = chapters
+		}
+	}
+
+	learningItem.Content = details.Topic
+	learningItem.Level = &details.Level
+	learningItem.Details, _ = json.Marshal(details)
+	learningItem.Tags, _ = json.Marshal(details.Tags)
+
+	return s.videoRepo.UpdateVideoDetails(ctx, learningItem)
+}
+
+// UpdateRetellSettings sets the retell attempt settings of a lesson.
+// Retell sessions already started keep the settings they started with.
+func (s *VideoService) UpdateRetellSettings(ctx context.Context, input UpdateRetellSettingsInput) (*RetellSettings, *errors.AppError) {
+	learningItem, err := s.videoRepo.GetVideo(ctx, input.VideoID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the video owner can change retell settings")
+	}
+	if err := checkVideoVersion(learningItem, input.Version); err != nil {
+		return nil, err
+	}
+
+	var details VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse video details", err)
+	}
+
+	details.RetellSettings = &input.Settings
+	learningItem.Details, _ = json.Marshal(details)
+	if err := s.videoRepo.UpdateVideoDetails(ctx, learningItem); err != nil {
+		return nil, err
+	}
+
+	return details.RetellSettings, nil
+}
+
+// PatchVideo applies a JSON Merge Patch (RFC 7386) to the content, tags, details and metadata of a video.
+func (s *VideoService) PatchVideo(ctx context.Context, input PatchVideoInput) (*LearningItem, *errors.AppError) {
+	learningItem, err := s.videoRepo.GetVideo(ctx, input.VideoID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the video owner can edit the video")
+	}
+	if err := checkVideoVersion(learningItem, input.Version); err != nil {
+		return nil, err
+	}
+
+	if input.Content != nil {
+		learningItem.Content = strings.TrimSpace(*input.Content)
+	}
+	if input.TagsSet {
+		learningItem.Tags, _ = json.Marshal(input.Tags)
+	}
+
+	if input.Details != nil {
+		details, err := mergePatchJSON(learningItem.Details, input.Details)
+		if err != nil {
+			return nil, err
+		}
+		// The patched details must still be valid video details
+		var parsed VideoDetails
+		if err := json.Unmarshal(details, &parsed); err != nil {
+			return nil, errors.ValidationWrap("patched details are not valid video details", err)
+		}
+		learningItem.Details = details
+	}
+
+	if input.Metadata != nil {
+		metadata, err := mergePatchJSON(learningItem.Metadata, input.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		learningItem.Metadata = metadata
+	}
+
+	if err := s.videoRepo.PatchVideo(ctx, learningItem); err != nil {
+		return nil, err
+	}
+
+	return learningItem, nil
+}
+
+// BulkVideos runs an activate / deactivate / delete / retag action over several of the user's videos at once.
+func (s *VideoService) BulkVideos(ctx context.Context, input BulkVideosInput) (*BulkVideosResponse, *errors.AppError) {
+	var tagsJSON json.RawMessage
+	if input.Action == BulkRetag {
+		tags := make([]string, 0, len(input.Tags))
+		for _, tag := range input.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		tagsJSON, _ = json.Marshal(tags)
+	}
+
+	results, err := s.videoRepo.BulkUpdate(ctx, input.UserID, input.Action, input.IDs, input.Filter, tagsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BulkVideosResponse{Action: input.Action, Results: results}
+	for _, result := range results {
+		if result.Status == BULK_OK {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp, nil
+}
+
+// mergePatchJSON applies a JSON Merge Patch (RFC 7386) to target and returns the encoded result.
+func mergePatchJSON(target, patch json.RawMessage) (json.RawMessage, *errors.AppError) {
+	var targetValue, patchValue interface{}
+	if len(target) > 0 {
+		if err := json.Unmarshal(target, &targetValue); err != nil {
+			return nil, errors.InternalWrap("failed to parse stored JSON", err)
+		}
+	}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, errors.ValidationWrap("invalid JSON merge patch", err)
+	}
+
+	merged, err := json.Marshal(mergePatch(targetValue, patchValue))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to encode patched JSON", err)
+	}
+	return merged, nil
+}
+
+// mergePatch merges patch into target: objects are merged recursively, null removes a key,
+// and any other value (including arrays) replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// checkVideoVersion rejects an edit made against an outdated version of the video.
+func checkVideoVersion(item *LearningItem, expected *int) *errors.AppError {
+	if expected == nil || *expected == item.Version {
+		return nil
+	}
+	return errors.Conflict("video was modified by another request").
+		WithDetails(map[string]interface{}{"current_version": item.Version})
+}
+
+// GetVideoChapters returns the chapters of a video.
+func (s *VideoService) GetVideoChapters(ctx context.Context, videoID, userID string) (*VideoChaptersResponse, *errors.AppError) {
+	learningItem, err := s.videoRepo.GetVideo(ctx, videoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var details VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse video details", err)
+	}
+
+	chapters := details.Chapters
+	if chapters == nil {
+		chapters = []VideoChapter{}
+	}
+
+	return &VideoChaptersResponse{
+		VideoID:  videoID,
+		Chapters: chapters,
+	}, nil
+}
+
+// ExpandVocabulary generates new example sentences and collocations for a word of the video's
+// vocabulary or key phrases and appends them with their provenance. Examples are voiced when asked;
+// an example whose audio fails is kept without it.
+func (s *VideoService) ExpandVocabulary(ctx context.Context, input ExpandVocabularyInput) (*ExpandVocabularyResponse, *errors.AppError) {
+	learningItem, err := s.videoRepo.GetVideo(ctx, input.ItemID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the video owner can expand its vocabulary")
+	}
+	if err := checkVideoVersion(learningItem, input.Version); err != nil {
+		return nil, err
+	}
+
+	var details VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse video details", err)
+	}
+	item := findVocabularyItem(&details, input.Word)
+	if item == nil {
+		return nil, errors.NotFound("word not found in the video vocabulary")
+	}
+
+	examples := min(input.Examples, maxWordExamples-len(item.Examples))
+	collocations := min(input.Collocations, maxWordCollocations-len(item.Collocations))
+	if examples <= 0 && collocations <= 0 {
+		return nil, errors.Conflict("word already has the maximum number of examples and collocations")
+	}
+
+	callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+	expansion, err := s.aiRepo.ExpandVocabulary(callCtx, *item, details.Language, details.Level, max(examples, 0), max(collocations, 0))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip anything the item already has
+	seen := map[string]bool{normalizeWord(item.Example): true}
+	for _, example := range item.Examples {
+		seen[normalizeWord(example.Text)] = true
+	}
+	for _, collocation := range item.Collocations {
+		seen[normalizeWord(collocation.Text)] = true
+	}
+
+	provenance := Provenance{Source: VOCABULARY_SOURCE_EXPAND, CreatedBy: input.UserID, CreatedAt: time.Now().UTC()}
+	addedExamples := make([]VocabularyExample, 0, len(expansion.Examples))
+	for _, text := range expansion.Examples {
+		text = strings.TrimSpace(text)
+		if text == "" || seen[normalizeWord(text)] {
+			continue
+		}
+		seen[normalizeWord(text)] = true
+		example := VocabularyExample{Text: text, Provenance: provenance}
+		if input.Audio {
+			example.AudioURL = s.synthesizeExample(ctx, input.ItemID, text, details.Language)
+		}
+		addedExamples = append(addedExamples, example)
+	}
+	addedCollocations := make([]VocabularyCollocation, 0, len(expansion.Collocations))
+	for _, c := range expansion.Collocations {
+		text := strings.TrimSpace(c.Text)
+		if text == "" || seen[normalizeWord(text)] {
+			continue
+		}
+		seen[normalizeWord(text)] = true
+		addedCollocations = append(addedCollocations, VocabularyCollocation{Text: text, Meaning: strings.TrimSpace(c.Meaning), Provenance: provenance})
+	}
+	if len(addedExamples) == 0 && len(addedCollocations) == 0 {
+		return nil, errors.Internal("expansion returned only existing examples and collocations")
+	}
+
+	item.Examples = append(item.Examples, addedExamples...)
+	item.Collocations = append(item.Collocations, addedCollocations...)
+	learningItem.Details, _ = json.Marshal(details)
+	if err := s.videoRepo.UpdateVideoDetails(ctx, learningItem); err != nil {
+		return nil, err
+	}
+
+	return &ExpandVocabularyResponse{
+		ItemID:       input.ItemID,
+		Item:         *item,
+		Examples:     addedExamples,
+		Collocations: addedCollocations,
+		Version:      learningItem.Version,
+	}, nil
+}
+
+// findVocabularyItem returns the vocabulary item or key phrase whose text is word (ignoring case and spacing).
+func findVocabularyItem(details *VideoDetails, word string) *VocabularyItem {
+	word = normalizeWord(word)
+	for _, items := range [][]VocabularyItem{details.Vocabulary, details.KeyPhrases} {
+		for i := range items {
+			if normalizeWord(items[i].Text) == word {
+				return &items[i]
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeWord folds case and spacing for comparing vocabulary texts.
+func normalizeWord(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// synthesizeExample voices an example sentence and returns its URL, or "" when it fails.
+func (s *VideoService) synthesizeExample(ctx context.Context, videoID, text, language string) string {
+	if s.audioRepo == nil {
+		return ""
+	}
+
+	callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Speech)
+	audioBytes, err := s.audioRepo.Synthesize(callCtx, text, client.VoiceForLanguage(language))
+	cancel()
+	if err != nil {
+		return ""
+	}
+
+	key := fmt.Sprintf("videos/%s/vocabulary/%s.mp3", videoID, uuid.New().String())
+	url, err := s.fileRepo.UploadBytes(ctx, audioBytes, key, "audio/mpeg")
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// checkReadings corrects the romanized readings and validates the IPA of the vocabulary.
+// Invalid IPA is regenerated once; transcriptions that are still invalid are dropped.
+func (s *VideoService) checkReadings(ctx context.Context, vocab *VideoVocabulary, language string) {
+	s.correctReadings(vocab.Vocabulary, language)
+	s.correctReadings(vocab.KeyPhrases, language)
+
+	var invalid []*VocabularyItem
+	var texts []string
+	for _, items := range [][]VocabularyItem{vocab.Vocabulary, vocab.KeyPhrases} {
+		for i := range items {
+			if items[i].Reading == nil || items[i].Reading.IPA == "" {
+				continue
+			}
+			if client.ValidateIPA(language, items[i].Reading.IPA) != nil {
+				invalid = append(invalid, &items[i])
+				texts = append(texts, strings.TrimSpace(items[i].Text))
+			}
+		}
+	}
+	if len(invalid) == 0 {
 		return
 	}
-	_ = s.batchRepo.UpdateEvaluateRetellJob(ctx, payload.AttemptID, PROCESS_SAVE_RETEL, BATCH_COMPLETED, "")
 
+	callCtx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+	regenerated, err := s.aiRepo.GenerateReadingIPA(callCtx, language, texts)
+	cancel()
+	for i, item := range invalid {
+		if err == nil && regenerated[texts[i]] != "" {
+			item.Reading.IPA = regenerated[texts[i]]
+			continue
+		}
+		item.Reading.IPA = ""
+		if item.Reading.Standard == "" {
+			item.Reading = nil
+		}
+	}
+}
+
+// rankVocabulary sets the frequency rank of every vocabulary item and key phrase.
+// It reports whether any rank changed.
+func (s *VideoService) rankVocabulary(vocab *VideoVocabulary, language string) bool {
+	changed := false
+	for _, items := range [][]VocabularyItem{vocab.Vocabulary, vocab.KeyPhrases} {
+		for i := range items {
+			rank, _ := s.opts.WordFrequency.Rank(language, items[i].Text)
+			if items[i].FrequencyRank != rank {
+				items[i].FrequencyRank = rank
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// correctReadings replaces AI romanizations that spell other syllables than the deterministic
+// one. Romanizations the romanizer cannot fully resolve are kept as the AI wrote them.
+func (s *VideoService) correctReadings(items []VocabularyItem, language string) {
+	if s.opts.Romanizer == nil {
+		return
+	}
+	for i := range items {
+		romanized, ok := s.opts.Romanizer.Romanize(language, items[i].Text)
+		if !ok {
+			// Only Chinese, Japanese and Korean have a standard romanization
+			if items[i].Reading != nil {
+				items[i].Reading.Standard = ""
+				if items[i].Reading.IPA == "" {
+					items[i].Reading = nil
+				}
+			}
+			continue
+		}
+		if !romanized.Complete {
+			continue
+		}
+		if items[i].Reading == nil {
+			items[i].Reading = &VocabularyReading{}
+		}
+		if !client.SameReading(items[i].Reading.Standard, romanized.Standard) {
+			items[i].Reading.Standard = romanized.Standard
+		}
+	}
+}
+
+// shouldGenerateChapters reports whether a video of the given duration (seconds) gets chapters.
+func (s *VideoService) shouldGenerateChapters(duration float64) bool {
+	return s.opts.ChaptersMinDuration > 0 && duration >= s.opts.ChaptersMinDuration.Seconds()
+}
+
+// joinTranscriptSegments builds the full transcript text from its segments.
+func joinTranscriptSegments(segments []TranscriptSegment) string {
+	texts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		texts = append(texts, seg.Text)
+	}
+	return strings.TrimSpace(strings.Join(texts, " "))
 }
 
 // ToggleTranscript toggles the transcript action for a video.
@@ -810,3 +1698,288 @@ func scoreQuizAnswers(gistQuiz any, answers []QuizAnswer) float64 {
 
 	return total
 }
+
+// evaluateRetell credits key points that the transcript clearly covers without the AI,
+// and only sends the remaining (ambiguous) key points to the model.
+func (s *VideoService) evaluateRetell(ctx context.Context, transcript string, keyPoints []string) (*RetellEvaluation, *errors.AppError) {
+	ctx, cancel := client.WithTimeout(ctx, s.opts.Timeouts.Chat)
+	defer cancel()
+
+	if s.opts.RetellMatchCoverage <= 0 || len(keyPoints) == 0 {
+		return s.aiRepo.EvaluateRetellStory(ctx, transcript, keyPoints)
+	}
+
+	transcriptTokens := make(map[string]bool)
+	for _, token := range retellTokens(transcript) {
+		transcriptTokens[token] = true
+	}
+
+	matched := []string{}
+	var ambiguous []string
+	for _, kp := range keyPoints {
+		if retellCoverage(retellTokens(kp), transcriptTokens) >= s.opts.RetellMatchCoverage {
+			matched = append(matched, kp)
+		} else {
+			ambiguous = append(ambiguous, kp)
+		}
+	}
+
+	if len(ambiguous) == 0 {
+		return &RetellEvaluation{
+			Score:            100,
+			MatchesKeyPoints: matched,
+			Analysis:         "All key points were covered in the retell.",
+		}, nil
+	}
+
+	eval, err := s.aiRepo.EvaluateRetellStory(ctx, transcript, ambiguous)
+	if err != nil {
+		return nil, err
+	}
+
+	// Auto-credited points count as full marks, the AI score covers the rest
+	total := float64(len(keyPoints))
+	eval.Score = (float64(len(matched))*100 + eval.Score*float64(len(ambiguous))) / total
+	eval.MatchesKeyPoints = append(matched, eval.MatchesKeyPoints...)
+	return eval, nil
+}
+
+// retellCoverage returns the fraction of key point tokens found in the transcript.
+func retellCoverage(keyPointTokens []string, transcriptTokens map[string]bool) float64 {
+	if len(keyPointTokens) == 0 {
+		return 0
+	}
+
+	hits := 0
+	for _, token := range keyPointTokens {
+		if transcriptTokens[token] || retellFuzzyHit(token, transcriptTokens) {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(keyPointTokens))
+}
+
+// retellFuzzyHit matches inflected forms (e.g. "walked" / "walking") by a shared 5-letter stem.
+func retellFuzzyHit(token string, transcriptTokens map[string]bool) bool {
+	stem := []rune(token)
+	if len(stem) < 5 {
+		return false
+	}
+	prefix := string(stem[:5])
+	for t := range transcriptTokens {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// retellTokens splits text into lowercase content words.
+// Scripts written without spaces (Chinese, Japanese) are split into character bigrams.
+func retellTokens(text string) []string {
+	var tokens []string
+	var word, cjk []rune
+
+	flushWord := func() {
+		// Skip short function words ("a", "to", "de", ...)
+		if len(word) >= 3 {
+			tokens = append(tokens, string(word))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// weightedRetellScore scores matched key points by their weights (0-100).
+func weightedRetellScore(keyPoints []string, weights []float64, matches []string) float64 {
+	matched := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		matched[strings.TrimSpace(m)] = true
+	}
+
+	var total, earned float64
+	for i, kp := range keyPoints {
+		total += weights[i]
+		if matched[strings.TrimSpace(kp)] {
+			earned += weights[i]
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	return earned / total * 100
+}
+
+// ListRetellPoints returns the retell key points of a lesson.
+func (s *VideoService) ListRetellPoints(ctx context.Context, input RetellPointInput) ([]RetellKeyPoint, *errors.AppError) {
+	learningItem, err := s.videoRepo.GetVideo(ctx, input.VideoID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var details VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse video details", err)
+	}
+
+	return retellKeyPoints(&details), nil
+}
+
+// AddRetellPoint appends a key point to the lesson.
+func (s *VideoService) AddRetellPoint(ctx context.Context, input RetellPointInput) ([]RetellKeyPoint, *errors.AppError) {
+	return s.editRetellPoints(ctx, input, func(points []RetellKeyPoint) ([]RetellKeyPoint, *errors.AppError) {
+		return append(points, RetellKeyPoint{Text: input.Text, Weight: input.Weight}), nil
+	})
+}
+
+// UpdateRetellPoint changes the text and weight of a key point.
+func (s *VideoService) UpdateRetellPoint(ctx context.Context, input RetellPointInput) ([]RetellKeyPoint, *errors.AppError) {
+	return s.editRetellPoints(ctx, input, func(points []RetellKeyPoint) ([]RetellKeyPoint, *errors.AppError) {
+		if input.Index >= len(points) {
+			return nil, errors.NotFound("retell key point not found")
+		}
+		points[input.Index].Text = input.Text
+		points[input.Index].Weight = input.Weight
+		return points, nil
+	})
+}
+
+// DeleteRetellPoint removes a key point.
+func (s *VideoService) DeleteRetellPoint(ctx context.Context, input RetellPointInput) ([]RetellKeyPoint, *errors.AppError) {
+	return s.editRetellPoints(ctx, input, func(points []RetellKeyPoint) ([]RetellKeyPoint, *errors.AppError) {
+		if input.Index >= len(points) {
+			return nil, errors.NotFound("retell key point not found")
+		}
+		return append(points[:input.Index], points[input.Index+1:]...), nil
+	})
+}
+
+// ReorderRetellPoints puts the key points in the given order (a permutation of the current indexes).
+func (s *VideoService) ReorderRetellPoints(ctx context.Context, input RetellPointInput) ([]RetellKeyPoint, *errors.AppError) {
+	return s.editRetellPoints(ctx, input, func(points []RetellKeyPoint) ([]RetellKeyPoint, *errors.AppError) {
+		if len(input.Order) != len(points) {
+			return nil, errors.Validation("order must list every key point exactly once")
+		}
+		seen := make(map[int]bool, len(points))
+		reordered := make([]RetellKeyPoint, 0, len(points))
+		for _, idx := range input.Order {
+			if idx < 0 || idx >= len(points) || seen[idx] {
+				return nil, errors.Validation("order must list every key point exactly once")
+			}
+			seen[idx] = true
+			reordered = append(reordered, points[idx])
+		}
+		return reordered, nil
+	})
+}
+
+// editRetellPoints loads the lesson's key points, applies edit, validates and saves them.
+// Retell sessions already started keep their own snapshot of the key points.
+func (s *VideoService) editRetellPoints(ctx context.Context, input RetellPointInput, edit func([]RetellKeyPoint) ([]RetellKeyPoint, *errors.AppError)) ([]RetellKeyPoint, *errors.AppError) {
+	learningItem, err := s.videoRepo.GetVideo(ctx, input.VideoID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if learningItem.CreatedBy != input.UserID {
+		return nil, errors.Forbidden("only the video owner can edit retell key points")
+	}
+	if err := checkVideoVersion(learningItem, input.Version); err != nil {
+		return nil, err
+	}
+
+	var details VideoDetails
+	if err := json.Unmarshal(learningItem.Details, &details); err != nil {
+		return nil, errors.InternalWrap("failed to parse video details", err)
+	}
+
+	points, err := edit(retellKeyPoints(&details))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRetellKeyPoints(points); err != nil {
+		return nil, err
+	}
+
+	details.RetellStory.KeyPoints = make([]string, len(points))
+	details.RetellStory.Weights = make([]float64, len(points))
+	for i, p := range points {
+		details.RetellStory.KeyPoints[i] = p.Text
+		details.RetellStory.Weights[i] = p.Weight
+	}
+	details.RetellStory.Authored = true
+
+	learningItem.Details, _ = json.Marshal(details)
+	if err := s.videoRepo.UpdateVideoDetails(ctx, learningItem); err != nil {
+		return nil, err
+	}
+
+	return retellKeyPoints(&details), nil
+}
+
+// retellKeyPoints returns the lesson's key points with their weights (1 when not set).
+func retellKeyPoints(details *VideoDetails) []RetellKeyPoint {
+	story := details.RetellStory
+	points := make([]RetellKeyPoint, len(story.KeyPoints))
+	for i, text := range story.KeyPoints {
+		weight := 1.0
+		if len(story.Weights) == len(story.KeyPoints) {
+			weight = story.Weights[i]
+		}
+		points[i] = RetellKeyPoint{Index: i, Text: text, Weight: weight}
+	}
+	return points
+}
+
+// validateRetellKeyPoints checks count, text and weights of the key points.
+func validateRetellKeyPoints(points []RetellKeyPoint) *errors.AppError {
+	if len(points) == 0 {
+		return errors.Validation("a retell needs at least one key point")
+	}
+	if len(points) > maxRetellKeyPoints {
+		return errors.Validation(fmt.Sprintf("a retell can have at most %d key points", maxRetellKeyPoints))
+	}
+
+	seen := make(map[string]bool, len(points))
+	for i := range points {
+		points[i].Text = strings.TrimSpace(points[i].Text)
+		points[i].Index = i
+		if points[i].Text == "" {
+			return errors.Validation("key point text cannot be empty")
+		}
+		// Matches are reported by text, so duplicates would be ambiguous
+		if seen[points[i].Text] {
+			return errors.Validation("key points must be unique")
+		}
+		seen[points[i].Text] = true
+		if points[i].Weight <= 0 || points[i].Weight > maxRetellKeyPointWeight {
+			return errors.Validation(fmt.Sprintf("key point weight must be greater than 0 and at most %d", maxRetellKeyPointWeight))
+		}
+	}
+	return nil
+}
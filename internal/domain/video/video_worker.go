@@ -11,18 +11,23 @@ import (
 const (
 	WORKER_UPLOAD_VIDEO   = "worker_upload_video"
 	WORKER_EVALUATE_RETEL = "worker_evaluate_retel"
+	WORKER_PURGE_RETELL   = "worker_purge_retell_audio"
+	WORKER_REGENERATE     = "worker_regenerate_video_details"
+	WORKER_BACKFILL_RANKS = "worker_backfill_frequency_ranks"
 )
 
 // RegisterVideoWorkers register video workers to queue
 func RegisterVideoWorkers(queue *client.QueueClient, service *VideoService) {
 
 	// Job Upload Video
-	queue.RegisterWorker(WORKER_UPLOAD_VIDEO, func(ctx context.Context, job client.Job) error {
+	queue.RegisterDurableWorker(WORKER_UPLOAD_VIDEO, UploadVideoPayload{}, func(ctx context.Context, job client.Job) error {
 		payload, ok := job.Payload.(UploadVideoPayload)
 		if !ok {
 			return fmt.Errorf("invalid %s payload type", WORKER_UPLOAD_VIDEO)
 		}
-		service.ProcessUploadVideo(ctx, payload)
+		if err := service.ProcessUploadVideo(client.WithBudgetBatch(ctx, payload.VideoID), payload); err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -31,12 +36,62 @@ func RegisterVideoWorkers(queue *client.QueueClient, service *VideoService) {
 func RegisterEvaluateRetelWorker(queue *client.QueueClient, service *VideoService) {
 
 	// Job Evaluate Retel
-	queue.RegisterWorker(WORKER_EVALUATE_RETEL, func(ctx context.Context, job client.Job) error {
+	queue.RegisterDurableWorker(WORKER_EVALUATE_RETEL, SubmitRetellPayload{}, func(ctx context.Context, job client.Job) error {
 		payload, ok := job.Payload.(SubmitRetellPayload)
 		if !ok {
 			return fmt.Errorf("invalid %s payload type", WORKER_EVALUATE_RETEL)
 		}
-		service.ProcessEvaluateRetel(ctx, payload)
+		if err := service.ProcessEvaluateRetel(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// RegisterPurgeRetellWorker register retell audio retention purge worker to queue
+func RegisterPurgeRetellWorker(queue *client.QueueClient, service *VideoService) {
+
+	// Job Purge Retell Audio
+	queue.RegisterWorker(WORKER_PURGE_RETELL, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(PurgeRetellAudioPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_PURGE_RETELL)
+		}
+		if err := service.PurgeRetellAudio(ctx, payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// RegisterRegenerateDetailsWorker register video details regeneration worker to queue
+func RegisterRegenerateDetailsWorker(queue *client.QueueClient, service *VideoService) {
+
+	// Job Regenerate Video Details
+	queue.RegisterDurableWorker(WORKER_REGENERATE, RegenerateVideoDetailsPayload{}, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(RegenerateVideoDetailsPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_REGENERATE)
+		}
+		if err := service.ProcessRegenerateVideoDetails(client.WithBudgetBatch(ctx, payload.VideoID), payload); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// RegisterBackfillFrequencyWorker register vocabulary frequency rank backfill worker to queue
+func RegisterBackfillFrequencyWorker(queue *client.QueueClient, service *VideoService) {
+
+	// Job Backfill Frequency Ranks
+	queue.RegisterWorker(WORKER_BACKFILL_RANKS, func(ctx context.Context, job client.Job) error {
+		payload, ok := job.Payload.(BackfillFrequencyPayload)
+		if !ok {
+			return fmt.Errorf("invalid %s payload type", WORKER_BACKFILL_RANKS)
+		}
+		if err := service.BackfillFrequencyRanks(ctx, payload); err != nil {
+			return err
+		}
 		return nil
 	})
 }
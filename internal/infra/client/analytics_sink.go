@@ -0,0 +1,229 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Analytics sinks
+const (
+	ANALYTICS_SINK_BIGQUERY   = "bigquery"
+	ANALYTICS_SINK_CLICKHOUSE = "clickhouse"
+)
+
+// AnalyticsSink appends rows to a table of an analytics warehouse.
+type AnalyticsSink interface {
+	Name() string
+	Insert(ctx context.Context, table string, rows []map[string]any) error
+}
+
+// AnalyticsSinkConfig selects and configures the analytics sink.
+type AnalyticsSinkConfig struct {
+	Sink string // "", bigquery or clickhouse
+
+	ClickHouseURL      string
+	ClickHouseDatabase string
+	ClickHouseUser     string
+	ClickHousePassword string
+
+	BigQueryProjectID string // defaults to the service account's project
+	BigQueryDataset   string
+	SABase64          string // Base64-encoded service account JSON
+}
+
+// NewAnalyticsSink creates the configured sink, or nil when no sink is configured.
+func NewAnalyticsSink(ctx context.Context, cfg AnalyticsSinkConfig) (AnalyticsSink, error) {
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+
+	switch cfg.Sink {
+	case "":
+		return nil, nil
+	case ANALYTICS_SINK_CLICKHOUSE:
+		if cfg.ClickHouseURL == "" {
+			return nil, fmt.Errorf("clickhouse url not configured")
+		}
+		return &clickHouseSink{
+			url:      cfg.ClickHouseURL,
+			database: cfg.ClickHouseDatabase,
+			user:     cfg.ClickHouseUser,
+			password: cfg.ClickHousePassword,
+			client:   httpClient,
+		}, nil
+	case ANALYTICS_SINK_BIGQUERY:
+		if cfg.SABase64 == "" || cfg.BigQueryDataset == "" {
+			return nil, fmt.Errorf("bigquery SA credentials or dataset not configured")
+		}
+		saJSON, err := base64.StdEncoding.DecodeString(cfg.SABase64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Base64 SA JSON: %v", err)
+		}
+		creds, err := google.CredentialsFromJSON(ctx, saJSON, "https://www.googleapis.com/auth/bigquery.insertdata")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get google credentials: %v", err)
+		}
+		projectID := cfg.BigQueryProjectID
+		if projectID == "" {
+			projectID = creds.ProjectID
+		}
+		return &bigQuerySink{
+			projectID: projectID,
+			dataset:   cfg.BigQueryDataset,
+			tokens:    oauth2.ReuseTokenSource(nil, creds.TokenSource),
+			client:    httpClient,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown analytics sink %q", cfg.Sink)
+}
+
+// -------------------------------------------------------------------------
+// ClickHouse (HTTP interface, JSONEachRow)
+// -------------------------------------------------------------------------
+
+type clickHouseSink struct {
+	url      string
+	database string
+	user     string
+	password string
+	client   *http.Client
+}
+
+func (s *clickHouseSink) Name() string { return ANALYTICS_SINK_CLICKHOUSE }
+
+func (s *clickHouseSink) Insert(ctx context.Context, table string, rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return fmt.Errorf("failed to encode clickhouse row: %w", err)
+		}
+	}
+
+	if s.database != "" {
+		table = s.database + "." + table
+	}
+	// Timestamps are sent as RFC 3339
+	query := url.Values{
+		"query":                  {"INSERT INTO " + table + " FORMAT JSONEachRow"},
+		"date_time_input_format": {"best_effort"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/?"+query.Encode(), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create clickhouse request: %w", err)
+	}
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("clickhouse insert failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("clickhouse insert into %s returned %d: %s", table, resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------
+// BigQuery (tabledata.insertAll streaming inserts)
+// -------------------------------------------------------------------------
+
+type bigQuerySink struct {
+	projectID string
+	dataset   string
+	tokens    oauth2.TokenSource
+	client    *http.Client
+}
+
+type bigQueryInsertRow struct {
+	InsertID string         `json:"insertId,omitempty"`
+	JSON     map[string]any `json:"json"`
+}
+
+type bigQueryInsertResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+func (s *bigQuerySink) Name() string { return ANALYTICS_SINK_BIGQUERY }
+
+// Insert streams rows into the table. A row's "insert_id" column, when present, is also its insertId,
+// so BigQuery drops the duplicates of a retried batch.
+func (s *bigQuerySink) Insert(ctx context.Context, table string, rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	payload := struct {
+		Rows []bigQueryInsertRow `json:"rows"`
+	}{Rows: make([]bigQueryInsertRow, len(rows))}
+	for i, row := range rows {
+		id, _ := row["insert_id"].(string)
+		payload.Rows[i] = bigQueryInsertRow{InsertID: id, JSON: row}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode bigquery rows: %w", err)
+	}
+
+	token, err := s.tokens.Token()
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://bigquery.googleapis.com/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+		url.PathEscape(s.projectID), url.PathEscape(s.dataset), url.PathEscape(table))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create bigquery request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	token.SetAuthHeader(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bigquery insert failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("bigquery insert into %s returned %d: %s", table, resp.StatusCode, msg)
+	}
+
+	var result bigQueryInsertResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bigquery response: %w", err)
+	}
+	if len(result.InsertErrors) > 0 {
+		first := result.InsertErrors[0]
+		reason := "unknown"
+		if len(first.Errors) > 0 {
+			reason = first.Errors[0].Reason + ": " + first.Errors[0].Message
+		}
+		return fmt.Errorf("bigquery rejected %d rows of %s (row %d: %s)", len(result.InsertErrors), table, first.Index, reason)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// anthropicVersion is the Messages API version the request and response types follow.
+const anthropicVersion = "2023-06-01"
+
+// AnthropicClient talks to the Anthropic Messages API.
+type AnthropicClient struct {
+	baseURL   string // e.g. https://api.anthropic.com
+	apiKey    string
+	model     string
+	maxTokens int
+	client    *http.Client
+	budget    *BudgetClient
+	limiter   *RateLimiter
+}
+
+// anthropicRequest is the request body for the Messages API.
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	System    string        `json:"system,omitempty"`
+	Messages  []ChatMessage `json:"messages"`
+	Stream    bool          `json:"stream,omitempty"`
+}
+
+// anthropicResponse is the part of a Messages API response we read.
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+}
+
+// anthropicStreamEvent is the part of a streamed Messages API event we read.
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewAnthropicClient creates a new Anthropic chat client.
+func NewAnthropicClient(baseURL, apiKey, model string, maxTokens int, budget *BudgetClient, limiter *RateLimiter) *AnthropicClient {
+	return &AnthropicClient{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		apiKey:    apiKey,
+		model:     model,
+		maxTokens: maxTokens,
+		budget:    budget,
+		limiter:   limiter,
+		client: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// Configured reports whether the API key and model are set.
+func (c *AnthropicClient) Configured() bool {
+	return c.apiKey != "" && c.model != ""
+}
+
+// ChatCompletion sends a system prompt + user message and returns the assistant's response text.
+func (c *AnthropicClient) ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError) {
+	return c.ChatCompletionMultiTurn(ctx, []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	})
+}
+
+// ChatCompletionMultiTurn sends a full message history and returns the assistant's response text.
+// System messages are joined into the request's system prompt, which the Messages API keeps apart.
+func (c *AnthropicClient) ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, false)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.InternalWrap("failed to decode response", err)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", errors.Internal("no text returned from anthropic")
+	}
+	// A cut-off response is usually truncated JSON; let the next provider try
+	if result.StopReason == "max_tokens" {
+		return "", errors.Internal("anthropic response hit max_tokens")
+	}
+
+	return text.String(), nil
+}
+
+// Stream sends a full message history and passes the response text to onDelta as it arrives.
+func (c *AnthropicClient) Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, true)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	appErr = readSSE(ctx, resp.Body, func(data string) (bool, *errors.AppError) {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, errors.InternalWrap("failed to decode stream event", err)
+		}
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				text.WriteString(event.Delta.Text)
+				onDelta(event.Delta.Text)
+			}
+		case "message_delta":
+			if event.Delta.StopReason == "max_tokens" {
+				return false, errors.Internal("anthropic response hit max_tokens")
+			}
+		case "error":
+			return false, errors.InternalWrap("anthropic stream error", fmt.Errorf("%s: %s", event.Error.Type, event.Error.Message))
+		case "message_stop":
+			return true, nil
+		}
+		return false, nil
+	})
+	if appErr != nil {
+		return "", appErr
+	}
+	if text.Len() == 0 {
+		return "", errors.Internal("no text returned from anthropic")
+	}
+	return text.String(), nil
+}
+
+// send posts a Messages API request and returns the successful response for the caller to read and close.
+func (c *AnthropicClient) send(ctx context.Context, messages []ChatMessage, stream bool) (*http.Response, *errors.AppError) {
+	if !c.Configured() {
+		return nil, errors.Unsupported("anthropic not configured")
+	}
+
+	var system []string
+	turns := make([]ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+			continue
+		}
+		turns = append(turns, m)
+	}
+	if len(turns) == 0 {
+		return nil, errors.Internal("anthropic needs at least one user message")
+	}
+
+	if err := c.budget.Spend(ctx, BudgetProviderAnthropic); err != nil {
+		return nil, err
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	bodyJSON, err := json.Marshal(anthropicRequest{
+		Model:     c.model,
+		MaxTokens: c.maxTokens,
+		System:    strings.Join(system, "\n\n"),
+		Messages:  turns,
+		Stream:    stream,
+	})
+	if err != nil {
+		return nil, errors.InternalWrap("failed to marshal request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewReader(bodyJSON))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create request", err)
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, RequestError(ctx, "failed to send request", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, errors.InternalWrap("anthropic messages api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
+	}
+	return resp, nil
+}
@@ -17,6 +17,10 @@ type AzureChatGPTClient struct {
 	endpoint string // e.g. https://your-resource.openai.azure.com
 	apiKey   string
 	client   *http.Client
+	budget   *BudgetClient
+	limiter  *RateLimiter
+
+	credentials *CredentialStore // organization keys (nil = platform keys only)
 }
 
 // ChatMessage is a single message in the chat history.
@@ -29,6 +33,7 @@ type ChatMessage struct {
 type chatRequest struct {
 	Messages    []ChatMessage `json:"messages"`
 	Temperature float64       `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
 }
 
 // chatResponse is the response from the Chat Completions API.
@@ -41,21 +46,52 @@ type chatChoice struct {
 }
 
 // NewAzureChatGPTClient creates a new Azure OpenAI Chat Completions client.
-func NewAzureChatGPTClient(endpoint, apiKey string) *AzureChatGPTClient {
+func NewAzureChatGPTClient(endpoint, apiKey string, budget *BudgetClient, limiter *RateLimiter, credentials *CredentialStore, faults *FaultInjector) *AzureChatGPTClient {
 	return &AzureChatGPTClient{
-		endpoint: endpoint,
-		apiKey:   apiKey,
+		endpoint:    endpoint,
+		apiKey:      apiKey,
+		budget:      budget,
+		limiter:     limiter,
+		credentials: credentials,
 		client: &http.Client{
-			Timeout: 120 * time.Second,
+			Timeout:   120 * time.Second,
+			Transport: faults.Transport(FaultTargetAzureChat, nil),
 		},
 	}
 }
 
+// Configured reports whether the Azure OpenAI endpoint and key are set.
+func (c *AzureChatGPTClient) Configured() bool {
+	return c.endpoint != "" && c.apiKey != ""
+}
+
+// target returns the endpoint and key of a call: the organization's own when it has some.
+func (c *AzureChatGPTClient) target(ctx context.Context) (*providerTarget, *errors.AppError) {
+	target, err := c.credentials.resolveTarget(ctx, CredentialAzureOpenAI, providerTarget{endpoint: c.endpoint, secret: c.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	if target.endpoint == "" || target.secret == "" {
+		return nil, errors.Unsupported("Azure OpenAI Chat credentials not configured")
+	}
+	return target, nil
+}
+
 // ChatCompletion sends a system prompt + user message to Azure OpenAI Chat Completions
 // and returns the assistant's response text.
 func (c *AzureChatGPTClient) ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError) {
-	if c.apiKey == "" || c.endpoint == "" {
-		return "", errors.Internal("Azure OpenAI Chat credentials not configured")
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return "", appErr
+	}
+
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderChatGPT); err != nil {
+			return "", err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return "", err
 	}
 
 	reqBody := chatRequest{
@@ -72,17 +108,17 @@ func (c *AzureChatGPTClient) ChatCompletion(ctx context.Context, systemPrompt, u
 	}
 
 	// Azure OpenAI Chat Completions endpoint
-	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(bodyJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", target.endpoint, bytes.NewReader(bodyJSON))
 	if err != nil {
 		return "", errors.InternalWrap("failed to create request", err)
 	}
 
-	req.Header.Set("api-key", c.apiKey)
+	req.Header.Set("api-key", target.secret)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", errors.InternalWrap("failed to send request", err)
+		return "", RequestError(ctx, "failed to send request", err)
 	}
 	defer resp.Body.Close()
 
@@ -106,44 +142,76 @@ func (c *AzureChatGPTClient) ChatCompletion(ctx context.Context, systemPrompt, u
 // ChatCompletionMultiTurn sends a full message history to Azure OpenAI Chat Completions
 // and returns the assistant's response text. Use this for multi-turn conversations.
 func (c *AzureChatGPTClient) ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError) {
-	if c.apiKey == "" || c.endpoint == "" {
-		return "", errors.Internal("Azure OpenAI Chat credentials not configured")
+	resp, appErr := c.send(ctx, messages, false)
+	if appErr != nil {
+		return "", appErr
 	}
+	defer resp.Body.Close()
 
-	reqBody := chatRequest{Messages: messages}
+	var result chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.InternalWrap("failed to decode response", err)
+	}
+
+	if len(result.Choices) == 0 {
+		return "", errors.Internal("no choices returned from azure openai")
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+// Stream sends a full message history to Azure OpenAI Chat Completions and passes the
+// response text to onDelta as it arrives.
+func (c *AzureChatGPTClient) Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, true)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	return readChatStream(ctx, resp.Body, onDelta)
+}
+
+// send posts a Chat Completions request and returns the successful response for the caller to read and close.
+func (c *AzureChatGPTClient) send(ctx context.Context, messages []ChatMessage, stream bool) (*http.Response, *errors.AppError) {
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderChatGPT); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	reqBody := chatRequest{Messages: messages, Stream: stream}
 
 	bodyJSON, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", errors.InternalWrap("failed to marshal request", err)
+		return nil, errors.InternalWrap("failed to marshal request", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(bodyJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", target.endpoint, bytes.NewReader(bodyJSON))
 	if err != nil {
-		return "", errors.InternalWrap("failed to create request", err)
+		return nil, errors.InternalWrap("failed to create request", err)
 	}
 
-	req.Header.Set("api-key", c.apiKey)
+	req.Header.Set("api-key", target.secret)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", errors.InternalWrap("failed to send request", err)
+		return nil, RequestError(ctx, "failed to send request", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		defer resp.Body.Close()
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", errors.InternalWrap("azure openai chat api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
-	}
-
-	var result chatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", errors.InternalWrap("failed to decode response", err)
+		return nil, errors.InternalWrap("azure openai chat api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
 	}
-
-	if len(result.Choices) == 0 {
-		return "", errors.Internal("no choices returned from azure openai")
-	}
-
-	return result.Choices[0].Message.Content, nil
+	return resp, nil
 }
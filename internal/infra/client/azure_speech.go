@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/windfall/uwu_service/pkg/errors"
@@ -56,28 +57,96 @@ type AzureEvaluationSpeech struct {
 	NBest       []AzureNBest `json:"NBest"`
 }
 
+// SpeechClient synthesizes speech and assesses pronunciation (AzureSpeechClient, or FakeProviders in load tests).
+type SpeechClient interface {
+	Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError)
+	EvaluatePronunciation(ctx context.Context, audioBytes []byte, referenceText string, language string) (*AzureEvaluationSpeech, *errors.AppError)
+}
+
 // AzureSpeechClient wraps Azure AI Speech text-to-speech.
 type AzureSpeechClient struct {
-	apiKey string
-	region string
-	client *http.Client
+	apiKey  string
+	region  string
+	client  *http.Client
+	budget  *BudgetClient
+	limiter *RateLimiter
+	health  *ProviderHealthClient
+
+	credentials *CredentialStore // organization keys (nil = platform keys only)
 }
 
 // NewAzureSpeechClient creates a new Azure speech client.
-func NewAzureSpeechClient(apiKey, region string) *AzureSpeechClient {
+func NewAzureSpeechClient(apiKey, region string, budget *BudgetClient, limiter *RateLimiter, health *ProviderHealthClient, credentials *CredentialStore, faults *FaultInjector) *AzureSpeechClient {
 	return &AzureSpeechClient{
-		apiKey: apiKey,
-		region: region,
+		apiKey:      apiKey,
+		region:      region,
+		budget:      budget,
+		limiter:     limiter,
+		health:      health,
+		credentials: credentials,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: faults.Transport(FaultTargetAzureSpeech, nil),
 		},
 	}
 }
 
+// Configured reports whether the speech key and region are set.
+func (c *AzureSpeechClient) Configured() bool {
+	return c.apiKey != "" && c.region != ""
+}
+
+// target returns the region and key of a call: the organization's own when it has some.
+func (c *AzureSpeechClient) target(ctx context.Context) (*providerTarget, *errors.AppError) {
+	target, err := c.credentials.resolveTarget(ctx, CredentialAzureSpeech, providerTarget{region: c.region, secret: c.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	if target.region == "" || target.secret == "" {
+		return nil, errors.Unsupported("Azure speech credentials not configured")
+	}
+	return target, nil
+}
+
+// VoiceForLanguage returns the Azure neural voice for a learning language (e.g. "chinese").
+func VoiceForLanguage(language string) string {
+	switch strings.ToLower(language) {
+	case "chinese":
+		return "zh-CN-XiaoxiaoNeural"
+	case "japanese":
+		return "ja-JP-NanamiNeural"
+	case "french":
+		return "fr-FR-DeniseNeural"
+	case "spanish":
+		return "es-ES-ElviraNeural"
+	case "portuguese":
+		return "pt-BR-FranciscaNeural"
+	case "arabic":
+		return "ar-SA-ZariyahNeural"
+	case "russian":
+		return "ru-RU-SvetlanaNeural"
+	default:
+		return "en-US-AvaMultilingualNeural"
+	}
+}
+
 // Synthesize generates speech from text using Azure AI Speech.
 func (c *AzureSpeechClient) Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError) {
-	if c.apiKey == "" || c.region == "" {
-		return nil, errors.Internal("Azure speech credentials not configured")
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := c.health.Check(ctx, ProviderAzureSpeech); err != nil {
+		return nil, err
+	}
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderSpeechTTS); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
 	}
 
 	if voice == "" {
@@ -86,7 +155,7 @@ func (c *AzureSpeechClient) Synthesize(ctx context.Context, text, voice string)
 
 	u := url.URL{
 		Scheme: "https",
-		Host:   fmt.Sprintf("%s.tts.speech.microsoft.com", c.region),
+		Host:   fmt.Sprintf("%s.tts.speech.microsoft.com", target.region),
 		Path:   "/cognitiveservices/v1",
 	}
 
@@ -101,14 +170,14 @@ func (c *AzureSpeechClient) Synthesize(ctx context.Context, text, voice string)
 		return nil, errors.InternalWrap("failed to create azure speech request", err)
 	}
 
-	req.Header.Set("Ocp-Apim-Subscription-Key", c.apiKey)
+	req.Header.Set("Ocp-Apim-Subscription-Key", target.secret)
 	req.Header.Set("Content-Type", "application/ssml+xml")
 	req.Header.Set("X-Microsoft-OutputFormat", "audio-16khz-128kbitrate-mono-mp3")
 	req.Header.Set("User-Agent", "uwu_service")
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.InternalWrap("failed to send azure speech request", err)
+		return nil, RequestError(ctx, "failed to send azure speech request", err)
 	}
 	defer resp.Body.Close()
 
@@ -127,8 +196,21 @@ func (c *AzureSpeechClient) Synthesize(ctx context.Context, text, voice string)
 
 // EvaluatePronunciation assesses pronunciation of audio bytes against a reference text.
 func (c *AzureSpeechClient) EvaluatePronunciation(ctx context.Context, audioBytes []byte, referenceText string, language string) (*AzureEvaluationSpeech, *errors.AppError) {
-	if c.apiKey == "" || c.region == "" {
-		return nil, errors.Internal("Azure speech credentials not configured")
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := c.health.Check(ctx, ProviderAzureSpeech); err != nil {
+		return nil, err
+	}
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderAssessment); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
 	}
 
 	// Convert language to Azure Speech format
@@ -136,7 +218,7 @@ func (c *AzureSpeechClient) EvaluatePronunciation(ctx context.Context, audioByte
 
 	u := url.URL{
 		Scheme:   "https",
-		Host:     fmt.Sprintf("%s.stt.speech.microsoft.com", c.region),
+		Host:     fmt.Sprintf("%s.stt.speech.microsoft.com", target.region),
 		Path:     "/speech/recognition/conversation/cognitiveservices/v1",
 		RawQuery: fmt.Sprintf("language=%s", url.QueryEscape(language)),
 	}
@@ -163,7 +245,7 @@ func (c *AzureSpeechClient) EvaluatePronunciation(ctx context.Context, audioByte
 	// Base64 encode
 	encodedConfig := base64.StdEncoding.EncodeToString(configJSON)
 
-	req.Header.Set("Ocp-Apim-Subscription-Key", c.apiKey)
+	req.Header.Set("Ocp-Apim-Subscription-Key", target.secret)
 	req.Header.Set("Content-Type", "audio/wav; codecs=audio/pcm; samplerate=16000") // Assuming standard 16kHz WAV
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Pronunciation-Assessment", encodedConfig)
@@ -171,7 +253,7 @@ func (c *AzureSpeechClient) EvaluatePronunciation(ctx context.Context, audioByte
 	// Execute request
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.InternalWrap("failed to send azure speech recognition request", err)
+		return nil, RequestError(ctx, "failed to send azure speech recognition request", err)
 	}
 	defer resp.Body.Close()
 
@@ -260,3 +342,14 @@ func DeduplicateWords(result AzureEvaluationSpeech) AzureEvaluationSpeech {
 
 	return result
 }
+
+// Probe lists the voices of the region, which checks the key without synthesizing audio.
+func (c *AzureSpeechClient) Probe(ctx context.Context) error {
+	url := fmt.Sprintf("https://%s.tts.speech.microsoft.com/cognitiveservices/voices/list", c.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", c.apiKey)
+	return probeHTTP(c.client, req)
+}
@@ -14,11 +14,21 @@ import (
 	"github.com/windfall/uwu_service/pkg/errors"
 )
 
+// TranscriptionClient transcribes audio files (AzureWhisperClient, or FakeProviders in load tests).
+type TranscriptionClient interface {
+	TranscribeFile(ctx context.Context, wavPath, language string) (*WhisperResponse, *errors.AppError)
+}
+
 // AzureWhisperClient wraps the Azure OpenAI Whisper REST API for audio transcription.
 type AzureWhisperClient struct {
 	endpoint string // e.g. https://your-resource.openai.azure.com
 	apiKey   string
 	client   *http.Client
+	budget   *BudgetClient
+	limiter  *RateLimiter
+	health   *ProviderHealthClient
+
+	credentials *CredentialStore // organization keys (nil = platform keys only)
 }
 
 // WhisperResponse is the verbose_json response from Azure OpenAI Whisper.
@@ -33,10 +43,12 @@ type WhisperResponse struct {
 
 // WhisperSegment represents a sentence-level segment with timing.
 type WhisperSegment struct {
-	ID    int     `json:"id"`
-	Start float64 `json:"start"` // seconds
-	End   float64 `json:"end"`   // seconds
-	Text  string  `json:"text"`
+	ID           int     `json:"id"`
+	Start        float64 `json:"start"` // seconds
+	End          float64 `json:"end"`   // seconds
+	Text         string  `json:"text"`
+	AvgLogprob   float64 `json:"avg_logprob"`
+	NoSpeechProb float64 `json:"no_speech_prob"`
 }
 
 // WhisperWord represents a single word with timing (in seconds).
@@ -47,22 +59,57 @@ type WhisperWord struct {
 }
 
 // NewAzureWhisperClient creates a new Azure OpenAI Whisper client.
-func NewAzureWhisperClient(endpoint, apiKey string) *AzureWhisperClient {
+func NewAzureWhisperClient(endpoint, apiKey string, budget *BudgetClient, limiter *RateLimiter, health *ProviderHealthClient, credentials *CredentialStore, faults *FaultInjector) *AzureWhisperClient {
 	return &AzureWhisperClient{
-		endpoint: endpoint,
-		apiKey:   apiKey,
+		endpoint:    endpoint,
+		apiKey:      apiKey,
+		budget:      budget,
+		limiter:     limiter,
+		health:      health,
+		credentials: credentials,
 		client: &http.Client{
-			Timeout: 120 * time.Second, // Whisper can take longer for large files
+			Timeout:   120 * time.Second, // Whisper can take longer for large files
+			Transport: faults.Transport(FaultTargetWhisper, nil),
 		},
 	}
 }
 
+// Configured reports whether the Whisper endpoint and key are set.
+func (c *AzureWhisperClient) Configured() bool {
+	return c.endpoint != "" && c.apiKey != ""
+}
+
+// target returns the endpoint and key of a call: the organization's own when it has some.
+func (c *AzureWhisperClient) target(ctx context.Context) (*providerTarget, *errors.AppError) {
+	target, err := c.credentials.resolveTarget(ctx, CredentialAzureWhisper, providerTarget{endpoint: c.endpoint, secret: c.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	if target.endpoint == "" || target.secret == "" {
+		return nil, errors.Unsupported("Azure Whisper credentials not configured")
+	}
+	return target, nil
+}
+
 // TranscribeFile sends a WAV audio file to Azure OpenAI Whisper for transcription.
 // Returns the full WhisperResponse with word-level timestamps.
 // lang is optional (e.g. "en", "th"); if empty, Whisper auto-detects.
 func (c *AzureWhisperClient) TranscribeFile(ctx context.Context, wavPath, language string) (*WhisperResponse, *errors.AppError) {
-	if c.apiKey == "" || c.endpoint == "" {
-		return nil, errors.Internal("Azure Whisper credentials not configured")
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := c.health.Check(ctx, ProviderWhisper); err != nil {
+		return nil, err
+	}
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderWhisper); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
 	}
 
 	// Read the audio file
@@ -87,8 +134,10 @@ func (c *AzureWhisperClient) TranscribeFile(ctx context.Context, wavPath, langua
 	// Add response_format field (verbose_json for word-level timestamps)
 	_ = writer.WriteField("response_format", "verbose_json")
 
-	// Add language field
-	_ = writer.WriteField("language", language)
+	// Add language field (omitted to let Whisper auto-detect)
+	if language != "" {
+		_ = writer.WriteField("language", language)
+	}
 
 	// Add timestamp granularities (segment and word)
 	_ = writer.WriteField("timestamp_granularities[]", "segment")
@@ -97,17 +146,17 @@ func (c *AzureWhisperClient) TranscribeFile(ctx context.Context, wavPath, langua
 		return nil, errors.InternalWrap("failed to close multipart writer", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, &body)
+	req, err := http.NewRequestWithContext(ctx, "POST", target.endpoint, &body)
 	if err != nil {
 		return nil, errors.InternalWrap("failed to create request", err)
 	}
 
-	req.Header.Set("api-key", c.apiKey)
+	req.Header.Set("api-key", target.secret)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.InternalWrap("failed to send request", err)
+		return nil, RequestError(ctx, "failed to send request", err)
 	}
 	defer resp.Body.Close()
 
@@ -123,3 +172,14 @@ func (c *AzureWhisperClient) TranscribeFile(ctx context.Context, wavPath, langua
 
 	return &result, nil
 }
+
+// Probe sends an empty GET to the deployment: a rejected key or a server error fails it,
+// any other answer (the deployment only accepts POST) means Whisper is reachable.
+func (c *AzureWhisperClient) Probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("api-key", c.apiKey)
+	return probeHTTP(c.client, req)
+}
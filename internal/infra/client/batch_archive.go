@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BatchRetention is how long batches stay in Redis, with optional overrides per batch type.
+type BatchRetention struct {
+	ProcessingTTL       time.Duration
+	CompletedTTL        time.Duration
+	ProcessingTTLByType map[string]time.Duration
+	CompletedTTLByType  map[string]time.Duration
+}
+
+// Processing returns the TTL of a batch that is still running.
+func (r BatchRetention) Processing(batchType string) time.Duration {
+	if ttl, ok := r.ProcessingTTLByType[batchType]; ok && ttl > 0 {
+		return ttl
+	}
+	return r.ProcessingTTL
+}
+
+// Completed returns the TTL of a batch that has completed or failed.
+func (r BatchRetention) Completed(batchType string) time.Duration {
+	if ttl, ok := r.CompletedTTLByType[batchType]; ok && ttl > 0 {
+		return ttl
+	}
+	return r.CompletedTTL
+}
+
+// BatchArchive keeps the final state of batches in Postgres so history outlives Redis retention.
+type BatchArchive struct {
+	db *PostgresClient
+}
+
+// NewBatchArchive creates a new BatchArchive.
+func NewBatchArchive(db *PostgresClient) *BatchArchive {
+	return &BatchArchive{db: db}
+}
+
+// Save upserts the batch; a batch that is updated after it failed overwrites its earlier record.
+func (a *BatchArchive) Save(ctx context.Context, batchType string, batch *response.MetaProcessing) error {
+	if a == nil || batch == nil {
+		return nil
+	}
+
+	jobs, _ := json.Marshal(batch.BatchJobs)
+	var result []byte
+	if len(batch.Result) > 0 {
+		result = batch.Result
+	}
+	var resultURL, referenceType, referenceID *string
+	if batch.ResultURL != "" {
+		resultURL = &batch.ResultURL
+	}
+	if batch.ReferenceType != "" {
+		referenceType, referenceID = &batch.ReferenceType, &batch.ReferenceID
+	}
+
+	query := `
+		INSERT INTO batch_history (batch_id, batch_type, reference_type, reference_id, status, total_jobs, completed_jobs, jobs, result, result_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (batch_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			completed_jobs = EXCLUDED.completed_jobs,
+			jobs = EXCLUDED.jobs,
+			result = EXCLUDED.result,
+			result_url = EXCLUDED.result_url,
+			updated_at = EXCLUDED.updated_at,
+			archived_at = NOW()
+	`
+	_, err := a.db.Pool.Exec(ctx, query,
+		batch.BatchID, batchType, referenceType, referenceID, batch.Status, batch.TotalJobs, batch.CompletedJobs,
+		jobs, result, resultURL, parseBatchTime(batch.CreatedAt), parseBatchTime(batch.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive batch: %w", err)
+	}
+	return nil
+}
+
+// Get returns an archived batch, or nil when it was never archived.
+func (a *BatchArchive) Get(ctx context.Context, batchID string) (*response.MetaProcessing, error) {
+	batches, err := a.GetMany(ctx, []string{batchID})
+	if err != nil {
+		return nil, err
+	}
+	return batches[batchID], nil
+}
+
+// GetMany returns the archived batches among batchIDs by id; batches never archived are left out.
+func (a *BatchArchive) GetMany(ctx context.Context, batchIDs []string) (map[string]*response.MetaProcessing, error) {
+	batches := make(map[string]*response.MetaProcessing, len(batchIDs))
+	if a == nil || len(batchIDs) == 0 {
+		return batches, nil
+	}
+
+	query := `
+		SELECT batch_id, COALESCE(reference_type, ''), COALESCE(reference_id, ''), status, total_jobs, completed_jobs,
+			jobs, result, COALESCE(result_url, ''), created_at, updated_at
+		FROM batch_history
+		WHERE batch_id = ANY($1)
+	`
+	rows, err := a.db.Pool.Query(ctx, query, batchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived batches: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			batch     = &response.MetaProcessing{}
+			jobs      []byte
+			result    []byte
+			createdAt *time.Time
+			updatedAt *time.Time
+		)
+		if err := rows.Scan(
+			&batch.BatchID, &batch.ReferenceType, &batch.ReferenceID, &batch.Status, &batch.TotalJobs, &batch.CompletedJobs, &jobs, &result, &batch.ResultURL, &createdAt, &updatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan archived batch: %w", err)
+		}
+
+		_ = json.Unmarshal(jobs, &batch.BatchJobs)
+		if len(result) > 0 {
+			batch.Result = json.RawMessage(result)
+		}
+		batch.CreatedAt = formatBatchTime(createdAt)
+		batch.UpdatedAt = formatBatchTime(updatedAt)
+		batches[batch.BatchID] = batch
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get archived batches: %w", err)
+	}
+	return batches, nil
+}
+
+func parseBatchTime(value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func formatBatchTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,371 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// Batch and job status:
+const (
+	BatchPending    = "pending"
+	BatchProcessing = "processing"
+	BatchCompleted  = "completed"
+	BatchFailed     = "failed"
+	BatchUnknown    = "unknown"
+)
+
+// CachedBatches is the batch state every domain batch repository works on: the BatchStore in
+// Postgres, read through and written through a Redis cache that expires with the retention of
+// the batch type. Finished batches are saved to the BatchArchive and oversized results are
+// offloaded to R2. Batches created before they were kept in Postgres live in Redis only and are
+// updated there.
+//
+// The domain repositories pass the process names of a batch type; they are the job order of
+// cached batches that have no job_names field.
+type CachedBatches struct {
+	redis     *RedisClient
+	store     *BatchStore
+	archive   *BatchArchive
+	results   *BatchResultStorage
+	retention BatchRetention
+	log       *slog.Logger
+}
+
+// NewCachedBatches creates a new CachedBatches.
+func NewCachedBatches(redis *RedisClient, store *BatchStore, archive *BatchArchive, results *BatchResultStorage, retention BatchRetention, log *slog.Logger) *CachedBatches {
+	return &CachedBatches{
+		redis:     redis,
+		store:     store,
+		archive:   archive,
+		results:   results,
+		retention: retention,
+		log:       log,
+	}
+}
+
+// Get returns the full batch status including all jobs, from the Redis cache or else from
+// Postgres; nil when neither has it.
+func (c *CachedBatches) Get(ctx context.Context, batchID string, processNames []string) (*response.MetaProcessing, *errors.AppError) {
+	batch, cacheErr := c.getCached(ctx, batchID, processNames)
+	if cacheErr == nil && batch != nil {
+		return batch, nil
+	}
+
+	batch, batchType, err := c.store.Get(ctx, batchID)
+	if err != nil {
+		if cacheErr != nil {
+			return nil, cacheErr
+		}
+		return nil, errors.InternalWrap("failed to get batch", err)
+	}
+	if batch != nil && cacheErr == nil {
+		c.cache(ctx, batchType, batch)
+	}
+	return batch, nil
+}
+
+// getCached reads a batch from Redis; nil when it is not cached.
+func (c *CachedBatches) getCached(ctx context.Context, batchID string, processNames []string) (*response.MetaProcessing, *errors.AppError) {
+	batchFields, err := c.redis.HGetAll(ctx, batchKey(batchID))
+	if err != nil {
+		return nil, errors.NotFoundWrap("failed to get batch", err)
+	}
+	if len(batchFields) == 0 {
+		return nil, nil
+	}
+
+	jobFields, err := c.redis.HGetAll(ctx, batchJobsKey(batchID))
+	if err != nil {
+		return nil, errors.NotFoundWrap("failed to get jobs", err)
+	}
+	return BatchFromCache(batchID, batchFields, jobFields, processNames), nil
+}
+
+// Create initializes a batch with pending jobs in Postgres and caches it in Redis.
+func (c *CachedBatches) Create(ctx context.Context, batchID, batchType, referenceType, referenceID string, processNames []string) (*response.MetaProcessing, *errors.AppError) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	jobs := make([]response.BatchJob, 0, len(processNames))
+	for _, name := range processNames {
+		jobs = append(jobs, response.BatchJob{Name: name, Status: BatchPending})
+	}
+
+	batch := &response.MetaProcessing{
+		BatchID:       batchID,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		Status:        BatchPending,
+		TotalJobs:     len(processNames),
+		CompletedJobs: 0,
+		BatchJobs:     jobs,
+		CreatedAt:     &now,
+		UpdatedAt:     &now,
+	}
+	if err := c.store.Create(ctx, batchType, batch); err != nil {
+		c.log.Error("Failed to create batch", "batch_id", batchID, "batch_type", batchType, "error", err)
+		return nil, errors.Internal("failed to create batch")
+	}
+	c.cache(ctx, batchType, batch)
+
+	return batch, nil
+}
+
+// UpdateJob updates a single job within the batch and recalculates batch state.
+func (c *CachedBatches) UpdateJob(ctx context.Context, batchID, jobName, status, jobErr string, processNames []string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	job := response.BatchJob{
+		Name:   jobName,
+		Status: status,
+	}
+
+	switch status {
+	case BatchProcessing:
+		job.StartedAt = now
+	case BatchCompleted:
+		job.CompletedAt = now
+	case BatchFailed:
+		job.CompletedAt = now
+		job.Error = jobErr
+	}
+
+	batch, batchType, err := c.store.UpdateJob(ctx, batchID, job)
+	if err != nil {
+		c.log.Error("Failed to update batch job", "batch_id", batchID, "job_name", jobName, "error", err)
+		return err
+	}
+	if batch == nil {
+		// Created before batches were kept in Postgres; Redis has the only copy
+		return c.updateCachedJob(ctx, batchID, job, processNames)
+	}
+
+	c.cache(ctx, batchType, batch)
+	if batch.Status == BatchCompleted || batch.Status == BatchFailed {
+		if err := c.archive.Save(ctx, batchType, batch); err != nil {
+			c.log.Error("Failed to archive batch", "batch_id", batchID, "error", err)
+		}
+	}
+	return nil
+}
+
+// RetryJobs moves the named jobs back to pending when failedJob is failed and nothing of the
+// batch is running (see BatchStore.RetryJobs). It reports false, with the current batch, when
+// another retry claimed the jobs first or the batch is no longer failed.
+func (c *CachedBatches) RetryJobs(ctx context.Context, batchID, failedJob string, names []string) (*response.MetaProcessing, bool, error) {
+	batch, batchType, claimed, err := c.store.RetryJobs(ctx, batchID, failedJob, names)
+	if err != nil {
+		c.log.Error("Failed to reset batch jobs", "batch_id", batchID, "job_name", failedJob, "error", err)
+		return nil, false, err
+	}
+	if batch != nil && claimed {
+		c.cache(ctx, batchType, batch)
+	}
+	return batch, claimed, nil
+}
+
+// updateCachedJob updates a job of a batch that only Redis has and recalculates its state there.
+func (c *CachedBatches) updateCachedJob(ctx context.Context, batchID string, job response.BatchJob, processNames []string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	jobsKey := batchJobsKey(batchID)
+
+	jobJSON, _ := json.Marshal(job)
+	if err := c.redis.HSet(ctx, jobsKey, job.Name, string(jobJSON)); err != nil {
+		c.log.Error("Failed to update batch job", "batch_id", batchID, "job_name", job.Name, "error", err)
+		return err
+	}
+
+	fields, err := c.redis.HGetAll(ctx, jobsKey)
+	if err != nil {
+		return err
+	}
+
+	batchType := ""
+	if batchMeta, err := c.redis.HGetAll(ctx, batchKey(batchID)); err == nil {
+		batchType = batchMeta["type"]
+		if names := cachedJobNames(batchMeta); len(names) > 0 {
+			processNames = names
+		}
+	}
+
+	completed := 0
+	hasFailed := false
+	for _, raw := range fields {
+		var current response.BatchJob
+		if err := json.Unmarshal([]byte(raw), &current); err != nil {
+			continue
+		}
+		if current.Status == BatchCompleted {
+			completed++
+		}
+		if current.Status == BatchFailed {
+			hasFailed = true
+		}
+	}
+
+	batchStatus := BatchProcessing
+	switch {
+	case hasFailed:
+		batchStatus = BatchFailed
+	case completed == len(processNames):
+		batchStatus = BatchCompleted
+	}
+
+	if err := c.redis.HSet(ctx, batchKey(batchID),
+		"status", batchStatus,
+		"completed_jobs", strconv.Itoa(completed),
+		"updated_at", now,
+	); err != nil {
+		return err
+	}
+
+	if batchStatus == BatchCompleted || batchStatus == BatchFailed {
+		_ = c.redis.SetExpiry(ctx, batchKey(batchID), c.retention.Completed(batchType))
+		_ = c.redis.SetExpiry(ctx, jobsKey, c.retention.Completed(batchType))
+		c.archiveCached(ctx, batchID, batchType, processNames)
+	}
+
+	return nil
+}
+
+// archiveCached writes the final state of a Redis-only batch to the history; failures are logged
+// since Redis still has it.
+func (c *CachedBatches) archiveCached(ctx context.Context, batchID, batchType string, processNames []string) {
+	batch, err := c.Get(ctx, batchID, processNames)
+	if err != nil || batch == nil {
+		return
+	}
+	if err := c.archive.Save(ctx, batchType, batch); err != nil {
+		c.log.Error("Failed to archive batch", "batch_id", batchID, "error", err)
+	}
+}
+
+// SetResult stores the final serialized result of the batch, or a result_url pointing at R2
+// when the result is over the size limit.
+func (c *CachedBatches) SetResult(ctx context.Context, batchID string, result json.RawMessage) error {
+	url, err := c.results.Offload(ctx, batchID, result)
+	if err != nil {
+		c.log.Error("Failed to offload batch result", "batch_id", batchID, "size", len(result), "error", err)
+		return err
+	}
+
+	stored, err := c.store.SetResult(ctx, batchID, result, url)
+	if err != nil {
+		c.log.Error("Failed to store batch result", "batch_id", batchID, "error", err)
+		return err
+	}
+	if stored {
+		// The next read caches the batch again with its result
+		_ = c.redis.Del(ctx, batchKey(batchID), batchJobsKey(batchID))
+		return nil
+	}
+
+	if url != "" {
+		_ = c.redis.HDel(ctx, batchKey(batchID), "result")
+		return c.redis.HSet(ctx, batchKey(batchID), "result_url", url)
+	}
+
+	if err := c.redis.HSet(ctx, batchKey(batchID), "result", string(result)); err != nil {
+		c.log.Error("Failed to set batch result", "batch_id", batchID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// SetInput stores the job payload of a batch so its failed jobs can be retried.
+func (c *CachedBatches) SetInput(ctx context.Context, batchID string, input any) error {
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	if _, err := c.store.SetInput(ctx, batchID, inputJSON); err != nil {
+		c.log.Error("Failed to store batch input", "batch_id", batchID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetInput decodes the stored job payload of a batch into input. It reports false when the
+// batch has none (it was created before inputs were stored).
+func (c *CachedBatches) GetInput(ctx context.Context, batchID string, input any) (bool, error) {
+	inputJSON, err := c.store.GetInput(ctx, batchID)
+	if err != nil || inputJSON == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(inputJSON, input); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *CachedBatches) cache(ctx context.Context, batchType string, batch *response.MetaProcessing) {
+	if err := CacheBatch(ctx, c.redis, c.retention, batchType, batch); err != nil {
+		c.log.Warn("Failed to cache batch", "batch_id", batch.BatchID, "error", err)
+	}
+}
+
+// BatchFromCache builds a batch from its Redis hashes (see CacheBatch). Jobs keep the order the
+// batch was created with when it is known, else the order of processNames, else name order.
+func BatchFromCache(batchID string, batchFields, jobFields map[string]string, processNames []string) *response.MetaProcessing {
+	totalJobs, _ := strconv.Atoi(batchFields["total_jobs"])
+	completedJobs, _ := strconv.Atoi(batchFields["completed_jobs"])
+	createdAt := batchFields["created_at"]
+	updatedAt := batchFields["updated_at"]
+
+	batch := &response.MetaProcessing{
+		BatchID:       batchID,
+		ReferenceType: batchFields["reference_type"],
+		ReferenceID:   batchFields["reference_id"],
+		Status:        batchFields["status"],
+		TotalJobs:     totalJobs,
+		CompletedJobs: completedJobs,
+		CreatedAt:     &createdAt,
+		UpdatedAt:     &updatedAt,
+	}
+	if result := batchFields["result"]; result != "" {
+		batch.Result = json.RawMessage(result)
+	}
+	batch.ResultURL = batchFields["result_url"]
+
+	names := cachedJobNames(batchFields)
+	if len(names) == 0 {
+		names = processNames
+	}
+	if len(names) == 0 {
+		for name := range jobFields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		var job response.BatchJob
+		if raw, ok := jobFields[name]; !ok || json.Unmarshal([]byte(raw), &job) != nil {
+			job = response.BatchJob{Name: name, Status: BatchUnknown}
+		}
+		batch.BatchJobs = append(batch.BatchJobs, job)
+	}
+
+	return batch
+}
+
+// cachedJobNames returns the job_names field of a cached batch, or nil when it has none.
+func cachedJobNames(batchFields map[string]string) []string {
+	var names []string
+	if raw := batchFields["job_names"]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &names)
+	}
+	return names
+}
+
+func batchKey(batchID string) string {
+	return fmt.Sprintf("batch:%s", batchID)
+}
+
+func batchJobsKey(batchID string) string {
+	return fmt.Sprintf("batch:%s:jobs", batchID)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// batchEventsChannel is the Postgres NOTIFY channel BatchStore announces changed batch IDs on.
+const batchEventsChannel = "batch_events"
+
+// batchEventsRetry is how long the listener waits before reconnecting.
+const batchEventsRetry = 5 * time.Second
+
+// BatchEvents tells subscribers when a batch changes. Every instance listens on its own
+// connection, so a change is seen no matter which instance ran the job.
+type BatchEvents struct {
+	db  *PostgresClient
+	log *slog.Logger
+
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+// NewBatchEvents creates a new BatchEvents. Nothing is delivered until Run is started.
+func NewBatchEvents(db *PostgresClient, log *slog.Logger) *BatchEvents {
+	return &BatchEvents{
+		db:   db,
+		log:  log,
+		subs: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a value after the batch changes, and a function
+// that ends the subscription. Changes that arrive faster than they are read are coalesced.
+func (e *BatchEvents) Subscribe(batchID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	e.mu.Lock()
+	if e.subs[batchID] == nil {
+		e.subs[batchID] = make(map[chan struct{}]struct{})
+	}
+	e.subs[batchID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		delete(e.subs[batchID], ch)
+		if len(e.subs[batchID]) == 0 {
+			delete(e.subs, batchID)
+		}
+		e.mu.Unlock()
+	}
+}
+
+// Run listens for batch changes until ctx is cancelled, reconnecting when the connection drops.
+func (e *BatchEvents) Run(ctx context.Context) {
+	for {
+		if err := e.listen(ctx); err != nil && ctx.Err() == nil {
+			e.log.Warn("Batch events listener stopped, reconnecting", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(batchEventsRetry):
+		}
+	}
+}
+
+func (e *BatchEvents) listen(ctx context.Context) error {
+	// A dedicated connection, so the pool does not lose one to LISTEN
+	conn, err := pgx.ConnectConfig(ctx, e.db.Pool.Config().ConnConfig.Copy())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+batchEventsChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		e.publish(notification.Payload)
+	}
+}
+
+func (e *BatchEvents) publish(batchID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ch := range e.subs[batchID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BatchResultStorage moves batch results that are too large for the Redis batch hash to R2.
+type BatchResultStorage struct {
+	storage  *CloudflareClient
+	maxBytes int
+}
+
+// NewBatchResultStorage creates a BatchResultStorage; a non-positive maxBytes keeps every result in Redis.
+func NewBatchResultStorage(storage *CloudflareClient, maxBytes int) *BatchResultStorage {
+	return &BatchResultStorage{
+		storage:  storage,
+		maxBytes: maxBytes,
+	}
+}
+
+// Offload uploads the result to R2 when it is over the size limit and returns its URL.
+// An empty URL means the result is small enough to be stored inline.
+func (s *BatchResultStorage) Offload(ctx context.Context, batchID string, result []byte) (string, error) {
+	if s == nil || s.storage == nil || s.maxBytes <= 0 || len(result) <= s.maxBytes {
+		return "", nil
+	}
+
+	key := fmt.Sprintf("batches/%s/result.json", strings.ReplaceAll(batchID, ":", "/"))
+	return s.storage.UploadR2Object(ctx, key, bytes.NewReader(result), "application/json")
+}
@@ -0,0 +1,399 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// BatchStore keeps processing batches and their jobs in Postgres. It is the source of truth for
+// batch state; CachedBatches caches batches in Redis (see CacheBatch) and reads from here when
+// the cache has expired or Redis is down.
+type BatchStore struct {
+	db *PostgresClient
+}
+
+// NewBatchStore creates a new BatchStore.
+func NewBatchStore(db *PostgresClient) *BatchStore {
+	return &BatchStore{db: db}
+}
+
+// batchQuerier is what loading jobs needs from a pool or a transaction.
+type batchQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Create stores a new batch with its jobs in the order they are listed.
+func (s *BatchStore) Create(ctx context.Context, batchType string, batch *response.MetaProcessing) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO batches (batch_id, batch_type, reference_type, reference_id, status, total_jobs, completed_jobs, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, COALESCE($8, NOW()), COALESCE($9, NOW()))
+	`, batch.BatchID, batchType, batch.ReferenceType, batch.ReferenceID, batch.Status, batch.TotalJobs, batch.CompletedJobs,
+		parseBatchTime(batch.CreatedAt), parseBatchTime(batch.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to store batch: %w", err)
+	}
+
+	for i, job := range batch.BatchJobs {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO batch_jobs (batch_id, name, position, status)
+			VALUES ($1, $2, $3, $4)
+		`, batch.BatchID, job.Name, i+1, job.Status); err != nil {
+			return fmt.Errorf("failed to store batch job: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return nil
+}
+
+// Get returns a batch with its type, or nil when it is not stored.
+func (s *BatchStore) Get(ctx context.Context, batchID string) (*response.MetaProcessing, string, error) {
+	batches, types, err := s.getMany(ctx, s.db.Pool, []string{batchID})
+	if err != nil {
+		return nil, "", err
+	}
+	return batches[batchID], types[batchID], nil
+}
+
+// GetMany returns the stored batches among batchIDs by id; batches that are not stored are left out.
+func (s *BatchStore) GetMany(ctx context.Context, batchIDs []string) (map[string]*response.MetaProcessing, error) {
+	batches, _, err := s.getMany(ctx, s.db.Pool, batchIDs)
+	return batches, err
+}
+
+// UpdateJob replaces one job of the batch and recalculates the batch state the same way for
+// every domain: failed once a job failed, completed once every job completed, processing
+// otherwise. It returns the updated batch and its type, or nil when the batch is not stored
+// (it was created before batches were kept in Postgres).
+func (s *BatchStore) UpdateJob(ctx context.Context, batchID string, job response.BatchJob) (*response.MetaProcessing, string, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Jobs of one batch run concurrently; the row lock keeps the recount consistent
+	var locked int
+	err = tx.QueryRow(ctx, `SELECT 1 FROM batches WHERE batch_id = $1 FOR UPDATE`, batchID).Scan(&locked)
+	if err == pgx.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to lock batch: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO batch_jobs (batch_id, name, position, status, error, started_at, completed_at)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM batch_jobs WHERE batch_id = $1), $3, NULLIF($4, ''), $5, $6)
+		ON CONFLICT (batch_id, name) DO UPDATE SET
+			status = EXCLUDED.status,
+			error = EXCLUDED.error,
+			started_at = EXCLUDED.started_at,
+			completed_at = EXCLUDED.completed_at
+	`, batchID, job.Name, job.Status, job.Error, parseBatchTimeString(job.StartedAt), parseBatchTimeString(job.CompletedAt))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update batch job: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE batches b SET
+			completed_jobs = c.completed,
+			status = CASE
+				WHEN c.failed > 0 THEN 'failed'
+				WHEN c.completed = b.total_jobs THEN 'completed'
+				ELSE 'processing'
+			END,
+			updated_at = NOW()
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE status = 'completed') AS completed, COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM batch_jobs WHERE batch_id = $1
+		) c
+		WHERE b.batch_id = $1
+	`, batchID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update batch: %w", err)
+	}
+
+	// Delivered to BatchEvents listeners on commit
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, batchEventsChannel, batchID); err != nil {
+		return nil, "", fmt.Errorf("failed to notify batch change: %w", err)
+	}
+
+	batches, types, err := s.getMany(ctx, tx, []string{batchID})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to commit batch job: %w", err)
+	}
+	return batches[batchID], types[batchID], nil
+}
+
+// RetryJobs moves the named jobs back to pending so a failed job can run again, provided the
+// failed job is still failed and no job of the batch is pending or processing. The check and
+// the update are one statement under the batch row lock, so of two concurrent retries only one
+// claims the jobs. It returns the updated batch and its type and whether the jobs were claimed;
+// the batch is nil when it is not stored.
+func (s *BatchStore) RetryJobs(ctx context.Context, batchID, failedJob string, names []string) (*response.MetaProcessing, string, bool, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked int
+	err = tx.QueryRow(ctx, `SELECT 1 FROM batches WHERE batch_id = $1 FOR UPDATE`, batchID).Scan(&locked)
+	if err == pgx.ErrNoRows {
+		return nil, "", false, nil
+	}
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to lock batch: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE batch_jobs SET status = 'pending', error = NULL, started_at = NULL, completed_at = NULL
+		WHERE batch_id = $1 AND name = ANY($2)
+			AND EXISTS (SELECT 1 FROM batch_jobs WHERE batch_id = $1 AND name = $3 AND status = 'failed')
+			AND NOT EXISTS (SELECT 1 FROM batch_jobs WHERE batch_id = $1 AND status IN ('pending', 'processing'))
+	`, batchID, names, failedJob)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to reset batch jobs: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		batches, types, err := s.getMany(ctx, tx, []string{batchID})
+		if err != nil {
+			return nil, "", false, err
+		}
+		return batches[batchID], types[batchID], false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE batches b SET
+			completed_jobs = (SELECT COUNT(*) FROM batch_jobs WHERE batch_id = $1 AND status = 'completed'),
+			status = 'pending',
+			updated_at = NOW()
+		WHERE b.batch_id = $1
+	`, batchID)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to update batch: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, batchEventsChannel, batchID); err != nil {
+		return nil, "", false, fmt.Errorf("failed to notify batch change: %w", err)
+	}
+
+	batches, types, err := s.getMany(ctx, tx, []string{batchID})
+	if err != nil {
+		return nil, "", false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", false, fmt.Errorf("failed to commit batch retry: %w", err)
+	}
+	return batches[batchID], types[batchID], true, nil
+}
+
+// SetResult stores the final result, or the URL it was offloaded to. It reports whether the
+// batch is stored.
+func (s *BatchStore) SetResult(ctx context.Context, batchID string, result json.RawMessage, resultURL string) (bool, error) {
+	var stored []byte
+	if resultURL == "" && len(result) > 0 {
+		stored = result
+	}
+	var found bool
+	err := s.db.Pool.QueryRow(ctx, `
+		WITH updated AS (
+			UPDATE batches SET result = $2, result_url = NULLIF($3, ''), updated_at = NOW()
+			WHERE batch_id = $1
+			RETURNING batch_id
+		)
+		SELECT COUNT(*) > 0 FROM (SELECT pg_notify($4, batch_id) FROM updated) notified
+	`, batchID, stored, resultURL, batchEventsChannel).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("failed to store batch result: %w", err)
+	}
+	return found, nil
+}
+
+// SetInput stores the job input a failed job of the batch is retried with. It reports whether
+// the batch is stored.
+func (s *BatchStore) SetInput(ctx context.Context, batchID string, input json.RawMessage) (bool, error) {
+	tag, err := s.db.Pool.Exec(ctx, `UPDATE batches SET input = $2 WHERE batch_id = $1`, batchID, []byte(input))
+	if err != nil {
+		return false, fmt.Errorf("failed to store batch input: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetInput returns the stored job input of a batch, or nil when it has none.
+func (s *BatchStore) GetInput(ctx context.Context, batchID string) (json.RawMessage, error) {
+	var input []byte
+	err := s.db.Pool.QueryRow(ctx, `SELECT input FROM batches WHERE batch_id = $1`, batchID).Scan(&input)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch input: %w", err)
+	}
+	if len(input) == 0 {
+		return nil, nil
+	}
+	return json.RawMessage(input), nil
+}
+
+func (s *BatchStore) getMany(ctx context.Context, q batchQuerier, batchIDs []string) (map[string]*response.MetaProcessing, map[string]string, error) {
+	batches := make(map[string]*response.MetaProcessing, len(batchIDs))
+	types := make(map[string]string, len(batchIDs))
+	if s == nil || len(batchIDs) == 0 {
+		return batches, types, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT batch_id, batch_type, COALESCE(reference_type, ''), COALESCE(reference_id, ''), status, total_jobs, completed_jobs,
+			result, COALESCE(result_url, ''), created_at, updated_at
+		FROM batches
+		WHERE batch_id = ANY($1)
+	`, batchIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get batches: %w", err)
+	}
+	for rows.Next() {
+		var (
+			batch     = &response.MetaProcessing{BatchJobs: make([]response.BatchJob, 0)}
+			batchType string
+			result    []byte
+			createdAt *time.Time
+			updatedAt *time.Time
+		)
+		if err := rows.Scan(&batch.BatchID, &batchType, &batch.ReferenceType, &batch.ReferenceID, &batch.Status, &batch.TotalJobs,
+			&batch.CompletedJobs, &result, &batch.ResultURL, &createdAt, &updatedAt); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan batch: %w", err)
+		}
+		if len(result) > 0 {
+			batch.Result = json.RawMessage(result)
+		}
+		batch.CreatedAt = formatBatchTime(createdAt)
+		batch.UpdatedAt = formatBatchTime(updatedAt)
+		batches[batch.BatchID] = batch
+		types[batch.BatchID] = batchType
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get batches: %w", err)
+	}
+	if len(batches) == 0 {
+		return batches, types, nil
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT batch_id, name, status, COALESCE(error, ''), started_at, completed_at
+		FROM batch_jobs
+		WHERE batch_id = ANY($1)
+		ORDER BY batch_id, position
+	`, batchIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get batch jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			batchID     string
+			job         response.BatchJob
+			startedAt   *time.Time
+			completedAt *time.Time
+		)
+		if err := rows.Scan(&batchID, &job.Name, &job.Status, &job.Error, &startedAt, &completedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan batch job: %w", err)
+		}
+		if startedAt != nil {
+			job.StartedAt = *formatBatchTime(startedAt)
+		}
+		if completedAt != nil {
+			job.CompletedAt = *formatBatchTime(completedAt)
+		}
+		if batch, ok := batches[batchID]; ok {
+			batch.BatchJobs = append(batch.BatchJobs, job)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get batch jobs: %w", err)
+	}
+	return batches, types, nil
+}
+
+// CacheBatch writes a batch and its jobs to Redis in the layout the batch repositories read,
+// expiring with the retention of its type and status.
+func CacheBatch(ctx context.Context, redis *RedisClient, retention BatchRetention, batchType string, batch *response.MetaProcessing) error {
+	metaKey := batchKey(batch.BatchID)
+	jobsKey := batchJobsKey(batch.BatchID)
+
+	names := make([]string, 0, len(batch.BatchJobs))
+	jobs := make([]interface{}, 0, 2*len(batch.BatchJobs))
+	for _, job := range batch.BatchJobs {
+		jobJSON, _ := json.Marshal(job)
+		names = append(names, job.Name)
+		jobs = append(jobs, job.Name, string(jobJSON))
+	}
+	namesJSON, _ := json.Marshal(names)
+
+	fields := []interface{}{
+		"type", batchType,
+		"reference_type", batch.ReferenceType,
+		"reference_id", batch.ReferenceID,
+		"status", batch.Status,
+		"total_jobs", strconv.Itoa(batch.TotalJobs),
+		"completed_jobs", strconv.Itoa(batch.CompletedJobs),
+		"job_names", string(namesJSON),
+	}
+	if batch.CreatedAt != nil {
+		fields = append(fields, "created_at", *batch.CreatedAt)
+	}
+	if batch.UpdatedAt != nil {
+		fields = append(fields, "updated_at", *batch.UpdatedAt)
+	}
+	if len(batch.Result) > 0 {
+		fields = append(fields, "result", string(batch.Result))
+	}
+	if batch.ResultURL != "" {
+		fields = append(fields, "result_url", batch.ResultURL)
+	}
+
+	if err := redis.HSet(ctx, metaKey, fields...); err != nil {
+		return err
+	}
+	if len(jobs) > 0 {
+		if err := redis.HSet(ctx, jobsKey, jobs...); err != nil {
+			return err
+		}
+	}
+
+	ttl := retention.Processing(batchType)
+	if batch.Status == BatchCompleted || batch.Status == BatchFailed {
+		ttl = retention.Completed(batchType)
+	}
+	_ = redis.SetExpiry(ctx, metaKey, ttl)
+	_ = redis.SetExpiry(ctx, jobsKey, ttl)
+	return nil
+}
+
+func parseBatchTimeString(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	return parseBatchTime(&value)
+}
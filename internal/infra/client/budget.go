@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// BudgetProvider identifies a priced AI provider.
+type BudgetProvider string
+
+const (
+	BudgetProviderChatGPT    BudgetProvider = "chatgpt"
+	BudgetProviderWhisper    BudgetProvider = "whisper"
+	BudgetProviderSpeechTTS  BudgetProvider = "speech_tts"
+	BudgetProviderAssessment BudgetProvider = "speech_assessment"
+	BudgetProviderImage      BudgetProvider = "image"
+	BudgetProviderOCR        BudgetProvider = "ocr"
+	BudgetProviderGemini     BudgetProvider = "gemini"
+	BudgetProviderGateway    BudgetProvider = "gateway"
+	BudgetProviderAnthropic  BudgetProvider = "anthropic"
+)
+
+const (
+	budgetDailyKeyTTL = 48 * time.Hour
+	budgetBatchKeyTTL = 3 * time.Hour
+)
+
+// BudgetOptions holds the per-call prices (USD) and the spend ceilings.
+// A ceiling of 0 disables that check.
+type BudgetOptions struct {
+	Prices     map[BudgetProvider]float64
+	DailyLimit float64
+	BatchLimit float64
+}
+
+// BudgetClient estimates the cost of AI calls and tracks spend per batch and per day in Redis.
+type BudgetClient struct {
+	redis *RedisClient
+	opts  BudgetOptions
+	log   *slog.Logger
+}
+
+// NewBudgetClient creates a new budget client.
+func NewBudgetClient(redis *RedisClient, opts BudgetOptions, log *slog.Logger) *BudgetClient {
+	return &BudgetClient{redis: redis, opts: opts, log: log}
+}
+
+type budgetBatchKey struct{}
+
+// WithBudgetBatch attaches a batch ID to ctx so AI calls made with it count toward that batch.
+func WithBudgetBatch(ctx context.Context, batchID string) context.Context {
+	return context.WithValue(ctx, budgetBatchKey{}, batchID)
+}
+
+func budgetBatchFromContext(ctx context.Context) string {
+	batchID, _ := ctx.Value(budgetBatchKey{}).(string)
+	return batchID
+}
+
+func budgetDailyKey(now time.Time) string {
+	return fmt.Sprintf("budget:daily:%s", now.UTC().Format("2006-01-02"))
+}
+
+func budgetBatchRedisKey(batchID string) string {
+	return fmt.Sprintf("budget:batch:%s", batchID)
+}
+
+// CheckDaily refuses new generation jobs once today's spend reached the daily ceiling.
+func (c *BudgetClient) CheckDaily(ctx context.Context) *errors.AppError {
+	if c == nil || c.opts.DailyLimit <= 0 {
+		return nil
+	}
+
+	spent, err := c.redis.GetFloat(ctx, budgetDailyKey(time.Now()))
+	if err != nil {
+		// Budget tracking must not take the service down
+		c.log.Warn("Failed to read daily spend", "error", err.Error())
+		return nil
+	}
+	if spent >= c.opts.DailyLimit {
+		return errors.BudgetExceeded("daily AI budget exceeded, please try again tomorrow").
+			WithDetails(map[string]interface{}{"spent": spent, "limit": c.opts.DailyLimit})
+	}
+	return nil
+}
+
+// Spend records the estimated cost of one call to provider.
+// When ctx carries a batch ID, the call is refused if it would push the batch past its ceiling.
+func (c *BudgetClient) Spend(ctx context.Context, provider BudgetProvider) *errors.AppError {
+	return c.SpendUnits(ctx, provider, 1)
+}
+
+// SpendUnits records the cost of a call priced per unit, e.g. an image request returning several images.
+func (c *BudgetClient) SpendUnits(ctx context.Context, provider BudgetProvider, units int) *errors.AppError {
+	if c == nil {
+		return nil
+	}
+
+	cost := c.opts.Prices[provider] * float64(units)
+	if cost <= 0 {
+		return nil
+	}
+
+	if batchID := budgetBatchFromContext(ctx); batchID != "" {
+		key := budgetBatchRedisKey(batchID)
+		spent, err := c.redis.IncrByFloat(ctx, key, cost)
+		if err != nil {
+			c.log.Warn("Failed to record batch spend", "batch_id", batchID, "error", err.Error())
+		} else {
+			_ = c.redis.SetExpiry(ctx, key, budgetBatchKeyTTL)
+			if c.opts.BatchLimit > 0 && spent > c.opts.BatchLimit {
+				// Roll back so the refused call is not counted
+				_, _ = c.redis.IncrByFloat(ctx, key, -cost)
+				return errors.BudgetExceeded("batch AI budget exceeded").
+					WithDetails(map[string]interface{}{"batch_id": batchID, "limit": c.opts.BatchLimit})
+			}
+		}
+	}
+
+	key := budgetDailyKey(time.Now())
+	if _, err := c.redis.IncrByFloat(ctx, key, cost); err != nil {
+		c.log.Warn("Failed to record daily spend", "provider", string(provider), "error", err.Error())
+		return nil
+	}
+	_ = c.redis.SetExpiry(ctx, key, budgetDailyKeyTTL)
+	return nil
+}
@@ -0,0 +1,82 @@
+package client
+
+// Capabilities the server may offer, depending on which providers are configured
+const (
+	CapabilityChat            = "chat"             // script generation, chat replies, evaluations
+	CapabilityTTS             = "tts"              // dialog, sparring and vocabulary audio
+	CapabilitySTT             = "stt"              // video transcripts, retell and sparring transcription
+	CapabilityPronunciation   = "pronunciation"    // speaking assessment
+	CapabilityImageGeneration = "image_generation" // dialog background images
+	CapabilityEmbeddings      = "embeddings"       // content clustering
+	CapabilityAnalyticsExport = "analytics_export" // export to the analytics warehouse
+)
+
+// Capability is one capability and the configured providers that back it.
+type Capability struct {
+	Name      string   `json:"name"`
+	Available bool     `json:"available"`
+	Providers []string `json:"providers"`
+}
+
+// Capabilities is computed once at startup from the configured clients. Routes check it up front,
+// so a request that needs a missing provider is rejected instead of failing deep inside a batch.
+type Capabilities struct {
+	order  []string
+	byName map[string]*Capability
+}
+
+// NewCapabilities creates an empty capability registry.
+func NewCapabilities() *Capabilities {
+	return &Capabilities{byName: make(map[string]*Capability)}
+}
+
+// Set records the providers of a capability; it is available when there is at least one.
+func (c *Capabilities) Set(name string, providers ...string) {
+	if _, ok := c.byName[name]; !ok {
+		c.order = append(c.order, name)
+	}
+	c.byName[name] = &Capability{
+		Name:      name,
+		Available: len(providers) > 0,
+		Providers: append([]string{}, providers...),
+	}
+}
+
+// Has reports whether a capability is available.
+func (c *Capabilities) Has(name string) bool {
+	capability, ok := c.byName[name]
+	return ok && capability.Available
+}
+
+// Missing returns the names that are not available, in the given order.
+func (c *Capabilities) Missing(names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if !c.Has(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// List returns every capability in the order they were set.
+func (c *Capabilities) List() []Capability {
+	list := make([]Capability, 0, len(c.order))
+	for _, name := range c.order {
+		list = append(list, *c.byName[name])
+	}
+	return list
+}
+
+// ConfiguredProviders returns the names of the providers whose client is configured, in order.
+// Clients that cannot tell are assumed to be configured.
+func ConfiguredProviders(providers []ChatProvider) []string {
+	names := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if c, ok := provider.Client.(interface{ Configured() bool }); ok && !c.Configured() {
+			continue
+		}
+		names = append(names, provider.Name)
+	}
+	return names
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// ChatClient is implemented by every chat-completion provider.
+type ChatClient interface {
+	ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError)
+	ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError)
+	// Stream is like ChatCompletionMultiTurn, but passes each piece of the response text to
+	// onDelta as it arrives. It still returns the whole text.
+	Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError)
+}
+
+// Chat features that can be routed to their own provider chain.
+const (
+	ChatFeatureVideoDetails      = "video_details"
+	ChatFeatureRetellEvaluation  = "retell_evaluation"
+	ChatFeatureDialogGeneration  = "dialog_generation"
+	ChatFeatureChatReply         = "chat_reply"
+	ChatFeatureVocabulary        = "vocabulary"
+	ChatFeatureChapters          = "chapters"
+	ChatFeatureMemorySummary     = "memory_summary"
+	ChatFeatureTranscriptSummary = "transcript_summary"
+	ChatFeatureQualityReview     = "quality_review"
+)
+
+type chatFeatureKey struct{}
+
+// WithChatFeature tags ctx with the feature making the chat call, so a feature-specific chain can be used.
+func WithChatFeature(ctx context.Context, feature string) context.Context {
+	return context.WithValue(ctx, chatFeatureKey{}, feature)
+}
+
+type chatProviderKey struct{}
+
+// WithChatProvider pins the chat calls made with ctx to one named provider, overriding the default
+// and feature chains. An empty name keeps the chains.
+func WithChatProvider(ctx context.Context, provider string) context.Context {
+	if provider == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, chatProviderKey{}, provider)
+}
+
+// ChatProviderFromContext returns the provider pinned with WithChatProvider, or "".
+func ChatProviderFromContext(ctx context.Context) string {
+	provider, _ := ctx.Value(chatProviderKey{}).(string)
+	return provider
+}
+
+// ValidatedChatCompletion calls ChatCompletion and checks the response with validate.
+// A FallbackChatClient falls through to its next provider when validation fails.
+func ValidatedChatCompletion(ctx context.Context, c ChatClient, systemPrompt, userMessage string, validate func(string) *errors.AppError) (string, *errors.AppError) {
+	if fc, ok := c.(*FallbackChatClient); ok {
+		return fc.ChatCompletionValidated(ctx, systemPrompt, userMessage, validate)
+	}
+
+	text, err := c.ChatCompletion(ctx, systemPrompt, userMessage)
+	if err != nil {
+		return "", err
+	}
+	if err := validate(text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// ChatProvider is a named chat client in a fallback chain.
+type ChatProvider struct {
+	Name   string
+	Client ChatClient
+}
+
+// FallbackChatClient tries each provider in order and returns the first successful response.
+// Features may override the default chain, and a request may pin one selectable provider.
+type FallbackChatClient struct {
+	providers  []ChatProvider
+	features   map[string][]ChatProvider
+	selectable map[string]ChatProvider
+	log        *slog.Logger
+}
+
+// NewFallbackChatClient creates a chat client that falls back through providers in order.
+func NewFallbackChatClient(providers []ChatProvider, log *slog.Logger) *FallbackChatClient {
+	return &FallbackChatClient{
+		providers:  providers,
+		features:   make(map[string][]ChatProvider),
+		selectable: make(map[string]ChatProvider),
+		log:        log,
+	}
+}
+
+// SetFeatureChain routes calls tagged with feature (see WithChatFeature) to providers instead of the default chain.
+// Call it during setup, before the client is used.
+func (c *FallbackChatClient) SetFeatureChain(feature string, providers []ChatProvider) {
+	c.features[feature] = providers
+}
+
+// AddSelectable lets requests pin provider with WithChatProvider. Call it during setup, before the client is used.
+func (c *FallbackChatClient) AddSelectable(provider ChatProvider) {
+	c.selectable[provider.Name] = provider
+}
+
+// Selectable reports whether requests may pin the named provider.
+func (c *FallbackChatClient) Selectable(name string) bool {
+	_, ok := c.selectable[name]
+	return ok
+}
+
+// ChatCompletion sends a system prompt + user message to the first provider that succeeds.
+func (c *FallbackChatClient) ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError) {
+	return c.try(ctx, func(p ChatProvider) (string, *errors.AppError) {
+		return p.Client.ChatCompletion(ctx, systemPrompt, userMessage)
+	})
+}
+
+// ChatCompletionValidated is like ChatCompletion, but a response rejected by validate
+// counts as a provider failure and the next provider is tried.
+func (c *FallbackChatClient) ChatCompletionValidated(ctx context.Context, systemPrompt, userMessage string, validate func(string) *errors.AppError) (string, *errors.AppError) {
+	return c.try(ctx, func(p ChatProvider) (string, *errors.AppError) {
+		text, err := p.Client.ChatCompletion(ctx, systemPrompt, userMessage)
+		if err != nil {
+			return "", err
+		}
+		if err := validate(text); err != nil {
+			return "", err
+		}
+		return text, nil
+	})
+}
+
+// ChatCompletionMultiTurn sends a full message history to the first provider that succeeds.
+func (c *FallbackChatClient) ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError) {
+	return c.try(ctx, func(p ChatProvider) (string, *errors.AppError) {
+		return p.Client.ChatCompletionMultiTurn(ctx, messages)
+	})
+}
+
+// Stream streams a full message history from the first provider that succeeds. Once a provider
+// has sent part of the response, its failure ends the stream: the caller already has that text.
+func (c *FallbackChatClient) Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError) {
+	providers, appErr := c.chain(ctx)
+	if appErr != nil {
+		return "", appErr
+	}
+
+	var lastErr *errors.AppError
+	for _, p := range providers {
+		started := false
+		text, err := p.Client.Stream(ctx, messages, func(delta string) {
+			started = true
+			onDelta(delta)
+		})
+		if err == nil {
+			return text, nil
+		}
+		if started || stopsFallback(err) {
+			return "", err
+		}
+
+		c.log.Warn("Chat provider failed, trying next", "provider", p.Name, "error", err.Error())
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+func (c *FallbackChatClient) try(ctx context.Context, call func(p ChatProvider) (string, *errors.AppError)) (string, *errors.AppError) {
+	providers, appErr := c.chain(ctx)
+	if appErr != nil {
+		return "", appErr
+	}
+
+	var lastErr *errors.AppError
+	for _, p := range providers {
+		text, err := call(p)
+		if err == nil {
+			return text, nil
+		}
+		if stopsFallback(err) {
+			return "", err
+		}
+
+		c.log.Warn("Chat provider failed, trying next", "provider", p.Name, "error", err.Error())
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// chain returns the providers of a call: the pinned provider, the feature's chain or the default chain.
+func (c *FallbackChatClient) chain(ctx context.Context) ([]ChatProvider, *errors.AppError) {
+	providers := c.providers
+	if feature, _ := ctx.Value(chatFeatureKey{}).(string); feature != "" {
+		if chain, ok := c.features[feature]; ok {
+			providers = chain
+		}
+	}
+	if name := ChatProviderFromContext(ctx); name != "" {
+		provider, ok := c.selectable[name]
+		if !ok {
+			return nil, errors.Validation("chat provider " + name + " cannot be selected")
+		}
+		providers = []ChatProvider{provider}
+	}
+	if len(providers) == 0 {
+		return nil, errors.Internal("no chat provider configured")
+	}
+	return providers, nil
+}
+
+// stopsFallback reports whether err applies to every provider. Budget and cancellation errors do.
+func stopsFallback(err *errors.AppError) bool {
+	return err.GetCode() == string(errors.ErrBudget) || err.GetCode() == string(errors.ErrTimeout)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maxStreamLine is the longest Server-Sent Events line a chat stream may send.
+const maxStreamLine = 1 << 20
+
+// readSSE calls onData with the data of every Server-Sent Event in body until it reports done or
+// the body ends. Event names are ignored; every provider we stream from repeats the type in the data.
+func readSSE(ctx context.Context, body io.Reader, onData func(data string) (bool, *errors.AppError)) *errors.AppError {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		done, err := onData(strings.TrimSpace(data))
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return RequestError(ctx, "failed to read stream", err)
+	}
+	return nil
+}
+
+// chatStreamChunk is one event of a streamed (OpenAI-style) Chat Completions response.
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// readChatStream reads a streamed Chat Completions response, as sent by Azure OpenAI and
+// OpenAI-compatible gateways, passing each piece of content to onDelta. It returns the whole reply.
+func readChatStream(ctx context.Context, body io.Reader, onDelta func(string)) (string, *errors.AppError) {
+	var text strings.Builder
+	err := readSSE(ctx, body, func(data string) (bool, *errors.AppError) {
+		if data == "[DONE]" {
+			return true, nil
+		}
+		var chunk chatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, errors.InternalWrap("failed to decode stream chunk", err)
+		}
+		// Azure sends a first chunk without choices (the prompt filter results)
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				onDelta(choice.Delta.Content)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if text.Len() == 0 {
+		return "", errors.Internal("empty stream returned from chat provider")
+	}
+	return text.String(), nil
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -16,10 +20,12 @@ type CloudflareClient struct {
 	s3Client *s3.Client
 	bucket   string
 	cdnURL   string
+	health   *ProviderHealthClient
+	upload   R2UploadConfig
 }
 
 // NewCloudflareClient creates a new Cloudflare R2 client.
-func NewCloudflareClient(ctx context.Context, accessKeyID, secretKey, endpoint, bucketName, cdnURL string) (*CloudflareClient, error) {
+func NewCloudflareClient(ctx context.Context, accessKeyID, secretKey, endpoint, bucketName, cdnURL string, health *ProviderHealthClient, upload R2UploadConfig, faults *FaultInjector) (*CloudflareClient, error) {
 	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretKey, "")),
 		config.WithRegion("auto"),
@@ -30,33 +36,134 @@ func NewCloudflareClient(ctx context.Context, accessKeyID, secretKey, endpoint,
 
 	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
 		o.BaseEndpoint = aws.String(endpoint)
+		if faults != nil {
+			o.HTTPClient = &http.Client{Transport: faults.Transport(FaultTargetR2, awshttp.NewBuildableClient().GetTransport())}
+		}
 	})
 
 	return &CloudflareClient{
 		s3Client: s3Client,
 		bucket:   bucketName,
 		cdnURL:   cdnURL,
+		health:   health,
+		upload:   upload.withDefaults(),
 	}, nil
 }
 
-// UploadR2Object uploads an object to R2 and returns the public URL.
+// UploadR2Object uploads an object to R2 and returns the public URL. Large objects are uploaded
+// in parts, failed requests are retried with backoff, and the object's SHA-256 is stored in its
+// metadata and checked once the upload completes.
 func (c *CloudflareClient) UploadR2Object(ctx context.Context, key string, data io.Reader, contentType string) (string, error) {
-	// PutObject API
-	_, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(c.bucket),
-		Key:         aws.String(key),
-		Body:        data,
-		ContentType: aws.String(contentType),
-	})
+	if err := c.health.Check(ctx, ProviderR2); err != nil {
+		return "", err
+	}
+
+	src, err := newUploadSource(data)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload to R2: %w", err)
 	}
+	defer src.cleanup()
+
+	if src.size >= c.upload.MultipartThreshold {
+		err = c.putMultipart(ctx, key, contentType, src)
+	} else {
+		err = c.putObject(ctx, key, contentType, src)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to R2: %w", err)
+	}
+
+	if err := c.verifyUpload(ctx, key, src); err != nil {
+		return "", fmt.Errorf("failed to verify R2 upload: %w", err)
+	}
 
 	// Return the public URL
 	return fmt.Sprintf("%s/%s", c.cdnURL, key), nil
 }
 
+// DeleteR2Object removes an object from R2.
+func (c *CloudflareClient) DeleteR2Object(ctx context.Context, key string) error {
+	_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete from R2: %w", err)
+	}
+	return nil
+}
+
 // GetR2ObjectURL returns the public URL for a given key.
 func (c *CloudflareClient) GetR2ObjectURL(key string) string {
 	return fmt.Sprintf("%s/%s", c.cdnURL, key)
 }
+
+// GetR2Object opens an object for reading; the caller closes it.
+func (c *CloudflareClient) GetR2Object(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get from R2: %w", err)
+	}
+	return out.Body, nil
+}
+
+// PresignR2Object returns a signed download URL for a private object, valid for ttl.
+func (c *CloudflareClient) PresignR2Object(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(c.s3Client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign R2 object: %w", err)
+	}
+	return req.URL, nil
+}
+
+// R2Object is a stored object as listed by ListR2Objects.
+type R2Object struct {
+	Key  string
+	Size int64
+}
+
+// ListR2Objects calls fn with every object whose key starts with prefix ("" lists the whole bucket),
+// a page at a time, stopping at the first error.
+func (c *CloudflareClient) ListR2Objects(ctx context.Context, prefix string, fn func([]R2Object) error) error {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(c.bucket)}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	paginator := s3.NewListObjectsV2Paginator(c.s3Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list R2 objects: %w", err)
+		}
+
+		objects := make([]R2Object, 0, len(page.Contents))
+		for _, obj := range page.Contents {
+			objects = append(objects, R2Object{Key: aws.ToString(obj.Key), Size: aws.ToInt64(obj.Size)})
+		}
+		if err := fn(objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// R2KeyFromURL returns the object key of a public URL built by GetR2ObjectURL.
+func (c *CloudflareClient) R2KeyFromURL(url string) (string, bool) {
+	prefix := c.cdnURL + "/"
+	if c.cdnURL == "" || !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
+
+// Probe checks the bucket is reachable with the configured credentials.
+func (c *CloudflareClient) Probe(ctx context.Context) error {
+	_, err := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(c.bucket)})
+	return err
+}
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// R2 multipart limits (parts other than the last must be at least 5 MiB, at most 10000 parts)
+const (
+	minR2PartSize = 5 << 20
+	maxR2Parts    = 10000
+)
+
+// R2ChecksumMetadataKey is the object metadata key holding the hex SHA-256 of the uploaded content.
+const R2ChecksumMetadataKey = "sha256"
+
+// R2UploadConfig controls how objects are uploaded to R2.
+type R2UploadConfig struct {
+	MultipartThreshold int64         // objects at least this large are uploaded in parts
+	PartSize           int64         // size of each part
+	Retries            int           // extra attempts for a failed PUT or part
+	RetryBackoff       time.Duration // wait before the first retry, doubled on each one
+}
+
+func (c R2UploadConfig) withDefaults() R2UploadConfig {
+	if c.PartSize < minR2PartSize {
+		c.PartSize = minR2PartSize
+	}
+	if c.MultipartThreshold <= 0 {
+		c.MultipartThreshold = 8 * c.PartSize
+	}
+	if c.Retries < 0 {
+		c.Retries = 0
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	return c
+}
+
+// uploadSource is an upload body that can be read again for retries.
+type uploadSource struct {
+	data    io.ReaderAt
+	size    int64
+	sha256  string
+	cleanup func()
+}
+
+func (s *uploadSource) section(offset, size int64) *io.SectionReader {
+	return io.NewSectionReader(s.data, offset, size)
+}
+
+// newUploadSource makes data re-readable and hashes it. Files and in-memory readers are read in
+// place from their current position; other readers are spooled to a temp file first.
+func newUploadSource(data io.Reader) (*uploadSource, error) {
+	src := &uploadSource{cleanup: func() {}}
+
+	ra, isReaderAt := data.(io.ReaderAt)
+	seeker, isSeeker := data.(io.Seeker)
+	if isReaderAt && isSeeker {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload position: %w", err)
+		}
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload size: %w", err)
+		}
+		src.size = end - start
+		src.data = io.NewSectionReader(ra, start, src.size)
+	} else {
+		tmp, err := os.CreateTemp("", "r2-upload-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create upload spool file: %w", err)
+		}
+		src.cleanup = func() {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+		n, err := io.Copy(tmp, data)
+		if err != nil {
+			src.cleanup()
+			return nil, fmt.Errorf("failed to spool upload: %w", err)
+		}
+		src.data, src.size = tmp, n
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, src.section(0, src.size)); err != nil {
+		src.cleanup()
+		return nil, fmt.Errorf("failed to hash upload: %w", err)
+	}
+	src.sha256 = hex.EncodeToString(h.Sum(nil))
+	return src, nil
+}
+
+// contentMD5 returns the base64 MD5 R2 checks each request body against.
+func contentMD5(r io.ReadSeeker) (string, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// withRetry runs fn until it succeeds, the retries run out or ctx is done, backing off between attempts.
+func (c *CloudflareClient) withRetry(ctx context.Context, fn func() error) error {
+	backoff := c.upload.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.upload.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// putObject uploads the whole source in a single PUT.
+func (c *CloudflareClient) putObject(ctx context.Context, key, contentType string, src *uploadSource) error {
+	return c.withRetry(ctx, func() error {
+		body := src.section(0, src.size)
+		md5sum, err := contentMD5(body)
+		if err != nil {
+			return err
+		}
+		_, err = c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(c.bucket),
+			Key:           aws.String(key),
+			Body:          body,
+			ContentLength: aws.Int64(src.size),
+			ContentMD5:    aws.String(md5sum),
+			ContentType:   aws.String(contentType),
+			Metadata:      map[string]string{R2ChecksumMetadataKey: src.sha256},
+		})
+		return err
+	})
+}
+
+// putMultipart uploads the source in parts, retrying each part on its own. A failed upload is aborted
+// so R2 does not keep the parts.
+func (c *CloudflareClient) putMultipart(ctx context.Context, key, contentType string, src *uploadSource) error {
+	partSize := max(c.upload.PartSize, (src.size+maxR2Parts-1)/maxR2Parts)
+
+	var uploadID *string
+	err := c.withRetry(ctx, func() error {
+		out, err := c.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      aws.String(c.bucket),
+			Key:         aws.String(key),
+			ContentType: aws.String(contentType),
+			Metadata:    map[string]string{R2ChecksumMetadataKey: src.sha256},
+		})
+		if err != nil {
+			return err
+		}
+		uploadID = out.UploadId
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	parts, err := c.uploadParts(ctx, key, uploadID, partSize, src)
+	if err == nil {
+		err = c.withRetry(ctx, func() error {
+			_, err := c.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+				Bucket:          aws.String(c.bucket),
+				Key:             aws.String(key),
+				UploadId:        uploadID,
+				MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+			})
+			return err
+		})
+	}
+	if err != nil {
+		// Use a fresh context so a cancelled upload is still cleaned up
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		_, _ = c.s3Client.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(c.bucket),
+			Key:      aws.String(key),
+			UploadId: uploadID,
+		})
+		return err
+	}
+	return nil
+}
+
+func (c *CloudflareClient) uploadParts(ctx context.Context, key string, uploadID *string, partSize int64, src *uploadSource) ([]types.CompletedPart, error) {
+	var parts []types.CompletedPart
+	for offset, number := int64(0), int32(1); offset < src.size; offset, number = offset+partSize, number+1 {
+		size := min(partSize, src.size-offset)
+
+		var etag *string
+		err := c.withRetry(ctx, func() error {
+			body := src.section(offset, size)
+			md5sum, err := contentMD5(body)
+			if err != nil {
+				return err
+			}
+			out, err := c.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        aws.String(c.bucket),
+				Key:           aws.String(key),
+				UploadId:      uploadID,
+				PartNumber:    aws.Int32(number),
+				Body:          body,
+				ContentLength: aws.Int64(size),
+				ContentMD5:    aws.String(md5sum),
+			})
+			if err != nil {
+				return err
+			}
+			etag = out.ETag
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", number, err)
+		}
+		parts = append(parts, types.CompletedPart{ETag: etag, PartNumber: aws.Int32(number)})
+	}
+	return parts, nil
+}
+
+// verifyUpload checks the stored object has the uploaded size and checksum.
+func (c *CloudflareClient) verifyUpload(ctx context.Context, key string, src *uploadSource) error {
+	var head *s3.HeadObjectOutput
+	err := c.withRetry(ctx, func() error {
+		var err error
+		head, err = c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(c.bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read uploaded object: %w", err)
+	}
+
+	if size := aws.ToInt64(head.ContentLength); size != src.size {
+		return fmt.Errorf("uploaded object is %d bytes, expected %d", size, src.size)
+	}
+	if sum := head.Metadata[R2ChecksumMetadataKey]; sum != src.sha256 {
+		return fmt.Errorf("uploaded object checksum %q does not match %q", sum, src.sha256)
+	}
+	return nil
+}
+
+// VerifyR2Object downloads an object and checks its content against the SHA-256 stored in its
+// metadata. It returns false for corrupted objects; objects uploaded without a checksum are
+// reported as an error.
+func (c *CloudflareClient) VerifyR2Object(ctx context.Context, key string) (bool, error) {
+	out, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get from R2: %w", err)
+	}
+	defer out.Body.Close()
+
+	expected, ok := out.Metadata[R2ChecksumMetadataKey]
+	if !ok {
+		return false, fmt.Errorf("object %s has no checksum", key)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, out.Body); err != nil {
+		return false, fmt.Errorf("failed to read from R2: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)) == expected, nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Features of learning items (learning_items.feature_id)
+const (
+	FeatureVideo  = 1
+	FeatureDialog = 2
+)
+
+// Audiences of learning items
+const (
+	AudienceGeneral = "general"
+	AudienceAdult   = "adult"
+)
+
+// AdultAge is the age from which learners see and generate adult-audience content.
+const AdultAge = 18
+
+// MinorContentRules is appended to generation prompts when the learner is under AdultAge.
+const MinorContentRules = `
+
+**Audience:**
+The learner is a minor. The scenario must be suitable for all ages:
+- No alcohol, bars, gambling, casinos, betting, drugs, smoking, dating or romance, violence or weapons.
+- Prefer school, family, hobbies, sports, shopping, travel and everyday situations.
+- Set "audience" to "general".`
+
+// IsAudience reports whether audience is a known audience.
+func IsAudience(audience string) bool {
+	return audience == AudienceGeneral || audience == AudienceAdult
+}
+
+// AudienceVisibleSQL returns a condition hiding adult-audience learning items (aliased as alias)
+// from minors. userParam is the placeholder holding the user ID. Owners always see their own
+// items and learners without a birth date count as adults.
+func AudienceVisibleSQL(alias, userParam string) string {
+	return fmt.Sprintf(`(%[1]s.audience = '%[3]s' OR %[1]s.created_by = %[2]s OR NOT EXISTS (
+		SELECT 1 FROM users u WHERE u.id::text = %[2]s AND u.birth_date > CURRENT_DATE - INTERVAL '%[4]d years'))`,
+		alias, userParam, AudienceGeneral, AdultAge)
+}
+
+// IsMinorSQL selects whether the user with ID in userParam is under AdultAge (false without a birth date).
+func IsMinorSQL(userParam string) string {
+	return fmt.Sprintf(`SELECT COALESCE(bool_or(birth_date > CURRENT_DATE - INTERVAL '%[2]d years'), false) FROM users WHERE id::text = %[1]s`,
+		userParam, AdultAge)
+}
+
+// Reasons learners can give when reporting content
+const (
+	ReportInappropriate   = "inappropriate"
+	ReportOffensive       = "offensive"
+	ReportUnsafeForMinors = "unsafe_for_minors"
+	ReportInaccurate      = "inaccurate"
+	ReportLowQuality      = "low_quality"
+	ReportOther           = "other"
+)
+
+// ModerationGuidanceWindow is how long upheld reports keep adjusting generation prompts.
+const ModerationGuidanceWindow = 30 * 24 * time.Hour
+
+// reportGuidance is the generation rule added while reports for a reason keep being upheld.
+var reportGuidance = map[string]string{
+	ReportInappropriate:   "Keep every scenario appropriate for a classroom; avoid sexual, violent or disturbing situations.",
+	ReportOffensive:       "Avoid stereotypes, insults and humor at the expense of any group.",
+	ReportUnsafeForMinors: "Avoid alcohol, gambling, drugs and other situations unsuitable for young learners unless clearly requested.",
+	ReportInaccurate:      "Double-check facts, prices, customs and grammar; prefer well-known, verifiable details.",
+	ReportLowQuality:      "Make every line natural and purposeful; avoid repetitive, vague or filler turns.",
+	ReportOther:           "",
+}
+
+// IsReportReason reports whether reason is a known report reason.
+func IsReportReason(reason string) bool {
+	_, ok := reportGuidance[reason]
+	return ok
+}
+
+// ModerationGuidance builds the prompt section for the reasons of recently upheld reports.
+func ModerationGuidance(reasons []string) string {
+	var rules []string
+	for _, reason := range reasons {
+		if rule := reportGuidance[reason]; rule != "" {
+			rules = append(rules, "- "+rule)
+		}
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	return "\n\n**Moderation feedback:**\nLearners reported earlier content for these issues; avoid them:\n" + strings.Join(rules, "\n")
+}
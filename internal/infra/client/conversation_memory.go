@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+const memorySummaryPrompt = `You maintain the memory of an ongoing language-practice conversation.
+
+Merge the previous summary (if any) with the new messages into one short summary (at most 120 words).
+Keep facts, names, decisions, open questions and what the learner has already done or said.
+Write plain text only, in the conversation's language.`
+
+// ConversationMemoryOptions bounds how much history a session keeps.
+type ConversationMemoryOptions struct {
+	TTL         time.Duration // idle sessions expire after this
+	MaxMessages int           // summarize once the session holds more messages than this
+	KeepRecent  int           // messages kept verbatim when summarizing
+}
+
+// ConversationMemory stores per-session turn history in Redis.
+// Older turns are folded into a running summary so prompts stay small.
+// A nil *ConversationMemory stores nothing.
+type ConversationMemory struct {
+	redis *RedisClient
+	chat  ChatClient
+	opts  ConversationMemoryOptions
+	log   *slog.Logger
+}
+
+// NewConversationMemory creates a new conversation memory.
+func NewConversationMemory(redis *RedisClient, chat ChatClient, opts ConversationMemoryOptions, log *slog.Logger) *ConversationMemory {
+	if opts.KeepRecent < 1 {
+		opts.KeepRecent = 1
+	}
+	if opts.MaxMessages < opts.KeepRecent {
+		opts.MaxMessages = opts.KeepRecent
+	}
+	return &ConversationMemory{redis: redis, chat: chat, opts: opts, log: log}
+}
+
+func memoryTurnsKey(sessionID string) string {
+	return fmt.Sprintf("memory:%s:turns", sessionID)
+}
+
+func memoryMetaKey(sessionID string) string {
+	return fmt.Sprintf("memory:%s:meta", sessionID)
+}
+
+func memoryLockKey(sessionID string) string {
+	return fmt.Sprintf("memory:%s:lock", sessionID)
+}
+
+// memorySummaryLockTTL bounds how long a crashed summarization blocks the next one.
+const memorySummaryLockTTL = 2 * time.Minute
+
+// History returns the session history: the running summary (as a system message) followed by the recent turns.
+func (m *ConversationMemory) History(ctx context.Context, sessionID string) ([]ChatMessage, *errors.AppError) {
+	if m == nil {
+		return nil, nil
+	}
+
+	meta, err := m.redis.HGetAll(ctx, memoryMetaKey(sessionID))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to read conversation summary", err)
+	}
+	turns, appErr := m.turns(ctx, sessionID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	history := make([]ChatMessage, 0, len(turns)+1)
+	if summary := meta["summary"]; summary != "" {
+		history = append(history, ChatMessage{Role: "system", Content: "Summary of the earlier conversation: " + summary})
+	}
+	return append(history, turns...), nil
+}
+
+// Append adds messages to the session and summarizes the oldest ones once it grows past MaxMessages.
+func (m *ConversationMemory) Append(ctx context.Context, sessionID string, messages ...ChatMessage) *errors.AppError {
+	if m == nil || len(messages) == 0 {
+		return nil
+	}
+
+	key := memoryTurnsKey(sessionID)
+	for _, msg := range messages {
+		if err := m.redis.RPush(ctx, key, msg); err != nil {
+			return errors.InternalWrap("failed to append conversation memory", err)
+		}
+	}
+	_ = m.redis.SetExpiry(ctx, key, m.opts.TTL)
+	_ = m.redis.SetExpiry(ctx, memoryMetaKey(sessionID), m.opts.TTL)
+
+	length, err := m.redis.LLen(ctx, key)
+	if err != nil {
+		return errors.InternalWrap("failed to read conversation memory", err)
+	}
+	if length <= int64(m.opts.MaxMessages) {
+		return nil
+	}
+
+	// Summarization failures keep the full history; the next append retries
+	if err := m.summarize(ctx, sessionID); err != nil {
+		m.log.Warn("Failed to summarize conversation memory", "session_id", sessionID, "error", err.Error())
+	}
+	return nil
+}
+
+// Clear drops the session history.
+func (m *ConversationMemory) Clear(ctx context.Context, sessionID string) *errors.AppError {
+	if m == nil {
+		return nil
+	}
+	if err := m.redis.Del(ctx, memoryTurnsKey(sessionID), memoryMetaKey(sessionID)); err != nil {
+		return errors.InternalWrap("failed to clear conversation memory", err)
+	}
+	return nil
+}
+
+func (m *ConversationMemory) turns(ctx context.Context, sessionID string) ([]ChatMessage, *errors.AppError) {
+	raw, err := m.redis.LRange(ctx, memoryTurnsKey(sessionID), 0, -1)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to read conversation memory", err)
+	}
+
+	turns := make([]ChatMessage, 0, len(raw))
+	for _, item := range raw {
+		var msg ChatMessage
+		if err := json.Unmarshal([]byte(item), &msg); err == nil {
+			turns = append(turns, msg)
+		}
+	}
+	return turns, nil
+}
+
+// summarize folds all but the KeepRecent newest turns into the running summary and drops them from
+// the turn list. It runs under a per-session lock, so concurrent appends never summarize (and trim)
+// the same turns twice; while another summarization runs it does nothing.
+func (m *ConversationMemory) summarize(ctx context.Context, sessionID string) *errors.AppError {
+	lockKey := memoryLockKey(sessionID)
+	token, locked, err := m.redis.TryLock(ctx, lockKey, memorySummaryLockTTL)
+	if err != nil {
+		return errors.InternalWrap("failed to lock conversation memory", err)
+	}
+	if !locked {
+		return nil
+	}
+	defer m.redis.Unlock(context.WithoutCancel(ctx), lockKey, token)
+
+	// Read under the lock: only appends can happen meanwhile, and they go to the end of the list
+	turns, appErr := m.turns(ctx, sessionID)
+	if appErr != nil {
+		return appErr
+	}
+	if len(turns) <= m.opts.MaxMessages {
+		return nil
+	}
+	old := turns[:len(turns)-m.opts.KeepRecent]
+
+	meta, err := m.redis.HGetAll(ctx, memoryMetaKey(sessionID))
+	if err != nil {
+		return errors.InternalWrap("failed to read conversation summary", err)
+	}
+
+	var b strings.Builder
+	if summary := meta["summary"]; summary != "" {
+		fmt.Fprintf(&b, "Previous summary: %s\n\n", summary)
+	}
+	b.WriteString("New messages:\n")
+	for _, msg := range old {
+		fmt.Fprintf(&b, "%s: %s\n", msg.Role, msg.Content)
+	}
+
+	summary, appErr := m.chat.ChatCompletion(WithChatFeature(ctx, ChatFeatureMemorySummary), memorySummaryPrompt, b.String())
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := m.redis.HSet(ctx, memoryMetaKey(sessionID), "summary", strings.TrimSpace(summary)); err != nil {
+		return errors.InternalWrap("failed to save conversation summary", err)
+	}
+	// Trim by count so turns appended meanwhile are kept
+	if err := m.redis.LTrim(ctx, memoryTurnsKey(sessionID), int64(len(old)), -1); err != nil {
+		return errors.InternalWrap("failed to trim conversation memory", err)
+	}
+	_ = m.redis.SetExpiry(ctx, memoryMetaKey(sessionID), m.opts.TTL)
+	return nil
+}
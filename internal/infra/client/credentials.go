@@ -0,0 +1,323 @@
+package client
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Providers an organization can bring its own credentials for
+const (
+	CredentialAzureOpenAI  = "azure_openai"  // chat completions: endpoint + API key
+	CredentialAzureWhisper = "azure_whisper" // transcription: endpoint + API key
+	CredentialAzureSpeech  = "azure_speech"  // TTS and pronunciation: region + API key
+	CredentialGCP          = "gcp"           // Imagen and Vision: service account JSON (+ optional location as region)
+)
+
+// CredentialProviders lists the providers that accept organization credentials.
+var CredentialProviders = map[string]bool{
+	CredentialAzureOpenAI:  true,
+	CredentialAzureWhisper: true,
+	CredentialAzureSpeech:  true,
+	CredentialGCP:          true,
+}
+
+// ProviderCredentials are the decrypted credentials of one provider.
+type ProviderCredentials struct {
+	Endpoint string
+	Region   string
+	Secret   string // API key or service account JSON
+}
+
+// CredentialInfo describes stored credentials without the secret.
+type CredentialInfo struct {
+	Provider  string    `json:"provider"`
+	Endpoint  string    `json:"endpoint,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	KeyHint   string    `json:"key_hint"` // last characters of the secret
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type organizationKey struct{}
+
+// WithOrganization returns a context whose provider calls use the organization's credentials.
+func WithOrganization(ctx context.Context, organizationID string) context.Context {
+	if organizationID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// OrganizationFromContext returns the organization of the request or job ("" for none).
+func OrganizationFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(organizationKey{}).(string)
+	return id
+}
+
+type cachedCredentials struct {
+	creds   *ProviderCredentials // nil = the organization has none for the provider
+	expires time.Time
+}
+
+type cachedOrganization struct {
+	id      string
+	expires time.Time
+}
+
+// CredentialStore keeps per-organization provider credentials encrypted with AES-256-GCM and
+// resolves them for a call. Lookups are cached briefly, including misses, so calls of users
+// without an organization or of providers an organization did not configure stay off Postgres.
+type CredentialStore struct {
+	db   *PostgresClient
+	aead cipher.AEAD
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	creds map[string]cachedCredentials  // organization/provider
+	orgs  map[string]cachedOrganization // user id
+}
+
+// NewCredentialStore creates a credential store from a Base64-encoded 32-byte key.
+// An empty key disables organization credentials and returns a nil store.
+func NewCredentialStore(db *PostgresClient, keyBase64 string, ttl time.Duration) (*CredentialStore, error) {
+	if keyBase64 == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(keyBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Base64 credentials key: %v", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("credentials key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CredentialStore{
+		db:    db,
+		aead:  aead,
+		ttl:   ttl,
+		creds: make(map[string]cachedCredentials),
+		orgs:  make(map[string]cachedOrganization),
+	}, nil
+}
+
+// Enabled reports whether organization credentials are in use.
+func (s *CredentialStore) Enabled() bool {
+	return s != nil
+}
+
+// Resolve returns the credentials of the context's organization for a provider, or nil when the
+// call should use the platform's own keys (no store, no organization, or none configured).
+func (s *CredentialStore) Resolve(ctx context.Context, provider string) (*ProviderCredentials, error) {
+	if s == nil {
+		return nil, nil
+	}
+	organizationID := OrganizationFromContext(ctx)
+	if organizationID == "" {
+		return nil, nil
+	}
+
+	cacheKey := organizationID + "/" + provider
+	s.mu.Lock()
+	cached, ok := s.creds[cacheKey]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.creds, nil
+	}
+
+	var creds *ProviderCredentials
+	var endpoint, region string
+	var sealed []byte
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT endpoint, region, secret_ciphertext
+		FROM organization_credentials
+		WHERE organization_id = $1 AND provider = $2
+	`, organizationID, provider).Scan(&endpoint, &region, &sealed)
+	switch {
+	case err == pgx.ErrNoRows:
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s credentials: %w", provider, err)
+	default:
+		secret, err := s.open(organizationID, provider, sealed)
+		if err != nil {
+			return nil, err
+		}
+		creds = &ProviderCredentials{Endpoint: endpoint, Region: region, Secret: secret}
+	}
+
+	s.mu.Lock()
+	s.creds[cacheKey] = cachedCredentials{creds: creds, expires: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	return creds, nil
+}
+
+// OrganizationOf returns the organization a user belongs to ("" for none).
+func (s *CredentialStore) OrganizationOf(ctx context.Context, userID string) (string, error) {
+	if s == nil || userID == "" {
+		return "", nil
+	}
+
+	s.mu.Lock()
+	cached, ok := s.orgs[userID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.id, nil
+	}
+
+	var organizationID string
+	err := s.db.Pool.QueryRow(ctx, `SELECT COALESCE(organization_id::text, '') FROM users WHERE id = $1`, userID).Scan(&organizationID)
+	if err != nil && err != pgx.ErrNoRows {
+		return "", fmt.Errorf("failed to read user organization: %w", err)
+	}
+
+	s.mu.Lock()
+	s.orgs[userID] = cachedOrganization{id: organizationID, expires: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	return organizationID, nil
+}
+
+// Save encrypts and upserts the credentials of an organization for a provider.
+func (s *CredentialStore) Save(ctx context.Context, organizationID, provider string, creds ProviderCredentials) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// The row identity is sealed in, so a ciphertext copied to another organization does not open
+	sealed := s.aead.Seal(nonce, nonce, []byte(creds.Secret), []byte(organizationID+"/"+provider))
+
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO organization_credentials (organization_id, provider, endpoint, region, secret_ciphertext, key_hint, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (organization_id, provider) DO UPDATE SET
+			endpoint = EXCLUDED.endpoint,
+			region = EXCLUDED.region,
+			secret_ciphertext = EXCLUDED.secret_ciphertext,
+			key_hint = EXCLUDED.key_hint,
+			updated_at = NOW()
+	`, organizationID, provider, creds.Endpoint, creds.Region, sealed, keyHint(provider, creds.Secret))
+	if err != nil {
+		return fmt.Errorf("failed to save %s credentials: %w", provider, err)
+	}
+
+	s.forgetCredentials(organizationID, provider)
+	return nil
+}
+
+// Delete removes the credentials of an organization for a provider; calls fall back to platform keys.
+func (s *CredentialStore) Delete(ctx context.Context, organizationID, provider string) (bool, error) {
+	tag, err := s.db.Pool.Exec(ctx, `DELETE FROM organization_credentials WHERE organization_id = $1 AND provider = $2`, organizationID, provider)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete %s credentials: %w", provider, err)
+	}
+
+	s.forgetCredentials(organizationID, provider)
+	return tag.RowsAffected() > 0, nil
+}
+
+// List returns the providers an organization has credentials for, without secrets.
+func (s *CredentialStore) List(ctx context.Context, organizationID string) ([]CredentialInfo, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT provider, endpoint, region, key_hint, updated_at
+		FROM organization_credentials
+		WHERE organization_id = $1
+		ORDER BY provider
+	`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+
+	list := make([]CredentialInfo, 0)
+	for rows.Next() {
+		var info CredentialInfo
+		if err := rows.Scan(&info.Provider, &info.Endpoint, &info.Region, &info.KeyHint, &info.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan credentials: %w", err)
+		}
+		list = append(list, info)
+	}
+	return list, rows.Err()
+}
+
+// ForgetUser drops the cached organization of a user after their membership changed.
+func (s *CredentialStore) ForgetUser(userID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.orgs, userID)
+	s.mu.Unlock()
+}
+
+// forgetCredentials drops a cached lookup. Other instances pick the change up when their cache expires.
+func (s *CredentialStore) forgetCredentials(organizationID, provider string) {
+	s.mu.Lock()
+	delete(s.creds, organizationID+"/"+provider)
+	s.mu.Unlock()
+}
+
+func (s *CredentialStore) open(organizationID, provider string, sealed []byte) (string, error) {
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return "", fmt.Errorf("%s credentials of organization %s are corrupt", provider, organizationID)
+	}
+	secret, err := s.aead.Open(nil, sealed[:size], sealed[size:], []byte(organizationID+"/"+provider))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s credentials of organization %s: %w", provider, organizationID, err)
+	}
+	return string(secret), nil
+}
+
+// keyHint keeps the last 4 characters of a key (the client email of a service account) so admins
+// can tell credentials apart.
+func keyHint(provider, secret string) string {
+	if provider == CredentialGCP {
+		var sa struct {
+			ClientEmail string `json:"client_email"`
+		}
+		if json.Unmarshal([]byte(secret), &sa) == nil && sa.ClientEmail != "" {
+			return sa.ClientEmail
+		}
+	}
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// providerTarget is where one call goes and with which key.
+type providerTarget struct {
+	endpoint string
+	region   string
+	secret   string
+	own      bool // organization credentials: billed to the organization, so kept out of the platform budget
+}
+
+// resolveTarget picks the organization's credentials when it has some and the platform's otherwise.
+// A failed lookup fails the call rather than silently billing it to the platform.
+func (s *CredentialStore) resolveTarget(ctx context.Context, provider string, platform providerTarget) (*providerTarget, *errors.AppError) {
+	creds, err := s.Resolve(ctx, provider)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to resolve organization credentials", err)
+	}
+	if creds == nil {
+		return &platform, nil
+	}
+	return &providerTarget{endpoint: creds.Endpoint, region: creds.Region, secret: creds.Secret, own: true}, nil
+}
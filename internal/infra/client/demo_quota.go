@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Demo quota kinds
+const (
+	DemoQuotaWrites      = "writes"      // every write request of the session
+	DemoQuotaGenerations = "generations" // content generation requests
+)
+
+// DemoQuotaOptions holds how much one demo session may do.
+type DemoQuotaOptions struct {
+	Limits map[string]int // per quota kind; a kind without a limit is refused
+	TTL    time.Duration  // how long the counters live, at least the session lifetime
+}
+
+// DemoQuota counts what each demo session used in Redis, so every instance enforces the same quota.
+type DemoQuota struct {
+	redis *RedisClient
+	opts  DemoQuotaOptions
+}
+
+// NewDemoQuota creates a new demo quota.
+func NewDemoQuota(redis *RedisClient, opts DemoQuotaOptions) *DemoQuota {
+	return &DemoQuota{redis: redis, opts: opts}
+}
+
+func demoQuotaKey(userID, kind string) string {
+	return fmt.Sprintf("demo:quota:%s:%s", userID, kind)
+}
+
+// Take counts one use of kind for the demo user and reports whether it was within the limit.
+func (q *DemoQuota) Take(ctx context.Context, userID, kind string) (bool, error) {
+	limit := q.opts.Limits[kind]
+	if limit <= 0 {
+		return false, nil
+	}
+
+	key := demoQuotaKey(userID, kind)
+	used, err := q.redis.IncrWithExpiry(ctx, key, q.opts.TTL)
+	if err != nil {
+		return false, err
+	}
+	return used <= int64(limit), nil
+}
+
+// Limits returns the quota of each kind.
+func (q *DemoQuota) Limits() map[string]int {
+	return q.opts.Limits
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// ProviderFake names the fake providers in chains and capabilities.
+const ProviderFake = "fake"
+
+// fakeTranscript is what FakeProviders hears in every recording.
+var fakeTranscript = []WhisperSegment{
+	{ID: 0, Start: 0, End: 4, Text: "Good morning, I would like a coffee, please."},
+	{ID: 1, Start: 4, End: 8, Text: "Of course. Would you like milk or sugar with it?"},
+	{ID: 2, Start: 8, End: 12, Text: "Just a little milk, thank you very much."},
+}
+
+// FakeProviders stands in for every AI provider (FAKE_PROVIDERS=true) so load tests and API tests
+// run the real pipelines without paid calls. Each call waits the configured latency, then answers
+// with canned output: chat responses are looked up by the start of the system prompt (see Respond),
+// audio is silence, images are a plain square and transcripts are always the same short exchange.
+type FakeProviders struct {
+	latency   time.Duration
+	responses map[string]string
+}
+
+// NewFakeProviders creates fake providers that take latency per call. They answer this package's
+// own prompts; callers add the responses of theirs with Respond.
+func NewFakeProviders(latency time.Duration) *FakeProviders {
+	return &FakeProviders{
+		latency: latency,
+		responses: map[string]string{
+			memorySummaryPrompt: "The learner ordered a coffee and talked about what to eat.",
+		},
+	}
+}
+
+// Respond answers chat calls whose system prompt starts with systemPrompt with response.
+// Call it during setup, before the providers are used.
+func (f *FakeProviders) Respond(systemPrompt, response string) {
+	f.responses[systemPrompt] = response
+}
+
+// wait simulates the provider's latency.
+func (f *FakeProviders) wait(ctx context.Context) *errors.AppError {
+	if f.latency <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return errors.Timeout("fake provider call cancelled")
+	case <-time.After(f.latency):
+		return nil
+	}
+}
+
+// ChatCompletion returns the canned response of the system prompt. A prompt that starts with
+// several registered prompts gets the response of the longest, so lookups don't depend on map order.
+func (f *FakeProviders) ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError) {
+	if err := f.wait(ctx); err != nil {
+		return "", err
+	}
+	if response, ok := f.responses[systemPrompt]; ok {
+		return response, nil
+	}
+
+	match, found := "", false
+	for prefix := range f.responses {
+		if strings.HasPrefix(systemPrompt, prefix) && len(prefix) >= len(match) {
+			match, found = prefix, true
+		}
+	}
+	if !found {
+		return "", errors.Unsupported("fake chat provider has no response for this prompt")
+	}
+	return f.responses[match], nil
+}
+
+// ChatCompletionMultiTurn returns the canned response of the first system message.
+func (f *FakeProviders) ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError) {
+	for _, message := range messages {
+		if message.Role == "system" {
+			return f.ChatCompletion(ctx, message.Content, "")
+		}
+	}
+	return "", errors.Unsupported("fake chat provider needs a system message")
+}
+
+// Stream passes the canned response of the first system message to onDelta in one piece.
+func (f *FakeProviders) Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError) {
+	text, err := f.ChatCompletionMultiTurn(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	onDelta(text)
+	return text, nil
+}
+
+// Synthesize returns about a second of silent MP3.
+func (f *FakeProviders) Synthesize(ctx context.Context, text, voice string) ([]byte, *errors.AppError) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return fakeSilentMP3(38), nil
+}
+
+// EvaluatePronunciation scores every word of the reference text 90.
+func (f *FakeProviders) EvaluatePronunciation(ctx context.Context, audioBytes []byte, referenceText string, language string) (*AzureEvaluationSpeech, *errors.AppError) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	best := AzureNBest{
+		AccuracyScore:     90,
+		CompletenessScore: 90,
+		Confidence:        0.9,
+		DisplayText:       referenceText,
+		FluencyScore:      90,
+		PronScore:         90,
+	}
+	for i, word := range strings.Fields(referenceText) {
+		best.Words = append(best.Words, AzureWord{
+			AccuracyScore: 90,
+			Confidence:    0.9,
+			Duration:      4000000,
+			ErrorType:     "None",
+			Offset:        i * 4000000,
+			Word:          word,
+		})
+	}
+	return &AzureEvaluationSpeech{DisplayText: referenceText, Duration: len(best.Words) * 4000000, NBest: []AzureNBest{best}}, nil
+}
+
+// TranscribeFile returns the fake transcript with full confidence, whatever the audio.
+func (f *FakeProviders) TranscribeFile(ctx context.Context, wavPath, language string) (*WhisperResponse, *errors.AppError) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	transcript := &WhisperResponse{
+		Task:     "transcribe",
+		Language: "english",
+		Duration: fakeTranscript[len(fakeTranscript)-1].End,
+		Segments: append([]WhisperSegment{}, fakeTranscript...),
+	}
+	var texts []string
+	for _, segment := range fakeTranscript {
+		texts = append(texts, segment.Text)
+
+		words := strings.Fields(segment.Text)
+		step := (segment.End - segment.Start) / float64(len(words))
+		for i, word := range words {
+			start := segment.Start + float64(i)*step
+			transcript.Words = append(transcript.Words, WhisperWord{Word: word, Start: start, End: start + step})
+		}
+	}
+	transcript.Text = strings.Join(texts, " ")
+	return transcript, nil
+}
+
+// GenerateImage returns plain grey PNG squares, one per requested sample.
+func (f *FakeProviders) GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([][]byte, *errors.AppError) {
+	opts, appErr := opts.withDefaults()
+	if appErr != nil {
+		return nil, appErr
+	}
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 0xc0, 0xc0, 0xc0, 0xff
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.InternalWrap("failed to encode fake image", err)
+	}
+
+	images := make([][]byte, opts.SampleCount)
+	for i := range images {
+		images[i] = buf.Bytes()
+	}
+	return images, nil
+}
+
+// DetectText finds no text in fake images.
+func (f *FakeProviders) DetectText(ctx context.Context, image []byte) (string, *errors.AppError) {
+	if err := f.wait(ctx); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// fakeSilentMP3 returns n silent MPEG-1 Layer III frames (32 kbps, 44.1 kHz, mono; ~26 ms each).
+func fakeSilentMP3(n int) []byte {
+	const frameSize = 104 // 144 * 32000 / 44100
+	out := make([]byte, 0, n*frameSize)
+	for i := 0; i < n; i++ {
+		frame := make([]byte, frameSize)
+		copy(frame, []byte{0xff, 0xfb, 0x10, 0xc4})
+		out = append(out, frame...)
+	}
+	return out
+}
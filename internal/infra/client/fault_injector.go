@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Dependencies the fault injector can disrupt
+const (
+	FaultTargetAzureChat   = "azure_chat"
+	FaultTargetWhisper     = ProviderWhisper
+	FaultTargetAzureSpeech = ProviderAzureSpeech
+	FaultTargetGemini      = ProviderGemini
+	FaultTargetR2          = ProviderR2
+	FaultTargetRedis       = "redis"
+)
+
+// FaultTargets lists every target accepted in the fault injection config.
+var FaultTargets = map[string]bool{
+	FaultTargetAzureChat:   true,
+	FaultTargetWhisper:     true,
+	FaultTargetAzureSpeech: true,
+	FaultTargetGemini:      true,
+	FaultTargetR2:          true,
+	FaultTargetRedis:       true,
+}
+
+// errFaultInjected is returned by Redis commands that the injector fails.
+var errFaultInjected = fmt.Errorf("fault injected")
+
+// FaultInjector makes calls to external dependencies fail or slow down at configured rates, to
+// check retries, provider fallbacks and batch failure handling before a real outage does.
+// HTTP calls fail with a 503 response, so they take the same path as a provider outage.
+// A nil FaultInjector injects nothing.
+type FaultInjector struct {
+	errorRates map[string]float64       // chance in [0, 1] that a call fails
+	latencies  map[string]time.Duration // added before every call
+	log        *slog.Logger
+}
+
+// NewFaultInjector creates a fault injector for the given targets. It returns nil when no
+// target has an error rate or latency, so production configs pay nothing.
+func NewFaultInjector(errorRates map[string]float64, latencies map[string]time.Duration, log *slog.Logger) (*FaultInjector, error) {
+	if len(errorRates) == 0 && len(latencies) == 0 {
+		return nil, nil
+	}
+
+	for target, rate := range errorRates {
+		if !FaultTargets[target] {
+			return nil, fmt.Errorf("unknown fault target %q", target)
+		}
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("fault error rate of %q must be between 0 and 1", target)
+		}
+	}
+	for target, latency := range latencies {
+		if !FaultTargets[target] {
+			return nil, fmt.Errorf("unknown fault target %q", target)
+		}
+		if latency < 0 {
+			return nil, fmt.Errorf("fault latency of %q must not be negative", target)
+		}
+	}
+
+	return &FaultInjector{errorRates: errorRates, latencies: latencies, log: log}, nil
+}
+
+// Targets describes the active faults, e.g. "r2(error=0.1) whisper(latency=2s)".
+func (f *FaultInjector) Targets() string {
+	if f == nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(FaultTargets))
+	for target := range FaultTargets {
+		if f.errorRates[target] > 0 || f.latencies[target] > 0 {
+			names = append(names, target)
+		}
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, target := range names {
+		var faults []string
+		if rate := f.errorRates[target]; rate > 0 {
+			faults = append(faults, fmt.Sprintf("error=%g", rate))
+		}
+		if latency := f.latencies[target]; latency > 0 {
+			faults = append(faults, "latency="+latency.String())
+		}
+		parts = append(parts, target+"("+strings.Join(faults, ",")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// inject waits the target's added latency and reports whether this call should fail.
+func (f *FaultInjector) inject(ctx context.Context, target string) (bool, error) {
+	if latency := f.latencies[target]; latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if rate := f.errorRates[target]; rate > 0 && rand.Float64() < rate {
+		f.log.Debug("Fault injected", "target", target)
+		return true, nil
+	}
+	return false, nil
+}
+
+// Transport wraps base (nil for the default transport) with the faults of target. A nil
+// FaultInjector returns base unchanged.
+func (f *FaultInjector) Transport(target string, base http.RoundTripper) http.RoundTripper {
+	if f == nil || (f.errorRates[target] == 0 && f.latencies[target] == 0) {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &faultTransport{faults: f, target: target, base: base}
+}
+
+type faultTransport struct {
+	faults *FaultInjector
+	target string
+	base   http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fail, err := t.faults.inject(req.Context(), t.target)
+	if err != nil {
+		return nil, err
+	}
+	if !fail {
+		return t.base.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	body := fmt.Sprintf("fault injected (%s)", t.target)
+	return &http.Response{
+		Status:        "503 Service Unavailable",
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/plain"}, "Retry-After": []string{"1"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// RedisHook returns a go-redis hook with the faults of the redis target (nil when there are none).
+func (f *FaultInjector) RedisHook() redis.Hook {
+	if f == nil || (f.errorRates[FaultTargetRedis] == 0 && f.latencies[FaultTargetRedis] == 0) {
+		return nil
+	}
+	return faultRedisHook{faults: f}
+}
+
+type faultRedisHook struct {
+	faults *FaultInjector
+}
+
+func (h faultRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h faultRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		fail, err := h.faults.inject(ctx, FaultTargetRedis)
+		if err != nil {
+			return err
+		}
+		if fail {
+			cmd.SetErr(errFaultInjected)
+			return errFaultInjected
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h faultRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		fail, err := h.faults.inject(ctx, FaultTargetRedis)
+		if err != nil {
+			return err
+		}
+		if fail {
+			for _, cmd := range cmds {
+				cmd.SetErr(errFaultInjected)
+			}
+			return errFaultInjected
+		}
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,40 @@
+package client
+
+import "fmt"
+
+// Content types users can favorite
+const (
+	FavoriteVideo  = "video"
+	FavoriteDialog = "dialog"
+	FavoriteDeck   = "deck"
+)
+
+// IsFavoriteType reports whether contentType can be favorited.
+func IsFavoriteType(contentType string) bool {
+	return contentType == FavoriteVideo || contentType == FavoriteDialog || contentType == FavoriteDeck
+}
+
+// FavoritedSQL selects whether the user with ID in userParam favorited the content of contentType
+// aliased as alias.
+func FavoritedSQL(alias, contentType, userParam string) string {
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM favorites f
+		WHERE f.user_id::text = %[2]s AND f.content_type = '%[3]s' AND f.content_id = %[1]s.id)`,
+		alias, userParam, contentType)
+}
+
+// ToggleFavoriteSQL removes the favorite of user $1 for content $2 of type $3, or adds it when
+// there was none, returning the favorite ID and whether the content is now favorited.
+const ToggleFavoriteSQL = `
+	WITH removed AS (
+		DELETE FROM favorites WHERE user_id = $1 AND content_type = $3 AND content_id = $2
+		RETURNING id
+	), added AS (
+		INSERT INTO favorites (user_id, content_type, content_id)
+		SELECT $1, $3, $2 WHERE NOT EXISTS (SELECT 1 FROM removed)
+		ON CONFLICT (user_id, content_type, content_id) DO UPDATE SET content_type = EXCLUDED.content_type
+		RETURNING id
+	)
+	SELECT id, true FROM added
+	UNION ALL
+	SELECT id, false FROM removed
+`
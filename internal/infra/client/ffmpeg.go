@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// FFmpegOptions holds the tunables for audio processing.
+type FFmpegOptions struct {
+	LoudnessTarget float64 // integrated loudness in LUFS, e.g. -16
+	TruePeak       float64 // maximum true peak in dBTP, e.g. -1.5
+	LoudnessRange  float64 // loudness range target in LU, e.g. 11
+
+	SilenceTrimEnabled bool          // trim leading/trailing silence from user recordings
+	SilenceThreshold   float64       // level in dB below which audio counts as silence, e.g. -50
+	SilenceMinDuration time.Duration // minimum silence length to trim, e.g. 300ms
+
+	MaxRecordingDuration time.Duration // longest user recording accepted, 0 disables the check
+}
+
+// FFmpegClient wraps the ffmpeg binary for audio processing.
+type FFmpegClient struct {
+	opts FFmpegOptions
+}
+
+// NewFFmpegClient creates a new ffmpeg client.
+func NewFFmpegClient(opts FFmpegOptions) *FFmpegClient {
+	return &FFmpegClient{opts: opts}
+}
+
+// LoudnormFilter returns the loudnorm audio filter built from the configured targets.
+func (c *FFmpegClient) LoudnormFilter() string {
+	return fmt.Sprintf("loudnorm=I=%g:TP=%g:LRA=%g", c.opts.LoudnessTarget, c.opts.TruePeak, c.opts.LoudnessRange)
+}
+
+// NormalizeLoudness applies the loudnorm filter to srcPath and writes the result to dstPath.
+// The output codec is picked from the dstPath extension.
+func (c *FFmpegClient) NormalizeLoudness(ctx context.Context, srcPath, dstPath string) *errors.AppError {
+	if err := runAudioFilter(ctx, srcPath, dstPath, c.LoudnormFilter()); err != nil {
+		return errors.InternalWrap("ffmpeg loudness normalization", err)
+	}
+	return nil
+}
+
+// NormalizeLoudnessInPlace normalizes the file at path and replaces it with the result.
+func (c *FFmpegClient) NormalizeLoudnessInPlace(ctx context.Context, path string) *errors.AppError {
+	return replaceInPlace(path, func(tmpPath string) *errors.AppError {
+		return c.NormalizeLoudness(ctx, path, tmpPath)
+	})
+}
+
+// SilenceRemoveFilter returns a filter that trims leading and trailing silence.
+// Trailing silence is trimmed by reversing the stream, trimming the (new) start and reversing back.
+func (c *FFmpegClient) SilenceRemoveFilter() string {
+	trim := fmt.Sprintf("silenceremove=start_periods=1:start_duration=%g:start_threshold=%gdB",
+		c.opts.SilenceMinDuration.Seconds(), c.opts.SilenceThreshold)
+	return trim + ",areverse," + trim + ",areverse"
+}
+
+// TrimSilence removes leading and trailing silence from srcPath and writes the result to dstPath.
+func (c *FFmpegClient) TrimSilence(ctx context.Context, srcPath, dstPath string) *errors.AppError {
+	if err := runAudioFilter(ctx, srcPath, dstPath, c.SilenceRemoveFilter()); err != nil {
+		return errors.InternalWrap("ffmpeg silence trim", err)
+	}
+	return nil
+}
+
+// TrimSilenceInPlace trims the file at path and replaces it with the result.
+// It is a no-op when silence trimming is disabled.
+func (c *FFmpegClient) TrimSilenceInPlace(ctx context.Context, path string) *errors.AppError {
+	if !c.opts.SilenceTrimEnabled {
+		return nil
+	}
+	return replaceInPlace(path, func(tmpPath string) *errors.AppError {
+		return c.TrimSilence(ctx, path, tmpPath)
+	})
+}
+
+// ProbeDuration returns the duration of the media file at path using ffprobe.
+func (c *FFmpegClient) ProbeDuration(ctx context.Context, path string) (time.Duration, *errors.AppError) {
+	output, err := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	).Output()
+	if err != nil {
+		return 0, errors.ValidationWrap("unable to read audio duration", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, errors.ValidationWrap("unable to read audio duration", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// CheckMaxDuration rejects recordings longer than the configured MaxRecordingDuration.
+func (c *FFmpegClient) CheckMaxDuration(ctx context.Context, path string) *errors.AppError {
+	if c.opts.MaxRecordingDuration <= 0 {
+		return nil
+	}
+
+	duration, err := c.ProbeDuration(ctx, path)
+	if err != nil {
+		return err
+	}
+	if duration > c.opts.MaxRecordingDuration {
+		return errors.AudioTooLong(fmt.Sprintf("recording must not exceed %g seconds", c.opts.MaxRecordingDuration.Seconds())).
+			WithDetails(map[string]interface{}{
+				"duration_seconds":     duration.Seconds(),
+				"max_duration_seconds": c.opts.MaxRecordingDuration.Seconds(),
+			})
+	}
+	return nil
+}
+
+// NormalizeLoudnessBytes normalizes in-memory audio (e.g. TTS output) and returns the new bytes.
+// ext is the file extension of the audio format, e.g. ".mp3".
+func (c *FFmpegClient) NormalizeLoudnessBytes(ctx context.Context, data []byte, ext string) ([]byte, *errors.AppError) {
+	src, err := os.CreateTemp("", "ffmpeg_src_*"+ext)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create temp audio file", err)
+	}
+	defer os.Remove(src.Name())
+
+	if _, err := src.Write(data); err != nil {
+		src.Close()
+		return nil, errors.InternalWrap("failed to write temp audio file", err)
+	}
+	src.Close()
+
+	dstPath := strings.TrimSuffix(src.Name(), ext) + ".norm" + ext
+	defer os.Remove(dstPath)
+
+	if err := c.NormalizeLoudness(ctx, src.Name(), dstPath); err != nil {
+		return nil, err
+	}
+
+	normalized, err := os.ReadFile(dstPath)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to read normalized audio", err)
+	}
+	return normalized, nil
+}
+
+// TranscodeBytes re-encodes in-memory media into the format of dstExt (e.g. ".opus", ".webp").
+// srcExt is the file extension of the input.
+func (c *FFmpegClient) TranscodeBytes(ctx context.Context, data []byte, srcExt, dstExt string) ([]byte, *errors.AppError) {
+	src, err := os.CreateTemp("", "ffmpeg_src_*"+srcExt)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create temp media file", err)
+	}
+	defer os.Remove(src.Name())
+
+	if _, err := src.Write(data); err != nil {
+		src.Close()
+		return nil, errors.InternalWrap("failed to write temp media file", err)
+	}
+	src.Close()
+
+	dstPath := strings.TrimSuffix(src.Name(), srcExt) + ".out" + dstExt
+	defer os.Remove(dstPath)
+
+	args := []string{"-y", "-i", src.Name()}
+	args = append(args, codecArgs(dstPath)...)
+	args = append(args, dstPath)
+	if output, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput(); err != nil {
+		return nil, errors.InternalWrap("ffmpeg transcode", fmt.Errorf("%w: %s", err, string(output)))
+	}
+
+	transcoded, err := os.ReadFile(dstPath)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to read transcoded media", err)
+	}
+	return transcoded, nil
+}
+
+// runAudioFilter runs ffmpeg with a single audio filter chain.
+func runAudioFilter(ctx context.Context, srcPath, dstPath, filter string) error {
+	args := []string{"-y", "-i", srcPath, "-af", filter}
+	args = append(args, codecArgs(dstPath)...)
+	args = append(args, dstPath)
+
+	if output, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+	return nil
+}
+
+// replaceInPlace lets process write to a sibling temp file and then swaps it over path.
+func replaceInPlace(path string, process func(tmpPath string) *errors.AppError) *errors.AppError {
+	ext := filepath.Ext(path)
+	tmpPath := strings.TrimSuffix(path, ext) + ".tmp" + ext
+
+	if err := process(tmpPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return errors.InternalWrap("failed to replace processed audio", err)
+	}
+	return nil
+}
+
+// codecArgs returns the ffmpeg output codec arguments for the given file extension.
+func codecArgs(path string) []string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		// 16kHz mono PCM is what Whisper and Azure pronunciation assessment expect
+		return []string{"-acodec", "pcm_s16le", "-ar", "16000", "-ac", "1"}
+	case ".mp3":
+		return []string{"-c:a", "libmp3lame", "-b:a", "128k", "-ar", "16000", "-ac", "1"}
+	case ".m4a":
+		return []string{"-c:a", "aac", "-b:a", "64k", "-ac", "1", "-ar", "16000", "-movflags", "faststart"}
+	case ".opus":
+		// Speech stays clear at a fraction of the mp3 bitrate
+		return []string{"-c:a", "libopus", "-b:a", "24k", "-ac", "1", "-application", "voip"}
+	case ".webp":
+		return []string{"-c:v", "libwebp", "-quality", "80"}
+	case ".avif":
+		return []string{"-c:v", "libaom-av1", "-still-picture", "1", "-crf", "32", "-cpu-used", "6"}
+	default:
+		return nil
+	}
+}
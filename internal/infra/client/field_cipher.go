@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks an encrypted field value: enc:v1:<key id>:<base64(nonce || ciphertext)>.
+const encryptedPrefix = "enc:v1:"
+
+// SensitiveActionFields are the user action metadata fields stored encrypted, by action type.
+// A path is a dot-separated list of keys, where "[]" descends into every array element.
+var SensitiveActionFields = map[string][]string{
+	"submit_retell": {
+		"attempts[].transcript",
+		"attempts[].retell_analysis",
+	},
+	"submit_speech": {
+		"scripts[].evaluation.display_text",
+		"attempts[].scripts[].evaluation.display_text",
+	},
+	"submit_sparring": {
+		"messages[].content",
+		"messages[].suggestion",
+	},
+}
+
+// FieldCipher encrypts sensitive text fields with AES-256-GCM before they are stored. It holds a
+// key ring: new values use the active key, older keys only decrypt until values are re-encrypted.
+// A nil FieldCipher stores plaintext.
+type FieldCipher struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// NewFieldCipher parses a key ring "id:base64key,id:base64key" of 32-byte keys; the first key is
+// active. An empty ring disables encryption and returns a nil cipher.
+func NewFieldCipher(ring string) (*FieldCipher, error) {
+	if strings.TrimSpace(ring) == "" {
+		return nil, nil
+	}
+
+	c := &FieldCipher{keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(ring, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("field encryption key %q must be id:base64key", entry)
+		}
+		if _, dup := c.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate field encryption key id %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode field encryption key %q: %v", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("field encryption key %q must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[id] = aead
+		if c.active == "" {
+			c.active = id
+		}
+	}
+	return c, nil
+}
+
+// ActiveKey returns the id of the key new values are encrypted with ("" when disabled).
+func (c *FieldCipher) ActiveKey() string {
+	if c == nil {
+		return ""
+	}
+	return c.active
+}
+
+// Encrypt seals a value with the active key. Empty values stay empty.
+func (c *FieldCipher) Encrypt(plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	aead := c.keys[c.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + c.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens an encrypted value. Values stored before encryption was enabled are returned as they are.
+func (c *FieldCipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("encrypted field found but field encryption is not configured")
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", fmt.Errorf("malformed encrypted field")
+	}
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("field encrypted with unknown key %q", id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted field")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// current reports whether a value is empty or already encrypted with the active key.
+func (c *FieldCipher) current(value string) bool {
+	return value == "" || strings.HasPrefix(value, encryptedPrefix+c.active+":")
+}
+
+// SealAction encrypts the sensitive fields of an action's metadata.
+func (c *FieldCipher) SealAction(actionType string, metadata json.RawMessage) (json.RawMessage, error) {
+	if c == nil {
+		return metadata, nil
+	}
+	result, _, err := transformFields(metadata, SensitiveActionFields[actionType], c.Encrypt)
+	return result, err
+}
+
+// OpenAction decrypts the sensitive fields of an action's metadata.
+func (c *FieldCipher) OpenAction(actionType string, metadata json.RawMessage) (json.RawMessage, error) {
+	result, _, err := transformFields(metadata, SensitiveActionFields[actionType], c.Decrypt)
+	return result, err
+}
+
+// RotateAction re-encrypts sensitive fields that are plaintext or use an older key. It reports
+// whether anything changed, so unchanged rows are not rewritten.
+func (c *FieldCipher) RotateAction(actionType string, metadata json.RawMessage) (json.RawMessage, bool, error) {
+	if c == nil {
+		return metadata, false, nil
+	}
+	return transformFields(metadata, SensitiveActionFields[actionType], func(value string) (string, error) {
+		if c.current(value) {
+			return value, nil
+		}
+		plaintext, err := c.Decrypt(value)
+		if err != nil {
+			return "", err
+		}
+		return c.Encrypt(plaintext)
+	})
+}
+
+// transformFields rewrites the string values at paths with fn and reports whether any changed.
+// Documents without those fields are returned untouched.
+func transformFields(doc json.RawMessage, paths []string, fn func(string) (string, error)) (json.RawMessage, bool, error) {
+	if len(paths) == 0 || len(bytes.TrimSpace(doc)) == 0 {
+		return doc, false, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, false, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	changed := false
+	for _, path := range paths {
+		if err := transformPath(root, strings.Split(path, "."), fn, &changed); err != nil {
+			return nil, false, err
+		}
+	}
+	if !changed {
+		return doc, false, nil
+	}
+
+	result, err := json.Marshal(root)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return result, true, nil
+}
+
+func transformPath(node interface{}, keys []string, fn func(string) (string, error), changed *bool) error {
+	object, ok := node.(map[string]interface{})
+	if !ok || len(keys) == 0 {
+		return nil
+	}
+
+	key, each := strings.CutSuffix(keys[0], "[]")
+	child, ok := object[key]
+	if !ok {
+		return nil
+	}
+
+	if each {
+		items, ok := child.([]interface{})
+		if !ok {
+			return nil
+		}
+		for _, item := range items {
+			if err := transformPath(item, keys[1:], fn, changed); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(keys) > 1 {
+		return transformPath(child, keys[1:], fn, changed)
+	}
+
+	value, ok := child.(string)
+	if !ok {
+		return nil
+	}
+	updated, err := fn(value)
+	if err != nil {
+		return err
+	}
+	if updated != value {
+		object[key] = updated
+		*changed = true
+	}
+	return nil
+}
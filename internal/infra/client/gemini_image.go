@@ -14,16 +14,42 @@ import (
 	"golang.org/x/oauth2/google"
 )
 
-// GeminiImageClient wraps Vertex AI Imagen 3 Flash model.
+// ImageClient generates images and reads the text in them (GeminiImageClient, FakeProviders in load
+// tests, or a FallbackImageClient chaining them).
+type ImageClient interface {
+	GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([][]byte, *errors.AppError)
+	DetectText(ctx context.Context, image []byte) (string, *errors.AppError)
+}
+
+// GeminiImageClient wraps Vertex AI: the Imagen 3 Flash model and Cloud Vision OCR.
 type GeminiImageClient struct {
+	*vertexAccount
+}
+
+// vertexAccount is the Vertex AI account the Gemini clients share: the platform service account,
+// the organizations' own, and the budget, rate limit and health check of ProviderGemini.
+type vertexAccount struct {
 	projectID string
 	location  string
 	saJSON    []byte
 	client    *http.Client
+	budget    *BudgetClient
+	limiter   *RateLimiter
+	health    *ProviderHealthClient
+
+	credentials *CredentialStore // organization service accounts (nil = platform only)
+}
+
+// gcpTarget is the service account, project and location of one call.
+type gcpTarget struct {
+	saJSON    []byte
+	projectID string
+	location  string
+	own       bool // the organization's service account, kept out of the platform budget
 }
 
 // NewGeminiImageClient creates a new Gemini image client from a Base64-encoded Service Account JSON.
-func NewGeminiImageClient(saBase64, location string) (*GeminiImageClient, error) {
+func NewGeminiImageClient(saBase64, location string, budget *BudgetClient, limiter *RateLimiter, health *ProviderHealthClient, credentials *CredentialStore, faults *FaultInjector) (*GeminiImageClient, error) {
 	if saBase64 == "" {
 		return nil, fmt.Errorf("gemini SA credentials not configured")
 	}
@@ -33,28 +59,134 @@ func NewGeminiImageClient(saBase64, location string) (*GeminiImageClient, error)
 		return nil, fmt.Errorf("failed to decode Base64 SA JSON: %v", err)
 	}
 
-	// Extract project_id from SA JSON
+	projectID, err := serviceAccountProject(saJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GeminiImageClient{vertexAccount: &vertexAccount{
+		projectID:   projectID,
+		location:    location,
+		saJSON:      saJSON,
+		budget:      budget,
+		limiter:     limiter,
+		health:      health,
+		credentials: credentials,
+		client: &http.Client{
+			Timeout:   120 * time.Second,
+			Transport: faults.Transport(FaultTargetGemini, nil),
+		},
+	}}, nil
+}
+
+// serviceAccountProject extracts project_id from a service account JSON.
+func serviceAccountProject(saJSON []byte) (string, error) {
 	var sa struct {
 		ProjectID string `json:"project_id"`
 	}
 	if err := json.Unmarshal(saJSON, &sa); err != nil {
-		return nil, fmt.Errorf("failed to parse SA JSON for project_id: %v", err)
+		return "", fmt.Errorf("failed to parse SA JSON for project_id: %v", err)
 	}
+	return sa.ProjectID, nil
+}
 
-	return &GeminiImageClient{
-		projectID: sa.ProjectID,
-		location:  location,
-		saJSON:    saJSON,
-		client: &http.Client{
-			Timeout: 120 * time.Second,
-		},
-	}, nil
+// target returns the service account of a call: the organization's own when it has one.
+// An organization without a location of its own uses the platform's.
+func (c *vertexAccount) target(ctx context.Context) (*gcpTarget, *errors.AppError) {
+	target, appErr := c.credentials.resolveTarget(ctx, CredentialGCP, providerTarget{})
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !target.own {
+		return &gcpTarget{saJSON: c.saJSON, projectID: c.projectID, location: c.location}, nil
+	}
+
+	projectID, err := serviceAccountProject([]byte(target.secret))
+	if err != nil {
+		return nil, errors.InternalWrap("invalid organization service account", err)
+	}
+	location := target.region
+	if location == "" {
+		location = c.location
+	}
+	return &gcpTarget{saJSON: []byte(target.secret), projectID: projectID, location: location, own: true}, nil
+}
+
+// Imagen aspect ratios
+const (
+	ImageAspectSquare       = "1:1"
+	ImageAspectPortrait     = "9:16"
+	ImageAspectLandscape    = "16:9"
+	ImageAspectPortrait3x4  = "3:4"
+	ImageAspectLandscape4x3 = "4:3"
+)
+
+// Image request defaults and limits
+const (
+	defaultImageAspectRatio = ImageAspectPortrait
+	defaultImageSamples     = 1
+	maxImageSamples         = 4
+)
+
+// Imagen output sizes (sampleImageSize; only some Imagen models accept it)
+const (
+	ImageSize1K = "1K"
+	ImageSize2K = "2K"
+)
+
+// ImageOptions shapes an image generation request. Zero values use the defaults.
+type ImageOptions struct {
+	AspectRatio    string // 1:1, 3:4, 4:3, 9:16 or 16:9 (default 9:16)
+	SampleCount    int    // images returned, 1-4 (default 1)
+	Size           string // 1K or 2K ("" leaves the model default)
+	NegativePrompt string // what the image must not show ("" sends none)
+}
+
+func (o ImageOptions) withDefaults() (ImageOptions, *errors.AppError) {
+	if o.AspectRatio == "" {
+		o.AspectRatio = defaultImageAspectRatio
+	}
+	switch o.AspectRatio {
+	case ImageAspectSquare, ImageAspectPortrait, ImageAspectLandscape, ImageAspectPortrait3x4, ImageAspectLandscape4x3:
+	default:
+		return o, errors.Validation(fmt.Sprintf("unsupported image aspect ratio %q", o.AspectRatio))
+	}
+	if o.SampleCount == 0 {
+		o.SampleCount = defaultImageSamples
+	}
+	if o.SampleCount < 1 || o.SampleCount > maxImageSamples {
+		return o, errors.Validation(fmt.Sprintf("image sample count must be between 1 and %d", maxImageSamples))
+	}
+	if o.Size != "" && o.Size != ImageSize1K && o.Size != ImageSize2K {
+		return o, errors.Validation(fmt.Sprintf("unsupported image size %q", o.Size))
+	}
+	return o, nil
 }
 
-// GenerateImage creates a PNG image and returns the raw bytes.
-func (c *GeminiImageClient) GenerateImage(ctx context.Context, prompt string) ([]byte, *errors.AppError) {
+// GenerateImage creates PNG images shaped by opts and returns their raw bytes (one per sample).
+func (c *GeminiImageClient) GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([][]byte, *errors.AppError) {
+	opts, appErr := opts.withDefaults()
+	if appErr != nil {
+		return nil, appErr
+	}
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if err := c.health.Check(ctx, ProviderGemini); err != nil {
+		return nil, err
+	}
+	if !target.own {
+		if err := c.budget.SpendUnits(ctx, BudgetProviderImage, opts.SampleCount); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
 	// 1. Get Token
-	creds, err := google.CredentialsFromJSON(ctx, c.saJSON, "https://www.googleapis.com/auth/cloud-platform")
+	creds, err := google.CredentialsFromJSON(ctx, target.saJSON, "https://www.googleapis.com/auth/cloud-platform")
 	if err != nil {
 		return nil, errors.InternalWrap("failed to get google credentials", err)
 	}
@@ -65,22 +197,29 @@ func (c *GeminiImageClient) GenerateImage(ctx context.Context, prompt string) ([
 	}
 
 	// 2. Model: imagen-3.0-fast-generate-001
-	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/imagen-3.0-fast-generate-001:predict", c.location, c.projectID, c.location)
+	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/imagen-3.0-fast-generate-001:predict", target.location, target.projectID, target.location)
 
 	// 3. Request Body
+	parameters := map[string]interface{}{
+		"sampleCount": opts.SampleCount,
+		"aspectRatio": opts.AspectRatio,
+		"outputOptions": map[string]interface{}{
+			"mimeType": "image/png",
+		},
+	}
+	if opts.Size != "" {
+		parameters["sampleImageSize"] = opts.Size
+	}
+	if opts.NegativePrompt != "" {
+		parameters["negativePrompt"] = opts.NegativePrompt
+	}
 	reqBody := map[string]interface{}{
 		"instances": []map[string]interface{}{
 			{
 				"prompt": prompt,
 			},
 		},
-		"parameters": map[string]interface{}{
-			"sampleCount": 1,
-			"aspectRatio": "9:16",
-			"outputOptions": map[string]interface{}{
-				"mimeType": "image/png",
-			},
-		},
+		"parameters": parameters,
 	}
 
 	bodyJSON, _ := json.Marshal(reqBody)
@@ -95,7 +234,7 @@ func (c *GeminiImageClient) GenerateImage(ctx context.Context, prompt string) ([
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.InternalWrap("failed to send gemini image request", err)
+		return nil, RequestError(ctx, "failed to send gemini image request", err)
 	}
 	defer resp.Body.Close()
 
@@ -117,14 +256,41 @@ func (c *GeminiImageClient) GenerateImage(ctx context.Context, prompt string) ([
 		return nil, errors.InternalWrap("failed to decode gemini image response", err)
 	}
 
-	if len(result.Predictions) == 0 || result.Predictions[0].BytesBase64Encoded == "" {
+	// Samples blocked by the safety filter come back without image data
+	images := make([][]byte, 0, len(result.Predictions))
+	for _, prediction := range result.Predictions {
+		if prediction.BytesBase64Encoded == "" {
+			continue
+		}
+		imageBytes, err := base64.StdEncoding.DecodeString(prediction.BytesBase64Encoded)
+		if err != nil {
+			return nil, errors.InternalWrap("failed to decode base64 image data", err)
+		}
+		images = append(images, imageBytes)
+	}
+	if len(images) == 0 {
 		return nil, errors.Internal("gemini image api returned no image data")
 	}
 
-	imageBytes, err := base64.StdEncoding.DecodeString(result.Predictions[0].BytesBase64Encoded)
+	return images, nil
+}
+
+// Probe fetches an access token and reads the Imagen model resource (no image is generated).
+func (c *GeminiImageClient) Probe(ctx context.Context) error {
+	creds, err := google.CredentialsFromJSON(ctx, c.saJSON, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return err
+	}
+	token, err := creds.TokenSource.Token()
 	if err != nil {
-		return nil, errors.InternalWrap("failed to decode base64 image data", err)
+		return err
 	}
 
-	return imageBytes, nil
+	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/imagen-3.0-fast-generate-001", c.location, c.projectID, c.location)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return probeHTTP(c.client, req)
 }
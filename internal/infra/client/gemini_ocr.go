@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+// visionAnnotateURL is the Cloud Vision endpoint used to read text in generated images.
+const visionAnnotateURL = "https://vision.googleapis.com/v1/images:annotate"
+
+// DetectText returns the text Cloud Vision reads in the image ("" when there is none).
+// It uses the same service account as image generation (the organization's when it has one).
+func (c *GeminiImageClient) DetectText(ctx context.Context, image []byte) (string, *errors.AppError) {
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return "", appErr
+	}
+	if err := c.health.Check(ctx, ProviderGemini); err != nil {
+		return "", err
+	}
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderOCR); err != nil {
+			return "", err
+		}
+	}
+
+	creds, err := google.CredentialsFromJSON(ctx, target.saJSON, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return "", errors.InternalWrap("failed to get google credentials", err)
+	}
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		return "", errors.InternalWrap("failed to get access token", err)
+	}
+
+	reqBody := map[string]interface{}{
+		"requests": []map[string]interface{}{
+			{
+				"image":    map[string]string{"content": base64.StdEncoding.EncodeToString(image)},
+				"features": []map[string]string{{"type": "TEXT_DETECTION"}},
+			},
+		},
+	}
+	bodyJSON, _ := json.Marshal(reqBody)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, visionAnnotateURL, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return "", errors.InternalWrap("failed to create vision request", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+	// Bill the service account's project rather than the token's default one
+	req.Header.Set("x-goog-user-project", target.projectID)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", RequestError(ctx, "failed to send vision request", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", errors.InternalWrap("vision api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
+	}
+
+	var result struct {
+		Responses []struct {
+			FullTextAnnotation *struct {
+				Text string `json:"text"`
+			} `json:"fullTextAnnotation"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"responses"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.InternalWrap("failed to decode vision response", err)
+	}
+	if len(result.Responses) == 0 {
+		return "", nil
+	}
+	if e := result.Responses[0].Error; e != nil {
+		return "", errors.InternalWrap("vision api error", fmt.Errorf("%s", e.Message))
+	}
+	if a := result.Responses[0].FullTextAnnotation; a != nil {
+		return a.Text, nil
+	}
+	return "", nil
+}
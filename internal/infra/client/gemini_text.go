@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+// geminiTextModel is the Vertex AI Gemini model used for structured output.
+const geminiTextModel = "gemini-2.0-flash-001"
+
+// structuredAttempts is how often GenerateStructured asks before giving up on unparsable output.
+const structuredAttempts = 2
+
+// StructuredClient returns JSON constrained to a response schema (GeminiTextClient).
+type StructuredClient interface {
+	GenerateStructured(ctx context.Context, prompt string, schema map[string]interface{}) (json.RawMessage, *errors.AppError)
+}
+
+// GeminiTextClient asks Gemini on Vertex AI for structured output. It shares the account of the
+// GeminiImageClient it is made from, with its budget (BUDGET_PRICE_GEMINI), Imagen rate limit and
+// health check.
+type GeminiTextClient struct {
+	*vertexAccount
+}
+
+// NewGeminiTextClient creates a Gemini text client on the account of image.
+func NewGeminiTextClient(image *GeminiImageClient) *GeminiTextClient {
+	return &GeminiTextClient{vertexAccount: image.vertexAccount}
+}
+
+// GenerateStructured asks Gemini for JSON that matches schema, a Vertex AI responseSchema (OpenAPI
+// subset: type, properties, items, enum, required, ...). Decoding is constrained to the schema, so
+// the result has no code fences or prose around it. Output that still does not parse, such as a
+// reply cut off at the token limit, is requested once more.
+func (c *GeminiTextClient) GenerateStructured(ctx context.Context, prompt string, schema map[string]interface{}) (json.RawMessage, *errors.AppError) {
+	var lastErr *errors.AppError
+	for attempt := 1; attempt <= structuredAttempts; attempt++ {
+		text, finishReason, err := c.generateContent(ctx, prompt, schema)
+		if err != nil {
+			return nil, err
+		}
+		if json.Valid([]byte(text)) {
+			return json.RawMessage(text), nil
+		}
+		lastErr = errors.InternalWrap("gemini returned invalid JSON", fmt.Errorf("finish reason %s, attempt %d", finishReason, attempt))
+	}
+	return nil, lastErr
+}
+
+// generateContent sends one generateContent request and returns the reply text and why it ended.
+func (c *GeminiTextClient) generateContent(ctx context.Context, prompt string, schema map[string]interface{}) (string, string, *errors.AppError) {
+	target, appErr := c.target(ctx)
+	if appErr != nil {
+		return "", "", appErr
+	}
+	if err := c.health.Check(ctx, ProviderGemini); err != nil {
+		return "", "", err
+	}
+	if !target.own {
+		if err := c.budget.Spend(ctx, BudgetProviderGemini); err != nil {
+			return "", "", err
+		}
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return "", "", err
+	}
+
+	creds, err := google.CredentialsFromJSON(ctx, target.saJSON, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return "", "", errors.InternalWrap("failed to get google credentials", err)
+	}
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		return "", "", errors.InternalWrap("failed to get access token", err)
+	}
+
+	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent", target.location, target.projectID, target.location, geminiTextModel)
+
+	reqBody := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"role":  "user",
+				"parts": []map[string]string{{"text": prompt}},
+			},
+		},
+		"generationConfig": map[string]interface{}{
+			"responseMimeType": "application/json",
+			"responseSchema":   schema,
+		},
+	}
+	bodyJSON, _ := json.Marshal(reqBody)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return "", "", errors.InternalWrap("failed to create gemini request", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", "", RequestError(ctx, "failed to send gemini request", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", "", errors.InternalWrap("gemini api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
+	}
+
+	var result struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+		PromptFeedback *struct {
+			BlockReason string `json:"blockReason"`
+		} `json:"promptFeedback"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", "", errors.InternalWrap("failed to decode gemini response", err)
+	}
+
+	// Blocked prompts come back without candidates
+	if len(result.Candidates) == 0 {
+		if result.PromptFeedback != nil && result.PromptFeedback.BlockReason != "" {
+			return "", "", errors.Validation("prompt was blocked by the safety filter").
+				WithDetails(map[string]interface{}{"block_reason": result.PromptFeedback.BlockReason})
+		}
+		return "", "", errors.Internal("gemini api returned no candidates")
+	}
+
+	var text string
+	for _, part := range result.Candidates[0].Content.Parts {
+		text += part.Text
+	}
+	return text, result.Candidates[0].FinishReason, nil
+}
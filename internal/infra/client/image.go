@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// ImageProvider is a named image client in a fallback chain.
+type ImageProvider struct {
+	Name   string
+	Client ImageClient
+}
+
+// FallbackImageClient tries each image provider in order and returns the first successful result.
+type FallbackImageClient struct {
+	providers []ImageProvider
+	log       *slog.Logger
+}
+
+// NewFallbackImageClient creates an image client that falls back through providers in order.
+func NewFallbackImageClient(providers []ImageProvider, log *slog.Logger) *FallbackImageClient {
+	return &FallbackImageClient{providers: providers, log: log}
+}
+
+// Names returns the names of the providers in chain order.
+func (c *FallbackImageClient) Names() []string {
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		names = append(names, provider.Name)
+	}
+	return names
+}
+
+// GenerateImage generates images with the first provider that succeeds.
+func (c *FallbackImageClient) GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([][]byte, *errors.AppError) {
+	return tryImage(c, func(p ImageProvider) ([][]byte, *errors.AppError) {
+		return p.Client.GenerateImage(ctx, prompt, opts)
+	})
+}
+
+// DetectText reads the text in image with the first provider that succeeds.
+func (c *FallbackImageClient) DetectText(ctx context.Context, image []byte) (string, *errors.AppError) {
+	return tryImage(c, func(p ImageProvider) (string, *errors.AppError) {
+		return p.Client.DetectText(ctx, image)
+	})
+}
+
+func tryImage[T any](c *FallbackImageClient, call func(p ImageProvider) (T, *errors.AppError)) (T, *errors.AppError) {
+	var zero T
+	if len(c.providers) == 0 {
+		return zero, errors.Unsupported("no image provider configured")
+	}
+
+	var lastErr *errors.AppError
+	for _, p := range c.providers {
+		result, err := call(p)
+		if err == nil {
+			return result, nil
+		}
+		if stopsFallback(err) {
+			return zero, err
+		}
+
+		c.log.Warn("Image provider failed, trying next", "provider", p.Name, "error", err.Error())
+		lastErr = err
+	}
+	return zero, lastErr
+}
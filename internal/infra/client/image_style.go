@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// Image style presets
+const (
+	ImageStylePhotorealistic = "photorealistic"
+	ImageStyleVector         = "vector"
+	ImageStyleWatercolor     = "watercolor"
+	ImageStyleKidFriendly    = "kid_friendly"
+)
+
+// imageStylePresets are the style instructions appended to generated image prompts.
+var imageStylePresets = map[string]string{
+	ImageStylePhotorealistic: "Photorealistic photograph, natural lighting, realistic textures and proportions, shallow depth of field.",
+	ImageStyleVector:         "Flat vector illustration, clean shapes, bold outlines, limited color palette, no gradients or photo textures.",
+	ImageStyleWatercolor:     "Soft watercolor painting, visible paper texture, gentle washes of color, loose brush strokes.",
+	ImageStyleKidFriendly:    "Bright, friendly cartoon illustration for children, rounded shapes, cheerful colors, nothing scary or violent.",
+}
+
+// ImageStyleName normalizes a style name ("Kid-Friendly" -> "kid_friendly").
+func ImageStyleName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+}
+
+// IsImageStyle reports whether name is a style preset.
+func IsImageStyle(name string) bool {
+	_, ok := imageStylePresets[ImageStyleName(name)]
+	return ok
+}
+
+// OrganizationImageStyles looks up the default image style an organization chose ("" for none).
+type OrganizationImageStyles interface {
+	OrganizationImageStyle(ctx context.Context, organizationID string) (string, *errors.AppError)
+}
+
+// ImageStyles resolves style presets to the text appended to image prompts.
+type ImageStyles struct {
+	prompts       map[string]string
+	defaultStyle  string
+	organizations OrganizationImageStyles
+}
+
+// NewImageStyles builds the presets with overrides of their prompt text (by preset name), the
+// style used when a request names none ("" leaves the prompt as the model wrote it) and the
+// organizations' own defaults, which take precedence over it (nil for none).
+func NewImageStyles(overrides map[string]string, defaultStyle string, organizations OrganizationImageStyles) (*ImageStyles, error) {
+	styles := &ImageStyles{prompts: make(map[string]string, len(imageStylePresets)), organizations: organizations}
+	for name, prompt := range imageStylePresets {
+		styles.prompts[name] = prompt
+	}
+	for name, prompt := range overrides {
+		name = ImageStyleName(name)
+		if _, ok := styles.prompts[name]; !ok {
+			return nil, fmt.Errorf("unknown image style %q", name)
+		}
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			styles.prompts[name] = prompt
+		}
+	}
+
+	styles.defaultStyle = ImageStyleName(defaultStyle)
+	if styles.defaultStyle != "" && !IsImageStyle(styles.defaultStyle) {
+		return nil, fmt.Errorf("unknown default image style %q", defaultStyle)
+	}
+	return styles, nil
+}
+
+// Resolve returns the style applied for a requested style name: the request's, else the default of
+// the context's organization, else the platform default. A failed organization lookup falls back
+// to the platform default rather than failing the image.
+func (s *ImageStyles) Resolve(ctx context.Context, style string) string {
+	if s == nil {
+		return ""
+	}
+	if style = ImageStyleName(style); style != "" {
+		return style
+	}
+	if organizationID := OrganizationFromContext(ctx); organizationID != "" && s.organizations != nil {
+		if style, err := s.organizations.OrganizationImageStyle(ctx, organizationID); err == nil && IsImageStyle(style) {
+			return ImageStyleName(style)
+		}
+	}
+	return s.defaultStyle
+}
+
+// Apply appends the resolved style to prompt.
+func (s *ImageStyles) Apply(ctx context.Context, prompt, style string) string {
+	text, ok := "", false
+	if style = s.Resolve(ctx, style); style != "" {
+		text, ok = s.prompts[style]
+	}
+	if !ok {
+		return prompt
+	}
+	return strings.TrimSpace(prompt) + "\n\nStyle: " + text
+}
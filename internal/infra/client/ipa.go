@@ -0,0 +1,305 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxIPALength caps a transcription (characters).
+const maxIPALength = 200
+
+// ipaInventory is the broad segment set and syllable shape of a language.
+type ipaInventory struct {
+	vowels     string
+	consonants string
+	affricates []string // two-letter affricates written without a tie bar
+	syllabic   string   // consonants that may form a syllable on their own
+	maxOnset   int
+	maxCoda    int
+	maxVowels  int  // longest vowel run (diphthongs, triphthongs, vowel sequences)
+	tonal      bool // every word needs tone letters; tone diacritics are rejected
+}
+
+// ipaVowels are the IPA vowel letters; languages without an inventory accept all of them.
+const ipaVowels = "iyɨʉɯuɪʏʊeøɘɵɤoəɛœɜɞʌɔæɐaɶɑɒɚɝᵻɿʅ"
+
+// ipaConsonants are the IPA consonant letters (including glides).
+const ipaConsonants = "pbtdʈɖcɟkɡgqɢʔmɱnɳɲŋɴʙrʀⱱɾɽɸβfvθðszʃʒʂʐçʝxɣχʁħʕhɦɬɮʋɹɻjɰlɭʎʟɫwʍɥɕʑɺʜʢʡʘǀǃǂǁɓɗʄɠʛʧʤ"
+
+var ipaInventories = map[string]ipaInventory{
+	"zh": {
+		vowels:     "aɑeɛəɤiɨɪoʊuyɯɚʌɔæɐɵʏɿʅ",
+		consonants: "ptkmnŋfsɕʂxhlɻʐɹjwɥʈɖʔɣdbɡgzʑ",
+		affricates: []string{"ts", "tɕ", "ʈʂ", "tʂ"},
+		syllabic:   "mnŋɻɹ",
+		maxOnset:   2, maxCoda: 1, maxVowels: 3,
+		tonal: true,
+	},
+	"ja": {
+		vowels:     "aiɯueoɨɪʊɛɔə",
+		consonants: "pbtdkɡgszɕʑhçɸmnɲŋɴɾrjwɰʔʃʒɺβ",
+		affricates: []string{"ts", "dz", "tɕ", "dʑ", "tʃ", "dʒ"},
+		syllabic:   "ɴmnŋ",
+		maxOnset:   2, maxCoda: 1, maxVowels: 4,
+	},
+	"ko": {
+		vowels:     "aeɛioɯuʌøyɨɐəɔɪʊ",
+		consonants: "pbtdkɡgszɕʑhɦmnŋlɾrjwɥɰʔçxɸʃβ",
+		affricates: []string{"tɕ", "dʑ", "ts", "tʃ", "dʒ"},
+		maxOnset:   2, maxCoda: 1, maxVowels: 3,
+	},
+	"en": {
+		vowels:     "iɪeɛæaɑɒɔoʊuʌəɜɝɚɐɘɵɨʉ",
+		consonants: "pbtdkɡgfvθðszʃʒhmnŋlɫɹrɾwjʍʔxç",
+		affricates: []string{"tʃ", "dʒ"},
+		syllabic:   "mnlɹ",
+		maxOnset:   3, maxCoda: 4, maxVowels: 3,
+	},
+	"fr": {
+		vowels:     "iyueøoəɛœɔaɑɐɪʏʊ",
+		consonants: "pbtdkɡgfvszʃʒmnɲŋlʁrʀχjwɥ",
+		maxOnset:   3, maxCoda: 3, maxVowels: 3,
+	},
+	"es": {
+		vowels:     "aeiouɛɔəɪʊ",
+		consonants: "pbβtdðkɡgɣfθszʝʃxχhmnɲŋɱlʎɾrjwɟ",
+		affricates: []string{"tʃ"},
+		maxOnset:   3, maxCoda: 2, maxVowels: 3,
+	},
+	"pt": {
+		vowels:     "aɐeɛioɔuɨəɪʊ",
+		consonants: "pbtdkɡgfvszʃʒmnɲŋlɫʎɾrʁχhxɣjwβð",
+		affricates: []string{"tʃ", "dʒ"},
+		maxOnset:   3, maxCoda: 2, maxVowels: 3,
+	},
+	"ru": {
+		vowels:     "aɐəeɛiɨɪoɔuʊʉæɵʏ",
+		consonants: "pbtdkɡgfvszʂʐɕʑxɣmnɲlɫʎrɾjʃʒ",
+		affricates: []string{"ts", "tɕ", "tʃ"},
+		maxOnset:   4, maxCoda: 4, maxVowels: 3,
+	},
+	"ar": {
+		vowels:     "aiueoɑæɛɪʊəɐ",
+		consonants: "btdkɡgqfθðszʃʒxɣχʁħʕhʔmnlɫrɾjw",
+		affricates: []string{"dʒ"},
+		maxOnset:   2, maxCoda: 2, maxVowels: 2,
+	},
+}
+
+// ipaDefault applies to languages without an inventory.
+var ipaDefault = ipaInventory{
+	vowels:     ipaVowels,
+	consonants: ipaConsonants,
+	syllabic:   "mnŋlrɹ",
+	maxOnset:   4, maxCoda: 4, maxVowels: 3,
+}
+
+const (
+	ipaModifiers    = "ʰʲʷˠˤʼⁿˡ˞ːˑ"  // attach to the previous segment
+	ipaStress       = "ˈˌ."          // syllable boundaries
+	ipaToneLetters  = "˥˦˧˨˩¹²³⁴⁵⁰"  // tonal languages only
+	ipaTies         = "\u0361\u035c" // affricate tie bars
+	ipaSyllabicMark = "\u0329\u030d" // syllabic consonant
+	// Pinyin-style accents (acute, grave, macron, caron, circumflex, double acute, double grave)
+	ipaToneMarks = "\u0301\u0300\u0304\u030c\u0302\u030b\u030f"
+)
+
+// ipaDiacritics are the combining marks accepted on a segment (nasal, voiceless, dental,
+// non-syllabic, unreleased, tense, centralized, tongue position, rounding, phonation, velarized).
+const ipaDiacritics = "\u0303\u0325\u030a\u032a\u032f\u031a\u0348\u0308\u031f\u0320\u031d\u031e\u0318\u0319\u033a\u033b\u033c\u0339\u031c\u0324\u0330\u0334\u0327"
+
+// LanguageCode maps a language name (e.g. "english") or code to its two-letter code, or "" when unknown.
+func LanguageCode(language string) string {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "english", "en":
+		return "en"
+	case "french", "fr":
+		return "fr"
+	case "spanish", "es":
+		return "es"
+	case "portuguese", "pt":
+		return "pt"
+	case "russian", "ru":
+		return "ru"
+	case "arabic", "ar":
+		return "ar"
+	}
+	return RomanizationLanguage(language)
+}
+
+// ipaSegment is one sound: a base letter with its diacritics and modifiers.
+type ipaSegment struct {
+	base    string
+	nucleus bool
+}
+
+// ValidateIPA checks that ipa only uses the IPA letters of the language and that every
+// syllable has a nucleus and clusters the language allows. Enclosing slashes or brackets are ignored.
+func ValidateIPA(language, ipa string) *errors.AppError {
+	inv, ok := ipaInventories[LanguageCode(language)]
+	if !ok {
+		inv = ipaDefault
+	}
+
+	s := strings.TrimSpace(ipa)
+	if len(s) >= 2 && (s[0] == '/' && s[len(s)-1] == '/' || s[0] == '[' && s[len(s)-1] == ']') {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if s == "" {
+		return errors.Validation("IPA is empty")
+	}
+	if utf8.RuneCountInString(s) > maxIPALength {
+		return errors.Validation("IPA is too long")
+	}
+	// Decompose accented vowels, but keep ç a single letter
+	s = strings.ReplaceAll(norm.NFD.String(s), "c\u0327", "\u00e7")
+
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '‿' || r == '|' || r == '‖' }) {
+		if err := inv.validateWord(word); err != nil {
+			return errors.Validation(fmt.Sprintf("invalid IPA %q: %s", ipa, err))
+		}
+	}
+	return nil
+}
+
+// validateWord splits word into syllables and checks each of them.
+func (inv ipaInventory) validateWord(word string) error {
+	var syllables [][]ipaSegment
+	var current []ipaSegment
+	tones := 0
+	tie := false
+	closeSyllable := func() {
+		if len(current) > 0 {
+			syllables = append(syllables, current)
+			current = nil
+		}
+	}
+
+	for _, r := range word {
+		switch {
+		case strings.ContainsRune(ipaStress, r):
+			closeSyllable()
+		case strings.ContainsRune(ipaToneLetters, r):
+			if !inv.tonal {
+				return fmt.Errorf("tone letter %q in a non-tonal language", r)
+			}
+			if len(current) == 0 && len(syllables) == 0 {
+				return fmt.Errorf("tone letter before any sound")
+			}
+			tones++
+			closeSyllable()
+		case strings.ContainsRune(ipaToneMarks, r):
+			return fmt.Errorf("accent %q is not IPA (use tone letters)", r)
+		case strings.ContainsRune(ipaTies, r):
+			if len(current) == 0 {
+				return fmt.Errorf("tie bar without a preceding sound")
+			}
+			tie = true
+		case strings.ContainsRune(ipaSyllabicMark, r):
+			if len(current) == 0 {
+				return fmt.Errorf("syllabic mark without a preceding sound")
+			}
+			current[len(current)-1].nucleus = true
+		case strings.ContainsRune(ipaModifiers, r) || strings.ContainsRune(ipaDiacritics, r):
+			if len(current) == 0 {
+				return fmt.Errorf("%q without a preceding sound", r)
+			}
+		case strings.ContainsRune(inv.vowels, r):
+			current = append(current, ipaSegment{base: string(r), nucleus: true})
+			tie = false
+		case strings.ContainsRune(inv.consonants, r):
+			if n := len(current); n > 0 && !current[n-1].nucleus &&
+				(tie || inv.isAffricate(current[n-1].base+string(r))) {
+				current[n-1].base += string(r)
+			} else {
+				current = append(current, ipaSegment{base: string(r)})
+			}
+			tie = false
+		default:
+			return fmt.Errorf("%q is not an IPA letter of the language", r)
+		}
+	}
+	closeSyllable()
+
+	if len(syllables) == 0 {
+		return fmt.Errorf("no sounds")
+	}
+	if inv.tonal && tones == 0 {
+		return fmt.Errorf("missing tone letters")
+	}
+
+	// Without written boundaries the whole word is checked as one run of syllables
+	for _, syl := range syllables {
+		if !inv.hasNucleus(syl) {
+			return fmt.Errorf("syllable without a vowel")
+		}
+		syl[0].nucleus = syl[0].nucleus || len(syl) == 1
+		if err := inv.checkClusters(syl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkClusters checks the onset, coda, medial clusters and vowel runs of segs.
+func (inv ipaInventory) checkClusters(segs []ipaSegment) error {
+	first, last := -1, -1
+	for i, seg := range segs {
+		if seg.nucleus {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return fmt.Errorf("syllable without a vowel")
+	}
+	if first > inv.maxOnset {
+		return fmt.Errorf("onset cluster too long")
+	}
+	if len(segs)-1-last > inv.maxCoda {
+		return fmt.Errorf("coda cluster too long")
+	}
+
+	consonants, vowels := 0, 0
+	for _, seg := range segs[first : last+1] {
+		if seg.nucleus {
+			if consonants > inv.maxOnset+inv.maxCoda {
+				return fmt.Errorf("consonant cluster too long")
+			}
+			consonants = 0
+			vowels++
+			if vowels > inv.maxVowels {
+				return fmt.Errorf("too many vowels in a row")
+			}
+			continue
+		}
+		vowels = 0
+		consonants++
+	}
+	return nil
+}
+
+func (inv ipaInventory) hasNucleus(segs []ipaSegment) bool {
+	for _, seg := range segs {
+		if seg.nucleus {
+			return true
+		}
+	}
+	// A lone syllabic consonant (e.g. Japanese ɴ)
+	return len(segs) == 1 && strings.Contains(inv.syllabic, segs[0].base)
+}
+
+func (inv ipaInventory) isAffricate(s string) bool {
+	for _, a := range inv.affricates {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobRegistryOptions controls when a running background job counts as overdue.
+type JobRegistryOptions struct {
+	// ExpectedDuration is the default budget for any job name not listed in ExpectedByName.
+	ExpectedDuration time.Duration
+	ExpectedByName   map[string]time.Duration
+	CheckInterval    time.Duration
+}
+
+// TrackedJob is a snapshot of one running background job.
+type TrackedJob struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	BatchID   string    `json:"batch_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Running   float64   `json:"running_seconds"`
+	Expected  float64   `json:"expected_seconds"`
+	Overdue   bool      `json:"overdue"`
+}
+
+// JobRegistryStats is what the registry publishes on /debug/vars.
+type JobRegistryStats struct {
+	Running  int    `json:"running"`
+	Overdue  int    `json:"overdue"`
+	Started  uint64 `json:"started"`
+	Finished uint64 `json:"finished"`
+	Alerted  uint64 `json:"alerted"`
+}
+
+type trackedJob struct {
+	id        uint64
+	name      string
+	batchID   string
+	startedAt time.Time
+	alerted   bool
+}
+
+// JobRegistry keeps every queue job and the goroutines it spawns in one place,
+// so a job that never returns shows up instead of leaking silently.
+type JobRegistry struct {
+	opts     JobRegistryOptions
+	log      *slog.Logger
+	mu       sync.Mutex
+	jobs     map[uint64]*trackedJob
+	nextID   atomic.Uint64
+	finished atomic.Uint64
+	alerted  atomic.Uint64
+}
+
+// NewJobRegistry creates a new job registry.
+func NewJobRegistry(opts JobRegistryOptions, log *slog.Logger) *JobRegistry {
+	return &JobRegistry{
+		opts: opts,
+		log:  log,
+		jobs: make(map[uint64]*trackedJob),
+	}
+}
+
+type jobRegistryKey struct{}
+type jobBatchKey struct{}
+
+// Start records a running job and returns a ctx carrying the registry and batch ID
+// (for TrackGo) plus the func to call when the job returns. A nil registry is a no-op.
+func (r *JobRegistry) Start(ctx context.Context, name, batchID string) (context.Context, func()) {
+	if r == nil {
+		return ctx, func() {}
+	}
+
+	id := r.nextID.Add(1)
+	r.mu.Lock()
+	r.jobs[id] = &trackedJob{id: id, name: name, batchID: batchID, startedAt: time.Now()}
+	r.mu.Unlock()
+
+	ctx = context.WithValue(ctx, jobRegistryKey{}, r)
+	ctx = context.WithValue(ctx, jobBatchKey{}, batchID)
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			job := r.jobs[id]
+			delete(r.jobs, id)
+			r.mu.Unlock()
+
+			r.finished.Add(1)
+			if job != nil && job.alerted {
+				r.log.Info("Overdue background job finished",
+					"job_id", id,
+					"job_name", name,
+					"batch_id", batchID,
+					"duration", time.Since(job.startedAt).String(),
+				)
+			}
+		})
+	}
+}
+
+// TrackGo runs fn in a goroutine registered under the registry and batch stored in ctx
+// by Start. Without a registry in ctx it behaves like a plain go statement.
+func TrackGo(ctx context.Context, name string, fn func()) {
+	r, _ := ctx.Value(jobRegistryKey{}).(*JobRegistry)
+	batchID, _ := ctx.Value(jobBatchKey{}).(string)
+
+	_, done := r.Start(ctx, name, batchID)
+	go func() {
+		defer done()
+		fn()
+	}()
+}
+
+func (r *JobRegistry) expected(name string) time.Duration {
+	if d, ok := r.opts.ExpectedByName[name]; ok {
+		return d
+	}
+	return r.opts.ExpectedDuration
+}
+
+// List returns the running jobs, longest-running first.
+func (r *JobRegistry) List() []TrackedJob {
+	now := time.Now()
+
+	r.mu.Lock()
+	jobs := make([]TrackedJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		running := now.Sub(job.startedAt)
+		expected := r.expected(job.name)
+		jobs = append(jobs, TrackedJob{
+			ID:        job.id,
+			Name:      job.name,
+			BatchID:   job.batchID,
+			StartedAt: job.startedAt,
+			Running:   running.Seconds(),
+			Expected:  expected.Seconds(),
+			Overdue:   expected > 0 && running > expected,
+		})
+	}
+	r.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.Before(jobs[j].StartedAt) })
+	return jobs
+}
+
+// Stats returns running/overdue counts and lifetime counters.
+func (r *JobRegistry) Stats() JobRegistryStats {
+	jobs := r.List()
+	overdue := 0
+	for _, job := range jobs {
+		if job.Overdue {
+			overdue++
+		}
+	}
+
+	return JobRegistryStats{
+		Running:  len(jobs),
+		Overdue:  overdue,
+		Started:  r.nextID.Load(),
+		Finished: r.finished.Load(),
+		Alerted:  r.alerted.Load(),
+	}
+}
+
+// Watch logs an error once for every job that runs past its expected duration,
+// until ctx is cancelled.
+func (r *JobRegistry) Watch(ctx context.Context) {
+	if r.opts.CheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkOverdue()
+		}
+	}
+}
+
+func (r *JobRegistry) checkOverdue() {
+	now := time.Now()
+
+	r.mu.Lock()
+	var overdue []trackedJob
+	for _, job := range r.jobs {
+		expected := r.expected(job.name)
+		if job.alerted || expected <= 0 || now.Sub(job.startedAt) <= expected {
+			continue
+		}
+		job.alerted = true
+		overdue = append(overdue, *job)
+	}
+	r.mu.Unlock()
+
+	for _, job := range overdue {
+		r.alerted.Add(1)
+		r.log.Error("Background job exceeded expected duration",
+			"job_id", job.id,
+			"job_name", job.name,
+			"batch_id", job.batchID,
+			"running", now.Sub(job.startedAt).String(),
+			"expected", r.expected(job.name).String(),
+		)
+	}
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// maintenanceKey is the Redis hash of active maintenance flags, one field per scope.
+const maintenanceKey = "maintenance:flags"
+
+// MaintenanceScopeAll puts the whole API into maintenance.
+const MaintenanceScopeAll = "all"
+
+// Kill switch scopes for expensive routes
+const (
+	MaintenanceScopeVideoUpload    = "video_upload"
+	MaintenanceScopeVideoBulk      = "video_bulk"
+	MaintenanceScopeDialogGenerate = "dialog_generate"
+)
+
+// MaintenanceScopes lists the scopes that can be switched off.
+var MaintenanceScopes = map[string]bool{
+	MaintenanceScopeAll:            true,
+	MaintenanceScopeVideoUpload:    true,
+	MaintenanceScopeVideoBulk:      true,
+	MaintenanceScopeDialogGenerate: true,
+}
+
+// MaintenanceFlag is an active maintenance switch.
+type MaintenanceFlag struct {
+	Scope      string    `json:"scope"`
+	Message    string    `json:"message"`
+	RetryAfter int       `json:"retry_after"` // seconds, sent as the Retry-After header
+	Since      time.Time `json:"since"`
+}
+
+// MaintenanceClient reads and writes maintenance flags in Redis. Reads are cached for
+// refresh so the middleware does not hit Redis on every request; if Redis is
+// unreachable the last known flags stay in effect.
+type MaintenanceClient struct {
+	redis   *RedisClient
+	refresh time.Duration
+	log     *slog.Logger
+
+	mu       sync.RWMutex
+	flags    map[string]MaintenanceFlag
+	loadedAt time.Time
+}
+
+// NewMaintenanceClient creates a new maintenance flag client.
+func NewMaintenanceClient(redis *RedisClient, refresh time.Duration, log *slog.Logger) *MaintenanceClient {
+	return &MaintenanceClient{
+		redis:   redis,
+		refresh: refresh,
+		log:     log,
+		flags:   make(map[string]MaintenanceFlag),
+	}
+}
+
+// Active returns the flag that blocks scope: the global flag first, then the scope's own.
+func (c *MaintenanceClient) Active(ctx context.Context, scope string) (*MaintenanceFlag, bool) {
+	flags := c.load(ctx)
+	if flag, ok := flags[MaintenanceScopeAll]; ok {
+		return &flag, true
+	}
+	if scope == "" {
+		return nil, false
+	}
+	if flag, ok := flags[scope]; ok {
+		return &flag, true
+	}
+	return nil, false
+}
+
+// List returns the active flags, sorted by scope.
+func (c *MaintenanceClient) List(ctx context.Context) ([]MaintenanceFlag, *errors.AppError) {
+	if err := c.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list := make([]MaintenanceFlag, 0, len(c.flags))
+	for _, flag := range c.flags {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Scope < list[j].Scope })
+	return list, nil
+}
+
+// Enable turns a scope off until Disable is called.
+func (c *MaintenanceClient) Enable(ctx context.Context, flag MaintenanceFlag) *errors.AppError {
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return errors.InternalWrap("failed to marshal maintenance flag", err)
+	}
+	if err := c.redis.HSet(ctx, maintenanceKey, flag.Scope, string(data)); err != nil {
+		return errors.InternalWrap("failed to set maintenance flag", err)
+	}
+	return c.reload(ctx)
+}
+
+// Disable turns a scope back on.
+func (c *MaintenanceClient) Disable(ctx context.Context, scope string) *errors.AppError {
+	if err := c.redis.HDel(ctx, maintenanceKey, scope); err != nil {
+		return errors.InternalWrap("failed to clear maintenance flag", err)
+	}
+	return c.reload(ctx)
+}
+
+// load returns the cached flags, refreshing them when stale.
+func (c *MaintenanceClient) load(ctx context.Context) map[string]MaintenanceFlag {
+	c.mu.RLock()
+	fresh := time.Since(c.loadedAt) < c.refresh
+	flags := c.flags
+	c.mu.RUnlock()
+	if fresh {
+		return flags
+	}
+
+	if err := c.reload(ctx); err != nil {
+		c.log.Warn("Failed to refresh maintenance flags", "error", err.Error())
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.flags
+}
+
+func (c *MaintenanceClient) reload(ctx context.Context) *errors.AppError {
+	fields, err := c.redis.HGetAll(ctx, maintenanceKey)
+	if err != nil {
+		// Keep the last known flags, but retry only after the next refresh interval
+		c.mu.Lock()
+		c.loadedAt = time.Now()
+		c.mu.Unlock()
+		return errors.InternalWrap("failed to load maintenance flags", err)
+	}
+
+	flags := make(map[string]MaintenanceFlag, len(fields))
+	for scope, raw := range fields {
+		var flag MaintenanceFlag
+		if err := json.Unmarshal([]byte(raw), &flag); err != nil {
+			continue
+		}
+		flag.Scope = scope
+		flags[scope] = flag
+	}
+
+	c.mu.Lock()
+	c.flags = flags
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
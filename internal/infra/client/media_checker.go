@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// MediaChecker checks that stored media URLs still resolve.
+type MediaChecker struct {
+	client *http.Client
+}
+
+// NewMediaChecker creates a media checker whose requests give up after timeout.
+func NewMediaChecker(timeout time.Duration) *MediaChecker {
+	return &MediaChecker{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Check sends a HEAD request to url and returns the response status. Servers that do not
+// allow HEAD are asked for the first byte instead.
+func (c *MediaChecker) Check(ctx context.Context, url string) (int, error) {
+	status, err := c.do(ctx, http.MethodHead, url)
+	if err != nil || status != http.StatusMethodNotAllowed {
+		return status, err
+	}
+	return c.do(ctx, http.MethodGet, url)
+}
+
+func (c *MediaChecker) do(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
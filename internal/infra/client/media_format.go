@@ -0,0 +1,56 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MediaFormat is an alternate encoding stored next to an audio or image asset.
+type MediaFormat struct {
+	Name        string
+	Ext         string
+	ContentType string
+}
+
+// mediaFormats are the variant encodings ffmpeg can produce (see codecArgs).
+var mediaFormats = map[string]MediaFormat{
+	"opus": {Name: "opus", Ext: ".opus", ContentType: "audio/ogg"},
+	"m4a":  {Name: "m4a", Ext: ".m4a", ContentType: "audio/mp4"},
+	"webp": {Name: "webp", Ext: ".webp", ContentType: "image/webp"},
+	"avif": {Name: "avif", Ext: ".avif", ContentType: "image/avif"},
+}
+
+// MediaVariants lists the formats uploaded next to each generated audio and image asset.
+type MediaVariants struct {
+	Audio []MediaFormat
+	Image []MediaFormat
+}
+
+// NewMediaVariants resolves the configured format names, e.g. audio "opus" and image "webp,avif".
+func NewMediaVariants(audio, image []string) (MediaVariants, error) {
+	var variants MediaVariants
+	var err error
+	if variants.Audio, err = lookupMediaFormats(audio, "audio/"); err != nil {
+		return MediaVariants{}, err
+	}
+	if variants.Image, err = lookupMediaFormats(image, "image/"); err != nil {
+		return MediaVariants{}, err
+	}
+	return variants, nil
+}
+
+func lookupMediaFormats(names []string, kind string) ([]MediaFormat, error) {
+	var formats []MediaFormat
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		format, ok := mediaFormats[name]
+		if !ok || !strings.HasPrefix(format.ContentType, kind) {
+			return nil, fmt.Errorf("unknown %s media format %q", strings.TrimSuffix(kind, "/"), name)
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// OllamaClient wraps a self-hosted Ollama chat API.
+// It is meant for low-stakes generation in dev and cost-sensitive environments.
+type OllamaClient struct {
+	baseURL  string // e.g. http://localhost:11434
+	model    string
+	jsonMode bool
+	client   *http.Client
+}
+
+// ollamaChatRequest is the request body for the Ollama /api/chat endpoint.
+type ollamaChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+}
+
+// ollamaChatResponse is the response from the Ollama /api/chat endpoint, or one chunk of it when streaming.
+type ollamaChatResponse struct {
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+}
+
+// NewOllamaClient creates a new Ollama chat client.
+// When jsonMode is true the model is constrained to return valid JSON.
+func NewOllamaClient(baseURL, model string, jsonMode bool) *OllamaClient {
+	return &OllamaClient{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		model:    model,
+		jsonMode: jsonMode,
+		client: &http.Client{
+			Timeout: 300 * time.Second, // local models on CPU can be slow
+		},
+	}
+}
+
+// Configured reports whether an Ollama URL and model are set.
+func (c *OllamaClient) Configured() bool {
+	return c.baseURL != "" && c.model != ""
+}
+
+// ChatCompletion sends a system prompt + user message and returns the assistant's response text.
+func (c *OllamaClient) ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError) {
+	return c.ChatCompletionMultiTurn(ctx, []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	})
+}
+
+// ChatCompletionMultiTurn sends a full message history and returns the assistant's response text.
+func (c *OllamaClient) ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, false)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	var result ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.InternalWrap("failed to decode response", err)
+	}
+
+	if result.Message.Content == "" {
+		return "", errors.Internal("empty response returned from ollama")
+	}
+
+	return result.Message.Content, nil
+}
+
+// Stream sends a full message history and passes the response text to onDelta as it arrives.
+// Ollama streams one JSON object per line, the last with done set.
+func (c *OllamaClient) Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, true)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaChatResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", RequestError(ctx, "failed to read stream", err)
+		}
+		if chunk.Message.Content != "" {
+			text.WriteString(chunk.Message.Content)
+			onDelta(chunk.Message.Content)
+		}
+		if chunk.Done {
+			break
+		}
+	}
+
+	if text.Len() == 0 {
+		return "", errors.Internal("empty response returned from ollama")
+	}
+	return text.String(), nil
+}
+
+// send posts a chat request and returns the successful response for the caller to read and close.
+func (c *OllamaClient) send(ctx context.Context, messages []ChatMessage, stream bool) (*http.Response, *errors.AppError) {
+	if c.baseURL == "" || c.model == "" {
+		return nil, errors.Unsupported("Ollama not configured")
+	}
+
+	reqBody := ollamaChatRequest{Model: c.model, Messages: messages, Stream: stream}
+	if c.jsonMode {
+		reqBody.Format = "json"
+	}
+
+	bodyJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.InternalWrap("failed to marshal request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewReader(bodyJSON))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, RequestError(ctx, "failed to send request", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, errors.InternalWrap("ollama chat api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// OpenAICompatibleClient talks to any OpenAI-compatible Chat Completions API,
+// e.g. an LLM gateway such as OpenRouter or LiteLLM.
+type OpenAICompatibleClient struct {
+	baseURL string // e.g. https://openrouter.ai/api/v1
+	apiKey  string
+	model   string
+	client  *http.Client
+	budget  *BudgetClient
+	limiter *RateLimiter
+}
+
+// openAIChatRequest is the request body for an OpenAI-compatible Chat Completions API.
+type openAIChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+}
+
+// NewOpenAICompatibleClient creates a new OpenAI-compatible chat client.
+func NewOpenAICompatibleClient(baseURL, apiKey, model string, budget *BudgetClient, limiter *RateLimiter) *OpenAICompatibleClient {
+	return &OpenAICompatibleClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		budget:  budget,
+		limiter: limiter,
+		client: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// Configured reports whether the gateway URL and chat model are set.
+func (c *OpenAICompatibleClient) Configured() bool {
+	return c.baseURL != "" && c.model != ""
+}
+
+// ChatCompletion sends a system prompt + user message and returns the assistant's response text.
+func (c *OpenAICompatibleClient) ChatCompletion(ctx context.Context, systemPrompt, userMessage string) (string, *errors.AppError) {
+	return c.ChatCompletionMultiTurn(ctx, []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	})
+}
+
+// ChatCompletionMultiTurn sends a full message history and returns the assistant's response text.
+func (c *OpenAICompatibleClient) ChatCompletionMultiTurn(ctx context.Context, messages []ChatMessage) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, false)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	var result chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.InternalWrap("failed to decode response", err)
+	}
+
+	if len(result.Choices) == 0 {
+		return "", errors.Internal("no choices returned from llm gateway")
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+// Stream sends a full message history and passes the response text to onDelta as it arrives.
+func (c *OpenAICompatibleClient) Stream(ctx context.Context, messages []ChatMessage, onDelta func(string)) (string, *errors.AppError) {
+	resp, appErr := c.send(ctx, messages, true)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer resp.Body.Close()
+
+	return readChatStream(ctx, resp.Body, onDelta)
+}
+
+// send posts a Chat Completions request and returns the successful response for the caller to read and close.
+func (c *OpenAICompatibleClient) send(ctx context.Context, messages []ChatMessage, stream bool) (*http.Response, *errors.AppError) {
+	if c.baseURL == "" || c.model == "" {
+		return nil, errors.Unsupported("LLM gateway not configured")
+	}
+
+	if err := c.budget.Spend(ctx, BudgetProviderGateway); err != nil {
+		return nil, err
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	bodyJSON, err := json.Marshal(openAIChatRequest{Model: c.model, Messages: messages, Stream: stream})
+	if err != nil {
+		return nil, errors.InternalWrap("failed to marshal request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(bodyJSON))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create request", err)
+	}
+
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, RequestError(ctx, "failed to send request", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, errors.InternalWrap("llm gateway chat api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
+	}
+	return resp, nil
+}
+
+// openAIEmbeddingRequest is the request body for an OpenAI-compatible Embeddings API.
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// openAIEmbeddingResponse is the response of an OpenAI-compatible Embeddings API.
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embeddings returns one embedding vector per input, in input order.
+func (c *OpenAICompatibleClient) Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, *errors.AppError) {
+	if c.baseURL == "" || model == "" {
+		return nil, errors.Unsupported("LLM gateway embeddings not configured")
+	}
+
+	if err := c.budget.Spend(ctx, BudgetProviderGateway); err != nil {
+		return nil, err
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	bodyJSON, err := json.Marshal(openAIEmbeddingRequest{Model: model, Input: inputs})
+	if err != nil {
+		return nil, errors.InternalWrap("failed to marshal request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/embeddings", bytes.NewReader(bodyJSON))
+	if err != nil {
+		return nil, errors.InternalWrap("failed to create request", err)
+	}
+
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, RequestError(ctx, "failed to send request", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, errors.InternalWrap("llm gateway embeddings api error", fmt.Errorf("status code: %d, response body: %s", resp.StatusCode, string(respBody)))
+	}
+
+	var result openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.InternalWrap("failed to decode response", err)
+	}
+	if len(result.Data) != len(inputs) {
+		return nil, errors.Internal(fmt.Sprintf("llm gateway returned %d embeddings for %d inputs", len(result.Data), len(inputs)))
+	}
+
+	vectors := make([][]float64, len(inputs))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, errors.Internal("llm gateway returned an embedding with an invalid index")
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
@@ -12,12 +12,15 @@ type PostgresClient struct {
 	Pool *pgxpool.Pool
 }
 
-// NewPostgresClient creates a new PostgreSQL client.
-func NewPostgresClient(ctx context.Context, connectionString string) (*PostgresClient, error) {
+// NewPostgresClient creates a new PostgreSQL client. Queries are timed by tracer when it is set.
+func NewPostgresClient(ctx context.Context, connectionString string, tracer *QueryTracer) (*PostgresClient, error) {
 	config, err := pgxpool.ParseConfig(connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse postgres config: %w", err)
 	}
+	if tracer != nil {
+		config.ConnConfig.Tracer = tracer
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+)
+
+// PromptBudget keeps prompt inputs within a model's context window.
+// A nil *PromptBudget (or MaxTokens <= 0) leaves inputs untouched.
+type PromptBudget struct {
+	maxTokens int
+	log       *slog.Logger
+}
+
+// NewPromptBudget creates a prompt budget of maxTokens input tokens per call.
+func NewPromptBudget(maxTokens int, log *slog.Logger) *PromptBudget {
+	return &PromptBudget{maxTokens: maxTokens, log: log}
+}
+
+// EstimateTokens approximates the token count of text without a tokenizer:
+// about four characters per token for alphabetic scripts and one token per CJK or Thai character.
+func EstimateTokens(text string) int {
+	var dense, other int
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai) {
+			dense++
+		} else {
+			other++
+		}
+	}
+	return dense + (other+3)/4
+}
+
+// Fit returns input trimmed so that it plus the reserved prompt parts (system prompt, instructions)
+// fit the budget. Trimming is deterministic: the head and tail of the input are kept and the middle
+// is replaced with an omission marker. Every truncation is logged under name.
+func (b *PromptBudget) Fit(name, input string, reserved ...string) string {
+	if b == nil || b.maxTokens <= 0 {
+		return input
+	}
+
+	available := b.maxTokens
+	for _, part := range reserved {
+		available -= EstimateTokens(part)
+	}
+	total := EstimateTokens(input)
+	if total <= available {
+		return input
+	}
+
+	kept := truncateMiddle(input, max(available, 0))
+	b.log.Warn("Prompt input truncated to fit token budget",
+		"input", name,
+		"input_tokens", total,
+		"kept_tokens", EstimateTokens(kept),
+		"max_tokens", b.maxTokens,
+	)
+	return kept
+}
+
+// truncateMiddle keeps about two thirds of budget from the start of text and one third from the end,
+// cutting on word boundaries where the script has them.
+func truncateMiddle(text string, budget int) string {
+	marker := "\n[... truncated ...]\n"
+	budget -= EstimateTokens(marker)
+	if budget <= 0 {
+		return ""
+	}
+
+	runes := []rune(text)
+	headBudget := budget * 2 / 3
+	tailBudget := budget - headBudget
+
+	head := 0
+	for used := 0; head < len(runes); head++ {
+		used += runeTokenCost(runes[head])
+		if used > headBudget*4 {
+			break
+		}
+	}
+	tail := len(runes)
+	for used := 0; tail > head; tail-- {
+		used += runeTokenCost(runes[tail-1])
+		if used > tailBudget*4 {
+			break
+		}
+	}
+
+	headText := strings.TrimSpace(string(runes[:head]))
+	if i := strings.LastIndexFunc(headText, unicode.IsSpace); i > len(headText)/2 {
+		headText = headText[:i]
+	}
+	tailText := strings.TrimSpace(string(runes[tail:]))
+	if i := strings.IndexFunc(tailText, unicode.IsSpace); i >= 0 && i < len(tailText)/2 {
+		tailText = strings.TrimSpace(tailText[i:])
+	}
+
+	return fmt.Sprintf("%s%s%s", headText, marker, tailText)
+}
+
+// runeTokenCost is the rune's weight in quarter tokens (see EstimateTokens).
+func runeTokenCost(r rune) int {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai) {
+		return 4
+	}
+	return 1
+}
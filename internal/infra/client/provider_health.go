@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// providerHealthKey is the Redis hash of provider health, one field per provider.
+const providerHealthKey = "provider:health"
+
+// Probed providers
+const (
+	ProviderGemini      = "gemini"
+	ProviderAzureSpeech = "azure_speech"
+	ProviderWhisper     = "whisper"
+	ProviderR2          = "r2"
+)
+
+// Provider health states
+const (
+	ProviderHealthy  = "healthy"
+	ProviderDegraded = "degraded"
+)
+
+// ProviderProbe is a cheap call that fails when the provider cannot serve requests.
+type ProviderProbe func(ctx context.Context) error
+
+// ProviderStatus is the last probe result of a provider.
+type ProviderStatus struct {
+	Provider            string    `json:"provider"`
+	Status              string    `json:"status"`
+	Error               string    `json:"error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	CheckedAt           time.Time `json:"checked_at"`
+}
+
+// ProviderHealthOptions configures probing.
+type ProviderHealthOptions struct {
+	Interval         time.Duration // 0 disables probing, every provider stays healthy
+	Timeout          time.Duration
+	FailureThreshold int           // consecutive failures before a provider is degraded
+	Refresh          time.Duration // how long Redis reads are cached
+}
+
+// ProviderHealthClient probes providers in the background and keeps their status in Redis,
+// so every instance sees the same degradation flags. Reads are cached like maintenance flags.
+type ProviderHealthClient struct {
+	redis *RedisClient
+	opts  ProviderHealthOptions
+	log   *slog.Logger
+
+	probes map[string]ProviderProbe
+
+	mu       sync.RWMutex
+	statuses map[string]ProviderStatus
+	loadedAt time.Time
+}
+
+// NewProviderHealthClient creates a new provider health client.
+func NewProviderHealthClient(redis *RedisClient, opts ProviderHealthOptions, log *slog.Logger) *ProviderHealthClient {
+	return &ProviderHealthClient{
+		redis:    redis,
+		opts:     opts,
+		log:      log,
+		probes:   make(map[string]ProviderProbe),
+		statuses: make(map[string]ProviderStatus),
+	}
+}
+
+// Register adds a provider probe. Register before calling Run.
+func (c *ProviderHealthClient) Register(provider string, probe ProviderProbe) {
+	c.probes[provider] = probe
+}
+
+// Run probes every provider each interval until ctx is cancelled.
+func (c *ProviderHealthClient) Run(ctx context.Context) {
+	if c.opts.Interval <= 0 || len(c.probes) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.probeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *ProviderHealthClient) probeAll(ctx context.Context) {
+	previous := c.load(ctx)
+
+	for provider, probe := range c.probes {
+		probeCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+		err := probe(probeCtx)
+		cancel()
+
+		status := ProviderStatus{Provider: provider, Status: ProviderHealthy, CheckedAt: time.Now().UTC()}
+		if err != nil {
+			status.Error = err.Error()
+			status.ConsecutiveFailures = previous[provider].ConsecutiveFailures + 1
+			if status.ConsecutiveFailures >= c.opts.FailureThreshold {
+				status.Status = ProviderDegraded
+			}
+		}
+
+		switch was := previous[provider].Status; {
+		case status.Status == ProviderDegraded && was != ProviderDegraded:
+			c.log.Error("Provider degraded", "provider", provider, "failures", status.ConsecutiveFailures, "error", status.Error)
+		case status.Status == ProviderHealthy && was == ProviderDegraded:
+			c.log.Info("Provider recovered", "provider", provider)
+		}
+
+		data, mErr := json.Marshal(status)
+		if mErr != nil {
+			continue
+		}
+		if rErr := c.redis.HSet(ctx, providerHealthKey, provider, string(data)); rErr != nil {
+			c.log.Warn("Failed to store provider health", "provider", provider, "error", rErr)
+		}
+	}
+
+	if err := c.reload(ctx); err != nil {
+		c.log.Warn("Failed to refresh provider health", "error", err.Error())
+	}
+}
+
+// Check fast-fails calls to a degraded provider. A nil client or an unknown provider passes.
+func (c *ProviderHealthClient) Check(ctx context.Context, provider string) *errors.AppError {
+	if c == nil {
+		return nil
+	}
+
+	status, ok := c.load(ctx)[provider]
+	if !ok || status.Status != ProviderDegraded {
+		return nil
+	}
+	return errors.ProviderUnavailable(fmt.Sprintf("%s is temporarily unavailable", provider)).WithDetails(map[string]interface{}{
+		"provider":   provider,
+		"checked_at": status.CheckedAt,
+	})
+}
+
+// Degraded returns the degraded providers among the given ones (all providers when none are given).
+func (c *ProviderHealthClient) Degraded(ctx context.Context, providers ...string) []ProviderStatus {
+	var degraded []ProviderStatus
+	for _, status := range c.List(ctx) {
+		if status.Status != ProviderDegraded {
+			continue
+		}
+		if len(providers) == 0 || slices.Contains(providers, status.Provider) {
+			degraded = append(degraded, status)
+		}
+	}
+	return degraded
+}
+
+// List returns the last known status of every provider, sorted by name.
+func (c *ProviderHealthClient) List(ctx context.Context) []ProviderStatus {
+	statuses := c.load(ctx)
+	list := make([]ProviderStatus, 0, len(statuses))
+	for _, status := range statuses {
+		list = append(list, status)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Provider < list[j].Provider })
+	return list
+}
+
+// load returns the cached statuses, refreshing them when stale.
+func (c *ProviderHealthClient) load(ctx context.Context) map[string]ProviderStatus {
+	c.mu.RLock()
+	fresh := time.Since(c.loadedAt) < c.opts.Refresh
+	statuses := c.statuses
+	c.mu.RUnlock()
+	if fresh {
+		return statuses
+	}
+
+	if err := c.reload(ctx); err != nil {
+		c.log.Warn("Failed to refresh provider health", "error", err.Error())
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.statuses
+}
+
+func (c *ProviderHealthClient) reload(ctx context.Context) *errors.AppError {
+	fields, err := c.redis.HGetAll(ctx, providerHealthKey)
+	if err != nil {
+		// Keep the last known statuses, but retry only after the next refresh interval
+		c.mu.Lock()
+		c.loadedAt = time.Now()
+		c.mu.Unlock()
+		return errors.InternalWrap("failed to read provider health", err)
+	}
+
+	statuses := make(map[string]ProviderStatus, len(fields))
+	for provider, raw := range fields {
+		var status ProviderStatus
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			continue
+		}
+		statuses[provider] = status
+	}
+
+	c.mu.Lock()
+	c.statuses = statuses
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// probeHTTP treats server errors and rejected credentials as failures; any other answer means the provider is reachable.
+func probeHTTP(httpClient *http.Client, req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("probe returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryDurationBuckets are the upper bounds (ms) of the query duration histograms; slower queries
+// fall in the last, unbounded bucket.
+var queryDurationBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// maxLoggedSQLLength caps the statement text in slow query logs.
+const maxLoggedSQLLength = 500
+
+// modulePrefix is stripped from caller names ("video.(*videoRepository).ListVideos").
+const modulePrefix = "github.com/windfall/uwu_service/"
+
+// QueryStats is the timing of the queries one caller ran.
+type QueryStats struct {
+	Count   uint64  `json:"count"`
+	Errors  uint64  `json:"errors"`
+	Slow    uint64  `json:"slow"`
+	Rows    int64   `json:"rows"`
+	TotalMs float64 `json:"total_ms"`
+	MaxMs   float64 `json:"max_ms"`
+	// Buckets[i] counts queries up to queryDurationBuckets[i] ms; the last one counts the rest
+	Buckets []uint64 `json:"buckets"`
+}
+
+// QueryTracerStats is what the tracer publishes on /debug/vars.
+type QueryTracerStats struct {
+	BucketsMs []float64              `json:"buckets_ms"`
+	All       QueryStats             `json:"all"`
+	ByCaller  map[string]*QueryStats `json:"by_caller"`
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at     time.Time
+	sql    string
+	caller string
+
+	// Batches only: rows and the first error of their queries so far
+	rows int64
+	err  error
+}
+
+// QueryTracer times every pgx query, keeps a duration histogram per calling repository method
+// and logs queries slower than a threshold.
+type QueryTracer struct {
+	log           *slog.Logger
+	slowThreshold time.Duration
+	mu            sync.Mutex
+	all           QueryStats
+	byCaller      map[string]*QueryStats
+}
+
+// NewQueryTracer creates a query tracer that logs queries taking at least slowThreshold (0 disables the log).
+func NewQueryTracer(slowThreshold time.Duration, log *slog.Logger) *QueryTracer {
+	return &QueryTracer{
+		log:           log,
+		slowThreshold: slowThreshold,
+		all:           QueryStats{Buckets: make([]uint64, len(queryDurationBuckets)+1)},
+		byCaller:      make(map[string]*QueryStats),
+	}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{
+		at:     time.Now(),
+		sql:    data.SQL,
+		caller: queryCaller(),
+	})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer. Query results end here once their rows are closed.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	if start, ok := ctx.Value(queryStartKey{}).(*queryStart); ok {
+		t.finish(start, data.CommandTag.RowsAffected(), data.Err)
+	}
+}
+
+// TraceBatchStart implements pgx.BatchTracer. A batch is timed as one query, from send to close.
+func (t *QueryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{
+		at:     time.Now(),
+		caller: queryCaller(),
+	})
+}
+
+// TraceBatchQuery implements pgx.BatchTracer, collecting the statements of the batch for the slow query log.
+func (t *QueryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	start, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+	start.rows += data.CommandTag.RowsAffected()
+	if start.sql != "" {
+		start.sql += "; "
+	}
+	start.sql += data.SQL
+	if data.Err != nil && start.err == nil {
+		start.err = data.Err
+	}
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *QueryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+	err := data.Err
+	if err == nil {
+		err = start.err
+	}
+	t.finish(start, start.rows, err)
+}
+
+func (t *QueryTracer) finish(start *queryStart, rows int64, err error) {
+	elapsed := time.Since(start.at)
+	slow := t.slowThreshold > 0 && elapsed >= t.slowThreshold
+
+	t.mu.Lock()
+	stats, ok := t.byCaller[start.caller]
+	if !ok {
+		stats = &QueryStats{Buckets: make([]uint64, len(queryDurationBuckets)+1)}
+		t.byCaller[start.caller] = stats
+	}
+	stats.record(elapsed, rows, err != nil, slow)
+	t.all.record(elapsed, rows, err != nil, slow)
+	t.mu.Unlock()
+
+	if slow {
+		t.log.Warn("Slow query",
+			"caller", start.caller,
+			"duration_ms", elapsed.Milliseconds(),
+			"rows", rows,
+			"error", err,
+			"sql", compactSQL(start.sql),
+		)
+	}
+}
+
+// Stats returns a copy of the timings collected so far.
+func (t *QueryTracer) Stats() QueryTracerStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := QueryTracerStats{
+		BucketsMs: queryDurationBuckets,
+		All:       t.all.clone(),
+		ByCaller:  make(map[string]*QueryStats, len(t.byCaller)),
+	}
+	for caller, s := range t.byCaller {
+		c := s.clone()
+		stats.ByCaller[caller] = &c
+	}
+	return stats
+}
+
+func (s *QueryStats) record(elapsed time.Duration, rows int64, failed, slow bool) {
+	ms := float64(elapsed.Microseconds()) / 1000
+
+	s.Count++
+	s.Rows += rows
+	s.TotalMs += ms
+	s.MaxMs = max(s.MaxMs, ms)
+	if failed {
+		s.Errors++
+	}
+	if slow {
+		s.Slow++
+	}
+	s.Buckets[sort.SearchFloat64s(queryDurationBuckets, ms)]++
+}
+
+func (s *QueryStats) clone() QueryStats {
+	c := *s
+	c.Buckets = append([]uint64(nil), s.Buckets...)
+	return c
+}
+
+// queryCaller names the first function of this module up the stack, usually the repository method.
+func queryCaller() string {
+	// Skip runtime.Callers, queryCaller and TraceQueryStart (or TraceBatchStart)
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, modulePrefix); ok {
+			name = strings.TrimPrefix(name, "internal/domain/")
+			return strings.TrimPrefix(name, "internal/infra/")
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// compactSQL collapses whitespace so multi-line statements log on one line.
+func compactSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLoggedSQLLength {
+		sql = sql[:maxLoggedSQLLength] + "..."
+	}
+	return sql
+}
@@ -2,9 +2,13 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/windfall/uwu_service/pkg/errors"
 )
@@ -13,6 +17,10 @@ import (
 type Job struct {
 	Type    string      // ชื่อประเภทงาน เช่น "process_upload_video"
 	Payload interface{} // ข้อมูลที่ต้องการส่ง (ใช้ any หรือ interface{})
+	BatchID string      // Batch ที่งานนี้ทำให้ (ถ้ามี) ใช้แสดงใน Job Registry
+
+	OrganizationID string // องค์กรของผู้สั่งงาน (ถ้ามี) เพื่อให้เรียก AI ด้วย Key ขององค์กร
+	ChatProvider   string // Chat Provider ที่ Request เลือกไว้ (ถ้ามี) แทน Chain ที่ตั้งค่าไว้
 }
 
 // WorkerFunc คือหน้าตาของฟังก์ชันที่แต่ละ Domain ต้องเขียนมารับงาน
@@ -24,15 +32,35 @@ type QueueClient struct {
 	jobsChan chan Job
 	workers  map[string]WorkerFunc // เก็บว่างาน Type ไหน ต้องเรียกฟังก์ชันอะไร
 	wg       sync.WaitGroup
+	running  atomic.Int32 // จำนวน Worker ที่ทำงานอยู่
+	registry *JobRegistry // ติดตามงานที่กำลังรันอยู่ (nil = ไม่ติดตาม)
+
+	// งานแบบ Durable เก็บลง Postgres แทน Channel จึงไม่หายตอน Restart (nil store = เก็บใน Memory เหมือนงานอื่น)
+	store        *JobStore
+	durable      map[string]reflect.Type // Type ของ Payload ที่ใช้ Decode กลับจาก JSON
+	wake         chan struct{}           // ปลุก Worker ทันทีที่มีงาน Durable ใหม่
+	pollInterval time.Duration           // รอบที่ Worker ว่างจะกลับไปดูงานใน Store
 }
 
 // NewQueueClient สร้างคิวใหม่ตามขนาด Buffer ที่ต้องการ
-func NewQueueClient(log *slog.Logger, bufferSize int) *QueueClient {
+func NewQueueClient(log *slog.Logger, bufferSize int, registry *JobRegistry) *QueueClient {
 	return &QueueClient{
 		log:      log,
 		jobsChan: make(chan Job, bufferSize),
 		workers:  make(map[string]WorkerFunc),
+		registry: registry,
+		durable:  make(map[string]reflect.Type),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// SetStore ให้งานแบบ Durable เก็บลง Postgres (ต้องเรียกก่อน Start)
+func (c *QueueClient) SetStore(store *JobStore, pollInterval time.Duration) {
+	if pollInterval <= 0 {
+		pollInterval = 2 * time.Second
 	}
+	c.store = store
+	c.pollInterval = pollInterval
 }
 
 // RegisterWorker ให้แต่ละ Domain นำ Worker ของตัวเองมาลงทะเบียน
@@ -41,8 +69,27 @@ func (c *QueueClient) RegisterWorker(jobType string, fn WorkerFunc) {
 	c.workers[jobType] = fn
 }
 
+// RegisterDurableWorker ลงทะเบียน Worker ของงานที่ต้องรอดจากการ Restart
+// payload คือตัวอย่าง Payload (เช่น GenerateDialogPayload{}) ต้องแปลงเป็น JSON ได้
+// งานจะถูก Retry เมื่อ Worker คืน Error และย้ายไป Dead Letter เมื่อครบจำนวนครั้ง
+func (c *QueueClient) RegisterDurableWorker(jobType string, payload interface{}, fn WorkerFunc) {
+	c.workers[jobType] = fn
+	c.durable[jobType] = reflect.TypeOf(payload)
+}
+
 // Enqueue โยนงานเข้า Queue (เรียกจาก Handler)
 func (c *QueueClient) Enqueue(job Job) *errors.AppError {
+	if c.store != nil {
+		if _, ok := c.durable[job.Type]; ok {
+			err := c.enqueueDurable(job)
+			if err == nil {
+				return nil
+			}
+			// Store ใช้ไม่ได้ ยังรันงานใน Memory ได้ แค่ไม่รอดจากการ Restart
+			c.log.Warn("Failed to store durable job, falling back to memory", "job_type", job.Type, "error", err)
+		}
+	}
+
 	select {
 	case c.jobsChan <- job:
 		return nil
@@ -52,6 +99,45 @@ func (c *QueueClient) Enqueue(job Job) *errors.AppError {
 	}
 }
 
+// enqueueDurable เก็บงานลง Store แล้วปลุก Worker ที่ว่างอยู่
+func (c *QueueClient) enqueueDurable(job Job) error {
+	payload, err := json.Marshal(job.Payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.store.Insert(ctx, job, payload); err != nil {
+		return err
+	}
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// EnqueueEvery โยนงานเข้า Queue ทุกๆ interval จนกว่า ctx จะถูกยกเลิก (ใช้กับงานตามรอบเวลา)
+func (c *QueueClient) EnqueueEvery(ctx context.Context, interval time.Duration, job Job) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Enqueue(job); err != nil {
+					c.log.Warn("Failed to enqueue scheduled job", "job_type", job.Type, "error", err.Error())
+				}
+			}
+		}
+	}()
+}
+
 // Start เริ่มเปิดรับงานด้วยจำนวน Goroutine (Workers) ตามที่ระบุ
 func (c *QueueClient) Start(ctx context.Context, numWorkers int) {
 	for i := range numWorkers {
@@ -60,43 +146,190 @@ func (c *QueueClient) Start(ctx context.Context, numWorkers int) {
 	}
 }
 
-// process คือลูปที่ Goroutine จะดึงงานไปทำ
+// process คือลูปที่ Goroutine จะดึงงานไปทำ (งานใน Store ก่อน แล้วค่อยรองานใน Channel)
 func (c *QueueClient) process(ctx context.Context, workerID int) {
 	defer c.wg.Done()
+	c.running.Add(1)
+	defer c.running.Add(-1)
+
+	var poll <-chan time.Time
+	if c.store != nil && len(c.durable) > 0 {
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
 
 	for {
+		if poll != nil && c.claimDurable(ctx, workerID) {
+			continue
+		}
+
 		select {
 		case <-ctx.Done(): // รอรับสัญญาณ Shutdown
 			c.log.Info("Worker shutting down", "worker_id", workerID)
 			return
 		case job := <-c.jobsChan:
-			// ดึงงานมาหาว่าต้องเรียกฟังก์ชันไหน
-			fn, exists := c.workers[job.Type]
-			if !exists {
-				c.log.Warn("No worker registered",
-					"worker_id", workerID,
-					"job_type", job.Type,
-				)
-				continue
-			}
+			c.run(ctx, workerID, job)
+		case <-c.wake:
+		case <-poll:
+		}
+	}
+}
+
+// run เรียกฟังก์ชันของ Domain ที่รับงาน Type นั้น
+func (c *QueueClient) run(ctx context.Context, workerID int, job Job) error {
+	// ดึงงานมาหาว่าต้องเรียกฟังก์ชันไหน
+	fn, exists := c.workers[job.Type]
+	if !exists {
+		c.log.Warn("No worker registered",
+			"worker_id", workerID,
+			"job_type", job.Type,
+		)
+		return fmt.Errorf("no worker registered for %s", job.Type)
+	}
+
+	// สั่งรันฟังก์ชันของ Domain นั้นๆ (ลงทะเบียนไว้ใน Registry ระหว่างรัน)
+	jobCtx, done := c.registry.Start(WithChatProvider(WithOrganization(ctx, job.OrganizationID), job.ChatProvider), job.Type, job.BatchID)
+	err := fn(jobCtx, job)
+	done()
+	if err != nil {
+		c.log.Error("Failed to process job",
+			"worker_id", workerID,
+			"job_type", job.Type,
+			"error", err,
+		)
+	} else {
+		c.log.Info("Successfully processed job",
+			"worker_id", workerID,
+			"job_type", job.Type,
+		)
+	}
+	return err
+}
+
+// claimDurable ดึงงานหนึ่งงานจาก Store มาทำ คืน true ถ้ามีงานให้ทำ
+func (c *QueueClient) claimDurable(ctx context.Context, workerID int) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	types := make([]string, 0, len(c.durable))
+	for jobType := range c.durable {
+		types = append(types, jobType)
+	}
+	stored, err := c.store.Claim(ctx, types)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.log.Warn("Failed to claim durable job", "worker_id", workerID, "error", err)
+		}
+		return false
+	}
+	if stored == nil {
+		return false
+	}
+
+	// การอัปเดต Store หลังงานจบต้องทำได้แม้ ctx ถูกยกเลิกแล้ว (ตอน Shutdown)
+	storeCtx := context.WithoutCancel(ctx)
 
-			// สั่งรันฟังก์ชันของ Domain นั้นๆ
-			if err := fn(ctx, job); err != nil {
-				c.log.Error("Failed to process job",
-					"worker_id", workerID,
-					"job_type", job.Type,
-					"error", err,
-				)
-			} else {
-				c.log.Info("Successfully processed job",
-					"worker_id", workerID,
-					"job_type", job.Type,
-				)
+	payload := reflect.New(c.durable[stored.Type])
+	if err := json.Unmarshal(stored.Payload, payload.Interface()); err != nil {
+		c.failDurable(storeCtx, stored, fmt.Errorf("decode payload: %w", err))
+		return true
+	}
+	job := Job{
+		Type:           stored.Type,
+		Payload:        payload.Elem().Interface(),
+		BatchID:        stored.BatchID,
+		OrganizationID: stored.OrganizationID,
+		ChatProvider:   stored.ChatProvider,
+	}
+
+	// ต่ออายุ Lease ระหว่างรัน ถ้า Process ตาย Lease จะหมดแล้ว Worker ตัวอื่นจะรับงานต่อ
+	heartbeatCtx, stopHeartbeat := context.WithCancel(storeCtx)
+	go c.heartbeat(heartbeatCtx, stored.ID)
+	err = c.run(ctx, workerID, job)
+	stopHeartbeat()
+
+	switch {
+	case ctx.Err() != nil:
+		// Shutdown ระหว่างรัน คืนงานให้ Worker ตัวถัดไปทำใหม่โดยไม่นับครั้ง
+		if err := c.store.Release(storeCtx, stored.ID); err != nil {
+			c.log.Warn("Failed to release durable job", "job_id", stored.ID, "error", err)
+		}
+	case err != nil:
+		c.failDurable(storeCtx, stored, err)
+	default:
+		if err := c.store.Complete(storeCtx, stored.ID); err != nil {
+			c.log.Warn("Failed to complete durable job", "job_id", stored.ID, "error", err)
+		}
+	}
+	return true
+}
+
+// failDurable นัด Retry หรือย้ายงานไป Dead Letter
+func (c *QueueClient) failDurable(ctx context.Context, stored *StoredJob, cause error) {
+	dead, err := c.store.Fail(ctx, stored, cause)
+	if err != nil {
+		c.log.Warn("Failed to record durable job failure", "job_id", stored.ID, "error", err)
+		return
+	}
+	if dead {
+		c.log.Error("Durable job moved to dead letter",
+			"job_id", stored.ID,
+			"job_type", stored.Type,
+			"attempts", stored.Attempts,
+			"error", cause,
+		)
+	}
+}
+
+// heartbeat ต่ออายุ Lease ทุก 1/3 ของ Lease จนกว่างานจะจบ
+func (c *QueueClient) heartbeat(ctx context.Context, id int64) {
+	ticker := time.NewTicker(c.store.opts.Lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.store.Heartbeat(ctx, id); err != nil && ctx.Err() == nil {
+				c.log.Warn("Failed to renew durable job lease", "job_id", id, "error", err)
 			}
 		}
 	}
 }
 
+// QueueStats คือสถานะของ Queue ณ ตอนนี้ (ใช้กับ /debug/vars)
+type QueueStats struct {
+	Pending  int            `json:"pending"`
+	Capacity int            `json:"capacity"`
+	Workers  int            `json:"workers"`
+	Durable  *JobStoreStats `json:"durable,omitempty"` // งานใน Store (nil ถ้าไม่ได้ใช้ Store)
+}
+
+// Stats คืนจำนวนงานที่รออยู่ใน Buffer และใน Store
+func (c *QueueClient) Stats() QueueStats {
+	stats := QueueStats{
+		Pending:  len(c.jobsChan),
+		Capacity: cap(c.jobsChan),
+		Workers:  int(c.running.Load()),
+	}
+	if c.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if durable, err := c.store.Stats(ctx); err == nil {
+			stats.Durable = &durable
+		}
+	}
+	return stats
+}
+
+// Store คืน Store ของงาน Durable (nil ถ้าไม่ได้ใช้)
+func (c *QueueClient) Store() *JobStore {
+	return c.store
+}
+
 // Stop รอจนกว่า Worker ทุกตัวจะทำงานที่ค้างอยู่ให้เสร็จ (Graceful Shutdown)
 func (c *QueueClient) Stop() {
 	c.wg.Wait()
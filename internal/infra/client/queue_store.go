@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Durable job statuses
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDead    = "dead"
+)
+
+// JobStoreOptions controls leases and retries of durable jobs.
+type JobStoreOptions struct {
+	// Lease is how long a claimed job belongs to its worker without a heartbeat; a job whose
+	// process died is claimed again once it expires.
+	Lease time.Duration
+	// MaxAttempts is how many times a job runs (lost leases included) before it is dead-lettered.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled on each further attempt.
+	RetryBackoff time.Duration
+}
+
+// StoredJob is a durable job as kept in queue_jobs.
+type StoredJob struct {
+	ID             int64           `json:"id"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	BatchID        string          `json:"batch_id,omitempty"`
+	OrganizationID string          `json:"organization_id,omitempty"`
+	ChatProvider   string          `json:"chat_provider,omitempty"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// JobStoreStats counts durable jobs by status.
+type JobStoreStats struct {
+	Pending int `json:"pending"`
+	Running int `json:"running"`
+	Dead    int `json:"dead"`
+}
+
+// JobStore keeps durable queue jobs in Postgres, so jobs survive a restart and can be run by
+// any instance. Workers claim jobs with SKIP LOCKED and hold them with a renewed lease.
+type JobStore struct {
+	db    *PostgresClient
+	owner string // identifies this process in locked_by (host/pid plus a random suffix, pids repeat across container restarts)
+	opts  JobStoreOptions
+}
+
+// NewJobStore creates a job store; db must have the queue_jobs table.
+func NewJobStore(db *PostgresClient, opts JobStoreOptions) *JobStore {
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 30 * time.Second
+	}
+
+	host, _ := os.Hostname()
+	return &JobStore{
+		db:    db,
+		owner: fmt.Sprintf("%s/%d/%s", host, os.Getpid(), uuid.NewString()[:8]),
+		opts:  opts,
+	}
+}
+
+// Insert stores a new pending job.
+func (s *JobStore) Insert(ctx context.Context, job Job, payload []byte) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO queue_jobs (job_type, payload, batch_id, organization_id, chat_provider)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5)
+	`, job.Type, payload, job.BatchID, job.OrganizationID, job.ChatProvider)
+	return err
+}
+
+// Claim takes the oldest runnable job of the given types: pending and due, or running with an
+// expired lease. Expired jobs that already used all their attempts (their worker crashed every
+// time) are dead-lettered instead. It returns nil when there is none.
+func (s *JobStore) Claim(ctx context.Context, jobTypes []string) (*StoredJob, error) {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE queue_jobs
+		SET status = 'dead', last_error = 'lease expired on the last attempt (worker lost)',
+			locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE job_type = ANY($1) AND status = 'running' AND locked_until < NOW() AND attempts >= $2
+	`, jobTypes, s.opts.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	var job StoredJob
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE queue_jobs
+		SET status = 'running', attempts = attempts + 1, locked_by = $2,
+			locked_until = NOW() + make_interval(secs => $3), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM queue_jobs
+			WHERE job_type = ANY($1)
+				AND ((status = 'pending' AND run_at <= NOW()) OR (status = 'running' AND locked_until < NOW() AND attempts < $4))
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, job_type, payload, batch_id, COALESCE(organization_id::text, ''), chat_provider, status, attempts,
+			COALESCE(last_error, ''), created_at, updated_at
+	`, jobTypes, s.owner, s.opts.Lease.Seconds(), s.opts.MaxAttempts).Scan(
+		&job.ID, &job.Type, &job.Payload, &job.BatchID, &job.OrganizationID, &job.ChatProvider, &job.Status, &job.Attempts,
+		&job.LastError, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Heartbeat renews the lease of a running job.
+func (s *JobStore) Heartbeat(ctx context.Context, id int64) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE queue_jobs SET locked_until = NOW() + make_interval(secs => $3)
+		WHERE id = $1 AND status = 'running' AND locked_by = $2
+	`, id, s.owner, s.opts.Lease.Seconds())
+	return err
+}
+
+// Complete removes a job that finished.
+func (s *JobStore) Complete(ctx context.Context, id int64) error {
+	_, err := s.db.Pool.Exec(ctx, `DELETE FROM queue_jobs WHERE id = $1 AND locked_by = $2`, id, s.owner)
+	return err
+}
+
+// Fail schedules a retry with exponential backoff, or dead-letters the job once it has used
+// all its attempts. It reports whether the job is dead.
+func (s *JobStore) Fail(ctx context.Context, job *StoredJob, cause error) (bool, error) {
+	if job.Attempts >= s.opts.MaxAttempts {
+		_, err := s.db.Pool.Exec(ctx, `
+			UPDATE queue_jobs SET status = 'dead', last_error = $3, locked_by = NULL, locked_until = NULL, updated_at = NOW()
+			WHERE id = $1 AND locked_by = $2
+		`, job.ID, s.owner, cause.Error())
+		return true, err
+	}
+
+	backoff := s.opts.RetryBackoff << (job.Attempts - 1)
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE queue_jobs SET status = 'pending', last_error = $3, run_at = NOW() + make_interval(secs => $4),
+			locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2
+	`, job.ID, s.owner, cause.Error(), backoff.Seconds())
+	return false, err
+}
+
+// Release hands a job back without counting the attempt (used on shutdown).
+func (s *JobStore) Release(ctx context.Context, id int64) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE queue_jobs SET status = 'pending', attempts = GREATEST(attempts - 1, 0),
+			locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2
+	`, id, s.owner)
+	return err
+}
+
+// ListDead returns dead-lettered jobs, most recent first.
+func (s *JobStore) ListDead(ctx context.Context, limit int) ([]*StoredJob, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, job_type, payload, batch_id, COALESCE(organization_id::text, ''), chat_provider, status, attempts,
+			COALESCE(last_error, ''), created_at, updated_at
+		FROM queue_jobs WHERE status = 'dead'
+		ORDER BY updated_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*StoredJob, 0)
+	for rows.Next() {
+		var job StoredJob
+		if err := rows.Scan(&job.ID, &job.Type, &job.Payload, &job.BatchID, &job.OrganizationID, &job.ChatProvider, &job.Status, &job.Attempts,
+			&job.LastError, &job.CreatedAt, &job.UpdatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+// Retry puts a dead job back in the queue with fresh attempts. It reports whether the job was dead.
+func (s *JobStore) Retry(ctx context.Context, id int64) (bool, error) {
+	tag, err := s.db.Pool.Exec(ctx, `
+		UPDATE queue_jobs SET status = 'pending', attempts = 0, run_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Stats counts durable jobs by status.
+func (s *JobStore) Stats(ctx context.Context) (JobStoreStats, error) {
+	var stats JobStoreStats
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'running'),
+			COUNT(*) FILTER (WHERE status = 'dead')
+		FROM queue_jobs
+	`).Scan(&stats.Pending, &stats.Running, &stats.Dead)
+	return stats, err
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// RateLimiterStats is a snapshot of a limiter's queueing metrics.
+type RateLimiterStats struct {
+	Provider   string        `json:"provider"`
+	Waiting    int64         `json:"waiting"`     // calls currently queued for a token
+	TotalCalls int64         `json:"total_calls"` // calls that passed the limiter
+	TotalWaits int64         `json:"total_waits"` // calls that had to queue
+	TotalWait  time.Duration `json:"total_wait"`  // accumulated queueing time
+}
+
+// RateLimiter is a token-bucket limiter for calls to a single provider.
+// A nil *RateLimiter lets every call through.
+type RateLimiter struct {
+	provider string
+	rate     float64 // tokens per second
+	burst    float64
+	log      *slog.Logger
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	waiting    atomic.Int64
+	totalCalls atomic.Int64
+	totalWaits atomic.Int64
+	totalWait  atomic.Int64
+}
+
+// NewRateLimiter creates a limiter allowing qps calls per second with bursts up to burst.
+// It returns nil (no limit) when qps <= 0.
+func NewRateLimiter(provider string, qps float64, burst int, log *slog.Logger) *RateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		provider: provider,
+		rate:     qps,
+		burst:    float64(burst),
+		log:      log,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) *errors.AppError {
+	if l == nil {
+		return nil
+	}
+
+	// Reserve a token (may go negative) and compute how long to wait for it
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	l.totalCalls.Add(1)
+	if delay <= 0 {
+		return nil
+	}
+
+	queued := l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+	l.totalWaits.Add(1)
+	l.totalWait.Add(int64(delay))
+	l.log.Debug("Rate limiter queued call", "provider", l.provider, "delay", delay.String(), "queued", queued)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return errors.Wrap(errors.ErrTimeout, "rate limiter wait cancelled", ctx.Err())
+	}
+}
+
+// Stats returns the limiter's queueing metrics.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	if l == nil {
+		return RateLimiterStats{}
+	}
+	return RateLimiterStats{
+		Provider:   l.provider,
+		Waiting:    l.waiting.Load(),
+		TotalCalls: l.totalCalls.Load(),
+		TotalWaits: l.totalWaits.Load(),
+		TotalWait:  time.Duration(l.totalWait.Load()),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -16,7 +17,7 @@ type RedisClient struct {
 
 // NewRedisClient creates a new Redis client from URL.
 // URL format: redis://[:password@]host:port/db
-func NewRedisClient(url string) (*RedisClient, error) {
+func NewRedisClient(url string, faults *FaultInjector) (*RedisClient, error) {
 	opts, err := redis.ParseURL(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse redis url: %w", err)
@@ -32,6 +33,11 @@ func NewRedisClient(url string) (*RedisClient, error) {
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
+	// Faults start after the connection check so the server can still boot
+	if hook := faults.RedisHook(); hook != nil {
+		client.AddHook(hook)
+	}
+
 	return &RedisClient{client: client}, nil
 }
 
@@ -93,6 +99,110 @@ func (r *RedisClient) HGetAll(ctx context.Context, key string) (map[string]strin
 	return r.client.HGetAll(ctx, key).Result()
 }
 
+// HGetAllMany returns the fields of several Redis Hashes in one round trip, in the order of keys.
+// Missing hashes are empty.
+func (r *RedisClient) HGetAllMany(ctx context.Context, keys ...string) ([]map[string]string, error) {
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HGetAll(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]map[string]string, len(keys))
+	for i, cmd := range cmds {
+		values[i] = cmd.Val()
+	}
+	return values, nil
+}
+
+// HDel removes fields from a Redis Hash.
+func (r *RedisClient) HDel(ctx context.Context, key string, fields ...string) error {
+	return r.client.HDel(ctx, key, fields...).Err()
+}
+
+// IncrByFloat increments a float counter and returns the new value.
+func (r *RedisClient) IncrByFloat(ctx context.Context, key string, value float64) (float64, error) {
+	return r.client.IncrByFloat(ctx, key, value).Result()
+}
+
+// Incr increments an integer counter and returns the new value.
+func (r *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// IncrWithExpiry increments an integer counter and, in the same transaction, gives it a TTL unless
+// it already has one, so a counter never outlives ttl from its first increment. Needs Redis 7.
+func (r *RedisClient) IncrWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// GetFloat returns a float counter, or 0 when the key does not exist.
+func (r *RedisClient) GetFloat(ctx context.Context, key string) (float64, error) {
+	value, err := r.client.Get(ctx, key).Float64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, err
+}
+
+// LRange returns the list elements between start and stop (inclusive, negative counts from the end).
+func (r *RedisClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.client.LRange(ctx, key, start, stop).Result()
+}
+
+// LLen returns the length of a list (0 when it does not exist).
+func (r *RedisClient) LLen(ctx context.Context, key string) (int64, error) {
+	return r.client.LLen(ctx, key).Result()
+}
+
+// LTrim keeps only the list elements between start and stop.
+func (r *RedisClient) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return r.client.LTrim(ctx, key, start, stop).Err()
+}
+
+// unlockScript deletes a lock only while it still holds the caller's token.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// TryLock takes a lock that expires after ttl, unless someone else holds it. The returned token
+// releases it with Unlock.
+func (r *RedisClient) TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token := uuid.NewString()
+	ok, err := r.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// Unlock releases a lock taken with TryLock; a lock that expired and was taken by someone else is left alone.
+func (r *RedisClient) Unlock(ctx context.Context, key, token string) error {
+	return unlockScript.Run(ctx, r.client, []string{key}, token).Err()
+}
+
+// Del deletes keys.
+func (r *RedisClient) Del(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
+}
+
 // Ping checks Redis connectivity.
 func (r *RedisClient) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
@@ -0,0 +1,976 @@
+package client
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Romanization languages
+const (
+	RomanizeChinese  = "zh"
+	RomanizeJapanese = "ja"
+	RomanizeKorean   = "ko"
+)
+
+// Romanization is the deterministic reading of a text: pinyin for Chinese, Hepburn romaji
+// for Japanese kana and Revised Romanization for Korean.
+type Romanization struct {
+	Language string `json:"language"`
+	Text     string `json:"text"`
+	Standard string `json:"standard"`
+	// False when some characters have no deterministic reading (kanji, rare or polyphonic hanzi)
+	Complete   bool     `json:"complete"`
+	Unresolved []string `json:"unresolved,omitempty"`
+}
+
+// Romanizer romanizes texts and keeps the most recent results in memory.
+type Romanizer struct {
+	mu         sync.Mutex
+	cache      map[string]Romanization
+	maxEntries int
+}
+
+// NewRomanizer creates a romanizer caching up to maxEntries results (0 disables the cache).
+func NewRomanizer(maxEntries int) *Romanizer {
+	return &Romanizer{cache: make(map[string]Romanization), maxEntries: maxEntries}
+}
+
+// RomanizationLanguage maps a language name or code to zh, ja or ko, or "" when it has no romanization.
+func RomanizationLanguage(language string) string {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "zh", "zh-cn", "chinese", "mandarin":
+		return RomanizeChinese
+	case "ja", "ja-jp", "japanese":
+		return RomanizeJapanese
+	case "ko", "ko-kr", "korean":
+		return RomanizeKorean
+	}
+	return ""
+}
+
+// Romanize returns the reading of text, or false when the language has no romanization.
+func (r *Romanizer) Romanize(language, text string) (Romanization, bool) {
+	lang := RomanizationLanguage(language)
+	if lang == "" {
+		return Romanization{}, false
+	}
+
+	key := lang + "\x00" + text
+	r.mu.Lock()
+	cached, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok {
+		return cached, true
+	}
+
+	result := Romanization{Language: lang, Text: text}
+	var unresolved []rune
+	switch lang {
+	case RomanizeChinese:
+		result.Standard, unresolved = romanizeChinese(text)
+	case RomanizeJapanese:
+		result.Standard, unresolved = romanizeJapanese(text)
+	case RomanizeKorean:
+		result.Standard = romanizeKorean(text)
+	}
+	result.Complete = len(unresolved) == 0
+	seen := make(map[rune]bool)
+	for _, c := range unresolved {
+		if !seen[c] {
+			seen[c] = true
+			result.Unresolved = append(result.Unresolved, string(c))
+		}
+	}
+
+	if r.maxEntries > 0 {
+		r.mu.Lock()
+		// A full cache starts over; readings are cheap to recompute
+		if len(r.cache) >= r.maxEntries {
+			clear(r.cache)
+		}
+		r.cache[key] = result
+		r.mu.Unlock()
+	}
+	return result, true
+}
+
+// SameReading reports whether two readings spell the same syllables, ignoring case, spacing,
+// apostrophes, hyphens and tone marks. Tones are left to the AI because neutral tones and
+// tone sandhi depend on the word.
+func SameReading(a, b string) bool {
+	return foldReading(a) == foldReading(b)
+}
+
+func foldReading(s string) string {
+	var b strings.Builder
+	for _, c := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, c) || unicode.IsSpace(c) || c == '\'' || c == '-' || c == '’' {
+			continue
+		}
+		b.WriteRune(c)
+	}
+	// ü is written v or u in loose pinyin
+	return strings.NewReplacer("v", "u").Replace(b.String())
+}
+
+// cjkPunctuation maps full-width punctuation to ASCII.
+var cjkPunctuation = map[rune]string{
+	'。': ".", '、': ",", '，': ",", '！': "!", '？': "?", '：': ":", '；': ";",
+	'「': "\"", '」': "\"", '『': "\"", '』': "\"", '（': "(", '）': ")", '　': " ", '・': " ", '…': "...",
+}
+
+// -------------------------------------------------------------------------
+// Chinese (Hanyu Pinyin)
+// -------------------------------------------------------------------------
+
+// pinyinTable lists common characters with a single reading. Polyphonic characters
+// (行, 长, 了, 得 ...) are left out on purpose; they stay unresolved.
+const pinyinTable = `
+ài 爱碍
+ān 安
+àn 按案暗岸
+bā 八巴
+bǎ 把
+bà 爸
+ba 吧
+bái 白
+bǎi 百摆
+bān 班般搬
+bǎn 板版
+bàn 办半伴
+bāng 帮
+bàng 棒
+bāo 包
+bǎo 保饱宝
+bào 报抱暴
+bēi 杯悲
+běi 北
+bèi 被倍备
+běn 本
+bǐ 笔比彼
+bì 必毕闭
+biān 边编
+biàn 变遍
+biǎo 表
+bié 别
+bīn 宾
+bìng 病并
+bō 播波
+bó 博
+bù 部步布
+cā 擦
+cái 才材财
+cài 菜
+cān 餐
+cǎo 草
+cè 册厕测
+céng 层
+chá 茶查
+chǎn 产
+cháng 常尝
+chǎng 场厂
+chàng 唱
+chāo 超抄
+chē 车
+chén 晨
+chéng 城成程乘
+chī 吃
+chí 迟持池
+chū 出初
+chú 除厨
+chǔ 楚础
+chuān 穿川
+chuán 船
+chuāng 窗
+chuáng 床
+chūn 春
+cí 词辞
+cì 次
+cóng 从
+cù 醋
+cuò 错
+dá 答
+dǎ 打
+dài 带代袋戴
+dān 单
+dàn 但蛋淡
+dāo 刀
+dǎo 导岛
+dào 到道
+dēng 灯登
+děng 等
+dī 低
+dǐ 底
+dì 第弟递
+diǎn 点典
+diàn 电店
+diào 掉
+dìng 定订
+dōng 东冬
+dǒng 懂
+dòng 动
+dú 读独
+duǎn 短
+duàn 段断
+duì 对队
+duō 多
+è 饿
+ér 儿而
+ěr 耳
+èr 二
+fǎ 法
+fàn 饭
+fāng 方
+fáng 房防
+fǎng 访
+fàng 放
+fēi 飞非
+fèi 费
+fèn 份
+fēng 风
+fú 服福
+fù 父附复付负
+gāi 该
+gǎi 改
+gài 概
+gǎn 感敢赶
+gāng 刚
+gāo 高
+gào 告
+gē 哥歌
+gè 个各
+gēn 跟根
+gōng 工公功
+gòng 共
+gǒu 狗
+gòu 够购
+gù 故顾
+guā 瓜
+guà 挂
+guān 关
+guǎn 馆管
+guàn 惯
+guǎng 广
+guì 贵
+guó 国
+guǒ 果
+guò 过
+hái 孩
+hǎi 海
+hán 寒
+hàn 汉
+hē 喝
+hé 河合何
+hēi 黑
+hěn 很
+hóng 红
+hòu 后候
+hú 湖
+hù 护户
+huā 花
+huà 话画化
+huài 坏
+huān 欢
+huán 环
+huàn 换
+huáng 黄
+huí 回
+huǒ 火
+huò 或货
+jī 机鸡基积
+jí 级急极及即集
+jǐ 己
+jì 记计季技寄际继
+jiā 家加
+jià 价
+jiān 坚
+jiǎn 简检减
+jiàn 见件建健
+jiǎng 讲奖
+jiāo 交
+jiào 叫
+jiē 街接
+jié 节
+jiě 姐
+jiè 介界借
+jīn 今金
+jǐn 紧
+jìn 进近
+jīng 京经精
+jǐng 景警
+jìng 静境镜
+jiǔ 九久酒
+jiù 就旧救
+jú 局
+jǔ 举
+jù 句具剧
+jué 决
+kāi 开
+kǎo 考
+kě 可渴
+kè 课客刻克
+kěn 肯
+kǒu 口
+kū 哭
+kǔ 苦
+kù 裤
+kuài 快块
+kùn 困
+lā 拉
+lái 来
+lán 蓝
+lǎn 懒
+lǎo 老
+lèi 类
+lěng 冷
+lí 离
+lǐ 里理礼李
+lì 力历立利例
+lián 连
+liǎn 脸
+liàn 练
+liáng 凉
+liǎng 两
+liàng 亮辆
+lín 林邻
+líng 零
+lǐng 领
+lìng 另
+liú 流留
+liù 六
+lóu 楼
+lù 路
+lǜ 绿
+lǚ 旅
+luàn 乱
+mā 妈
+má 麻
+mǎ 马
+mà 骂
+ma 吗
+mǎi 买
+mài 卖
+màn 慢
+máng 忙
+māo 猫
+máo 毛
+mào 帽
+me 么
+méi 没
+měi 美每
+mèi 妹
+mén 门
+men 们
+mǐ 米
+miàn 面
+mín 民
+míng 名明
+mǔ 母
+mù 木目
+ná 拿
+nǎ 哪
+nà 那
+nǎi 奶
+nán 男南
+nǎo 脑
+ne 呢
+néng 能
+nǐ 你
+nián 年
+niàn 念
+niǎo 鸟
+nín 您
+niú 牛
+nóng 农
+nǚ 女
+nuǎn 暖
+ōu 欧
+pá 爬
+pà 怕
+pái 排
+pàng 胖
+pǎo 跑
+péng 朋
+pí 皮
+piān 篇
+piàn 片
+piào 票
+píng 平苹瓶
+pǔ 普
+qī 七期妻
+qí 其骑齐
+qǐ 起
+qì 气汽器
+qiān 千签
+qián 前钱
+qiáng 墙
+qiāo 敲
+qiáo 桥
+qīn 亲
+qīng 青清轻
+qíng 情晴
+qǐng 请
+qiū 秋
+qiú 球求
+qū 区
+qù 去趣
+quán 全
+què 确
+qún 裙
+rán 然
+ràng 让
+rè 热
+rén 人
+rèn 认
+rì 日
+róng 容
+ròu 肉
+rú 如
+rù 入
+sān 三
+sǎn 伞
+shān 山
+shāng 商伤
+shàng 上
+shāo 烧
+shè 社
+shéi 谁
+shēn 身深
+shēng 生声
+shèng 胜剩
+shī 师诗失
+shí 十时实识食石
+shǐ 始使史
+shì 是事市试室视世式适
+shǒu 手首
+shòu 受瘦售
+shū 书叔舒输
+shǔ 鼠
+shù 树术
+shuāng 双
+shuǐ 水
+shuì 睡
+shuō 说
+sī 思司丝私
+sì 四寺
+sòng 送
+sù 诉速
+suān 酸
+suàn 算
+suī 虽
+suì 岁
+suǒ 所
+tā 他她它
+tái 台
+tài 太态
+tán 谈
+tāng 汤
+táng 糖
+tǎng 躺
+tè 特
+téng 疼
+tí 题提
+tiān 天
+tián 甜田
+tiáo 条
+tiào 跳
+tiē 贴
+tīng 听
+tíng 停
+tōng 通
+tóng 同
+tòng 痛
+tóu 头
+tú 图
+tuǐ 腿
+wài 外
+wán 玩完
+wǎn 晚碗
+wàn 万
+wǎng 网往
+wàng 忘望
+wēi 危
+wéi 围
+wèi 位味喂
+wén 文闻
+wèn 问
+wǒ 我
+wò 握
+wū 屋
+wǔ 五午舞
+wù 物务误
+xī 西希息
+xí 习席
+xǐ 洗喜
+xì 戏
+xià 下夏
+xiān 先鲜
+xiàn 现线
+xiāng 香乡
+xiǎng 想响
+xiàng 向象像项
+xiǎo 小
+xiào 笑校
+xiē 些
+xié 鞋
+xiě 写
+xiè 谢
+xīn 心新辛
+xìn 信
+xīng 星
+xíng 形
+xìng 姓幸
+xiū 休
+xū 需
+xǔ 许
+xué 学
+xuě 雪
+yán 颜言研
+yǎn 眼
+yàn 验
+yáng 阳羊
+yàng 样
+yào 药
+yé 爷
+yě 也
+yè 夜业页
+yī 衣医
+yí 姨宜
+yǐ 已以椅
+yì 意易艺亿
+yīn 因音阴
+yín 银
+yǐn 饮
+yìn 印
+yīng 英
+yíng 迎赢
+yǐng 影
+yòng 用
+yóu 游邮油由
+yǒu 有友
+yòu 又右
+yú 鱼
+yǔ 语雨
+yù 育遇预
+yuán 员元园原
+yuǎn 远
+yuàn 院愿
+yuè 月越
+yún 云
+yùn 运
+zài 在再
+zán 咱
+zǎo 早
+zěn 怎
+zhàn 站
+zhāng 张
+zhǎo 找
+zhào 照
+zhè 这
+zhēn 真
+zhěng 整
+zhèng 政
+zhī 知之支
+zhí 直值职
+zhǐ 纸指
+zhì 至制治质
+zhōng 钟
+zhōu 周州
+zhū 猪
+zhǔ 主
+zhù 住注助祝
+zhǔn 准
+zhuō 桌
+zì 字自
+zǒng 总
+zǒu 走
+zū 租
+zú 足族
+zuì 最醉
+zuó 昨
+zuǒ 左
+zuò 做坐座作
+`
+
+// pinyinWords fixes the reading of common words with polyphonic characters or neutral tones.
+// They are matched before single characters, longest first.
+var pinyinWords = map[string]string{
+	"你好": "nǐ hǎo", "很好": "hěn hǎo", "好吃": "hǎo chī", "好看": "hǎo kàn", "爱好": "ài hào", "好奇": "hào qí",
+	"银行": "yín háng", "行人": "xíng rén", "不行": "bù xíng", "不好": "bù hǎo", "不要": "bú yào", "旅行": "lǚ xíng", "自行车": "zì xíng chē",
+	"长城": "cháng chéng", "校长": "xiào zhǎng", "长大": "zhǎng dà",
+	"还是": "hái shì", "还有": "hái yǒu", "和平": "hé píng", "都是": "dōu shì", "首都": "shǒu dū",
+	"重要": "zhòng yào", "重新": "chóng xīn", "因为": "yīn wèi", "为什么": "wèi shén me", "认为": "rèn wéi",
+	"音乐": "yīn yuè", "快乐": "kuài lè", "觉得": "jué de", "睡觉": "shuì jiào",
+	"方便": "fāng biàn", "便宜": "pián yi", "当然": "dāng rán", "发现": "fā xiàn", "头发": "tóu fa",
+	"要求": "yāo qiú", "需要": "xū yào", "多少": "duō shao", "教室": "jiào shì",
+	"中国": "zhōng guó", "中文": "zhōng wén", "中午": "zhōng wǔ", "一样": "yí yàng", "了解": "liǎo jiě",
+	"的确": "dí què", "目的": "mù dì", "得到": "dé dào", "地方": "dì fang", "什么": "shén me",
+	"空气": "kōng qì", "有空": "yǒu kòng", "会议": "huì yì", "大家": "dà jiā", "大夫": "dài fu",
+	"时间": "shí jiān", "房间": "fáng jiān", "假期": "jià qī", "只有": "zhǐ yǒu", "数学": "shù xué",
+	"孩子": "hái zi", "儿子": "ér zi", "桌子": "zhuō zi", "椅子": "yǐ zi",
+	"朋友": "péng you", "东西": "dōng xi", "谢谢": "xiè xie", "喜欢": "xǐ huan", "认识": "rèn shi",
+	"知道": "zhī dao", "先生": "xiān sheng", "衣服": "yī fu", "休息": "xiū xi", "客气": "kè qi",
+	"意思": "yì si", "明白": "míng bai", "时候": "shí hou", "漂亮": "piào liang",
+	"妈妈": "mā ma", "爸爸": "bà ba", "哥哥": "gē ge", "姐姐": "jiě jie", "弟弟": "dì di", "妹妹": "mèi mei",
+}
+
+// pinyinMaxWord is the longest key of pinyinWords in characters.
+const pinyinMaxWord = 3
+
+var pinyinByRune = func() map[rune]string {
+	m := make(map[rune]string)
+	for _, line := range strings.Split(pinyinTable, "\n") {
+		syllable, chars, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		for _, c := range chars {
+			m[c] = syllable
+		}
+	}
+	return m
+}()
+
+// pinyinAt returns the syllables of the word or character starting at runes[i], or nil.
+func pinyinAt(runes []rune, i int) []string {
+	for n := min(pinyinMaxWord, len(runes)-i); n >= 2; n-- {
+		if reading, ok := pinyinWords[string(runes[i:i+n])]; ok {
+			return strings.Fields(reading)
+		}
+	}
+	if i < len(runes) {
+		if syllable, ok := pinyinByRune[runes[i]]; ok {
+			return []string{syllable}
+		}
+	}
+	return nil
+}
+
+// romanizeChinese writes one pinyin syllable per character, separated by spaces, with the
+// tone changes of 一 and 不 applied.
+func romanizeChinese(text string) (string, []rune) {
+	runes := []rune(text)
+	var parts []string
+	var unresolved []rune
+	var b strings.Builder
+	flush := func() {
+		if len(parts) > 0 {
+			if s := b.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "(") && !strings.HasSuffix(s, "\"") {
+				b.WriteString(" ")
+			}
+			b.WriteString(strings.Join(parts, " "))
+			parts = parts[:0]
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if !unicode.Is(unicode.Han, c) {
+			flush()
+			if p, ok := cjkPunctuation[c]; ok {
+				b.WriteString(p)
+			} else {
+				b.WriteRune(c)
+			}
+			continue
+		}
+
+		if syllables := pinyinAt(runes, i); len(syllables) > 1 {
+			parts = append(parts, syllables...)
+			i += len(syllables) - 1
+			continue
+		}
+
+		var next string
+		if syllables := pinyinAt(runes, i+1); len(syllables) > 0 {
+			next = syllables[0]
+		}
+		switch c {
+		case '不':
+			// bú before a fourth tone
+			if pinyinTone(next) == 4 {
+				parts = append(parts, "bú")
+			} else {
+				parts = append(parts, "bù")
+			}
+			continue
+		case '一':
+			// yí before a fourth tone, yì before the others, yī on its own
+			switch tone := pinyinTone(next); {
+			case next == "":
+				parts = append(parts, "yī")
+			case tone == 4:
+				parts = append(parts, "yí")
+			default:
+				parts = append(parts, "yì")
+			}
+			continue
+		}
+
+		syllable, ok := pinyinByRune[c]
+		if !ok {
+			unresolved = append(unresolved, c)
+			syllable = string(c)
+		}
+		parts = append(parts, syllable)
+	}
+	flush()
+
+	return strings.TrimSpace(b.String()), unresolved
+}
+
+// pinyinTone returns the tone (1-4) of a marked syllable, 0 for neutral or unknown.
+func pinyinTone(syllable string) int {
+	for _, c := range norm.NFD.String(syllable) {
+		switch c {
+		case '̄':
+			return 1
+		case '́':
+			return 2
+		case '̌':
+			return 3
+		case '̀':
+			return 4
+		}
+	}
+	return 0
+}
+
+// -------------------------------------------------------------------------
+// Japanese (Hepburn, kana only)
+// -------------------------------------------------------------------------
+
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa", 'ゕ': "ka", 'ゖ': "ke",
+}
+
+// kanaYoon is the consonant of an i-row kana combined with a small ya/yu/yo.
+var kanaYoon = map[rune]string{
+	'き': "ky", 'ぎ': "gy", 'し': "sh", 'じ': "j", 'ち': "ch", 'ぢ': "j", 'に': "ny",
+	'ひ': "hy", 'び': "by", 'ぴ': "py", 'み': "my", 'り': "ry",
+}
+
+// kanaExtended covers the katakana combinations for foreign sounds (ファ, ティ ...).
+var kanaExtended = map[string]string{
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo",
+	"てぃ": "ti", "でぃ": "di", "とぅ": "tu", "どぅ": "du",
+	"うぃ": "wi", "うぇ": "we", "うぉ": "wo", "いぇ": "ye",
+	"しぇ": "she", "じぇ": "je", "ちぇ": "che",
+	"つぁ": "tsa", "つぃ": "tsi", "つぇ": "tse", "つぉ": "tso",
+	"ゔぁ": "va", "ゔぃ": "vi", "ゔぇ": "ve", "ゔぉ": "vo",
+}
+
+// romanizeJapanese writes kana in Hepburn with long vowels spelled out (コーヒー -> koohii).
+// Kanji have no deterministic reading and stay unresolved.
+func romanizeJapanese(text string) (string, []rune) {
+	// Katakana to hiragana
+	runes := []rune(text)
+	for i, c := range runes {
+		if c >= 'ァ' && c <= 'ヶ' {
+			runes[i] = c - 0x60
+		}
+	}
+
+	var b strings.Builder
+	var unresolved []rune
+	geminate := false
+	lastVowel := func() string {
+		s := b.String()
+		for i := len(s) - 1; i >= 0; i-- {
+			if strings.IndexByte("aeiou", s[i]) >= 0 {
+				return s[i : i+1]
+			}
+		}
+		return ""
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		var syllable string
+		switch {
+		case c == 'っ':
+			geminate = true
+			continue
+		case c == 'ん':
+			syllable = "n"
+			if i+1 < len(runes) {
+				if next, ok := kanaRomaji[runes[i+1]]; ok && (strings.IndexByte("aeiouy", next[0]) >= 0) {
+					syllable = "n'"
+				}
+			}
+		case c == 'ー':
+			syllable = lastVowel()
+		case i+1 < len(runes) && kanaExtended[string(runes[i:i+2])] != "":
+			syllable = kanaExtended[string(runes[i:i+2])]
+			i++
+		case i+1 < len(runes) && kanaYoon[c] != "" && strings.ContainsRune("ゃゅょ", runes[i+1]):
+			syllable = kanaYoon[c] + kanaRomaji[runes[i+1]][1:]
+			i++
+		default:
+			romaji, ok := kanaRomaji[c]
+			if !ok {
+				geminate = false
+				if unicode.Is(unicode.Han, c) {
+					unresolved = append(unresolved, c)
+					b.WriteRune(c)
+				} else if p, ok := cjkPunctuation[c]; ok {
+					b.WriteString(p)
+				} else {
+					b.WriteRune(c)
+				}
+				continue
+			}
+			syllable = romaji
+		}
+
+		if geminate && syllable != "" && strings.IndexByte("aeioun", syllable[0]) < 0 {
+			// っ doubles the next consonant, tch for ch
+			if strings.HasPrefix(syllable, "ch") {
+				b.WriteString("t")
+			} else {
+				b.WriteByte(syllable[0])
+			}
+		}
+		geminate = false
+		b.WriteString(syllable)
+	}
+
+	return b.String(), unresolved
+}
+
+// -------------------------------------------------------------------------
+// Korean (Revised Romanization)
+// -------------------------------------------------------------------------
+
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulVowels   = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	// Final consonant before another consonant or at the end of a word
+	hangulFinals = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+	// Final consonant before a silent ㅇ: what stays and what moves to the next syllable
+	hangulLiaison = [][2]string{
+		{"", ""}, {"", "g"}, {"", "kk"}, {"k", "s"}, {"", "n"}, {"n", "j"}, {"", "n"}, {"", "d"},
+		{"", "r"}, {"l", "g"}, {"l", "m"}, {"l", "b"}, {"l", "s"}, {"l", "t"}, {"l", "p"}, {"", "r"},
+		{"", "m"}, {"", "b"}, {"p", "s"}, {"", "s"}, {"", "ss"}, {"ng", ""}, {"", "j"}, {"", "ch"},
+		{"", "k"}, {"", "t"}, {"", "p"}, {"", ""},
+	}
+)
+
+// Hangul jamo indexes used by the sound change rules
+const (
+	hangulInitialG      = 0
+	hangulInitialN      = 2
+	hangulInitialD      = 3
+	hangulInitialR      = 5
+	hangulInitialM      = 6
+	hangulInitialSilent = 11
+	hangulInitialJ      = 12
+	hangulInitialH      = 18
+	hangulFinalN        = 4
+	hangulFinalL        = 8
+	hangulFinalH        = 27
+)
+
+// romanizeKorean applies Revised Romanization with liaison, nasalization, ㄹ assimilation
+// and ㅎ aspiration inside a word.
+func romanizeKorean(text string) string {
+	type syllable struct{ initial, vowel, final int }
+	decode := func(c rune) (syllable, bool) {
+		if c < 0xAC00 || c > 0xD7A3 {
+			return syllable{}, false
+		}
+		idx := int(c - 0xAC00)
+		return syllable{initial: idx / 588, vowel: (idx % 588) / 28, final: idx % 28}, true
+	}
+
+	runes := []rune(text)
+	var b strings.Builder
+	carry := "" // initial sound moved or changed by the previous syllable
+	carrySet := false
+	for i, c := range runes {
+		cur, ok := decode(c)
+		if !ok {
+			carrySet = false
+			if p, ok := cjkPunctuation[c]; ok {
+				b.WriteString(p)
+			} else {
+				b.WriteRune(c)
+			}
+			continue
+		}
+
+		initial := hangulInitials[cur.initial]
+		if carrySet {
+			initial = carry
+		}
+		carrySet = false
+
+		final := hangulFinals[cur.final]
+		if i+1 < len(runes) {
+			if next, ok := decode(runes[i+1]); ok && cur.final != 0 {
+				switch {
+				case next.initial == hangulInitialSilent:
+					final, carry = hangulLiaison[cur.final][0], hangulLiaison[cur.final][1]
+					carrySet = carry != "" || cur.final == hangulFinalH
+				case cur.final == hangulFinalH && (next.initial == hangulInitialG || next.initial == hangulInitialD || next.initial == hangulInitialJ):
+					// ㅎ + ㄱ/ㄷ/ㅈ -> k/t/ch
+					final, carry, carrySet = "", map[int]string{hangulInitialG: "k", hangulInitialD: "t", hangulInitialJ: "ch"}[next.initial], true
+				case next.initial == hangulInitialH && (final == "k" || final == "t" || final == "p") && cur.final != hangulFinalH:
+					// ㄱ/ㄷ/ㅂ + ㅎ -> k/t/p
+					carry, carrySet = final, true
+					final = ""
+				case next.initial == hangulInitialR && (cur.final == hangulFinalL || cur.final == hangulFinalN):
+					// ㄹㄹ and ㄴㄹ -> ll
+					final, carry, carrySet = "l", "l", true
+				case next.initial == hangulInitialR:
+					// ㄹ after other finals is read ㄴ, which nasalizes k/t/p
+					final = nasalize(final)
+					carry, carrySet = "n", true
+				case next.initial == hangulInitialN && cur.final == hangulFinalL:
+					// ㄹㄴ -> ll
+					final, carry, carrySet = "l", "l", true
+				case next.initial == hangulInitialN || next.initial == hangulInitialM:
+					final = nasalize(final)
+				}
+			}
+		}
+
+		b.WriteString(initial)
+		b.WriteString(hangulVowels[cur.vowel])
+		b.WriteString(final)
+	}
+
+	return b.String()
+}
+
+// nasalize turns a k/t/p final into ng/n/m before a nasal.
+func nasalize(final string) string {
+	switch final {
+	case "k":
+		return "ng"
+	case "t":
+		return "n"
+	case "p":
+		return "m"
+	}
+	return final
+}
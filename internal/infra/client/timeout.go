@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+)
+
+// TimeoutPolicy is the deadline of each kind of external call (0 leaves the call unbounded).
+type TimeoutPolicy struct {
+	Chat          time.Duration
+	Image         time.Duration
+	Transcription time.Duration
+	Speech        time.Duration // TTS and pronunciation assessment
+}
+
+// WithTimeout bounds ctx by d; a non-positive d returns ctx unchanged.
+func WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// RequestError wraps a failed provider request, reporting deadline overruns as timeouts.
+func RequestError(ctx context.Context, message string, err error) *errors.AppError {
+	if stderrors.Is(err, context.DeadlineExceeded) || stderrors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.TimeoutWrap(message+": deadline exceeded", err)
+	}
+	return errors.InternalWrap(message, err)
+}
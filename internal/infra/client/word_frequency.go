@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// WordFrequency ranks words by how common they are in their language (1 is the most common).
+type WordFrequency struct {
+	ranks map[string]map[string]int
+}
+
+// LoadWordFrequency reads one list per language from dir, named by language code (en.txt, zh.txt, ...).
+// Each line holds a word, optionally followed by its count, most frequent first; lines past
+// maxRank are ignored (0 keeps all). An empty dir returns an empty WordFrequency.
+func LoadWordFrequency(dir string, maxRank int) (*WordFrequency, error) {
+	f := &WordFrequency{ranks: make(map[string]map[string]int)}
+	if dir == "" {
+		return f, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		language := LanguageCode(strings.TrimSuffix(filepath.Base(path), ".txt"))
+		if language == "" {
+			continue
+		}
+		ranks, err := readFrequencyList(path, maxRank)
+		if err != nil {
+			return nil, fmt.Errorf("frequency list %s: %w", path, err)
+		}
+		f.ranks[language] = ranks
+	}
+	return f, nil
+}
+
+func readFrequencyList(path string, maxRank int) (map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ranks := make(map[string]int)
+	rank := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		rank++
+		if maxRank > 0 && rank > maxRank {
+			break
+		}
+		word := strings.ToLower(fields[0])
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = rank
+		}
+	}
+	return ranks, scanner.Err()
+}
+
+// Languages returns the codes of the loaded lists.
+func (f *WordFrequency) Languages() []string {
+	if f == nil {
+		return nil
+	}
+	languages := make([]string, 0, len(f.ranks))
+	for language := range f.ranks {
+		languages = append(languages, language)
+	}
+	return languages
+}
+
+// Rank returns the frequency rank of text in language. A phrase not in the list as a whole
+// takes the rank of its rarest word; false when the language has no list or a word is missing.
+func (f *WordFrequency) Rank(language, text string) (int, bool) {
+	if f == nil {
+		return 0, false
+	}
+	ranks, ok := f.ranks[LanguageCode(language)]
+	if !ok {
+		return 0, false
+	}
+
+	text = strings.ToLower(strings.TrimSpace(text))
+	if rank, ok := ranks[text]; ok {
+		return rank, true
+	}
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+	})
+	if len(words) < 2 && (len(words) == 0 || words[0] == text) {
+		return 0, false
+	}
+	rarest := 0
+	for _, word := range words {
+		rank, ok := ranks[word]
+		if !ok {
+			return 0, false
+		}
+		rarest = max(rarest, rank)
+	}
+	return rarest, true
+}
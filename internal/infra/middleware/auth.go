@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/windfall/uwu_service/internal/domain/auth"
 	"github.com/windfall/uwu_service/pkg/errors"
 	"github.com/windfall/uwu_service/pkg/response"
 )
@@ -14,8 +13,31 @@ type contextKey string
 
 const UserIDKey contextKey = "user_id"
 
+// ImpersonatorKey holds the admin behind an impersonation token.
+const ImpersonatorKey contextKey = "impersonated_by"
+
+// DemoKey marks requests of an ephemeral demo account.
+const DemoKey contextKey = "demo"
+
+// TokenClaims represents the structured claims inside the JWT
+type TokenClaims struct {
+	UserID      string
+	Email       string
+	DisplayName string
+	AvatarURL   string
+	// ImpersonatedBy is the admin acting as the user, empty for normal sessions
+	ImpersonatedBy string
+	// Demo marks an ephemeral demo account (see DemoAccess)
+	Demo bool
+}
+
+// TokenValidator parses and validates the JWT of a request (the auth repository).
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*TokenClaims, *errors.AppError)
+}
+
 // Auth returns a middleware that validates JWT tokens from the Authorization header.
-func Auth(authRepo auth.AuthRepository) func(http.Handler) http.Handler {
+func Auth(authRepo TokenValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -38,6 +60,19 @@ func Auth(authRepo auth.AuthRepository) func(http.Handler) http.Handler {
 
 			// Set user ID in context
 			ctx := context.WithValue(r.Context(), UserIDKey, tokenClaims.UserID)
+
+			// Impersonation sessions only see what the learner sees, they never act for them
+			if tokenClaims.ImpersonatedBy != "" {
+				if r.Method != http.MethodGet && r.Method != http.MethodHead {
+					response.HandleError(w, errors.Forbidden("impersonation sessions are read-only"))
+					return
+				}
+				ctx = context.WithValue(ctx, ImpersonatorKey, tokenClaims.ImpersonatedBy)
+			}
+			if tokenClaims.Demo {
+				ctx = context.WithValue(ctx, DemoKey, true)
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -50,3 +85,17 @@ func GetUserID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// GetImpersonator returns the admin behind an impersonation token, or "".
+func GetImpersonator(ctx context.Context) string {
+	if admin, ok := ctx.Value(ImpersonatorKey).(string); ok {
+		return admin
+	}
+	return ""
+}
+
+// IsDemo reports whether the request comes from an ephemeral demo account.
+func IsDemo(ctx context.Context) bool {
+	demo, _ := ctx.Value(DemoKey).(bool)
+	return demo
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+const strictJSONKey contextKey = "strict_json"
+
+// BodyLimit returns a middleware that caps request bodies at maxBytes (413 above it) and rejects
+// JSON nested deeper than maxDepth. Multipart bodies are not buffered; they are only capped at the
+// larger maxMultipartBytes, and upload routes apply their own tighter limits on top.
+// A non-positive value disables that check.
+func BodyLimit(maxBytes, maxMultipartBytes int64, maxDepth int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+				if maxMultipartBytes > 0 {
+					if r.ContentLength > maxMultipartBytes {
+						response.HandleError(w, errors.TooLarge(fmt.Sprintf("request body must be at most %d bytes", maxMultipartBytes)))
+						return
+					}
+					r.Body = http.MaxBytesReader(w, r.Body, maxMultipartBytes)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tooLarge := errors.TooLarge(fmt.Sprintf("request body must be at most %d bytes", maxBytes))
+			if maxBytes > 0 && r.ContentLength > maxBytes {
+				response.HandleError(w, tooLarge)
+				return
+			}
+
+			// Buffer the body so its size and depth are known before any handler decodes it
+			reader := io.Reader(r.Body)
+			if maxBytes > 0 {
+				reader = io.LimitReader(r.Body, maxBytes+1)
+			}
+			body, err := io.ReadAll(reader)
+			r.Body.Close()
+			if err != nil {
+				response.HandleError(w, errors.ValidationWrap("failed to read request body", err))
+				return
+			}
+			if maxBytes > 0 && int64(len(body)) > maxBytes {
+				response.HandleError(w, tooLarge)
+				return
+			}
+			if maxDepth > 0 && jsonDepth(body) > maxDepth {
+				response.HandleError(w, errors.Validation(fmt.Sprintf("JSON body must be nested at most %d levels deep", maxDepth)))
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StrictJSON marks a route so DecodeJSON rejects unknown fields.
+func StrictJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), strictJSONKey, true)))
+	})
+}
+
+// DecodeJSON decodes the request body into v, rejecting unknown fields on StrictJSON routes.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	if strict, _ := r.Context().Value(strictJSONKey).(bool); strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+// jsonDepth returns the deepest object/array nesting in data, ignoring brackets inside strings.
+func jsonDepth(data []byte) int {
+	depth, deepest := 0, 0
+	inString, escaped := false, false
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if depth > deepest {
+				deepest = depth
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return deepest
+}
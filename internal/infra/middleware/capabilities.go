@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// RequireCapabilities returns a middleware that answers 501 when the server has no configured
+// provider for a capability the route needs.
+func RequireCapabilities(capabilities *client.Capabilities, names ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			missing := capabilities.Missing(names...)
+			if len(missing) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			response.HandleError(w, errors.Unsupported("this server is not configured for this operation").WithDetails(map[string]interface{}{
+				"capabilities": missing,
+			}))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ChatProviderHeader names the chat provider a request wants instead of the configured chains.
+const ChatProviderHeader = "X-Chat-Provider"
+
+// ChatProvider returns a middleware that pins the request's chat calls, and the jobs it queues,
+// to the provider named in X-Chat-Provider. Providers that are not selectable answer 400.
+func ChatProvider(chat *client.FallbackChatClient) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := strings.TrimSpace(r.Header.Get(ChatProviderHeader))
+			if name == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !chat.Selectable(name) {
+				response.HandleError(w, errors.Validation("chat provider "+name+" cannot be selected"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(client.WithChatProvider(r.Context(), name)))
+		})
+	}
+}
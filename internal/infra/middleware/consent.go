@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// ConsentChecker reports whether a user accepted the latest terms of a consent type.
+type ConsentChecker interface {
+	CheckConsent(ctx context.Context, userID, consentType string) *errors.AppError
+}
+
+// RequireConsent returns a middleware that answers 403 CONSENT_REQUIRED until the
+// authenticated user has accepted the latest terms of consentType. It must run after Auth.
+func RequireConsent(checker ConsentChecker, consentType string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := checker.CheckConsent(r.Context(), GetUserID(r.Context()), consentType); err != nil {
+				response.HandleError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
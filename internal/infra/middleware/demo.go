@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// DemoPolicy lists what demo sessions may do: the quota kinds each allowed route counts toward,
+// keyed by method and full route pattern, e.g. "POST /api/v1/dialogs/generate".
+type DemoPolicy map[string][]string
+
+// DemoAccess limits demo sessions to the routes in policy, each request taking one use of its quota
+// kinds. GET requests not in policy are free; any other route answers 403. Other sessions pass through.
+// It must run inside a route group, where the matched route pattern is known.
+func DemoAccess(quota *client.DemoQuota, policy DemoPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !IsDemo(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			kinds, allowed := policy[r.Method+" "+chi.RouteContext(r.Context()).RoutePattern()]
+			if !allowed {
+				if r.Method == http.MethodGet || r.Method == http.MethodHead {
+					next.ServeHTTP(w, r)
+					return
+				}
+				response.HandleError(w, errors.Forbidden("not available in demo sessions"))
+				return
+			}
+
+			userID := GetUserID(r.Context())
+			for _, kind := range kinds {
+				ok, err := quota.Take(r.Context(), userID, kind)
+				if err != nil {
+					// Without the counters the quota cannot be enforced, so demo sessions are refused
+					response.HandleError(w, errors.ProviderUnavailable("demo sessions are temporarily unavailable"))
+					return
+				}
+				if !ok {
+					response.HandleError(w, errors.RateLimit("demo quota used up").WithDetails(map[string]interface{}{
+						"quota": kind,
+						"limit": quota.Limits()[kind],
+					}))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
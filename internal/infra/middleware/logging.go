@@ -31,6 +31,11 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.wroteHeader = true
 }
 
+// Unwrap ให้ http.ResponseController เข้าถึง Flush ของ ResponseWriter ตัวจริงได้ (ใช้กับ SSE)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Logger เป็น Middleware บันทึกข้อมูลการเข้าใช้งาน
 func Logger(log *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
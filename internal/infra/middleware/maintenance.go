@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// Maintenance returns a middleware that answers 503 with Retry-After while the whole API,
+// or the given scope, is switched off. An empty scope only honors the global flag.
+func Maintenance(maintenance *client.MaintenanceClient, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flag, active := maintenance.Active(r.Context(), scope)
+			if !active {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if flag.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(flag.RetryAfter))
+			}
+			message := flag.Message
+			if message == "" {
+				message = "service is temporarily unavailable for maintenance"
+			}
+			response.HandleError(w, errors.Maintenance(message).WithDetails(map[string]interface{}{
+				"scope":       flag.Scope,
+				"retry_after": flag.RetryAfter,
+			}))
+		})
+	}
+}
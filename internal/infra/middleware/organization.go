@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// Organization returns a middleware that puts the authenticated user's organization in the
+// context, so provider calls and queued jobs use the organization's own keys. It must run
+// after Auth and does nothing when organization credentials are disabled.
+func Organization(credentials *client.CredentialStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !credentials.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			organizationID, err := credentials.OrganizationOf(r.Context(), GetUserID(r.Context()))
+			if err != nil {
+				response.HandleError(w, errors.InternalWrap("failed to resolve organization", err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(client.WithOrganization(r.Context(), organizationID)))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/windfall/uwu_service/internal/infra/client"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// RequireProviders returns a middleware that answers 503 while any of the providers the
+// route depends on is degraded, instead of queueing work that is bound to fail.
+func RequireProviders(health *client.ProviderHealthClient, providers ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			degraded := health.Degraded(r.Context(), providers...)
+			if len(degraded) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			names := make([]string, len(degraded))
+			for i, status := range degraded {
+				names[i] = status.Provider
+			}
+			response.HandleError(w, errors.ProviderUnavailable("a provider this request depends on is temporarily unavailable").WithDetails(map[string]interface{}{
+				"providers": names,
+			}))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
+)
+
+// clientBucketIdle is how long an idle client bucket is kept before it is swept.
+const clientBucketIdle = 10 * time.Minute
+
+// clientBucketMax caps the buckets kept per limiter; once full, new clients are limited
+// until buckets refill or go idle.
+const clientBucketMax = 100000
+
+type clientBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ClientRateLimiter is a per-client-IP token bucket for unauthenticated routes. Buckets live in
+// memory, so each instance enforces the limit on its own.
+type ClientRateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+// NewClientRateLimiter allows qps requests per second per client with bursts up to burst.
+// It returns nil (no limit) when qps <= 0.
+func NewClientRateLimiter(qps float64, burst int) *ClientRateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &ClientRateLimiter{
+		rate:      qps,
+		burst:     float64(burst),
+		buckets:   make(map[string]*clientBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token for key, or returns how long until one is available.
+func (l *ClientRateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > clientBucketIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > clientBucketIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= clientBucketMax {
+			// A bucket idle long enough to refill is the same as a new one
+			refill := time.Duration(l.burst / l.rate * float64(time.Second))
+			for k, b := range l.buckets {
+				if now.Sub(b.last) >= refill {
+					delete(l.buckets, k)
+				}
+			}
+			if len(l.buckets) >= clientBucketMax {
+				return false, refill
+			}
+		}
+		b = &clientBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// RateLimitByIP returns a middleware answering 429 with Retry-After once a client IP runs out
+// of tokens. It relies on ClientIP having set RemoteAddr from trusted proxies only. A nil limiter
+// lets everything through.
+func RateLimitByIP(limiter *ClientRateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+
+			if ok, wait := limiter.allow(ip); !ok {
+				w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+				response.HandleError(w, errors.RateLimit("too many requests, slow down"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// debugRoutes serves pprof, expvar (/debug/vars) and a goroutine dump.
+func debugRoutes(r chi.Router) {
+	r.Get("/goroutines", goroutineDump)
+	r.Mount("/", chiMiddleware.Profiler())
+}
+
+// goroutineDump writes goroutines grouped by stack with their counts, which is what
+// a leak looks like; ?full=true writes every goroutine with its own stack instead.
+func goroutineDump(w http.ResponseWriter, r *http.Request) {
+	debug := 1
+	if full, _ := strconv.ParseBool(r.URL.Query().Get("full")); full {
+		debug = 2
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = pprof.Lookup("goroutine").WriteTo(w, debug)
+}
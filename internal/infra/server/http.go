@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -12,11 +13,33 @@ import (
 
 	"github.com/windfall/uwu_service/internal/config"
 	"github.com/windfall/uwu_service/internal/domain/auth"
+	"github.com/windfall/uwu_service/internal/domain/batch"
+	"github.com/windfall/uwu_service/internal/domain/bundle"
+	"github.com/windfall/uwu_service/internal/domain/consent"
+	"github.com/windfall/uwu_service/internal/domain/content"
+	"github.com/windfall/uwu_service/internal/domain/deck"
+	"github.com/windfall/uwu_service/internal/domain/delta"
+	"github.com/windfall/uwu_service/internal/domain/demo"
 	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/domain/event"
+	"github.com/windfall/uwu_service/internal/domain/favorite"
+	"github.com/windfall/uwu_service/internal/domain/feature"
+	"github.com/windfall/uwu_service/internal/domain/goal"
+	"github.com/windfall/uwu_service/internal/domain/maintenance"
+	"github.com/windfall/uwu_service/internal/domain/moderation"
+	"github.com/windfall/uwu_service/internal/domain/note"
+	"github.com/windfall/uwu_service/internal/domain/organization"
 	"github.com/windfall/uwu_service/internal/domain/profile"
+	"github.com/windfall/uwu_service/internal/domain/public"
+	"github.com/windfall/uwu_service/internal/domain/recommendation"
+	"github.com/windfall/uwu_service/internal/domain/romanize"
+	"github.com/windfall/uwu_service/internal/domain/storage"
+	"github.com/windfall/uwu_service/internal/domain/support"
 	"github.com/windfall/uwu_service/internal/domain/video"
 	"github.com/windfall/uwu_service/internal/infra/client"
 	"github.com/windfall/uwu_service/internal/infra/middleware"
+	"github.com/windfall/uwu_service/pkg/errors"
+	"github.com/windfall/uwu_service/pkg/response"
 )
 
 // HTTPServer represents the HTTP server
@@ -30,26 +53,96 @@ func NewHTTPServer(
 	cfg *config.Config,
 	log *slog.Logger,
 	db *client.PostgresClient,
+	rateLimiters []*client.RateLimiter,
 	authRepo auth.AuthRepository,
 	authHandler *auth.AuthHandler,
 	videoHandler *video.VideoHandler,
 	dialogHandler *dialog.DialogHandler,
 	profileHandler *profile.ProfileHandler,
+	featureHandler *feature.FeatureHandler,
+	contentHandler *content.ContentHandler,
+	recommendationHandler *recommendation.RecommendationHandler,
+	goalHandler *goal.GoalHandler,
+	eventHandler *event.EventHandler,
+	deltaHandler *delta.DeltaHandler,
+	publicHandler *public.PublicHandler,
+	supportHandler *support.SupportHandler,
+	maintenanceClient *client.MaintenanceClient,
+	providerHealth *client.ProviderHealthClient,
+	maintenanceHandler *maintenance.MaintenanceHandler,
+	bundleHandler *bundle.BundleHandler,
+	consentService *consent.ConsentService,
+	consentHandler *consent.ConsentHandler,
+	moderationHandler *moderation.ModerationHandler,
+	romanizeHandler *romanize.RomanizeHandler,
+	deckHandler *deck.DeckHandler,
+	favoriteHandler *favorite.FavoriteHandler,
+	noteHandler *note.NoteHandler,
+	batchHandler *batch.BatchHandler,
+	storageHandler *storage.StorageHandler,
+	organizationHandler *organization.OrganizationHandler,
+	demoHandler *demo.DemoHandler,
+	demoQuota *client.DemoQuota,
+	credentialStore *client.CredentialStore,
+	jobRegistry *client.JobRegistry,
+	jobStore *client.JobStore,
+	capabilities *client.Capabilities,
+	chatClient *client.FallbackChatClient,
 ) *HTTPServer {
 	r := chi.NewRouter()
 
+	// Speaking and retell store the user's voice
+	requireRecordingConsent := middleware.RequireConsent(consentService, consent.CONSENT_RECORDING)
+
+	// Routes that need a provider this deployment may not have configured
+	requireChat := middleware.RequireCapabilities(capabilities, client.CapabilityChat)
+	requireDialogGeneration := middleware.RequireCapabilities(capabilities, client.CapabilityChat, client.CapabilityTTS, client.CapabilityImageGeneration)
+	requireTranscription := middleware.RequireCapabilities(capabilities, client.CapabilitySTT, client.CapabilityChat)
+
+	// What demo sessions may do besides reading, and the quotas each route counts toward
+	demoWrite := []string{client.DemoQuotaWrites}
+	demoGenerate := []string{client.DemoQuotaWrites, client.DemoQuotaGenerations}
+	demoPolicy := middleware.DemoPolicy{
+		"POST /api/v1/dialogs/generate":                           demoGenerate,
+		"POST /api/v1/dialogs/{dialogID}/start-speech":            demoWrite,
+		"POST /api/v1/dialogs/{dialogID}/submit-speech":           demoWrite,
+		"POST /api/v1/dialogs/{dialogID}/start-chat":              demoWrite,
+		"POST /api/v1/dialogs/{dialogID}/submit-chat":             demoWrite,
+		"POST /api/v1/dialogs/{dialogID}/sparring/turn":           demoWrite,
+		"POST /api/v1/dialogs/{dialogID}/turns/{turnIndex}/check": demoWrite,
+		"GET /api/v1/dialogs/{dialogID}/turns/{turnIndex}/hint":   demoWrite,
+		"POST /api/v1/dialogs/{dialogID}/toggle-saved":            demoWrite,
+		"POST /api/v1/videos/{videoID}/start-quiz":                demoWrite,
+		"POST /api/v1/videos/{videoID}/submit-quiz":               demoWrite,
+		"POST /api/v1/videos/{videoID}/toggle-saved":              demoWrite,
+		"PUT /api/v1/favorites/{contentType}/{contentID}":         demoWrite,
+		"DELETE /api/v1/favorites/{contentType}/{contentID}":      demoWrite,
+		"POST /api/v1/consents/{consentType}/accept":              demoWrite,
+	}
+
 	// Global middleware
 	r.Use(chiMiddleware.RequestID)
-	r.Use(chiMiddleware.RealIP)
+	r.Use(middleware.ClientIP(cfg.TrustedProxies))
 	r.Use(middleware.Logger(log))
 	r.Use(middleware.Recovery(log))
 	r.Use(chiMiddleware.Compress(5))
+	r.Use(middleware.SecurityHeaders(middleware.SecurityOptions{
+		ContentSecurityPolicy: cfg.SecurityCSP,
+		ReferrerPolicy:        cfg.SecurityReferrerPolicy,
+		HSTSMaxAge:            cfg.SecurityHSTSMaxAge,
+		HSTSIncludeSubdomains: cfg.SecurityHSTSIncludeSubdomains,
+	}))
+
+	// Request bodies (multipart uploads get a larger cap, upload routes add their own limits)
+	r.Use(middleware.BodyLimit(cfg.MaxJSONBodyBytes, cfg.MaxMultipartBodyBytes, cfg.MaxJSONDepth))
 
-	// CORS
+	// CORS (origins are checked by the same OriginChecker that guards WebSocket upgrades)
+	origins := middleware.NewOriginChecker(cfg.CORSAllowedOrigins)
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowOriginFunc:  origins.AllowOrigin,
 		AllowedMethods:   cfg.CORSAllowedMethods,
 		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		ExposedHeaders:   []string{"ETag", "Last-Modified"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -64,6 +157,58 @@ func NewHTTPServer(
 		})
 	})
 
+	// Readiness: database reachable, degraded providers listed (they only disable the features that need them)
+	r.Get("/ready", func(w http.ResponseWriter, r *http.Request) {
+		status, code := "ready", http.StatusOK
+		if err := db.Pool.Ping(r.Context()); err != nil {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+		degraded := providerHealth.Degraded(r.Context())
+		if code == http.StatusOK && len(degraded) > 0 {
+			status = "degraded"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":             status,
+			"degraded_providers": degraded,
+			"providers":          providerHealth.List(r.Context()),
+		})
+	})
+
+	r.Get("/health/rate-limits", func(w http.ResponseWriter, r *http.Request) {
+		stats := make([]client.RateLimiterStats, 0, len(rateLimiters))
+		for _, limiter := range rateLimiters {
+			if limiter != nil {
+				stats = append(stats, limiter.Stats())
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rate_limits": stats,
+		})
+	})
+
+	// Runtime diagnostics (pprof, expvar, goroutine dump) behind admin auth
+	if cfg.DebugEndpointsEnabled && cfg.AdminEnabled() {
+		r.Route("/debug", func(r chi.Router) {
+			r.Use(chiMiddleware.BasicAuth("uwu_service admin", map[string]string{cfg.DevAdminUser: cfg.DevAdminPass}))
+			debugRoutes(r)
+		})
+	}
+
+	// Public read-only content (no auth, own per-IP rate limit, cacheable)
+	r.Route("/public/v1", func(r chi.Router) {
+		r.Use(middleware.Maintenance(maintenanceClient, ""))
+		r.Use(middleware.RateLimitByIP(middleware.NewClientRateLimiter(cfg.PublicRateLimitQPS, cfg.PublicRateLimitBurst)))
+
+		r.Get("/items", publicHandler.ListItems)
+		r.Get("/items/{itemID}", publicHandler.GetItem)
+	})
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		// r.Post("/dev/clear-migrations", func(w http.ResponseWriter, r *http.Request) {
@@ -99,43 +244,246 @@ func NewHTTPServer(
 		// 	})
 		// })
 
-		// Public auth endpoints
-		r.Post("/auth/register", authHandler.Register)
-		r.Post("/auth/login", authHandler.Login)
+		// Admin endpoints (basic auth with the dev admin credentials, not mounted without them)
+		if cfg.AdminEnabled() {
+			r.Group(func(r chi.Router) {
+				r.Use(chiMiddleware.BasicAuth("uwu_service admin", map[string]string{cfg.DevAdminUser: cfg.DevAdminPass}))
+
+				// Maintenance mode and route kill switches
+				r.Get("/admin/maintenance", maintenanceHandler.ListFlags)
+				r.Put("/admin/maintenance/{scope}", maintenanceHandler.EnableScope)
+				r.Delete("/admin/maintenance/{scope}", maintenanceHandler.DisableScope)
+
+				r.Get("/admin/content/stats", contentHandler.GetStats)
+				r.Get("/admin/content/clusters", contentHandler.ListClusters)
+				r.Get("/admin/content/media", contentHandler.ListMediaIssues)
+				r.Get("/admin/content/glossary", contentHandler.ListGlossaryConflicts)
+				r.With(middleware.StrictJSON).Post("/admin/content/glossary/{conflictID}/merge", contentHandler.MergeGlossaryConflict)
+
+				// Moderation queue of reported videos and dialogs
+				r.Get("/admin/moderation/queue", moderationHandler.ListQueue)
+				r.With(middleware.StrictJSON).Post("/admin/moderation/items/{itemID}/resolve", moderationHandler.ResolveReports)
+				r.Get("/admin/moderation/reviews", moderationHandler.ListReviews)
+				r.With(middleware.StrictJSON).Post("/admin/moderation/reviews/{itemID}/resolve", moderationHandler.ResolveReview)
+
+				// Approve learning items for the public content API
+				r.Put("/admin/public/{itemID}", publicHandler.Approve)
+				r.Delete("/admin/public/{itemID}", publicHandler.Withdraw)
+
+				// R2 storage usage by content type and user
+				r.Get("/admin/storage/usage", storageHandler.GetUsage)
 
-		// Protected endpoints (require JWT)
+				// Running background jobs (queue jobs and the goroutines they spawn), longest-running first
+				r.Get("/admin/jobs", func(w http.ResponseWriter, r *http.Request) {
+					response.OKWithMeta(w, jobRegistry.List(), jobRegistry.Stats())
+				})
+
+				// Durable jobs that used all their attempts, and putting one back in the queue
+				r.Get("/admin/jobs/dead", func(w http.ResponseWriter, r *http.Request) {
+					if jobStore == nil {
+						response.HandleError(w, errors.Unsupported("durable queue not enabled"))
+						return
+					}
+					jobs, err := jobStore.ListDead(r.Context(), 100)
+					if err != nil {
+						response.HandleError(w, errors.InternalWrap("failed to list dead jobs", err))
+						return
+					}
+					response.OK(w, jobs)
+				})
+				r.Post("/admin/jobs/dead/{jobID}/retry", func(w http.ResponseWriter, r *http.Request) {
+					if jobStore == nil {
+						response.HandleError(w, errors.Unsupported("durable queue not enabled"))
+						return
+					}
+					id, err := strconv.ParseInt(chi.URLParam(r, "jobID"), 10, 64)
+					if err != nil {
+						response.HandleError(w, errors.Validation("invalid job id"))
+						return
+					}
+					retried, err := jobStore.Retry(r.Context(), id)
+					if err != nil {
+						response.HandleError(w, errors.InternalWrap("failed to retry job", err))
+						return
+					}
+					if !retried {
+						response.HandleError(w, errors.NotFound("dead job not found"))
+						return
+					}
+					response.NoContent(w)
+				})
+
+				// Support tooling (every call is audited)
+				r.Get("/admin/users/{userID}/sessions", supportHandler.ListSessions)
+				r.Get("/admin/users/{userID}/batches", supportHandler.ListBatches)
+				r.Get("/admin/users/{userID}/errors", supportHandler.ListErrors)
+
+				// Organizations and their bring-your-own-key provider credentials (every change is audited)
+				r.Get("/admin/organizations", organizationHandler.ListOrganizations)
+				r.With(middleware.StrictJSON).Post("/admin/organizations", organizationHandler.CreateOrganization)
+				r.Put("/admin/organizations/{orgID}/members/{userID}", organizationHandler.AddMember)
+				r.Delete("/admin/organizations/{orgID}/members/{userID}", organizationHandler.RemoveMember)
+				r.With(middleware.StrictJSON).Put("/admin/organizations/{orgID}/image-style", organizationHandler.SetImageStyle)
+				r.Get("/admin/organizations/{orgID}/credentials", organizationHandler.ListCredentials)
+				r.With(middleware.StrictJSON).Put("/admin/organizations/{orgID}/credentials/{provider}", organizationHandler.SetCredentials)
+				r.Delete("/admin/organizations/{orgID}/credentials/{provider}", organizationHandler.DeleteCredentials)
+
+				// Parental controls: correct a learner's birth date, reclassify a dialog's audience
+				r.With(middleware.StrictJSON).Put("/admin/users/{userID}/birth-date", profileHandler.AdminSetBirthDate)
+				r.With(middleware.StrictJSON).Put("/admin/dialogs/{dialogID}/audience", dialogHandler.SetAudience)
+			})
+		}
+
+		// Impersonation mints tokens for any user, so it takes its own credentials rather than the admin ones
+		if cfg.ImpersonationEnabled() {
+			r.With(chiMiddleware.BasicAuth("uwu_service impersonation", map[string]string{cfg.ImpersonationUser: cfg.ImpersonationPass})).
+				Post("/admin/users/{userID}/impersonate", supportHandler.Impersonate)
+		}
+
+		// Everything else honors maintenance mode (admin stays reachable to switch it off)
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.Auth(authRepo))
-
-			// Dialog
-			r.Get("/dialogs/contents", dialogHandler.ListDialogContents)
-			r.Post("/dialogs/generate", dialogHandler.GenerateDialog)
-			r.Get("/dialogs/{dialogID}/details", dialogHandler.GetDialogDetails)
-			r.Post("/dialogs/{dialogID}/toggle-saved", dialogHandler.ToggleSaved)
-			r.Post("/dialogs/{dialogID}/start-chat", dialogHandler.StartChat)
-			r.Post("/dialogs/{dialogID}/start-speech", dialogHandler.StartSpeech)
-			r.Post("/dialogs/{dialogID}/submit-chat", dialogHandler.SubmitChat)
-			r.Get("/dialogs/{dialogID}/submit-chat", dialogHandler.GetSubmitChat)
-			r.Post("/dialogs/{dialogID}/submit-speech", dialogHandler.SubmitSpeech)
-			// GET /dialogs/{dialogID}/speech-scripts
-			// POST /dialogs/{dialogID}/speech-scripts
-
-			// Video
-			r.Get("/videos/contents", videoHandler.ListVideoContents)
-			r.Post("/videos/upload", videoHandler.UploadVideo)
-			r.Get("/videos/{videoID}/details", videoHandler.GetVideoDetails)
-			r.Post("/videos/{videoID}/toggle-saved", videoHandler.ToggleSaved)
-			r.Post("/videos/{videoID}/toggle-transcript", videoHandler.ToggleTranscript)
-			r.Post("/videos/{videoID}/start-quiz", videoHandler.StartQuiz)
-			r.Post("/videos/{videoID}/start-retell", videoHandler.StartRetell)
-			r.Post("/videos/{videoID}/submit-quiz", videoHandler.SubmitGistQuiz)
-			r.Post("/videos/{videoID}/submit-retell", videoHandler.SubmitRetellStory)
-
-			// Profile
-			r.Get("/profile", profileHandler.GetProfile)
-			// r.Put("profile", profileHandler.UpdateProfile)
-			// r.Get("profile/stats", profileHandler.GetProfileStats)
+			r.Use(middleware.Maintenance(maintenanceClient, ""))
+
+			// Public auth endpoints
+			r.Post("/auth/register", authHandler.Register)
+			r.Post("/auth/login", authHandler.Login)
+
+			// Public demo sessions for the marketing site's try-it widget
+			if cfg.DemoEnabled {
+				r.With(middleware.RateLimitByIP(middleware.NewClientRateLimiter(cfg.DemoRateLimitQPS, cfg.DemoRateLimitBurst))).Post("/demo/session", demoHandler.StartSession)
+			}
+
+			// Public feature registry
+			r.Get("/features", featureHandler.ListFeatures)
+
+			// What this deployment is configured for (operations needing a missing capability answer 501)
+			r.Get("/capabilities", func(w http.ResponseWriter, r *http.Request) {
+				response.OK(w, capabilities.List())
+			})
+
+			// Protected endpoints (require JWT)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.Auth(authRepo))
+				r.Use(middleware.Organization(credentialStore))
+				r.Use(middleware.DemoAccess(demoQuota, demoPolicy))
+				r.Use(middleware.ChatProvider(chatClient))
+
+				// Dialog
+				r.Get("/dialogs/contents", dialogHandler.ListDialogContents)
+				r.With(middleware.Maintenance(maintenanceClient, client.MaintenanceScopeDialogGenerate), requireDialogGeneration, middleware.StrictJSON).Post("/dialogs/generate", dialogHandler.GenerateDialog)
+				r.With(middleware.Maintenance(maintenanceClient, client.MaintenanceScopeDialogGenerate), requireDialogGeneration).Post("/dialogs/{dialogID}/adapt", dialogHandler.AdaptDialog)
+				r.Get("/dialogs/{dialogID}/details", dialogHandler.GetDialogDetails)
+				r.Post("/dialogs/{dialogID}/toggle-saved", dialogHandler.ToggleSaved)
+				r.Post("/dialogs/{dialogID}/publish", dialogHandler.PublishDialog)
+				r.Post("/dialogs/{dialogID}/unpublish", dialogHandler.UnpublishDialog)
+				r.Post("/dialogs/{dialogID}/start-chat", dialogHandler.StartChat)
+				r.Post("/dialogs/{dialogID}/start-speech", dialogHandler.StartSpeech)
+				r.With(requireChat, middleware.StrictJSON).Post("/dialogs/{dialogID}/submit-chat", dialogHandler.SubmitChat)
+				r.Get("/dialogs/{dialogID}/submit-chat", dialogHandler.GetSubmitChat)
+				r.Get("/dialogs/{dialogID}/session", dialogHandler.GetPracticeSession)
+				r.With(requireChat, middleware.StrictJSON).Post("/dialogs/{dialogID}/sparring/turn", dialogHandler.SparringTurn)
+				r.With(requireRecordingConsent, middleware.RequireCapabilities(capabilities, client.CapabilityPronunciation), middleware.RequireProviders(providerHealth, client.ProviderAzureSpeech)).Post("/dialogs/{dialogID}/submit-speech", dialogHandler.SubmitSpeech)
+				r.With(middleware.RequireCapabilities(capabilities, client.CapabilityChat, client.CapabilityTTS), middleware.StrictJSON).Post("/dialogs/{dialogID}/turns/{turnIndex}/regenerate", dialogHandler.RegenerateScriptTurn)
+				r.Get("/dialogs/{dialogID}/batches/{batchID}", dialogHandler.GetDialogBatch)
+				r.With(middleware.Maintenance(maintenanceClient, client.MaintenanceScopeDialogGenerate), requireDialogGeneration).Post("/batches/{batchID}/jobs/{jobName}/retry", dialogHandler.RetryBatchJob)
+				r.Get("/batches/{batchID}/events", batchHandler.StreamEvents)
+				r.Post("/dialogs/{dialogID}/turns/{turnIndex}/check", dialogHandler.CheckTurnBlanks)
+				r.With(requireChat).Get("/dialogs/{dialogID}/turns/{turnIndex}/hint", dialogHandler.GetTurnHint)
+				r.With(requireChat, middleware.StrictJSON).Post("/dialogs/{dialogID}/fix", dialogHandler.ProposeFix)
+				r.Post("/dialogs/{dialogID}/fix/{fixID}/apply", dialogHandler.ApplyFix)
+				// GET /dialogs/{dialogID}/speech-scripts
+				// POST /dialogs/{dialogID}/speech-scripts
+
+				// Video
+				r.Get("/videos/contents", videoHandler.ListVideoContents)
+				r.With(middleware.Maintenance(maintenanceClient, client.MaintenanceScopeVideoUpload), requireTranscription, middleware.RequireProviders(providerHealth, client.ProviderR2, client.ProviderWhisper)).Post("/videos/upload", videoHandler.UploadVideo)
+				r.With(middleware.Maintenance(maintenanceClient, client.MaintenanceScopeVideoBulk)).Post("/videos/bulk", videoHandler.BulkVideos)
+				r.Get("/videos/{videoID}/details", videoHandler.GetVideoDetails)
+				r.Patch("/videos/{videoID}", videoHandler.PatchVideo)
+				r.Get("/videos/{videoID}/chapters", videoHandler.GetVideoChapters)
+				r.Post("/videos/{videoID}/toggle-saved", videoHandler.ToggleSaved)
+				r.Post("/videos/{videoID}/toggle-transcript", videoHandler.ToggleTranscript)
+				r.Patch("/videos/{videoID}/transcript", videoHandler.UpdateTranscript)
+				r.Post("/videos/{videoID}/start-quiz", videoHandler.StartQuiz)
+				r.Post("/videos/{videoID}/start-retell", videoHandler.StartRetell)
+				r.Put("/videos/{videoID}/retell-settings", videoHandler.UpdateRetellSettings)
+				r.Get("/videos/{videoID}/retell-points", videoHandler.ListRetellPoints)
+				r.Post("/videos/{videoID}/retell-points", videoHandler.AddRetellPoint)
+				r.Put("/videos/{videoID}/retell-points/order", videoHandler.ReorderRetellPoints)
+				r.Patch("/videos/{videoID}/retell-points/{pointIndex}", videoHandler.UpdateRetellPoint)
+				r.Delete("/videos/{videoID}/retell-points/{pointIndex}", videoHandler.DeleteRetellPoint)
+				r.Post("/videos/{videoID}/submit-quiz", videoHandler.SubmitGistQuiz)
+
+				r.With(requireRecordingConsent, requireTranscription, middleware.RequireProviders(providerHealth, client.ProviderR2, client.ProviderWhisper)).Post("/videos/{videoID}/submit-retell", videoHandler.SubmitRetellStory)
+
+				// Learning item vocabulary expansion (video vocabulary and key phrases)
+				r.With(requireChat, middleware.StrictJSON).Post("/learning-items/{itemID}/expand", videoHandler.ExpandVocabulary)
 
+				// Profile
+				r.Get("/profile", profileHandler.GetProfile)
+				r.With(middleware.StrictJSON).Put("/profile/birth-date", profileHandler.SetBirthDate)
+				// r.Put("profile", profileHandler.UpdateProfile)
+				// r.Get("profile/stats", profileHandler.GetProfileStats)
+
+				// Recommendations
+				r.Get("/users/me/next", recommendationHandler.GetNextActivities)
+
+				// Daily goal
+				r.Get("/users/me/goal", goalHandler.GetDailyGoal)
+				r.Put("/users/me/goal", goalHandler.UpdateDailyGoal)
+
+				// Client activity events
+				r.Post("/events", eventHandler.IngestEvents)
+
+				// Delta sync
+				r.Get("/sync", deltaHandler.Sync)
+
+				// Offline bundles
+				r.With(middleware.StrictJSON).Post("/bundles", bundleHandler.CreateBundle)
+				r.Get("/bundles/{bundleID}", bundleHandler.GetBundle)
+
+				// Terms and consents
+				r.Get("/consents", consentHandler.ListConsents)
+				r.With(middleware.StrictJSON).Post("/consents/{consentType}/accept", consentHandler.AcceptConsent)
+
+				// Content reports
+				r.With(middleware.StrictJSON).Post("/content/{type}/{id}/report", moderationHandler.ReportContent)
+
+				// Romanization (pinyin, romaji, revised romanization)
+				r.Get("/romanize", romanizeHandler.Romanize)
+
+				// Decks
+				r.Get("/decks", deckHandler.ListDecks)
+				r.With(middleware.StrictJSON).Post("/decks", deckHandler.CreateDeck)
+				r.Get("/decks/shared/{token}", deckHandler.GetSharedDeck)
+				r.Post("/decks/shared/{token}/copy", deckHandler.CopySharedDeck)
+				r.Get("/decks/public", deckHandler.ListPublicDecks)
+				r.Get("/decks/public/{deckID}", deckHandler.GetPublicDeck)
+				r.Post("/decks/public/{deckID}/copy", deckHandler.CopyPublicDeck)
+				r.Get("/decks/{deckID}", deckHandler.GetDeck)
+				r.With(middleware.StrictJSON).Patch("/decks/{deckID}", deckHandler.UpdateDeck)
+				r.Delete("/decks/{deckID}", deckHandler.DeleteDeck)
+				r.Put("/decks/{deckID}/items/{itemID}", deckHandler.AddItem)
+				r.Delete("/decks/{deckID}/items/{itemID}", deckHandler.RemoveItem)
+				r.Post("/decks/{deckID}/share", deckHandler.ShareDeck)
+				r.Delete("/decks/{deckID}/share", deckHandler.UnshareDeck)
+				r.Post("/decks/{deckID}/publish", deckHandler.PublishDeck)
+				r.Post("/decks/{deckID}/unpublish", deckHandler.UnpublishDeck)
+				r.Get("/decks/{deckID}/review", deckHandler.GetReviewQueue)
+				r.With(middleware.StrictJSON).Post("/decks/{deckID}/items/{itemID}/review", deckHandler.ReviewItem)
+
+				// Favorites
+				r.Get("/favorites", favoriteHandler.ListFavorites)
+				r.Put("/favorites/{contentType}/{contentID}", favoriteHandler.AddFavorite)
+				r.Delete("/favorites/{contentType}/{contentID}", favoriteHandler.RemoveFavorite)
+
+				// Notes
+				r.Get("/items/{itemID}/notes", noteHandler.ListNotes)
+				r.With(middleware.StrictJSON).Post("/items/{itemID}/notes", noteHandler.CreateNote)
+				r.With(middleware.StrictJSON).Patch("/notes/{noteID}", noteHandler.UpdateNote)
+				r.Delete("/notes/{noteID}", noteHandler.DeleteNote)
+
+			})
 		})
 	})
 
@@ -159,6 +507,11 @@ func (s *HTTPServer) Start() error {
 	return nil
 }
 
+// Handler returns the router, for serving it without a listener (e.g. httptest).
+func (s *HTTPServer) Handler() http.Handler {
+	return s.server.Handler
+}
+
 // Shutdown gracefully shuts down the HTTP server.
 func (s *HTTPServer) Shutdown(ctx context.Context) error {
 	s.log.Info("Shutting down HTTP server")
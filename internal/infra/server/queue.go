@@ -3,8 +3,17 @@ package server
 import (
 	"context"
 	"log/slog"
+	"time"
 
+	"github.com/windfall/uwu_service/internal/domain/analytics"
+	"github.com/windfall/uwu_service/internal/domain/bundle"
+	"github.com/windfall/uwu_service/internal/domain/content"
+	"github.com/windfall/uwu_service/internal/domain/demo"
 	"github.com/windfall/uwu_service/internal/domain/dialog"
+	"github.com/windfall/uwu_service/internal/domain/encryption"
+	"github.com/windfall/uwu_service/internal/domain/event"
+	"github.com/windfall/uwu_service/internal/domain/goal"
+	"github.com/windfall/uwu_service/internal/domain/storage"
 	"github.com/windfall/uwu_service/internal/domain/video"
 	"github.com/windfall/uwu_service/internal/infra/client"
 )
@@ -15,8 +24,17 @@ type QueueServer struct {
 	log   *slog.Logger
 
 	// Services ที่ Worker ต้องใช้ (ทำ DI เข้ามา)
-	videoService  *video.VideoService
-	dialogService *dialog.DialogService
+	videoService   *video.VideoService
+	dialogService  *dialog.DialogService
+	contentService *content.ContentService
+	goalService    *goal.GoalService
+	bundleService  *bundle.BundleService
+	storageService *storage.StorageService
+	eventService   *event.EventService
+
+	encryptionService *encryption.EncryptionService
+	analyticsService  *analytics.AnalyticsService
+	demoService       *demo.DemoService
 }
 
 // NewQueueServer สร้าง Instance ของตัวจัดการ Queue
@@ -25,12 +43,29 @@ func NewQueueServer(
 	queue *client.QueueClient,
 	videoService *video.VideoService,
 	dialogService *dialog.DialogService,
+	contentService *content.ContentService,
+	goalService *goal.GoalService,
+	bundleService *bundle.BundleService,
+	storageService *storage.StorageService,
+	eventService *event.EventService,
+	encryptionService *encryption.EncryptionService,
+	analyticsService *analytics.AnalyticsService,
+	demoService *demo.DemoService,
 ) *QueueServer {
 	return &QueueServer{
-		log:           log,
-		queue:         queue,
-		videoService:  videoService,
-		dialogService: dialogService,
+		log:            log,
+		queue:          queue,
+		videoService:   videoService,
+		dialogService:  dialogService,
+		contentService: contentService,
+		goalService:    goalService,
+		bundleService:  bundleService,
+		storageService: storageService,
+		eventService:   eventService,
+
+		encryptionService: encryptionService,
+		analyticsService:  analyticsService,
+		demoService:       demoService,
 	}
 }
 
@@ -41,9 +76,36 @@ func (s *QueueServer) SetupWorkers() {
 	// Video Workers
 	video.RegisterVideoWorkers(s.queue, s.videoService)
 	video.RegisterEvaluateRetelWorker(s.queue, s.videoService)
+	video.RegisterPurgeRetellWorker(s.queue, s.videoService)
+	video.RegisterRegenerateDetailsWorker(s.queue, s.videoService)
+	video.RegisterBackfillFrequencyWorker(s.queue, s.videoService)
 
 	// Dialog Workers
 	dialog.RegisterDialogWorkers(s.queue, s.dialogService)
+
+	// Content Workers
+	content.RegisterContentWorkers(s.queue, s.contentService)
+
+	// Goal Workers
+	goal.RegisterGoalWorkers(s.queue, s.goalService)
+
+	// Bundle Workers
+	bundle.RegisterBundleWorkers(s.queue, s.bundleService)
+
+	// Storage Workers
+	storage.RegisterStorageWorkers(s.queue, s.storageService)
+
+	// Event Workers
+	event.RegisterEventWorkers(s.queue, s.eventService)
+
+	// Encryption Workers
+	encryption.RegisterEncryptionWorkers(s.queue, s.encryptionService)
+
+	// Analytics Workers
+	analytics.RegisterAnalyticsWorkers(s.queue, s.analyticsService)
+
+	// Demo Workers
+	demo.RegisterDemoWorkers(s.queue, s.demoService)
 }
 
 // Start สั่งรันคิว
@@ -54,6 +116,174 @@ func (s *QueueServer) Start(ctx context.Context, numWorkers int) {
 	s.queue.Start(ctx, numWorkers)
 }
 
+// ScheduleRecordingPurge ตั้งรอบลบไฟล์เสียงของผู้ใช้ที่เก่ากว่า retention (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleRecordingPurge(ctx context.Context, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		s.log.Info("Recording purge disabled")
+		return
+	}
+
+	s.log.Info("Scheduling recording purge", "retention", retention.String(), "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    video.WORKER_PURGE_RETELL,
+		Payload: video.PurgeRetellAudioPayload{Retention: retention},
+	})
+}
+
+// ScheduleContentClustering ตั้งรอบจัดกลุ่มหัวข้อของคอนเทนต์ทั้งหมด (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleContentClustering(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Content clustering disabled")
+		return
+	}
+
+	s.log.Info("Scheduling content clustering", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    content.WORKER_CLUSTER_CONTENT,
+		Payload: content.ClusterContentPayload{},
+	})
+}
+
+// ScheduleGlossaryCheck ตั้งรอบตรวจความหมายของคำศัพท์ที่ขัดกันในภาษาเดียวกัน (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleGlossaryCheck(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Glossary check disabled")
+		return
+	}
+
+	s.log.Info("Scheduling glossary check", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    content.WORKER_CHECK_GLOSSARY,
+		Payload: content.CheckGlossaryPayload{},
+	})
+}
+
+// ScheduleMediaCheck ตั้งรอบตรวจไฟล์สื่อของคอนเทนต์ที่หายหรือเปิดไม่ได้ (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleMediaCheck(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Media check disabled")
+		return
+	}
+
+	s.log.Info("Scheduling media check", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    content.WORKER_CHECK_MEDIA,
+		Payload: content.CheckMediaPayload{},
+	})
+}
+
+// ScheduleGoalReminders ตั้งรอบตรวจการแจ้งเตือนเป้าหมายรายวันที่ถึงเวลาของผู้ใช้แต่ละคน (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleGoalReminders(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Goal reminders disabled")
+		return
+	}
+
+	s.log.Info("Scheduling goal reminders", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    goal.WORKER_SCAN_REMINDERS,
+		Payload: goal.ScanRemindersPayload{},
+	})
+}
+
+// ScheduleStorageUsage ตั้งรอบสรุปขนาดไฟล์ใน R2 แยกตามประเภทคอนเทนต์และผู้ใช้ (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleStorageUsage(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Storage usage scan disabled")
+		return
+	}
+
+	s.log.Info("Scheduling storage usage scan", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    storage.WORKER_SCAN_USAGE,
+		Payload: storage.ScanUsagePayload{},
+	})
+}
+
+// ScheduleEventPartitions ตั้งรอบสร้าง partition รายเดือนของ activity events ล่วงหน้า และเก็บถาวร/ลบเดือนที่เกิน retention (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleEventPartitions(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Activity event partition maintenance disabled")
+		return
+	}
+
+	job := client.Job{
+		Type:    event.WORKER_MAINTAIN_PARTITIONS,
+		Payload: event.MaintainPartitionsPayload{},
+	}
+
+	// รันทันทีตอนเริ่มระบบด้วย เพื่อให้มี partition ของเดือนปัจจุบันเสมอ
+	s.log.Info("Scheduling activity event partition maintenance", "interval", interval.String())
+	if err := s.queue.Enqueue(job); err != nil {
+		s.log.Error("Failed to enqueue activity event partition maintenance", "error", err)
+	}
+	s.queue.EnqueueEvery(ctx, interval, job)
+}
+
+// ScheduleAnalyticsExport ตั้งรอบส่ง events และสรุปรายวันไปยังระบบ analytics ภายนอก (interval <= 0 หรือไม่ได้ตั้ง sink คือปิด)
+func (s *QueueServer) ScheduleAnalyticsExport(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Analytics export disabled")
+		return
+	}
+
+	s.log.Info("Scheduling analytics export", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    analytics.WORKER_EXPORT_ANALYTICS,
+		Payload: analytics.ExportPayload{},
+	})
+}
+
+// ScheduleFieldReencryption ตั้งรอบเข้ารหัสฟิลด์ที่ยังเป็น plaintext หรือใช้คีย์เก่าใหม่ด้วยคีย์ปัจจุบัน (interval <= 0 หรือไม่ได้ตั้งคีย์คือปิด)
+func (s *QueueServer) ScheduleFieldReencryption(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Field re-encryption disabled")
+		return
+	}
+
+	job := client.Job{
+		Type:    encryption.WORKER_REENCRYPT_FIELDS,
+		Payload: encryption.ReencryptPayload{},
+	}
+
+	// รันทันทีตอนเริ่มระบบด้วย เพื่อให้ข้อมูลเดิมถูกเข้ารหัสหลังเปิดใช้งานหรือเปลี่ยนคีย์
+	s.log.Info("Scheduling field re-encryption", "interval", interval.String())
+	if err := s.queue.Enqueue(job); err != nil {
+		s.log.Error("Failed to enqueue field re-encryption", "error", err)
+	}
+	s.queue.EnqueueEvery(ctx, interval, job)
+}
+
+// ScheduleDemoCleanup ตั้งรอบลบบัญชี demo ที่หมดอายุพร้อมคอนเทนต์ที่สร้างไว้ (interval <= 0 คือปิด)
+func (s *QueueServer) ScheduleDemoCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		s.log.Info("Demo account cleanup disabled")
+		return
+	}
+
+	s.log.Info("Scheduling demo account cleanup", "interval", interval.String())
+	s.queue.EnqueueEvery(ctx, interval, client.Job{
+		Type:    demo.WORKER_CLEANUP_DEMO,
+		Payload: demo.CleanupPayload{},
+	})
+}
+
+// BackfillFrequencyRanks สั่งจัดอันดับความถี่ของคำศัพท์ในวิดีโอเดิมทั้งหมดหนึ่งครั้งตอนเริ่มระบบ (ถ้าไม่มีรายการความถี่คือปิด)
+func (s *QueueServer) BackfillFrequencyRanks(enabled bool) {
+	if !enabled {
+		s.log.Info("Frequency rank backfill disabled")
+		return
+	}
+
+	s.log.Info("Enqueueing frequency rank backfill")
+	if err := s.queue.Enqueue(client.Job{
+		Type:    video.WORKER_BACKFILL_RANKS,
+		Payload: video.BackfillFrequencyPayload{},
+	}); err != nil {
+		s.log.Error("Failed to enqueue frequency rank backfill", "error", err)
+	}
+}
+
 // Stop สั่งปิดคิวอย่างปลอดภัย (Graceful Shutdown)
 func (s *QueueServer) Stop() {
 	s.log.Info("Stopping Queue Server...")
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE learning_items DROP COLUMN IF EXISTS version;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Optimistic concurrency control: bumped on every update of a learning item
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMIT;
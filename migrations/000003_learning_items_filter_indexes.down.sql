@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_tags;
+DROP INDEX IF EXISTS idx_learning_items_created_at;
+DROP INDEX IF EXISTS idx_learning_items_level;
+
+COMMIT;
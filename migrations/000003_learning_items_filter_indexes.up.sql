@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Indexes backing the filtered learning item listing
+CREATE INDEX IF NOT EXISTS idx_learning_items_level ON learning_items(level);
+CREATE INDEX IF NOT EXISTS idx_learning_items_created_at ON learning_items(feature_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_learning_items_tags ON learning_items USING GIN (tags jsonb_path_ops);
+
+COMMIT;
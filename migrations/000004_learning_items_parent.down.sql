@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_parent_id;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS parent_id;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Links adapted variants (e.g. a dialog regenerated for another level) to their original
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES learning_items(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_learning_items_parent_id ON learning_items(parent_id);
+
+COMMIT;
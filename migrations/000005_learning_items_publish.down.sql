@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE learning_items DROP COLUMN IF EXISTS published_at;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS published_version;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS published_details;
+
+COMMIT;
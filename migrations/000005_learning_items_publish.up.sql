@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Published snapshot of a learning item; details stays the editable draft
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS published_details JSONB;
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS published_version INTEGER;
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+
+-- Dialogs that are already live stay visible to learners
+UPDATE learning_items
+SET published_details = details, published_version = version, published_at = NOW()
+WHERE feature_id = 2 AND is_active = true;
+
+COMMIT;
@@ -0,0 +1,2 @@
+-- Postgres cannot drop enum values; remove the sessions so the value is unused
+DELETE FROM user_actions WHERE action_type = 'submit_sparring';
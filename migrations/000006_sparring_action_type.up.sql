@@ -0,0 +1,2 @@
+-- Live sparring sessions on dialogs (ADD VALUE cannot run inside a transaction block)
+ALTER TYPE user_action_type_enum ADD VALUE IF NOT EXISTS 'submit_sparring';
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_cluster_label;
+
+ALTER TABLE learning_items DROP COLUMN IF EXISTS clustered_at;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS cluster_label;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Topic cluster assigned by the periodic content clustering job
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS cluster_label TEXT;
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS clustered_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_learning_items_cluster_label ON learning_items (cluster_label) WHERE cluster_label IS NOT NULL;
+
+COMMIT;
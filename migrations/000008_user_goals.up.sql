@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Daily learning goal and reminder preferences per user
+CREATE TABLE IF NOT EXISTS user_goals (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    goal_type VARCHAR(20) NOT NULL DEFAULT 'items', -- 'items' or 'minutes'
+    target INTEGER NOT NULL DEFAULT 3,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    reminder_time TIME, -- local time; NULL disables reminders
+    last_reminded_on DATE, -- local date of the last reminder sent
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_user_goals_reminder_time ON user_goals(reminder_time) WHERE reminder_time IS NOT NULL;
+
+COMMIT;
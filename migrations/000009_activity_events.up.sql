@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Client-side telemetry, append-only
+CREATE TABLE IF NOT EXISTS activity_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL, -- item_viewed, audio_played, turn_completed
+    learning_id UUID REFERENCES learning_items(id) ON DELETE CASCADE,
+    duration_ms INTEGER,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_occurred ON activity_events(user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_learning ON activity_events(user_id, learning_id) WHERE learning_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Admin and support actions taken on behalf of or about a user
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    admin VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL, -- impersonate, view_sessions, view_batches, view_errors
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target_user ON admin_audit_log(target_user_id, created_at);
+
+COMMIT;
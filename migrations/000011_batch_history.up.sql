@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Final state of processing batches, kept after they expire from Redis
+CREATE TABLE IF NOT EXISTS batch_history (
+    batch_id VARCHAR(255) PRIMARY KEY,
+    batch_type VARCHAR(50) NOT NULL, -- upload_video, evaluate_retell, generate_dialog, regenerate_turn
+    status VARCHAR(20) NOT NULL,
+    total_jobs INT NOT NULL DEFAULT 0,
+    completed_jobs INT NOT NULL DEFAULT 0,
+    jobs JSONB DEFAULT '[]'::jsonb,
+    result JSONB,
+    result_url TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_batch_history_type_archived ON batch_history(batch_type, archived_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_batch_history_reference;
+ALTER TABLE batch_history DROP COLUMN IF EXISTS reference_id;
+ALTER TABLE batch_history DROP COLUMN IF EXISTS reference_type;
+
+COMMIT;
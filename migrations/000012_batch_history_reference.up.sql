@@ -0,0 +1,8 @@
+BEGIN;
+
+-- What an archived batch worked on (the batch ID alone is ambiguous)
+ALTER TABLE batch_history ADD COLUMN IF NOT EXISTS reference_type VARCHAR(50); -- video, retell_attempt, dialog
+ALTER TABLE batch_history ADD COLUMN IF NOT EXISTS reference_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_batch_history_reference ON batch_history(reference_type, reference_id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_updated_at;
+DROP TABLE IF EXISTS learning_item_tombstones;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Hard-deleted learning items, so delta sync can tell clients to drop them
+CREATE TABLE IF NOT EXISTS learning_item_tombstones (
+    id UUID PRIMARY KEY,
+    feature_id INTEGER,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_learning_item_tombstones_deleted_at ON learning_item_tombstones(deleted_at, id);
+
+-- Delta sync pages through changes by (updated_at, id)
+CREATE INDEX IF NOT EXISTS idx_learning_items_updated_at ON learning_items(updated_at, id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_public;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS is_public;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Learning items approved for the unauthenticated public API (marketing and web previews)
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS is_public BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_learning_items_public ON learning_items(feature_id, created_at DESC) WHERE is_public;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS user_consents;
+DROP TABLE IF EXISTS consent_terms;
+
+COMMIT;
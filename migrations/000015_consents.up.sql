@@ -0,0 +1,36 @@
+BEGIN;
+
+-- Versioned terms users must accept (e.g. consent to store voice recordings); the newest
+-- published_at per type is the one in force
+CREATE TABLE IF NOT EXISTS consent_terms (
+    consent_type VARCHAR(50) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consent_type, version)
+);
+CREATE INDEX IF NOT EXISTS idx_consent_terms_latest ON consent_terms(consent_type, published_at DESC);
+
+-- Every acceptance is kept, one row per user and terms version
+CREATE TABLE IF NOT EXISTS user_consents (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    consent_type VARCHAR(50) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    accepted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    PRIMARY KEY (user_id, consent_type, version),
+    FOREIGN KEY (consent_type, version) REFERENCES consent_terms(consent_type, version)
+);
+
+INSERT INTO consent_terms (consent_type, version, title, body)
+VALUES (
+    'recording',
+    '1',
+    'Voice recording consent',
+    'Speaking and retell exercises record your voice. Recordings are stored to transcribe and score your answers and to show you your progress. They are deleted together with your account.'
+)
+ON CONFLICT DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_audience;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS audience;
+ALTER TABLE users DROP COLUMN IF EXISTS birth_date;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Learners under the adult age do not see or generate adult-audience content
+ALTER TABLE users ADD COLUMN IF NOT EXISTS birth_date DATE;
+
+-- general: suitable for every age; adult: alcohol, gambling and similar scenarios
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS audience VARCHAR(20) NOT NULL DEFAULT 'general';
+CREATE INDEX IF NOT EXISTS idx_learning_items_audience ON learning_items(audience) WHERE audience <> 'general';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE learning_items DROP COLUMN IF EXISTS moderation_hidden_at;
+DROP TABLE IF EXISTS content_reports;
+
+COMMIT;
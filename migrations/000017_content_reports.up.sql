@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Learner reports on generated content; one report per user and item
+CREATE TABLE IF NOT EXISTS content_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    learning_id UUID NOT NULL REFERENCES learning_items(id) ON DELETE CASCADE,
+    feature_id INTEGER NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL,
+    comment TEXT,
+    -- open, dismissed or upheld
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    UNIQUE (learning_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_content_reports_status ON content_reports(status, learning_id);
+CREATE INDEX IF NOT EXISTS idx_content_reports_upheld ON content_reports(feature_id, resolved_at) WHERE status = 'upheld';
+
+-- Set when reports deactivated the item, so dismissing them can restore it
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS moderation_hidden_at TIMESTAMPTZ;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_learning_items_review_status;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS review_status;
+ALTER TABLE learning_items DROP COLUMN IF EXISTS quality_score;
+
+COMMIT;
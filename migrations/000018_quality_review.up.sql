@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Quality critic score of generated content and the review state of items it flagged
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS quality_score SMALLINT;
+-- pending, approved or rejected; NULL when the critic did not flag the item
+ALTER TABLE learning_items ADD COLUMN IF NOT EXISTS review_status VARCHAR(20);
+CREATE INDEX IF NOT EXISTS idx_learning_items_review_status ON learning_items(review_status, created_at) WHERE review_status IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Terms the glossary check found with more than one meaning in the same language
+CREATE TABLE IF NOT EXISTS glossary_conflicts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    language VARCHAR(20) NOT NULL,
+    term TEXT NOT NULL,
+    -- [{"meaning": "...", "items": ["<learning_id>"], "count": 1}], most used first
+    meanings JSONB NOT NULL,
+    suggested_meaning TEXT NOT NULL,
+    -- true when every meaning is a rewording of the suggestion
+    mergeable BOOLEAN NOT NULL DEFAULT false,
+    checked_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (language, term)
+);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS item_scenario_links;
+
+COMMIT;
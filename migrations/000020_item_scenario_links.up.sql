@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Video vocabulary terms used in the turns of published dialogs (rebuilt on publish)
+CREATE TABLE IF NOT EXISTS item_scenario_links (
+    item_id UUID NOT NULL REFERENCES learning_items(id) ON DELETE CASCADE,
+    scenario_id UUID NOT NULL REFERENCES learning_items(id) ON DELETE CASCADE,
+    turn_index INT NOT NULL,
+    term TEXT NOT NULL,
+    PRIMARY KEY (item_id, scenario_id, turn_index, term)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_scenario_links_scenario ON item_scenario_links (scenario_id);
+
+COMMIT;
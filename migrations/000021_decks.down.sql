@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS deck_items;
+DROP TABLE IF EXISTS decks;
+
+COMMIT;
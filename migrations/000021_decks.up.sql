@@ -0,0 +1,23 @@
+BEGIN;
+
+-- User-curated collections of learning items
+CREATE TABLE IF NOT EXISTS decks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    share_token UUID UNIQUE, -- set while the deck is shared by link
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_decks_user_id ON decks(user_id, updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS deck_items (
+    deck_id UUID NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+    learning_id UUID NOT NULL REFERENCES learning_items(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (deck_id, learning_id)
+);
+CREATE INDEX IF NOT EXISTS idx_deck_items_learning_id ON deck_items(learning_id);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_decks_public_recent;
+DROP INDEX IF EXISTS idx_decks_public_popular;
+ALTER TABLE decks
+    DROP COLUMN IF EXISTS view_count,
+    DROP COLUMN IF EXISTS copy_count,
+    DROP COLUMN IF EXISTS copied_from,
+    DROP COLUMN IF EXISTS published_at;
+
+COMMIT;
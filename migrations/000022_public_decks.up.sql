@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Decks published to the public marketplace
+ALTER TABLE decks
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS copied_from UUID REFERENCES decks(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS copy_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS view_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_decks_public_popular ON decks(copy_count DESC, view_count DESC) WHERE published_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_decks_public_recent ON decks(published_at DESC) WHERE published_at IS NOT NULL;
+
+COMMIT;
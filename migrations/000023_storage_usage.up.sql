@@ -0,0 +1,16 @@
+BEGIN;
+
+-- R2 usage by content type and owner, rebuilt by each storage usage scan
+CREATE TABLE IF NOT EXISTS storage_usage (
+    content_type VARCHAR(30) NOT NULL,
+    -- owning user ('' when the object cannot be attributed, e.g. batch results and bundles)
+    user_id VARCHAR(50) NOT NULL DEFAULT '',
+    object_count BIGINT NOT NULL DEFAULT 0,
+    total_bytes BIGINT NOT NULL DEFAULT 0,
+    scanned_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (content_type, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_usage_user ON storage_usage(user_id);
+
+COMMIT;
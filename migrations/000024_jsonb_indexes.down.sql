@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_user_actions_retell_updated;
+DROP INDEX IF EXISTS idx_learning_items_created_by;
+DROP INDEX IF EXISTS idx_user_actions_metadata;
+DROP INDEX IF EXISTS idx_learning_items_metadata;
+DROP INDEX IF EXISTS idx_learning_items_details;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Containment (@>) lookups on item JSONB; dialogs are learning_items rows, so this covers their metadata too.
+-- tags (GIN) and feature_id + created_at already have indexes from 000003.
+CREATE INDEX IF NOT EXISTS idx_learning_items_details ON learning_items USING GIN (details jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_learning_items_metadata ON learning_items USING GIN (metadata jsonb_path_ops);
+
+-- Session state (chat status, quiz and retell attempts) lives in user action metadata
+CREATE INDEX IF NOT EXISTS idx_user_actions_metadata ON user_actions USING GIN (metadata jsonb_path_ops);
+
+-- Items by creator (creator filter of the video list, support and storage reports)
+CREATE INDEX IF NOT EXISTS idx_learning_items_created_by ON learning_items(created_by, created_at DESC);
+
+-- Recording purge walks retell actions oldest first
+CREATE INDEX IF NOT EXISTS idx_user_actions_retell_updated ON user_actions(updated_at) WHERE action_type = 'submit_retell';
+
+COMMIT;
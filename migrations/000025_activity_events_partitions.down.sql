@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Back to a single table; events already archived and dropped by the maintenance job are not restored
+ALTER TABLE activity_events RENAME TO activity_events_partitioned;
+DROP INDEX IF EXISTS idx_activity_events_user_occurred;
+DROP INDEX IF EXISTS idx_activity_events_user_learning;
+
+CREATE TABLE activity_events (
+    id BIGINT PRIMARY KEY DEFAULT nextval('activity_events_id_seq'),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL, -- item_viewed, audio_played, turn_completed
+    learning_id UUID REFERENCES learning_items(id) ON DELETE CASCADE,
+    duration_ms INTEGER,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_occurred ON activity_events(user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_learning ON activity_events(user_id, learning_id) WHERE learning_id IS NOT NULL;
+
+INSERT INTO activity_events (id, user_id, event_type, learning_id, duration_ms, metadata, occurred_at, created_at)
+SELECT id, user_id, event_type, learning_id, duration_ms, metadata, occurred_at, created_at
+FROM activity_events_partitioned;
+
+ALTER SEQUENCE activity_events_id_seq OWNED BY activity_events.id;
+-- Drops every partition with it
+DROP TABLE activity_events_partitioned;
+
+COMMIT;
@@ -0,0 +1,54 @@
+BEGIN;
+
+-- Partition boundaries are whole UTC months
+SET LOCAL TIME ZONE 'UTC';
+
+-- Rebuild activity_events as a table partitioned by month of occurred_at, keeping ids
+ALTER TABLE activity_events RENAME TO activity_events_unpartitioned;
+DROP INDEX IF EXISTS idx_activity_events_user_occurred;
+DROP INDEX IF EXISTS idx_activity_events_user_learning;
+
+CREATE TABLE activity_events (
+    id BIGINT NOT NULL DEFAULT nextval('activity_events_id_seq'),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL, -- item_viewed, audio_played, turn_completed
+    learning_id UUID REFERENCES learning_items(id) ON DELETE CASCADE,
+    duration_ms INTEGER,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (id, occurred_at)
+) PARTITION BY RANGE (occurred_at);
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_occurred ON activity_events(user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_learning ON activity_events(user_id, learning_id) WHERE learning_id IS NOT NULL;
+
+-- Catches events outside the monthly partitions the maintenance job creates ahead
+CREATE TABLE IF NOT EXISTS activity_events_default PARTITION OF activity_events DEFAULT;
+
+-- activity_events_YYYY_MM from the oldest event's month to two months ahead
+DO $$
+DECLARE
+    m TIMESTAMPTZ;
+BEGIN
+    FOR m IN
+        SELECT generate_series(
+            date_trunc('month', COALESCE((SELECT MIN(occurred_at) FROM activity_events_unpartitioned), NOW())),
+            date_trunc('month', NOW()) + INTERVAL '2 months',
+            INTERVAL '1 month'
+        )
+    LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF activity_events FOR VALUES FROM (%L) TO (%L)',
+            'activity_events_' || to_char(m, 'YYYY_MM'), m, m + INTERVAL '1 month'
+        );
+    END LOOP;
+END $$;
+
+INSERT INTO activity_events (id, user_id, event_type, learning_id, duration_ms, metadata, occurred_at, created_at)
+SELECT id, user_id, event_type, learning_id, duration_ms, metadata, occurred_at, created_at
+FROM activity_events_unpartitioned;
+
+ALTER SEQUENCE activity_events_id_seq OWNED BY activity_events.id;
+DROP TABLE activity_events_unpartitioned;
+
+COMMIT;
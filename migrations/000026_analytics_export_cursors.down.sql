@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_batch_history_archived;
+DROP INDEX IF EXISTS idx_activity_events_created;
+DROP TABLE IF EXISTS analytics_export_cursors;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- How far each analytics stream has been exported to the analytics sink
+CREATE TABLE IF NOT EXISTS analytics_export_cursors (
+    stream VARCHAR(50) PRIMARY KEY, -- activity_events, quiz_attempts, retell_attempts, generation_batches, daily_usage
+    exported_until TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Activity events are exported by when they were received
+CREATE INDEX IF NOT EXISTS idx_activity_events_created ON activity_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_batch_history_archived ON batch_history(archived_at);
+
+COMMIT;
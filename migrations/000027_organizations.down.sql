@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS organization_credentials;
+DROP INDEX IF EXISTS idx_users_organization;
+ALTER TABLE users DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organizations;
+
+COMMIT;
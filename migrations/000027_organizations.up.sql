@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Enterprise customers; their members' AI calls can use the organization's own provider keys
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_users_organization ON users(organization_id) WHERE organization_id IS NOT NULL;
+
+-- Bring-your-own-key credentials, one row per organization and provider
+CREATE TABLE IF NOT EXISTS organization_credentials (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL, -- azure_openai, azure_whisper, azure_speech, gcp
+    endpoint TEXT NOT NULL DEFAULT '',
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    secret_ciphertext BYTEA NOT NULL, -- AES-256-GCM nonce || ciphertext, sealed with CREDENTIALS_ENCRYPTION_KEY
+    key_hint VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (organization_id, provider)
+);
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+-- Durable queue jobs (generation flows); finished jobs are deleted, dead ones kept for an admin retry
+CREATE TABLE queue_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    job_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    batch_id TEXT NOT NULL DEFAULT '',
+    organization_id UUID,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_by TEXT,
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_queue_jobs_ready ON queue_jobs (run_at, id) WHERE status = 'pending';
+CREATE INDEX idx_queue_jobs_lease ON queue_jobs (locked_until) WHERE status = 'running';
+CREATE INDEX idx_queue_jobs_dead ON queue_jobs (updated_at DESC) WHERE status = 'dead';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS batch_jobs;
+DROP TABLE IF EXISTS batches;
+
+COMMIT;
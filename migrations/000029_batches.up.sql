@@ -0,0 +1,46 @@
+BEGIN;
+
+-- Processing batches and their jobs; Redis only caches them (see client.BatchStore)
+CREATE TABLE IF NOT EXISTS batches (
+    batch_id VARCHAR(255) PRIMARY KEY,
+    batch_type VARCHAR(50) NOT NULL, -- upload_video, evaluate_retell, generate_dialog, regenerate_turn, build_bundle
+    reference_type VARCHAR(50),
+    reference_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    total_jobs INT NOT NULL DEFAULT 0,
+    completed_jobs INT NOT NULL DEFAULT 0,
+    result JSONB,
+    result_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_batches_reference ON batches(reference_type, reference_id);
+
+CREATE TABLE IF NOT EXISTS batch_jobs (
+    batch_id VARCHAR(255) NOT NULL REFERENCES batches(batch_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    position INT NOT NULL, -- order the batch was created with
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    PRIMARY KEY (batch_id, name)
+);
+
+-- Archived batches are the first rows
+INSERT INTO batches (batch_id, batch_type, reference_type, reference_id, status, total_jobs, completed_jobs, result, result_url, created_at, updated_at)
+SELECT batch_id, batch_type, reference_type, reference_id, status, total_jobs, completed_jobs, result, result_url,
+    COALESCE(created_at, archived_at), COALESCE(updated_at, archived_at)
+FROM batch_history
+ON CONFLICT (batch_id) DO NOTHING;
+
+INSERT INTO batch_jobs (batch_id, name, position, status, error, started_at, completed_at)
+SELECT h.batch_id, j.job->>'name', j.position, COALESCE(j.job->>'status', 'unknown'), NULLIF(j.job->>'error', ''),
+    NULLIF(j.job->>'started_at', '')::timestamptz, NULLIF(j.job->>'completed_at', '')::timestamptz
+FROM batch_history h
+CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(h.jobs) = 'array' THEN h.jobs ELSE '[]'::jsonb END)
+    WITH ORDINALITY AS j(job, position)
+WHERE COALESCE(j.job->>'name', '') <> ''
+ON CONFLICT (batch_id, name) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Media of active items the media check found missing or unreachable; replaced on every check
+CREATE TABLE IF NOT EXISTS media_issues (
+    learning_id UUID NOT NULL REFERENCES learning_items(id) ON DELETE CASCADE,
+    feature_id INTEGER NOT NULL,
+    language VARCHAR(20) NOT NULL DEFAULT '',
+    field VARCHAR(30) NOT NULL,          -- image, audio, script_audio, video, thumbnail
+    turn_index INT NOT NULL DEFAULT -1,  -- script turn of script_audio, -1 otherwise
+    url TEXT NOT NULL DEFAULT '',
+    problem VARCHAR(20) NOT NULL,        -- missing, unreachable
+    status_code INT NOT NULL DEFAULT 0,  -- HTTP status of the check (0 = no response)
+    repair_queued BOOLEAN NOT NULL DEFAULT false,
+    checked_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (learning_id, field, turn_index)
+);
+CREATE INDEX IF NOT EXISTS idx_media_issues_problem ON media_issues(problem, feature_id);
+
+COMMIT;
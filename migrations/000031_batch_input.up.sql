@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Job input of a batch, kept so a failed job can be retried without the original request
+ALTER TABLE batches ADD COLUMN IF NOT EXISTS input JSONB;
+
+COMMIT;
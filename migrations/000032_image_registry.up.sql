@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Generated dialog images by prompt, so dialogs with the same image prompt and style share one image
+CREATE TABLE IF NOT EXISTS image_registry (
+    prompt_hash    TEXT PRIMARY KEY,
+    image_url      TEXT NOT NULL,
+    variants       JSONB,
+    dialog_id      UUID,
+    reuse_count    INT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_reused_at TIMESTAMPTZ
+);
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+-- Favorites of any content type (replaces the quiz_saved / dialogue_saved actions)
+CREATE TABLE IF NOT EXISTS favorites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content_type TEXT NOT NULL CHECK (content_type IN ('video', 'dialog', 'deck')),
+    content_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, content_type, content_id)
+);
+CREATE INDEX IF NOT EXISTS idx_favorites_user ON favorites(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_favorites_content ON favorites(content_type, content_id);
+
+-- Carry over saved videos and dialogs; the old actions are no longer read
+INSERT INTO favorites (user_id, content_type, content_id, created_at)
+SELECT user_id, CASE WHEN action_type = 'quiz_saved' THEN 'video' ELSE 'dialog' END, learning_id, COALESCE(updated_at, created_at, NOW())
+FROM user_actions
+WHERE action_type IN ('quiz_saved', 'dialogue_saved') AND deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+COMMIT;
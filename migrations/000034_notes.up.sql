@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Private notes of users on learning items. Deleted notes keep a row (deleted_at) so delta sync
+-- can tell clients to drop them.
+CREATE TABLE IF NOT EXISTS notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    learning_id UUID NOT NULL REFERENCES learning_items(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_notes_user_item ON notes(user_id, learning_id, created_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Ephemeral accounts issued by POST /demo/session; they and their content are deleted after this time
+ALTER TABLE users ADD COLUMN IF NOT EXISTS demo_expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_users_demo_expires_at ON users(demo_expires_at) WHERE demo_expires_at IS NOT NULL;
+
+COMMIT;
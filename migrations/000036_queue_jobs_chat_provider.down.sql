@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE queue_jobs DROP COLUMN IF EXISTS chat_provider;
+
+COMMIT;
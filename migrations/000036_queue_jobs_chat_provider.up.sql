@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Chat provider pinned by the request that queued the job (empty uses the configured chains)
+ALTER TABLE queue_jobs ADD COLUMN IF NOT EXISTS chat_provider TEXT NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_deck_items_due_at;
+ALTER TABLE deck_items
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS due_at,
+    DROP COLUMN IF EXISTS review_streak,
+    DROP COLUMN IF EXISTS review_ease,
+    DROP COLUMN IF EXISTS review_interval;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Spaced-repetition schedule of each deck item, set by the owner's reviews
+ALTER TABLE deck_items
+    ADD COLUMN IF NOT EXISTS review_interval INTEGER NOT NULL DEFAULT 0, -- days
+    ADD COLUMN IF NOT EXISTS review_ease DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    ADD COLUMN IF NOT EXISTS review_streak INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ, -- NULL until the first review
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_deck_items_due_at ON deck_items(deck_id, due_at NULLS FIRST);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS image_style;
+
+COMMIT;
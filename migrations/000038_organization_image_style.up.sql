@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Default image style preset of an organization's dialogs (NULL uses IMAGE_STYLE_DEFAULT)
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS image_style VARCHAR(50);
+
+COMMIT;
@@ -14,6 +14,13 @@ const (
 	ErrForbidden    ErrorCode = "FORBIDDEN"
 	ErrConflict     ErrorCode = "CONFLICT"
 	ErrRateLimit    ErrorCode = "RATE_LIMIT_EXCEEDED"
+	ErrAudioTooLong ErrorCode = "AUDIO_TOO_LONG"
+	ErrBudget       ErrorCode = "BUDGET_EXCEEDED"
+	ErrMaintenance  ErrorCode = "MAINTENANCE"
+	ErrTooLarge     ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrProviderDown ErrorCode = "PROVIDER_UNAVAILABLE"
+	ErrConsent      ErrorCode = "CONSENT_REQUIRED"
+	ErrUnsupported  ErrorCode = "CAPABILITY_UNAVAILABLE"
 
 	// Service-specific errors
 	ErrAIService      ErrorCode = "AI_SERVICE_ERROR"
@@ -105,3 +112,20 @@ func ConflictWrap(message string, err error) *AppError { return Wrap(ErrConflict
 
 func RateLimit(message string) *AppError                { return New(ErrRateLimit, message) }
 func RateLimitWrap(message string, err error) *AppError { return Wrap(ErrRateLimit, message, err) }
+
+func AudioTooLong(message string) *AppError { return New(ErrAudioTooLong, message) }
+
+func BudgetExceeded(message string) *AppError { return New(ErrBudget, message) }
+
+func Maintenance(message string) *AppError { return New(ErrMaintenance, message) }
+
+func TooLarge(message string) *AppError { return New(ErrTooLarge, message) }
+
+func ProviderUnavailable(message string) *AppError { return New(ErrProviderDown, message) }
+
+func ConsentRequired(message string) *AppError { return New(ErrConsent, message) }
+
+func Unsupported(message string) *AppError { return New(ErrUnsupported, message) }
+
+func Timeout(message string) *AppError                { return New(ErrTimeout, message) }
+func TimeoutWrap(message string, err error) *AppError { return Wrap(ErrTimeout, message, err) }
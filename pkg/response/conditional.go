@@ -0,0 +1,63 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OKConditional writes data and meta like OKWithMeta, with an ETag and Last-Modified built from
+// updatedAt. Clients that send a matching If-None-Match (or, without one, an If-Modified-Since
+// no older than updatedAt) get 304 Not Modified and no body.
+//
+// The ETag also hashes the encoded response, so per-user fields (actions, batch progress)
+// change it even when the item itself did not.
+func OKConditional(w http.ResponseWriter, r *http.Request, updatedAt time.Time, data interface{}, meta interface{}) {
+	body, err := json.Marshal(Response{Success: true, Data: data, Meta: meta})
+	if err != nil {
+		OKWithMeta(w, data, meta)
+		return
+	}
+
+	h := fnv.New64a()
+	h.Write(body)
+	etag := fmt.Sprintf(`W/"%x-%x"`, updatedAt.Unix(), h.Sum64())
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if !updatedAt.IsZero() {
+		w.Header().Set("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, updatedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// notModified applies If-None-Match, falling back to If-Modified-Since only when no ETag was sent.
+func notModified(r *http.Request, etag string, updatedAt time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// Weak comparison: W/"x" and "x" match
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !updatedAt.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !updatedAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package response
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// MediaVariants maps a canonical media URL to its alternate encodings by content type,
+// e.g. {"https://cdn/x.mp3": {"audio/ogg": "https://cdn/x.opus"}}.
+type MediaVariants map[string]map[string]string
+
+// Resolve returns the encoding of url the request's Accept header prefers. Only media types
+// listed explicitly count, so clients that don't ask keep the canonical URL.
+func (v MediaVariants) Resolve(r *http.Request, url string) string {
+	variants := v[url]
+	if len(variants) == 0 {
+		return url
+	}
+
+	accepted := parseAccept(r.Header.Get("Accept"))
+	best := url
+	bestQ, bestPos := 0.0, len(accepted)
+	if canonical := mediaTypeByExt(path.Ext(url)); canonical != "" {
+		if i, q, ok := findAccepted(accepted, canonical); ok {
+			bestQ, bestPos = q, i
+		}
+	}
+
+	for contentType, variantURL := range variants {
+		i, q, ok := findAccepted(accepted, contentType)
+		if !ok || q == 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && i < bestPos) {
+			best, bestQ, bestPos = variantURL, q, i
+		}
+	}
+	return best
+}
+
+// NegotiateMediaJSON rewrites the media URLs of a details blob that carries its own
+// media_variants to the encodings the request accepts. Other blobs are returned as is.
+func NegotiateMediaJSON(r *http.Request, raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || r.Header.Get("Accept") == "" {
+		return raw
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return raw
+	}
+	variantsRaw, ok := doc["media_variants"]
+	if !ok {
+		return raw
+	}
+
+	var variants MediaVariants
+	if data, err := json.Marshal(variantsRaw); err != nil || json.Unmarshal(data, &variants) != nil || len(variants) == 0 {
+		return raw
+	}
+
+	for key, value := range doc {
+		if key != "media_variants" {
+			doc[key] = resolveMediaValue(r, variants, value)
+		}
+	}
+
+	resolved, err := json.Marshal(doc)
+	if err != nil {
+		return raw
+	}
+	return resolved
+}
+
+func resolveMediaValue(r *http.Request, variants MediaVariants, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = resolveMediaValue(r, variants, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = resolveMediaValue(r, variants, child)
+		}
+		return v
+	case string:
+		return variants.Resolve(r, v)
+	default:
+		return v
+	}
+}
+
+// mediaTypeByExt covers the audio types the mime package may not know on minimal images.
+func mediaTypeByExt(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".mp3":
+		return "audio/mpeg"
+	case ".m4a":
+		return "audio/mp4"
+	case ".opus":
+		return "audio/ogg"
+	}
+	return mime.TypeByExtension(ext)
+}
+
+type acceptedType struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept splits an Accept header into media types and their q values, in header order.
+func parseAccept(header string) []acceptedType {
+	var accepted []acceptedType
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		accepted = append(accepted, acceptedType{mediaType: mediaType, q: q})
+	}
+	return accepted
+}
+
+func findAccepted(accepted []acceptedType, contentType string) (int, float64, bool) {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for i, a := range accepted {
+		if a.mediaType == contentType {
+			return i, a.q, true
+		}
+	}
+	return 0, 0, false
+}
@@ -35,13 +35,17 @@ type MetaPagination struct {
 }
 
 type MetaProcessing struct {
-	BatchID       string     `json:"batch_id"`
-	Status        string     `json:"status"`
-	TotalJobs     int        `json:"total_jobs"`
-	CompletedJobs int        `json:"completed_jobs"`
-	BatchJobs     []BatchJob `json:"jobs"`
-	CreatedAt     *string    `json:"created_at"`
-	UpdatedAt     *string    `json:"updated_at"`
+	BatchID       string          `json:"batch_id"`
+	ReferenceType string          `json:"reference_type,omitempty"` // what the batch works on: video, retell_attempt, dialog
+	ReferenceID   string          `json:"reference_id,omitempty"`
+	Status        string          `json:"status"`
+	TotalJobs     int             `json:"total_jobs"`
+	CompletedJobs int             `json:"completed_jobs"`
+	BatchJobs     []BatchJob      `json:"jobs"`
+	Result        json.RawMessage `json:"result,omitempty"`
+	ResultURL     string          `json:"result_url,omitempty"`
+	CreatedAt     *string         `json:"created_at"`
+	UpdatedAt     *string         `json:"updated_at"`
 }
 
 type BatchJob struct {
@@ -144,7 +148,7 @@ func mapErrorCodeToHTTPStatus(code string) int {
 		return http.StatusBadRequest
 	case "UNAUTHORIZED":
 		return http.StatusUnauthorized
-	case "FORBIDDEN":
+	case "FORBIDDEN", "CONSENT_REQUIRED":
 		return http.StatusForbidden
 	case "NOT_FOUND":
 		return http.StatusNotFound
@@ -152,8 +156,16 @@ func mapErrorCodeToHTTPStatus(code string) int {
 		return http.StatusConflict
 	case "RATE_LIMIT_EXCEEDED":
 		return http.StatusTooManyRequests
+	case "BUDGET_EXCEEDED":
+		return http.StatusTooManyRequests
+	case "AUDIO_TOO_LONG", "PAYLOAD_TOO_LARGE":
+		return http.StatusRequestEntityTooLarge
 	case "TIMEOUT_ERROR":
 		return http.StatusGatewayTimeout
+	case "MAINTENANCE", "PROVIDER_UNAVAILABLE":
+		return http.StatusServiceUnavailable
+	case "CAPABILITY_UNAVAILABLE":
+		return http.StatusNotImplemented
 	default:
 		// คลุมพวก INTERNAL_ERROR, DATABASE_ERROR, AI_SERVICE_ERROR
 		return http.StatusInternalServerError